@@ -18,6 +18,7 @@ import (
 )
 
 var checkCmd = &cobra.Command{
-	Use:   "check",
-	Short: "Contains some verification checks",
+	Use:     "check",
+	Aliases: []string{"audit"},
+	Short:   "Contains some verification checks",
 }
@@ -0,0 +1,124 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/lint"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var a11yMaxProblems int
+
+func init() {
+	initHugoBuilderFlags(checkA11yCmd)
+	checkA11yCmd.Flags().IntVar(&a11yMaxProblems, "maxProblems", 0, "fail if more than this number of problems are found, -1 to never fail")
+	checkCmd.AddCommand(checkA11yCmd)
+}
+
+var checkA11yCmd = &cobra.Command{
+	Use:   "a11y",
+	Short: "Check the rendered HTML for accessibility problems",
+	Long: `Build the site in memory and check the rendered HTML pages for some
+common accessibility problems:
+
+    img-alt        images without an alt attribute
+    heading-order  headings skipping levels, e.g. an h4 following an h2
+    empty-link     links without any text or label
+
+The problems are listed per page with its content file.
+The command fails if more than --maxProblems problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgInit := func(c *commandeer) error {
+			c.Set("renderToMemory", true)
+			return nil
+		}
+
+		c, err := InitializeConfig(false, cfgInit, cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := c.buildSites(); err != nil {
+			return newSystemError("Error building site:", err)
+		}
+
+		count, err := checkA11y(os.Stdout, c.Fs.Destination, Hugo.PageOutputFiles(), c.PathSpec().WorkingDir())
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		if a11yMaxProblems >= 0 && count > a11yMaxProblems {
+			return newSystemErrorF("Found %d accessibility problem(s)", count)
+		}
+
+		return nil
+	},
+}
+
+// checkA11y checks the HTML files and writes the problems found per page to
+// w. It returns the number of problems.
+func checkA11y(w io.Writer, fs afero.Fs, files []hugolib.PageOutputFile, workingDir string) (int, error) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+
+	count := 0
+	seen := make(map[string]bool)
+
+	for _, f := range files {
+		if !f.OutputFormat.IsHTML || seen[f.Filename] {
+			continue
+		}
+		seen[f.Filename] = true
+
+		file, err := fs.Open(f.Filename)
+		if err != nil {
+			// Not rendered, e.g. a page without a layout.
+			continue
+		}
+		problems, err := lint.CheckA11y(file)
+		file.Close()
+		if err != nil {
+			return count, fmt.Errorf("failed to check %s: %s", f.Filename, err)
+		}
+
+		if len(problems) == 0 {
+			continue
+		}
+		count += len(problems)
+
+		source := "no content file"
+		if f.Page.File != nil && f.Page.File.Filename() != "" {
+			source = f.Page.File.Filename()
+			if rel, err := filepath.Rel(workingDir, source); err == nil {
+				source = filepath.ToSlash(rel)
+			}
+		}
+
+		fmt.Fprintf(w, "%s (%s)\n", f.Page.RelPermalink(), source)
+		for _, p := range problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"github.com/gohugoio/hugo/lint"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var frontMatterSchema string
+
+func init() {
+	checkFrontMatterCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	checkFrontMatterCmd.Flags().StringVar(&frontMatterSchema, "schema", "frontmatter-schema.toml", "schema file (TOML, YAML or JSON) relative to the source")
+	checkCmd.AddCommand(checkFrontMatterCmd)
+}
+
+var checkFrontMatterCmd = &cobra.Command{
+	Use:   "frontmatter",
+	Short: "Validate front matter against a schema",
+	Long: `Validate the front matter of all content files against a schema.
+
+The schema has one table per section or page kind (home, section or page),
+plus "_default" for rules that apply to all content. Section rules override
+kind rules, which override the defaults. Example:
+
+    [_default.title]
+    required = true
+
+    [posts.description]
+    required = true
+    type = "string"
+
+    [posts.categories]
+    type = "array"
+    allowed = ["go", "hugo"]
+
+Supported types are string, bool, int, float, date, array and map.
+The command fails if any problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := InitializeConfig(false, nil)
+		if err != nil {
+			return err
+		}
+
+		ps := c.PathSpec()
+		schemaFilename := ps.AbsPathify(frontMatterSchema)
+
+		b, err := afero.ReadFile(c.Fs.Source, schemaFilename)
+		if err != nil {
+			return newUserError("Unable to read schema:", err)
+		}
+
+		schema, err := lint.ParseFrontMatterSchema(schemaFilename, b)
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		problems, err := lint.CheckFrontMatter(c.Fs.Source, ps.AbsPathify(c.Cfg.GetString("contentDir")), schema)
+		if err != nil {
+			return newSystemError("Error checking front matter:", err)
+		}
+
+		for _, p := range problems {
+			c.Logger.ERROR.Println(p)
+		}
+
+		if len(problems) > 0 {
+			return newSystemErrorF("Found %d front matter problem(s)", len(problems))
+		}
+
+		c.Logger.FEEDBACK.Println("Front matter OK")
+
+		return nil
+	},
+}
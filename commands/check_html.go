@@ -0,0 +1,240 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/lint"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var htmlMaxProblems int
+
+func init() {
+	initHugoBuilderFlags(checkHTMLCmd)
+	checkHTMLCmd.Flags().IntVar(&htmlMaxProblems, "maxProblems", 0, "fail if more than this number of problems are found, -1 to never fail")
+	checkCmd.AddCommand(checkHTMLCmd)
+}
+
+var checkHTMLCmd = &cobra.Command{
+	Use:   "html",
+	Short: "Check the rendered HTML for conformance problems",
+	Long: `Build the site in memory and check the rendered HTML pages for
+problems the browsers silently repair, but that are usually mistakes in the
+templates, e.g. an element left open by a partial:
+
+    doctype              no <!DOCTYPE html>
+    unclosed             an element without an end tag
+    stray-end-tag        an end tag without a matching start tag
+    misnested            an end tag closing an element with open elements inside
+    void-end-tag         an end tag for a void element, e.g. </br>
+    duplicate-attribute  the same attribute set twice on an element
+    duplicate-id         the same id used on more than one element
+
+The problems are listed per page with its content file, followed by the
+problems found in more than one page rendered with the same template, which
+are most likely in the template itself.
+The command fails if more than --maxProblems problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgInit := func(c *commandeer) error {
+			c.Set("renderToMemory", true)
+			return nil
+		}
+
+		c, err := InitializeConfig(false, cfgInit, cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := c.buildSites(); err != nil {
+			return newSystemError("Error building site:", err)
+		}
+
+		results, err := checkHTML(c.Fs.Destination, Hugo.PageOutputFiles(), c.PathSpec().WorkingDir())
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		count := printHTMLProblems(os.Stdout, results)
+
+		if htmlMaxProblems >= 0 && count > htmlMaxProblems {
+			return newSystemErrorF("Found %d HTML problem(s)", count)
+		}
+
+		return nil
+	},
+}
+
+// htmlCheckResult holds the problems found in a published file.
+type htmlCheckResult struct {
+	page     string
+	source   string
+	template string
+	problems []lint.HTMLProblem
+}
+
+// checkHTML checks the HTML files in parallel and returns the files with
+// problems, sorted by filename.
+func checkHTML(fs afero.Fs, files []hugolib.PageOutputFile, workingDir string) ([]htmlCheckResult, error) {
+	var (
+		toCheck []hugolib.PageOutputFile
+		seen    = make(map[string]bool)
+	)
+
+	for _, f := range files {
+		if f.OutputFormat.IsHTML && !seen[f.Filename] {
+			seen[f.Filename] = true
+			toCheck = append(toCheck, f)
+		}
+	}
+
+	sort.SliceStable(toCheck, func(i, j int) bool {
+		return toCheck[i].Filename < toCheck[j].Filename
+	})
+
+	results := make([]htmlCheckResult, len(toCheck))
+	jobs := make(chan int)
+
+	g := &errgroup.Group{}
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		g.Go(func() error {
+			var checkErr error
+			for i := range jobs {
+				if checkErr != nil {
+					// Keep receiving, so sending the jobs does not block.
+					continue
+				}
+				f := toCheck[i]
+				file, err := fs.Open(f.Filename)
+				if err != nil {
+					// Not rendered, e.g. a page without a layout.
+					continue
+				}
+				problems, err := lint.CheckHTML(file)
+				file.Close()
+				if err != nil {
+					checkErr = fmt.Errorf("failed to check %s: %s", f.Filename, err)
+					continue
+				}
+
+				source := "no content file"
+				if f.Page.File != nil && f.Page.File.Filename() != "" {
+					source = f.Page.File.Filename()
+					if rel, err := filepath.Rel(workingDir, source); err == nil {
+						source = filepath.ToSlash(rel)
+					}
+				}
+
+				results[i] = htmlCheckResult{
+					page:     f.Page.RelPermalink(),
+					source:   source,
+					template: f.Template,
+					problems: problems,
+				}
+			}
+			return checkErr
+		})
+	}
+
+	for i := range toCheck {
+		jobs <- i
+	}
+	close(jobs)
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var withProblems []htmlCheckResult
+	for _, r := range results {
+		if len(r.problems) > 0 {
+			withProblems = append(withProblems, r)
+		}
+	}
+
+	return withProblems, nil
+}
+
+// printHTMLProblems writes the problems per page and the problems common to
+// the pages rendered with the same template to w. It returns the number of
+// problems.
+func printHTMLProblems(w io.Writer, results []htmlCheckResult) int {
+	type templateProblem struct {
+		template, rule, message string
+	}
+
+	var (
+		count  int
+		common = make(map[templateProblem]int)
+	)
+
+	for _, r := range results {
+		count += len(r.problems)
+
+		fmt.Fprintf(w, "%s (%s)\n", r.page, r.source)
+
+		seen := make(map[templateProblem]bool)
+		for _, p := range r.problems {
+			fmt.Fprintf(w, "  %s\n", p)
+
+			tp := templateProblem{template: r.template, rule: p.Rule, message: p.Message}
+			if r.template != "" && !seen[tp] {
+				seen[tp] = true
+				common[tp]++
+			}
+		}
+	}
+
+	var templateProblems []templateProblem
+	for tp, n := range common {
+		if n > 1 {
+			templateProblems = append(templateProblems, tp)
+		}
+	}
+
+	if len(templateProblems) == 0 {
+		return count
+	}
+
+	sort.Slice(templateProblems, func(i, j int) bool {
+		a, b := templateProblems[i], templateProblems[j]
+		if a.template != b.template {
+			return a.template < b.template
+		}
+		if a.rule != b.rule {
+			return a.rule < b.rule
+		}
+		return a.message < b.message
+	})
+
+	fmt.Fprintln(w, "\nCommon problems by template:")
+	for i, tp := range templateProblems {
+		if i == 0 || tp.template != templateProblems[i-1].template {
+			fmt.Fprintln(w, tp.template)
+		}
+		fmt.Fprintf(w, "  %s: %s (%d pages)\n", tp.rule, tp.message, common[tp])
+	}
+
+	return count
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/lint"
+	"github.com/gohugoio/hugo/output"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintHTMLProblems(t *testing.T) {
+	assert := require.New(t)
+
+	unclosed := lint.HTMLProblem{Rule: lint.HTMLRuleUnclosed, Line: 3, Message: "<div> is never closed"}
+	stray := lint.HTMLProblem{Rule: lint.HTMLRuleStrayEndTag, Line: 9, Message: "</p> has no matching start tag"}
+
+	results := []htmlCheckResult{
+		{page: "/", source: "no content file", template: "index.html", problems: []lint.HTMLProblem{unclosed}},
+		{page: "/posts/a/", source: "content/posts/a.md", template: "_default/single.html", problems: []lint.HTMLProblem{unclosed, stray}},
+		{page: "/posts/b/", source: "content/posts/b.md", template: "_default/single.html", problems: []lint.HTMLProblem{unclosed, unclosed}},
+	}
+
+	var out bytes.Buffer
+	assert.Equal(5, printHTMLProblems(&out, results))
+	assert.Equal(`/ (no content file)
+  3: unclosed: <div> is never closed
+/posts/a/ (content/posts/a.md)
+  3: unclosed: <div> is never closed
+  9: stray-end-tag: </p> has no matching start tag
+/posts/b/ (content/posts/b.md)
+  3: unclosed: <div> is never closed
+  3: unclosed: <div> is never closed
+
+Common problems by template:
+_default/single.html
+  unclosed: <div> is never closed (2 pages)
+`, out.String())
+
+	out.Reset()
+	assert.Equal(1, printHTMLProblems(&out, results[:1]))
+	assert.NotContains(out.String(), "Common problems")
+}
+
+type failingReadFs struct {
+	afero.Fs
+}
+
+func (fs failingReadFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return failingReadFile{f}, nil
+}
+
+type failingReadFile struct {
+	afero.File
+}
+
+func (f failingReadFile) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestCheckHTMLError(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+
+	// More files than workers, so all of them fail before all are sent.
+	var files []hugolib.PageOutputFile
+	for i := 0; i < 2*runtime.NumCPU()+2; i++ {
+		filename := fmt.Sprintf("/public/p%d/index.html", i)
+		assert.NoError(afero.WriteFile(fs, filename, []byte("<p>"), 0755))
+		files = append(files, hugolib.PageOutputFile{OutputFormat: output.HTMLFormat, Filename: filename})
+	}
+
+	_, err := checkHTML(failingReadFs{fs}, files, "/")
+	assert.Error(err)
+	assert.Contains(err.Error(), "read failed")
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/lint"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proseLinters     []string
+	proseMaxProblems int
+)
+
+func init() {
+	checkProseCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	checkProseCmd.Flags().StringSliceVar(&proseLinters, "linter", nil, "only run the prose linters with these names")
+	checkProseCmd.Flags().IntVar(&proseMaxProblems, "maxProblems", 0, "fail if more than this number of problems are found, -1 to never fail")
+	checkCmd.AddCommand(checkProseCmd)
+}
+
+var checkProseCmd = &cobra.Command{
+	Use:   "prose",
+	Short: "Run spell checkers and prose linters over the content",
+	Long: `Run the spell checkers and prose linters set in the site config over the
+content files and list the problems found per page, e.g.:
+
+    [[proseLinters]]
+    name = "vale"
+
+    [[proseLinters]]
+    name = "codespell"
+    args = ["--ignore-words", "words.txt"]
+
+Other linters need a command and, if their output is not in the
+file:line:col: message format, a format regexp with the named groups file,
+line, col and message. The content files are appended to the arguments.
+
+The command fails if more than --maxProblems problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := InitializeConfig(false, nil)
+		if err != nil {
+			return err
+		}
+
+		linters, err := lint.DecodeProseLinters(c.Cfg.Get("proseLinters"))
+		if err != nil {
+			return newUserError(err)
+		}
+		if len(proseLinters) > 0 {
+			var filtered []lint.ProseLinter
+			for _, l := range linters {
+				if helpers.InStringArray(proseLinters, l.Name) {
+					filtered = append(filtered, l)
+				}
+			}
+			linters = filtered
+		}
+		if len(linters) == 0 {
+			return newUserError("no prose linters configured, see proseLinters in the site config")
+		}
+
+		sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+		if err != nil {
+			return newSystemError("Error creating sites", err)
+		}
+
+		if err := sites.Build(hugolib.BuildCfg{SkipRender: true}); err != nil {
+			return newSystemError("Error Processing Source Content", err)
+		}
+
+		pages := make(map[string]string)
+		var filenames []string
+		for _, p := range sites.Pages() {
+			if p.File == nil || p.File.Filename() == "" {
+				continue
+			}
+			filename := p.File.Filename()
+			if _, found := pages[filename]; !found {
+				filenames = append(filenames, filename)
+			}
+			pages[filename] = p.RelPermalink()
+		}
+		sort.Strings(filenames)
+
+		var problems []lint.ProseProblem
+		for _, l := range linters {
+			lp, err := l.Run(c.PathSpec().WorkingDir(), filenames)
+			if err != nil {
+				return newSystemError(err)
+			}
+			problems = append(problems, lp...)
+		}
+
+		printProseProblems(os.Stdout, problems, pages, c.PathSpec().WorkingDir())
+
+		if proseMaxProblems >= 0 && len(problems) > proseMaxProblems {
+			return newSystemErrorF("Found %d prose problem(s)", len(problems))
+		}
+
+		return nil
+	},
+}
+
+// printProseProblems prints the problems grouped by page, given the page
+// permalinks keyed by content filename.
+func printProseProblems(w io.Writer, problems []lint.ProseProblem, pages map[string]string, workingDir string) {
+	byFile := make(map[string][]lint.ProseProblem)
+	var filenames []string
+	for _, p := range problems {
+		if _, found := byFile[p.Filename]; !found {
+			filenames = append(filenames, p.Filename)
+		}
+		byFile[p.Filename] = append(byFile[p.Filename], p)
+	}
+
+	sort.Slice(filenames, func(i, j int) bool {
+		pi, pj := pages[filenames[i]], pages[filenames[j]]
+		if pi != pj {
+			// Files not mapped to a page last.
+			return pj == "" || (pi != "" && pi < pj)
+		}
+		return filenames[i] < filenames[j]
+	})
+
+	for _, filename := range filenames {
+		rel := filename
+		if r, err := filepath.Rel(workingDir, filename); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+
+		fp := byFile[filename]
+		sort.SliceStable(fp, func(i, j int) bool {
+			if fp[i].Line != fp[j].Line {
+				return fp[i].Line < fp[j].Line
+			}
+			return fp[i].Column < fp[j].Column
+		})
+
+		if permalink, found := pages[filename]; found {
+			fmt.Fprintf(w, "%s (%s)\n", permalink, rel)
+		} else {
+			fmt.Fprintln(w, rel)
+		}
+		for _, p := range fp {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/lint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintProseProblems(t *testing.T) {
+	assert := require.New(t)
+
+	workingDir := filepath.FromSlash("/work")
+	a := filepath.FromSlash("/work/content/posts/a.md")
+	b := filepath.FromSlash("/work/content/about.md")
+	c := filepath.FromSlash("/work/content/orphan.md")
+
+	problems := []lint.ProseProblem{
+		{Filename: a, Line: 9, Column: 1, Linter: "vale", Message: "second"},
+		{Filename: c, Line: 1, Linter: "codespell", Message: "teh ==> the"},
+		{Filename: a, Line: 2, Linter: "codespell", Message: "first"},
+		{Filename: b, Line: 3, Column: 5, Linter: "vale", Message: "about"},
+	}
+
+	pages := map[string]string{
+		a: "/posts/a/",
+		b: "/about/",
+	}
+
+	var out bytes.Buffer
+	printProseProblems(&out, problems, pages, workingDir)
+
+	assert.Equal(`/about/ (content/about.md)
+  3:5 vale: about
+/posts/a/ (content/posts/a.md)
+  2 codespell: first
+  9:1 vale: second
+content/orphan.md
+  1 codespell: teh ==> the
+`, out.String())
+}
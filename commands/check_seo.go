@@ -0,0 +1,197 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/lint"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seoMaxProblems int
+	seoFormat      string
+)
+
+func init() {
+	initHugoBuilderFlags(checkSEOCmd)
+	checkSEOCmd.Flags().IntVar(&seoMaxProblems, "maxProblems", 0, "fail if more than this number of problems are found, -1 to never fail")
+	checkSEOCmd.Flags().StringVar(&seoFormat, "format", "text", "output format, text or json")
+	checkCmd.AddCommand(checkSEOCmd)
+}
+
+var checkSEOCmd = &cobra.Command{
+	Use:   "seo",
+	Short: "Check the rendered HTML for missing or too long metadata",
+	Long: `Build the site in memory and check the metadata in the head of the
+rendered HTML pages:
+
+    title               no title
+    title-length        a title longer than maxTitleLength, 60 by default
+    description         no description meta element
+    description-length  a description longer than maxDescriptionLength, 160 by default
+    canonical           no canonical link
+    og-image            no og:image meta element
+    twitter-image       no twitter:image meta element
+
+The thresholds and the rules to skip can be set in the seo site config, and
+be overridden per section:
+
+    [seo]
+    disable = ["twitter-image"]
+    [seo.sections.blog]
+    maxTitleLength = 70
+
+The problems are listed per page with its content file, or as JSON with
+--format json. The command fails if more than --maxProblems problems are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if seoFormat != "text" && seoFormat != "json" {
+			return newUserError(fmt.Sprintf("unsupported format %q, must be text or json", seoFormat))
+		}
+
+		cfgInit := func(c *commandeer) error {
+			c.Set("renderToMemory", true)
+			return nil
+		}
+
+		c, err := InitializeConfig(false, cfgInit, cmd)
+		if err != nil {
+			return err
+		}
+
+		conf, err := lint.DecodeSEOConfig(c.Cfg.Get("seo"))
+		if err != nil {
+			return newUserError(err)
+		}
+
+		if err := c.buildSites(); err != nil {
+			return newSystemError("Error building site:", err)
+		}
+
+		reports, err := checkSEO(c.Fs.Destination, Hugo.PageOutputFiles(), conf, c.PathSpec().WorkingDir())
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		if err := printSEOReports(os.Stdout, reports, seoFormat); err != nil {
+			return newSystemError(err)
+		}
+
+		count := 0
+		for _, r := range reports {
+			count += len(r.Problems)
+		}
+
+		if seoMaxProblems >= 0 && count > seoMaxProblems {
+			return newSystemErrorF("Found %d SEO problem(s)", count)
+		}
+
+		return nil
+	},
+}
+
+// seoReport holds the SEO problems found in a page.
+type seoReport struct {
+	Page     string            `json:"page"`
+	Source   string            `json:"source,omitempty"`
+	Section  string            `json:"section"`
+	Problems []lint.SEOProblem `json:"problems"`
+}
+
+// checkSEO checks the HTML files with the rules for their section and
+// returns the pages with problems.
+func checkSEO(fs afero.Fs, files []hugolib.PageOutputFile, conf lint.SEOConfig, workingDir string) ([]seoReport, error) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+
+	var (
+		reports []seoReport
+		seen    = make(map[string]bool)
+	)
+
+	for _, f := range files {
+		if !f.OutputFormat.IsHTML || f.Page.Kind == "404" || seen[f.Filename] {
+			continue
+		}
+		seen[f.Filename] = true
+
+		file, err := fs.Open(f.Filename)
+		if err != nil {
+			// Not rendered, e.g. a page without a layout.
+			continue
+		}
+		problems, err := lint.CheckSEO(file, conf.ForSection(f.Page.Section()))
+		file.Close()
+		if err != nil {
+			return reports, fmt.Errorf("failed to check %s: %s", f.Filename, err)
+		}
+
+		if len(problems) == 0 {
+			continue
+		}
+
+		var source string
+		if f.Page.File != nil && f.Page.File.Filename() != "" {
+			source = f.Page.File.Filename()
+			if rel, err := filepath.Rel(workingDir, source); err == nil {
+				source = filepath.ToSlash(rel)
+			}
+		}
+
+		reports = append(reports, seoReport{
+			Page:     f.Page.RelPermalink(),
+			Source:   source,
+			Section:  f.Page.Section(),
+			Problems: problems,
+		})
+	}
+
+	return reports, nil
+}
+
+func printSEOReports(w io.Writer, reports []seoReport, format string) error {
+	if format == "json" {
+		if reports == nil {
+			reports = []seoReport{}
+		}
+		b, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	for _, r := range reports {
+		source := r.Source
+		if source == "" {
+			source = "no content file"
+		}
+		fmt.Fprintf(w, "%s (%s)\n", r.Page, source)
+		for _, p := range r.Problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+
+	return nil
+}
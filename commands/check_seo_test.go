@@ -0,0 +1,60 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gohugoio/hugo/lint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintSEOReports(t *testing.T) {
+	assert := require.New(t)
+
+	reports := []seoReport{
+		{Page: "/", Section: "", Problems: []lint.SEOProblem{{Rule: lint.SEORuleCanonical, Message: "the page has no canonical URL"}}},
+		{Page: "/blog/a/", Source: "content/blog/a.md", Section: "blog", Problems: []lint.SEOProblem{{Rule: lint.SEORuleTitle, Message: "the page has no title"}}},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(printSEOReports(&out, reports, "text"))
+	assert.Equal(`/ (no content file)
+  canonical: the page has no canonical URL
+/blog/a/ (content/blog/a.md)
+  title: the page has no title
+`, out.String())
+
+	out.Reset()
+	assert.NoError(printSEOReports(&out, reports[1:], "json"))
+	assert.Equal(`[
+  {
+    "page": "/blog/a/",
+    "source": "content/blog/a.md",
+    "section": "blog",
+    "problems": [
+      {
+        "rule": "title",
+        "message": "the page has no title"
+      }
+    ]
+  }
+]
+`, out.String())
+
+	out.Reset()
+	assert.NoError(printSEOReports(&out, nil, "json"))
+	assert.Equal("[]\n", out.String())
+}
@@ -0,0 +1,312 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/lint"
+	src "github.com/gohugoio/hugo/source"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unusedExclude  []string
+	unusedMaxFiles int
+)
+
+// The files usually requested without being referenced, e.g. by browsers
+// and hosting services.
+var defaultUnusedExcludes = []string{
+	"favicon.ico",
+	"robots.txt",
+	"CNAME",
+	"_redirects",
+	"_headers",
+	".well-known/",
+}
+
+func init() {
+	initHugoBuilderFlags(checkUnusedCmd)
+	checkUnusedCmd.Flags().StringSliceVar(&unusedExclude, "exclude", nil, "patterns in .gitignore syntax of the files to leave out, relative to the project dir")
+	checkUnusedCmd.Flags().IntVar(&unusedMaxFiles, "maxUnused", 0, "fail if more than this number of unused files are found, -1 to never fail")
+	checkCmd.AddCommand(checkUnusedCmd)
+}
+
+var checkUnusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "List the bundle resources and static files never referenced",
+	Long: `Build the site in memory and list the page bundle resources and the
+static files not referenced by any of the rendered files, e.g. by a link, an
+image, a stylesheet, a feed or a JSON file.
+
+A resource is also in use if an image processed from it is referenced.
+Resources only used in templates, e.g. with .Content, can not be detected, so
+check the list before removing files.
+
+Leave files out with --exclude, in .gitignore syntax and relative to the
+project dir, e.g.:
+
+    hugo check unused --exclude "*.pdf" --exclude "static/downloads/"
+
+The files ` + strings.Join(defaultUnusedExcludes, ", ") + ` are always
+left out. The command fails if more than --maxUnused unused files are found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exclude, err := src.ParseIgnorePatterns(strings.NewReader(strings.Join(append(defaultUnusedExcludes, unusedExclude...), "\n")))
+		if err != nil {
+			return newUserError("Invalid --exclude:", err)
+		}
+
+		cfgInit := func(c *commandeer) error {
+			c.Set("renderToMemory", true)
+			return nil
+		}
+
+		c, err := InitializeConfig(false, cfgInit, cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := c.buildSites(); err != nil {
+			return newSystemError("Error building site:", err)
+		}
+
+		ps := c.PathSpec()
+
+		var files []unusedFile
+		for _, r := range Hugo.PageResourceFiles() {
+			files = append(files, unusedFile{URL: r.Resource.RelPermalink(), Filename: r.SourceFilename})
+		}
+
+		static, err := staticFiles(c.Fs.Source, c.staticDirsConfig, ps.BasePath)
+		if err != nil {
+			return newSystemError(err)
+		}
+		files = append(files, static...)
+
+		unused, err := findUnusedFiles(c.Fs.Destination, ps.PublishDir, ps.BaseURL.URL(), files)
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		count := printUnusedFiles(os.Stdout, unused, exclude, ps.WorkingDir())
+
+		if unusedMaxFiles >= 0 && count > unusedMaxFiles {
+			return newSystemErrorF("Found %d unused file(s)", count)
+		}
+
+		return nil
+	},
+}
+
+// unusedFile is a published page resource or static file.
+type unusedFile struct {
+	// The URL path, including the base path.
+	URL string
+
+	// The absolute source filename.
+	Filename string
+
+	static bool
+}
+
+// staticFiles returns the files in the static dirs. A file in a project
+// static dir overrides the file with the same path in the theme.
+func staticFiles(fs afero.Fs, dirsConfig []*src.Dirs, basePath string) ([]unusedFile, error) {
+	byURL := make(map[string]unusedFile)
+
+	for _, dirs := range dirsConfig {
+		urlPrefix := "/" + strings.Trim(basePath, "/")
+		if dirs.Language != nil {
+			// Multihost setup.
+			urlPrefix = path.Join(urlPrefix, dirs.Language.Lang)
+		}
+
+		for _, dir := range dirs.AbsStaticDirs {
+			err := helpers.SymbolicWalk(fs, dir, func(filename string, fi os.FileInfo, err error) error {
+				if err != nil || fi.IsDir() {
+					return nil
+				}
+				rel, err := filepath.Rel(dir, filename)
+				if err != nil {
+					return nil
+				}
+				u := path.Join(urlPrefix, filepath.ToSlash(rel))
+				byURL[u] = unusedFile{URL: u, Filename: filename, static: true}
+				return nil
+			})
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	files := make([]unusedFile, 0, len(byURL))
+	for _, f := range byURL {
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// The extensions of the rendered files to look for references in.
+var referencingExtensions = map[string]bool{
+	".html": true, ".htm": true, ".xml": true, ".css": true, ".js": true,
+	".json": true, ".svg": true, ".txt": true, ".webmanifest": true,
+}
+
+// processedImageRe matches the base name of a processed image, e.g.
+// sunset_hu<hash>_<size>_100x0_resize_box_2.jpg, where the first group is the
+// base name of the original without the extension.
+var processedImageRe = regexp.MustCompile(`^(.+?)_hu[0-9a-f]{32}_\d+_`)
+
+// findUnusedFiles returns the files not referenced by any of the files
+// rendered to publishDir, sorted by filename.
+func findUnusedFiles(fs afero.Fs, publishDir string, baseURL *url.URL, files []unusedFile) ([]unusedFile, error) {
+	var (
+		refs = make(map[string]bool)
+
+		// The URLs of the processed images without the hash and the
+		// processing options, i.e. the URLs of the originals without the
+		// extension.
+		processed = make(map[string]bool)
+	)
+
+	err := afero.Walk(fs, publishDir, func(filename string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || !referencingExtensions[strings.ToLower(filepath.Ext(filename))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(publishDir, filename)
+		if err != nil {
+			return nil
+		}
+
+		f, err := fs.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		ext := strings.ToLower(filepath.Ext(filename))
+		found, err := lint.ExtractReferences(f, ext == ".html" || ext == ".htm")
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", filename, err)
+		}
+
+		fileURL := *baseURL
+		fileURL.Path = path.Join("/", baseURL.Path, filepath.ToSlash(rel))
+
+		for _, ref := range found {
+			p := resolveReference(&fileURL, ref)
+			if p == "" {
+				continue
+			}
+			refs[p] = true
+			if m := processedImageRe.FindStringSubmatch(path.Base(p)); m != nil {
+				processed[path.Join(path.Dir(p), m[1])] = true
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []unusedFile
+	for _, f := range files {
+		u, err := url.Parse(f.URL)
+		if err != nil {
+			continue
+		}
+		p := u.Path
+		if refs[p] || processed[strings.TrimSuffix(p, path.Ext(p))] {
+			continue
+		}
+		unused = append(unused, f)
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].Filename < unused[j].Filename
+	})
+
+	return unused, nil
+}
+
+// resolveReference resolves the reference in the file with the given URL
+// and returns its path. It returns an empty string for references to other
+// sites and for other schemes, e.g. mailto.
+func resolveReference(fileURL *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+
+	if u.Host != "" && u.Host != fileURL.Host {
+		return ""
+	}
+
+	return fileURL.ResolveReference(u).Path
+}
+
+// printUnusedFiles writes the unused files not excluded to w, relative to
+// the working dir, and returns their number.
+func printUnusedFiles(w io.Writer, files []unusedFile, exclude *src.IgnorePatterns, workingDir string) int {
+	var resources, static []string
+
+	for _, f := range files {
+		filename := f.Filename
+		if rel, err := filepath.Rel(workingDir, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+		filename = filepath.ToSlash(filename)
+
+		if exclude.MatchWithParents(filename, false) {
+			continue
+		}
+
+		line := fmt.Sprintf("  %s (%s)", filename, f.URL)
+		if f.static {
+			static = append(static, line)
+		} else {
+			resources = append(resources, line)
+		}
+	}
+
+	if len(resources) > 0 {
+		fmt.Fprintln(w, "Unused page resources:")
+		fmt.Fprintln(w, strings.Join(resources, "\n"))
+	}
+	if len(static) > 0 {
+		fmt.Fprintln(w, "Unused static files:")
+		fmt.Fprintln(w, strings.Join(static, "\n"))
+	}
+
+	return len(resources) + len(static)
+}
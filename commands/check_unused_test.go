@@ -0,0 +1,82 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	src "github.com/gohugoio/hugo/source"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUnusedFiles(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	publishDir := filepath.FromSlash("/work/public")
+
+	write := func(filename, content string) {
+		assert.NoError(afero.WriteFile(fs, filepath.Join(publishDir, filepath.FromSlash(filename)), []byte(content), 0755))
+	}
+
+	write("index.html", `<link rel="stylesheet" href="/blog/css/main.css"><a href="posts/p1/">P1</a><a href="mailto:jo@example.com">Mail</a><img src="https://other.com/blog/images/other.png"><img src="/blog/img_hub/logo.png">`)
+	write("posts/p1/index.html", `<img src="a.jpg"><img src="sunset_hu0123456789abcdef0123456789abcdef_1000_100x0_resize_box_2.jpg"><a href="http://example.com/blog/posts/p1/doc%20one.pdf">Doc</a>`)
+	write("css/main.css", `body { background: url(../images/bg.png) }`)
+	write("index.json", `{"logo": "\/blog\/images\/logo.svg"}`)
+	write("posts/p1/a.jpg", "")
+
+	baseURL, _ := url.Parse("http://example.com/blog/")
+
+	files := []unusedFile{
+		{URL: "/blog/posts/p1/a.jpg", Filename: "/work/content/posts/p1/a.jpg"},
+		{URL: "/blog/posts/p1/b.jpg", Filename: "/work/content/posts/p1/b.jpg"},
+		{URL: "/blog/posts/p1/sunset.jpg", Filename: "/work/content/posts/p1/sunset.jpg"},
+		{URL: "/blog/posts/p1/doc%20one.pdf", Filename: "/work/content/posts/p1/doc one.pdf"},
+		{URL: "/blog/css/main.css", Filename: "/work/static/css/main.css", static: true},
+		{URL: "/blog/images/bg.png", Filename: "/work/static/images/bg.png", static: true},
+		{URL: "/blog/images/logo.svg", Filename: "/work/static/images/logo.svg", static: true},
+		{URL: "/blog/images/other.png", Filename: "/work/static/images/other.png", static: true},
+		{URL: "/blog/img.png", Filename: "/work/static/img.png", static: true},
+		{URL: "/blog/img_hub/logo.png", Filename: "/work/static/img_hub/logo.png", static: true},
+		{URL: "/blog/favicon.ico", Filename: "/work/static/favicon.ico", static: true},
+		{URL: "/blog/downloads/a.zip", Filename: "/work/static/downloads/a.zip", static: true},
+	}
+
+	unused, err := findUnusedFiles(fs, publishDir, baseURL, files)
+	assert.NoError(err)
+
+	var urls []string
+	for _, f := range unused {
+		urls = append(urls, f.URL)
+	}
+	assert.Equal([]string{"/blog/posts/p1/b.jpg", "/blog/downloads/a.zip", "/blog/favicon.ico", "/blog/images/other.png", "/blog/img.png"}, urls)
+
+	exclude, err := src.ParseIgnorePatterns(strings.NewReader(strings.Join(append(defaultUnusedExcludes, "static/downloads/"), "\n")))
+	assert.NoError(err)
+
+	var out bytes.Buffer
+	count := printUnusedFiles(&out, unused, exclude, "/work")
+	assert.Equal(3, count)
+	assert.Equal(`Unused page resources:
+  content/posts/p1/b.jpg (/blog/posts/p1/b.jpg)
+Unused static files:
+  static/images/other.png (/blog/images/other.png)
+  static/img.png (/blog/img.png)
+`, out.String())
+}
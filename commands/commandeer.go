@@ -19,6 +19,8 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugofs"
 	src "github.com/gohugoio/hugo/source"
+	"github.com/gohugoio/hugo/watcher"
+	"github.com/spf13/cobra"
 )
 
 type commandeer struct {
@@ -32,6 +34,16 @@ type commandeer struct {
 	languages   helpers.Languages
 
 	configured bool
+
+	// The config init func and the commands with flags, used when the config
+	// is reloaded.
+	doWithCommandeer func(c *commandeer) error
+	subCmdVs         []*cobra.Command
+
+	// The watcher of the content, layout etc. dirs and the dirs added to it
+	// when started, set in server and watch mode.
+	contentWatcher *watcher.Batcher
+	watchedDirs    []string
 }
 
 func (c *commandeer) Set(key string, value interface{}) {
@@ -0,0 +1,384 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// The kinds of dynamic completions, see completeCmd.
+const (
+	// The content dirs, e.g. posts/, for hugo new.
+	completeContent = "content"
+
+	// The config keys, e.g. baseurl=, for config overrides.
+	completeConfig = "config"
+)
+
+var (
+	// The dynamic completions of command arguments, keyed by command path.
+	argCompletions = map[string]string{
+		"hugo new": completeContent,
+	}
+
+	// The dynamic completions of flag values, keyed by flag name.
+	flagCompletions = map[string]string{
+		"set": completeConfig,
+	}
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script for Hugo",
+	Long: `Generate the shell completion script for Hugo for the given shell and
+write it to stdout. Besides the commands and flags, the content
+directories are completed for hugo new and the config keys for --set.
+
+Bash:
+
+	$ source <(hugo completion bash)
+
+Zsh:
+
+	$ source <(hugo completion zsh)
+
+Fish:
+
+	$ hugo completion fish > ~/.config/fish/completions/hugo.fish
+
+PowerShell:
+
+	PS> hugo completion powershell | Out-String | Invoke-Expression`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return newUserError("the shell needs to be provided, one of bash, zsh, fish or powershell")
+		}
+		return genCompletion(cmd.Root(), os.Stdout, args[0])
+	},
+}
+
+var completeCmd = &cobra.Command{
+	Use:    "__complete [content|config] [prefix]",
+	Short:  "Print the dynamic completions used by the shell completion scripts",
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return newUserError("the completion kind needs to be provided")
+		}
+
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+
+		cfg, err := hugolib.LoadConfig(hugofs.Os, source, cfgFile)
+		if err != nil {
+			// No site, nothing to complete.
+			return nil
+		}
+
+		var candidates []string
+		switch args[0] {
+		case completeContent:
+			contentDir := filepath.Join(cfg.GetString("workingDir"), cfg.GetString("contentDir"))
+			candidates = completeContentDirs(hugofs.Os, contentDir, prefix)
+		case completeConfig:
+			candidates = completeConfigKeys(cfg.AllKeys(), prefix)
+		default:
+			return newUserError(fmt.Sprintf("unknown completion kind %q", args[0]))
+		}
+
+		for _, c := range candidates {
+			fmt.Println(c)
+		}
+
+		return nil
+	},
+}
+
+// completeContentDirs returns the dirs below the content dir starting with
+// the given prefix, e.g. posts/ and projects/ for p.
+func completeContentDirs(fs afero.Fs, contentDir, prefix string) []string {
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i != -1 {
+		dir = prefix[:i+1]
+	}
+
+	fis, err := afero.ReadDir(fs, filepath.Join(contentDir, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, fi := range fis {
+		name := dir + fi.Name() + "/"
+		if fi.IsDir() && !strings.HasPrefix(fi.Name(), ".") && strings.HasPrefix(name, prefix) {
+			dirs = append(dirs, name)
+		}
+	}
+
+	return dirs
+}
+
+// completeConfigKeys returns the config keys starting with the given prefix
+// as key=.
+func completeConfigKeys(keys []string, prefix string) []string {
+	var candidates []string
+	for _, key := range keys {
+		if hiddenConfigKeys[strings.SplitN(key, ".", 2)[0]] {
+			continue
+		}
+		if strings.HasPrefix(key, strings.ToLower(prefix)) {
+			candidates = append(candidates, key+"=")
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+func genCompletion(root *cobra.Command, w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return genBashCompletion(root, w)
+	case "zsh":
+		// The Bash completion works in zsh with bashcompinit, which
+		// covers the flags and the dynamic completions.
+		fmt.Fprintln(w, "#compdef hugo")
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		return genBashCompletion(root, w)
+	case "fish":
+		return genFishCompletion(root, w)
+	case "powershell":
+		return genPowerShellCompletion(root, w)
+	}
+	return newUserError(fmt.Sprintf("unsupported shell %q, must be one of bash, zsh, fish or powershell", shell))
+}
+
+const bashCompletionFuncs = `__hugo_complete()
+{
+    local out
+    out=$(hugo __complete "$1" "${cur}" 2>/dev/null) || return
+    COMPREPLY=( $(compgen -W "${out}" -- "${cur}") )
+    if [[ $(type -t compopt) = "builtin" && ( ${COMPREPLY[0]} == */ || ${COMPREPLY[0]} == *= ) ]]; then
+        compopt -o nospace
+    fi
+}
+
+__hugo_complete_content()
+{
+    __hugo_complete content
+}
+
+__hugo_complete_config()
+{
+    __hugo_complete config
+}
+`
+
+func genBashCompletion(root *cobra.Command, w io.Writer) error {
+	var funcs bytes.Buffer
+	funcs.WriteString(bashCompletionFuncs)
+	funcs.WriteString("\n__custom_func()\n{\n    case ${last_command} in\n")
+	for _, path := range sortedKeys(argCompletions) {
+		fmt.Fprintf(&funcs, "        %s)\n            __hugo_complete_%s\n            ;;\n", strings.Replace(path, " ", "_", -1), argCompletions[path])
+	}
+	funcs.WriteString("    esac\n}\n")
+
+	walkCommands(root, func(c *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if kind, found := flagCompletions[f.Name]; found {
+					flags.SetAnnotation(f.Name, cobra.BashCompCustom, []string{"__hugo_complete_" + kind})
+				}
+			})
+		}
+	})
+
+	root.BashCompletionFunction = funcs.String()
+
+	return root.GenBashCompletion(w)
+}
+
+const fishCompletionFuncs = `function __hugo_using_command
+    set -l path
+    for w in (commandline -opc)[2..-1]
+        switch $w
+            case '-*'
+            case '*'
+                set path $path $w
+        end
+    end
+    test "$path" = "$argv"
+end
+
+`
+
+func genFishCompletion(root *cobra.Command, w io.Writer) error {
+	var b bytes.Buffer
+	b.WriteString(fishCompletionFuncs)
+
+	walkCommands(root, func(c *cobra.Command) {
+		path := strings.TrimSpace(strings.TrimPrefix(c.CommandPath(), root.Name()))
+		cond := fmt.Sprintf("-n '__hugo_using_command %s'", path)
+
+		for _, sub := range c.Commands() {
+			if !sub.IsAvailableCommand() {
+				continue
+			}
+			fmt.Fprintf(&b, "complete -c hugo -f %s -a %s -d %s\n", cond, fishQuote(sub.Name()), fishQuote(sub.Short))
+		}
+
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			fmt.Fprintf(&b, "complete -c hugo %s -l %s", cond, f.Name)
+			if f.Shorthand != "" {
+				fmt.Fprintf(&b, " -s %s", f.Shorthand)
+			}
+			if f.Value.Type() != "bool" {
+				b.WriteString(" -r")
+			}
+			if kind, found := flagCompletions[f.Name]; found {
+				fmt.Fprintf(&b, " -f -a '(hugo __complete %s (commandline -ct))'", kind)
+			}
+			fmt.Fprintf(&b, " -d %s\n", fishQuote(f.Usage))
+		})
+
+		if kind, found := argCompletions[c.CommandPath()]; found {
+			fmt.Fprintf(&b, "complete -c hugo -f %s -a '(hugo __complete %s (commandline -ct))'\n", cond, kind)
+		}
+	})
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
+
+func genPowerShellCompletion(root *cobra.Command, w io.Writer) error {
+	var b bytes.Buffer
+
+	b.WriteString("Register-ArgumentCompleter -Native -CommandName 'hugo' -ScriptBlock {\n")
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	b.WriteString("    $commands = @{\n")
+	walkCommands(root, func(c *cobra.Command) {
+		var words []string
+		for _, sub := range c.Commands() {
+			if sub.IsAvailableCommand() {
+				words = append(words, sub.Name())
+			}
+		}
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			words = append(words, "--"+f.Name)
+		})
+		for i, word := range words {
+			words[i] = psQuote(word)
+		}
+		fmt.Fprintf(&b, "        %s = @(%s)\n", psQuote(c.CommandPath()), strings.Join(words, ", "))
+	})
+	b.WriteString("    }\n")
+
+	b.WriteString("    $argCompletions = @{\n")
+	for _, path := range sortedKeys(argCompletions) {
+		fmt.Fprintf(&b, "        %s = %s\n", psQuote(path), psQuote(argCompletions[path]))
+	}
+	b.WriteString("    }\n")
+
+	b.WriteString("    $flagCompletions = @{\n")
+	for _, name := range sortedKeys(flagCompletions) {
+		fmt.Fprintf(&b, "        %s = %s\n", psQuote("--"+name), psQuote(flagCompletions[name]))
+	}
+	b.WriteString("    }\n")
+
+	b.WriteString(`
+    $path = 'hugo'
+    $prev = ''
+    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {
+        $word = $element.ToString()
+        if ($element.Extent.StartOffset -ge $cursorPosition) { break }
+        if ($word -eq $wordToComplete -and $element.Extent.EndOffset -ge $cursorPosition) { break }
+        if ($commands.ContainsKey("$path $word")) { $path = "$path $word" }
+        $prev = $word
+    }
+
+    $candidates = @()
+    if ($flagCompletions.ContainsKey($prev)) {
+        $candidates = @(hugo __complete $flagCompletions[$prev] $wordToComplete)
+    } else {
+        $candidates = $commands[$path]
+        if ($argCompletions.ContainsKey($path) -and -not $wordToComplete.StartsWith('-')) {
+            $candidates += @(hugo __complete $argCompletions[$path] $wordToComplete)
+        }
+    }
+
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+func psQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// walkCommands calls fn for the command and all its available
+// subcommands.
+func walkCommands(c *cobra.Command, fn func(c *cobra.Command)) {
+	fn(c)
+	for _, sub := range c.Commands() {
+		if sub.IsAvailableCommand() {
+			walkCommands(sub, fn)
+		}
+	}
+}
+
+// visitCommandFlags calls fn for the visible local and inherited flags of
+// the command.
+func visitCommandFlags(c *cobra.Command, fn func(f *pflag.Flag)) {
+	seen := make(map[string]bool)
+	visit := func(f *pflag.Flag) {
+		if f.Hidden || seen[f.Name] {
+			return
+		}
+		seen[f.Name] = true
+		fn(f)
+	}
+	c.LocalFlags().VisitAll(visit)
+	c.InheritedFlags().VisitAll(visit)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
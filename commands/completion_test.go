@@ -0,0 +1,122 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompletionTestCommands() *cobra.Command {
+	root := &cobra.Command{Use: "hugo"}
+	root.PersistentFlags().String("config", "", "config file")
+	root.PersistentFlags().String("set", "", "set a config value")
+
+	newCmd := &cobra.Command{Use: "new", Short: "Create new content", Run: func(cmd *cobra.Command, args []string) {}}
+	newCmd.Flags().StringP("kind", "k", "", "content type to create")
+	newCmd.AddCommand(&cobra.Command{Use: "site", Short: "Create a new site", Run: func(cmd *cobra.Command, args []string) {}})
+
+	root.AddCommand(newCmd, &cobra.Command{Use: "hidden", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}})
+
+	return root
+}
+
+func TestGenCompletion(t *testing.T) {
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		shell  string
+		expect []string
+	}{
+		{"bash", []string{"__hugo_complete content", "hugo_new)\n            __hugo_complete_content", `flags_completion+=("__hugo_complete_config")`}},
+		{"zsh", []string{"bashcompinit", "__hugo_complete_content"}},
+		{"fish", []string{
+			"complete -c hugo -f -n '__hugo_using_command ' -a 'new' -d 'Create new content'",
+			"complete -c hugo -n '__hugo_using_command new' -l kind -s k -r -d 'content type to create'",
+			"complete -c hugo -n '__hugo_using_command new' -l config -r -d 'config file'",
+			"-l set -r -f -a '(hugo __complete config (commandline -ct))'",
+			"complete -c hugo -f -n '__hugo_using_command new' -a '(hugo __complete content (commandline -ct))'",
+		}},
+		{"powershell", []string{
+			"'hugo' = @('new', '--config', '--set')",
+			"'hugo new' = @('site', '--kind', '--config', '--set')",
+			"'hugo new' = 'content'",
+			"'--set' = 'config'",
+		}},
+	} {
+		var b bytes.Buffer
+		assert.NoError(genCompletion(newCompletionTestCommands(), &b, test.shell))
+		for _, expect := range test.expect {
+			assert.Contains(b.String(), expect, test.shell)
+		}
+		assert.NotContains(b.String(), "hidden", test.shell)
+	}
+
+	assert.Error(genCompletion(newCompletionTestCommands(), ioutil.Discard, "tcsh"))
+}
+
+func TestCompleteContentDirs(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	contentDir := filepath.FromSlash("/work/content")
+	for _, dir := range []string{"posts/2018", "posts/drafts", "projects", ".git", "about"} {
+		assert.NoError(fs.MkdirAll(filepath.Join(contentDir, filepath.FromSlash(dir)), 0755))
+	}
+	assert.NoError(afero.WriteFile(fs, filepath.Join(contentDir, "posts", "p1.md"), []byte("P1"), 0755))
+
+	assert.Equal([]string{"about/", "posts/", "projects/"}, completeContentDirs(fs, contentDir, ""))
+	assert.Equal([]string{"posts/", "projects/"}, completeContentDirs(fs, contentDir, "p"))
+	assert.Equal([]string{"posts/2018/", "posts/drafts/"}, completeContentDirs(fs, contentDir, "posts/"))
+	assert.Equal([]string{"posts/drafts/"}, completeContentDirs(fs, contentDir, "posts/d"))
+	assert.Empty(completeContentDirs(fs, contentDir, "nope/"))
+
+	assert.Equal([]string{"baseurl=", "builddrafts="}, completeConfigKeys([]string{"title", "builddrafts", "baseurl"}, "B"))
+	assert.Equal([]string{"contentdir="}, completeConfigKeys([]string{"contentdir", "configlayers"}, "c"))
+}
+
+// TestGenManInSync verifies that the man pages document all the commands and
+// their flags.
+func TestGenManInSync(t *testing.T) {
+	assert := require.New(t)
+
+	AddCommands()
+
+	dir, err := ioutil.TempDir("", "hugo-man")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	HugoCmd.DisableAutoGenTag = true
+	assert.NoError(doc.GenManTree(HugoCmd, &doc.GenManHeader{Section: "1"}, dir))
+
+	walkCommands(HugoCmd, func(c *cobra.Command) {
+		filename := filepath.Join(dir, strings.Replace(c.CommandPath(), " ", "-", -1)+".1")
+		b, err := ioutil.ReadFile(filename)
+		assert.NoError(err, c.CommandPath())
+
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			assert.Contains(string(b), `\-\-`+strings.Replace(f.Name, "-", `\-`, -1), c.CommandPath())
+		})
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/spf13/cast"
+)
+
+// The config overrides set with --set, e.g. params.env=staging.
+var configOverrides []string
+
+// parseConfigOverride parses a key=value config override. The value is
+// parsed as a TOML value, e.g. true, 42, 1.5 or ["a", "b"], falling back to
+// a string.
+func parseConfigOverride(s string) (string, interface{}, error) {
+	parts := strings.SplitN(s, "=", 2)
+	key := strings.TrimSpace(parts[0])
+	if len(parts) != 2 || key == "" {
+		return "", nil, fmt.Errorf("invalid config override %q, must be key=value", s)
+	}
+
+	value := strings.TrimSpace(parts[1])
+	if m, err := parser.HandleTOMLMetaData([]byte("v = " + value)); err == nil {
+		if v, found := cast.ToStringMap(m)["v"]; found {
+			return key, v, nil
+		}
+	}
+
+	return key, value, nil
+}
+
+// setConfigValue sets the config value for the given key, where nested
+// keys are separated by dots, e.g. params.env. Unlike Set on the config
+// provider, the other values in the maps along the path are kept.
+func setConfigValue(cfg config.Provider, key string, value interface{}) {
+	path := strings.Split(strings.ToLower(key), ".")
+	if len(path) == 1 {
+		cfg.Set(key, value)
+		return
+	}
+
+	root := copyConfigMap(cfg.Get(path[0]))
+	m := root
+	for _, k := range path[1 : len(path)-1] {
+		next := copyConfigMap(m[k])
+		m[k] = next
+		m = next
+	}
+	m[path[len(path)-1]] = value
+
+	cfg.Set(path[0], root)
+}
+
+func copyConfigMap(v interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, vv := range cast.ToStringMap(v) {
+		m[strings.ToLower(k)] = vv
+	}
+	return m
+}
+
+// applyConfigOverrides applies the config overrides set with --set.
+func applyConfigOverrides(cfg config.Provider, overrides []string) error {
+	for _, s := range overrides {
+		key, value, err := parseConfigOverride(s)
+		if err != nil {
+			return newUserError(err)
+		}
+		setConfigValue(cfg, key, value)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigOverride(t *testing.T) {
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		in    string
+		key   string
+		value interface{}
+	}{
+		{"params.env=staging", "params.env", "staging"},
+		{"enableGitInfo=false", "enableGitInfo", false},
+		{"paginate = 20", "paginate", int64(20)},
+		{"params.ratio=1.5", "params.ratio", 1.5},
+		{`title="My = Site"`, "title", "My = Site"},
+		{"title=My Site", "title", "My Site"},
+		{`disableKinds=["RSS", "404"]`, "disableKinds", []interface{}{"RSS", "404"}},
+		{"params.date=2018-01-02T15:04:05Z", "params.date", time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"params.empty=", "params.empty", ""},
+	} {
+		key, value, err := parseConfigOverride(test.in)
+		assert.NoError(err, test.in)
+		assert.Equal(test.key, key, test.in)
+		assert.Equal(test.value, value, test.in)
+	}
+
+	for _, in := range []string{"params.env", "=value"} {
+		_, _, err := parseConfigOverride(in)
+		assert.Error(err, in)
+	}
+}
+
+func TestApplyConfigOverrides(t *testing.T) {
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("title", "Site")
+	v.Set("params", map[string]interface{}{"author": "Bep", "Social": map[string]interface{}{"twitter": "gohugoio"}})
+
+	assert.NoError(applyConfigOverrides(v, []string{
+		"title=Staging",
+		"params.env=staging",
+		"params.social.github=gohugoio",
+		"params.new.deep.key=true",
+		"enableGitInfo=false",
+	}))
+
+	assert.Equal("Staging", v.GetString("title"))
+	assert.False(v.GetBool("enableGitInfo"))
+
+	params := v.GetStringMap("params")
+	assert.Equal("Bep", params["author"])
+	assert.Equal("staging", params["env"])
+	assert.Equal(map[string]interface{}{"twitter": "gohugoio", "github": "gohugoio"}, params["social"])
+	assert.Equal(true, v.GetBool("params.new.deep.key"))
+
+	assert.Error(applyConfigOverrides(v, []string{"novalue"}))
+}
+
+func TestConfigOverridesSiteParams(t *testing.T) {
+	assert := require.New(t)
+
+	home := buildTestSite(t, map[string]string{
+		"config.toml": `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[params]
+author = "Bep"
+env = "production"
+`,
+		"content/a.md":       "---\ntitle: A\n---\nContent.",
+		"layouts/index.html": "{{ .Site.Params.env }}|{{ .Site.Params.author }}",
+	}, "--set", "params.env=staging")
+
+	assert.Equal("staging|Bep", home)
+}
+
+// buildTestSite builds a site with the given files and command line
+// arguments and returns the rendered home page.
+func buildTestSite(t *testing.T, files map[string]string, args ...string) string {
+	assert := require.New(t)
+
+	// The flags and the sites may be left over from the other tests.
+	Hugo = nil
+	renderToMemory = false
+	defer resetGlobalLoggers()
+	defer func() {
+		configOverrides = nil
+		destination = ""
+		theme = ""
+		themesDir = ""
+		quiet = false
+		Hugo = nil
+	}()
+
+	dir, err := ioutil.TempDir("", "hugo-flags")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	for filename, content := range files {
+		filename = filepath.Join(dir, filepath.FromSlash(filename))
+		assert.NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+		assert.NoError(ioutil.WriteFile(filename, []byte(content), 0644))
+	}
+
+	publishDir := filepath.Join(dir, "public")
+	HugoCmd.SetArgs(append([]string{"--source", dir, "--destination", publishDir, "--quiet"}, args...))
+	assert.NoError(HugoCmd.Execute())
+
+	b, err := ioutil.ReadFile(filepath.Join(publishDir, "index.html"))
+	assert.NoError(err)
+
+	return string(b)
+}
@@ -0,0 +1,279 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/livereload"
+	"github.com/gohugoio/hugo/watcher"
+	"github.com/spf13/viper"
+)
+
+// The config keys set while building, which are not to be reported as
+// changed when the config is reloaded.
+var runtimeConfigKeys = map[string]bool{
+	"languagessorted": true,
+	"multilingual":    true,
+	"multihost":       true,
+	"configlayers":    true,
+}
+
+// watchConfig reloads the config and rebuilds the sites when the project or
+// the theme config files change.
+func (c *commandeer) watchConfig() {
+	w, err := watcher.New(500 * time.Millisecond)
+	if err != nil {
+		c.Logger.ERROR.Println("Failed to watch the config files:", err)
+		return
+	}
+
+	watched := make(map[string]bool)
+	watchDirs := func(files []string) {
+		for _, filename := range files {
+			dir := filepath.Dir(filename)
+			if watched[dir] {
+				continue
+			}
+			// Watch the directories, as editors may replace the files when
+			// saving. The theme config may not exist yet.
+			if err := w.Add(dir); err == nil {
+				watched[dir] = true
+			}
+		}
+	}
+
+	files := c.configFiles()
+	watchDirs(files)
+
+	go func() {
+		for {
+			select {
+			case evs := <-w.Events:
+				changed := changedConfigFiles(files, evs)
+				if len(changed) == 0 {
+					continue
+				}
+
+				c.Logger.FEEDBACK.Println("Config file changed:", strings.Join(changed, ", "))
+
+				if err := c.reloadConfig(); err != nil {
+					c.Logger.ERROR.Println("Failed to reload config:", err)
+					continue
+				}
+
+				// The theme may have changed.
+				files = c.configFiles()
+				watchDirs(files)
+
+				if !buildWatch && !c.Cfg.GetBool("disableLiveReload") {
+					livereload.ForceRefresh()
+				}
+			case err := <-w.Errors:
+				if err != nil {
+					c.Logger.ERROR.Println("Error watching the config files:", err)
+				}
+			}
+		}
+	}()
+}
+
+// reloadConfig loads the config again, with the same flags, and does a full
+// rebuild of the sites. The current sites are kept if the build fails.
+func (c *commandeer) reloadConfig() error {
+	defer c.timeTrack(time.Now(), "Total")
+
+	buildMu.Lock()
+	defer buildMu.Unlock()
+
+	doWithCommandeer := c.doWithCommandeer
+
+	nc, err := InitializeConfig(c.Running, func(nc *commandeer) error {
+		// Keep serving on the same ports.
+		nc.serverPorts = c.serverPorts
+		if doWithCommandeer != nil {
+			return doWithCommandeer(nc)
+		}
+		return nil
+	}, c.subCmdVs...)
+	if err != nil {
+		return err
+	}
+
+	// The server keeps serving from the destination it was started with,
+	// which is in memory by default.
+	nc.Fs.Destination = c.Fs.Destination
+	nc.visitedURLs = c.visitedURLs
+	nc.doWithCommandeer = doWithCommandeer
+	nc.contentWatcher = c.contentWatcher
+
+	if keys := changedConfigKeys(c.Cfg, nc.Cfg); len(keys) > 0 {
+		c.Logger.FEEDBACK.Println("Changed config:", strings.Join(keys, ", "))
+	}
+
+	if !quiet {
+		c.Logger.FEEDBACK.Println("Started building sites ...")
+	}
+
+	// Start from scratch, as e.g. the languages may have changed, but keep
+	// the current sites if the build fails.
+	old := Hugo
+	Hugo = nil
+	if err := nc.fullBuild(); err != nil {
+		Hugo = old
+		return err
+	}
+
+	for _, s := range Hugo.Sites {
+		s.RegisterMediaTypes()
+	}
+
+	nc.watchedDirs = c.watchedDirs
+	*c = *nc
+
+	// The content dir or the theme may have changed.
+	c.updateWatchedDirs()
+
+	return nil
+}
+
+// updateWatchedDirs points the content watcher to the dirs in the current
+// config. It must be called with buildMu held.
+func (c *commandeer) updateWatchedDirs() {
+	if c.contentWatcher == nil {
+		return
+	}
+
+	dirs, err := c.getDirList()
+	if err != nil {
+		c.Logger.ERROR.Println("Failed to update the watched dirs:", err)
+		return
+	}
+
+	current := make(map[string]bool)
+	for _, d := range dirs {
+		current[d] = true
+	}
+
+	for _, d := range c.watchedDirs {
+		if !current[d] {
+			_ = c.contentWatcher.Remove(d)
+		}
+	}
+
+	for _, d := range dirs {
+		if d != "" {
+			_ = c.contentWatcher.Add(d)
+		}
+	}
+
+	c.watchedDirs = dirs
+}
+
+// configFiles returns the absolute filenames of the project config files and
+// the possible theme config files.
+func (c *commandeer) configFiles() []string {
+	var files []string
+
+	if v, ok := c.Cfg.(*viper.Viper); ok && v.ConfigFileUsed() != "" {
+		files = append(files, v.ConfigFileUsed())
+	}
+	if cfgFile != "" {
+		files = append(files, strings.Split(cfgFile, ",")[1:]...)
+	}
+
+	if themeDir := c.PathSpec().GetThemeDir(); themeDir != "" {
+		for _, ext := range []string{"toml", "yaml", "yml", "json"} {
+			files = append(files, filepath.Join(themeDir, "config."+ext))
+		}
+	}
+
+	for i, filename := range files {
+		if abs, err := filepath.Abs(filename); err == nil {
+			files[i] = abs
+		}
+	}
+
+	return files
+}
+
+// changedConfigFiles returns the config files changed by the given events.
+func changedConfigFiles(files []string, evs []fsnotify.Event) []string {
+	var changed []string
+	seen := make(map[string]bool)
+
+	for _, ev := range evs {
+		if ev.Op == fsnotify.Chmod || seen[ev.Name] {
+			continue
+		}
+		for _, filename := range files {
+			if filepath.Clean(ev.Name) == filename {
+				seen[ev.Name] = true
+				changed = append(changed, filename)
+				break
+			}
+		}
+	}
+
+	return changed
+}
+
+// changedConfigKeys returns the sorted top level config keys with different
+// values in the two configs.
+func changedConfigKeys(old, new config.Provider) []string {
+	oldSettings, newSettings := allSettings(old), allSettings(new)
+
+	var keys []string
+	for k, v := range newSettings {
+		if !reflect.DeepEqual(v, oldSettings[k]) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range oldSettings {
+		if _, found := newSettings[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func allSettings(cfg config.Provider) map[string]interface{} {
+	settings := make(map[string]interface{})
+
+	v, ok := cfg.(*viper.Viper)
+	if !ok {
+		return settings
+	}
+
+	for _, k := range v.AllKeys() {
+		k = strings.SplitN(k, ".", 2)[0]
+		if runtimeConfigKeys[k] {
+			continue
+		}
+		if _, found := settings[k]; !found {
+			settings[k] = v.Get(k)
+		}
+	}
+
+	return settings
+}
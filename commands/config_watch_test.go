@@ -0,0 +1,129 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gohugoio/hugo/watcher"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedConfigFiles(t *testing.T) {
+	assert := require.New(t)
+
+	config := filepath.FromSlash("/site/config.toml")
+	themeConfig := filepath.FromSlash("/site/themes/mytheme/config.toml")
+	files := []string{config, themeConfig}
+
+	assert.Empty(changedConfigFiles(files, []fsnotify.Event{
+		{Name: filepath.FromSlash("/site/content/post.md"), Op: fsnotify.Write},
+		{Name: config, Op: fsnotify.Chmod},
+	}))
+
+	assert.Equal([]string{config, themeConfig}, changedConfigFiles(files, []fsnotify.Event{
+		{Name: config, Op: fsnotify.Rename},
+		{Name: config, Op: fsnotify.Create},
+		{Name: filepath.FromSlash("/site/themes/mytheme/layouts/index.html"), Op: fsnotify.Write},
+		{Name: themeConfig, Op: fsnotify.Create},
+	}))
+}
+
+func TestChangedConfigKeys(t *testing.T) {
+	assert := require.New(t)
+
+	old := viper.New()
+	old.Set("title", "Site")
+	old.Set("params", map[string]interface{}{"a": 1, "b": 2})
+	old.Set("paginate", 10)
+	old.Set("languagesSorted", []string{"en"})
+
+	new := viper.New()
+	new.Set("title", "Site")
+	new.Set("params", map[string]interface{}{"a": 1, "b": 3})
+	new.Set("theme", "mytheme")
+
+	assert.Equal([]string{"paginate", "params", "theme"}, changedConfigKeys(old, new))
+	assert.Empty(changedConfigKeys(new, new))
+}
+
+func TestReloadConfig(t *testing.T) {
+	assert := require.New(t)
+	defer resetGlobalLoggers()
+	defer func() {
+		source = ""
+		quiet = false
+		Hugo = nil
+	}()
+
+	dir, err := ioutil.TempDir("", "hugo-reload")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	assert.NoError(err)
+
+	writeFiles := func(files map[string]string) {
+		for filename, content := range files {
+			filename = filepath.Join(dir, filepath.FromSlash(filename))
+			assert.NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+			assert.NoError(ioutil.WriteFile(filename, []byte(content), 0644))
+		}
+	}
+
+	writeFiles(map[string]string{
+		"config.toml":                  `baseURL = "http://example.com/"`,
+		"content/a.md":                 "---\ntitle: A\n---\n",
+		"content2/b.md":                "---\ntitle: B\n---\n",
+		"layouts/_default/single.html": "{{ .Title }}",
+		"layouts/_default/list.html":   "{{ .Title }}",
+	})
+
+	source = dir
+	Hugo = nil
+	quiet = true
+	c, err := InitializeConfig(true, func(c *commandeer) error {
+		c.Set("renderToMemory", true)
+		return nil
+	})
+	assert.NoError(err)
+	assert.NoError(c.fullBuild())
+
+	w, err := watcher.New(time.Hour)
+	assert.NoError(err)
+	defer w.Close()
+	c.contentWatcher = w
+	c.watchedDirs, err = c.getDirList()
+	assert.NoError(err)
+	assert.Contains(c.watchedDirs, filepath.Join(dir, "content"))
+
+	writeFiles(map[string]string{"config.toml": "baseURL = \"http://example.com/\"\ncontentDir = \"content2\""})
+	assert.NoError(c.reloadConfig())
+	assert.Equal("content2", c.Cfg.GetString("contentDir"))
+	assert.NotNil(Hugo.GetContentPage(filepath.Join(dir, "content2", "b.md")))
+	assert.Contains(c.watchedDirs, filepath.Join(dir, "content2"))
+	assert.NotContains(c.watchedDirs, filepath.Join(dir, "content"))
+
+	// The current sites are kept if the new config fails to build.
+	sites := Hugo
+	writeFiles(map[string]string{"config.toml": "baseURL = \"http://example.com/\"\ncontentDir = \"nope\""})
+	assert.Error(c.reloadConfig())
+	assert.True(sites == Hugo)
+	assert.Equal("content2", c.Cfg.GetString("contentDir"))
+}
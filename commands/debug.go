@@ -0,0 +1,86 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/tpl"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+func init() {
+	debugCmd.AddCommand(debugTemplatesCmd)
+	debugCmd.PersistentFlags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	debugCmd.PersistentFlags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
+}
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Print debug information about the site",
+	Long: `Print debug information about the site.
+
+Debug requires a subcommand, e.g. ` + "`hugo debug templates`.",
+	RunE: nil,
+}
+
+var debugTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the templates and the base templates they resolved to",
+	Long: `List the templates loaded from the layouts folders and the chain of
+base templates (baseof) each of them extends, innermost first. A section's
+baseof with nothing but define blocks extends the next baseof in the lookup
+order, e.g. the one in _default.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := InitializeConfig(false, nil)
+		if err != nil {
+			return err
+		}
+
+		sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+		if err != nil {
+			return newSystemError("Error creating sites", err)
+		}
+
+		provider, ok := sites.Sites[0].Tmpl.(tpl.TemplateInfoProvider)
+		if !ok {
+			return newSystemErrorF("template info not available")
+		}
+
+		workingDir := c.Cfg.GetString("workingDir")
+		rel := func(filename string) string {
+			if r, err := filepath.Rel(workingDir, filename); err == nil && !strings.HasPrefix(r, "..") {
+				return filepath.ToSlash(r)
+			}
+			return filename
+		}
+
+		for _, info := range provider.TemplateInfos() {
+			jww.FEEDBACK.Printf("%s: %s\n", info.Name, rel(info.Filename))
+			if len(info.BaseFilenames) == 0 {
+				continue
+			}
+			bases := make([]string, len(info.BaseFilenames))
+			for i, f := range info.BaseFilenames {
+				bases[i] = rel(f)
+			}
+			jww.FEEDBACK.Printf("  baseof: %s\n", strings.Join(bases, " -> "))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffSummaryOnly bool
+	diffContext     int
+)
+
+func init() {
+	initHugoBuilderFlags(diffCmd)
+	diffCmd.Flags().BoolVar(&diffSummaryOnly, "summary", false, "only list the added, removed and modified pages")
+	diffCmd.Flags().IntVar(&diffContext, "context", 5, "number of unchanged words to show around each change")
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [olddir]",
+	Short: "Compare the site with a previously published version",
+	Long: `Build the site in memory and compare the rendered HTML pages with
+the ones in a previously published directory, e.g. a copy of public/.
+
+Pages are listed as added (A), removed (D) or modified (M). For modified
+pages a word diff of the text content is printed, with removed words
+marked as [-removed-] and added words as {+added+}.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return newUserError("the old directory needs to be provided")
+		}
+
+		oldDir, err := filepath.Abs(args[0])
+		if err != nil {
+			return newUserError(err)
+		}
+
+		if exists, _ := helpers.DirExists(oldDir, hugofs.Os); !exists {
+			return newUserError("directory not found:", oldDir)
+		}
+
+		cfgInit := func(c *commandeer) error {
+			c.Set("renderToMemory", true)
+			return nil
+		}
+
+		c, err := InitializeConfig(false, cfgInit, cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := c.buildSites(); err != nil {
+			return newSystemError("Error building site:", err)
+		}
+
+		return diffPublishDirs(os.Stdout, hugofs.Os, oldDir, c.Fs.Destination, helpers.FilePathSeparator, diffSummaryOnly, diffContext)
+	},
+}
+
+// diffPublishDirs compares the HTML files below the two directories and
+// writes a report to w.
+func diffPublishDirs(w io.Writer, oldFs afero.Fs, oldDir string, newFs afero.Fs, newDir string, summaryOnly bool, context int) error {
+	oldFiles, err := collectHTMLFiles(oldFs, oldDir)
+	if err != nil {
+		return err
+	}
+
+	newFiles, err := collectHTMLFiles(newFs, newDir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		all                       = make(map[string]bool)
+		added, removed, unchanged int
+		modified                  int
+	)
+
+	for name := range oldFiles {
+		all[name] = true
+	}
+	for name := range newFiles {
+		all[name] = true
+	}
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldFilename, inOld := oldFiles[name]
+		newFilename, inNew := newFiles[name]
+
+		switch {
+		case !inOld:
+			added++
+			fmt.Fprintln(w, "A", name)
+		case !inNew:
+			removed++
+			fmt.Fprintln(w, "D", name)
+		default:
+			oldWords, err := htmlFileWords(oldFs, oldFilename)
+			if err != nil {
+				return err
+			}
+			newWords, err := htmlFileWords(newFs, newFilename)
+			if err != nil {
+				return err
+			}
+
+			diff := helpers.FormatWordDiff(helpers.DiffWords(oldWords, newWords), context)
+			if diff == "" {
+				unchanged++
+				continue
+			}
+
+			modified++
+			fmt.Fprintln(w, "M", name)
+			if !summaryOnly {
+				fmt.Fprintf(w, "    %s\n", diff)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d added, %d removed, %d modified, %d unchanged\n", added, removed, modified, unchanged)
+
+	return nil
+}
+
+// collectHTMLFiles maps the slash separated path, relative to dir, to the
+// filename for all the HTML files below dir.
+func collectHTMLFiles(fs afero.Fs, dir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".html") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files["/"+filepath.ToSlash(rel)] = path
+
+		return nil
+	})
+
+	return files, err
+}
+
+func htmlFileWords(fs afero.Fs, filename string) ([]string, error) {
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(helpers.StripHTML(string(b))), nil
+}
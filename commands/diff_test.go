@@ -0,0 +1,53 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPublishDirs(t *testing.T) {
+	assert := require.New(t)
+
+	oldFs, newFs := afero.NewMemMapFs(), afero.NewMemMapFs()
+
+	write := func(fs afero.Fs, name, content string) {
+		assert.NoError(afero.WriteFile(fs, filepath.FromSlash(name), []byte(content), 0755))
+	}
+
+	write(oldFs, "/old/index.html", "<html><body><h1>Home</h1><p>Welcome to my site.</p></body></html>")
+	write(oldFs, "/old/about/index.html", "<p>About me</p>")
+	write(oldFs, "/old/removed/index.html", "<p>Gone</p>")
+	write(oldFs, "/old/style.css", "body {}")
+
+	write(newFs, "/new/index.html", "<html><body><h1>Home</h1><p>Welcome to my <b>new</b> blog.</p></body></html>")
+	write(newFs, "/new/about/index.html", "<div>About   me</div>")
+	write(newFs, "/new/added/index.html", "<p>New</p>")
+
+	var b bytes.Buffer
+	assert.NoError(diffPublishDirs(&b, oldFs, filepath.FromSlash("/old"), newFs, filepath.FromSlash("/new"), false, 2))
+
+	assert.Equal(`A /added/index.html
+M /index.html
+    ... to my [-site.-] {+new blog.+}
+D /removed/index.html
+
+1 added, 1 removed, 1 modified, 1 unchanged
+`, b.String())
+}
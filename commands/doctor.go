@@ -0,0 +1,314 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// The maximum length of a path on Windows without long path support.
+const windowsMaxPath = 260
+
+func init() {
+	doctorCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	doctorCmd.Flags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
+	doctorCmd.Flags().StringVarP(&theme, "theme", "t", "", "theme to use (located in /themes/THEMENAME/)")
+	doctorCmd.Flags().StringVarP(&themesDir, "themesDir", "", "", "filesystem path to themes directory")
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment for problems building the site",
+	Long: `Check the environment for problems building the site:
+
+    helpers       the external helpers for the markup formats used in the content,
+                  e.g. asciidoctor and pandoc, are installed
+    files         the limit of open files is above the number of files to watch
+    case          no paths differ by case only, which collide on case-insensitive
+                  filesystems, e.g. on macOS and Windows
+    path length   no paths are longer than the 260 characters Windows allows
+                  without long path support
+    cache         the themes are installed and the cache dirs are writable
+
+The same checks are run by "hugo env --full". The command fails if a problem
+is found, warnings are only reported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(os.Stdout)
+	},
+}
+
+func runDoctor(w io.Writer) error {
+	c, err := InitializeConfig(false, nil)
+	if err != nil {
+		return err
+	}
+
+	d := &doctor{fs: c.Fs.Source, w: w}
+
+	ps := c.PathSpec()
+
+	var dirs []string
+	for _, dir := range append([]string{ps.ContentDir(), ps.LayoutDir(), c.Cfg.GetString("dataDir"), c.Cfg.GetString("i18nDir")}, ps.StaticDirs()...) {
+		dirs = append(dirs, ps.AbsPathify(dir))
+	}
+	if ps.ThemeSet() {
+		dirs = append(dirs, ps.GetThemeDir())
+	}
+
+	files, err := d.walk(dirs...)
+	if err != nil {
+		return newSystemError(err)
+	}
+
+	extHelpers, err := helpers.ExternalHelpers(c.Cfg)
+	if err != nil {
+		return newUserError(err)
+	}
+	d.checkHelpers(extHelpers, d.contentFormats(ps.AbsPathify(ps.ContentDir()), files))
+
+	limit, found := fileDescriptorLimit()
+	d.checkFileLimit(limit, found, len(files))
+
+	d.section("Filesystem")
+	caseDir := c.Cfg.GetString("cacheDir")
+	if exists, _ := helpers.DirExists(caseDir, d.fs); caseDir == "" || !exists {
+		caseDir = os.TempDir()
+	}
+	if insensitive, err := isCaseInsensitiveFs(caseDir); err != nil {
+		d.warn("unable to check the case sensitivity of %s: %s", caseDir, err)
+	} else if insensitive {
+		d.ok("%s is on a case-insensitive filesystem", caseDir)
+	} else {
+		d.ok("%s is on a case-sensitive filesystem", caseDir)
+	}
+	d.checkCaseCollisions(files)
+	d.checkPathLengths(files, runtime.GOOS == "windows")
+
+	d.section("Cache")
+	if ps.ThemeSet() {
+		if exists, _ := helpers.Exists(ps.GetThemeDir(), d.fs); exists {
+			d.ok("theme %q installed in %s", ps.Theme(), ps.GetThemeDir())
+		} else {
+			d.problem("theme %q not installed in %s", ps.Theme(), ps.GetThemeDir())
+		}
+	}
+	d.checkWritable("cacheDir", c.Cfg.GetString("cacheDir"))
+	d.checkWritable("resourceDir", ps.AbsPathify(c.Cfg.GetString("resourceDir")))
+
+	fmt.Fprintf(w, "\n%d problem(s), %d warning(s)\n", d.problems, d.warnings)
+
+	if d.problems > 0 {
+		return newSystemErrorF("Found %d problem(s)", d.problems)
+	}
+
+	return nil
+}
+
+// doctor checks the environment and writes the findings to w.
+type doctor struct {
+	fs afero.Fs
+	w  io.Writer
+
+	problems int
+	warnings int
+}
+
+func (d *doctor) section(name string) {
+	fmt.Fprintf(d.w, "\n%s:\n", name)
+}
+
+func (d *doctor) ok(format string, a ...interface{}) {
+	fmt.Fprintf(d.w, "  OK       %s\n", fmt.Sprintf(format, a...))
+}
+
+func (d *doctor) warn(format string, a ...interface{}) {
+	d.warnings++
+	fmt.Fprintf(d.w, "  WARNING  %s\n", fmt.Sprintf(format, a...))
+}
+
+func (d *doctor) problem(format string, a ...interface{}) {
+	d.problems++
+	fmt.Fprintf(d.w, "  PROBLEM  %s\n", fmt.Sprintf(format, a...))
+}
+
+// walk returns the files in the given dirs, skipping dirs that don't exist.
+func (d *doctor) walk(dirs ...string) ([]string, error) {
+	var (
+		files []string
+		seen  = make(map[string]bool)
+	)
+
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		if exists, _ := helpers.Exists(dir, d.fs); !exists {
+			continue
+		}
+
+		err := afero.Walk(d.fs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// contentFormats returns the number of content files per markup format.
+func (d *doctor) contentFormats(contentDir string, files []string) map[string]int {
+	formats := make(map[string]int)
+	prefix := contentDir + helpers.FilePathSeparator
+	for _, f := range files {
+		if strings.HasPrefix(f, prefix) {
+			formats[helpers.GuessType(strings.TrimPrefix(filepath.Ext(f), "."))]++
+		}
+	}
+	return formats
+}
+
+func (d *doctor) checkHelpers(extHelpers []helpers.ExternalHelper, formats map[string]int) {
+	d.section("External helpers")
+	for _, h := range extHelpers {
+		count := formats[h.Format]
+		switch {
+		case h.Path != "":
+			d.ok("%s: %s (%d content files)", h.Name, h.Path, count)
+		case count > 0:
+			d.problem("%s: not found in $PATH, needed by %d content files; install it or set the binary in the markup config", h.Name, count)
+		default:
+			d.ok("%s: not found in $PATH, not used", h.Name)
+		}
+	}
+}
+
+func (d *doctor) checkFileLimit(limit uint64, found bool, count int) {
+	d.section("Open files")
+	switch {
+	case !found:
+		d.ok("%d files to watch", count)
+	case uint64(count) >= limit:
+		d.warn("%d files to watch, but the limit of open files is %d; the server may not watch all files, raise it with ulimit -n", count, limit)
+	default:
+		d.ok("%d files to watch, the limit of open files is %d", count, limit)
+	}
+}
+
+// checkCaseCollisions reports the paths that differ by case only.
+func (d *doctor) checkCaseCollisions(files []string) {
+	byLower := make(map[string][]string)
+	var keys []string
+	for _, f := range files {
+		key := strings.ToLower(f)
+		if _, found := byLower[key]; !found {
+			keys = append(keys, key)
+		}
+		byLower[key] = append(byLower[key], f)
+	}
+
+	collisions := 0
+	for _, key := range keys {
+		if paths := byLower[key]; len(paths) > 1 {
+			collisions++
+			d.warn("paths differ by case only and collide on case-insensitive filesystems: %s", strings.Join(paths, ", "))
+		}
+	}
+
+	if collisions == 0 {
+		d.ok("no paths differ by case only")
+	}
+}
+
+// checkPathLengths reports the paths too long for Windows. They are
+// problems on Windows and warnings elsewhere.
+func (d *doctor) checkPathLengths(files []string, windows bool) {
+	tooLong := 0
+	for _, f := range files {
+		if len(f) < windowsMaxPath {
+			continue
+		}
+		tooLong++
+		if windows {
+			d.problem("path is %d characters, longer than Windows allows: %s", len(f), f)
+		} else {
+			d.warn("path is %d characters, too long on Windows: %s", len(f), f)
+		}
+	}
+
+	if tooLong == 0 {
+		d.ok("no paths longer than %d characters", windowsMaxPath-1)
+	}
+}
+
+// checkWritable checks that the dir, if it exists, is writable.
+func (d *doctor) checkWritable(name, dir string) {
+	if dir == "" {
+		return
+	}
+
+	if exists, _ := helpers.Exists(dir, d.fs); !exists {
+		d.ok("%s %s will be created", name, dir)
+		return
+	}
+
+	f, err := afero.TempFile(d.fs, dir, "hugo-doctor")
+	if err != nil {
+		d.problem("%s %s is not writable: %s", name, dir, err)
+		return
+	}
+	f.Close()
+	d.fs.Remove(f.Name())
+
+	d.ok("%s %s is writable", name, dir)
+}
+
+// isCaseInsensitiveFs returns whether dir is on a case-insensitive filesystem.
+// It creates and removes a temporary file in dir, so dir should be the cache
+// or the temp dir, not the project.
+func isCaseInsensitiveFs(dir string) (bool, error) {
+	f, err := ioutil.TempFile(dir, "hugo-case-check")
+	if err != nil {
+		return false, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	upper := filepath.Join(filepath.Dir(f.Name()), strings.ToUpper(filepath.Base(f.Name())))
+	_, err = os.Stat(upper)
+
+	return err == nil, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	long := filepath.FromSlash("/site/content/" + strings.Repeat("a", windowsMaxPath) + ".md")
+	for _, name := range []string{"/site/content/Post.md", "/site/content/post.md", "/site/content/doc.adoc", "/site/static/img.png", long} {
+		afero.WriteFile(fs, filepath.FromSlash(name), []byte("x"), 0755)
+	}
+
+	var b bytes.Buffer
+	d := &doctor{fs: fs, w: &b}
+
+	files, err := d.walk(filepath.FromSlash("/site/content"), filepath.FromSlash("/site/static"), filepath.FromSlash("/site/data"))
+	assert.NoError(err)
+	assert.Len(files, 5)
+
+	formats := d.contentFormats(filepath.FromSlash("/site/content"), files)
+	assert.Equal(map[string]int{"markdown": 3, "asciidoc": 1}, formats)
+
+	d.checkHelpers([]helpers.ExternalHelper{
+		{Format: "asciidoc", Name: "AsciiDoc"},
+		{Format: "pandoc", Name: "Pandoc"},
+		{Format: "rst", Name: "reStructuredText", Path: "/usr/bin/rst2html"},
+	}, formats)
+	d.checkFileLimit(4, true, len(files))
+	d.checkCaseCollisions(files)
+	d.checkPathLengths(files, false)
+
+	assert.Equal(1, d.problems)
+	assert.Equal(3, d.warnings)
+
+	out := b.String()
+	assert.Contains(out, "PROBLEM  AsciiDoc: not found in $PATH, needed by 1 content files")
+	assert.Contains(out, "OK       Pandoc: not found in $PATH, not used")
+	assert.Contains(out, "OK       reStructuredText: /usr/bin/rst2html (0 content files)")
+	assert.Contains(out, "WARNING  5 files to watch, but the limit of open files is 4")
+	assert.Contains(out, "collide on case-insensitive filesystems: "+filepath.FromSlash("/site/content/Post.md")+", "+filepath.FromSlash("/site/content/post.md"))
+	assert.Contains(out, "too long on Windows: "+long)
+
+	d.checkPathLengths(files, true)
+	assert.Equal(2, d.problems)
+}
+
+func TestDoctorCheckWritable(t *testing.T) {
+	assert := require.New(t)
+
+	var b bytes.Buffer
+	d := &doctor{fs: afero.NewMemMapFs(), w: &b}
+
+	d.checkWritable("cacheDir", "/cache")
+	assert.NoError(d.fs.MkdirAll("/resources", 0777))
+	d.checkWritable("resourceDir", "/resources")
+
+	d = &doctor{fs: afero.NewReadOnlyFs(d.fs), w: &b}
+	d.checkWritable("resourceDir", "/resources")
+
+	assert.Equal(1, d.problems)
+	assert.Contains(b.String(), "cacheDir /cache will be created")
+	assert.Contains(b.String(), "resourceDir /resources is writable")
+	assert.Contains(b.String(), "resourceDir /resources is not writable")
+}
@@ -14,22 +14,40 @@
 package commands
 
 import (
+	"os"
 	"runtime"
 
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
 )
 
+var envFull bool
+
+func init() {
+	envCmd.Flags().BoolVar(&envFull, "full", false, "also check the environment for problems building the site, see hugo doctor")
+	envCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	envCmd.Flags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
+	envCmd.Flags().StringVarP(&theme, "theme", "t", "", "theme to use (located in /themes/THEMENAME/)")
+	envCmd.Flags().StringVarP(&themesDir, "themesDir", "", "", "filesystem path to themes directory")
+}
+
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "Print Hugo version and environment info",
-	Long:  `Print Hugo version and environment info. This is useful in Hugo bug reports.`,
+	Long: `Print Hugo version and environment info. This is useful in Hugo bug reports.
+
+With --full the environment is also checked for problems building the site,
+see hugo doctor.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		printHugoVersion()
 		jww.FEEDBACK.Printf("GOOS=%q\n", runtime.GOOS)
 		jww.FEEDBACK.Printf("GOARCH=%q\n", runtime.GOARCH)
 		jww.FEEDBACK.Printf("GOVERSION=%q\n", runtime.Version())
 
+		if envFull {
+			return runDoctor(os.Stdout)
+		}
+
 		return nil
 	},
 }
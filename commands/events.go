@@ -0,0 +1,210 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/hugolib"
+)
+
+const eventsFormatJSON = "json"
+
+// The event type sent for errors logged during a build.
+const buildEventError = "error"
+
+// The event type sent when the server is ready, with its port and URL.
+const eventServerStarted = "server-started"
+
+// buildEvents streams the build events to GUI frontends and editor plugins
+// if --events is set. It lives as long as the process, i.e. over rebuilds.
+var buildEvents *eventStream
+
+// eventEntry is a build event in the JSON event stream.
+type eventEntry struct {
+	Type         string `json:"type"`
+	Time         string `json:"time"`
+	Lang         string `json:"lang,omitempty"`
+	Path         string `json:"path,omitempty"`
+	URL          string `json:"url,omitempty"`
+	OutputFormat string `json:"outputFormat,omitempty"`
+	DurationMs   int64  `json:"durationMs,omitempty"`
+	Code         string `json:"code,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// eventStream writes the build events as JSON lines to stdout, or to the
+// clients connected to a Unix socket.
+type eventStream struct {
+	mu      sync.Mutex
+	out     io.Writer
+	clients []net.Conn
+
+	listener net.Listener
+}
+
+// newEventStream creates the event stream configured with the events and
+// eventsSocket flags. It returns nil if events are not enabled.
+func newEventStream(format, socket string) (*eventStream, error) {
+	switch format {
+	case "":
+		if socket != "" {
+			return nil, newUserError("--eventsSocket requires --events json")
+		}
+		return nil, nil
+	case eventsFormatJSON:
+	default:
+		return nil, newUserError(fmt.Sprintf("invalid events format %q, must be json", format))
+	}
+
+	if socket == "" {
+		return &eventStream{out: os.Stdout}, nil
+	}
+
+	// Remove a socket left behind by an earlier run.
+	if fi, err := os.Stat(socket); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(socket)
+	}
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, newSystemErrorF("failed to listen for events on %q: %s", socket, err)
+	}
+
+	s := &eventStream{listener: l}
+	go s.accept()
+
+	return s, nil
+}
+
+func (s *eventStream) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.clients = append(s.clients, conn)
+		s.mu.Unlock()
+	}
+}
+
+// onStdout returns whether the events are written to stdout, in which case
+// the human readable output must go elsewhere.
+func (s *eventStream) onStdout() bool {
+	return s != nil && s.out != nil
+}
+
+// humanOutput returns where to write the build summary and reports.
+func humanOutput() io.Writer {
+	if buildEvents.onStdout() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// handleBuildEvent is the build event handler added to the HugoSites.
+func (s *eventStream) handleBuildEvent(e hugolib.BuildEvent) {
+	entry := eventEntry{
+		Type:         string(e.Type),
+		Time:         e.Time.Format(time.RFC3339Nano),
+		Lang:         e.Lang,
+		Path:         e.Path,
+		URL:          e.URL,
+		OutputFormat: e.OutputFormat,
+		DurationMs:   int64(e.Duration / time.Millisecond),
+	}
+	if e.Err != nil {
+		entry.Error = e.Err.Error()
+	}
+	s.send(entry)
+}
+
+// logError sends an error logged during a build, as those usually do not
+// fail the build.
+func (s *eventStream) logError(code, message string) {
+	s.send(eventEntry{
+		Type:  buildEventError,
+		Time:  time.Now().Format(time.RFC3339Nano),
+		Code:  code,
+		Error: message,
+	})
+}
+
+// serverStarted sends the port and URL of a started server.
+func (s *eventStream) serverStarted(port int, url string) {
+	if s == nil {
+		return
+	}
+	s.send(eventEntry{
+		Type: eventServerStarted,
+		Time: time.Now().Format(time.RFC3339Nano),
+		URL:  url,
+		Port: port,
+	})
+}
+
+func (s *eventStream) send(entry eventEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.out != nil {
+		s.out.Write(b)
+		return
+	}
+
+	// Drop the clients that went away.
+	clients := s.clients[:0]
+	for _, c := range s.clients {
+		if _, err := c.Write(b); err != nil {
+			c.Close()
+			continue
+		}
+		clients = append(clients, c)
+	}
+	s.clients = clients
+}
+
+// Close closes the socket and its clients, if any.
+func (s *eventStream) Close() error {
+	if s == nil || s.listener == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.clients {
+		c.Close()
+	}
+	s.clients = nil
+
+	if err := s.listener.Close(); err != nil {
+		return fmt.Errorf("failed to close events socket: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gohugoio/hugo/hugolib"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEventStream(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := newEventStream("", "")
+	assert.NoError(err)
+	assert.Nil(s)
+	assert.False(s.onStdout())
+	assert.NoError(s.Close())
+
+	s, err = newEventStream("json", "")
+	assert.NoError(err)
+	assert.True(s.onStdout())
+
+	_, err = newEventStream("xml", "")
+	assert.Error(err)
+
+	_, err = newEventStream("", "hugo.sock")
+	assert.Error(err)
+}
+
+func TestEventStreamJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var out bytes.Buffer
+	s := &eventStream{out: &out}
+
+	now := time.Now()
+	s.handleBuildEvent(hugolib.BuildEvent{Type: hugolib.BuildStarted, Time: now})
+	s.handleBuildEvent(hugolib.BuildEvent{Type: hugolib.BuildPageRendered, Time: now, Lang: "en", Path: "post/a.md", URL: "/post/a/", OutputFormat: "HTML"})
+	s.handleBuildEvent(hugolib.BuildEvent{Type: hugolib.BuildFailed, Time: now, Duration: 1500 * time.Millisecond, Err: errors.New("failed")})
+
+	entries := decodeEvents(t, out.String())
+	assert.Len(entries, 3)
+	assert.Equal("started", entries[0].Type)
+	assert.Equal(now.Format(time.RFC3339Nano), entries[0].Time)
+	assert.Equal(eventEntry{Type: "pageRendered", Lang: "en", Path: "post/a.md", URL: "/post/a/", OutputFormat: "HTML"}, clearTime(entries[1]))
+	assert.Equal(eventEntry{Type: "failed", DurationMs: 1500, Error: "failed"}, clearTime(entries[2]))
+
+	out.Reset()
+	s.serverStarted(1313, "http://localhost:1313/")
+	entries = decodeEvents(t, out.String())
+	assert.Equal([]eventEntry{{Type: "server-started", Port: 1313, URL: "http://localhost:1313/"}}, []eventEntry{clearTime(entries[0])})
+
+	// No events configured.
+	var nilStream *eventStream
+	nilStream.serverStarted(1313, "http://localhost:1313/")
+}
+
+func TestEventStreamLoggedErrors(t *testing.T) {
+	assert := require.New(t)
+
+	var out bytes.Buffer
+	logW, err := newLogWriter(ioutil.Discard, logFormatText, false)
+	assert.NoError(err)
+	logW.events = &eventStream{out: &out}
+
+	logger := jww.NewNotepad(jww.LevelError, jww.LevelWarn, ioutil.Discard, logW, "", 0)
+	logger.WARN.Println("a warning")
+	logger.ERROR.Printf("[missingLayout] %s", "no layout for post/a.md")
+
+	entries := decodeEvents(t, out.String())
+	assert.Len(entries, 1)
+	assert.Equal(eventEntry{Type: "error", Code: "missingLayout", Error: "no layout for post/a.md"}, clearTime(entries[0]))
+}
+
+func TestEventStreamSocket(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir("", "hugo-events")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "hugo.sock")
+	s, err := newEventStream("json", socket)
+	assert.NoError(err)
+	assert.False(s.onStdout())
+	defer s.Close()
+
+	conn, err := net.Dial("unix", socket)
+	assert.NoError(err)
+	defer conn.Close()
+
+	// Wait for the client to be accepted.
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		n := len(s.clients)
+		s.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.handleBuildEvent(hugolib.BuildEvent{Type: hugolib.BuildFinished, Time: time.Now()})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(err)
+	assert.Equal(eventEntry{Type: "finished"}, clearTime(decodeEvents(t, line)[0]))
+
+	assert.NoError(s.Close())
+	_, err = os.Stat(socket)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestEventStreamFailingBuildStdout(t *testing.T) {
+	assert := require.New(t)
+	defer resetGlobalLoggers()
+	defer func() { buildEvents = nil }()
+
+	dir, err := ioutil.TempDir("", "hugo-events")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	for filename, content := range map[string]string{
+		"config.toml":  `baseURL = "http://example.com/"`,
+		"content/a.md": "---\ntitle: A\n---\nContent.",
+		"content/b.md": "---\ntitle: [B\n---\nContent.",
+	} {
+		filename = filepath.Join(dir, filepath.FromSlash(filename))
+		assert.NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+		assert.NoError(ioutil.WriteFile(filename, []byte(content), 0644))
+	}
+
+	stdout := captureStdout(t, func() {
+		HugoCmd.SetArgs([]string{"--source", dir, "--events", "json", "--renderToMemory"})
+		HugoCmd.SetOutput(ioutil.Discard)
+		defer HugoCmd.SetOutput(nil)
+		assert.Error(HugoCmd.Execute())
+	})
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	for _, line := range lines {
+		var entry eventEntry
+		assert.NoError(json.Unmarshal([]byte(line), &entry), line)
+		if entry.Type == buildEventError {
+			assert.NotRegexp(logTimeRe, entry.Error)
+		}
+	}
+
+	entries := decodeEvents(t, stdout)
+	assert.Equal("failed", entries[len(entries)-1].Type)
+}
+
+func decodeEvents(t *testing.T, s string) []eventEntry {
+	var entries []eventEntry
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry eventEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		require.NotEmpty(t, entry.Time)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func clearTime(e eventEntry) eventEntry {
+	e.Time = ""
+	return e
+}
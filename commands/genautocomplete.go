@@ -14,13 +14,15 @@
 package commands
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
 )
 
 var autocompleteTarget string
 
-// bash for now (zsh and others will come)
+// One of bash, zsh, fish or powershell.
 var autocompleteType string
 
 var genautocompleteCmd = &cobra.Command{
@@ -28,8 +30,8 @@ var genautocompleteCmd = &cobra.Command{
 	Short: "Generate shell autocompletion script for Hugo",
 	Long: `Generates a shell autocompletion script for Hugo.
 
-NOTE: The current version supports Bash only.
-      This should work for *nix systems with Bash installed.
+The supported shells are bash, zsh, fish and powershell, set with --type.
+To write the script to stdout, see hugo completion.
 
 By default, the file is written directly to /etc/bash_completion.d
 for convenience, and the command may need superuser rights, e.g.:
@@ -45,17 +47,17 @@ or just source them in directly:
 	$ . /etc/bash_completion`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if autocompleteType != "bash" {
-			return newUserError("Only Bash is supported for now")
+		f, err := os.Create(autocompleteTarget)
+		if err != nil {
+			return err
 		}
+		defer f.Close()
 
-		err := cmd.Root().GenBashCompletionFile(autocompleteTarget)
-
-		if err != nil {
+		if err := genCompletion(cmd.Root(), f, autocompleteType); err != nil {
 			return err
 		}
 
-		jww.FEEDBACK.Println("Bash completion file for Hugo saved to", autocompleteTarget)
+		jww.FEEDBACK.Println("Completion file for Hugo saved to", autocompleteTarget)
 
 		return nil
 	},
@@ -63,7 +65,7 @@ or just source them in directly:
 
 func init() {
 	genautocompleteCmd.PersistentFlags().StringVarP(&autocompleteTarget, "completionfile", "", "/etc/bash_completion.d/hugo.sh", "autocompletion file")
-	genautocompleteCmd.PersistentFlags().StringVarP(&autocompleteType, "type", "", "bash", "autocompletion type (currently only bash supported)")
+	genautocompleteCmd.PersistentFlags().StringVarP(&autocompleteType, "type", "", "bash", "autocompletion type (bash, zsh, fish or powershell)")
 
 	// For bash-completion
 	genautocompleteCmd.PersistentFlags().SetAnnotation("completionfile", cobra.BashCompFilenameExt, []string{})
@@ -14,11 +14,19 @@
 package commands
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/alecthomas/chroma"
-	"github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugolib"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +34,14 @@ type genChromaStyles struct {
 	style          string
 	highlightStyle string
 	linesStyle     string
+	pygments       bool
+	listStyles     bool
+	listLexers     bool
 	cmd            *cobra.Command
+
+	// The site config with the custom styles, nil if not in a site.
+	cfg    config.Provider
+	cfgErr error
 }
 
 // TODO(bep) highlight
@@ -37,23 +52,84 @@ func createGenChromaStyles() *genChromaStyles {
 			Short: "Generate CSS stylesheet for the Chroma code highlighter",
 			Long: `Generate CSS stylesheet for the Chroma code highlighter for a given style. This stylesheet is needed if pygmentsUseClasses is enabled in config.
 
+Use --pygments to scope the CSS to the highlight class used by Pygments, which
+also matches the code blocks highlighted by Chroma. Use --list and --lexers to
+list the available styles and languages.
+
+Custom styles defined in pygmentsCustomStyles in the site config are written
+to the pygmentsCSS path at build time, and can be generated with --style when
+run in the site.
+
 See https://help.farbox.com/pygments.html for preview of available styles`,
 		},
 	}
 
 	g.cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		return g.generate()
+		// The custom styles are read from the site config, if any.
+		if cfg, err := hugolib.LoadConfig(hugofs.Os, source, cfgFile); err != nil {
+			g.cfgErr = err
+		} else {
+			g.cfg = cfg
+		}
+		return g.generate(os.Stdout)
 	}
 
 	g.cmd.PersistentFlags().StringVar(&g.style, "style", "friendly", "highlighter style (see https://help.farbox.com/pygments.html)")
 	g.cmd.PersistentFlags().StringVar(&g.highlightStyle, "highlightStyle", "bg:#ffffcc", "style used for highlighting lines (see https://github.com/alecthomas/chroma)")
 	g.cmd.PersistentFlags().StringVar(&g.linesStyle, "linesStyle", "", "style used for line numbers (see https://github.com/alecthomas/chroma)")
+	g.cmd.PersistentFlags().BoolVar(&g.pygments, "pygments", false, "generate Pygments compatible CSS, scoped to the highlight class")
+	g.cmd.PersistentFlags().BoolVar(&g.listStyles, "list", false, "list the available styles")
+	g.cmd.PersistentFlags().BoolVar(&g.listLexers, "lexers", false, "list the available lexers, with their aliases and file names")
 
 	return g
 }
 
-func (g *genChromaStyles) generate() error {
-	builder := styles.Get(g.style).Builder()
+func (g *genChromaStyles) generate(w io.Writer) error {
+	var customStyles map[string]*chroma.Style
+	if g.cfg != nil {
+		var err error
+		if customStyles, err = helpers.NewChromaStyles(g.cfg); err != nil {
+			return newUserError(err)
+		}
+	}
+
+	if g.listStyles {
+		names := styles.Names()
+		for _, s := range customStyles {
+			if _, found := styles.Registry[strings.ToLower(s.Name)]; !found {
+				names = append(names, s.Name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(w, name)
+		}
+		return nil
+	}
+
+	if g.listLexers {
+		all := make(chroma.Lexers, len(lexers.Registry.Lexers))
+		copy(all, lexers.Registry.Lexers)
+		sort.Sort(all)
+		for _, l := range all {
+			config := l.Config()
+			fmt.Fprintf(w, "%s\n    aliases: %s\n    filenames: %s\n", config.Name, strings.Join(config.Aliases, " "), strings.Join(config.Filenames, " "))
+		}
+		return nil
+	}
+
+	base, found := customStyles[strings.ToLower(g.style)]
+	if !found {
+		base, found = styles.Registry[strings.ToLower(g.style)]
+	}
+	if !found {
+		if g.cfgErr != nil {
+			return newUserError(fmt.Sprintf("unknown style %q, see --list for the available styles; the custom styles are not available: %s", g.style, g.cfgErr))
+		}
+		return newUserError(fmt.Sprintf("unknown style %q, see --list for the available styles", g.style))
+	}
+
+	builder := base.Builder()
 	if g.highlightStyle != "" {
 		builder.Add(chroma.LineHighlight, g.highlightStyle)
 	}
@@ -64,7 +140,11 @@ func (g *genChromaStyles) generate() error {
 	if err != nil {
 		return err
 	}
-	formatter := html.New(html.WithClasses())
-	formatter.WriteCSS(os.Stdout, style)
-	return nil
+
+	selector := helpers.ChromaCSSSelector
+	if g.pygments {
+		selector = helpers.PygmentsCSSSelector
+	}
+
+	return helpers.WriteChromaCSS(w, style, selector)
 }
@@ -0,0 +1,69 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenChromaStyles(t *testing.T) {
+	assert := require.New(t)
+
+	var out bytes.Buffer
+	g := &genChromaStyles{style: "monokai", highlightStyle: "bg:#ffffcc"}
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "/* Background */ .chroma { ")
+	assert.Contains(out.String(), "/* LineHighlight */ .chroma .hl { background-color: #ffffcc;")
+
+	out.Reset()
+	g.pygments = true
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "/* Background */ .highlight { ")
+	assert.Contains(out.String(), "/* Keyword */ .highlight .k { ")
+	assert.NotContains(out.String(), ".chroma")
+
+	g.style = "nope"
+	assert.Error(g.generate(&out))
+
+	out.Reset()
+	g.listStyles = true
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "\nmonokai\n")
+
+	out.Reset()
+	g = &genChromaStyles{listLexers: true}
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "Go\n    aliases: go golang\n    filenames: *.go\n")
+
+	out.Reset()
+	cfg := viper.New()
+	cfg.Set("pygmentsCustomStyles", map[string]interface{}{
+		"myStyle": map[string]interface{}{
+			"base":    "monokai",
+			"Keyword": "bold #ff79c6",
+		},
+	})
+	g = &genChromaStyles{style: "mystyle", cfg: cfg}
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "/* Keyword */ .chroma .k { color: #ff79c6; font-weight: bold }")
+
+	out.Reset()
+	g.listStyles = true
+	assert.NoError(g.generate(&out))
+	assert.Contains(out.String(), "\nmystyle\n")
+}
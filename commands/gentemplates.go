@@ -0,0 +1,158 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/tpl/tplimpl"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+type genTemplates struct {
+	dir   string
+	all   bool
+	force bool
+	diff  bool
+	cmd   *cobra.Command
+}
+
+func createGenTemplates() *genTemplates {
+	g := &genTemplates{
+		cmd: &cobra.Command{
+			Use:   "templates [path...]",
+			Short: "List or extract the templates embedded in Hugo",
+			Long: `List the templates embedded in Hugo, e.g. the RSS and sitemap templates
+and the built-in shortcodes, or extract them into the layouts directory
+for customization, e.g.
+
+	hugo gen templates _default/rss.xml shortcodes/figure.html
+
+The extracted templates record the version of the embedded template they
+were extracted from, and Hugo warns when building if the embedded template
+has changed since. Use --diff to compare them with the current version.
+
+The embedded partials, e.g. disqus.html, are extracted to the partials
+directory and must be included with the partial func instead of the
+template func.`,
+		},
+	}
+
+	g.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return g.run(hugofs.Os, os.Stdout, args)
+	}
+
+	g.cmd.Flags().StringVar(&g.dir, "dir", "layouts", "the layouts directory to extract the templates to")
+	g.cmd.Flags().BoolVar(&g.all, "all", false, "extract all embedded templates")
+	g.cmd.Flags().BoolVar(&g.force, "force", false, "overwrite existing templates")
+	g.cmd.Flags().BoolVar(&g.diff, "diff", false, "show the differences between the extracted and the embedded templates")
+
+	return g
+}
+
+func (g *genTemplates) run(fs afero.Fs, w io.Writer, args []string) error {
+	var templates []tplimpl.EmbeddedTemplate
+	if g.all {
+		templates = tplimpl.EmbeddedTemplates()
+	} else {
+		for _, name := range args {
+			e, found := tplimpl.GetEmbeddedTemplate(filepath.ToSlash(name))
+			if !found {
+				return newUserError(fmt.Sprintf("no embedded template %q, run \"hugo gen templates\" to list them", name))
+			}
+			templates = append(templates, e)
+		}
+	}
+
+	if len(templates) == 0 {
+		if g.diff {
+			return g.diffAll(fs, w)
+		}
+		for _, e := range tplimpl.EmbeddedTemplates() {
+			fmt.Fprintf(w, "%-35s %s\n", e.Path(), e.Name)
+		}
+		return nil
+	}
+
+	for _, e := range templates {
+		if g.diff {
+			if err := g.diffTemplate(fs, w, e); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.extract(fs, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *genTemplates) extract(fs afero.Fs, e tplimpl.EmbeddedTemplate) error {
+	filename := filepath.Join(g.dir, filepath.FromSlash(e.Path()))
+
+	if exists, _ := helpers.Exists(filename, fs); exists && !g.force {
+		return newUserError(fmt.Sprintf("%s already exists, use --force to overwrite it", filename))
+	}
+
+	if err := helpers.WriteToDisk(filename, bytes.NewReader([]byte(e.Pinned())), fs); err != nil {
+		return newSystemError("Failed to write template", err)
+	}
+
+	jww.FEEDBACK.Println("Extracted", e.Name, "to", filename)
+
+	return nil
+}
+
+// diffAll shows the differences for the extracted templates in the layouts
+// dir.
+func (g *genTemplates) diffAll(fs afero.Fs, w io.Writer) error {
+	for _, e := range tplimpl.EmbeddedTemplates() {
+		filename := filepath.Join(g.dir, filepath.FromSlash(e.Path()))
+		if exists, _ := helpers.Exists(filename, fs); !exists {
+			continue
+		}
+		if err := g.diffTemplate(fs, w, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *genTemplates) diffTemplate(fs afero.Fs, w io.Writer, e tplimpl.EmbeddedTemplate) error {
+	filename := filepath.Join(g.dir, filepath.FromSlash(e.Path()))
+
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return newUserError(fmt.Sprintf("failed to read %s: %s", filename, err))
+	}
+
+	return difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(e.Pinned()),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: e.Name,
+		ToFile:   filename,
+		Context:  3,
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/tpl/tplimpl"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenTemplates(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	g := &genTemplates{dir: "layouts"}
+
+	var out bytes.Buffer
+	assert.NoError(g.run(fs, &out, nil))
+	assert.Contains(out.String(), "_default/rss.xml")
+	assert.Contains(out.String(), "partials/disqus.html")
+
+	assert.NoError(g.run(fs, &out, []string{"_default/rss.xml", "shortcodes/figure.html"}))
+
+	rss, _ := tplimpl.GetEmbeddedTemplate("_default/rss.xml")
+	b, err := afero.ReadFile(fs, filepath.FromSlash("layouts/_default/rss.xml"))
+	assert.NoError(err)
+	assert.Equal(rss.Pinned(), string(b))
+
+	exists, _ := afero.Exists(fs, filepath.FromSlash("layouts/shortcodes/figure.html"))
+	assert.True(exists)
+
+	// Do not overwrite without --force.
+	assert.Error(g.run(fs, &out, []string{"_default/rss.xml"}))
+	assert.Error(g.run(fs, &out, []string{"_default/nope.html"}))
+
+	g.diff = true
+	out.Reset()
+	assert.NoError(g.run(fs, &out, nil))
+	assert.Empty(out.String())
+
+	assert.NoError(afero.WriteFile(fs, filepath.FromSlash("layouts/_default/rss.xml"), []byte("<rss>"), 0755))
+	assert.NoError(g.run(fs, &out, nil))
+	assert.Contains(out.String(), "--- _internal/_default/rss.xml")
+	assert.Contains(out.String(), "+<rss>")
+	assert.NotContains(out.String(), "figure.html")
+}
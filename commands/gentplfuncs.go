@@ -0,0 +1,77 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/tpl/tplimpl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type genTplFuncs struct {
+	json bool
+	cmd  *cobra.Command
+}
+
+func createGenTplFuncs() *genTplFuncs {
+	g := &genTplFuncs{
+		cmd: &cobra.Command{
+			Use:   "tplfuncs",
+			Short: "List the template funcs with their signatures and examples",
+			Long: `List the template funcs built into Hugo with their namespaces, aliases,
+signatures and examples. Use --json for a machine-readable catalog, e.g.
+for editor autocompletion.
+
+The argument names and descriptions are read from the Go doc, so they are
+only included when run from the root of the Hugo source.`,
+		},
+	}
+
+	g.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return g.run(os.Stdout)
+	}
+
+	g.cmd.Flags().BoolVar(&g.json, "json", false, "print the template funcs as JSON")
+
+	return g
+}
+
+func (g *genTplFuncs) run(w io.Writer) error {
+	funcs := tplimpl.TemplateFuncs(&deps.Deps{Cfg: viper.New()})
+
+	if g.json {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(funcs)
+	}
+
+	for _, f := range funcs {
+		fmt.Fprintf(w, "%s.%s%s\n", f.Namespace, f.Name, f.Signature)
+		if len(f.Aliases) > 0 {
+			fmt.Fprintf(w, "    aliases: %s\n", strings.Join(f.Aliases, ", "))
+		}
+		for _, e := range f.Examples {
+			fmt.Fprintf(w, "    %s → %s\n", e[0], e[1])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenTplFuncs(t *testing.T) {
+	assert := require.New(t)
+
+	g := &genTplFuncs{}
+
+	var out bytes.Buffer
+	assert.NoError(g.run(&out))
+	assert.Contains(out.String(), "strings.Trim(")
+	assert.Contains(out.String(), "aliases: trim")
+
+	g.json = true
+	out.Reset()
+	assert.NoError(g.run(&out))
+
+	var funcs []struct {
+		Namespace string
+		Name      string
+		Aliases   []string
+		Signature string
+		Examples  [][2]string
+	}
+	assert.NoError(json.Unmarshal(out.Bytes(), &funcs))
+
+	var found bool
+	for i, f := range funcs {
+		if i > 0 {
+			prev := funcs[i-1]
+			assert.True(prev.Namespace < f.Namespace || prev.Namespace == f.Namespace && prev.Name < f.Name)
+		}
+		if f.Namespace == "strings" && f.Name == "Trim" {
+			found = true
+			assert.Equal([]string{"trim"}, f.Aliases)
+			assert.Contains(f.Signature, ") (string, error)")
+			assert.NotEmpty(f.Examples)
+		}
+	}
+	assert.True(found)
+}
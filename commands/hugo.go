@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"sort"
-	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
@@ -47,14 +46,14 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugolib"
 	"github.com/gohugoio/hugo/livereload"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/utils"
 	"github.com/gohugoio/hugo/watcher"
 	"github.com/spf13/afero"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
-	"github.com/spf13/fsync"
 	jww "github.com/spf13/jwalterweatherman"
 	"github.com/spf13/nitro"
-	"github.com/spf13/viper"
 )
 
 // Hugo represents the Hugo sites to build. This variable is exported as it
@@ -62,6 +61,10 @@ import (
 // provide a cleaner external API, but until then, this is it.
 var Hugo *hugolib.HugoSites
 
+// buildMu serializes the rebuilds on file changes and the config reloads,
+// which replace the commandeer and Hugo, in server and watch mode.
+var buildMu sync.Mutex
+
 const (
 	ansiEsc    = "\u001B"
 	clearLine  = "\r\033[K"
@@ -127,6 +130,12 @@ built with love by spf13 and friends in Go.
 
 Complete documentation is available at http://gohugo.io/.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspace != "" {
+			if buildWatch {
+				return newUserError("--watch is not supported with --workspace")
+			}
+			return buildWorkspace(workspace)
+		}
 
 		cfgInit := func(c *commandeer) error {
 			if buildWatch {
@@ -163,19 +172,21 @@ var (
 )
 
 var (
-	gc              bool
-	baseURL         string
-	cacheDir        string
-	contentDir      string
-	layoutDir       string
-	cfgFile         string
-	destination     string
-	logFile         string
-	theme           string
-	themesDir       string
-	source          string
-	logI18nWarnings bool
-	disableKinds    []string
+	gc                 bool
+	baseURL            string
+	cacheDir           string
+	contentDir         string
+	layoutDir          string
+	cfgFile            string
+	destination        string
+	logFile            string
+	theme              string
+	themesDir          string
+	source             string
+	logI18nWarnings    bool
+	disableKinds       []string
+	ignoreRemoteErrors []string
+	workspace          string
 )
 
 // Execute adds all child commands to the root command HugoCmd and sets flags appropriately.
@@ -186,7 +197,14 @@ func Execute() {
 
 	AddCommands()
 
-	if c, err := HugoCmd.ExecuteC(); err != nil {
+	c, err := HugoCmd.ExecuteC()
+
+	// Let the plugin processes exit cleanly.
+	plugins.StopAll()
+	buildEvents.Close()
+	resetGlobalLoggers()
+
+	if err != nil {
 		if isUserError(err) {
 			c.Println("")
 			c.Println(c.UsageString())
@@ -201,6 +219,7 @@ func AddCommands() {
 	HugoCmd.AddCommand(serverCmd)
 	HugoCmd.AddCommand(versionCmd)
 	HugoCmd.AddCommand(envCmd)
+	HugoCmd.AddCommand(doctorCmd)
 	HugoCmd.AddCommand(configCmd)
 	HugoCmd.AddCommand(checkCmd)
 	HugoCmd.AddCommand(benchmarkCmd)
@@ -209,13 +228,21 @@ func AddCommands() {
 	HugoCmd.AddCommand(listCmd)
 	HugoCmd.AddCommand(undraftCmd)
 	HugoCmd.AddCommand(importCmd)
+	HugoCmd.AddCommand(modCmd)
+	HugoCmd.AddCommand(themeCmd)
+	HugoCmd.AddCommand(diffCmd)
+	HugoCmd.AddCommand(debugCmd)
 
+	HugoCmd.AddCommand(completionCmd)
+	HugoCmd.AddCommand(completeCmd)
 	HugoCmd.AddCommand(genCmd)
 	genCmd.AddCommand(genautocompleteCmd)
 	genCmd.AddCommand(gendocCmd)
 	genCmd.AddCommand(genmanCmd)
 	genCmd.AddCommand(createGenDocsHelper().cmd)
 	genCmd.AddCommand(createGenChromaStyles().cmd)
+	genCmd.AddCommand(createGenTemplates().cmd)
+	genCmd.AddCommand(createGenTplFuncs().cmd)
 
 }
 
@@ -228,6 +255,7 @@ func initHugoBuilderFlags(cmd *cobra.Command) {
 func initRootPersistentFlags() {
 	HugoCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is path/config.yaml|json|toml)")
 	HugoCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "build in quiet mode")
+	HugoCmd.PersistentFlags().StringArrayVarP(&configOverrides, "set", "e", nil, "override a config value, e.g. --set params.env=staging, can be repeated")
 
 	// Set bash-completion
 	validConfigFilenames := []string{"json", "js", "yaml", "yml", "toml", "tml"}
@@ -248,11 +276,13 @@ func initHugoBuildCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&layoutDir, "layoutDir", "l", "", "filesystem path to layout directory")
 	cmd.Flags().StringVarP(&cacheDir, "cacheDir", "", "", "filesystem path to cache directory. Defaults: $TMPDIR/hugo_cache/")
 	cmd.Flags().BoolP("ignoreCache", "", false, "ignores the cache directory")
+	cmd.Flags().StringSliceVar(&ignoreRemoteErrors, "ignoreRemoteErrors", []string{}, "URL patterns, e.g. https://api.example.com/*, for which failed remote fetches use the last cached version")
 	cmd.Flags().StringVarP(&destination, "destination", "d", "", "filesystem path to write files to")
 	cmd.Flags().StringVarP(&theme, "theme", "t", "", "theme to use (located in /themes/THEMENAME/)")
 	cmd.Flags().StringVarP(&themesDir, "themesDir", "", "", "filesystem path to themes directory")
 	cmd.Flags().Bool("uglyURLs", false, "if true, use /filename.html instead of /filename/")
 	cmd.Flags().Bool("canonifyURLs", false, "if true, all relative URLs will be canonicalized using baseURL")
+	cmd.Flags().Bool("offlineURLs", false, "if true, all links are made relative so the site can be opened from the file system")
 	cmd.Flags().StringVarP(&baseURL, "baseURL", "b", "", "hostname (and path) to the root, e.g. http://spf13.com/")
 	cmd.Flags().Bool("enableGitInfo", false, "add Git revision, date and author info to the pages")
 	cmd.Flags().BoolVar(&gc, "gc", false, "enable to run some cleanup tasks (remove unused cache files) after the build")
@@ -260,12 +290,19 @@ func initHugoBuildCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&nitro.AnalysisOn, "stepAnalysis", false, "display memory and timing of different steps of the program")
 	cmd.Flags().Bool("templateMetrics", false, "display metrics about template executions")
 	cmd.Flags().Bool("templateMetricsHints", false, "calculate some improvement hints when combined with --templateMetrics")
+	cmd.Flags().Bool("templateCoverage", false, "report the templates that were never executed")
 	cmd.Flags().Bool("pluralizeListTitles", true, "pluralize titles in lists using inflect")
 	cmd.Flags().Bool("preserveTaxonomyNames", false, `preserve taxonomy names as written ("Gérard Depardieu" vs "gerard-depardieu")`)
 	cmd.Flags().BoolP("forceSyncStatic", "", false, "copy all files when static is changed.")
 	cmd.Flags().BoolP("noTimes", "", false, "don't sync modification time of files")
 	cmd.Flags().BoolP("noChmod", "", false, "don't sync permission mode of files")
 	cmd.Flags().BoolVarP(&logI18nWarnings, "i18n-warnings", "", false, "print missing translations")
+	cmd.Flags().Bool("printDuplicates", false, "print pages with duplicate titles, permalinks or content after the build")
+	cmd.Flags().Bool("printTaxonomyMerges", false, "print the taxonomy terms merged by the taxonomyNormalization config after the build")
+	cmd.Flags().Bool("skipImages", false, "use placeholders instead of processing images, for faster builds during development")
+
+	cmd.Flags().String("events", "", "stream build events, e.g. pages rendered and errors, in the given format (json) to stdout for GUI frontends")
+	cmd.Flags().String("eventsSocket", "", "write the --events stream to clients of this Unix socket instead of stdout")
 
 	cmd.Flags().StringSliceVar(&disableKinds, "disableKinds", []string{}, "disable different kind of pages (home, RSS etc.)")
 
@@ -288,12 +325,15 @@ func init() {
 	HugoCmd.PersistentFlags().BoolVar(&logging, "log", false, "enable Logging")
 	HugoCmd.PersistentFlags().StringVar(&logFile, "logFile", "", "log File path (if set, logging enabled automatically)")
 	HugoCmd.PersistentFlags().BoolVar(&verboseLog, "verboseLog", false, "verbose logging")
+	HugoCmd.PersistentFlags().String("logFormat", logFormatText, "log format: text or json")
+	HugoCmd.PersistentFlags().Bool("panicOnWarning", false, "panic on the first warning logged")
 
 	initRootPersistentFlags()
 	initHugoBuilderFlags(HugoCmd)
 	initBenchmarkBuildingFlags(HugoCmd)
 
 	HugoCmd.Flags().BoolVarP(&buildWatch, "watch", "w", false, "watch filesystem for changes and recreate as needed")
+	HugoCmd.Flags().StringVar(&workspace, "workspace", "", "build all the sites listed in the given workspace file")
 	hugoCmdV = HugoCmd
 
 	// Set bash-completion
@@ -308,7 +348,18 @@ func InitializeConfig(running bool, doWithCommandeer func(c *commandeer) error,
 	// Init file systems. This may be changed at a later point.
 	osFs := hugofs.Os
 
-	config, err := hugolib.LoadConfig(osFs, source, cfgFile)
+	// The theme flags must be set before the theme config is loaded.
+	config, err := hugolib.LoadConfig(osFs, source, cfgFile, func(cfg config.Provider) error {
+		if theme != "" {
+			cfg.Set("theme", theme)
+		}
+
+		if themesDir != "" {
+			cfg.Set("themesDir", themesDir)
+		}
+
+		return applyConfigOverrides(cfg, configOverrides)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -320,6 +371,8 @@ func InitializeConfig(running bool, doWithCommandeer func(c *commandeer) error,
 	if err != nil {
 		return nil, err
 	}
+	c.doWithCommandeer = doWithCommandeer
+	c.subCmdVs = subCmdVs
 
 	for _, cmdV := range append([]*cobra.Command{hugoCmdV}, subCmdVs...) {
 		c.initializeFlags(cmdV)
@@ -339,6 +392,17 @@ func InitializeConfig(running bool, doWithCommandeer func(c *commandeer) error,
 		c.Set("disableKinds", disableKinds)
 	}
 
+	if len(ignoreRemoteErrors) > 0 {
+		c.Set("ignoreRemoteErrors", ignoreRemoteErrors)
+	}
+
+	if buildEvents == nil {
+		buildEvents, err = newEventStream(strings.ToLower(config.GetString("events")), config.GetString("eventsSocket"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	logger, err := createLogger(cfg.Cfg)
 	if err != nil {
 		return nil, err
@@ -348,18 +412,10 @@ func InitializeConfig(running bool, doWithCommandeer func(c *commandeer) error,
 
 	config.Set("logI18nWarnings", logI18nWarnings)
 
-	if !config.GetBool("relativeURLs") && config.GetString("baseURL") == "" {
+	if !config.GetBool("relativeURLs") && !config.GetBool("offlineURLs") && config.GetString("baseURL") == "" {
 		cfg.Logger.ERROR.Println("No 'baseURL' set in configuration or as a flag. Features like page menus will not work without one.")
 	}
 
-	if theme != "" {
-		config.Set("theme", theme)
-	}
-
-	if themesDir != "" {
-		config.Set("themesDir", themesDir)
-	}
-
 	if destination != "" {
 		config.Set("publishDir", destination)
 	}
@@ -439,8 +495,10 @@ func createLogger(cfg config.Provider) (*jww.Notepad, error) {
 		logHandle       = ioutil.Discard
 		logThreshold    = jww.LevelWarn
 		logFile         = cfg.GetString("logFile")
-		outHandle       = os.Stdout
 		stdoutThreshold = jww.LevelError
+
+		// Keep the event stream on stdout parseable.
+		outHandle = humanOutput()
 	)
 
 	if verboseLog || logging || (logFile != "") {
@@ -471,23 +529,53 @@ func createLogger(cfg config.Provider) (*jww.Notepad, error) {
 		}
 	}
 
-	// The global logger is used in some few cases.
-	jww.SetLogOutput(logHandle)
-	jww.SetLogThreshold(logThreshold)
-	jww.SetStdoutThreshold(stdoutThreshold)
-	helpers.InitLoggers()
+	logFormat := strings.ToLower(cfg.GetString("logFormat"))
+	flags := log.Ldate | log.Ltime
+	if logFormat == logFormatJSON {
+		// The time is part of the JSON entry.
+		flags = 0
+	}
+
+	// Warnings always reach the log handle, so that is where to look for them.
+	logW, err := newLogWriter(logHandle, logFormat, cfg.GetBool("panicOnWarning"))
+	if err != nil {
+		return nil, newUserError(err)
+	}
+	logW.events = buildEvents
+	outW, _ := newLogWriter(outHandle, logFormat, false)
+
+	// The global logger is used in some few cases, e.g. by
+	// helpers.DistinctErrorLog. Its lines go through the same writers,
+	// so they are formatted the same way.
+	globalThreshold := logThreshold
+	if stdoutThreshold < globalThreshold {
+		globalThreshold = stdoutThreshold
+	}
+	jww.SetLogOutput(&globalLogWriter{log: logW, out: outW, logThreshold: logThreshold, stdoutThreshold: stdoutThreshold})
+	jww.SetLogThreshold(globalThreshold)
+	jww.SetStdoutThreshold(jww.LevelFatal + 1)
+	jww.SetFlags(flags)
+
+	logger := jww.NewNotepad(stdoutThreshold, logThreshold, outW, logW, "", flags)
+
+	// The global feedback logger writes to its stdout handle directly. It
+	// is restored by resetGlobalLoggers.
+	jww.FEEDBACK = logger.FEEDBACK
 
-	return jww.NewNotepad(stdoutThreshold, logThreshold, outHandle, logHandle, "", log.Ldate|log.Ltime), nil
+	helpers.InitLoggers(cast.ToStringSlice(cfg.Get("ignoreLogs"))...)
+
+	return logger, nil
 }
 
 func (c *commandeer) initializeFlags(cmd *cobra.Command) {
-	persFlagKeys := []string{"debug", "verbose", "logFile"}
+	persFlagKeys := []string{"debug", "verbose", "logFile", "logFormat", "panicOnWarning"}
 	flagKeys := []string{
 		"buildDrafts",
 		"buildFuture",
 		"buildExpired",
 		"uglyURLs",
 		"canonifyURLs",
+		"offlineURLs",
 		"disable404",
 		"disableRSS",
 		"disableSitemap",
@@ -501,6 +589,12 @@ func (c *commandeer) initializeFlags(cmd *cobra.Command) {
 		"noChmod",
 		"templateMetrics",
 		"templateMetricsHints",
+		"templateCoverage",
+		"printDuplicates",
+		"printTaxonomyMerges",
+		"skipImages",
+		"events",
+		"eventsSocket",
 	}
 
 	// Remove these in Hugo 0.33.
@@ -532,20 +626,6 @@ func (c *commandeer) setValueFromFlag(flags *flag.FlagSet, key string) {
 	}
 }
 
-func (c *commandeer) watchConfig() {
-	v := c.Cfg.(*viper.Viper)
-	v.WatchConfig()
-	v.OnConfigChange(func(e fsnotify.Event) {
-		c.Logger.FEEDBACK.Println("Config file changed:", e.Name)
-		// Force a full rebuild
-		utils.CheckErr(c.Logger, c.recreateAndBuildSites(true))
-		if !c.Cfg.GetBool("disableLiveReload") {
-			// Will block forever trying to write to a channel that nobody is reading if livereload isn't initialized
-			livereload.ForceRefresh()
-		}
-	})
-}
-
 func (c *commandeer) fullBuild(watches ...bool) error {
 	var (
 		g         errgroup.Group
@@ -553,9 +633,10 @@ func (c *commandeer) fullBuild(watches ...bool) error {
 	)
 
 	if !quiet {
-		fmt.Print(hideCursor + "Building sites … ")
+		out := humanOutput()
+		fmt.Fprint(out, hideCursor+"Building sites … ")
 		defer func() {
-			fmt.Print(showCursor + clearLine)
+			fmt.Fprint(out, showCursor+clearLine)
 		}()
 	}
 
@@ -595,6 +676,15 @@ func (c *commandeer) fullBuild(watches ...bool) error {
 		}
 	}
 
+	count, err := Hugo.Precompress()
+	if err != nil {
+		return fmt.Errorf("Error precompressing files: %s", err)
+	}
+	for _, s := range Hugo.Sites {
+		// The publish dir is shared by the sites.
+		s.ProcessingStats.Precompressed = uint64(count)
+	}
+
 	return nil
 
 }
@@ -606,11 +696,29 @@ func (c *commandeer) build(watches ...bool) error {
 		return err
 	}
 
+	out := humanOutput()
+
 	// TODO(bep) Feedback?
 	if !quiet {
-		fmt.Println()
-		Hugo.PrintProcessingStats(os.Stdout)
-		fmt.Println()
+		fmt.Fprintln(out)
+		Hugo.PrintProcessingStats(out)
+		fmt.Fprintln(out)
+
+		if Hugo.PrintNextScheduledChange(out, time.Now()) {
+			fmt.Fprintln(out)
+		}
+	}
+
+	if c.Cfg.GetBool("printDuplicates") {
+		if Hugo.PrintDuplicatesReport(out) > 0 {
+			fmt.Fprintln(out)
+		}
+	}
+
+	if c.Cfg.GetBool("printTaxonomyMerges") {
+		if Hugo.PrintTaxonomyMergesReport(out) > 0 {
+			fmt.Fprintln(out)
+		}
 	}
 
 	if buildWatch {
@@ -678,21 +786,6 @@ func (c *commandeer) doWithPublishDirs(f func(dirs *src.Dirs, publishDir string)
 	return langCount, nil
 }
 
-type countingStatFs struct {
-	afero.Fs
-	statCounter uint64
-}
-
-func (fs *countingStatFs) Stat(name string) (os.FileInfo, error) {
-	f, err := fs.Fs.Stat(name)
-	if err == nil {
-		if !f.IsDir() {
-			atomic.AddUint64(&fs.statCounter, 1)
-		}
-	}
-	return f, err
-}
-
 func (c *commandeer) copyStaticTo(dirs *src.Dirs, publishDir string) (uint64, error) {
 
 	// If root, remove the second '/'
@@ -715,34 +808,19 @@ func (c *commandeer) copyStaticTo(dirs *src.Dirs, publishDir string) (uint64, er
 		return 0, nil
 	}
 
-	fs := &countingStatFs{Fs: staticSourceFs}
-
-	syncer := fsync.NewSyncer()
-	syncer.NoTimes = c.Cfg.GetBool("noTimes")
-	syncer.NoChmod = c.Cfg.GetBool("noChmod")
-	syncer.SrcFs = fs
-	syncer.DestFs = c.Fs.Destination
+	syncer := newStaticSync(c, staticSourceFs, publishDir)
 
-	if syncer.Delete {
-		c.Logger.INFO.Println("removing all files from destination that don't exist in static dirs")
-
-		syncer.DeleteFilter = func(f os.FileInfo) bool {
-			return f.IsDir() && strings.HasPrefix(f.Name(), ".")
-		}
-	}
 	c.Logger.INFO.Println("syncing static files to", publishDir)
 
 	// because we are using a baseFs (to get the union right).
 	// set sync src to root
-	err = syncer.Sync(publishDir, helpers.FilePathSeparator)
-	if err != nil {
+	if err := syncer.sync(publishDir, helpers.FilePathSeparator); err != nil {
 		return 0, err
 	}
 
-	// Sync runs Stat 3 times for every source file (which sounds much)
-	numFiles := fs.statCounter / 3
+	c.Logger.INFO.Printf("synced %d static files, %d unchanged", syncer.files, syncer.skipped)
 
-	return numFiles, err
+	return syncer.files, nil
 }
 
 func (c *commandeer) timeTrack(start time.Time, name string) {
@@ -916,6 +994,9 @@ func (c *commandeer) initSites() error {
 	if err != nil {
 		return err
 	}
+	if buildEvents != nil {
+		h.OnBuildEvent(buildEvents.handleBuildEvent)
+	}
 	Hugo = h
 
 	return nil
@@ -949,7 +1030,18 @@ func (c *commandeer) rebuildSites(events []fsnotify.Event) error {
 		}
 
 	}
-	return Hugo.Build(hugolib.BuildCfg{RecentlyVisited: visited}, events...)
+	if err := Hugo.Build(hugolib.BuildCfg{RecentlyVisited: visited}, events...); err != nil {
+		return err
+	}
+
+	if servePrecompressed {
+		// Keep the compressed siblings served by the server up to date.
+		if _, err := Hugo.Precompress(); err != nil {
+			return fmt.Errorf("Error precompressing files: %s", err)
+		}
+	}
+
+	return nil
 }
 
 // newWatcher creates a new watcher to watch filesystem events.
@@ -982,203 +1074,234 @@ func (c *commandeer) newWatcher(serve bool, dirList ...string) error {
 		}
 	}
 
+	// Kept for the config reloads, which may move the dirs.
+	buildMu.Lock()
+	c.contentWatcher = watcher
+	c.watchedDirs = dirList
+	buildMu.Unlock()
+
+	remoteDataEvents, stopRemoteData := c.watchRemoteData()
+	defer stopRemoteData()
+
 	go func() {
 		for {
 			select {
+			case evs := <-remoteDataEvents:
+				buildMu.Lock()
+				c.Logger.FEEDBACK.Println("\nRemote data changed, rebuilding site")
+				const layout = "2006-01-02 15:04:05.000 -0700"
+				c.Logger.FEEDBACK.Println(time.Now().Format(layout))
+
+				if err := c.rebuildSites(evs); err != nil {
+					c.Logger.ERROR.Println("Failed to rebuild site:", err)
+				}
+
+				if !buildWatch && !c.Cfg.GetBool("disableLiveReload") {
+					livereload.ForceRefresh()
+				}
+				buildMu.Unlock()
 			case evs := <-watcher.Events:
-				c.Logger.INFO.Println("Received System Events:", evs)
-
-				staticEvents := []fsnotify.Event{}
-				dynamicEvents := []fsnotify.Event{}
-
-				// Special handling for symbolic links inside /content.
-				filtered := []fsnotify.Event{}
-				for _, ev := range evs {
-					// Check the most specific first, i.e. files.
-					contentMapped := Hugo.ContentChanges.GetSymbolicLinkMappings(ev.Name)
-					if len(contentMapped) > 0 {
-						for _, mapped := range contentMapped {
-							filtered = append(filtered, fsnotify.Event{Name: mapped, Op: ev.Op})
-						}
-						continue
-					}
+				buildMu.Lock()
+				c.handleWatchEvents(watcher, staticSyncer, evs)
+				buildMu.Unlock()
+			case err := <-watcher.Errors:
+				if err != nil {
+					c.Logger.ERROR.Println(err)
+				}
+			}
+		}
+	}()
 
-					// Check for any symbolic directory mapping.
+	if serve {
+		go c.serve()
+	}
 
-					dir, name := filepath.Split(ev.Name)
+	wg.Wait()
+	return nil
+}
 
-					contentMapped = Hugo.ContentChanges.GetSymbolicLinkMappings(dir)
+// handleWatchEvents syncs the static files and rebuilds the sites for the
+// file system events. It must be called with buildMu held.
+func (c *commandeer) handleWatchEvents(watcher *watcher.Batcher, staticSyncer *staticSyncer, evs []fsnotify.Event) {
+	c.Logger.INFO.Println("Received System Events:", evs)
+
+	staticEvents := []fsnotify.Event{}
+	dynamicEvents := []fsnotify.Event{}
+
+	// Special handling for symbolic links inside /content.
+	filtered := []fsnotify.Event{}
+	for _, ev := range evs {
+		// Check the most specific first, i.e. files.
+		contentMapped := Hugo.ContentChanges.GetSymbolicLinkMappings(ev.Name)
+		if len(contentMapped) > 0 {
+			for _, mapped := range contentMapped {
+				filtered = append(filtered, fsnotify.Event{Name: mapped, Op: ev.Op})
+			}
+			continue
+		}
 
-					if len(contentMapped) == 0 {
-						filtered = append(filtered, ev)
-						continue
-					}
+		// Check for any symbolic directory mapping.
 
-					for _, mapped := range contentMapped {
-						mappedFilename := filepath.Join(mapped, name)
-						filtered = append(filtered, fsnotify.Event{Name: mappedFilename, Op: ev.Op})
-					}
-				}
+		dir, name := filepath.Split(ev.Name)
 
-				evs = filtered
-
-				for _, ev := range evs {
-					ext := filepath.Ext(ev.Name)
-					baseName := filepath.Base(ev.Name)
-					istemp := strings.HasSuffix(ext, "~") ||
-						(ext == ".swp") || // vim
-						(ext == ".swx") || // vim
-						(ext == ".tmp") || // generic temp file
-						(ext == ".DS_Store") || // OSX Thumbnail
-						baseName == "4913" || // vim
-						strings.HasPrefix(ext, ".goutputstream") || // gnome
-						strings.HasSuffix(ext, "jb_old___") || // intelliJ
-						strings.HasSuffix(ext, "jb_tmp___") || // intelliJ
-						strings.HasSuffix(ext, "jb_bak___") || // intelliJ
-						strings.HasPrefix(ext, ".sb-") || // byword
-						strings.HasPrefix(baseName, ".#") || // emacs
-						strings.HasPrefix(baseName, "#") // emacs
-					if istemp {
-						continue
-					}
-					// Sometimes during rm -rf operations a '"": REMOVE' is triggered. Just ignore these
-					if ev.Name == "" {
-						continue
-					}
+		contentMapped = Hugo.ContentChanges.GetSymbolicLinkMappings(dir)
 
-					// Write and rename operations are often followed by CHMOD.
-					// There may be valid use cases for rebuilding the site on CHMOD,
-					// but that will require more complex logic than this simple conditional.
-					// On OS X this seems to be related to Spotlight, see:
-					// https://github.com/go-fsnotify/fsnotify/issues/15
-					// A workaround is to put your site(s) on the Spotlight exception list,
-					// but that may be a little mysterious for most end users.
-					// So, for now, we skip reload on CHMOD.
-					// We do have to check for WRITE though. On slower laptops a Chmod
-					// could be aggregated with other important events, and we still want
-					// to rebuild on those
-					if ev.Op&(fsnotify.Chmod|fsnotify.Write|fsnotify.Create) == fsnotify.Chmod {
-						continue
-					}
+		if len(contentMapped) == 0 {
+			filtered = append(filtered, ev)
+			continue
+		}
 
-					walkAdder := func(path string, f os.FileInfo, err error) error {
-						if f.IsDir() {
-							c.Logger.FEEDBACK.Println("adding created directory to watchlist", path)
-							if err := watcher.Add(path); err != nil {
-								return err
-							}
-						} else if !staticSyncer.isStatic(path) {
-							// Hugo's rebuilding logic is entirely file based. When you drop a new folder into
-							// /content on OSX, the above logic will handle future watching of those files,
-							// but the initial CREATE is lost.
-							dynamicEvents = append(dynamicEvents, fsnotify.Event{Name: path, Op: fsnotify.Create})
-						}
-						return nil
-					}
+		for _, mapped := range contentMapped {
+			mappedFilename := filepath.Join(mapped, name)
+			filtered = append(filtered, fsnotify.Event{Name: mappedFilename, Op: ev.Op})
+		}
+	}
 
-					// recursively add new directories to watch list
-					// When mkdir -p is used, only the top directory triggers an event (at least on OSX)
-					if ev.Op&fsnotify.Create == fsnotify.Create {
-						if s, err := c.Fs.Source.Stat(ev.Name); err == nil && s.Mode().IsDir() {
-							_ = helpers.SymbolicWalk(c.Fs.Source, ev.Name, walkAdder)
-						}
-					}
+	evs = filtered
+
+	for _, ev := range evs {
+		ext := filepath.Ext(ev.Name)
+		baseName := filepath.Base(ev.Name)
+		istemp := strings.HasSuffix(ext, "~") ||
+			(ext == ".swp") || // vim
+			(ext == ".swx") || // vim
+			(ext == ".tmp") || // generic temp file
+			(ext == ".DS_Store") || // OSX Thumbnail
+			baseName == "4913" || // vim
+			strings.HasPrefix(ext, ".goutputstream") || // gnome
+			strings.HasSuffix(ext, "jb_old___") || // intelliJ
+			strings.HasSuffix(ext, "jb_tmp___") || // intelliJ
+			strings.HasSuffix(ext, "jb_bak___") || // intelliJ
+			strings.HasPrefix(ext, ".sb-") || // byword
+			strings.HasPrefix(baseName, ".#") || // emacs
+			strings.HasPrefix(baseName, "#") // emacs
+		if istemp {
+			continue
+		}
+		// Sometimes during rm -rf operations a '"": REMOVE' is triggered. Just ignore these
+		if ev.Name == "" {
+			continue
+		}
 
-					if staticSyncer.isStatic(ev.Name) {
-						staticEvents = append(staticEvents, ev)
-					} else {
-						dynamicEvents = append(dynamicEvents, ev)
-					}
+		// Write and rename operations are often followed by CHMOD.
+		// There may be valid use cases for rebuilding the site on CHMOD,
+		// but that will require more complex logic than this simple conditional.
+		// On OS X this seems to be related to Spotlight, see:
+		// https://github.com/go-fsnotify/fsnotify/issues/15
+		// A workaround is to put your site(s) on the Spotlight exception list,
+		// but that may be a little mysterious for most end users.
+		// So, for now, we skip reload on CHMOD.
+		// We do have to check for WRITE though. On slower laptops a Chmod
+		// could be aggregated with other important events, and we still want
+		// to rebuild on those
+		if ev.Op&(fsnotify.Chmod|fsnotify.Write|fsnotify.Create) == fsnotify.Chmod {
+			continue
+		}
+
+		walkAdder := func(path string, f os.FileInfo, err error) error {
+			if f.IsDir() {
+				c.Logger.FEEDBACK.Println("adding created directory to watchlist", path)
+				if err := watcher.Add(path); err != nil {
+					return err
 				}
+			} else if !staticSyncer.isStatic(path) {
+				// Hugo's rebuilding logic is entirely file based. When you drop a new folder into
+				// /content on OSX, the above logic will handle future watching of those files,
+				// but the initial CREATE is lost.
+				dynamicEvents = append(dynamicEvents, fsnotify.Event{Name: path, Op: fsnotify.Create})
+			}
+			return nil
+		}
 
-				if len(staticEvents) > 0 {
-					c.Logger.FEEDBACK.Println("\nStatic file changes detected")
-					const layout = "2006-01-02 15:04:05.000 -0700"
-					c.Logger.FEEDBACK.Println(time.Now().Format(layout))
+		// recursively add new directories to watch list
+		// When mkdir -p is used, only the top directory triggers an event (at least on OSX)
+		if ev.Op&fsnotify.Create == fsnotify.Create {
+			if s, err := c.Fs.Source.Stat(ev.Name); err == nil && s.Mode().IsDir() {
+				_ = helpers.SymbolicWalk(c.Fs.Source, ev.Name, walkAdder)
+			}
+		}
 
-					if c.Cfg.GetBool("forceSyncStatic") {
-						c.Logger.FEEDBACK.Printf("Syncing all static files\n")
-						_, err := c.copyStatic()
-						if err != nil {
-							utils.StopOnErr(c.Logger, err, "Error copying static files to publish dir")
-						}
-					} else {
-						if err := staticSyncer.syncsStaticEvents(staticEvents); err != nil {
-							c.Logger.ERROR.Println(err)
-							continue
-						}
-					}
+		if staticSyncer.isStatic(ev.Name) {
+			staticEvents = append(staticEvents, ev)
+		} else {
+			dynamicEvents = append(dynamicEvents, ev)
+		}
+	}
 
-					if !buildWatch && !c.Cfg.GetBool("disableLiveReload") {
-						// Will block forever trying to write to a channel that nobody is reading if livereload isn't initialized
+	if len(staticEvents) > 0 {
+		c.Logger.FEEDBACK.Println("\nStatic file changes detected")
+		const layout = "2006-01-02 15:04:05.000 -0700"
+		c.Logger.FEEDBACK.Println(time.Now().Format(layout))
 
-						// force refresh when more than one file
-						if len(staticEvents) > 0 {
-							for _, ev := range staticEvents {
-								path := staticSyncer.d.MakeStaticPathRelative(ev.Name)
-								livereload.RefreshPath(path)
-							}
+		if c.Cfg.GetBool("forceSyncStatic") {
+			c.Logger.FEEDBACK.Printf("Syncing all static files\n")
+			_, err := c.copyStatic()
+			if err != nil {
+				utils.StopOnErr(c.Logger, err, "Error copying static files to publish dir")
+			}
+		} else {
+			if err := staticSyncer.syncsStaticEvents(staticEvents); err != nil {
+				c.Logger.ERROR.Println(err)
+				return
+			}
+		}
 
-						} else {
-							livereload.ForceRefresh()
-						}
-					}
-				}
+		if !buildWatch && !c.Cfg.GetBool("disableLiveReload") {
+			// Will block forever trying to write to a channel that nobody is reading if livereload isn't initialized
 
-				if len(dynamicEvents) > 0 {
-					doLiveReload := !buildWatch && !c.Cfg.GetBool("disableLiveReload")
-					onePageName := pickOneWriteOrCreatePath(dynamicEvents)
+			// force refresh when more than one file
+			if len(staticEvents) > 0 {
+				for _, ev := range staticEvents {
+					path := staticSyncer.d.MakeStaticPathRelative(ev.Name)
+					livereload.RefreshPath(path)
+				}
 
-					if onePageName != "" && doLiveReload && !c.Cfg.GetBool("disableFastRender") {
-						p := Hugo.GetContentPage(onePageName)
-						if p != nil {
-							c.visitedURLs.Add(p.RelPermalink())
-						}
+			} else {
+				livereload.ForceRefresh()
+			}
+		}
+	}
 
-					}
+	if len(dynamicEvents) > 0 {
+		doLiveReload := !buildWatch && !c.Cfg.GetBool("disableLiveReload")
+		onePageName := pickOneWriteOrCreatePath(dynamicEvents)
 
-					c.Logger.FEEDBACK.Println("\nChange detected, rebuilding site")
-					const layout = "2006-01-02 15:04:05.000 -0700"
-					c.Logger.FEEDBACK.Println(time.Now().Format(layout))
+		if onePageName != "" && doLiveReload && !c.Cfg.GetBool("disableFastRender") {
+			p := Hugo.GetContentPage(onePageName)
+			if p != nil {
+				c.visitedURLs.Add(p.RelPermalink())
+			}
 
-					if err := c.rebuildSites(dynamicEvents); err != nil {
-						c.Logger.ERROR.Println("Failed to rebuild site:", err)
-					}
+		}
 
-					if doLiveReload {
-						navigate := c.Cfg.GetBool("navigateToChanged")
-						// We have fetched the same page above, but it may have
-						// changed.
-						var p *hugolib.Page
+		c.Logger.FEEDBACK.Println("\nChange detected, rebuilding site")
+		const layout = "2006-01-02 15:04:05.000 -0700"
+		c.Logger.FEEDBACK.Println(time.Now().Format(layout))
 
-						if navigate {
-							if onePageName != "" {
-								p = Hugo.GetContentPage(onePageName)
-							}
+		if err := c.rebuildSites(dynamicEvents); err != nil {
+			c.Logger.ERROR.Println("Failed to rebuild site:", err)
+		}
 
-						}
+		if doLiveReload {
+			navigate := c.Cfg.GetBool("navigateToChanged")
+			// We have fetched the same page above, but it may have
+			// changed.
+			var p *hugolib.Page
 
-						if p != nil {
-							livereload.NavigateToPathForPort(p.RelPermalink(), p.Site.ServerPort())
-						} else {
-							livereload.ForceRefresh()
-						}
-					}
-				}
-			case err := <-watcher.Errors:
-				if err != nil {
-					c.Logger.ERROR.Println(err)
+			if navigate {
+				if onePageName != "" {
+					p = Hugo.GetContentPage(onePageName)
 				}
+
 			}
-		}
-	}()
 
-	if serve {
-		go c.serve()
+			if p != nil {
+				livereload.NavigateToPathForPort(p.RelPermalink(), p.Site.ServerPort())
+			} else {
+				livereload.ForceRefresh()
+			}
+		}
 	}
-
-	wg.Wait()
-	return nil
 }
 
 func pickOneWriteOrCreatePath(events []fsnotify.Event) string {
@@ -14,7 +14,11 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gohugoio/hugo/hugolib"
 	"github.com/spf13/cobra"
@@ -25,10 +29,18 @@ func init() {
 	listCmd.AddCommand(listDraftsCmd)
 	listCmd.AddCommand(listFutureCmd)
 	listCmd.AddCommand(listExpiredCmd)
+	listCmd.AddCommand(listLayoutsCmd)
+	listLayoutsCmd.Flags().StringVar(&listLayoutsFor, "for", "", "the content file path, e.g. posts/my-post.md, or the relative permalink of the page")
+	listFutureCmd.Flags().StringVar(&listFormat, "format", "text", "output format, text or json; json includes the upcoming publish and expiry dates")
 	listCmd.PersistentFlags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
 	listCmd.PersistentFlags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
 }
 
+var (
+	listFormat     string
+	listLayoutsFor string
+)
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Listing out various types of content",
@@ -80,6 +92,10 @@ var listFutureCmd = &cobra.Command{
 	Long: `List all of the posts in your content directory which will be
 posted in the future.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if listFormat != "text" && listFormat != "json" {
+			return newUserError(fmt.Sprintf("unsupported format %q, must be text or json", listFormat))
+		}
+
 		cfgInit := func(c *commandeer) error {
 			c.Set("buildFuture", true)
 			return nil
@@ -99,6 +115,10 @@ posted in the future.`,
 			return newSystemError("Error Processing Source Content", err)
 		}
 
+		if listFormat == "json" {
+			return printScheduledChangesJSON(sites)
+		}
+
 		for _, p := range sites.Pages() {
 			if p.IsFuture() {
 				jww.FEEDBACK.Println(filepath.Join(p.File.Dir(), p.File.LogicalName()))
@@ -147,3 +167,91 @@ expired.`,
 
 	},
 }
+
+var listLayoutsCmd = &cobra.Command{
+	Use:   "layouts",
+	Short: "List the layouts looked up for a page",
+	Long: `List the layouts looked up for a page in every language and output
+format, in lookup order, and which one is used, e.g.
+
+	hugo list layouts --for posts/my-post.md`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if listLayoutsFor == "" {
+			return newUserError("the --for flag with the content file path or relative permalink of a page is required")
+		}
+
+		c, err := InitializeConfig(false, nil)
+		if err != nil {
+			return err
+		}
+
+		sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+
+		if err != nil {
+			return newSystemError("Error creating sites", err)
+		}
+
+		if err := sites.Build(hugolib.BuildCfg{SkipRender: true}); err != nil {
+			return newSystemError("Error Processing Source Content", err)
+		}
+
+		lookups := sites.LayoutLookups(listLayoutsFor)
+		if len(lookups) == 0 {
+			return newUserError(fmt.Sprintf("no page found for %q", listLayoutsFor))
+		}
+
+		printLayoutLookups(lookups, c.Cfg.GetString("workingDir"))
+
+		return nil
+	},
+}
+
+func printLayoutLookups(lookups []hugolib.LayoutLookup, workingDir string) {
+	for i, l := range lookups {
+		if i > 0 {
+			jww.FEEDBACK.Println()
+		}
+
+		jww.FEEDBACK.Printf("%s (kind %s, language %s, output format %s):\n", listLayoutsFor, l.Page.Kind, l.Page.Lang(), l.OutputFormat.Name)
+
+		for j, layout := range l.Layouts {
+			if j != l.Found {
+				jww.FEEDBACK.Printf("     %s\n", layout)
+				continue
+			}
+			filename := l.Filename
+			if rel, err := filepath.Rel(workingDir, filename); err == nil && filename != "" {
+				filename = filepath.ToSlash(rel)
+			}
+			if filename == "" {
+				filename = "built-in"
+			}
+			jww.FEEDBACK.Printf("  => %s (%s)\n", layout, filename)
+		}
+
+		if l.Found == -1 {
+			jww.FEEDBACK.Println("  no layout found")
+		}
+	}
+}
+
+// printScheduledChangesJSON prints the upcoming publish and expiry dates as
+// JSON, with the first in next, so a scheduler can time the next build.
+func printScheduledChangesJSON(sites *hugolib.HugoSites) error {
+	changes := sites.ScheduledChanges(time.Now())
+
+	out := struct {
+		Next    *hugolib.ScheduledChange  `json:"next"`
+		Changes []hugolib.ScheduledChange `json:"changes"`
+	}{Changes: changes}
+
+	if len(changes) > 0 {
+		out.Next = &changes[0]
+	} else {
+		out.Changes = []hugolib.ScheduledChange{}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -14,22 +14,79 @@
 package commands
 
 import (
+	"fmt"
+	"io"
+	"os"
 	"reflect"
 	"sort"
 
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugolib"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
 	"github.com/spf13/viper"
 )
 
+// The config keys set by Hugo for its own use, not shown to the user.
+var hiddenConfigKeys = map[string]bool{
+	"configlayers": true,
+}
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Print the site configuration",
 	Long:  `Print the site configuration, both default and custom settings.`,
 }
 
+var configMergedCmd = &cobra.Command{
+	Use:   "merged",
+	Short: "Print the merged params, menu and markup config and where each value is set",
+	Long: `Print the params, menu and markup config for every language, merged
+between the theme, the project and the language config, and where each value
+is set.
+
+How the maps are merged is set with _merge in the map with the higher
+priority, one of deep, shallow or none, e.g.:
+
+    [languages.de.params]
+    _merge = "deep"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := InitializeConfig(false, nil, configCmd)
+		if err != nil {
+			return err
+		}
+
+		languages, ok := c.Cfg.Get("languagesSorted").(helpers.Languages)
+		if !ok {
+			languages = helpers.Languages{helpers.NewDefaultLanguage(c.Cfg)}
+		}
+
+		printMergedConfig(os.Stdout, c.Cfg, languages)
+
+		return nil
+	},
+}
+
 func init() {
 	configCmd.RunE = printConfig
+	configCmd.AddCommand(configMergedCmd)
+}
+
+func printMergedConfig(w io.Writer, cfg config.Provider, languages helpers.Languages) {
+	for i, l := range languages {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s]\n", l.Lang)
+		for _, v := range hugolib.MergedConfig(cfg, l) {
+			if s, ok := v.Value.(string); ok {
+				fmt.Fprintf(w, "%s = %q  # %s\n", v.Key, s, v.Origin)
+			} else {
+				fmt.Fprintf(w, "%s = %v  # %s\n", v.Key, v.Value, v.Origin)
+			}
+		}
+	}
 }
 
 func printConfig(cmd *cobra.Command, args []string) error {
@@ -50,6 +107,9 @@ func printConfig(cmd *cobra.Command, args []string) error {
 
 	var keys []string
 	for k := range allSettings {
+		if hiddenConfigKeys[k] {
+			continue
+		}
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
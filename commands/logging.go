@@ -0,0 +1,190 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/helpers"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// Matches the date and time written by the jww loggers with the
+// log.Ldate and log.Ltime flags, e.g. "2018/01/02 15:04:05 ".
+var logTimeRe = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
+// Matches the event code at the start of a log message, e.g.
+// "[contentInStatic] static/post.md: ...".
+var logCodeRe = regexp.MustCompile(`^\[([\w.-]+)\]\s*`)
+
+var logLevels = map[string]string{
+	jww.LevelTrace.String():    "trace",
+	jww.LevelDebug.String():    "debug",
+	jww.LevelInfo.String():     "info",
+	jww.LevelWarn.String():     "warn",
+	jww.LevelError.String():    "error",
+	jww.LevelCritical.String(): "critical",
+	jww.LevelFatal.String():    "fatal",
+	"LOG:":                     "info",
+}
+
+// logEntry is a log line in the JSON log format.
+type logEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// logWriter sits between the loggers and the log handles. It writes the
+// log lines as JSON if configured to, and panics on warnings if
+// panicOnWarning is set, so CI builds stop at the first one. Errors are
+// also sent to the events stream, if set.
+type logWriter struct {
+	w              io.Writer
+	format         string
+	panicOnWarning bool
+	events         *eventStream
+}
+
+func newLogWriter(w io.Writer, format string, panicOnWarning bool) (*logWriter, error) {
+	switch format {
+	case "", logFormatText:
+		format = logFormatText
+	case logFormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be one of text or json", format)
+	}
+	return &logWriter{w: w, format: format, panicOnWarning: panicOnWarning}, nil
+}
+
+// Write is called once per log line.
+func (l *logWriter) Write(p []byte) (int, error) {
+	level, message := parseLogLine(string(p))
+	code, text := splitLogCode(message)
+
+	if l.events != nil && (level == "error" || level == "critical") {
+		l.events.logError(code, text)
+	}
+
+	if l.format == logFormatJSON {
+		entry := logEntry{
+			Time:    time.Now().Format(time.RFC3339),
+			Level:   level,
+			Code:    code,
+			Message: text,
+		}
+
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := l.w.Write(append(b, '\n')); err != nil {
+			return 0, err
+		}
+	} else if _, err := l.w.Write(p); err != nil {
+		return 0, err
+	}
+
+	if l.panicOnWarning && level == "warn" {
+		panic(fmt.Sprintf("warning with panicOnWarning set: %s", message))
+	}
+
+	return len(p), nil
+}
+
+// globalLogWriter is the log handle of the global jww loggers. Their
+// stdout handle cannot be replaced, so all of their lines are written here
+// and passed on to the log and stdout writers by level, as with the
+// Notepad created in createLogger.
+type globalLogWriter struct {
+	log             io.Writer
+	out             io.Writer
+	logThreshold    jww.Threshold
+	stdoutThreshold jww.Threshold
+}
+
+func (w *globalLogWriter) Write(p []byte) (int, error) {
+	threshold, found := lineThreshold(string(p))
+
+	// The lines of the LOG logger have no level and only go to the log.
+	if !found || threshold >= w.logThreshold {
+		if _, err := w.log.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	if found && threshold >= w.stdoutThreshold {
+		if _, err := w.out.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// lineThreshold returns the level of a line written by the jww loggers.
+func lineThreshold(line string) (jww.Threshold, bool) {
+	for t := jww.LevelTrace; t <= jww.LevelFatal; t++ {
+		if strings.HasPrefix(line, t.String()+" ") {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// splitLogCode splits the event code, if any, from a log message.
+func splitLogCode(message string) (code, text string) {
+	if m := logCodeRe.FindStringSubmatch(message); m != nil {
+		return m[1], message[len(m[0]):]
+	}
+	return "", message
+}
+
+// parseLogLine splits a line as written by the jww loggers into its level
+// and message, without the date and time. Lines without a level, i.e.
+// feedback, are at the info level.
+func parseLogLine(line string) (level, message string) {
+	line = strings.TrimRight(line, "\r\n")
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if level, found := logLevels[line[:i]]; found {
+			message = strings.TrimLeft(line[i:], " ")
+			return level, logTimeRe.ReplaceAllString(message, "")
+		}
+	}
+	return "info", line
+}
+
+// resetGlobalLoggers restores the global jww loggers, including
+// jww.FEEDBACK, and the distinct loggers in helpers changed by createLogger
+// to their defaults.
+func resetGlobalLoggers() {
+	jww.SetLogOutput(ioutil.Discard)
+	jww.SetLogThreshold(jww.LevelWarn)
+	jww.SetStdoutThreshold(jww.LevelError)
+	jww.SetFlags(log.Ldate | log.Ltime)
+	jww.ResetLogCounters()
+	helpers.InitLoggers()
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/helpers"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogWriterJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var out, log bytes.Buffer
+	outW, err := newLogWriter(&out, logFormatJSON, false)
+	assert.NoError(err)
+	logW, err := newLogWriter(&log, logFormatJSON, false)
+	assert.NoError(err)
+
+	logger := jww.NewNotepad(jww.LevelError, jww.LevelWarn, outW, logW, "", 0)
+	logger.WARN.Printf("[contentInStatic] %s: %s", "static/post.md", "move it")
+	logger.ERROR.Println("failed")
+	logger.FEEDBACK.Println("Started")
+
+	var entries []logEntry
+	for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+		var entry logEntry
+		assert.NoError(json.Unmarshal([]byte(line), &entry))
+		assert.NotEmpty(entry.Time)
+		entry.Time = ""
+		entries = append(entries, entry)
+	}
+
+	assert.Equal([]logEntry{
+		{Level: "warn", Code: "contentInStatic", Message: "static/post.md: move it"},
+		{Level: "error", Message: "failed"},
+		{Level: "info", Message: "Started"},
+	}, entries)
+
+	// Feedback and errors only on stdout.
+	assert.Equal(2, strings.Count(out.String(), "\n"))
+	assert.Contains(out.String(), `"level":"error"`)
+	assert.NotContains(out.String(), `"level":"warn"`)
+}
+
+func TestLogWriterPanicOnWarning(t *testing.T) {
+	assert := require.New(t)
+
+	var log bytes.Buffer
+	logW, err := newLogWriter(&log, logFormatText, true)
+	assert.NoError(err)
+
+	logger := jww.NewNotepad(jww.LevelError, jww.LevelWarn, &bytes.Buffer{}, logW, "", 0)
+	logger.ERROR.Println("an error")
+	assert.Equal("ERROR an error\n", log.String())
+
+	assert.Panics(func() { logger.WARN.Println("a warning") })
+	assert.Contains(log.String(), "WARN a warning")
+}
+
+func TestParseLogLine(t *testing.T) {
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		line, level, message string
+	}{
+		{"ERROR 2018/01/02 15:04:05 failed\n", "error", "failed"},
+		{"WARN 2018/01/02 15:04:05.123456 [code] warned\n", "warn", "[code] warned"},
+		{"ERROR failed\n", "error", "failed"},
+		{"Started\n", "info", "Started"},
+	} {
+		level, message := parseLogLine(test.line)
+		assert.Equal(test.level, level, test.line)
+		assert.Equal(test.message, message, test.line)
+	}
+}
+
+func TestNewLogWriterInvalidFormat(t *testing.T) {
+	_, err := newLogWriter(&bytes.Buffer{}, "xml", false)
+	require.Error(t, err)
+}
+
+func TestCreateLoggerJSONGlobalLoggers(t *testing.T) {
+	assert := require.New(t)
+	defer resetGlobalLoggers()
+
+	dir, err := ioutil.TempDir("", "hugo-log")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	logFile := filepath.Join(dir, "hugo.log")
+
+	cfg := viper.New()
+	cfg.Set("logFile", logFile)
+	cfg.Set("logFormat", logFormatJSON)
+
+	var logger *jww.Notepad
+	stdout := captureStdout(t, func() {
+		logger, err = createLogger(cfg)
+		assert.NoError(err)
+
+		helpers.DistinctErrorLog.Printf("[missingLayout] %s", "no layout for post/a.md")
+		helpers.DistinctErrorLog.Printf("[missingLayout] %s", "no layout for post/a.md")
+		jww.WARN.Println("a warning")
+		jww.FEEDBACK.Println("Started")
+	})
+
+	b, err := ioutil.ReadFile(logFile)
+	assert.NoError(err)
+
+	assert.Equal([]logEntry{
+		{Level: "error", Code: "missingLayout", Message: "no layout for post/a.md"},
+		{Level: "warn", Message: "a warning"},
+		{Level: "info", Message: "Started"},
+	}, decodeLogEntries(t, string(b)))
+
+	assert.Equal([]logEntry{
+		{Level: "error", Code: "missingLayout", Message: "no layout for post/a.md"},
+		{Level: "info", Message: "Started"},
+	}, decodeLogEntries(t, stdout))
+
+	assert.Equal(uint64(1), jww.LogCountForLevel(jww.LevelError))
+
+	assert.True(jww.FEEDBACK == logger.FEEDBACK)
+	resetGlobalLoggers()
+	assert.False(jww.FEEDBACK == logger.FEEDBACK)
+}
+
+func decodeLogEntries(t *testing.T, s string) []logEntry {
+	var entries []logEntry
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		var entry logEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		require.NotEmpty(t, entry.Time)
+		entry.Time = ""
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// captureStdout returns what is written to os.Stdout while running f.
+func captureStdout(t *testing.T, f func()) string {
+	tmp, err := ioutil.TempFile("", "hugo-stdout")
+	require.NoError(t, err)
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	stdout := os.Stdout
+	os.Stdout = tmp
+	defer func() { os.Stdout = stdout }()
+
+	f()
+
+	b, err := ioutil.ReadFile(tmp.Name())
+	require.NoError(t, err)
+	return string(b)
+}
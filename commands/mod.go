@@ -0,0 +1,81 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/npm"
+	"github.com/spf13/cobra"
+)
+
+var modCmd = &cobra.Command{
+	Use:   "mod",
+	Short: "Various helpers to work with the project's themes",
+	Long: `Various helpers to work with the project's themes.
+
+Mod requires a subcommand, e.g. ` + "`hugo mod npm pack`.",
+	RunE: nil,
+}
+
+var modNpmCmd = &cobra.Command{
+	Use:   "npm",
+	Short: "Various npm helpers",
+	RunE:  nil,
+}
+
+var modNpmPackCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Create a package.json from the project and its theme",
+	Long: `Create a package.json by merging the dependencies and devDependencies
+declared in the project and in the theme's package.json.
+
+If the project has a package.hugo.json, it will be used as the base,
+else the existing package.json is used. The project's dependency versions
+always win. Conflicting theme versions are reported as warnings.
+
+The "comments" section in the generated file lists where each dependency
+comes from.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := InitializeConfig(false, nil)
+		if err != nil {
+			return err
+		}
+
+		ps := c.PathSpec()
+
+		var sources []npm.Source
+		if ps.ThemeSet() {
+			sources = append(sources, npm.Source{Name: ps.Theme(), Dir: ps.GetThemeDir()})
+		}
+
+		if err := npm.Pack(c.Fs.Source, c.Logger, ps.WorkingDir(), sources...); err != nil {
+			return newSystemError("Error creating package.json:", err)
+		}
+
+		c.Logger.FEEDBACK.Println(filepath.Join(ps.WorkingDir(), npm.PackageJSONName), "created")
+
+		return nil
+	},
+}
+
+func init() {
+	modCmd.PersistentFlags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	modCmd.PersistentFlags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
+	modCmd.PersistentFlags().StringVarP(&theme, "theme", "t", "", "theme to use (located in /themes/THEMENAME/)")
+	modCmd.PersistentFlags().StringVarP(&themesDir, "themesDir", "", "", "filesystem path to themes directory")
+
+	modCmd.AddCommand(modNpmCmd)
+	modNpmCmd.AddCommand(modNpmPackCmd)
+}
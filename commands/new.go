@@ -36,6 +36,7 @@ import (
 var (
 	configFormat  string
 	contentEditor string
+	contentHook   string
 	contentType   string
 )
 
@@ -45,7 +46,9 @@ func init() {
 	newCmd.Flags().StringVarP(&contentType, "kind", "k", "", "content type to create")
 	newCmd.PersistentFlags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
 	newCmd.PersistentFlags().SetAnnotation("source", cobra.BashCompSubdirsInDir, []string{})
-	newCmd.Flags().StringVar(&contentEditor, "editor", "", "edit new content with this editor, if provided")
+	newCmd.Flags().StringVar(&contentEditor, "editor", "", "edit new content with this editor, if provided, e.g. '$EDITOR'")
+	newCmd.Flags().StringVar(&contentHook, "hook", "", "command to run with the new content file as argument, e.g. 'git add'")
+	newCmd.Flags().Bool("porcelain", false, "print only the path of the created file")
 
 	newCmd.AddCommand(newSiteCmd)
 	newCmd.AddCommand(newThemeCmd)
@@ -60,7 +63,10 @@ It will guess which kind of file to create based on the path provided.
 
 You can also specify the kind with ` + "`-k KIND`" + `.
 
-If archetypes are provided in your theme or site, they will be used.`,
+If archetypes are provided in your theme or site, they will be used.
+The site's archetypes take precedence over the theme's, and an archetype
+for the kind is preferred over one for the section. Run with ` + "`-v`" + ` to
+see which archetype was chosen.`,
 
 	RunE: NewContent,
 }
@@ -90,6 +96,12 @@ func NewContent(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("editor") {
 			c.Set("newContentEditor", contentEditor)
 		}
+		if cmd.Flags().Changed("hook") {
+			c.Set("newContentHook", contentHook)
+		}
+		if porcelain, _ := cmd.Flags().GetBool("porcelain"); porcelain {
+			c.Set("newContentPorcelain", true)
+		}
 		return nil
 	}
 
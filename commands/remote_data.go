@@ -0,0 +1,89 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchRemoteData refreshes the remote data sources in the site config, each
+// on its configured interval and all of them on SIGHUP. The events for the
+// sources that changed are sent on the returned channel, to be passed on to
+// rebuildSites. The returned func stops the refreshing.
+func (c *commandeer) watchRemoteData() (<-chan []fsnotify.Event, func()) {
+	events := make(chan []fsnotify.Event)
+	done := make(chan struct{})
+
+	if Hugo == nil || len(Hugo.RemoteDataSources()) == 0 {
+		return events, func() {}
+	}
+
+	refresh := func(keys ...string) {
+		// Hugo and c are replaced when the config is reloaded.
+		buildMu.Lock()
+		evs, err := Hugo.RefreshRemoteData(keys...)
+		if err != nil {
+			c.Logger.ERROR.Println(err)
+		}
+		buildMu.Unlock()
+		if len(evs) > 0 {
+			select {
+			case events <- evs:
+			case <-done:
+			}
+		}
+	}
+
+	for _, source := range Hugo.RemoteDataSources() {
+		if source.Refresh <= 0 {
+			continue
+		}
+		go func(key string, interval time.Duration) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					refresh(key)
+				case <-done:
+					return
+				}
+			}
+		}(source.Key, source.Refresh)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				c.Logger.FEEDBACK.Println("Refreshing remote data")
+				refresh()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return events, func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -15,10 +15,12 @@ package commands
 
 import (
 	"fmt"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -30,6 +32,7 @@ import (
 	"github.com/gohugoio/hugo/config"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugolib"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
@@ -46,7 +49,12 @@ var (
 	serverWatch       bool
 	noHTTPCache       bool
 
+	serverLatency      time.Duration
+	serverCacheControl string
+	servePrecompressed bool
+
 	disableFastRender bool
+	renderOnly        []string
 )
 
 var serverCmd = &cobra.Command{
@@ -89,24 +97,30 @@ func (f noDirFile) Readdir(count int) ([]os.FileInfo, error) {
 }
 
 func init() {
-	initHugoBuilderFlags(serverCmd)
-
-	serverCmd.Flags().IntVarP(&serverPort, "port", "p", 1313, "port on which the server will listen")
-	serverCmd.Flags().IntVar(&liveReloadPort, "liveReloadPort", -1, "port for live reloading (i.e. 443 in HTTPS proxy situations)")
-	serverCmd.Flags().StringVarP(&serverInterface, "bind", "", "127.0.0.1", "interface to which the server will bind")
-	serverCmd.Flags().BoolVarP(&serverWatch, "watch", "w", true, "watch filesystem for changes and recreate as needed")
-	serverCmd.Flags().BoolVar(&noHTTPCache, "noHTTPCache", false, "prevent HTTP caching")
-	serverCmd.Flags().BoolVarP(&serverAppend, "appendPort", "", true, "append port to baseURL")
-	serverCmd.Flags().BoolVar(&disableLiveReload, "disableLiveReload", false, "watch without enabling live browser reload on rebuild")
-	serverCmd.Flags().BoolVar(&navigateToChanged, "navigateToChanged", false, "navigate to changed content file on live browser reload")
-	serverCmd.Flags().BoolVar(&renderToDisk, "renderToDisk", false, "render to Destination path (default is render to memory & serve from there)")
-	serverCmd.Flags().BoolVar(&disableFastRender, "disableFastRender", false, "enables full re-renders on changes")
-
-	serverCmd.Flags().String("memstats", "", "log memory usage to this file")
-	serverCmd.Flags().String("meminterval", "100ms", "interval to poll memory usage (requires --memstats), valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
-
+	initServerFlags(serverCmd)
 	serverCmd.RunE = server
+}
 
+func initServerFlags(cmd *cobra.Command) {
+	initHugoBuilderFlags(cmd)
+
+	cmd.Flags().IntVarP(&serverPort, "port", "p", 1313, "port on which the server will listen, 0 to let the OS pick one")
+	cmd.Flags().IntVar(&liveReloadPort, "liveReloadPort", -1, "port for live reloading (i.e. 443 in HTTPS proxy situations)")
+	cmd.Flags().StringVarP(&serverInterface, "bind", "", "127.0.0.1", "interface(s) to which the server will bind, comma separated, e.g. 127.0.0.1,::1")
+	cmd.Flags().BoolVarP(&serverWatch, "watch", "w", true, "watch filesystem for changes and recreate as needed")
+	cmd.Flags().BoolVar(&noHTTPCache, "noHTTPCache", false, "prevent HTTP caching")
+	cmd.Flags().DurationVar(&serverLatency, "latency", 0, "delay the response to every request for the site's files by this duration, e.g. 200ms, to simulate a slow network")
+	cmd.Flags().StringVar(&serverCacheControl, "cacheControl", "", "Cache-Control header to send with the site's files, e.g. \"public, max-age=31536000\"")
+	cmd.Flags().BoolVar(&servePrecompressed, "precompressed", false, "serve the gzip and brotli compressed siblings of the files, see the precompress config, to clients accepting them")
+	cmd.Flags().BoolVarP(&serverAppend, "appendPort", "", true, "append port to baseURL")
+	cmd.Flags().BoolVar(&disableLiveReload, "disableLiveReload", false, "watch without enabling live browser reload on rebuild")
+	cmd.Flags().BoolVar(&navigateToChanged, "navigateToChanged", false, "navigate to changed content file on live browser reload")
+	cmd.Flags().BoolVar(&renderToDisk, "renderToDisk", false, "render to Destination path (default is render to memory & serve from there)")
+	cmd.Flags().BoolVar(&disableFastRender, "disableFastRender", false, "enables full re-renders on changes")
+	cmd.Flags().StringSliceVar(&renderOnly, "renderOnly", nil, "only build the given content files/directories (relative to the content dir) and the sections, taxonomies and feeds listing them")
+
+	cmd.Flags().String("memstats", "", "log memory usage to this file")
+	cmd.Flags().String("meminterval", "100ms", "interval to poll memory usage (requires --memstats), valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
 }
 
 func server(cmd *cobra.Command, args []string) error {
@@ -115,8 +129,21 @@ func server(cmd *cobra.Command, args []string) error {
 		renderToDisk = true
 	}
 
+	if noHTTPCache && serverCacheControl != "" {
+		return newUserError("--noHTTPCache and --cacheControl cannot be used together")
+	}
+
+	// The logger is created after cfgInit, which picks the ports, so these
+	// are logged when it is ready. With --events json, stdout is reserved
+	// for the events.
+	var portLogs []func(logger *jww.Notepad)
+
 	cfgInit := func(c *commandeer) error {
 		c.Set("renderToMemory", !renderToDisk)
+		if servePrecompressed && !c.Cfg.IsSet("precompress") {
+			// Use the default precompress config.
+			c.Set("precompress", map[string]interface{}{})
+		}
 		if cmd.Flags().Changed("navigateToChanged") {
 			c.Set("navigateToChanged", navigateToChanged)
 		}
@@ -126,45 +153,70 @@ func server(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("disableFastRender") {
 			c.Set("disableFastRender", disableFastRender)
 		}
+		if cmd.Flags().Changed("renderOnly") {
+			c.Set("renderOnly", renderOnly)
+		}
 		if serverWatch {
 			c.Set("watch", true)
 		}
 
-		serverPorts := make([]int, 1)
+		// The ports are kept when the config is reloaded.
+		if c.serverPorts == nil {
+			serverPorts := make([]int, 1)
 
-		if c.languages.IsMultihost() {
-			if !serverAppend {
-				return newSystemError("--appendPort=false not supported when in multihost mode")
+			if c.languages.IsMultihost() {
+				if !serverAppend {
+					return newSystemError("--appendPort=false not supported when in multihost mode")
+				}
+				serverPorts = make([]int, len(c.languages))
 			}
-			serverPorts = make([]int, len(c.languages))
-		}
-
-		currentServerPort := serverPort
 
-		for i := 0; i < len(serverPorts); i++ {
-			l, err := net.Listen("tcp", net.JoinHostPort(serverInterface, strconv.Itoa(currentServerPort)))
-			if err == nil {
-				l.Close()
-				serverPorts[i] = currentServerPort
-			} else {
-				if i == 0 && serverCmd.Flags().Changed("port") {
-					// port set explicitly by user -- he/she probably meant it!
-					return newSystemErrorF("Server startup failed: %s", err)
+			bindAddresses := parseBindAddresses(serverInterface)
+			currentServerPort := serverPort
+
+			for i := 0; i < len(serverPorts); i++ {
+				if currentServerPort == 0 {
+					// Let the OS pick the port.
+					port, err := findAvailablePort(bindAddresses)
+					if err != nil {
+						return newSystemError("Unable to find a port to use:", err)
+					}
+					serverPorts[i] = port
+					portLogs = append(portLogs, func(logger *jww.Notepad) {
+						logger.FEEDBACK.Println("Using port", port)
+					})
+					continue
 				}
-				jww.ERROR.Println("port", serverPort, "already in use, attempting to use an available port")
-				sp, err := helpers.FindAvailablePort()
-				if err != nil {
-					return newSystemError("Unable to find alternative port to use:", err)
+
+				err := checkPortAvailable(bindAddresses, currentServerPort)
+				if err == nil {
+					serverPorts[i] = currentServerPort
+				} else {
+					if i == 0 && cmd.Flags().Changed("port") {
+						// port set explicitly by user -- he/she probably meant it!
+						return newSystemErrorF("Server startup failed: %s", err)
+					}
+					portLogs = append(portLogs, func(logger *jww.Notepad) {
+						logger.ERROR.Println("port", serverPort, "already in use, attempting to use an available port")
+					})
+					port, err := findAvailablePort(bindAddresses)
+					if err != nil {
+						return newSystemError("Unable to find alternative port to use:", err)
+					}
+					serverPorts[i] = port
 				}
-				serverPorts[i] = sp.Port
+
+				currentServerPort = serverPorts[i] + 1
 			}
 
-			currentServerPort = serverPorts[i] + 1
+			c.serverPorts = serverPorts
+		} else if c.languages.IsMultihost() && len(c.serverPorts) != len(c.languages) {
+			// The config was reloaded.
+			return newUserError("changing the number of languages in multihost mode requires a server restart")
 		}
+		serverPorts := c.serverPorts
 
-		c.serverPorts = serverPorts
-
-		c.Set("port", serverPort)
+		c.Set("port", serverPorts[0])
 		if liveReloadPort != -1 {
 			c.Set("liveReloadPort", liveReloadPort)
 		} else {
@@ -191,15 +243,19 @@ func server(cmd *cobra.Command, args []string) error {
 
 	}
 
-	if err := memStats(); err != nil {
+	if err := memStats(cmd); err != nil {
 		jww.ERROR.Println("memstats error:", err)
 	}
 
-	c, err := InitializeConfig(true, cfgInit, serverCmd)
+	c, err := InitializeConfig(true, cfgInit, cmd)
 	if err != nil {
 		return err
 	}
 
+	for _, log := range portLogs {
+		log(c.Logger)
+	}
+
 	if err := c.build(serverWatch); err != nil {
 		return err
 	}
@@ -228,7 +284,7 @@ func server(cmd *cobra.Command, args []string) error {
 
 		rootWatchDirs := strings.Join(helpers.UniqueStrings(helpers.ExtractRootPaths(relWatchDirs)), ",")
 
-		jww.FEEDBACK.Printf("Watching for changes in %s%s{%s}\n", baseWatchDir, helpers.FilePathSeparator, rootWatchDirs)
+		c.Logger.FEEDBACK.Printf("Watching for changes in %s%s{%s}\n", baseWatchDir, helpers.FilePathSeparator, rootWatchDirs)
 		err = c.newWatcher(true, watchDirs...)
 
 		if err != nil {
@@ -245,7 +301,7 @@ type fileServer struct {
 	c        *commandeer
 }
 
-func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, error) {
+func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, []string, error) {
 	baseURL := f.baseURLs[i]
 	root := f.roots[i]
 	port := f.c.serverPorts[i]
@@ -260,9 +316,9 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 
 	if i == 0 {
 		if renderToDisk {
-			jww.FEEDBACK.Println("Serving pages from " + absPublishDir)
+			f.c.Logger.FEEDBACK.Println("Serving pages from " + absPublishDir)
 		} else {
-			jww.FEEDBACK.Println("Serving pages from memory")
+			f.c.Logger.FEEDBACK.Println("Serving pages from memory")
 		}
 	}
 
@@ -272,34 +328,58 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 	doLiveReload := !buildWatch && !f.c.Cfg.GetBool("disableLiveReload")
 	fastRenderMode := doLiveReload && !f.c.Cfg.GetBool("disableFastRender")
 
+	// The queue is kept when the config is reloaded, which replaces f.c.
+	visitedURLs := f.c.visitedURLs
+
 	if i == 0 && fastRenderMode {
-		jww.FEEDBACK.Println("Running in Fast Render Mode. For full rebuilds on change: hugo server --disableFastRender")
+		f.c.Logger.FEEDBACK.Println("Running in Fast Render Mode. For full rebuilds on change: hugo server --disableFastRender")
 	}
 
 	// We're only interested in the path
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("Invalid baseURL: %s", err)
+		return nil, "", nil, fmt.Errorf("Invalid baseURL: %s", err)
+	}
+
+	headers, err := hugolib.DecodeHeaders(f.c.Cfg, false)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	decorate := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range hugolib.MatchHeaders(headers, r.URL.Path) {
+				w.Header().Set(k, v)
+			}
+
 			if noHTTPCache {
 				w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
 				w.Header().Set("Pragma", "no-cache")
+			} else if serverCacheControl != "" {
+				w.Header().Set("Cache-Control", serverCacheControl)
 			}
 
 			if fastRenderMode {
 				p := r.RequestURI
 				if strings.HasSuffix(p, "/") || strings.HasSuffix(p, "html") || strings.HasSuffix(p, "htm") {
-					f.c.visitedURLs.Add(p)
+					visitedURLs.Add(p)
 				}
 			}
 			h.ServeHTTP(w, r)
 		})
 	}
 
-	fileserver := decorate(http.FileServer(fs))
+	var fileserver http.Handler = http.FileServer(fs)
+	if servePrecompressed {
+		fileserver = precompressedHandler(fs, fileserver)
+	}
+	fileserver = decorate(fileserver)
+	if serverLatency > 0 {
+		if i == 0 {
+			f.c.Logger.FEEDBACK.Printf("Delaying every response by %s\n", serverLatency)
+		}
+		fileserver = latencyHandler(serverLatency, fileserver)
+	}
 	mu := http.NewServeMux()
 
 	if u.Path == "" || u.Path == "/" {
@@ -308,9 +388,12 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 		mu.Handle(u.Path, http.StripPrefix(u.Path, fileserver))
 	}
 
-	endpoint := net.JoinHostPort(serverInterface, strconv.Itoa(port))
+	var endpoints []string
+	for _, addr := range parseBindAddresses(serverInterface) {
+		endpoints = append(endpoints, net.JoinHostPort(addr, strconv.Itoa(port)))
+	}
 
-	return mu, u.String(), endpoint, nil
+	return mu, u.String(), endpoints, nil
 }
 
 func (c *commandeer) serve() {
@@ -346,23 +429,213 @@ func (c *commandeer) serve() {
 	}
 
 	for i, _ := range baseURLs {
-		mu, serverURL, endpoint, err := srv.createEndpoint(i)
+		mu, serverURL, endpoints, err := srv.createEndpoint(i)
+		if err != nil {
+			c.Logger.ERROR.Printf("Error: %s\n", err.Error())
+			os.Exit(1)
+		}
 
 		if doLiveReload {
 			mu.HandleFunc("/livereload.js", livereload.ServeJS)
 			mu.HandleFunc("/livereload", livereload.Handler)
 		}
-		jww.FEEDBACK.Printf("Web Server is available at %s (bind address %s)\n", serverURL, serverInterface)
-		go func() {
-			err = http.ListenAndServe(endpoint, mu)
+
+		var basePath string
+		if u, err := url.Parse(baseURLs[i]); err == nil {
+			basePath = u.Path
+		}
+		handler := forwardedPrefixHandler(basePath, mu)
+
+		c.Logger.FEEDBACK.Printf("Web Server is available at %s (bind address %s)\n", serverURL, serverInterface)
+		for _, endpoint := range endpoints {
+			// Listen before the server-started event is sent, so the
+			// clients can connect when they get it.
+			l, err := net.Listen("tcp", endpoint)
 			if err != nil {
-				jww.ERROR.Printf("Error: %s\n", err.Error())
+				c.Logger.ERROR.Printf("Error: %s\n", err.Error())
 				os.Exit(1)
 			}
-		}()
+			go func(l net.Listener) {
+				err := http.Serve(l, handler)
+				if err != nil {
+					c.Logger.ERROR.Printf("Error: %s\n", err.Error())
+					os.Exit(1)
+				}
+			}(l)
+		}
+		buildEvents.serverStarted(c.serverPorts[i], serverURL)
+	}
+
+	c.Logger.FEEDBACK.Println("Press Ctrl+C to stop")
+}
+
+// parseBindAddresses splits the comma separated list of addresses given in
+// --bind, e.g. "127.0.0.1,::1".
+func parseBindAddresses(s string) []string {
+	var addresses []string
+	for _, addr := range strings.Split(s, ",") {
+		addr = strings.Trim(strings.TrimSpace(addr), "[]")
+		if addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+	if len(addresses) == 0 {
+		addresses = []string{"127.0.0.1"}
+	}
+	return addresses
+}
+
+// checkPortAvailable checks that the port is free on all the bind addresses.
+func checkPortAvailable(addresses []string, port int) error {
+	for _, addr := range addresses {
+		l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+		l.Close()
+	}
+	return nil
+}
+
+// findAvailablePort returns a port picked by the OS on the first of the
+// bind addresses that is free on all of them.
+func findAvailablePort(addresses []string) (int, error) {
+	var err error
+	for i := 0; i < 10; i++ {
+		var l net.Listener
+		l, err = net.Listen("tcp", net.JoinHostPort(addresses[0], "0"))
+		if err != nil {
+			return 0, err
+		}
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+
+		if err = checkPortAvailable(addresses[1:], port); err == nil {
+			return port, nil
+		}
+	}
+	return 0, err
+}
+
+// forwardedPrefixHandler supports serving the site behind a reverse proxy
+// that strips a sub-path, e.g. when https://example.com/preview/ is proxied
+// to the server root. The proxy tells us about the stripped path in the
+// X-Forwarded-Prefix header, which we add back so it matches the path in
+// baseURL, basePath. Prefixes not in basePath are ignored, the site is then
+// served from the server root.
+func forwardedPrefixHandler(basePath string, h http.Handler) http.Handler {
+	basePath = "/" + strings.Trim(basePath, "/") + "/"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimSuffix(r.Header.Get("X-Forwarded-Prefix"), "/")
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+
+		if prefix != "" && strings.HasPrefix(basePath, prefix+"/") && r.URL.Path != "/livereload.js" && r.URL.Path != "/livereload" &&
+			r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			r.URL.Path = prefix + r.URL.Path
+			r.RequestURI = r.URL.RequestURI()
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// latencyHandler delays the responses of h by d.
+func latencyHandler(d time.Duration, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(d)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// precompressedEncodings are the encodings of the precompressed siblings of
+// the published files in the order of preference.
+var precompressedEncodings = []struct {
+	name   string
+	suffix string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// precompressedHandler serves the precompressed sibling of the requested
+// file, e.g. main.css.br, if the client accepts its encoding and it was
+// created from the current version of the file, else it passes the request
+// on to h.
+func precompressedHandler(fs http.FileSystem, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if strings.HasSuffix(name, "/") {
+			name += "index.html"
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+
+		for _, enc := range precompressedEncodings {
+			if !accepted[enc.name] || !isPrecompressedSibling(fs, name, name+enc.suffix) {
+				continue
+			}
+
+			ctype := mime.TypeByExtension(path.Ext(name))
+			if ctype == "" {
+				ctype = "application/octet-stream"
+			}
+			w.Header().Set("Content-Type", ctype)
+			w.Header().Set("Content-Encoding", enc.name)
+
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = name + enc.suffix
+			h.ServeHTTP(w, r2)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// acceptedEncodings parses the Accept-Encoding header value.
+func acceptedEncodings(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		enc := strings.ToLower(strings.TrimSpace(fields[0]))
+		if enc == "" {
+			continue
+		}
+		if len(fields) > 1 && strings.Replace(strings.TrimSpace(fields[1]), " ", "", -1) == "q=0" {
+			continue
+		}
+		accepted[enc] = true
 	}
+	return accepted
+}
+
+// isPrecompressedSibling reports whether compressed exists and has the
+// modification time of filename, which the precompress step sets.
+func isPrecompressedSibling(fs http.FileSystem, filename, compressed string) bool {
+	stat := func(name string) os.FileInfo {
+		f, err := fs.Open(name)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			return nil
+		}
+		return fi
+	}
+
+	fi, cfi := stat(filename), stat(compressed)
 
-	jww.FEEDBACK.Println("Press Ctrl+C to stop")
+	return fi != nil && cfi != nil && fi.ModTime().Equal(cfi.ModTime())
 }
 
 // fixURL massages the baseURL into a form needed for serving
@@ -415,10 +688,10 @@ func fixURL(cfg config.Provider, s string, port int) (string, error) {
 	return u.String(), nil
 }
 
-func memStats() error {
-	memstats := serverCmd.Flags().Lookup("memstats").Value.String()
+func memStats(cmd *cobra.Command) error {
+	memstats := cmd.Flags().Lookup("memstats").Value.String()
 	if memstats != "" {
-		interval, err := time.ParseDuration(serverCmd.Flags().Lookup("meminterval").Value.String())
+		interval, err := time.ParseDuration(cmd.Flags().Lookup("meminterval").Value.String())
 		if err != nil {
 			interval, _ = time.ParseDuration("100ms")
 		}
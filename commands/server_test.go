@@ -14,9 +14,15 @@
 package commands
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/spf13/afero"
 	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFixURL(t *testing.T) {
@@ -56,3 +62,123 @@ func TestFixURL(t *testing.T) {
 		}
 	}
 }
+
+func TestParseBindAddresses(t *testing.T) {
+	for i, test := range []struct {
+		in     string
+		expect []string
+	}{
+		{"127.0.0.1", []string{"127.0.0.1"}},
+		{"127.0.0.1, ::1", []string{"127.0.0.1", "::1"}},
+		{"0.0.0.0,[::]", []string{"0.0.0.0", "::"}},
+		{"", []string{"127.0.0.1"}},
+	} {
+		if result := parseBindAddresses(test.in); !reflect.DeepEqual(result, test.expect) {
+			t.Errorf("[%d] expected %v, got %v", i, test.expect, result)
+		}
+	}
+}
+
+func TestForwardedPrefixHandler(t *testing.T) {
+	var path string
+	h := forwardedPrefixHandler("/preview/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+
+	for i, test := range []struct {
+		path   string
+		prefix string
+		expect string
+	}{
+		{"/posts/", "", "/posts/"},
+		{"/posts/", "/preview", "/preview/posts/"},
+		{"/posts/", "preview/", "/preview/posts/"},
+		{"/preview/posts/", "/preview", "/preview/posts/"},
+		{"/livereload.js", "/preview", "/livereload.js"},
+		// Not in baseURL.
+		{"/posts/", "/other", "/posts/"},
+		{"/posts/", "/pre", "/posts/"},
+	} {
+		r := httptest.NewRequest("GET", test.path, nil)
+		if test.prefix != "" {
+			r.Header.Set("X-Forwarded-Prefix", test.prefix)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if path != test.expect {
+			t.Errorf("[%d] expected %q, got %q", i, test.expect, path)
+		}
+	}
+}
+
+func TestFindAvailablePort(t *testing.T) {
+	assert := require.New(t)
+
+	port, err := findAvailablePort([]string{"127.0.0.1"})
+	assert.NoError(err)
+	assert.NoError(checkPortAvailable([]string{"127.0.0.1"}, port))
+
+	_, err = findAvailablePort([]string{"192.0.2.1"})
+	assert.Error(err)
+}
+
+func TestPrecompressedHandler(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	modTime := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, content := range map[string]string{
+		"/index.html":      "html",
+		"/index.html.br":   "html brotli",
+		"/index.html.gz":   "html gzip",
+		"/css/main.css":    "css",
+		"/css/main.css.gz": "css gzip",
+		"/js/main.js":      "js",
+		"/js/main.js.gz":   "stale js gzip",
+	} {
+		assert.NoError(afero.WriteFile(fs, name, []byte(content), 0755))
+		assert.NoError(fs.Chtimes(name, modTime, modTime))
+	}
+	assert.NoError(fs.Chtimes("/js/main.js", modTime.Add(time.Second), modTime.Add(time.Second)))
+
+	httpFs := afero.NewHttpFs(fs).Dir("/")
+	h := precompressedHandler(httpFs, http.FileServer(httpFs))
+
+	for i, test := range []struct {
+		path           string
+		acceptEncoding string
+		expectBody     string
+		expectEncoding string
+		expectType     string
+	}{
+		{"/", "gzip, deflate, br", "html brotli", "br", "text/html; charset=utf-8"},
+		{"/", "gzip, br;q=0", "html gzip", "gzip", "text/html; charset=utf-8"},
+		{"/", "", "html", "", "text/html; charset=utf-8"},
+		{"/css/main.css", "gzip, br", "css gzip", "gzip", "text/css; charset=utf-8"},
+		{"/js/main.js", "gzip", "js", "", ""},
+	} {
+		r := httptest.NewRequest("GET", test.path, nil)
+		if test.acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", test.acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(http.StatusOK, w.Code, "[%d]", i)
+		assert.Equal(test.expectBody, w.Body.String(), "[%d]", i)
+		assert.Equal(test.expectEncoding, w.Header().Get("Content-Encoding"), "[%d]", i)
+		if test.expectType != "" {
+			assert.Equal(test.expectType, w.Header().Get("Content-Type"), "[%d]", i)
+		}
+	}
+}
+
+func TestLatencyHandler(t *testing.T) {
+	h := latencyHandler(20*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	start := time.Now()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected a delay of at least 20ms, got %s", elapsed)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	"golang.org/x/net/context"
+	"golang.org/x/sync/errgroup"
+)
+
+// staticSync copies the static files to the destination, concurrently.
+// Unchanged files, i.e. with the same size and modification time or else the
+// same checksum, are not copied again, so large static files such as videos
+// are not rewritten on every build.
+type staticSync struct {
+	srcFs  afero.Fs
+	destFs afero.Fs
+
+	noTimes bool
+	noChmod bool
+
+	// If set, the target paths relative to this dir are mangled to be
+	// portable to Windows, see helpers.MakeWindowsSafePath, and shortened
+	// to windowsMaxPath.
+	windowsSafeDir string
+	windowsMaxPath int
+
+	numWorkers int
+
+	// Counters.
+	files   uint64
+	skipped uint64
+}
+
+func newStaticSync(c *commandeer, srcFs afero.Fs, publishDir string) *staticSync {
+	numWorkers := runtime.NumCPU() * 2
+	if numWorkers < 4 {
+		numWorkers = 4
+	}
+
+	s := &staticSync{
+		srcFs:      srcFs,
+		destFs:     c.Fs.Destination,
+		noTimes:    c.Cfg.GetBool("noTimes"),
+		noChmod:    c.Cfg.GetBool("noChmod"),
+		numWorkers: numWorkers,
+	}
+
+	if c.PathSpec().MangleWindowsPaths() {
+		s.windowsSafeDir = publishDir
+		s.windowsMaxPath = helpers.MaxWindowsPathIn(c.PathSpec().AbsPathify(publishDir))
+	}
+
+	return s
+}
+
+type staticSyncFile struct {
+	dst, src string
+	fi       os.FileInfo
+}
+
+// sync copies the files in the src dir into the dst dir.
+func (s *staticSync) sync(dst, src string) error {
+	files := make(chan staticSyncFile)
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	for i := 0; i < s.numWorkers; i++ {
+		g.Go(func() error {
+			for f := range files {
+				if err := s.syncFile(f.dst, f.src, f.fi); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// The directory permissions and modification times are synced when
+	// the files are in place.
+	var dirs []staticSyncFile
+
+	err := afero.Walk(s.srcFs, src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := s.targetPath(filepath.Join(dst, rel))
+
+		if fi.IsDir() {
+			dirs = append(dirs, staticSyncFile{dst: target, src: path, fi: fi})
+			return s.syncDir(target)
+		}
+
+		select {
+		case files <- staticSyncFile{dst: target, src: path, fi: fi}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	close(files)
+
+	if werr := g.Wait(); werr != nil {
+		// The walk error is a consequence of this.
+		return werr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := dirs[i]
+		dstat, err := s.destFs.Stat(d.dst)
+		if err != nil {
+			return err
+		}
+		if err := s.syncStats(d.dst, d.fi, dstat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// targetPath returns the target filename, mangled if windowsSafeDir is set.
+func (s *staticSync) targetPath(filename string) string {
+	if s.windowsSafeDir == "" {
+		return filename
+	}
+
+	rel, err := filepath.Rel(s.windowsSafeDir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+
+	return filepath.Join(s.windowsSafeDir, helpers.MakeWindowsSafePath(rel, s.windowsMaxPath))
+}
+
+func (s *staticSync) syncDir(dst string) error {
+	dstat, err := s.destFs.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if dstat != nil && dstat.IsDir() {
+		return nil
+	}
+
+	if dstat != nil {
+		if err := s.destFs.Remove(dst); err != nil {
+			return err
+		}
+	}
+
+	// The permissions are synced later.
+	return s.destFs.MkdirAll(dst, 0755)
+}
+
+func (s *staticSync) syncFile(dst, src string, fi os.FileInfo) error {
+	atomic.AddUint64(&s.files, 1)
+
+	dstat, err := s.destFs.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if dstat != nil && dstat.IsDir() {
+		if err := s.destFs.RemoveAll(dst); err != nil {
+			return err
+		}
+		dstat = nil
+	}
+
+	unchanged, err := s.unchanged(dst, src, fi, dstat)
+	if err != nil {
+		return err
+	}
+
+	if unchanged {
+		atomic.AddUint64(&s.skipped, 1)
+	} else {
+		if err := s.copyFile(dst, src); err != nil {
+			return err
+		}
+		if dstat, err = s.destFs.Stat(dst); err != nil {
+			return err
+		}
+	}
+
+	return s.syncStats(dst, fi, dstat)
+}
+
+// syncStats makes sure the destination has the same permissions and
+// modification time as the source.
+func (s *staticSync) syncStats(dst string, fi, dstat os.FileInfo) error {
+	if !s.noChmod && dstat.Mode().Perm() != fi.Mode().Perm() {
+		if err := s.destFs.Chmod(dst, fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if !s.noTimes && !dstat.ModTime().Equal(fi.ModTime()) {
+		if err := s.destFs.Chtimes(dst, fi.ModTime(), fi.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unchanged reports whether the destination file is the same as the source.
+// Files with the same size and modification time are assumed to be equal,
+// else the checksums are compared.
+func (s *staticSync) unchanged(dst, src string, fi, dstat os.FileInfo) (bool, error) {
+	if dstat == nil || dstat.Size() != fi.Size() {
+		return false, nil
+	}
+
+	if dstat.ModTime().Equal(fi.ModTime()) {
+		return true, nil
+	}
+
+	srcHash, err := s.checksum(s.srcFs, src)
+	if err != nil {
+		return false, err
+	}
+
+	dstHash, err := s.checksum(s.destFs, dst)
+	if err != nil {
+		return false, err
+	}
+
+	return srcHash == dstHash, nil
+}
+
+func (s *staticSync) checksum(fs afero.Fs, filename string) (string, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return helpers.MD5FromFile(f)
+}
+
+func (s *staticSync) copyFile(dst, src string) error {
+	sf, err := s.srcFs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	df, err := s.destFs.Create(dst)
+	if err != nil && os.IsNotExist(err) {
+		// When syncing a single file, the target directory may not exist yet.
+		if err = s.destFs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		df, err = s.destFs.Create(dst)
+	}
+	if err != nil {
+		return err
+	}
+	defer df.Close()
+
+	_, err = io.Copy(df, sf)
+	return err
+}
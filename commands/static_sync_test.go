@@ -0,0 +1,112 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticSync(t *testing.T) {
+	assert := require.New(t)
+
+	srcFs, destFs := afero.NewMemMapFs(), afero.NewMemMapFs()
+
+	writeFile := func(fs afero.Fs, filename, content string, modTime time.Time) {
+		filename = filepath.FromSlash(filename)
+		assert.NoError(afero.WriteFile(fs, filename, []byte(content), 0644))
+		assert.NoError(fs.Chtimes(filename, modTime, modTime))
+	}
+
+	readFile := func(filename string) string {
+		b, err := afero.ReadFile(destFs, filepath.FromSlash(filename))
+		assert.NoError(err)
+		return string(b)
+	}
+
+	t1 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	for _, filename := range []string{"/static/a.txt", "/static/b.txt", "/static/css/c.css", "/static/video/d.mp4"} {
+		writeFile(srcFs, filename, filepath.Base(filename), t1)
+	}
+
+	s := &staticSync{srcFs: srcFs, destFs: destFs, numWorkers: 3}
+	assert.NoError(s.sync(filepath.FromSlash("/public"), filepath.FromSlash("/static")))
+	assert.Equal(uint64(4), s.files)
+	assert.Equal(uint64(0), s.skipped)
+	assert.Equal("c.css", readFile("/public/css/c.css"))
+
+	fi, err := destFs.Stat(filepath.FromSlash("/public/video/d.mp4"))
+	assert.NoError(err)
+	assert.True(fi.ModTime().Equal(t1))
+
+	// Same size, different content and modification time.
+	writeFile(srcFs, "/static/a.txt", "A.txt", t2)
+	// Same content, different modification time.
+	writeFile(srcFs, "/static/b.txt", "b.txt", t2)
+	// Changed size.
+	writeFile(srcFs, "/static/css/c.css", "body {}", t1)
+
+	s = &staticSync{srcFs: srcFs, destFs: destFs, numWorkers: 3}
+	assert.NoError(s.sync(filepath.FromSlash("/public"), filepath.FromSlash("/static")))
+	assert.Equal(uint64(4), s.files)
+	assert.Equal(uint64(2), s.skipped)
+
+	assert.Equal("A.txt", readFile("/public/a.txt"))
+	assert.Equal("body {}", readFile("/public/css/c.css"))
+
+	fi, err = destFs.Stat(filepath.FromSlash("/public/b.txt"))
+	assert.NoError(err)
+	assert.True(fi.ModTime().Equal(t2))
+
+	// Single file.
+	writeFile(srcFs, "/static/new/e.txt", "e", t1)
+	s = &staticSync{srcFs: srcFs, destFs: destFs, numWorkers: 1}
+	assert.NoError(s.sync(filepath.FromSlash("/public/new/e.txt"), filepath.FromSlash("/static/new/e.txt")))
+	assert.Equal("e", readFile("/public/new/e.txt"))
+
+	assert.Error(s.sync(filepath.FromSlash("/public/f.txt"), filepath.FromSlash("/static/f.txt")))
+}
+
+func TestStaticSyncWindowsSafe(t *testing.T) {
+	assert := require.New(t)
+
+	srcFs, destFs := afero.NewMemMapFs(), afero.NewMemMapFs()
+
+	for _, filename := range []string{"/static/con/a.txt", "/static/aux.txt", "/static/b.txt"} {
+		assert.NoError(afero.WriteFile(srcFs, filepath.FromSlash(filename), []byte("x"), 0644))
+	}
+
+	publishDir := filepath.FromSlash("/public")
+	s := &staticSync{srcFs: srcFs, destFs: destFs, numWorkers: 2, windowsSafeDir: publishDir, windowsMaxPath: 100}
+	assert.NoError(s.sync(publishDir, filepath.FromSlash("/static")))
+
+	for _, filename := range []string{"/public/con_/a.txt", "/public/aux_.txt", "/public/b.txt"} {
+		_, err := destFs.Stat(filepath.FromSlash(filename))
+		assert.NoError(err, filename)
+	}
+
+	_, err := destFs.Stat(filepath.FromSlash("/public/con"))
+	assert.Error(err)
+
+	// Single file.
+	assert.NoError(s.sync(filepath.FromSlash("/public/con/a.txt"), filepath.FromSlash("/static/con/a.txt")))
+	_, err = destFs.Stat(filepath.FromSlash("/public/con"))
+	assert.Error(err)
+}
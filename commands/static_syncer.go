@@ -20,7 +20,6 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/gohugoio/hugo/helpers"
 	src "github.com/gohugoio/hugo/source"
-	"github.com/spf13/fsync"
 )
 
 type staticSyncer struct {
@@ -60,11 +59,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 			return 0, nil
 		}
 
-		syncer := fsync.NewSyncer()
-		syncer.NoTimes = c.Cfg.GetBool("noTimes")
-		syncer.NoChmod = c.Cfg.GetBool("noChmod")
-		syncer.SrcFs = staticSourceFs
-		syncer.DestFs = c.Fs.Destination
+		syncer := newStaticSync(c, staticSourceFs, publishDir)
 
 		// prevent spamming the log on changes
 		logger := helpers.NewDistinctFeedbackLogger()
@@ -107,7 +102,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 			if ev.Op&fsnotify.Rename == fsnotify.Rename || ev.Op&fsnotify.Remove == fsnotify.Remove {
 				if _, err := staticSourceFs.Stat(relPath); os.IsNotExist(err) {
 					// If file doesn't exist in any static dir, remove it
-					toRemove := filepath.Join(publishDir, relPath)
+					toRemove := syncer.targetPath(filepath.Join(publishDir, relPath))
 
 					logger.Println("File no longer exists in static dir, removing", toRemove)
 					_ = c.Fs.Destination.RemoveAll(toRemove)
@@ -115,7 +110,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 					// If file still exists, sync it
 					logger.Println("Syncing", relPath, "to", publishDir)
 
-					if err := syncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
+					if err := syncer.sync(filepath.Join(publishDir, relPath), relPath); err != nil {
 						c.Logger.ERROR.Println(err)
 					}
 				} else {
@@ -127,7 +122,7 @@ func (s *staticSyncer) syncsStaticEvents(staticEvents []fsnotify.Event) error {
 
 			// For all other event operations Hugo will sync static.
 			logger.Println("Syncing", relPath, "to", publishDir)
-			if err := syncer.Sync(filepath.Join(publishDir, relPath), relPath); err != nil {
+			if err := syncer.sync(filepath.Join(publishDir, relPath), relPath); err != nil {
 				c.Logger.ERROR.Println(err)
 			}
 		}
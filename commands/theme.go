@@ -0,0 +1,505 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"archive/zip"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var themePackageOutput string
+
+func init() {
+	themePackageCmd.Flags().StringVarP(&themePackageOutput, "output", "o", "", "filename of the archive, defaults to THEMENAME.zip in the current directory")
+	themeCmd.AddCommand(themePackageCmd)
+
+	initServerFlags(themeServeCmd)
+	themeCmd.AddCommand(themeServeCmd)
+}
+
+var themeCmd = &cobra.Command{
+	Use:   "theme",
+	Short: "Various helpers for theme authors",
+	Long: `Various helpers for theme authors.
+
+Theme requires a subcommand, e.g. ` + "`hugo theme package`.",
+	RunE: nil,
+}
+
+var themePackageCmd = &cobra.Command{
+	Use:   "package [path]",
+	Short: "Validate a theme and create a distributable archive",
+	Long: `Validate the theme in the given directory (default the current
+directory) and package it into a zip archive ready for submission.
+
+The command:
+
+    * checks that theme.toml has the fields required by the theme site
+    * checks that images/screenshot.png (1500x1000) and images/tn.png
+      (900x600) exist and are PNG images at least that size
+    * checks that the partials and templates referenced in the layouts exist
+    * builds the theme's exampleSite in memory
+
+The archive is only written when no problems are found. Version control
+files and the exampleSite's public and resources directories are left out.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		themeDir, err := filepath.Abs(dir)
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		fs := afero.NewOsFs()
+
+		problems, err := checkTheme(fs, themeDir)
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		if err := buildThemeExampleSite(themeDir); err != nil {
+			problems = append(problems, fmt.Sprintf("exampleSite: %s", err))
+		}
+
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+
+		if len(problems) > 0 {
+			return newSystemErrorF("Found %d problem(s) in theme", len(problems))
+		}
+
+		filename := themePackageOutput
+		if filename == "" {
+			filename = filepath.Base(themeDir) + ".zip"
+		}
+
+		filename, err = filepath.Abs(filename)
+		if err != nil {
+			return newSystemError(err)
+		}
+
+		f, err := os.Create(filename)
+		if err != nil {
+			return newSystemError(err)
+		}
+		defer f.Close()
+
+		if err := writeThemeArchive(f, fs, themeDir, filename); err != nil {
+			return newSystemError("Error creating theme archive:", err)
+		}
+
+		fmt.Println(filename, "created")
+
+		return nil
+	},
+}
+
+var themeServeCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "Serve the exampleSite of a theme",
+	Long: `Build and serve the exampleSite of the theme in the given directory
+(default the current directory) with the theme applied.
+
+This is a shortcut for
+
+    hugo server --source THEMEDIR/exampleSite --themesDir THEMEDIR/.. --theme THEMENAME
+
+Changes to both the exampleSite and the theme's layouts, i18n, data and
+static files are watched and rebuilt as in hugo server. It accepts the
+same flags as hugo server.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		exampleSite, dir, name, err := themeExampleSite(dir)
+		if err != nil {
+			return newUserError(err)
+		}
+
+		if !cmd.Flags().Changed("source") {
+			source = exampleSite
+		}
+		if !cmd.Flags().Changed("themesDir") {
+			themesDir = dir
+		}
+		if !cmd.Flags().Changed("theme") {
+			theme = name
+		}
+
+		return server(cmd, nil)
+	},
+}
+
+// themeExampleSite returns the exampleSite directory of the theme in dir
+// and the themes dir and theme name to build it with.
+func themeExampleSite(dir string) (exampleSite, themesDir, theme string, err error) {
+	themeDir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+
+	exampleSite = filepath.Join(themeDir, "exampleSite")
+	fi, err := os.Stat(exampleSite)
+	if err != nil {
+		err = fmt.Errorf("no exampleSite found in %s", themeDir)
+		return
+	}
+	if !fi.IsDir() {
+		err = fmt.Errorf("%s is not a directory", exampleSite)
+		return
+	}
+
+	return exampleSite, filepath.Dir(themeDir), filepath.Base(themeDir), nil
+}
+
+// requiredThemeFields are the theme.toml keys the theme site needs to list a
+// theme. Nested keys are separated by a dot.
+var requiredThemeFields = []string{
+	"name",
+	"license",
+	"licenselink",
+	"description",
+	"homepage",
+	"tags",
+	"features",
+	"min_version",
+	"author.name",
+}
+
+// checkTheme validates theme.toml and the layouts of the theme in themeDir.
+func checkTheme(fs afero.Fs, themeDir string) ([]string, error) {
+	var problems []string
+
+	b, err := afero.ReadFile(fs, filepath.Join(themeDir, "theme.toml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			problems = append(problems, "theme.toml: file not found")
+		} else {
+			return nil, err
+		}
+	} else {
+		meta, err := parser.HandleTOMLMetaData(b)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("theme.toml: %s", err))
+		} else {
+			for _, field := range missingThemeFields(meta.(map[string]interface{})) {
+				problems = append(problems, fmt.Sprintf("theme.toml: missing or empty %q", field))
+			}
+		}
+	}
+
+	images, err := checkThemeImages(fs, themeDir)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, images...)
+
+	missing, err := missingThemeLayouts(fs, themeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(problems, missing...), nil
+}
+
+// requiredThemeImages are the images the theme site needs to show a theme,
+// with their minimum size in pixels.
+var requiredThemeImages = []struct {
+	name          string
+	width, height int
+}{
+	{"images/screenshot.png", 1500, 1000},
+	{"images/tn.png", 900, 600},
+}
+
+// checkThemeImages checks that the theme's screenshot and thumbnail exist
+// and are PNG images at least the required size.
+func checkThemeImages(fs afero.Fs, themeDir string) ([]string, error) {
+	var problems []string
+
+	for _, img := range requiredThemeImages {
+		f, err := fs.Open(filepath.Join(themeDir, filepath.FromSlash(img.name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				problems = append(problems, fmt.Sprintf("%s: file not found", img.name))
+				continue
+			}
+			return nil, err
+		}
+
+		config, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: not a valid PNG image: %s", img.name, err))
+			continue
+		}
+
+		if config.Width < img.width || config.Height < img.height {
+			problems = append(problems, fmt.Sprintf("%s: must be at least %dx%d pixels, got %dx%d", img.name, img.width, img.height, config.Width, config.Height))
+		}
+	}
+
+	return problems, nil
+}
+
+// missingThemeFields returns the required fields not set in the theme config.
+func missingThemeFields(config map[string]interface{}) []string {
+	var missing []string
+
+	for _, field := range requiredThemeFields {
+		var (
+			v     interface{} = config
+			found             = true
+		)
+
+		for _, key := range strings.Split(field, ".") {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			if v, ok = m[key]; !ok {
+				found = false
+				break
+			}
+		}
+
+		if !found || isEmptyThemeValue(v) {
+			missing = append(missing, field)
+		}
+	}
+
+	return missing
+}
+
+func isEmptyThemeValue(v interface{}) bool {
+	switch vv := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(vv) == ""
+	case []interface{}:
+		return len(vv) == 0
+	case []string:
+		return len(vv) == 0
+	}
+	return false
+}
+
+var (
+	themePartialRe  = regexp.MustCompile(`{{-?\s*partial(?:Cached)?\s+"([^"]+)"`)
+	themeTemplateRe = regexp.MustCompile(`{{-?\s*template\s+"([^"]+)"`)
+	themeDefineRe   = regexp.MustCompile(`{{-?\s*(?:define|block)\s+"([^"]+)"`)
+)
+
+// missingThemeLayouts returns the partials and templates referenced in the
+// theme's layouts that do not exist in the theme.
+func missingThemeLayouts(fs afero.Fs, themeDir string) ([]string, error) {
+	layoutsDir := filepath.Join(themeDir, "layouts")
+
+	type reference struct {
+		file, name string
+		partial    bool
+	}
+
+	var (
+		files   = make(map[string]bool)
+		defined = make(map[string]bool)
+		refs    []reference
+	)
+
+	err := helpers.SymbolicWalk(fs, layoutsDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(layoutsDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		files[rel] = true
+
+		b, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range themeDefineRe.FindAllSubmatch(b, -1) {
+			defined[string(m[1])] = true
+		}
+		for _, m := range themePartialRe.FindAllSubmatch(b, -1) {
+			refs = append(refs, reference{file: rel, name: string(m[1]), partial: true})
+		}
+		for _, m := range themeTemplateRe.FindAllSubmatch(b, -1) {
+			refs = append(refs, reference{file: rel, name: string(m[1])})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+
+	for _, ref := range refs {
+		if ref.partial {
+			name := "partials/" + strings.TrimPrefix(ref.name, "partials/")
+			if files[name] || files[name+".html"] {
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("layouts/%s: partial %q not found", ref.file, ref.name))
+			continue
+		}
+
+		if defined[ref.name] || files[ref.name] || strings.HasPrefix(ref.name, "_internal/") {
+			continue
+		}
+		missing = append(missing, fmt.Sprintf("layouts/%s: template %q not found", ref.file, ref.name))
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// buildThemeExampleSite builds the exampleSite of the theme in themeDir in
+// memory.
+func buildThemeExampleSite(themeDir string) error {
+	var err error
+	source, themesDir, theme, err = themeExampleSite(themeDir)
+	if err != nil {
+		return err
+	}
+
+	cfgInit := func(c *commandeer) error {
+		c.Set("renderToMemory", true)
+		return nil
+	}
+
+	c, err := InitializeConfig(false, cfgInit)
+	if err != nil {
+		return err
+	}
+
+	return c.buildSites()
+}
+
+// skipInThemeArchive reports whether the file or directory with the given
+// slash separated path relative to the theme dir should be left out of the
+// archive.
+func skipInThemeArchive(rel string) bool {
+	if strings.HasPrefix(filepath.Base(rel), ".") {
+		return true
+	}
+
+	switch rel {
+	case "exampleSite/public", "exampleSite/resources", "node_modules":
+		return true
+	}
+
+	return false
+}
+
+// writeThemeArchive writes the files in themeDir as a zip archive to w. The
+// files are stored below a directory named as the theme dir. The files in
+// exclude, e.g. the archive itself, are left out.
+func writeThemeArchive(w io.Writer, fs afero.Fs, themeDir string, exclude ...string) error {
+	zw := zip.NewWriter(w)
+
+	root := filepath.Base(themeDir)
+
+	err := helpers.SymbolicWalk(fs, themeDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(themeDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if skipInThemeArchive(rel) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		for _, filename := range exclude {
+			if path == filename {
+				return nil
+			}
+		}
+
+		header, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		header.Name = root + "/" + rel
+		header.Method = zip.Deflate
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		f, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTheme(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	themeDir := filepath.FromSlash("/themes/mytheme")
+
+	writeThemeFile := func(name, content string) {
+		assert.NoError(afero.WriteFile(fs, filepath.Join(themeDir, filepath.FromSlash(name)), []byte(content), 0755))
+	}
+
+	writeThemeFile("theme.toml", `
+name = "My Theme"
+license = "MIT"
+licenselink = "https://example.com/LICENSE"
+description = ""
+homepage = "https://example.com/"
+tags = []
+features = ["blog"]
+min_version = "0.32"
+
+[author]
+name = "Author"
+`)
+	writeThemeFile("layouts/_default/baseof.html", `{{ partial "head.html" . }}{{ block "main" . }}{{ end }}{{- partial "footer" . -}}`)
+	writeThemeFile("layouts/_default/single.html", `{{ define "main" }}{{ template "_internal/disqus.html" . }}{{ template "sidebar" . }}{{ partialCached "nav.html" . }}{{ end }}`)
+	writeThemeFile("layouts/partials/head.html", `{{ template "partials/missing.html" . }}`)
+	writeThemeFile("layouts/partials/footer.html", ``)
+	writeThemeFile("images/screenshot.png", themePNG(t, 1500, 1000))
+	writeThemeFile("images/tn.png", themePNG(t, 300, 200))
+
+	problems, err := checkTheme(fs, themeDir)
+	assert.NoError(err)
+	assert.Equal([]string{
+		`theme.toml: missing or empty "description"`,
+		`theme.toml: missing or empty "tags"`,
+		`images/tn.png: must be at least 900x600 pixels, got 300x200`,
+		`layouts/_default/single.html: partial "nav.html" not found`,
+		`layouts/_default/single.html: template "sidebar" not found`,
+		`layouts/partials/head.html: template "partials/missing.html" not found`,
+	}, problems)
+
+	assert.NoError(fs.Remove(filepath.Join(themeDir, "theme.toml")))
+	assert.NoError(fs.Remove(filepath.Join(themeDir, "images", "screenshot.png")))
+	writeThemeFile("images/tn.png", "not a PNG")
+	problems, err = checkTheme(fs, themeDir)
+	assert.NoError(err)
+	assert.Equal("theme.toml: file not found", problems[0])
+	assert.Equal("images/screenshot.png: file not found", problems[1])
+	assert.Contains(problems[2], "images/tn.png: not a valid PNG image")
+}
+
+func themePNG(t *testing.T, width, height int) string {
+	var b bytes.Buffer
+	require.NoError(t, png.Encode(&b, image.NewGray(image.Rect(0, 0, width, height))))
+	return b.String()
+}
+
+func TestMissingThemeFields(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(requiredThemeFields, missingThemeFields(map[string]interface{}{}))
+	assert.Equal([]string{"author.name"}, missingThemeFields(map[string]interface{}{
+		"name":        "a",
+		"license":     "MIT",
+		"licenselink": "b",
+		"description": "c",
+		"homepage":    "d",
+		"tags":        []interface{}{"e"},
+		"features":    []interface{}{"f"},
+		"min_version": "0.32",
+		"author":      map[string]interface{}{"homepage": "g"},
+	}))
+}
+
+func TestWriteThemeArchive(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	themeDir := filepath.FromSlash("/themes/mytheme")
+
+	for _, name := range []string{
+		"theme.toml",
+		"layouts/index.html",
+		"exampleSite/config.toml",
+		"exampleSite/public/index.html",
+		"exampleSite/resources/_gen/images/a.jpg",
+		".git/HEAD",
+		"static/.DS_Store",
+		"mytheme.zip",
+	} {
+		assert.NoError(afero.WriteFile(fs, filepath.Join(themeDir, filepath.FromSlash(name)), []byte(name), 0755))
+	}
+
+	var b bytes.Buffer
+	assert.NoError(writeThemeArchive(&b, fs, themeDir, filepath.Join(themeDir, "mytheme.zip")))
+
+	r, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	assert.NoError(err)
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+
+	assert.Equal([]string{
+		"mytheme/exampleSite/config.toml",
+		"mytheme/layouts/index.html",
+		"mytheme/theme.toml",
+	}, names)
+}
+
+func TestThemeExampleSite(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir("", "hugo-theme")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	themeDir := filepath.Join(dir, "mytheme")
+	assert.NoError(os.MkdirAll(themeDir, 0777))
+
+	_, _, _, err = themeExampleSite(themeDir)
+	assert.Error(err)
+
+	assert.NoError(os.MkdirAll(filepath.Join(themeDir, "exampleSite"), 0777))
+
+	exampleSite, themesDir, theme, err := themeExampleSite(themeDir)
+	assert.NoError(err)
+	assert.Equal(filepath.Join(themeDir, "exampleSite"), exampleSite)
+	assert.Equal(dir, themesDir)
+	assert.Equal("mytheme", theme)
+}
+
+func TestThemeFlagConfig(t *testing.T) {
+	assert := require.New(t)
+
+	home := buildTestSite(t, map[string]string{
+		"config.toml": `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[params]
+author = "Bep"
+`,
+		"content/a.md": "---\ntitle: A\n---\nContent.",
+		"mythemes/mytheme/config.toml": `
+[params]
+author = "Theme"
+color = "blue"
+`,
+		"mythemes/mytheme/layouts/index.html": "{{ .Site.Params.color }}|{{ .Site.Params.author }}",
+	}, "--theme", "mytheme", "--themesDir", "mythemes")
+
+	assert.Equal("blue|Bep", home)
+}
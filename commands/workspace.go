@@ -0,0 +1,195 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// workspaceConfig is a set of Hugo sites built together with
+// hugo --workspace sites.toml, e.g. a documentation portal made of many
+// small sites:
+//
+//   cacheDir = "cache"
+//   resourceDir = "resources"
+//
+//   [[sites]]
+//   source = "api"
+//   destination = "public/api"
+//
+//   [[sites]]
+//   source = "guide"
+//
+// All paths are relative to the workspace file. With --destination, each
+// site without a destination is written to a sub dir of it named after the
+// site's source, e.g. api for the first site above.
+type workspaceConfig struct {
+	// The cache dir shared by all the sites, e.g. for getJSON.
+	CacheDir string
+
+	// The resource dir shared by all the sites, e.g. for the processed
+	// images.
+	ResourceDir string
+
+	Sites []workspaceSite
+
+	// The dir of the workspace file.
+	dir string
+}
+
+type workspaceSite struct {
+	// The site root.
+	Source string
+
+	// Where to write the site. Default is the site's publishDir.
+	Destination string
+}
+
+// loadWorkspace reads the workspace file and makes its paths absolute.
+func loadWorkspace(fs afero.Fs, filename string) (workspaceConfig, error) {
+	var w workspaceConfig
+
+	v := viper.New()
+	v.SetFs(fs)
+	v.SetConfigFile(filename)
+	if err := v.ReadInConfig(); err != nil {
+		return w, fmt.Errorf("failed to read workspace %q: %s", filename, err)
+	}
+
+	if err := mapstructure.WeakDecode(v.AllSettings(), &w); err != nil {
+		return w, fmt.Errorf("failed to decode workspace %q: %s", filename, err)
+	}
+
+	if len(w.Sites) == 0 {
+		return w, fmt.Errorf("no sites in workspace %q", filename)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return w, err
+	}
+
+	abs := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	w.dir = dir
+	w.CacheDir = abs(w.CacheDir)
+	w.ResourceDir = abs(w.ResourceDir)
+	for i, s := range w.Sites {
+		if s.Source == "" {
+			return w, fmt.Errorf("site %d in workspace %q has no source", i+1, filename)
+		}
+		w.Sites[i].Source = abs(s.Source)
+		w.Sites[i].Destination = abs(s.Destination)
+	}
+
+	return w, nil
+}
+
+// siteDestination returns the publish dir for the given site, or an empty
+// string for the site's publishDir. The destination flag, if set, is shared
+// by all the sites, so each site gets its own dir below it.
+func (w workspaceConfig) siteDestination(site workspaceSite, destinationFlag string) (string, error) {
+	if site.Destination != "" || destinationFlag == "" {
+		return site.Destination, nil
+	}
+
+	dir, err := filepath.Abs(destinationFlag)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(w.dir, site.Source)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(site.Source)
+	}
+
+	return filepath.Join(dir, rel), nil
+}
+
+// buildWorkspace builds all the sites in the workspace file, one after the
+// other in this process. A failing site does not stop the others; the errors
+// are reported together at the end.
+func buildWorkspace(filename string) error {
+	w, err := loadWorkspace(afero.NewOsFs(), filename)
+	if err != nil {
+		return newUserError(err)
+	}
+
+	// The flags are shared by all the sites, and InitializeConfig
+	// updates some of them from the site config.
+	origSource, origDestination, origCacheDir := source, destination, cacheDir
+	defer func() {
+		source, destination, cacheDir = origSource, origDestination, origCacheDir
+	}()
+
+	var errs []string
+
+	for _, site := range w.Sites {
+		siteDestination, err := w.siteDestination(site, origDestination)
+		if err != nil {
+			return err
+		}
+		source, destination, cacheDir = site.Source, siteDestination, origCacheDir
+
+		if !quiet {
+			fmt.Printf("Building %s\n", site.Source)
+		}
+
+		if err := buildWorkspaceSite(w); err != nil {
+			errs = append(errs, fmt.Sprintf("  %s: %s", site.Source, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return newSystemErrorF("%d of %d sites in the workspace failed to build:\n%s", len(errs), len(w.Sites), strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+func buildWorkspaceSite(w workspaceConfig) error {
+	// Each site is built with its own HugoSites.
+	Hugo = nil
+
+	cfgInit := func(c *commandeer) error {
+		if w.CacheDir != "" {
+			c.Set("cacheDir", w.CacheDir)
+		}
+		if w.ResourceDir != "" {
+			c.Set("resourceDir", w.ResourceDir)
+		}
+		// Report the render errors with the other sites' instead of
+		// stopping the process.
+		c.Set("returnRenderErrors", true)
+		return nil
+	}
+
+	c, err := InitializeConfig(false, cfgInit)
+	if err != nil {
+		return err
+	}
+
+	return c.build()
+}
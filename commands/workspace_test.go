@@ -0,0 +1,89 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadWorkspace(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	dir := filepath.FromSlash("/work")
+	filename := filepath.Join(dir, "sites.toml")
+
+	assert.NoError(afero.WriteFile(fs, filename, []byte(`
+cacheDir = "cache"
+
+[[sites]]
+source = "api"
+destination = "public/api"
+
+[[sites]]
+source = "/abs/guide"
+`), 0755))
+
+	w, err := loadWorkspace(fs, filename)
+	assert.NoError(err)
+
+	assert.Equal(filepath.Join(dir, "cache"), w.CacheDir)
+	assert.Equal("", w.ResourceDir)
+	assert.Len(w.Sites, 2)
+	assert.Equal(filepath.Join(dir, "api"), w.Sites[0].Source)
+	assert.Equal(filepath.Join(dir, "public", "api"), w.Sites[0].Destination)
+	assert.Equal("/abs/guide", w.Sites[1].Source)
+	assert.Equal("", w.Sites[1].Destination)
+
+	assert.NoError(afero.WriteFile(fs, filename, []byte(`cacheDir = "cache"`), 0755))
+	_, err = loadWorkspace(fs, filename)
+	assert.Error(err)
+
+	assert.NoError(afero.WriteFile(fs, filename, []byte("[[sites]]\ndestination = \"public\"\n"), 0755))
+	_, err = loadWorkspace(fs, filename)
+	assert.Error(err)
+
+	_, err = loadWorkspace(fs, filepath.Join(dir, "missing.toml"))
+	assert.Error(err)
+}
+
+func TestWorkspaceSiteDestination(t *testing.T) {
+	assert := require.New(t)
+
+	dir := filepath.FromSlash("/work")
+	w := workspaceConfig{dir: dir}
+
+	api := workspaceSite{Source: filepath.Join(dir, "sites", "api")}
+	guide := workspaceSite{Source: filepath.FromSlash("/abs/guide")}
+	custom := workspaceSite{Source: filepath.Join(dir, "custom"), Destination: filepath.Join(dir, "out")}
+
+	for i, test := range []struct {
+		site     workspaceSite
+		flag     string
+		expected string
+	}{
+		{api, "", ""},
+		{api, filepath.FromSlash("/public"), filepath.FromSlash("/public/sites/api")},
+		{guide, filepath.FromSlash("/public"), filepath.FromSlash("/public/guide")},
+		{custom, filepath.FromSlash("/public"), filepath.Join(dir, "out")},
+	} {
+		destination, err := w.siteDestination(test.site, test.flag)
+		assert.NoError(err)
+		assert.Equal(test.expected, destination, "[%d]", i)
+	}
+}
@@ -16,9 +16,12 @@ package create
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"unicode"
 
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugolib"
@@ -30,11 +33,9 @@ import (
 func NewContent(
 	ps *helpers.PathSpec,
 	siteFactory func(filename string, siteUsed bool) (*hugolib.Site, error), kind, targetPath string) error {
-	ext := helpers.Ext(targetPath)
-
-	jww.INFO.Printf("attempting to create %q of %q of ext %q", targetPath, kind, ext)
+	jww.INFO.Printf("attempting to create %q of %q", targetPath, kind)
 
-	archetypeFilename := findArchetype(ps, kind, ext)
+	archetypeFilename := findArchetype(ps, kind, targetPath)
 
 	// Building the sites can be expensive, so only do it if really needed.
 	siteUsed := false
@@ -69,61 +70,260 @@ func NewContent(
 		return err
 	}
 
-	jww.FEEDBACK.Println(contentPath, "created")
+	porcelain := s.Cfg.GetBool("newContentPorcelain")
 
-	editor := s.Cfg.GetString("newContentEditor")
-	if editor != "" {
-		jww.FEEDBACK.Printf("Editing %s with %q ...\n", targetPath, editor)
+	if porcelain {
+		// Print the path only, to make it easy to consume in scripts.
+		fmt.Println(contentPath)
+	} else {
+		jww.FEEDBACK.Println(contentPath, "created")
+	}
 
-		cmd := exec.Command(editor, contentPath)
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	if editor := s.Cfg.GetString("newContentEditor"); editor != "" {
+		if !porcelain {
+			jww.FEEDBACK.Printf("Editing %s with %q ...\n", targetPath, editor)
+		}
 
-		return cmd.Run()
+		cmd, err := newContentCommand(editor, contentPath)
+		if err != nil {
+			return err
+		}
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	if hook := s.Cfg.GetString("newContentHook"); hook != "" {
+		jww.INFO.Printf("Running post-create hook %q on %s", hook, targetPath)
+
+		cmd, err := newContentCommand(hook, contentPath)
+		if err != nil {
+			return err
+		}
+		cmd.Dir = s.PathSpec.WorkingDir()
+		if porcelain {
+			// Keep stdout clean for the created path.
+			cmd.Stdout = os.Stderr
+		}
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("Post-create hook %q failed: %s", hook, err)
+		}
 	}
 
 	return nil
 }
 
-// FindArchetype takes a given kind/archetype of content and returns an output
-// path for that archetype.  If no archetype is found, an empty string is
-// returned.
-func findArchetype(ps *helpers.PathSpec, kind, ext string) (outpath string) {
-	search := []string{ps.AbsPathify(ps.Cfg.GetString("archetypeDir"))}
+// newContentCommand creates a command that runs the given command line with
+// the content filename as its last argument. The command line is split into
+// arguments with the shell's quoting rules, and environment variables are
+// expanded, so "$EDITOR" and "'/Applications/My Editor' --wait" can be used.
+func newContentCommand(command, filename string) (*exec.Cmd, error) {
+	args, err := splitCommandLine(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Command %q is empty after expansion", command)
+	}
 
-	if ps.Cfg.GetString("theme") != "" {
-		themeDir := filepath.Join(ps.AbsPathify(ps.Cfg.GetString("themesDir")+"/"+ps.Cfg.GetString("theme")), "/archetypes/")
-		if _, err := ps.Fs.Source.Stat(themeDir); os.IsNotExist(err) {
-			jww.ERROR.Printf("Unable to find archetypes directory for theme %q at %q", ps.Cfg.GetString("theme"), themeDir)
-		} else {
-			search = append(search, themeDir)
+	cmd := exec.Command(args[0], append(args[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd, nil
+}
+
+// splitCommandLine splits the command line into arguments like a POSIX
+// shell: text in single quotes is taken as is, text in double quotes is kept
+// in one argument with only \", \\ and \$ escaped and the environment
+// variables expanded, and outside quotes a backslash escapes the next
+// character and the values of environment variables are split on white space.
+func splitCommandLine(command string) ([]string, error) {
+	var (
+		args   []string
+		word   []rune
+		inWord bool
+		runes  = []rune(command)
+	)
+
+	endWord := func() {
+		if inWord {
+			args = append(args, string(word))
 		}
+		word, inWord = word[:0], false
 	}
 
-	for _, x := range search {
-		// If the new content isn't in a subdirectory, kind == "".
-		// Therefore it should be excluded otherwise `is a directory`
-		// error will occur. github.com/gohugoio/hugo/issues/411
-		var pathsToCheck = []string{"default"}
+	// expand reads the variable name after the $ at runes[i] and returns
+	// its value and the index of the last rune read. A $ not followed by a
+	// name is returned as is.
+	expand := func(i int) (string, int) {
+		if i+1 < len(runes) && runes[i+1] == '{' {
+			end := i + 2
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end < len(runes) {
+				return os.Getenv(string(runes[i+2 : end])), end
+			}
+		}
+		end := i + 1
+		for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+			end++
+		}
+		if end == i+1 {
+			return "$", i
+		}
+		return os.Getenv(string(runes[i+1 : end])), end - 1
+	}
 
-		if ext != "" {
-			if kind != "" {
-				pathsToCheck = append([]string{kind + ext, "default" + ext}, pathsToCheck...)
-			} else {
-				pathsToCheck = append([]string{"default" + ext}, pathsToCheck...)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			endWord()
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word, inWord = append(word, runes[i]), true
+			}
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
 			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("Unterminated single quote in command %q", command)
+			}
+			word, inWord = append(word, runes[i+1:end]...), true
+			i = end
+		case r == '"':
+			inWord = true
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, fmt.Errorf("Unterminated double quote in command %q", command)
+				}
+				r = runes[i]
+				if r == '"' {
+					break
+				}
+				if r == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`, runes[i+1]) {
+					i++
+					word = append(word, runes[i])
+				} else if r == '$' {
+					var value string
+					value, i = expand(i)
+					word = append(word, []rune(value)...)
+				} else {
+					word = append(word, r)
+				}
+			}
+		case r == '$':
+			start := i
+			var value string
+			value, i = expand(i)
+			if i == start {
+				word, inWord = append(word, r), true
+				continue
+			}
+			// Split the value like the shell, joining its first and last
+			// fields with the text around the variable.
+			if strings.IndexFunc(value, unicode.IsSpace) == 0 {
+				endWord()
+			}
+			for j, field := range strings.Fields(value) {
+				if j > 0 {
+					endWord()
+				}
+				word, inWord = append(word, []rune(field)...), true
+			}
+			if strings.LastIndexFunc(value, unicode.IsSpace) == len(value)-1 && value != "" {
+				endWord()
+			}
+		default:
+			word, inWord = append(word, r), true
 		}
+	}
+
+	endWord()
+
+	return args, nil
+}
+
+// findArchetype takes a given kind/archetype of content and returns the path
+// to the archetype file to use. The project's archetype directory is searched
+// before the theme's, and in each directory we look for, in order:
+//
+// 1. <kind><ext>, kind being the type given with --kind or the section.
+// 2. <section><ext>, if a kind other than the section was given.
+// 3. default<ext>
+// 4. default
+//
+// If no archetype is found, an empty string is returned.
+func findArchetype(ps *helpers.PathSpec, kind, targetPath string) (outpath string) {
+	ext := helpers.Ext(targetPath)
+	section := contentSection(targetPath)
 
-		for _, p := range pathsToCheck {
+	for _, x := range archetypeDirs(ps) {
+		for _, p := range archetypeCandidates(kind, section, ext) {
 			curpath := filepath.Join(x, p)
 			jww.DEBUG.Println("checking", curpath, "for archetypes")
 			if exists, _ := helpers.Exists(curpath, ps.Fs.Source); exists {
-				jww.INFO.Println("curpath: " + curpath)
+				jww.INFO.Printf("Using archetype %q for %q", curpath, targetPath)
 				return curpath
 			}
 		}
 	}
 
+	jww.INFO.Printf("No archetype found for %q, using the default", targetPath)
+
 	return ""
 }
+
+// archetypeDirs returns the archetype directories to search, in lookup order.
+func archetypeDirs(ps *helpers.PathSpec) []string {
+	dirs := []string{ps.AbsPathify(ps.Cfg.GetString("archetypeDir"))}
+
+	if ps.ThemeSet() {
+		themeDir := filepath.Join(ps.GetThemeDir(), "archetypes")
+		if _, err := ps.Fs.Source.Stat(themeDir); os.IsNotExist(err) {
+			jww.ERROR.Printf("Unable to find archetypes directory for theme %q at %q", ps.Theme(), themeDir)
+		} else {
+			dirs = append(dirs, themeDir)
+		}
+	}
+
+	return dirs
+}
+
+func archetypeCandidates(kind, section, ext string) []string {
+	// If the new content isn't in a subdirectory, kind == "".
+	// Therefore it should be excluded otherwise `is a directory`
+	// error will occur. github.com/gohugoio/hugo/issues/411
+	if ext == "" {
+		return []string{"default"}
+	}
+
+	var candidates []string
+
+	if kind != "" {
+		candidates = append(candidates, kind+ext)
+	}
+
+	if section != "" && section != kind {
+		candidates = append(candidates, section+ext)
+	}
+
+	return append(candidates, "default"+ext, "default")
+}
+
+// contentSection returns the first directory of the content path, if any.
+func contentSection(targetPath string) string {
+	parts := strings.Split(strings.Trim(filepath.ToSlash(targetPath), "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0]
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package create
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContentCommand(t *testing.T) {
+	assert := require.New(t)
+
+	os.Setenv("HUGO_TEST_EDITOR", "myeditor --wait")
+	defer os.Unsetenv("HUGO_TEST_EDITOR")
+
+	for i, test := range []struct {
+		command  string
+		expected []string
+	}{
+		{"vim", []string{"vim", "content/post/a.md"}},
+		{"git add", []string{"git", "add", "content/post/a.md"}},
+		{"$HUGO_TEST_EDITOR", []string{"myeditor", "--wait", "content/post/a.md"}},
+		{"$HUGO_TEST_NOT_SET", nil},
+		{"'/Applications/My Editor.app/bin/edit' --wait", []string{"/Applications/My Editor.app/bin/edit", "--wait", "content/post/a.md"}},
+		{`"$HUGO_TEST_EDITOR" -n`, []string{"myeditor --wait", "-n", "content/post/a.md"}},
+		{`my\ editor '$HUGO_TEST_EDITOR' "a \"b\"" ${HUGO_TEST_EDITOR}x $`, []string{"my editor", "$HUGO_TEST_EDITOR", `a "b"`, "myeditor", "--waitx", "$", "content/post/a.md"}},
+		{"'vim", nil},
+		{`vim "a`, nil},
+	} {
+		cmd, err := newContentCommand(test.command, "content/post/a.md")
+		if test.expected == nil {
+			assert.Error(err, "[%d]", i)
+			continue
+		}
+		assert.NoError(err)
+		assert.Equal(test.expected, cmd.Args, "[%d]", i)
+	}
+}
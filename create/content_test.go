@@ -46,9 +46,12 @@ func TestNewContent(t *testing.T) {
 		{"post", "post/sample-1.md", []string{`title = "Post Arch title"`, `test = "test1"`, "date = \"2015-01-12T19:20:04-07:00\""}},
 		{"post", "post/org-1.org", []string{`#+title: ORG-1`}},
 		{"emptydate", "post/sample-ed.md", []string{`title = "Empty Date Arch title"`, `test = "test1"`}},
-		{"stump", "stump/sample-2.md", []string{`title: "Sample 2"`}},      // no archetype file
-		{"", "sample-3.md", []string{`title: "Sample 3"`}},                 // no archetype
-		{"product", "product/sample-4.md", []string{`title = "SAMPLE-4"`}}, // empty archetype front matter
+		{"stump", "stump/sample-2.md", []string{`title: "Sample 2"`}},       // no archetype file
+		{"", "sample-3.md", []string{`title: "Sample 3"`}},                  // no archetype
+		{"product", "product/sample-4.md", []string{`title = "SAMPLE-4"`}},  // empty archetype front matter
+		{"product", "post/sample-5.md", []string{`title = "SAMPLE-5"`}},     // kind before section
+		{"nope", "post/sample-6.md", []string{`title = "Post Arch title"`}}, // fall back to section
+		{"themed", "themed/sample-7.md", []string{`title = "Theme Arch title"`}},
 		{"shortcodes", "shortcodes/go.md", []string{
 			`title = "GO"`,
 			"{{< myshortcode >}}",
@@ -117,6 +120,14 @@ func initFs(fs *hugofs.Fs) error {
 			path:    filepath.Join("archetypes", "post.md"),
 			content: "+++\ndate = \"2015-01-12T19:20:04-07:00\"\ntitle = \"Post Arch title\"\ntest = \"test1\"\n+++\n",
 		},
+		{
+			path:    filepath.Join("themes", "sample", "archetypes", "post.md"),
+			content: "+++\ntitle = \"Shadowed Theme Arch title\"\n+++\n",
+		},
+		{
+			path:    filepath.Join("themes", "sample", "archetypes", "themed.md"),
+			content: "+++\ndate = \"2015-01-12T19:20:04-07:00\"\ntitle = \"Theme Arch title\"\n+++\n",
+		},
 		{
 			path:    filepath.Join("archetypes", "post.org"),
 			content: "#+title: {{ .BaseFileName  | upper }}",
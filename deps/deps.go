@@ -54,6 +54,9 @@ type Deps struct {
 	translationProvider ResourceProvider
 
 	Metrics metrics.Provider
+
+	// Records the templates executed if templateCoverage is set.
+	Coverage *tpl.Coverage
 }
 
 // ResourceProvider is used to create and refresh, and clone resources needed.
@@ -125,6 +128,7 @@ func New(cfg DepsCfg) (*Deps, error) {
 	if err != nil {
 		return nil, err
 	}
+	contentSpec.LogWarnings(logger)
 
 	sp := source.NewSourceSpec(cfg.Language, fs)
 
@@ -145,6 +149,10 @@ func New(cfg DepsCfg) (*Deps, error) {
 		d.Metrics = metrics.NewProvider(cfg.Cfg.GetBool("templateMetricsHints"))
 	}
 
+	if cfg.Cfg.GetBool("templateCoverage") {
+		d.Coverage = tpl.NewCoverage()
+	}
+
 	return d, nil
 }
 
@@ -162,6 +170,7 @@ func (d Deps) ForLanguage(l *helpers.Language) (*Deps, error) {
 	if err != nil {
 		return nil, err
 	}
+	d.ContentSpec.LogWarnings(d.Log)
 
 	d.Cfg = l
 	d.Language = l
@@ -25,6 +25,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/alecthomas/chroma"
 	"github.com/chaseadamsio/goorgeous"
 	bp "github.com/gohugoio/hugo/bufferpool"
 	"github.com/gohugoio/hugo/config"
@@ -54,6 +55,19 @@ type ContentSpec struct {
 	Highlight            func(code, lang, optsStr string) (string, error)
 	defatultPygmentsOpts map[string]string
 
+	// The custom highlighting styles, keyed by lower case name.
+	chromaStyles map[string]*chroma.Style
+
+	// The converters for the markup formats rendered by external helpers,
+	// keyed by format, e.g. "asciidoc".
+	converters map[string]ContentConverter
+
+	// Markup holds the markup configuration and capabilities.
+	Markup MarkupConfig
+
+	// Set when pygmentsUseClassic is set but Pygments is not installed.
+	pygmentsMissing bool
+
 	cfg config.Provider
 }
 
@@ -80,13 +94,28 @@ func NewContentSpec(cfg config.Provider) (*ContentSpec, error) {
 	}
 	spec.defatultPygmentsOpts = options
 
+	spec.chromaStyles, err = NewChromaStyles(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.converters, err = newContentConverters(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Markup, err = newMarkupConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use the Pygmentize on path if present
 	useClassic := false
 	h := newHiglighters(spec)
 
 	if cfg.GetBool("pygmentsUseClassic") {
 		if !hasPygments() {
-			jww.WARN.Println("Highlighting with pygmentsUseClassic set requires Pygments to be installed and in the path")
+			spec.pygmentsMissing = true
 		} else {
 			useClassic = true
 		}
@@ -101,6 +130,14 @@ func NewContentSpec(cfg config.Provider) (*ContentSpec, error) {
 	return spec, nil
 }
 
+// LogWarnings logs the warnings about the content configuration, e.g. a
+// missing Pygments, to the given logger.
+func (c *ContentSpec) LogWarnings(logger *jww.Notepad) {
+	if c.pygmentsMissing {
+		Warnidf(logger, "warning-pygments-missing", "Highlighting with pygmentsUseClassic set requires Pygments to be installed and in the path")
+	}
+}
+
 // BlackFriday holds configuration values for BlackFriday rendering.
 type BlackFriday struct {
 	Smartypants           bool
@@ -111,6 +148,7 @@ type BlackFriday struct {
 	SmartDashes           bool
 	LatexDashes           bool
 	TaskLists             bool
+	Attributes            bool
 	PlainIDAnchors        bool
 	Extensions            []string
 	ExtensionsMask        []string
@@ -128,6 +166,7 @@ func newBlackfriday(config map[string]interface{}) *BlackFriday {
 		"latexDashes":           true,
 		"plainIDAnchors":        true,
 		"taskLists":             true,
+		"attributes":            false,
 	}
 
 	ToLowerMap(defaultParam)
@@ -445,28 +484,56 @@ type RenderingContext struct {
 	Config       *BlackFriday
 	RenderTOC    bool
 	Cfg          config.Provider
+
+	// RenderHooks, if set, renders tables and blockquotes in Markdown with
+	// templates. Only supported by Blackfriday.
+	RenderHooks RenderHooks
 }
 
 // RenderBytes renders a []byte.
 func (c ContentSpec) RenderBytes(ctx *RenderingContext) []byte {
 	switch ctx.PageFmt {
 	default:
+		if _, found := c.converters[ctx.PageFmt]; found {
+			// Registered by a plugin.
+			return c.externalRender(ctx)
+		}
 		return c.markdownRender(ctx)
 	case "markdown":
 		return c.markdownRender(ctx)
-	case "asciidoc":
-		return getAsciidocContent(ctx)
+	case "asciidoc", "rst", "pandoc":
+		return c.externalRender(ctx)
 	case "mmark":
 		return c.mmarkRender(ctx)
-	case "rst":
-		return getRstContent(ctx)
 	case "org":
 		return orgRender(ctx, c)
-	case "pandoc":
-		return getPandocContent(ctx)
 	}
 }
 
+// HasConverter returns whether a ContentConverter is registered for the
+// markup format, e.g. "asciidoc" or a format of a plugin.
+func (c ContentSpec) HasConverter(format string) bool {
+	_, found := c.converters[format]
+	return found
+}
+
+// externalRender renders the content with the ContentConverter registered
+// for its format.
+func (c ContentSpec) externalRender(ctx *RenderingContext) []byte {
+	converter, found := c.converters[ctx.PageFmt]
+	if !found {
+		jww.ERROR.Printf("%s: no converter for %q found", ctx.DocumentName, ctx.PageFmt)
+		return ctx.Content
+	}
+
+	result, err := converter.Convert(ctx)
+	if err != nil {
+		jww.ERROR.Println(err)
+	}
+
+	return result
+}
+
 // TotalWords counts instance of one or more consecutive white space
 // characters, as defined by unicode.IsSpace, in s.
 // This is a cheaper way of word counting than the obvious len(strings.Fields(s)).
@@ -602,32 +669,6 @@ func HasAsciidoc() bool {
 		getAsciidocExecPath() != "")
 }
 
-// getAsciidocContent calls asciidoctor or asciidoc as an external helper
-// to convert AsciiDoc content to HTML.
-func getAsciidocContent(ctx *RenderingContext) []byte {
-	var isAsciidoctor bool
-	path := getAsciidoctorExecPath()
-	if path == "" {
-		path = getAsciidocExecPath()
-		if path == "" {
-			jww.ERROR.Println("asciidoctor / asciidoc not found in $PATH: Please install.\n",
-				"                 Leaving AsciiDoc content unrendered.")
-			return ctx.Content
-		}
-	} else {
-		isAsciidoctor = true
-	}
-
-	jww.INFO.Println("Rendering", ctx.DocumentName, "with", path, "...")
-	args := []string{"--no-header-footer", "--safe"}
-	if isAsciidoctor {
-		// asciidoctor-specific arg to show stack traces on errors
-		args = append(args, "--trace")
-	}
-	args = append(args, "-")
-	return externallyRenderContent(ctx, path, args)
-}
-
 // HasRst returns whether rst2html is installed on this computer.
 func HasRst() bool {
 	return getRstExecPath() != ""
@@ -655,50 +696,6 @@ func getPythonExecPath() string {
 	return path
 }
 
-// getRstContent calls the Python script rst2html as an external helper
-// to convert reStructuredText content to HTML.
-func getRstContent(ctx *RenderingContext) []byte {
-	python := getPythonExecPath()
-	path := getRstExecPath()
-
-	if path == "" {
-		jww.ERROR.Println("rst2html / rst2html.py not found in $PATH: Please install.\n",
-			"                 Leaving reStructuredText content unrendered.")
-		return ctx.Content
-
-	}
-	jww.INFO.Println("Rendering", ctx.DocumentName, "with", path, "...")
-	args := []string{path, "--leave-comments", "--initial-header-level=2"}
-	result := externallyRenderContent(ctx, python, args)
-	// TODO(bep) check if rst2html has a body only option.
-	bodyStart := bytes.Index(result, []byte("<body>\n"))
-	if bodyStart < 0 {
-		bodyStart = -7 //compensate for length
-	}
-
-	bodyEnd := bytes.Index(result, []byte("\n</body>"))
-	if bodyEnd < 0 || bodyEnd >= len(result) {
-		bodyEnd = len(result) - 1
-		if bodyEnd < 0 {
-			bodyEnd = 0
-		}
-	}
-
-	return result[bodyStart+7 : bodyEnd]
-}
-
-// getPandocContent calls pandoc as an external helper to convert pandoc markdown to HTML.
-func getPandocContent(ctx *RenderingContext) []byte {
-	path, err := exec.LookPath("pandoc")
-	if err != nil {
-		jww.ERROR.Println("pandoc not found in $PATH: Please install.\n",
-			"                 Leaving pandoc content unrendered.")
-		return ctx.Content
-	}
-	args := []string{"--mathjax"}
-	return externallyRenderContent(ctx, path, args)
-}
-
 func orgRender(ctx *RenderingContext, c ContentSpec) []byte {
 	content := ctx.Content
 	cleanContent := bytes.Replace(content, []byte("# more"), []byte(""), 1)
@@ -706,11 +703,12 @@ func orgRender(ctx *RenderingContext, c ContentSpec) []byte {
 		c.getHTMLRenderer(blackfriday.HTML_TOC, ctx))
 }
 
-func externallyRenderContent(ctx *RenderingContext, path string, args []string) []byte {
+func externallyRenderContent(ctx *RenderingContext, path string, args []string, dir string) ([]byte, error) {
 	content := ctx.Content
 	cleanContent := bytes.Replace(content, SummaryDivider, []byte(""), 1)
 
 	cmd := exec.Command(path, args...)
+	cmd.Dir = dir
 	cmd.Stdin = bytes.NewReader(cleanContent)
 	var out, cmderr bytes.Buffer
 	cmd.Stdout = &out
@@ -725,8 +723,8 @@ func externallyRenderContent(ctx *RenderingContext, path string, args []string)
 		}
 	}
 	if err != nil {
-		jww.ERROR.Printf("%s rendering %s: %v", path, ctx.DocumentName, err)
+		err = fmt.Errorf("%s rendering %s: %v", path, ctx.DocumentName, err)
 	}
 
-	return normalizeExternalHelperLineFeeds(out.Bytes())
+	return normalizeExternalHelperLineFeeds(out.Bytes()), err
 }
@@ -0,0 +1,394 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday"
+)
+
+// Attribute lists such as {#id .class key=val} set the HTML attributes of
+// the element they belong to:
+//
+//   # A heading {#intro .title}
+//
+//   A paragraph with a class.
+//   {.lead}
+//
+//   ![Logo](logo.png){width=200}
+//
+// An attribute list in a paragraph of its own applies to the table,
+// blockquote, list or code block before it.
+//
+// Attribute lists are off by default, as text in braces would else change
+// in existing content. They are enabled in the site config:
+//
+//   [blackfriday]
+//   attributes = true
+
+var (
+	// An attribute list at the end of a heading.
+	headingAttributesRe = regexp.MustCompile(`\s*\{([^{}\n]*)\}\s*$`)
+
+	// An attribute list on the last line of a paragraph.
+	blockAttributesRe = regexp.MustCompile(`(?:^|\n)\{([^{}\n]*)\}\s*$`)
+
+	// An attribute list directly after an image.
+	inlineAttributesRe = regexp.MustCompile(`^\{([^{}\n]*)\}`)
+)
+
+// Quotes are usually converted by Smartypants before we see them.
+var attributeQuotes = map[rune]rune{
+	'"':      '"',
+	'\'':     '\'',
+	'“': '”',
+	'‘': '’',
+}
+
+// parseAttributes parses the content of an attribute list, e.g.
+// #id .class key=val. It returns false if this is not a valid attribute
+// list.
+func parseAttributes(s string) (map[string]string, bool) {
+	s = html.UnescapeString(s)
+	attrs := make(map[string]string)
+	var classes []string
+
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) == 0 {
+		return nil, false
+	}
+
+	isNameRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':'
+	}
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		switch runes[i] {
+		case '#', '.':
+			start := i + 1
+			j := start
+			for j < len(runes) && !unicode.IsSpace(runes[j]) {
+				j++
+			}
+			if j == start {
+				return nil, false
+			}
+			if runes[i] == '#' {
+				attrs["id"] = string(runes[start:j])
+			} else {
+				classes = append(classes, string(runes[start:j]))
+			}
+			i = j
+		default:
+			start := i
+			for i < len(runes) && isNameRune(runes[i]) {
+				i++
+			}
+			if i == start || i >= len(runes) || runes[i] != '=' {
+				return nil, false
+			}
+			key := strings.ToLower(string(runes[start:i]))
+			i++
+
+			var value []rune
+			if closing, ok := attributeQuotes[runeAt(runes, i)]; ok {
+				j := i + 1
+				for j < len(runes) && runes[j] != closing {
+					j++
+				}
+				if j == len(runes) {
+					return nil, false
+				}
+				value = runes[i+1 : j]
+				i = j + 1
+			} else {
+				j := i
+				for j < len(runes) && !unicode.IsSpace(runes[j]) {
+					j++
+				}
+				value = runes[i:j]
+				i = j
+			}
+
+			if key == "class" {
+				classes = append(classes, string(value))
+			} else {
+				attrs[key] = string(value)
+			}
+		}
+	}
+
+	if len(classes) > 0 {
+		attrs["class"] = strings.Join(classes, " ")
+	}
+
+	return attrs, true
+}
+
+func runeAt(runes []rune, i int) rune {
+	if i < len(runes) {
+		return runes[i]
+	}
+	return 0
+}
+
+// splitAttributes splits the trailing attribute list matched by re from the
+// rendered text.
+func splitAttributes(re *regexp.Regexp, text []byte) ([]byte, map[string]string) {
+	m := re.FindSubmatchIndex(text)
+	if m == nil {
+		return text, nil
+	}
+	attrs, ok := parseAttributes(string(text[m[2]:m[3]]))
+	if !ok {
+		return text, nil
+	}
+	return text[:m[0]], attrs
+}
+
+// addAttributes adds the attributes to the first HTML tag in b. An
+// existing class is extended, other existing attributes are replaced.
+func addAttributes(b []byte, attrs map[string]string) []byte {
+	start := bytes.IndexByte(b, '<')
+	if start == -1 || len(attrs) == 0 {
+		return b
+	}
+	end := bytes.IndexByte(b[start:], '>')
+	if end == -1 {
+		return b
+	}
+	end += start
+
+	tag := string(b[start:end])
+	closing := ""
+	if strings.HasSuffix(tag, "/") {
+		tag = strings.TrimRight(strings.TrimSuffix(tag, "/"), " ")
+		closing = " /"
+	}
+
+	for _, k := range sortedAttributeNames(attrs) {
+		v := html.EscapeString(attrs[k])
+		existing := " " + k + `="`
+		if i := strings.Index(tag, existing); i != -1 {
+			vstart := i + len(existing)
+			vend := vstart + strings.IndexByte(tag[vstart:], '"')
+			if k == "class" {
+				v = tag[vstart:vend] + " " + v
+			}
+			tag = tag[:vstart] + v + tag[vend:]
+			continue
+		}
+		tag += " " + k + `="` + v + `"`
+	}
+
+	var out bytes.Buffer
+	out.Write(b[:start])
+	out.WriteString(tag)
+	out.WriteString(closing)
+	out.Write(b[end:])
+	return out.Bytes()
+}
+
+func sortedAttributeNames(attrs map[string]string) []string {
+	names := make([]string, 0, len(attrs))
+	for k := range attrs {
+		names = append(names, k)
+	}
+	order := func(k string) int {
+		switch k {
+		case "id":
+			return 0
+		case "class":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(names, func(i, j int) bool {
+		oi, oj := order(names[i]), order(names[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// attributeTarget is the last block rendered, so an attribute list in the
+// paragraph after it can be applied to it.
+type attributeTarget struct {
+	out        *bytes.Buffer
+	start, end int
+
+	// Renders the block again with the given attributes, if set.
+	render func(attrs map[string]string) []byte
+}
+
+func (r *HugoHTMLRenderer) attributesEnabled() bool {
+	return r.Config.Attributes
+}
+
+// setAttributeTarget records the block rendered into out from start.
+func (r *HugoHTMLRenderer) setAttributeTarget(out *bytes.Buffer, start int, render func(attrs map[string]string) []byte) {
+	if !r.attributesEnabled() {
+		return
+	}
+	r.attributeTarget = attributeTarget{out: out, start: start, end: out.Len(), render: render}
+}
+
+// applyToAttributeTarget applies the attributes to the block just before
+// the current position in out. It returns false if there is no such block.
+func (r *HugoHTMLRenderer) applyToAttributeTarget(out *bytes.Buffer, attrs map[string]string) bool {
+	t := r.attributeTarget
+	if t.out != out || t.end != out.Len() {
+		return false
+	}
+
+	var b []byte
+	if t.render != nil {
+		b = t.render(attrs)
+	} else {
+		b = addAttributes(append([]byte(nil), out.Bytes()[t.start:]...), attrs)
+	}
+
+	out.Truncate(t.start)
+	out.Write(b)
+	r.attributeTarget = attributeTarget{}
+
+	return true
+}
+
+// Header adds support for attribute lists to headings.
+func (r *HugoHTMLRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	if !r.attributesEnabled() {
+		r.Renderer.Header(out, text, level, id)
+		return
+	}
+
+	var attrs map[string]string
+
+	// The Blackfriday header ID extension passes on the full
+	// {#id .class} as the ID.
+	if strings.ContainsAny(id, " \t") {
+		if a, ok := parseAttributes("#" + id); ok {
+			attrs = a
+			id = attrs["id"]
+		}
+	}
+
+	marker := out.Len()
+	if !text() {
+		return
+	}
+	content := append([]byte(nil), out.Bytes()[marker:]...)
+	out.Truncate(marker)
+
+	if attrs == nil {
+		content, attrs = splitAttributes(headingAttributesRe, content)
+		if attrs != nil {
+			if attrs["id"] != "" {
+				id = attrs["id"]
+			} else if id != "" && getMarkdownExtensions(r.RenderingContext)&blackfriday.EXTENSION_AUTO_HEADER_IDS != 0 {
+				// Blackfriday created the ID from the text including
+				// the attribute list.
+				id = blackfriday.SanitizedAnchorName(html.UnescapeString(StripHTML(string(content))))
+			}
+		}
+	}
+	delete(attrs, "id")
+
+	r.Renderer.Header(out, func() bool {
+		out.Write(content)
+		return true
+	}, level, id)
+
+	if len(attrs) > 0 {
+		b := addAttributes(append([]byte(nil), out.Bytes()[marker:]...), attrs)
+		out.Truncate(marker)
+		out.Write(b)
+	}
+}
+
+// Paragraph adds support for attribute lists to paragraphs and the block
+// before it.
+func (r *HugoHTMLRenderer) Paragraph(out *bytes.Buffer, text func() bool) {
+	if !r.attributesEnabled() {
+		r.Renderer.Paragraph(out, text)
+		return
+	}
+
+	marker := out.Len()
+	if !text() {
+		return
+	}
+	rendered := append([]byte(nil), out.Bytes()[marker:]...)
+	out.Truncate(marker)
+
+	content, attrs := splitAttributes(blockAttributesRe, rendered)
+	if attrs != nil && len(bytes.TrimSpace(content)) == 0 {
+		if r.applyToAttributeTarget(out, attrs) {
+			return
+		}
+		// Nothing to apply it to.
+		content, attrs = rendered, nil
+	}
+
+	r.Renderer.Paragraph(out, func() bool {
+		out.Write(content)
+		return true
+	})
+
+	if len(attrs) > 0 {
+		b := addAttributes(append([]byte(nil), out.Bytes()[marker:]...), attrs)
+		out.Truncate(marker)
+		out.Write(b)
+	}
+}
+
+// Image records the image so an attribute list following it can be
+// applied.
+func (r *HugoHTMLRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	start := out.Len()
+	r.Renderer.Image(out, link, title, alt)
+	if r.attributesEnabled() {
+		r.image = attributeTarget{out: out, start: start, end: out.Len()}
+	}
+}
+
+// NormalText applies an attribute list directly following an image.
+func (r *HugoHTMLRenderer) NormalText(out *bytes.Buffer, text []byte) {
+	if r.image.out == out && r.image.end == out.Len() {
+		start := r.image.start
+		r.image = attributeTarget{}
+		if m := inlineAttributesRe.FindSubmatchIndex(text); m != nil {
+			if attrs, ok := parseAttributes(string(text[m[2]:m[3]])); ok {
+				b := addAttributes(append([]byte(nil), out.Bytes()[start:]...), attrs)
+				out.Truncate(start)
+				out.Write(b)
+				text = text[m[1]:]
+			}
+		}
+	}
+	r.Renderer.NormalText(out, text)
+}
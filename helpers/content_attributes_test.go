@@ -0,0 +1,136 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	for i, test := range []struct {
+		in     string
+		expect map[string]string
+	}{
+		{"#intro", map[string]string{"id": "intro"}},
+		{".a .b", map[string]string{"class": "a b"}},
+		{"#i .a width=200 data-x=y", map[string]string{"id": "i", "class": "a", "width": "200", "data-x": "y"}},
+		{`title="A title" class=c .d`, map[string]string{"title": "A title", "class": "c d"}},
+		{"title=&ldquo;Smart quotes&rdquo;", map[string]string{"title": "Smart quotes"}},
+		{"", nil},
+		{"just text", nil},
+		{"key=", map[string]string{"key": ""}},
+		{`title="unclosed`, nil},
+		{"#", nil},
+	} {
+		attrs, ok := parseAttributes(test.in)
+		assert.Equal(test.expect != nil, ok, fmt.Sprintf("[%d] %s", i, test.in))
+		if ok {
+			assert.Equal(test.expect, attrs, fmt.Sprintf("[%d] %s", i, test.in))
+		}
+	}
+}
+
+func TestAddAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	attrs := map[string]string{"class": "b", "id": "x", "title": `"q"`}
+
+	assert.Equal(`<p id="x" class="b" title="&#34;q&#34;">Text</p>`, string(addAttributes([]byte("<p>Text</p>"), attrs)))
+	assert.Equal(`<ul class="a b" id="x" title="&#34;q&#34;">`, string(addAttributes([]byte(`<ul class="a">`), attrs)))
+	assert.Equal(`<img src="a.png" alt="" id="x" class="b" title="&#34;q&#34;" />`, string(addAttributes([]byte(`<img src="a.png" alt="" />`), attrs)))
+}
+
+func TestMarkdownAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	c := newTestContentSpec()
+
+	render := func(content string, enabled bool) string {
+		bf := *c.BlackFriday
+		bf.Attributes = enabled
+		ctx := &RenderingContext{Cfg: c.cfg, Config: &bf}
+		ctx.Content = []byte(content)
+		return string(c.markdownRender(ctx))
+	}
+
+	out := render(`
+# Heading {.title}
+
+## Other {#custom .sub lang=en}
+
+A paragraph.
+{.lead}
+
+![Logo](logo.png){width=200 .logo} after.
+
+| a | b |
+|---|---|
+| 1 | 2 |
+
+{.striped}
+
+- one
+- two
+
+{#list}
+
+Not {an attribute list}.
+`, true)
+
+	assert.Contains(out, `<h1 id="heading" class="title">Heading</h1>`)
+	assert.Contains(out, `<h2 id="custom" class="sub" lang="en">Other</h2>`)
+	assert.Contains(out, `<p class="lead">A paragraph.</p>`)
+	assert.Contains(out, `<img src="logo.png" alt="Logo" class="logo" width="200" /> after.`)
+	assert.Contains(out, `<table class="striped">`)
+	assert.Contains(out, `<ul id="list">`)
+	assert.Contains(out, `<p>Not {an attribute list}.</p>`)
+	assert.NotContains(out, "{.striped}")
+
+	out = render("A paragraph.\n{.lead}\n", false)
+	assert.Contains(out, "<p>A paragraph.\n{.lead}</p>")
+}
+
+func TestMarkdownAttributesRenderHooks(t *testing.T) {
+	assert := require.New(t)
+
+	c := newTestContentSpec()
+	c.BlackFriday.Attributes = true
+	hooks := &testRenderHooks{}
+	ctx := &RenderingContext{Cfg: c.cfg, Config: c.BlackFriday, RenderHooks: hooks}
+	ctx.Content = []byte(`
+| a | b |
+|---|---|
+| 1 | 2 |
+{.striped}
+
+> Quote.
+
+{.pull}
+`)
+
+	out := string(c.markdownRender(ctx))
+
+	assert.Contains(out, `<div class="table">0</div>`)
+	assert.Contains(out, `<blockquote class="pull">`)
+
+	table := hooks.tables[len(hooks.tables)-1]
+	assert.Equal(map[string]string{"class": "striped"}, table.Attributes)
+	blockquote := hooks.blockquotes[len(hooks.blockquotes)-1]
+	assert.Equal(map[string]string{"class": "pull"}, blockquote.Attributes)
+}
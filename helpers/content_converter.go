@@ -0,0 +1,357 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/mitchellh/mapstructure"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// ContentConverter converts content in a markup format to HTML.
+type ContentConverter interface {
+	// Convert returns the content in ctx converted to HTML.
+	Convert(ctx *RenderingContext) ([]byte, error)
+
+	// Available returns whether the converter can be used, i.e. whether any
+	// external helper it needs is installed.
+	Available() bool
+}
+
+// ExternalConverterConfig configures the external helper used to convert a
+// markup format. It is set per format in the site config, e.g.:
+//
+//   [markup.asciidoc]
+//   extensions = ["asciidoctor-diagram"]
+//   safeMode = "unsafe"
+//   workingDir = "content"
+type ExternalConverterConfig struct {
+	// The binary to use instead of looking for the default ones in $PATH.
+	Binary string
+
+	// Additional arguments passed to the binary.
+	Args []string
+
+	// Extensions to enable. For Asciidoctor these are the libraries passed
+	// with -r, for Pandoc the Markdown extensions, e.g. "footnotes".
+	Extensions []string
+
+	// The safe mode. For Asciidoctor one of unsafe, safe, server or secure,
+	// defaults to safe. For reStructuredText "secure" disables raw HTML and
+	// file insertion.
+	SafeMode string
+
+	// The directory to run the binary in, relative to the working dir.
+	// Includes in the content are resolved relative to this directory.
+	WorkingDir string
+
+	// The maximum number of processes to run at the same time. Defaults to
+	// the number of CPUs.
+	MaxProcesses int
+}
+
+// externalConverter is a ContentConverter calling an external helper.
+type externalConverter struct {
+	// The name of the markup format, e.g. "AsciiDoc".
+	name string
+
+	cfg        ExternalConverterConfig
+	workingDir string
+
+	// Finds the binary and returns its path and the arguments to pass to it.
+	// It returns an empty path if the binary is not found.
+	find func(cfg ExternalConverterConfig) (path string, args []string)
+
+	// Optional processing of the output.
+	postProcess func(b []byte) []byte
+
+	init sync.Once
+	path string
+	args []string
+
+	// Limits the number of processes running at the same time.
+	sem chan struct{}
+}
+
+func newExternalConverter(name string, cfg ExternalConverterConfig, workingDir string, find func(cfg ExternalConverterConfig) (string, []string)) *externalConverter {
+	maxProcesses := cfg.MaxProcesses
+	if maxProcesses <= 0 {
+		maxProcesses = runtime.NumCPU()
+	}
+
+	dir := workingDir
+	if cfg.WorkingDir != "" {
+		dir = filepath.Join(workingDir, cfg.WorkingDir)
+	}
+
+	return &externalConverter{
+		name:       name,
+		cfg:        cfg,
+		workingDir: dir,
+		find:       find,
+		sem:        make(chan struct{}, maxProcesses),
+	}
+}
+
+func (c *externalConverter) lookup() {
+	c.init.Do(func() {
+		c.path, c.args = c.find(c.cfg)
+		if c.path != "" {
+			c.args = append(c.args, c.cfg.Args...)
+		}
+	})
+}
+
+func (c *externalConverter) Available() bool {
+	c.lookup()
+	return c.path != ""
+}
+
+// Convert runs the external helper with the content on stdin. If the helper
+// is not installed, the content is returned unrendered and an error is
+// logged once.
+func (c *externalConverter) Convert(ctx *RenderingContext) ([]byte, error) {
+	if !c.Available() {
+		DistinctErrorLog.Printf("%s: no %s helper found in $PATH, please install one or set the binary in the markup config. Leaving %s content unrendered.",
+			ctx.DocumentName, c.name, c.name)
+		return ctx.Content, nil
+	}
+
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+
+	jww.INFO.Println("Rendering", ctx.DocumentName, "with", c.path, "...")
+
+	result, err := externallyRenderContent(ctx, c.path, c.args, c.workingDir)
+	if c.postProcess != nil {
+		result = c.postProcess(result)
+	}
+
+	return result, err
+}
+
+// newContentConverters creates the converters for the markup formats
+// rendered by external helpers.
+func newContentConverters(cfg config.Provider) (map[string]ContentConverter, error) {
+	markupCfg := cfg.GetStringMap("markup")
+	workingDir := cfg.GetString("workingDir")
+
+	converterCfg := func(format string) (ExternalConverterConfig, error) {
+		var c ExternalConverterConfig
+		if v, found := markupCfg[format]; found {
+			if err := mapstructure.WeakDecode(v, &c); err != nil {
+				return c, fmt.Errorf("failed to decode markup config for %q: %s", format, err)
+			}
+		}
+		return c, nil
+	}
+
+	converters := make(map[string]ContentConverter)
+
+	for format, create := range map[string]func(cfg ExternalConverterConfig, workingDir string) *externalConverter{
+		"asciidoc": newAsciidocConverter,
+		"rst":      newRstConverter,
+		"pandoc":   newPandocConverter,
+	} {
+		c, err := converterCfg(format)
+		if err != nil {
+			return nil, err
+		}
+		converters[format] = create(c, workingDir)
+	}
+
+	ps, err := plugins.Load(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range ps {
+		for format, convert := range p.Converters() {
+			format = strings.ToLower(format)
+			if _, found := converters[format]; found || GuessType(format) != "unknown" {
+				return nil, fmt.Errorf("plugin %q: the markup format %q is built in", p.Name(), format)
+			}
+			converters[format] = pluginConverter(convert)
+		}
+	}
+
+	return converters, nil
+}
+
+// pluginConverter is a ContentConverter registered by a plugin.
+type pluginConverter func(content []byte) ([]byte, error)
+
+func (c pluginConverter) Convert(ctx *RenderingContext) ([]byte, error) {
+	return c(ctx.Content)
+}
+
+func (c pluginConverter) Available() bool {
+	return true
+}
+
+// ExternalHelper describes the external helper used to render a markup
+// format.
+type ExternalHelper struct {
+	// The markup format, e.g. "asciidoc".
+	Format string
+
+	// The name of the markup format, e.g. "AsciiDoc".
+	Name string
+
+	// The full path to the helper binary, empty if not found.
+	Path string
+}
+
+// ExternalHelpers returns the external helpers used to render the markup
+// formats in the given configuration, sorted by format.
+func ExternalHelpers(cfg config.Provider) ([]ExternalHelper, error) {
+	converters, err := newContentConverters(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var helpers []ExternalHelper
+	for format, c := range converters {
+		ec, ok := c.(*externalConverter)
+		if !ok {
+			continue
+		}
+		ec.lookup()
+		path := ec.path
+		if format == "rst" && path != "" {
+			// rst2html is run by Python.
+			path = ec.args[0]
+		}
+		helpers = append(helpers, ExternalHelper{Format: format, Name: ec.name, Path: path})
+	}
+
+	sort.Slice(helpers, func(i, j int) bool {
+		return helpers[i].Format < helpers[j].Format
+	})
+
+	return helpers, nil
+}
+
+func newAsciidocConverter(cfg ExternalConverterConfig, workingDir string) *externalConverter {
+	return newExternalConverter("AsciiDoc", cfg, workingDir, func(cfg ExternalConverterConfig) (string, []string) {
+		path := lookPath(cfg.Binary, "asciidoctor")
+		isAsciidoctor := path != ""
+		if !isAsciidoctor {
+			path = lookPath(cfg.Binary, "asciidoc")
+			if path == "" {
+				return "", nil
+			}
+		}
+
+		args := []string{"--no-header-footer"}
+
+		if isAsciidoctor {
+			safeMode := cfg.SafeMode
+			if safeMode == "" {
+				safeMode = "safe"
+			}
+			args = append(args, "--safe-mode", safeMode)
+			for _, ext := range cfg.Extensions {
+				args = append(args, "-r", ext)
+			}
+			// asciidoctor-specific arg to show stack traces on errors
+			args = append(args, "--trace")
+		} else if cfg.SafeMode != "unsafe" {
+			args = append(args, "--safe")
+		}
+
+		return path, append(args, "-")
+	})
+}
+
+func newRstConverter(cfg ExternalConverterConfig, workingDir string) *externalConverter {
+	c := newExternalConverter("reStructuredText", cfg, workingDir, func(cfg ExternalConverterConfig) (string, []string) {
+		rst := lookPath(cfg.Binary, "rst2html", "rst2html.py")
+		python := getPythonExecPath()
+		if rst == "" || python == "" {
+			return "", nil
+		}
+
+		args := []string{rst, "--leave-comments", "--initial-header-level=2"}
+		if cfg.SafeMode == "secure" {
+			args = append(args, "--no-raw", "--no-file-insertion")
+		}
+
+		return python, args
+	})
+	c.postProcess = extractRstBody
+
+	return c
+}
+
+func newPandocConverter(cfg ExternalConverterConfig, workingDir string) *externalConverter {
+	return newExternalConverter("Pandoc", cfg, workingDir, func(cfg ExternalConverterConfig) (string, []string) {
+		path := lookPath(cfg.Binary, "pandoc")
+		if path == "" {
+			return "", nil
+		}
+
+		args := []string{"--mathjax"}
+		if len(cfg.Extensions) > 0 {
+			args = append(args, "--from", "markdown+"+strings.Join(cfg.Extensions, "+"))
+		}
+
+		return path, args
+	})
+}
+
+// lookPath returns the full path to the configured binary if set, else to
+// the first of the defaults found in $PATH. It returns an empty string if
+// none is found.
+func lookPath(configured string, defaults ...string) string {
+	if configured != "" {
+		defaults = []string{configured}
+	}
+	for _, name := range defaults {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// extractRstBody returns the content of the body element in the HTML
+// document created by rst2html.
+func extractRstBody(result []byte) []byte {
+	// TODO(bep) check if rst2html has a body only option.
+	bodyStart := bytes.Index(result, []byte("<body>\n"))
+	if bodyStart < 0 {
+		bodyStart = -7 //compensate for length
+	}
+
+	bodyEnd := bytes.Index(result, []byte("\n</body>"))
+	if bodyEnd < 0 || bodyEnd >= len(result) {
+		bodyEnd = len(result) - 1
+		if bodyEnd < 0 {
+			bodyEnd = 0
+		}
+	}
+
+	return result[bodyStart+7 : bodyEnd]
+}
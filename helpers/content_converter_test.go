@@ -0,0 +1,129 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContentConverters(t *testing.T) {
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("workingDir", "/my/work")
+	v.Set("markup", map[string]interface{}{
+		"asciidoc": map[string]interface{}{
+			"extensions":   []string{"asciidoctor-diagram"},
+			"safeMode":     "unsafe",
+			"workingDir":   "content",
+			"maxProcesses": 2,
+		},
+	})
+
+	converters, err := newContentConverters(v)
+	assert.NoError(err)
+	assert.Len(converters, 3)
+
+	ad := converters["asciidoc"].(*externalConverter)
+	assert.Equal([]string{"asciidoctor-diagram"}, ad.cfg.Extensions)
+	assert.Equal(filepath.Join("/my/work", "content"), ad.workingDir)
+	assert.Equal(2, cap(ad.sem))
+
+	rst := converters["rst"].(*externalConverter)
+	assert.Equal("/my/work", rst.workingDir)
+	assert.Equal(runtime.NumCPU(), cap(rst.sem))
+
+	v.Set("markup", map[string]interface{}{
+		"pandoc": map[string]interface{}{"maxProcesses": "many"},
+	})
+	_, err = newContentConverters(v)
+	assert.Error(err)
+}
+
+func TestAsciidocConverterArgs(t *testing.T) {
+	assert := require.New(t)
+
+	c := newAsciidocConverter(ExternalConverterConfig{
+		Extensions: []string{"asciidoctor-diagram"},
+		SafeMode:   "server",
+		Args:       []string{"--verbose"},
+	}, "")
+
+	if _, err := exec.LookPath("asciidoctor"); err != nil {
+		t.Skip("asciidoctor not installed")
+	}
+
+	assert.True(c.Available())
+	assert.Equal([]string{"--no-header-footer", "--safe-mode", "server", "-r", "asciidoctor-diagram", "--trace", "-", "--verbose"}, c.args)
+}
+
+func TestExternalConverter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip on Windows")
+	}
+
+	assert := require.New(t)
+
+	c := newExternalConverter("Test", ExternalConverterConfig{Binary: "tr", Args: []string{"a-z", "A-Z"}}, "", func(cfg ExternalConverterConfig) (string, []string) {
+		return lookPath(cfg.Binary, "does-not-exist"), nil
+	})
+	c.postProcess = func(b []byte) []byte {
+		return append([]byte("<p>"), b...)
+	}
+
+	assert.True(c.Available())
+
+	result, err := c.Convert(&RenderingContext{Content: []byte("hello<!--more--> world"), DocumentName: "doc.test"})
+	assert.NoError(err)
+	assert.Equal("<p>HELLO WORLD", string(result))
+
+	missing := newExternalConverter("Missing", ExternalConverterConfig{}, "", func(cfg ExternalConverterConfig) (string, []string) {
+		return lookPath(cfg.Binary, "hugo-does-not-exist"), nil
+	})
+
+	assert.False(missing.Available())
+	result, err = missing.Convert(&RenderingContext{Content: []byte("unrendered"), DocumentName: "doc.missing"})
+	assert.NoError(err)
+	assert.Equal("unrendered", string(result))
+}
+
+func TestExtractRstBody(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("<p>Hello</p>", string(extractRstBody([]byte("<html>\n<body>\n<p>Hello</p>\n</body>\n</html>"))))
+	assert.Equal("", string(extractRstBody(nil)))
+}
+
+func TestExternalHelpers(t *testing.T) {
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("markup", map[string]interface{}{
+		"pandoc": map[string]interface{}{"binary": "hugo-does-not-exist"},
+	})
+
+	helpers, err := ExternalHelpers(v)
+	assert.NoError(err)
+	assert.Len(helpers, 3)
+	assert.Equal("asciidoc", helpers[0].Format)
+	assert.Equal("Pandoc", helpers[1].Name)
+	assert.Equal("", helpers[1].Path)
+	assert.Equal("rst", helpers[2].Format)
+}
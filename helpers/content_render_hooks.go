@@ -0,0 +1,235 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday"
+)
+
+// RenderHooks renders Markdown elements with the templates in
+// layouts/_markup, e.g. render-table.html. The methods return false if the
+// default rendering should be used.
+type RenderHooks interface {
+	RenderTable(ctx *TableContext) ([]byte, bool)
+	RenderBlockquote(ctx *BlockquoteContext) ([]byte, bool)
+}
+
+// TableCell is a cell in a Markdown table.
+type TableCell struct {
+	// The rendered cell content.
+	Text template.HTML
+
+	// One of left, right, center or empty.
+	Alignment string
+}
+
+// TableContext is the context passed to the table render hook.
+type TableContext struct {
+	// The page being rendered.
+	Page interface{}
+
+	// The header and body rows.
+	THead [][]TableCell
+	TBody [][]TableCell
+
+	// The attributes set with an attribute list after the table, e.g.
+	// {.striped}.
+	Attributes map[string]string
+
+	// The zero based index of the table in the page content.
+	Ordinal int
+}
+
+// The blockquote types.
+const (
+	BlockquoteRegular = "regular"
+	BlockquoteAlert   = "alert"
+)
+
+// BlockquoteContext is the context passed to the blockquote render hook.
+type BlockquoteContext struct {
+	// The page being rendered.
+	Page interface{}
+
+	// Either regular or alert, see AlertType.
+	Type string
+
+	// The lower case alert type for GitHub style alerts, e.g. note for a
+	// blockquote starting with [!NOTE].
+	AlertType string
+
+	// The rendered blockquote content, without the alert marker.
+	Text template.HTML
+
+	// The attributes set with an attribute list after the blockquote.
+	Attributes map[string]string
+
+	// The zero based index of the blockquote in the page content.
+	Ordinal int
+}
+
+// tableState collects the cells of the table being rendered. Blackfriday
+// renders the cells and rows before the table itself.
+type tableState struct {
+	row    []TableCell
+	header bool
+	thead  [][]TableCell
+	tbody  [][]TableCell
+}
+
+func (t *tableState) addCell(text []byte, align int, header bool) {
+	t.row = append(t.row, TableCell{Text: template.HTML(text), Alignment: tableAlignment(align)})
+	t.header = header
+}
+
+func (t *tableState) addRow() {
+	if t.header {
+		t.thead = append(t.thead, t.row)
+	} else {
+		t.tbody = append(t.tbody, t.row)
+	}
+	t.row = nil
+	t.header = false
+}
+
+func tableAlignment(align int) string {
+	switch align {
+	case blackfriday.TABLE_ALIGNMENT_LEFT:
+		return "left"
+	case blackfriday.TABLE_ALIGNMENT_RIGHT:
+		return "right"
+	case blackfriday.TABLE_ALIGNMENT_CENTER:
+		return "center"
+	}
+	return ""
+}
+
+// alertRe matches the marker of a GitHub style alert at the start of the
+// rendered blockquote, e.g. <p>[!NOTE].
+var alertRe = regexp.MustCompile(`^\s*<p>\[!([A-Za-z]+)\][ \t]*(\n|</p>\s*)?`)
+
+// parseAlert returns the alert type and the blockquote content without the
+// alert marker, or an empty alert type if this is a regular blockquote.
+func parseAlert(text []byte) (string, []byte) {
+	m := alertRe.FindSubmatchIndex(text)
+	if m == nil {
+		return "", text
+	}
+
+	alertType := strings.ToLower(string(text[m[2]:m[3]]))
+	rest := text[m[1]:]
+
+	if m[4] == -1 || text[m[4]] == '\n' {
+		// The paragraph continues after the marker.
+		rest = append([]byte("<p>"), rest...)
+	}
+
+	return alertType, bytes.TrimSpace(rest)
+}
+
+// TableHeaderCell collects the header cell for the table render hook.
+func (r *HugoHTMLRenderer) TableHeaderCell(out *bytes.Buffer, text []byte, align int) {
+	if r.RenderHooks != nil {
+		r.table.addCell(text, align, true)
+	}
+	r.Renderer.TableHeaderCell(out, text, align)
+}
+
+// TableCell collects the cell for the table render hook.
+func (r *HugoHTMLRenderer) TableCell(out *bytes.Buffer, text []byte, align int) {
+	if r.RenderHooks != nil {
+		r.table.addCell(text, align, false)
+	}
+	r.Renderer.TableCell(out, text, align)
+}
+
+// TableRow collects the row for the table render hook.
+func (r *HugoHTMLRenderer) TableRow(out *bytes.Buffer, text []byte) {
+	if r.RenderHooks != nil {
+		r.table.addRow()
+	}
+	r.Renderer.TableRow(out, text)
+}
+
+// Table renders the table with the table render hook, if any.
+func (r *HugoHTMLRenderer) Table(out *bytes.Buffer, header []byte, body []byte, columnData []int) {
+	var ctx *TableContext
+	if r.RenderHooks != nil {
+		ctx = &TableContext{THead: r.table.thead, TBody: r.table.tbody, Ordinal: r.tableOrdinal}
+		r.table = tableState{}
+		r.tableOrdinal++
+	}
+
+	render := func(attrs map[string]string) []byte {
+		if ctx != nil {
+			ctx.Attributes = attrs
+			if b, ok := r.RenderHooks.RenderTable(ctx); ok {
+				return append(b, '\n')
+			}
+		}
+		var b bytes.Buffer
+		r.Renderer.Table(&b, header, body, columnData)
+		return addAttributes(b.Bytes(), attrs)
+	}
+
+	r.writeBlock(out, render)
+}
+
+// BlockQuote renders the blockquote with the blockquote render hook, if any.
+func (r *HugoHTMLRenderer) BlockQuote(out *bytes.Buffer, text []byte) {
+	var ctx *BlockquoteContext
+	if r.RenderHooks != nil {
+		ctx = &BlockquoteContext{Type: BlockquoteRegular, Ordinal: r.blockquoteOrdinal}
+		r.blockquoteOrdinal++
+
+		alertType, inner := parseAlert(text)
+		if alertType != "" {
+			ctx.Type = BlockquoteAlert
+			ctx.AlertType = alertType
+			ctx.Text = template.HTML(inner)
+		} else {
+			ctx.Text = template.HTML(bytes.TrimSpace(text))
+		}
+	}
+
+	render := func(attrs map[string]string) []byte {
+		if ctx != nil {
+			ctx.Attributes = attrs
+			if b, ok := r.RenderHooks.RenderBlockquote(ctx); ok {
+				return append(b, '\n')
+			}
+		}
+		var b bytes.Buffer
+		r.Renderer.BlockQuote(&b, text)
+		return addAttributes(b.Bytes(), attrs)
+	}
+
+	r.writeBlock(out, render)
+}
+
+// writeBlock writes the block created by render and records it for a
+// following attribute list.
+func (r *HugoHTMLRenderer) writeBlock(out *bytes.Buffer, render func(attrs map[string]string) []byte) {
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	start := out.Len()
+	out.Write(render(nil))
+	r.setAttributeTarget(out, start, render)
+}
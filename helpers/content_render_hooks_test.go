@@ -0,0 +1,107 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRenderHooks struct {
+	tables      []*TableContext
+	blockquotes []*BlockquoteContext
+}
+
+func (h *testRenderHooks) RenderTable(ctx *TableContext) ([]byte, bool) {
+	h.tables = append(h.tables, ctx)
+	return []byte(fmt.Sprintf("<div class=\"table\">%d</div>", ctx.Ordinal)), true
+}
+
+func (h *testRenderHooks) RenderBlockquote(ctx *BlockquoteContext) ([]byte, bool) {
+	h.blockquotes = append(h.blockquotes, ctx)
+	if ctx.Type == BlockquoteRegular {
+		return nil, false
+	}
+	return []byte(fmt.Sprintf("<div class=\"%s\">%s</div>", ctx.AlertType, ctx.Text)), true
+}
+
+func TestRenderHooks(t *testing.T) {
+	assert := require.New(t)
+
+	c := newTestContentSpec()
+	hooks := &testRenderHooks{}
+	ctx := &RenderingContext{Cfg: c.cfg, Config: c.BlackFriday, RenderHooks: hooks}
+	ctx.Content = []byte(`
+| Left | Center | Right | None |
+|:-----|:------:|------:|------|
+| a    | *b*    | c     | d    |
+| e    | f      | g     | h    |
+
+> Just a quote.
+
+Some text.
+
+> [!NOTE]
+> Useful information.
+
+More text.
+
+> [!Warning] Be careful.
+`)
+
+	out := string(c.markdownRender(ctx))
+
+	assert.Contains(out, `<div class="table">0</div>`)
+	assert.NotContains(out, "<table>")
+	assert.Contains(out, "<blockquote>\n<p>Just a quote.</p>\n</blockquote>")
+	assert.Contains(out, "<div class=\"note\"><p>Useful information.</p></div>")
+	assert.Contains(out, "<div class=\"warning\"><p>Be careful.</p></div>")
+
+	assert.Len(hooks.tables, 1)
+	table := hooks.tables[0]
+	assert.Len(table.THead, 1)
+	assert.Len(table.TBody, 2)
+	assert.Equal([]TableCell{{"Left", "left"}, {"Center", "center"}, {"Right", "right"}, {"None", ""}}, table.THead[0])
+	assert.Equal(TableCell{"<em>b</em>", "center"}, table.TBody[0][1])
+	assert.Equal(TableCell{"h", ""}, table.TBody[1][3])
+
+	assert.Len(hooks.blockquotes, 3)
+	assert.Equal(BlockquoteRegular, hooks.blockquotes[0].Type)
+	assert.Equal(0, hooks.blockquotes[0].Ordinal)
+	assert.Equal(BlockquoteAlert, hooks.blockquotes[1].Type)
+	assert.Equal("note", hooks.blockquotes[1].AlertType)
+	assert.Equal(2, hooks.blockquotes[2].Ordinal)
+}
+
+func TestParseAlert(t *testing.T) {
+	assert := require.New(t)
+
+	for i, test := range []struct {
+		in        string
+		alertType string
+		text      string
+	}{
+		{"<p>Quote</p>\n", "", "<p>Quote</p>\n"},
+		{"<p>[!NOTE]\nText</p>\n", "note", "<p>Text</p>"},
+		{"<p>[!TIP] Text</p>\n", "tip", "<p>Text</p>"},
+		{"<p>[!CAUTION]</p>\n\n<p>Text</p>\n", "caution", "<p>Text</p>"},
+		{"<p>[NOTE] Text</p>\n", "", "<p>[NOTE] Text</p>\n"},
+	} {
+		alertType, text := parseAlert([]byte(test.in))
+		assert.Equal(test.alertType, alertType, fmt.Sprintf("[%d]", i))
+		assert.Equal(test.text, string(text), fmt.Sprintf("[%d]", i))
+	}
+}
@@ -29,11 +29,22 @@ type HugoHTMLRenderer struct {
 	cs *ContentSpec
 	*RenderingContext
 	blackfriday.Renderer
+
+	// State for the render hooks.
+	table             tableState
+	tableOrdinal      int
+	blockquoteOrdinal int
+
+	// State for the attribute lists.
+	attributeTarget attributeTarget
+	image           attributeTarget
 }
 
 // BlockCode renders a given text as a block of code.
 // Pygments is used if it is setup to handle code fences.
 func (r *HugoHTMLRenderer) BlockCode(out *bytes.Buffer, text []byte, lang string) {
+	defer r.setAttributeTarget(out, out.Len(), nil)
+
 	if r.Cfg.GetBool("pygmentsCodeFences") && (lang != "" || r.Cfg.GetBool("pygmentsCodeFencesGuessSyntax")) {
 		opts := r.Cfg.GetString("pygmentsOptions")
 		str := strings.Trim(html.UnescapeString(string(text)), "\n\r")
@@ -66,6 +77,8 @@ func (r *HugoHTMLRenderer) ListItem(out *bytes.Buffer, text []byte, flags int) {
 
 // List adds task list support to the Blackfriday renderer.
 func (r *HugoHTMLRenderer) List(out *bytes.Buffer, text func() bool, flags int) {
+	defer r.setAttributeTarget(out, out.Len(), nil)
+
 	if !r.Config.TaskLists {
 		r.Renderer.List(out, text, flags)
 		return
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"testing"
 
+	jww "github.com/spf13/jwalterweatherman"
 	"github.com/spf13/viper"
 
 	"github.com/miekg/mmark"
@@ -490,3 +491,20 @@ func BenchmarkTotalWordsOld(b *testing.B) {
 		}
 	}
 }
+
+func TestContentSpecLogWarnings(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := NewContentSpec(viper.New())
+	assert.NoError(err)
+
+	var b bytes.Buffer
+	logger := jww.NewNotepad(jww.LevelError, jww.LevelWarn, &b, &b, "", 0)
+
+	c.LogWarnings(logger)
+	assert.Empty(b.String())
+
+	c.pygmentsMissing = true
+	c.LogWarnings(logger)
+	assert.Contains(b.String(), "WARN [warning-pygments-missing] Highlighting with pygmentsUseClassic")
+}
@@ -72,7 +72,7 @@ func InStringArray(arr []string, el string) bool {
 // GuessType attempts to guess the type of file from a given string.
 func GuessType(in string) string {
 	switch strings.ToLower(in) {
-	case "md", "markdown", "mdown":
+	case "md", "markdown", "mdown", "blackfriday":
 		return "markdown"
 	case "asciidoc", "adoc", "ad":
 		return "asciidoc"
@@ -272,6 +272,12 @@ type DistinctLogger struct {
 	m      map[string]bool
 }
 
+// NewDistinctLogger creates a new DistinctLogger logging to the given logger,
+// e.g. a Notepad's WARN logger.
+func NewDistinctLogger(logger logPrinter) *DistinctLogger {
+	return &DistinctLogger{m: make(map[string]bool), logger: logger}
+}
+
 // Println will log the string returned from fmt.Sprintln given the arguments,
 // but not if it has been logged before.
 func (l *DistinctLogger) Println(v ...interface{}) {
@@ -288,6 +294,17 @@ func (l *DistinctLogger) Printf(format string, v ...interface{}) {
 	l.print(logStatement)
 }
 
+// Printidf logs the string returned from fmt.Sprintf given the arguments,
+// prefixed with the given stable ID, e.g. "[warning-data-override] ...".
+// Nothing is logged if the statement has been logged before, or if the ID is
+// set in the ignoreLogs config to acknowledge a known warning.
+func (l *DistinctLogger) Printidf(id, format string, v ...interface{}) {
+	if IsLogIgnored(id) {
+		return
+	}
+	l.print(fmt.Sprintf("[%s] %s", id, fmt.Sprintf(format, v...)))
+}
+
 func (l *DistinctLogger) print(logStatement string) {
 	l.RLock()
 	if l.m[logStatement] {
@@ -320,6 +337,39 @@ func NewDistinctFeedbackLogger() *DistinctLogger {
 	return &DistinctLogger{m: make(map[string]bool), logger: jww.FEEDBACK}
 }
 
+var (
+	ignoredLogsMu sync.RWMutex
+	ignoredLogs   map[string]bool
+)
+
+// IsLogIgnored returns whether the log statements with the given ID are set
+// to be ignored in the ignoreLogs config.
+func IsLogIgnored(id string) bool {
+	ignoredLogsMu.RLock()
+	defer ignoredLogsMu.RUnlock()
+	return ignoredLogs[strings.ToLower(id)]
+}
+
+var (
+	distinctWarnLogsMu sync.Mutex
+	distinctWarnLogs   = make(map[*jww.Notepad]*DistinctLogger)
+)
+
+// Warnidf logs a warning with the given stable ID to the WARN logger of the
+// given Notepad, usually the site's, once, unless the ID is set in the
+// ignoreLogs config. See DistinctLogger.Printidf.
+func Warnidf(logger *jww.Notepad, id, format string, v ...interface{}) {
+	distinctWarnLogsMu.Lock()
+	l, found := distinctWarnLogs[logger]
+	if !found {
+		l = NewDistinctLogger(logger.WARN)
+		distinctWarnLogs[logger] = l
+	}
+	distinctWarnLogsMu.Unlock()
+
+	l.Printidf(id, format, v...)
+}
+
 var (
 	// DistinctErrorLog can be used to avoid spamming the logs with errors.
 	DistinctErrorLog = NewDistinctErrorLogger()
@@ -331,8 +381,21 @@ var (
 	DistinctFeedbackLog = NewDistinctFeedbackLogger()
 )
 
-// InitLoggers sets up the global distinct loggers.
-func InitLoggers() {
+// InitLoggers sets up the global distinct loggers. The log statements with
+// the IDs in ignoreLogs, usually from the site config, are not logged.
+func InitLoggers(ignoreLogs ...string) {
+	ignored := make(map[string]bool)
+	for _, id := range ignoreLogs {
+		ignored[strings.ToLower(id)] = true
+	}
+	ignoredLogsMu.Lock()
+	ignoredLogs = ignored
+	ignoredLogsMu.Unlock()
+
+	distinctWarnLogsMu.Lock()
+	distinctWarnLogs = make(map[*jww.Notepad]*DistinctLogger)
+	distinctWarnLogsMu.Unlock()
+
 	DistinctErrorLog = NewDistinctErrorLogger()
 	DistinctWarnLog = NewDistinctWarnLogger()
 	DistinctFeedbackLog = NewDistinctFeedbackLogger()
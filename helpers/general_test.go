@@ -14,12 +14,14 @@
 package helpers
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,6 +34,7 @@ func TestGuessType(t *testing.T) {
 		{"md", "markdown"},
 		{"markdown", "markdown"},
 		{"mdown", "markdown"},
+		{"blackfriday", "markdown"},
 		{"asciidoc", "asciidoc"},
 		{"adoc", "asciidoc"},
 		{"ad", "asciidoc"},
@@ -213,6 +216,51 @@ func TestUniqueStrings(t *testing.T) {
 	}
 }
 
+type recordingPrinter struct {
+	lines []string
+}
+
+func (p *recordingPrinter) Println(a ...interface{}) {
+	p.lines = append(p.lines, fmt.Sprint(a...))
+}
+
+func TestDistinctLoggerPrintidf(t *testing.T) {
+	defer InitLoggers()
+	InitLoggers("warning-Ignored")
+
+	assert := require.New(t)
+	printer := &recordingPrinter{}
+	logger := NewDistinctLogger(printer)
+
+	logger.Printidf("warning-a", "page %q", "p1")
+	logger.Printidf("warning-a", "page %q", "p1")
+	logger.Printidf("warning-a", "page %q", "p2")
+	logger.Printidf("warning-ignored", "page %q", "p1")
+
+	assert.Equal([]string{`[warning-a] page "p1"`, `[warning-a] page "p2"`}, printer.lines)
+	assert.True(IsLogIgnored("WARNING-IGNORED"))
+	assert.False(IsLogIgnored("warning-a"))
+}
+
+func TestWarnidf(t *testing.T) {
+	defer InitLoggers()
+	InitLoggers("warning-ignored")
+
+	assert := require.New(t)
+
+	var b1, b2 bytes.Buffer
+	logger1 := jww.NewNotepad(jww.LevelError, jww.LevelWarn, &b1, &b1, "", 0)
+	logger2 := jww.NewNotepad(jww.LevelError, jww.LevelWarn, &b2, &b2, "", 0)
+
+	Warnidf(logger1, "warning-a", "page %q", "p1")
+	Warnidf(logger1, "warning-a", "page %q", "p1")
+	Warnidf(logger1, "warning-ignored", "page %q", "p1")
+	Warnidf(logger2, "warning-a", "page %q", "p1")
+
+	assert.Equal("WARN [warning-a] page \"p1\"\n", b1.String())
+	assert.Equal("WARN [warning-a] page \"p1\"\n", b2.String())
+}
+
 func TestFindAvailablePort(t *testing.T) {
 	addr, err := FindAvailablePort()
 	assert.Nil(t, err)
@@ -0,0 +1,115 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	globCacheMu sync.RWMutex
+	globCache   = make(map[string]*regexp.Regexp)
+)
+
+// GlobRegexp returns the compiled regexp for the glob pattern, or nil if the
+// pattern is invalid. In addition to the path.Match syntax, "**" matches
+// across directories and "{a,b}" matches any of the alternatives.
+func GlobRegexp(pattern string) *regexp.Regexp {
+	globCacheMu.RLock()
+	re, found := globCache[pattern]
+	globCacheMu.RUnlock()
+	if found {
+		return re
+	}
+
+	re, _ = regexp.Compile(globToRegexp(pattern))
+
+	globCacheMu.Lock()
+	globCache[pattern] = re
+	globCacheMu.Unlock()
+
+	return re
+}
+
+func globToRegexp(pattern string) string {
+	var (
+		b       bytes.Buffer
+		inGroup bool
+	)
+
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories.
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(pattern[i:], ']')
+			if j == -1 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j
+		case '{':
+			inGroup = true
+			b.WriteString("(?:")
+		case '}':
+			if inGroup {
+				inGroup = false
+				b.WriteString(")")
+			} else {
+				b.WriteString(`\}`)
+			}
+		case ',':
+			if inGroup {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return b.String()
+}
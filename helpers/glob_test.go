@@ -0,0 +1,47 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	assert := require.New(t)
+
+	for i, test := range []struct {
+		pattern string
+		path    string
+		expect  bool
+	}{
+		{"*.md", "a.md", true},
+		{"*.md", "posts/a.md", false},
+		{"posts/**", "posts/a.md", true},
+		{"posts/**", "posts/2018/a.md", true},
+		{"posts/**", "blog/a.md", false},
+		{"**/index.md", "index.md", true},
+		{"**/index.md", "a/b/index.md", true},
+		{"{posts,blog}/*.md", "blog/a.md", true},
+		{"{posts,blog}/*.md", "docs/a.md", false},
+		{"a?.md", "ab.md", true},
+		{"[!a]*.md", "b.md", true},
+		{"[!a]*.md", "a.md", false},
+	} {
+		re := GlobRegexp(test.pattern)
+		assert.NotNil(re, "[%d]", i)
+		assert.Equal(test.expect, re.MatchString(test.path), "[%d] %s %s", i, test.pattern, test.path)
+	}
+}
@@ -20,6 +20,8 @@ import (
 
 	"github.com/gohugoio/hugo/config"
 	"github.com/spf13/cast"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 // These are the settings that should only be looked up in the global Viper
@@ -121,6 +123,15 @@ func (l *Language) SetParam(k string, v interface{}) {
 	l.params[strings.ToLower(k)] = v
 }
 
+// SetParams replaces the params, e.g. to drop the params merged from the
+// global config. The keys are made lower case.
+func (l *Language) SetParams(params map[string]interface{}) {
+	l.params = make(map[string]interface{}, len(params))
+	for k, v := range params {
+		l.params[strings.ToLower(k)] = v
+	}
+}
+
 // GetBool returns the value associated with the key as a boolean.
 func (l *Language) GetBool(key string) bool { return cast.ToBool(l.Get(key)) }
 
@@ -190,3 +201,37 @@ func (l *Language) IsSet(key string) bool {
 	return l.Cfg.IsSet(key)
 
 }
+
+// NewCollator returns a new collator to sort strings by the rules of the
+// language's collation config, e.g. "zh-u-co-stroke" to sort Chinese by
+// stroke count, or its language code. It returns nil, i.e. byte order, if
+// collation is set to "none".
+//
+// The collator is not safe for concurrent use.
+func (l *Language) NewCollator(options ...collate.Option) *collate.Collator {
+	name := l.GetString("collation")
+	if name == "" {
+		name = l.Lang
+	}
+	if strings.ToLower(name) == "none" {
+		return nil
+	}
+
+	tag, err := language.Parse(name)
+	if err != nil {
+		tag = language.Und
+	}
+
+	return collate.New(tag, options...)
+}
+
+// CompareStrings compares a and b with the collator, nil for byte order.
+// Strings equal by the collator's rules are compared by byte order.
+func CompareStrings(c *collate.Collator, a, b string) int {
+	if c != nil {
+		if r := c.CompareString(a, b); r != 0 {
+			return r
+		}
+	}
+	return strings.Compare(a, b)
+}
@@ -14,6 +14,7 @@
 package helpers
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -31,3 +32,25 @@ func TestGetGlobalOnlySetting(t *testing.T) {
 	require.True(t, lang.GetBool("defaultContentLanguageInSubdir"))
 	require.Equal(t, "side", lang.GetString("paginatePath"))
 }
+
+func TestNewCollator(t *testing.T) {
+	assert := require.New(t)
+
+	sorted := func(lang, collation string, s ...string) []string {
+		v := viper.New()
+		l := NewLanguage(lang, v)
+		if collation != "" {
+			l.SetParam("collation", collation)
+		}
+		c := l.NewCollator()
+		sort.SliceStable(s, func(i, j int) bool { return CompareStrings(c, s[i], s[j]) < 0 })
+		return s
+	}
+
+	assert.Equal([]string{"apple", "Äpple", "Banana", "Öl", "Zebra"}, sorted("en", "", "Zebra", "Öl", "apple", "Banana", "Äpple"))
+	assert.Equal([]string{"apple", "Banana", "Zebra", "Äpple", "Öl"}, sorted("sv", "", "Zebra", "Öl", "apple", "Banana", "Äpple"))
+	assert.Equal([]string{"Banana", "Zebra", "apple", "Äpple", "Öl"}, sorted("sv", "none", "Zebra", "Öl", "apple", "Banana", "Äpple"))
+	assert.Equal([]string{"阿", "北京", "中国"}, sorted("zh", "", "中国", "阿", "北京"))
+	assert.Equal([]string{"中国", "北京", "阿"}, sorted("zh", "zh-u-co-stroke", "中国", "阿", "北京"))
+	assert.Equal([]string{"a", "A"}, sorted("en", "", "A", "a"))
+}
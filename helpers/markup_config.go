@@ -0,0 +1,170 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MarkupConfig holds the markup configuration and the features supported
+// for each markup format, available in the templates as .Site.Config.Markup.
+type MarkupConfig struct {
+	// The markup used for Markdown files without a markup set in front matter,
+	// either "markdown" (Blackfriday) or "mmark".
+	DefaultMarkdownHandler string
+
+	// The features supported for each markup format, keyed by format,
+	// e.g. "asciidoc".
+	Formats map[string]MarkupCapabilities
+}
+
+// MarkupCapabilities describes what the converter for a markup format supports.
+type MarkupCapabilities struct {
+	// The markup format, e.g. "markdown".
+	Format string
+
+	// The converter used, e.g. "blackfriday" or "asciidoctor". Empty if an
+	// external helper is needed but not installed.
+	Converter string
+
+	// Whether content in this format can be rendered. This is false when an
+	// external helper is needed but not installed.
+	Available bool
+
+	// Whether an external helper is used.
+	External bool
+
+	Footnotes       bool
+	Tables          bool
+	TaskLists       bool
+	DefinitionLists bool
+
+	// Whether .TableOfContents is set for pages in this format.
+	TableOfContents bool
+
+	// The summary divider in this format.
+	SummaryDivider string
+}
+
+// Supports returns whether the given feature is supported, one of
+// "footnotes", "tables", "tasklists", "definitionlists" or "toc".
+func (m MarkupCapabilities) Supports(feature string) (bool, error) {
+	switch strings.ToLower(feature) {
+	case "footnotes":
+		return m.Footnotes, nil
+	case "tables":
+		return m.Tables, nil
+	case "tasklists":
+		return m.TaskLists, nil
+	case "definitionlists":
+		return m.DefinitionLists, nil
+	case "toc", "tableofcontents":
+		return m.TableOfContents, nil
+	}
+	return false, fmt.Errorf("unknown markup feature %q", feature)
+}
+
+func newMarkupConfig(c *ContentSpec) (MarkupConfig, error) {
+	handler := GuessType(c.cfg.GetString("markup.defaultMarkdownHandler"))
+	switch handler {
+	case "unknown":
+		handler = "markdown"
+	case "markdown", "mmark":
+	default:
+		return MarkupConfig{}, fmt.Errorf("invalid defaultMarkdownHandler %q, must be one of blackfriday or mmark", c.cfg.GetString("markup.defaultMarkdownHandler"))
+	}
+
+	const divider = "<!--more-->"
+
+	formats := map[string]MarkupCapabilities{
+		"markdown": {
+			Converter:       "blackfriday",
+			Footnotes:       true,
+			Tables:          true,
+			TaskLists:       c.BlackFriday.TaskLists,
+			DefinitionLists: true,
+			TableOfContents: true,
+			SummaryDivider:  divider,
+		},
+		"mmark": {
+			Converter:       "mmark",
+			Footnotes:       true,
+			Tables:          true,
+			DefinitionLists: true,
+			SummaryDivider:  divider,
+		},
+		"org": {
+			Converter:       "goorgeous",
+			Footnotes:       true,
+			Tables:          true,
+			DefinitionLists: true,
+			SummaryDivider:  "# more",
+		},
+		"asciidoc": {
+			External:        true,
+			Footnotes:       true,
+			Tables:          true,
+			TaskLists:       true,
+			DefinitionLists: true,
+			SummaryDivider:  divider,
+		},
+		"rst": {
+			External:        true,
+			Footnotes:       true,
+			Tables:          true,
+			DefinitionLists: true,
+			SummaryDivider:  divider,
+		},
+		"pandoc": {
+			External:        true,
+			Footnotes:       true,
+			Tables:          true,
+			DefinitionLists: true,
+			SummaryDivider:  divider,
+		},
+		"html": {
+			Converter:      "none",
+			SummaryDivider: divider,
+		},
+	}
+
+	for format, m := range formats {
+		m.Format = format
+		if m.External {
+			if conv, ok := c.converters[format].(*externalConverter); ok && conv.Available() {
+				m.Available = true
+				m.Converter = externalConverterName(conv)
+			}
+		} else {
+			m.Available = true
+		}
+		formats[format] = m
+	}
+
+	return MarkupConfig{DefaultMarkdownHandler: handler, Formats: formats}, nil
+}
+
+// externalConverterName returns the name of the external helper used, e.g.
+// "asciidoctor".
+func externalConverterName(c *externalConverter) string {
+	path := c.path
+	if len(c.args) > 0 && strings.Contains(filepath.Base(c.args[0]), "rst2html") {
+		// Run through Python.
+		path = c.args[0]
+	}
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
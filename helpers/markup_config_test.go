@@ -0,0 +1,67 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkupConfig(t *testing.T) {
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("blackfriday", map[string]interface{}{"taskLists": false})
+
+	spec, err := NewContentSpec(v)
+	assert.NoError(err)
+
+	m := spec.Markup
+	assert.Equal("markdown", m.DefaultMarkdownHandler)
+	assert.Len(m.Formats, 7)
+
+	md := m.Formats["markdown"]
+	assert.Equal("markdown", md.Format)
+	assert.Equal("blackfriday", md.Converter)
+	assert.True(md.Available)
+	assert.False(md.External)
+	assert.False(md.TaskLists)
+	assert.True(md.TableOfContents)
+
+	assert.Equal("# more", m.Formats["org"].SummaryDivider)
+
+	ad := m.Formats["asciidoc"]
+	assert.True(ad.External)
+	assert.Equal(HasAsciidoc(), ad.Available)
+	if !ad.Available {
+		assert.Empty(ad.Converter)
+	}
+
+	supported, err := md.Supports("Footnotes")
+	assert.NoError(err)
+	assert.True(supported)
+	_, err = md.Supports("emoji")
+	assert.Error(err)
+
+	v.Set("markup", map[string]interface{}{"defaultMarkdownHandler": "mmark"})
+	spec, err = NewContentSpec(v)
+	assert.NoError(err)
+	assert.Equal("mmark", spec.Markup.DefaultMarkdownHandler)
+
+	v.Set("markup", map[string]interface{}{"defaultMarkdownHandler": "rst"})
+	_, err = NewContentSpec(v)
+	assert.Error(err)
+}
@@ -130,9 +130,7 @@ func (p *PathSpec) UnicodeSanitize(s string) string {
 	var result string
 
 	if p.removePathAccents {
-		// remove accents - see https://blog.golang.org/normalization
-		t := transform.Chain(norm.NFD, transform.RemoveFunc(isMn), norm.NFC)
-		result, _, _ = transform.String(t, string(target))
+		result = RemoveAccents(string(target))
 	} else {
 		result = string(target)
 	}
@@ -140,6 +138,15 @@ func (p *PathSpec) UnicodeSanitize(s string) string {
 	return result
 }
 
+// RemoveAccents removes the accents from the letters in s, e.g. "é" becomes
+// "e".
+func RemoveAccents(s string) string {
+	// See https://blog.golang.org/normalization
+	t := transform.Chain(norm.NFD, transform.RemoveFunc(isMn), norm.NFC)
+	result, _, _ := transform.String(t, s)
+	return result
+}
+
 func isMn(r rune) bool {
 	return unicode.Is(unicode.Mn, r) // Mn: nonspacing marks
 }
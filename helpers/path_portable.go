@@ -0,0 +1,130 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+)
+
+// WindowsMaxPath is the maximum length of a path on Windows without long
+// path support, including the terminating NUL character.
+const WindowsMaxPath = 260
+
+var windowsReservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// IsWindowsReservedName returns whether name can not be used as a file or
+// directory name on Windows, e.g. "con", "aux.html" or "file.".
+func IsWindowsReservedName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return true
+	}
+
+	base := name
+	if i := strings.Index(base, "."); i != -1 {
+		base = base[:i]
+	}
+
+	return windowsReservedNames[strings.ToLower(strings.TrimRight(base, " "))]
+}
+
+// CheckWindowsPath returns an error if the file at the absolute filename
+// can not be written on Windows, i.e. if it contains a reserved name or is
+// too long.
+func CheckWindowsPath(filename string) error {
+	for _, name := range strings.Split(filepath.ToSlash(filename), "/") {
+		if IsWindowsReservedName(name) {
+			return fmt.Errorf("%q is a reserved name on Windows", name)
+		}
+	}
+
+	if len(filename) >= WindowsMaxPath {
+		return fmt.Errorf("the path is %d characters, longer than Windows allows", len(filename))
+	}
+
+	return nil
+}
+
+// MaxWindowsPathIn returns the maximum length of a path relative to the
+// absolute dir, so the file can be written on Windows, see CheckWindowsPath.
+func MaxWindowsPathIn(dir string) int {
+	return WindowsMaxPath - 2 - len(strings.TrimSuffix(dir, FilePathSeparator))
+}
+
+// MakeWindowsSafePath mangles the path elements that are reserved names
+// on Windows by appending an underscore to the base name, e.g. "con/aux.html"
+// becomes "con_/aux_.html". If the path is still longer than maxLen, the
+// longest directory name is shortened and given a hash of its original name
+// to keep it unique.
+func MakeWindowsSafePath(path string, maxLen int) string {
+	elements := strings.Split(path, FilePathSeparator)
+
+	for i, name := range elements {
+		if !IsWindowsReservedName(name) {
+			continue
+		}
+		name = strings.TrimRight(name, ". ")
+		if j := strings.Index(name, "."); j != -1 {
+			elements[i] = name[:j] + "_" + name[j:]
+		} else {
+			elements[i] = name + "_"
+		}
+	}
+
+	path = strings.Join(elements, FilePathSeparator)
+
+	if maxLen <= 0 || len(path) <= maxLen {
+		return path
+	}
+
+	longest := -1
+	for i, name := range elements[:len(elements)-1] {
+		if longest == -1 || len(name) > len(elements[longest]) {
+			longest = i
+		}
+	}
+
+	if longest == -1 {
+		return path
+	}
+
+	name := elements[longest]
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("-%x", h.Sum32())
+
+	keep := len(name) - (len(path) - maxLen) - len(suffix)
+	if keep < 1 {
+		return path
+	}
+
+	// Do not cut a multibyte character in half.
+	for keep > 0 && keep < len(name) && name[keep]&0xC0 == 0x80 {
+		keep--
+	}
+
+	elements[longest] = name[:keep] + suffix
+
+	return strings.Join(elements, FilePathSeparator)
+}
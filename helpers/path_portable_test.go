@@ -0,0 +1,60 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWindowsReservedName(t *testing.T) {
+	assert := require.New(t)
+
+	for _, name := range []string{"con", "CON", "aux.html", "Com1.txt", "lpt9", "nul.tar.gz", "file.", "file "} {
+		assert.True(IsWindowsReservedName(name), name)
+	}
+
+	for _, name := range []string{"", ".", "..", "console", "com10", "index.html", "auxiliary.html", "contact"} {
+		assert.False(IsWindowsReservedName(name), name)
+	}
+}
+
+func TestCheckWindowsPath(t *testing.T) {
+	assert := require.New(t)
+
+	assert.NoError(CheckWindowsPath(filepath.FromSlash("/public/contact/index.html")))
+	assert.Error(CheckWindowsPath(filepath.FromSlash("/public/con/index.html")))
+	assert.Error(CheckWindowsPath(filepath.FromSlash("/public/" + strings.Repeat("a", WindowsMaxPath) + "/index.html")))
+}
+
+func TestMakeWindowsSafePath(t *testing.T) {
+	assert := require.New(t)
+
+	fp := filepath.FromSlash
+
+	assert.Equal(fp("/contact/index.html"), MakeWindowsSafePath(fp("/contact/index.html"), 0))
+	assert.Equal(fp("/con_/index.html"), MakeWindowsSafePath(fp("/con/index.html"), 0))
+	assert.Equal(fp("/blog/aux_.html"), MakeWindowsSafePath(fp("/blog/aux.html"), 0))
+	assert.Equal(fp("/blog/file_/index.html"), MakeWindowsSafePath(fp("/blog/file./index.html"), 0))
+
+	long := strings.Repeat("a", 100)
+	p := MakeWindowsSafePath(fp("/blog/"+long+"/index.html"), 60)
+	assert.Len(p, 60)
+	assert.True(strings.HasPrefix(p, fp("/blog/aaaa")))
+	assert.True(strings.HasSuffix(p, fp("/index.html")))
+	assert.NotEqual(p, MakeWindowsSafePath(fp("/blog/"+long+"b/index.html"), 60))
+}
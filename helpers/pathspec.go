@@ -35,6 +35,10 @@ type PathSpec struct {
 	uglyURLs           bool
 	canonifyURLs       bool
 
+	// How to handle paths that are not portable to Windows, one of warn,
+	// mangle or ignore.
+	windowsPaths string
+
 	Language  *Language
 	Languages Languages
 
@@ -125,6 +129,7 @@ func NewPathSpec(fs *hugofs.Fs, cfg config.Provider) (*PathSpec, error) {
 		workingDir:                     cfg.GetString("workingDir"),
 		staticDirs:                     staticDirs,
 		theme:                          cfg.GetString("theme"),
+		windowsPaths:                   strings.ToLower(cfg.GetString("windowsPaths")),
 		ProcessingStats:                NewProcessingStats(lang),
 	}
 
@@ -165,6 +170,29 @@ func getStringOrStringSlice(cfg config.Provider, key string, id int) []string {
 	return out
 }
 
+// MangleWindowsPaths returns whether the page paths not portable to Windows
+// should be mangled, see MakeWindowsSafePath.
+func (p *PathSpec) MangleWindowsPaths() bool {
+	return p.windowsPaths == "mangle"
+}
+
+// MakeWindowsSafePublishPath mangles the path relative to the publish dir
+// with MakeWindowsSafePath if the page paths not portable to Windows should
+// be mangled. The same length limit is used as when checking the published
+// files.
+func (p *PathSpec) MakeWindowsSafePublishPath(path string) string {
+	if !p.MangleWindowsPaths() {
+		return path
+	}
+	return MakeWindowsSafePath(path, MaxWindowsPathIn(p.AbsPathify(p.PublishDir)))
+}
+
+// WarnWindowsPaths returns whether to warn about published paths that are
+// not portable to Windows.
+func (p *PathSpec) WarnWindowsPaths() bool {
+	return p.windowsPaths != "ignore"
+}
+
 // PaginatePath returns the configured root path used for paginator pages.
 func (p *PathSpec) PaginatePath() string {
 	return p.paginatePath
@@ -32,6 +32,7 @@ type ProcessingStats struct {
 	Aliases         uint64
 	Sitemaps        uint64
 	Cleaned         uint64
+	Precompressed   uint64
 }
 
 type processingStatsTitleVal struct {
@@ -49,6 +50,7 @@ func (s *ProcessingStats) toVals() []processingStatsTitleVal {
 		processingStatsTitleVal{"Aliases", s.Aliases},
 		processingStatsTitleVal{"Sitemaps", s.Sitemaps},
 		processingStatsTitleVal{"Cleaned", s.Cleaned},
+		processingStatsTitleVal{"Precompressed", s.Precompressed},
 	}
 }
 
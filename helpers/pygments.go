@@ -30,7 +30,6 @@ import (
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/formatters/html"
 	"github.com/alecthomas/chroma/lexers"
-	"github.com/alecthomas/chroma/styles"
 	bp "github.com/gohugoio/hugo/bufferpool"
 
 	"github.com/gohugoio/hugo/config"
@@ -80,7 +79,7 @@ func (h highlighters) chromaHighlight(code, lang, optsStr string) (string, error
 	b := bp.GetBuffer()
 	defer bp.PutBuffer(b)
 
-	err = chromaHighlight(b, code, lang, style, f)
+	err = chromaHighlight(b, code, lang, h.cs.chromaStyle(style), f)
 	if err != nil {
 		jww.ERROR.Print(err.Error())
 		return code, err
@@ -177,7 +176,7 @@ func (h highlighters) injectCodeTag(code, lang string) string {
 	return preRe.ReplaceAllString(code, fmt.Sprintf("$1%s$2</code>$3", codeTag))
 }
 
-func chromaHighlight(w io.Writer, source, lexer, style string, f chroma.Formatter) error {
+func chromaHighlight(w io.Writer, source, lexer string, s *chroma.Style, f chroma.Formatter) error {
 	l := lexers.Get(lexer)
 	if l == nil {
 		l = lexers.Analyse(source)
@@ -191,11 +190,6 @@ func chromaHighlight(w io.Writer, source, lexer, style string, f chroma.Formatte
 		f = formatters.Fallback
 	}
 
-	s := styles.Get(style)
-	if s == nil {
-		s = styles.Fallback
-	}
-
 	it, err := l.Tokenise(nil, source)
 	if err != nil {
 		return err
@@ -0,0 +1,155 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/cast"
+)
+
+const (
+	// ChromaCSSSelector is the class of the code blocks highlighted by Chroma.
+	ChromaCSSSelector = ".chroma"
+
+	// PygmentsCSSSelector is the class of the code blocks highlighted by
+	// Pygments. It also matches the code blocks highlighted by Chroma, as
+	// Hugo wraps them in a div with the same class.
+	PygmentsCSSSelector = ".highlight"
+)
+
+// The token types that can be styled in a custom style, keyed by their
+// lower case name, e.g. "namefunction", as the config keys are lower case.
+var chromaTokenTypes = make(map[string]chroma.TokenType)
+
+func init() {
+	for tt := range chroma.StandardTypes {
+		chromaTokenTypes[strings.ToLower(tt.String())] = tt
+	}
+}
+
+// NewChromaStyles creates the custom highlighting styles defined in the
+// pygmentsCustomStyles config, e.g.
+//
+//     [pygmentsCustomStyles.mystyle]
+//     base = "monokai"
+//     Keyword = "bold #ff79c6"
+//     NameFunction = "#50fa7b"
+//     LineHighlight = "bg:#44475a"
+//
+// The style entries use the Pygments syntax. The tokens not set are styled as
+// in the base style, if any.
+func NewChromaStyles(cfg config.Provider) (map[string]*chroma.Style, error) {
+	m := cfg.GetStringMap("pygmentsCustomStyles")
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	customStyles := make(map[string]*chroma.Style)
+	for name, v := range m {
+		entries, err := cast.ToStringMapStringE(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom highlighting style %q: %s", name, err)
+		}
+		style, err := newChromaStyle(name, entries)
+		if err != nil {
+			return nil, err
+		}
+		customStyles[strings.ToLower(name)] = style
+	}
+
+	return customStyles, nil
+}
+
+func newChromaStyle(name string, entries map[string]string) (*chroma.Style, error) {
+	builder := chroma.NewStyleBuilder(name)
+	if base, found := entries["base"]; found {
+		s, found := styles.Registry[strings.ToLower(base)]
+		if !found {
+			return nil, fmt.Errorf("custom highlighting style %q: unknown base style %q", name, base)
+		}
+		builder = s.Builder()
+	}
+
+	var tokens []string
+	for k := range entries {
+		if k != "base" {
+			tokens = append(tokens, k)
+		}
+	}
+	sort.Strings(tokens)
+
+	for _, token := range tokens {
+		tt, found := chromaTokenTypes[strings.ToLower(token)]
+		if !found {
+			return nil, fmt.Errorf("custom highlighting style %q: unknown token type %q", name, token)
+		}
+		builder.Add(tt, entries[token])
+	}
+
+	style, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("custom highlighting style %q: %s", name, err)
+	}
+	style.Name = name
+
+	return style, nil
+}
+
+// ChromaStyle returns the custom or built-in highlighting style with the
+// given name.
+func (cs *ContentSpec) ChromaStyle(name string) (*chroma.Style, error) {
+	if s, found := cs.chromaStyles[strings.ToLower(name)]; found {
+		return s, nil
+	}
+	if s, found := styles.Registry[strings.ToLower(name)]; found {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown highlighting style %q", name)
+}
+
+// chromaStyle is like ChromaStyle, but falls back to the default style.
+func (cs *ContentSpec) chromaStyle(name string) *chroma.Style {
+	s, err := cs.ChromaStyle(name)
+	if err != nil {
+		return styles.Fallback
+	}
+	return s
+}
+
+// WriteChromaCSS writes the CSS for the given highlighting style, for use
+// with pygmentsUseClasses. The rules are scoped to the given class selector,
+// e.g. ChromaCSSSelector.
+func WriteChromaCSS(w io.Writer, style *chroma.Style, selector string) error {
+	var buf bytes.Buffer
+	if err := html.New(html.WithClasses()).WriteCSS(&buf, style); err != nil {
+		return err
+	}
+
+	css := buf.String()
+	if selector != ChromaCSSSelector {
+		css = strings.Replace(css, "*/ "+ChromaCSSSelector+" ", "*/ "+selector+" ", -1)
+	}
+
+	_, err := io.WriteString(w, css)
+	return err
+}
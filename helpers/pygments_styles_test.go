@@ -0,0 +1,87 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/chroma"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromaCustomStyles(t *testing.T) {
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("pygmentsCustomStyles", map[string]interface{}{
+		"MyStyle": map[string]interface{}{
+			"base":         "monokai",
+			"keyword":      "bold #ff79c6",
+			"NameFunction": "#50fa7b",
+		},
+		"plain": map[string]interface{}{
+			"background": "bg:#000000 #ffffff",
+		},
+	})
+
+	spec, err := NewContentSpec(v)
+	assert.NoError(err)
+
+	s, err := spec.ChromaStyle("mystyle")
+	assert.NoError(err)
+	assert.Equal("mystyle", s.Name)
+	assert.Equal("#ff79c6", s.Get(chroma.Keyword).Colour.String())
+	assert.Equal(chroma.Yes, s.Get(chroma.Keyword).Bold)
+	assert.Equal("#50fa7b", s.Get(chroma.NameFunction).Colour.String())
+
+	// From the base style.
+	monokai, err := spec.ChromaStyle("monokai")
+	assert.NoError(err)
+	assert.Equal(monokai.Get(chroma.Comment), s.Get(chroma.Comment))
+
+	s, err = spec.ChromaStyle("plain")
+	assert.NoError(err)
+	assert.Equal("#000000", s.Get(chroma.Background).Background.String())
+
+	_, err = spec.ChromaStyle("nope")
+	assert.Error(err)
+
+	var buf bytes.Buffer
+	s, _ = spec.ChromaStyle("mystyle")
+	assert.NoError(WriteChromaCSS(&buf, s, PygmentsCSSSelector))
+	assert.Contains(buf.String(), "/* Keyword */ .highlight .k { color: #ff79c6; font-weight: bold }")
+
+	// Custom styles are used when highlighting.
+	v.Set("pygmentsStyle", "mystyle")
+	spec, err = NewContentSpec(v)
+	assert.NoError(err)
+	result, err := spec.Highlight(`func main() {}`, "go", "")
+	assert.NoError(err)
+	assert.Contains(result, `<span style="color:#ff79c6;font-weight:bold">func</span>`)
+}
+
+func TestChromaCustomStylesInvalid(t *testing.T) {
+	for _, style := range []map[string]interface{}{
+		{"base": "nope"},
+		{"nope": "#ffffff"},
+		{"keyword": "nope"},
+	} {
+		v := viper.New()
+		v.Set("pygmentsCustomStyles", map[string]interface{}{"mystyle": style})
+		_, err := NewContentSpec(v)
+		require.Error(t, err, style)
+	}
+}
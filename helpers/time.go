@@ -0,0 +1,51 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Matches a time zone after the time of a date, e.g. "Z", "+01:00" or "CET".
+var timeZoneSuffixRe = regexp.MustCompile(`(?::\d\d(?:\.\d+)?\s*(?:Z|[+-]\d\d(?::?\d\d)?)|\s[A-Z]{3,5})$`)
+
+// ToTimeInLocationE converts v to a time.Time. Dates without a time zone,
+// e.g. "2018-01-02T15:04:05", are in the given location, in UTC if nil.
+// This includes the TOML local date times, which the parser puts in the
+// local time zone of the machine.
+func ToTimeInLocationE(v interface{}, loc *time.Location) (time.Time, error) {
+	t, err := cast.ToTimeE(v)
+	if err != nil || loc == nil {
+		return t, err
+	}
+
+	switch vv := v.(type) {
+	case string:
+		if timeZoneSuffixRe.MatchString(strings.TrimSpace(vv)) {
+			return t, nil
+		}
+	case time.Time:
+		if vv.Location() != time.Local {
+			return t, nil
+		}
+	default:
+		return t, nil
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToTimeInLocationE(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	require.NoError(t, err)
+
+	for i, test := range []struct {
+		in     interface{}
+		loc    *time.Location
+		expect time.Time
+	}{
+		{"2018-01-02T15:04:05", oslo, time.Date(2018, 1, 2, 15, 4, 5, 0, oslo)},
+		{"2018-07-02", oslo, time.Date(2018, 7, 2, 0, 0, 0, 0, oslo)},
+		{"2018-01-02T15:04:05Z", oslo, time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2018-01-02T15:04:05+05:00", oslo, time.Date(2018, 1, 2, 10, 4, 5, 0, time.UTC)},
+		{"Tue, 02 Jan 2018 15:04:05 UTC", oslo, time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2018-01-02T15:04:05", nil, time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC), oslo, time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{time.Date(2018, 1, 2, 15, 4, 5, 0, time.Local), oslo, time.Date(2018, 1, 2, 15, 4, 5, 0, oslo)},
+	} {
+		result, err := ToTimeInLocationE(test.in, test.loc)
+		require.NoError(t, err, "[%d]", i)
+		require.True(t, test.expect.Equal(result), "[%d] got %s, expected %s", i, result, test.expect)
+	}
+
+	_, err = ToTimeInLocationE("not a date", oslo)
+	require.Error(t, err)
+}
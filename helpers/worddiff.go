@@ -0,0 +1,253 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"strings"
+)
+
+// DiffOp is the operation in a WordDiff chunk.
+type DiffOp int
+
+const (
+	// DiffEqual marks words found in both texts.
+	DiffEqual DiffOp = iota
+	// DiffInsert marks words only found in the new text.
+	DiffInsert
+	// DiffDelete marks words only found in the old text.
+	DiffDelete
+)
+
+// WordDiff is a chunk of words in a diff.
+type WordDiff struct {
+	Op    DiffOp
+	Words []string
+}
+
+// maxWordDiffEdits is the edit distance at which DiffWords gives up on
+// finding the shortest edit script, see DiffWords.
+const maxWordDiffEdits = 1000
+
+// DiffWords returns the shortest edit script that turns the words in a into
+// the words in b, as a list of chunks, using Myers' diff algorithm.
+// The words both texts start and end with are matched up front. If the rest
+// needs more than maxWordDiffEdits edits, it is reported as deleted and
+// inserted as a whole, which keeps the time and memory used bounded for
+// texts that have little in common.
+func DiffWords(a, b []string) []WordDiff {
+	n, m := len(a), len(b)
+
+	prefix := 0
+	for prefix < n && prefix < m && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && a[n-1-suffix] == b[m-1-suffix] {
+		suffix++
+	}
+
+	var ops []DiffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, DiffEqual)
+	}
+	ops = append(ops, diffWordOps(a[prefix:n-suffix], b[prefix:m-suffix])...)
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, DiffEqual)
+	}
+
+	// Build the chunks.
+	var (
+		chunks []WordDiff
+		ai, bi int
+	)
+
+	for _, op := range ops {
+		var word string
+		switch op {
+		case DiffEqual:
+			word = a[ai]
+			ai++
+			bi++
+		case DiffInsert:
+			word = b[bi]
+			bi++
+		case DiffDelete:
+			word = a[ai]
+			ai++
+		}
+
+		if len(chunks) > 0 && chunks[len(chunks)-1].Op == op {
+			chunks[len(chunks)-1].Words = append(chunks[len(chunks)-1].Words, word)
+		} else {
+			chunks = append(chunks, WordDiff{Op: op, Words: []string{word}})
+		}
+	}
+
+	return chunks
+}
+
+// diffWordOps returns the edit script that turns a into b, one operation
+// per word, in forward order.
+func diffWordOps(a, b []string) []DiffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	if max > maxWordDiffEdits {
+		max = maxWordDiffEdits
+	}
+	offset := max + 1
+
+	var (
+		v     = make([]int, 2*max+3)
+		trace [][]int
+		found bool
+	)
+
+search:
+	for d := 0; d <= max; d++ {
+		// Only the diagonals reached in the previous round are needed to
+		// walk back from this one.
+		var snapshot []int
+		if d > 0 {
+			snapshot = append(snapshot, v[offset-d+1:offset+d]...)
+		}
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+
+	var ops []DiffOp
+
+	if !found {
+		for i := 0; i < n; i++ {
+			ops = append(ops, DiffDelete)
+		}
+		for i := 0; i < m; i++ {
+			ops = append(ops, DiffInsert)
+		}
+		return ops
+	}
+
+	// Walk the trace backwards to build the edit script.
+	x, y := n, m
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		vk := func(k int) int { return prev[k+d-1] }
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && vk(k-1) < vk(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := vk(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, DiffEqual)
+			x--
+			y--
+		}
+
+		if x == prevX {
+			ops = append(ops, DiffInsert)
+		} else {
+			ops = append(ops, DiffDelete)
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for x > 0 && y > 0 {
+		ops = append(ops, DiffEqual)
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// FormatWordDiff formats the chunks in the style of git's word diff, i.e.
+// "[-removed-]{+added+}", with at most the given number of unchanged words
+// shown around each change.
+// It returns an empty string if there are no changes.
+func FormatWordDiff(chunks []WordDiff, context int) string {
+	var (
+		buf     bytes.Buffer
+		changed bool
+	)
+
+	for i, c := range chunks {
+		switch c.Op {
+		case DiffInsert:
+			changed = true
+			buf.WriteString("{+" + strings.Join(c.Words, " ") + "+} ")
+		case DiffDelete:
+			changed = true
+			buf.WriteString("[-" + strings.Join(c.Words, " ") + "-] ")
+		case DiffEqual:
+			words := c.Words
+			first, last := i == 0, i == len(chunks)-1
+			if len(words) > 2*context || (first || last) && len(words) > context {
+				var head, tail []string
+				if !first {
+					head = words[:context]
+				}
+				if !last {
+					tail = words[len(words)-context:]
+				}
+				if len(head) > 0 {
+					buf.WriteString(strings.Join(head, " ") + " ")
+				}
+				buf.WriteString("... ")
+				if len(tail) > 0 {
+					buf.WriteString(strings.Join(tail, " ") + " ")
+				}
+			} else {
+				buf.WriteString(strings.Join(words, " ") + " ")
+			}
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+
+	return strings.TrimSpace(buf.String())
+}
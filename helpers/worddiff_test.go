@@ -0,0 +1,68 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffWords(t *testing.T) {
+	for i, test := range []struct {
+		a, b     string
+		expected string
+	}{
+		{"", "", ""},
+		{"a b c", "a b c", ""},
+		{"a b c", "a x c", "a [-b-] {+x+} c"},
+		{"a b c", "", "[-a b c-]"},
+		{"", "a b", "{+a b+}"},
+		{"the quick brown fox", "the slow brown dog jumps", "the [-quick-] {+slow+} brown [-fox-] {+dog jumps+}"},
+		{"1 2 3 4 5 6 7 8 9 10", "1 2 3 4 5 x 7 8 9 10", "... 4 5 [-6-] {+x+} 7 8 ..."},
+	} {
+		chunks := DiffWords(strings.Fields(test.a), strings.Fields(test.b))
+		require.Equal(t, test.expected, FormatWordDiff(chunks, 2), "[%d]", i)
+
+		// Applying the diff should give the new text.
+		var result []string
+		for _, c := range chunks {
+			if c.Op != DiffDelete {
+				result = append(result, c.Words...)
+			}
+		}
+		require.Equal(t, test.b, strings.Join(result, " "), "[%d]", i)
+	}
+}
+
+func TestDiffWordsMaxEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 2*maxWordDiffEdits; i++ {
+		a = append(a, fmt.Sprintf("a%d", i))
+		b = append(b, fmt.Sprintf("b%d", i))
+	}
+	a = append([]string{"first"}, append(a, "last")...)
+	b = append([]string{"first"}, append(b, "last")...)
+
+	chunks := DiffWords(a, b)
+	require.Len(t, chunks, 4)
+	require.Equal(t, WordDiff{Op: DiffEqual, Words: []string{"first"}}, chunks[0])
+	require.Equal(t, DiffDelete, chunks[1].Op)
+	require.Equal(t, a[1:len(a)-1], chunks[1].Words)
+	require.Equal(t, DiffInsert, chunks[2].Op)
+	require.Equal(t, b[1:len(b)-1], chunks[2].Words)
+	require.Equal(t, WordDiff{Op: DiffEqual, Words: []string{"last"}}, chunks[3])
+}
@@ -104,6 +104,10 @@ func (s *Site) publishDestAlias(allowRoot bool, path, permalink string, p *Page)
 		return err
 	}
 
+	if p != nil {
+		s.checkPublishPath(targetPath, "alias in page "+p.pathOrTitle())
+	}
+
 	aliasContent, err := handler.renderAlias(isXHTML, permalink, p)
 	if err != nil {
 		return err
@@ -164,7 +168,7 @@ func (a aliasHandler) targetPathAlias(src string) (string, error) {
 			return "", fmt.Errorf("Cannot create \"%s\": Windows filename restriction", originalAlias)
 		}
 		for _, m := range msgs {
-			a.log.WARN.Println(m)
+			helpers.Warnidf(a.log, "warning-alias-reserved-name", "%s", m)
 		}
 	}
 
@@ -13,11 +13,37 @@
 
 package hugolib
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+const (
+	// The data dir with the author profiles, e.g. data/authors/jane-doe.toml.
+	authorsDataKey = "authors"
+
+	// The taxonomy generating the author list and term pages when
+	// authors.generatePages is set in the site config:
+	//
+	//   [authors]
+	//   generatePages = true
+	authorsTaxonomy         = "authors"
+	authorsTaxonomySingular = "author"
+)
+
 // AuthorList is a list of all authors and their metadata.
 type AuthorList map[string]Author
 
 // Author contains details about the author of a page.
 type Author struct {
+	// The key used for the author in the authors front matter, the name of
+	// the profile file in data/authors without extension.
+	ID string
+
 	GivenName   string
 	FamilyName  string
 	DisplayName string
@@ -27,6 +53,11 @@ type Author struct {
 	LongBio     string
 	Email       string
 	Social      AuthorSocial
+
+	// All the fields in the author profile.
+	Params map[string]interface{}
+
+	s *Site
 }
 
 // AuthorSocial is a place to put social details per author. These are the
@@ -43,3 +74,77 @@ type Author struct {
 // - linkedin
 // - skype
 type AuthorSocial map[string]string
+
+// Name returns the name to display for the author: the display name if set,
+// else the given and family names, else the ID.
+func (a Author) Name() string {
+	if a.DisplayName != "" {
+		return a.DisplayName
+	}
+	if name := strings.TrimSpace(a.GivenName + " " + a.FamilyName); name != "" {
+		return name
+	}
+	return a.ID
+}
+
+// Page returns the author's term page, nil if the author pages are not
+// generated, see authors.generatePages.
+func (a Author) Page() *Page {
+	if a.s == nil || a.ID == "" {
+		return nil
+	}
+	return a.s.getPage(KindTaxonomy, authorsTaxonomy, a.s.getTaxonomyKey(a.ID))
+}
+
+// newAuthorList creates the authors from the profiles in the site data, see
+// authorsDataKey.
+func newAuthorList(s *Site, data interface{}) (AuthorList, error) {
+	al := make(AuthorList)
+
+	for id, v := range cast.ToStringMap(data) {
+		m, err := cast.ToStringMapE(v)
+		if err != nil {
+			continue
+		}
+
+		var a Author
+		if err := mapstructure.WeakDecode(m, &a); err != nil {
+			return nil, fmt.Errorf("failed to decode author profile %q: %s", id, err)
+		}
+		a.ID = id
+		a.Params = m
+		a.s = s
+
+		al[id] = a
+	}
+
+	return al, nil
+}
+
+// Sorted returns the authors sorted by name.
+func (al AuthorList) Sorted() []Author {
+	authors := make([]Author, 0, len(al))
+	for _, a := range al {
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		ni, nj := strings.ToLower(authors[i].Name()), strings.ToLower(authors[j].Name())
+		if ni == nj {
+			return authors[i].ID < authors[j].ID
+		}
+		return ni < nj
+	})
+	return authors
+}
+
+// AuthorsSorted returns the page's authors in the order they are listed in
+// the front matter. Authors without a profile in data/authors are left out.
+func (p *Page) AuthorsSorted() []Author {
+	var authors []Author
+	for _, id := range cast.ToStringSlice(p.Params["authors"]) {
+		if a, found := p.Site.Authors[id]; found {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
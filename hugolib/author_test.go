@@ -0,0 +1,61 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthors(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404"]
+
+[authors]
+generatePages = true
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", "---\ntitle: P1\nauthors: [joe, jane]\n---\n",
+		"content/p2.md", "---\ntitle: P2\nauthors: [jane]\n---\n",
+		"content/p3.md", "---\ntitle: P3\nauthors: [unknown]\n---\n",
+		"data/authors/jane.toml", "displayName = \"Jane Doe\"\nemail = \"jane@example.com\"\n[social]\ntwitter = \"janedoe\"\n",
+		"data/authors/joe.yaml", "givenName: Joe\nfamilyName: Bloggs\nshortBio: Writes.\n",
+		"layouts/_default/single.html", `Authors: {{ range .AuthorsSorted }}{{ .ID }}:{{ .Name }}:{{ with .Page }}{{ .RelPermalink }}{{ end }}|{{ end }}First: {{ .Author.Name }}
+{{ template "_internal/twitter_cards.html" . }}`,
+		"layouts/_default/list.html", `List: {{ range .Pages }}{{ .Title }}|{{ end }}`,
+		"layouts/taxonomy/author.html", `Author: {{ with index .Site.Authors .Data.Term }}{{ .Name }}: {{ .ShortBio }}{{ end }}|{{ range .Pages }}{{ .Title }}|{{ end }}`,
+		"layouts/taxonomy/author.terms.html", `Authors: {{ range .Site.Authors.Sorted }}{{ .Name }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	assert.Len(h.Sites[0].Info.Authors, 2)
+
+	th.assertFileContent("public/p1/index.html",
+		"Authors: joe:Joe Bloggs:/authors/joe/|jane:Jane Doe:/authors/jane/|First: Joe Bloggs",
+		`<meta name="twitter:creator" content="@janedoe"/>`)
+	th.assertFileContent("public/p2/index.html", "Authors: jane:Jane Doe:/authors/jane/|First: Jane Doe")
+	th.assertFileContent("public/p3/index.html", "Authors: First: \n")
+	th.assertFileContent("public/authors/joe/index.html", "Author: Joe Bloggs: Writes.|P1|")
+	th.assertFileContent("public/authors/jane/index.html", "Author: Jane Doe: |P1|P2|")
+	th.assertFileContent("public/authors/index.html", "Authors: Jane Doe|Joe Bloggs|")
+	th.assertFileContent("public/index.xml", "<author>jane@example.com (Jane Doe)</author>")
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+)
+
+// BuildOption configures a build started with Build.
+type BuildOption func(o *buildOptions)
+
+type buildOptions struct {
+	fs          afero.Fs
+	destination afero.Fs
+	workingDir  string
+	configFile  string
+	logger      *jww.Notepad
+	handlers    []func(e BuildEvent)
+	buildCfg    BuildCfg
+}
+
+// WithFs sets the filesystem to read the site from and to publish it to,
+// the OS filesystem by default.
+func WithFs(fs afero.Fs) BuildOption {
+	return func(o *buildOptions) {
+		o.fs = fs
+	}
+}
+
+// WithDestination sets the filesystem to publish the site to, e.g. an
+// afero.MemMapFs to render the site to memory. It defaults to the filesystem
+// the site is read from.
+func WithDestination(fs afero.Fs) BuildOption {
+	return func(o *buildOptions) {
+		o.destination = fs
+	}
+}
+
+// WithWorkingDir sets the dir of the site. The dirs in the config, e.g.
+// contentDir, are relative to it.
+func WithWorkingDir(dir string) BuildOption {
+	return func(o *buildOptions) {
+		o.workingDir = dir
+	}
+}
+
+// WithConfigFile loads the site config from the given file, relative to the
+// working dir. Several files can be given comma separated, the later merged
+// into the first.
+func WithConfigFile(filename string) BuildOption {
+	return func(o *buildOptions) {
+		o.configFile = filename
+	}
+}
+
+// WithLogger sets the logger to use.
+func WithLogger(logger *jww.Notepad) BuildOption {
+	return func(o *buildOptions) {
+		o.logger = logger
+	}
+}
+
+// WithEventHandler adds a handler called for the events of the build, see
+// HugoSites.OnBuildEvent.
+func WithEventHandler(handler func(e BuildEvent)) BuildOption {
+	return func(o *buildOptions) {
+		o.handlers = append(o.handlers, handler)
+	}
+}
+
+// WithBuildCfg sets the BuildCfg to use for the first build, e.g. to skip
+// rendering.
+func WithBuildCfg(cfg BuildCfg) BuildOption {
+	return func(o *buildOptions) {
+		o.buildCfg = cfg
+	}
+}
+
+// Build builds the site with the given config and options. This is the
+// supported way to build a site from a Go program without running the hugo
+// command, e.g.:
+//
+//   sites, err := hugolib.Build(
+//   	map[string]interface{}{"baseURL": "https://example.org/"},
+//   	hugolib.WithWorkingDir("/path/to/site"),
+//   	hugolib.WithConfigFile("config.toml"))
+//
+// The settings in config override the settings in the config file. The
+// returned HugoSites can be used to inspect the built sites and to rebuild
+// them with HugoSites.Build.
+func Build(config map[string]interface{}, options ...BuildOption) (*HugoSites, error) {
+	o := &buildOptions{fs: hugofs.Os}
+	for _, option := range options {
+		option(o)
+	}
+
+	var (
+		v   *viper.Viper
+		err error
+	)
+
+	if o.configFile != "" {
+		filenames := strings.Split(o.configFile, ",")
+		for i, filename := range filenames {
+			if !filepath.IsAbs(filename) {
+				filenames[i] = filepath.Join(o.workingDir, filename)
+			}
+		}
+		v, err = LoadConfig(o.fs, o.workingDir, strings.Join(filenames, ","))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		v = viper.New()
+		v.SetFs(o.fs)
+		v.RegisterAlias("indexes", "taxonomies")
+	}
+
+	for key, value := range config {
+		v.Set(key, value)
+	}
+
+	if o.workingDir != "" {
+		v.Set("workingDir", o.workingDir)
+	}
+
+	if err := loadDefaultSettingsFor(v); err != nil {
+		return nil, err
+	}
+
+	fs := hugofs.NewFrom(o.fs, v)
+	if o.destination != nil {
+		fs.Destination = o.destination
+	}
+
+	sites, err := NewHugoSites(deps.DepsCfg{Fs: fs, Cfg: v, Logger: o.logger})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, handler := range o.handlers {
+		sites.OnBuildEvent(handler)
+	}
+
+	if err := sites.Build(o.buildCfg); err != nil {
+		return sites, err
+	}
+
+	return sites, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"os"
+	"time"
+)
+
+// BuildEventType is the type of a BuildEvent.
+type BuildEventType string
+
+const (
+	// BuildStarted is sent when a build or a rebuild starts.
+	BuildStarted BuildEventType = "started"
+
+	// BuildContentCaptured is sent when the content files are read.
+	BuildContentCaptured BuildEventType = "captured"
+
+	// BuildPageRendered is sent for each page rendered to an output format.
+	BuildPageRendered BuildEventType = "pageRendered"
+
+	// BuildFinished is sent when a build succeeds.
+	BuildFinished BuildEventType = "finished"
+
+	// BuildFailed is sent when a build fails.
+	BuildFailed BuildEventType = "failed"
+)
+
+// BuildEvent describes a step in the lifecycle of a build.
+type BuildEvent struct {
+	Type BuildEventType
+	Time time.Time
+
+	// The language of the page, set for BuildPageRendered.
+	Lang string
+
+	// The path to the content file relative to the content dir, empty for
+	// pages without a content file, e.g. taxonomy lists. Set for
+	// BuildPageRendered.
+	Path string
+
+	// The relative permalink and the name of the rendered output format,
+	// set for BuildPageRendered.
+	URL          string
+	OutputFormat string
+
+	// The duration since BuildStarted, set for BuildFinished and BuildFailed.
+	Duration time.Duration
+
+	// The build error, set for BuildFailed.
+	Err error
+}
+
+// OnBuildEvent adds a handler called for the events of the builds of h.
+// The handlers are called in the order they are added and never
+// concurrently, so they should return quickly.
+func (h *HugoSites) OnBuildEvent(handler func(e BuildEvent)) {
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+	h.eventHandlers = append(h.eventHandlers, handler)
+}
+
+func (h *HugoSites) sendBuildEvent(e BuildEvent) {
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+
+	if len(h.eventHandlers) == 0 {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	if e.Type == BuildStarted {
+		h.buildStart = e.Time
+	}
+
+	for _, handler := range h.eventHandlers {
+		handler(e)
+	}
+}
+
+// exitBuild ends the process after an error the build cannot recover from.
+// BuildFailed is sent first, so the event listeners see the build end.
+func (s *Site) exitBuild(err error) {
+	if s.owner != nil {
+		s.owner.eventsMu.Lock()
+		start := s.owner.buildStart
+		s.owner.eventsMu.Unlock()
+
+		s.owner.sendBuildEvent(BuildEvent{Type: BuildFailed, Duration: time.Since(start), Err: err})
+	}
+	os.Exit(-1)
+}
+
+func (s *Site) sendPageRenderedEvent(p *PageOutput) {
+	if s.owner == nil {
+		return
+	}
+
+	s.owner.sendBuildEvent(BuildEvent{
+		Type:         BuildPageRendered,
+		Lang:         s.Language.Lang,
+		Path:         p.Path(),
+		URL:          p.RelPermalink(),
+		OutputFormat: p.outputFormat.Name,
+	})
+}
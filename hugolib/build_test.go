@@ -0,0 +1,123 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"/site/config.toml":                  "baseURL = \"https://example.org/\"\ntitle = \"From File\"\ndisableKinds = [\"taxonomy\", \"taxonomyTerm\", \"RSS\", \"sitemap\"]\n",
+		"/site/content/post.md":              "---\ntitle: Post\n---\nContent\n",
+		"/site/layouts/_default/single.html": "{{ .Title }}|{{ .Site.Title }}",
+		"/site/layouts/index.html":           "Home|{{ .Site.Title }}",
+	} {
+		assert.NoError(afero.WriteFile(fs, filepath.FromSlash(name), []byte(content), 0755))
+	}
+
+	var (
+		logBuf bytes.Buffer
+		events []BuildEvent
+	)
+
+	destination := afero.NewMemMapFs()
+
+	sites, err := Build(
+		map[string]interface{}{"title": "From Map"},
+		WithFs(fs),
+		WithDestination(destination),
+		WithWorkingDir(filepath.FromSlash("/site")),
+		WithConfigFile("config.toml"),
+		WithLogger(jww.NewNotepad(jww.LevelWarn, jww.LevelError, &logBuf, ioutil.Discard, "", log.Ldate|log.Ltime)),
+		WithEventHandler(func(e BuildEvent) {
+			events = append(events, e)
+		}))
+
+	assert.NoError(err)
+	assert.Len(sites.Sites, 1)
+	assert.Equal("From Map", sites.Sites[0].Info.Title)
+
+	b, err := afero.ReadFile(destination, filepath.FromSlash("/site/public/post/index.html"))
+	assert.NoError(err)
+	assert.Equal("Post|From Map", string(b))
+
+	exists, _ := afero.Exists(fs, filepath.FromSlash("/site/public/post/index.html"))
+	assert.False(exists)
+
+	assert.True(len(events) >= 4)
+	assert.Equal(BuildStarted, events[0].Type)
+	assert.Equal(BuildContentCaptured, events[1].Type)
+	assert.Equal(BuildFinished, events[len(events)-1].Type)
+
+	var rendered []string
+	for _, e := range events {
+		if e.Type == BuildPageRendered {
+			assert.Equal("en", e.Lang)
+			rendered = append(rendered, e.OutputFormat+"|"+e.URL+"|"+e.Path)
+		}
+	}
+	assert.Contains(rendered, "HTML|/post/|post.md")
+	assert.Contains(rendered, "HTML|/|")
+
+	// Rebuilds send the events to the same handlers.
+	events = nil
+	assert.NoError(sites.Build(BuildCfg{}))
+	assert.Equal(BuildFinished, events[len(events)-1].Type)
+}
+
+func TestBuildFailed(t *testing.T) {
+	t.Parallel()
+
+	var events []BuildEvent
+
+	_, err := Build(
+		map[string]interface{}{"baseURL": "https://example.org/"},
+		WithFs(afero.NewMemMapFs()),
+		WithConfigFile("config.toml"),
+		WithEventHandler(func(e BuildEvent) {
+			events = append(events, e)
+		}))
+
+	require.Error(t, err)
+	require.Empty(t, events)
+
+	// Failing builds send the failed event.
+	_, err = Build(
+		map[string]interface{}{"baseURL": "https://example.org/", "contentDir": "missing"},
+		WithFs(afero.NewMemMapFs()),
+		WithBuildCfg(BuildCfg{SkipRender: true}),
+		WithEventHandler(func(e BuildEvent) {
+			events = append(events, e)
+		}))
+
+	require.Error(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, BuildStarted, events[0].Type)
+	require.Equal(t, BuildFailed, events[1].Type)
+	require.Equal(t, err, events[1].Err)
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/media"
+	"github.com/mitchellh/mapstructure"
+)
+
+// cdnConfig configures the rewriting of the links to the site's assets to a
+// CDN in the published HTML. It is either set as the CDN's URL, which
+// rewrites the links to images, stylesheets, scripts, fonts, audio and
+// video:
+//
+//   cdnURL = "https://cdn.example.com/"
+//
+// Or with the media types and paths to rewrite:
+//
+//   [cdnURL]
+//   url = "https://cdn.example.com/"
+//   mediaTypes = ["image/*"]
+//   paths = ["/images/*"]
+//
+// The links are not rewritten by the development server.
+type cdnConfig struct {
+	URL string
+
+	// The media types to rewrite, where * matches any sub type, e.g.
+	// image/*.
+	MediaTypes []string
+
+	// The paths to rewrite, where * matches any sequence of characters,
+	// e.g. /images/*. All paths by default.
+	Paths []string
+
+	paths []*regexp.Regexp
+}
+
+var defaultCDNMediaTypes = []string{
+	"image/*",
+	"font/*",
+	"audio/*",
+	"video/*",
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+}
+
+func decodeCDNConfig(v interface{}) (*cdnConfig, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	c := &cdnConfig{}
+
+	if s, ok := v.(string); ok {
+		c.URL = s
+	} else if err := mapstructure.WeakDecode(v, c); err != nil {
+		return nil, fmt.Errorf("failed to decode cdnURL config: %s", err)
+	}
+
+	if c.URL == "" {
+		return nil, nil
+	}
+
+	if len(c.MediaTypes) == 0 {
+		c.MediaTypes = defaultCDNMediaTypes
+	}
+
+	for _, p := range c.Paths {
+		if !strings.HasPrefix(p, "/") {
+			return nil, fmt.Errorf("invalid cdnURL path %q, must start with a /", p)
+		}
+		pattern := strings.Replace(regexp.QuoteMeta(p), `\*`, ".*", -1)
+		c.paths = append(c.paths, regexp.MustCompile("^"+pattern+"$"))
+	}
+
+	return c, nil
+}
+
+// matches reports whether the link with the given path, relative to the
+// site root, should be served from the CDN.
+func (c *cdnConfig) matches(types media.Types, p string) bool {
+	if len(c.paths) > 0 {
+		var found bool
+		for _, re := range c.paths {
+			if re.MatchString(p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	mediaType := mediaTypeForPath(types, p)
+	if mediaType == "" {
+		return false
+	}
+
+	for _, pattern := range c.MediaTypes {
+		if match, _ := path.Match(strings.ToLower(pattern), mediaType); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mediaTypeForPath returns the media type, e.g. image/png, for the file
+// extension of the given path. The site's media types take precedence.
+func mediaTypeForPath(types media.Types, p string) string {
+	ext := strings.ToLower(path.Ext(p))
+	if ext == "" {
+		return ""
+	}
+
+	if m, found := types.GetBySuffix(ext[1:]); found {
+		return m.Type()
+	}
+
+	tp := mime.TypeByExtension(ext)
+	if i := strings.Index(tp, ";"); i != -1 {
+		tp = tp[:i]
+	}
+
+	return strings.TrimSpace(tp)
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/media"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCDNConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	c, err := decodeCDNConfig(nil)
+	assert.NoError(err)
+	assert.Nil(c)
+
+	c, err = decodeCDNConfig("https://cdn.example.com")
+	assert.NoError(err)
+	assert.True(c.matches(media.DefaultTypes, "/images/logo.png"))
+	assert.True(c.matches(media.DefaultTypes, "/css/main.css"))
+	assert.False(c.matches(media.DefaultTypes, "/posts/"))
+	assert.False(c.matches(media.DefaultTypes, "/index.xml"))
+
+	c, err = decodeCDNConfig(map[string]interface{}{
+		"url":        "https://cdn.example.com",
+		"mediaTypes": []string{"image/*"},
+		"paths":      []string{"/images/*"},
+	})
+	assert.NoError(err)
+	assert.True(c.matches(media.DefaultTypes, "/images/a/logo.png"))
+	assert.False(c.matches(media.DefaultTypes, "/logo.png"))
+	assert.False(c.matches(media.DefaultTypes, "/images/main.css"))
+
+	_, err = decodeCDNConfig(map[string]interface{}{"url": "https://cdn.example.com", "paths": []string{"images/*"}})
+	assert.Error(err)
+}
+
+func TestCDNURL(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+cdnURL = "https://cdn.example.com/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"layouts/_default/single.html", `<link rel="stylesheet" href="/css/main.css"><img src="/images/logo.png"><a href="/posts/">Posts</a>`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/p/index.html", `href="https://cdn.example.com/css/main.css"`, `src="https://cdn.example.com/images/logo.png"`, `href="/posts/"`)
+
+	h.running = true
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/p/index.html", `href="/css/main.css"`, `src="/images/logo.png"`)
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollation(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "sv"
+
+[taxonomies]
+tag = "tags"
+
+[languages]
+[languages.sv]
+weight = 1
+[languages.zh]
+weight = 2
+collation = "zh-u-co-stroke"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a.sv.md", "---\ntitle: Öl\ntags: [Öl, Zebra]\nauthor: Åsa\n---\n",
+		"content/b.sv.md", "---\ntitle: Zebra\ntags: [Äpple, apple]\nauthor: Anna\n---\n",
+		"content/c.sv.md", "---\ntitle: äpple\ntags: [Banana]\nauthor: Örjan\n---\n",
+		"content/d.sv.md", "---\ntitle: Banana\nauthor: Anna\n---\n",
+		"content/a.zh.md", "---\ntitle: 阿\nauthor: 阿\n---\n",
+		"content/b.zh.md", "---\ntitle: 中国\nauthor: 中国\n---\n",
+		"content/c.zh.md", "---\ntitle: 北京\nauthor: 北京\n---\n",
+		"layouts/index.html", `
+ByTitle: {{ range .Site.RegularPages.ByTitle }}{{ .Title }}|{{ end }}
+Tags: {{ range .Site.Taxonomies.tags.Alphabetical }}{{ .Name }}|{{ end }}
+Authors: {{ range .Site.RegularPages.GroupByParam "author" }}{{ .Key }}|{{ end }}
+`,
+		"layouts/_default/single.html", `{{ .Title }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html",
+		"ByTitle: Banana|Zebra|äpple|Öl|",
+		"Tags: apple|banana|zebra|äpple|öl|",
+		"Authors: Anna|Åsa|Örjan|")
+	th.assertFileContent("public/zh/index.html", "ByTitle: 中国|北京|阿|")
+}
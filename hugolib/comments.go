@@ -0,0 +1,268 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// commentsConfig configures the static comments, read from the site data
+// keyed by page path, e.g. data/comments/posts/my-post/entry1.yml as written
+// by Staticman, or from a remote JSON object such as
+// {"posts/my-post": [{"name": "Jane", "body": "Nice!"}]}:
+//
+//   [comments]
+//   dataKey = "comments"
+//   url = "https://api.example.com/comments.json"
+//
+// The comments are available in the templates with .Site.Comments.ForPage.
+type commentsConfig struct {
+	// The key in .Site.Data with the comments. Use dots for nested keys.
+	// Default is "comments".
+	DataKey string
+
+	// A remote JSON endpoint with the comments. If set, it is fetched as
+	// remote data with the key above, replacing any data files there.
+	URL string
+}
+
+func decodeCommentsConfig(cfg config.Provider) (commentsConfig, error) {
+	c := commentsConfig{DataKey: "comments"}
+
+	if v := cfg.Get("comments"); v != nil {
+		if err := mapstructure.WeakDecode(v, &c); err != nil {
+			return c, fmt.Errorf("failed to decode comments config: %s", err)
+		}
+	}
+
+	if c.DataKey == "" {
+		return c, fmt.Errorf("comments config must have a dataKey")
+	}
+
+	return c, nil
+}
+
+// Comment is a comment on a page.
+type Comment struct {
+	ID    string
+	Name  string
+	Email string
+
+	// The commenter's website.
+	URL string
+
+	// The comment text, from the body, message or comment field. It is not
+	// rendered, use e.g. markdownify.
+	Body string
+
+	Date time.Time
+
+	// The ID of the comment replied to, if any, from the replyTo or parent
+	// field.
+	ReplyTo string
+
+	// The replies to this comment, sorted by date.
+	Replies []*Comment
+
+	// All the fields of the comment as read from the data source.
+	Params map[string]interface{}
+}
+
+// Comments holds the comments for the site's pages.
+type Comments struct {
+	// Page path => the top level comments sorted by date.
+	byPath map[string][]*Comment
+}
+
+// ForPage returns the top level comments on the given page, sorted by date,
+// with the replies in Replies. The comments are looked up by the page's path
+// in the content dir without extension, e.g. posts/my-post, with the
+// directory as the path for bundles, then by its relative permalink.
+func (c *Comments) ForPage(page interface{}) ([]*Comment, error) {
+	if c == nil || page == nil || len(c.byPath) == 0 {
+		return nil, nil
+	}
+
+	p, err := unwrapPage(page)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.File != nil && p.File.Filename() != "" {
+		key := commentsPagePath(pageDepPath(p.File.Filename(), p.s.absContentDir()))
+		if comments, found := c.byPath[key]; found {
+			return comments, nil
+		}
+	}
+
+	return c.byPath[commentsPath(p.RelPermalink())], nil
+}
+
+// commentsPagePath returns the comments path for a page path as returned by
+// pageDepPath.
+func commentsPagePath(p string) string {
+	switch base := path.Base(p); base {
+	case "index", "_index":
+		p = path.Dir(p)
+		if p == "." {
+			p = ""
+		}
+	}
+	return p
+}
+
+func commentsPath(p string) string {
+	return strings.ToLower(strings.Trim(p, "/"))
+}
+
+// newComments creates the comments from the given site data.
+func newComments(data map[string]interface{}, dataKey string) *Comments {
+	var v interface{} = data
+	for _, part := range strings.Split(dataKey, ".") {
+		m, err := cast.ToStringMapE(v)
+		if err != nil {
+			return &Comments{}
+		}
+		v = m[part]
+	}
+
+	all := make(map[string][]*Comment)
+	collectComments(all, "", v)
+
+	c := &Comments{byPath: make(map[string][]*Comment)}
+	for p, comments := range all {
+		c.byPath[p] = threadComments(comments)
+	}
+
+	return c
+}
+
+// collectComments walks the data, adding the comments found by path. A
+// comment is a map with a body, message or comment field; any other map is
+// a level in the path, the last one for a map keyed by comment ID.
+func collectComments(all map[string][]*Comment, p string, v interface{}) {
+	switch vv := v.(type) {
+	case nil:
+		return
+	case []interface{}:
+		for i, item := range vv {
+			m, err := cast.ToStringMapE(item)
+			if err != nil || !isCommentData(m) {
+				continue
+			}
+			all[p] = append(all[p], newComment(strconv.Itoa(i), m))
+		}
+		return
+	}
+
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return
+	}
+
+	if isCommentData(m) {
+		parent := path.Dir(p)
+		if parent == "." {
+			parent = ""
+		}
+		all[parent] = append(all[parent], newComment(path.Base(p), m))
+		return
+	}
+
+	for k, sub := range m {
+		collectComments(all, commentsPath(path.Join(p, k)), sub)
+	}
+}
+
+var commentBodyFields = []string{"body", "message", "comment"}
+
+func isCommentData(m map[string]interface{}) bool {
+	for k, v := range m {
+		for _, field := range commentBodyFields {
+			if strings.EqualFold(k, field) {
+				if _, err := cast.ToStringE(v); err == nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func newComment(id string, m map[string]interface{}) *Comment {
+	c := &Comment{ID: id, Params: m}
+
+	for k, v := range m {
+		switch strings.ToLower(k) {
+		case "id", "_id":
+			c.ID = cast.ToString(v)
+		case "name", "author":
+			c.Name = cast.ToString(v)
+		case "email":
+			c.Email = cast.ToString(v)
+		case "url", "website":
+			c.URL = cast.ToString(v)
+		case "body", "message", "comment":
+			c.Body = cast.ToString(v)
+		case "date":
+			c.Date = cast.ToTime(v)
+		case "replyto", "reply_to", "parent":
+			c.ReplyTo = cast.ToString(v)
+		}
+	}
+
+	return c
+}
+
+// threadComments sorts the comments by date and moves the replies into the
+// comments they reply to. Replies to unknown comments are kept at the top
+// level.
+func threadComments(comments []*Comment) []*Comment {
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].Date.Equal(comments[j].Date) {
+			return comments[i].ID < comments[j].ID
+		}
+		return comments[i].Date.Before(comments[j].Date)
+	})
+
+	byID := make(map[string]*Comment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+
+	var top []*Comment
+	for _, c := range comments {
+		if parent, found := byID[c.ReplyTo]; found && c.ReplyTo != "" && parent != c {
+			parent.Replies = append(parent.Replies, c)
+			continue
+		}
+		top = append(top, c)
+	}
+
+	return top
+}
+
+// Comments returns the static comments, see commentsConfig.
+func (s *SiteInfo) Comments() *Comments {
+	return s.s.comments
+}
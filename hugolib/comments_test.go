@@ -0,0 +1,96 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const commentsTestTemplate = `Comments: {{ range .Site.Comments.ForPage . }}{{ .ID }}:{{ .Name }}:{{ .Body }}[{{ range .Replies }}{{ .ID }}:{{ .Name }}{{ end }}]|{{ end }}`
+
+func TestCommentsFromData(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\n---\n",
+		"content/posts/bundle/index.md", "---\ntitle: Bundle\n---\n",
+		"content/posts/p3.md", "---\ntitle: P3\n---\n",
+		"data/comments/posts/p1/entry2.yaml", "name: Joe\nmessage: Thanks!\ndate: 2018-01-02\nreplyTo: entry1\n",
+		"data/comments/posts/p1/entry1.yaml", "name: Jane\nmessage: Nice post.\ndate: 2018-01-01\n",
+		"data/comments/posts/p1/entry3.yaml", "name: Ann\nmessage: Agreed.\ndate: 2018-01-03\n",
+		"data/comments/posts/bundle.json", `[{"name": "Bob", "body": "Hi"}]`,
+		"layouts/_default/single.html", commentsTestTemplate,
+		"layouts/_default/list.html", "List",
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/p1/index.html", "entry1:Jane:Nice post.[entry2:Joe]|entry3:Ann:Agreed.[]|")
+	th.assertFileContent("public/posts/bundle/index.html", "0:Bob:Hi[]|")
+	th.assertFileContent("public/posts/p3/index.html", "Comments: ")
+}
+
+func TestCommentsFromURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"/posts/p1/": [{"id": "c1", "author": "Jane", "comment": "Remote"}]}`)
+	}))
+	defer srv.Close()
+
+	config := fmt.Sprintf(`
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[comments]
+dataKey = "remote.comments"
+url = "%s/comments"
+`, srv.URL)
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\nslug: first\n---\n",
+		"layouts/_default/single.html", commentsTestTemplate,
+		"layouts/_default/list.html", "List",
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/first/index.html", "c1:Jane:Remote[]|")
+}
+
+func TestDecodeCommentsConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg, _ := newTestCfg()
+
+	c, err := decodeCommentsConfig(cfg)
+	assert.NoError(err)
+	assert.Equal("comments", c.DataKey)
+
+	cfg.Set("comments", map[string]interface{}{"dataKey": ""})
+	_, err = decodeCommentsConfig(cfg)
+	assert.Error(err)
+}
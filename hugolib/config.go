@@ -23,12 +23,15 @@ import (
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/spf13/afero"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
 // LoadConfig loads Hugo configuration into a new Viper and then adds
-// a set of defaults.
-func LoadConfig(fs afero.Fs, relativeSourcePath, configFilename string) (*viper.Viper, error) {
+// a set of defaults. Any doWithConfig funcs are applied to the config
+// before the theme config, the defaults and the languages are loaded, so
+// values set there, e.g. from the command line, are seen by all of them.
+func LoadConfig(fs afero.Fs, relativeSourcePath, configFilename string, doWithConfig ...func(cfg config.Provider) error) (*viper.Viper, error) {
 	v := viper.New()
 	v.SetFs(fs)
 	if relativeSourcePath == "" {
@@ -64,6 +67,16 @@ func LoadConfig(fs afero.Fs, relativeSourcePath, configFilename string) (*viper.
 
 	v.RegisterAlias("indexes", "taxonomies")
 
+	for _, d := range doWithConfig {
+		if err := d(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadThemeConfig(fs, v, relativeSourcePath); err != nil {
+		return nil, err
+	}
+
 	// Remove these in Hugo 0.33.
 	if v.IsSet("disable404") {
 		helpers.Deprecated("site config", "disable404", "Use disableKinds=[\"404\"]", true)
@@ -85,6 +98,21 @@ func LoadConfig(fs afero.Fs, relativeSourcePath, configFilename string) (*viper.
 		return v, err
 	}
 
+	// The author and series pages are generated with taxonomies.
+	for _, t := range []struct{ key, singular, plural string }{
+		{"authors", authorsTaxonomySingular, authorsTaxonomy},
+		{"series", seriesTaxonomy, seriesTaxonomy},
+	} {
+		if !v.GetBool(t.key + ".generatePages") {
+			continue
+		}
+		taxonomies := v.GetStringMapString("taxonomies")
+		if _, found := taxonomies[t.singular]; !found {
+			taxonomies[t.singular] = t.plural
+			v.Set("taxonomies", taxonomies)
+		}
+	}
+
 	return v, nil
 }
 
@@ -102,6 +130,10 @@ func loadLanguageSettings(cfg config.Provider, oldLangs helpers.Languages) error
 		if err != nil {
 			return fmt.Errorf("Failed to parse multilingual config: %s", err)
 		}
+		langs, err = removeDisabledLanguages(cfg, langs)
+		if err != nil {
+			return err
+		}
 	}
 
 	if oldLangs != nil {
@@ -157,6 +189,32 @@ func loadLanguageSettings(cfg config.Provider, oldLangs helpers.Languages) error
 	return nil
 }
 
+// removeDisabledLanguages removes the languages set in disableLanguages, e.g.
+// translations in progress. Their config is kept, so they can be enabled
+// again, but no sites are built for them and their content is skipped.
+func removeDisabledLanguages(cfg config.Provider, langs helpers.Languages) (helpers.Languages, error) {
+	disabled := make(map[string]bool)
+	for _, lang := range cast.ToStringSlice(cfg.Get("disableLanguages")) {
+		disabled[strings.ToLower(lang)] = true
+	}
+	if len(disabled) == 0 {
+		return langs, nil
+	}
+
+	if disabled[strings.ToLower(cfg.GetString("defaultContentLanguage"))] {
+		return nil, fmt.Errorf("the default content language %q cannot be disabled", cfg.GetString("defaultContentLanguage"))
+	}
+
+	var enabled helpers.Languages
+	for _, l := range langs {
+		if !disabled[strings.ToLower(l.Lang)] {
+			enabled = append(enabled, l)
+		}
+	}
+
+	return enabled, nil
+}
+
 func loadDefaultSettingsFor(v *viper.Viper) error {
 
 	c, err := helpers.NewContentSpec(v)
@@ -174,6 +232,8 @@ func loadDefaultSettingsFor(v *viper.Viper) error {
 	v.SetDefault("layoutDir", "layouts")
 	v.SetDefault("staticDir", "static")
 	v.SetDefault("resourceDir", "resources")
+	v.SetDefault("deduplicateResources", false)
+	v.SetDefault("resourcesFrom", []string{})
 	v.SetDefault("archetypeDir", "archetypes")
 	v.SetDefault("publishDir", "public")
 	v.SetDefault("dataDir", "data")
@@ -188,15 +248,22 @@ func loadDefaultSettingsFor(v *viper.Viper) error {
 	v.SetDefault("ignoreCache", false)
 	v.SetDefault("canonifyURLs", false)
 	v.SetDefault("relativeURLs", false)
+	v.SetDefault("offlineURLs", false)
 	v.SetDefault("removePathAccents", false)
 	v.SetDefault("titleCaseStyle", "AP")
 	v.SetDefault("taxonomies", map[string]string{"tag": "tags", "category": "categories"})
 	v.SetDefault("permalinks", make(PermalinkOverrides, 0))
+	v.SetDefault("resourcePermalinks", make(PermalinkOverrides, 0))
 	v.SetDefault("sitemap", Sitemap{Priority: -1, Filename: "sitemap.xml"})
 	v.SetDefault("pygmentsStyle", "monokai")
 	v.SetDefault("pygmentsUseClasses", false)
 	v.SetDefault("pygmentsCodeFences", false)
 	v.SetDefault("pygmentsUseClassic", false)
+	v.SetDefault("pygmentsCSS", "")
+	v.SetDefault("disableLanguages", []string{})
+	v.SetDefault("imageWorkers", 0)
+	v.SetDefault("skipImages", false)
+	v.SetDefault("templateCoverage", false)
 	v.SetDefault("pygmentsOptions", "")
 	v.SetDefault("disableLiveReload", false)
 	v.SetDefault("pluralizeListTitles", true)
@@ -205,14 +272,21 @@ func loadDefaultSettingsFor(v *viper.Viper) error {
 	v.SetDefault("footnoteAnchorPrefix", "")
 	v.SetDefault("footnoteReturnLinkContents", "")
 	v.SetDefault("newContentEditor", "")
+	v.SetDefault("newContentHook", "")
 	v.SetDefault("paginate", 10)
 	v.SetDefault("paginatePath", "page")
+	v.SetDefault("paginateAliasFirstPage", true)
+	v.SetDefault("paginateNoIndex", false)
 	v.SetDefault("summaryLength", 70)
 	v.SetDefault("blackfriday", c.BlackFriday)
 	v.SetDefault("rSSUri", "index.xml")
 	v.SetDefault("rssLimit", -1)
+	v.SetDefault("sectionData", false)
+	v.SetDefault("sectionDataParams", []string{})
 	v.SetDefault("sectionPagesMenu", "")
 	v.SetDefault("disablePathToLower", false)
+	v.SetDefault("windowsPaths", "warn")
+	v.SetDefault("filenameNormalization", "nfc")
 	v.SetDefault("hasCJKLanguage", false)
 	v.SetDefault("enableEmoji", false)
 	v.SetDefault("pygmentsCodeFencesGuessSyntax", false)
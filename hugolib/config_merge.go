@@ -0,0 +1,365 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// The merge strategies for the params, menu and markup config between the
+// theme, the project and the languages, set with _merge in the map with
+// the higher priority, e.g.:
+//
+//	[languages.de.params]
+//	_merge = "deep"
+//
+// If not set there, the _merge of the lower levels applies.
+const (
+	// Maps are merged recursively.
+	mergeDeep = "deep"
+
+	// Keys missing on the higher level are added from the lower level.
+	mergeShallow = "shallow"
+
+	// The value on the higher level replaces the lower level.
+	mergeNone = "none"
+
+	mergeStrategyKey = "_merge"
+)
+
+// The config keys merged between the theme, the project and the languages,
+// with the default strategy for the languages. The theme config is merged
+// shallow by default.
+var mergedConfigKeys = map[string]string{
+	"params":      mergeShallow,
+	"menu":        mergeNone,
+	"markup":      mergeNone,
+	"blackfriday": mergeNone,
+}
+
+// The names of the config levels, see MergedConfigValue.
+const (
+	configOriginTheme   = "theme"
+	configOriginProject = "project"
+)
+
+// The config key holding the theme and project values for mergedConfigKeys
+// as they were before the merge.
+const configLayersKey = "configLayers"
+
+// configLayers holds the raw values of the merged config keys.
+type configLayers struct {
+	theme   map[string]interface{}
+	project map[string]interface{}
+}
+
+// mergeConfigValue merges lo into hi using the given strategy, the default
+// if not set with _merge in hi or lo.
+func mergeConfigValue(hi, lo interface{}, defaultStrategy string) interface{} {
+	if hi == nil {
+		return withoutMergeKey(lo)
+	}
+
+	him, hiIsMap := toConfigMap(hi)
+	lom, loIsMap := toConfigMap(lo)
+	if !hiIsMap || !loIsMap {
+		return withoutMergeKey(hi)
+	}
+
+	strategy := mergeStrategy(defaultStrategy, him, lom)
+
+	merged := make(map[string]interface{})
+	for k, v := range him {
+		if k != mergeStrategyKey {
+			merged[k] = withoutMergeKey(v)
+		}
+	}
+
+	if strategy == mergeNone {
+		return merged
+	}
+
+	for k, v := range lom {
+		if k == mergeStrategyKey {
+			continue
+		}
+		if _, found := merged[k]; !found {
+			merged[k] = withoutMergeKey(v)
+		} else if strategy == mergeDeep {
+			merged[k] = mergeConfigValue(him[k], v, mergeDeep)
+		}
+	}
+
+	return merged
+}
+
+// mergeStrategy returns the first _merge set in the maps, else the default.
+func mergeStrategy(defaultStrategy string, maps ...map[string]interface{}) string {
+	for _, m := range maps {
+		if s := strings.ToLower(cast.ToString(m[mergeStrategyKey])); s != "" {
+			return s
+		}
+	}
+	return defaultStrategy
+}
+
+func validateMergeStrategy(v interface{}) error {
+	m, ok := toConfigMap(v)
+	if !ok {
+		return nil
+	}
+	if s, found := m[mergeStrategyKey]; found {
+		switch strings.ToLower(cast.ToString(s)) {
+		case mergeDeep, mergeShallow, mergeNone:
+		default:
+			return fmt.Errorf("invalid %s value %q, must be one of deep, shallow or none", mergeStrategyKey, s)
+		}
+	}
+	for _, vv := range m {
+		if err := validateMergeStrategy(vv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toConfigMap returns the value as a map with lower case keys, if it is a
+// map.
+func toConfigMap(v interface{}) (map[string]interface{}, bool) {
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+	default:
+		return nil, false
+	}
+	m := make(map[string]interface{})
+	for k, vv := range cast.ToStringMap(v) {
+		m[strings.ToLower(k)] = vv
+	}
+	return m, true
+}
+
+func withoutMergeKey(v interface{}) interface{} {
+	m, ok := toConfigMap(v)
+	if !ok {
+		return v
+	}
+	delete(m, mergeStrategyKey)
+	for k, vv := range m {
+		m[k] = withoutMergeKey(vv)
+	}
+	return m
+}
+
+// withOrigin returns a copy of the config value with all values but maps
+// replaced by the origin, used to track where the merged values come from.
+func withOrigin(v interface{}, origin string) interface{} {
+	if v == nil {
+		return nil
+	}
+	m, ok := toConfigMap(v)
+	if !ok {
+		return origin
+	}
+	for k, vv := range m {
+		if k != mergeStrategyKey {
+			m[k] = withOrigin(vv, origin)
+		}
+	}
+	return m
+}
+
+// loadThemeConfig merges the params, menu and markup config of the theme, if
+// the theme has a config file, into the project config.
+func loadThemeConfig(fs afero.Fs, v *viper.Viper, sourcePath string) error {
+	layers := configLayers{project: make(map[string]interface{})}
+	for key := range mergedConfigKeys {
+		if v.IsSet(key) {
+			layers.project[key] = v.Get(key)
+		}
+		if err := validateMergeStrategy(v.Get(key)); err != nil {
+			return err
+		}
+	}
+
+	defer func() {
+		v.Set(configLayersKey, layers)
+	}()
+
+	theme := v.GetString("theme")
+	if theme == "" {
+		return nil
+	}
+
+	themesDir := v.GetString("themesDir")
+	if themesDir == "" {
+		themesDir = "themes"
+	}
+	themeDir := filepath.Join(sourcePath, themesDir, theme)
+	if filepath.IsAbs(themesDir) {
+		themeDir = filepath.Join(themesDir, theme)
+	}
+
+	var filename string
+	for _, ext := range []string{"toml", "yaml", "yml", "json"} {
+		candidate := filepath.Join(themeDir, "config."+ext)
+		if exists, _ := helpers.Exists(candidate, fs); exists {
+			filename = candidate
+			break
+		}
+	}
+	if filename == "" {
+		return nil
+	}
+
+	tv := viper.New()
+	tv.SetFs(fs)
+	tv.SetConfigFile(filename)
+	if err := tv.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read theme config %q: %s", filename, err)
+	}
+
+	layers.theme = make(map[string]interface{})
+	for key := range mergedConfigKeys {
+		if !tv.IsSet(key) {
+			continue
+		}
+		tval := tv.Get(key)
+		if err := validateMergeStrategy(tval); err != nil {
+			return fmt.Errorf("invalid theme config %q: %s", filename, err)
+		}
+		layers.theme[key] = tval
+		v.Set(key, mergeConfigValue(layers.project[key], tval, mergeShallow))
+	}
+
+	return nil
+}
+
+// mergeLanguageConfig merges the params, menu and markup config set for the
+// language with the project config.
+func mergeLanguageConfig(cfg config.Provider, language *helpers.Language, langConfig map[string]interface{}) error {
+	// The params go first, as these may be reset, see below.
+	keys := []string{"params"}
+	for key := range mergedConfigKeys {
+		if key != "params" {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		defaultStrategy := mergedConfigKeys[key]
+		local, found := langConfig[key]
+		if !found {
+			continue
+		}
+		if err := validateMergeStrategy(local); err != nil {
+			return fmt.Errorf("invalid config for language %q: %s", language.Lang, err)
+		}
+
+		global := cfg.Get(key)
+		merged := mergeConfigValue(local, global, defaultStrategy)
+
+		if key != "params" {
+			language.Set(key, merged)
+			continue
+		}
+
+		// The site params are the global params and the language
+		// settings, see helpers.NewLanguage.
+		mergedm, _ := toConfigMap(merged)
+		localm, _ := toConfigMap(local)
+		if mergeStrategy(defaultStrategy, localm) == mergeNone {
+			params := make(map[string]interface{})
+			for k, v := range langConfig {
+				params[k] = v
+			}
+			language.SetParams(params)
+		}
+		for k, v := range mergedm {
+			language.SetParam(k, v)
+		}
+	}
+
+	return nil
+}
+
+// MergedConfigValue is a value of the merged params, menu or markup config
+// for a language.
+type MergedConfigValue struct {
+	// The dot separated key, e.g. params.author.name.
+	Key   string
+	Value interface{}
+
+	// Where the value was set: theme, project or the language code.
+	Origin string
+}
+
+// MergedConfig returns the values of the params, menu and markup config for
+// the language, merged between the theme, the project and the language, and
+// their origin, sorted by key.
+func MergedConfig(cfg config.Provider, language *helpers.Language) []MergedConfigValue {
+	layers, _ := cfg.Get(configLayersKey).(configLayers)
+
+	var langConfig map[string]interface{}
+	for lang, v := range cfg.GetStringMap("languages") {
+		if lang == language.Lang {
+			langConfig, _ = toConfigMap(v)
+		}
+	}
+
+	var values []MergedConfigValue
+
+	for key, defaultStrategy := range mergedConfigKeys {
+		theme, project, local := layers.theme[key], layers.project[key], langConfig[key]
+
+		merged := mergeConfigValue(project, theme, mergeShallow)
+		origins := mergeConfigValue(withOrigin(project, configOriginProject), withOrigin(theme, configOriginTheme), mergeShallow)
+
+		if local != nil {
+			merged = mergeConfigValue(local, merged, defaultStrategy)
+			origins = mergeConfigValue(withOrigin(local, language.Lang), origins, defaultStrategy)
+		}
+
+		flattenMergedConfig(key, merged, origins, &values)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+
+	return values
+}
+
+func flattenMergedConfig(key string, v, origin interface{}, values *[]MergedConfigValue) {
+	if v == nil {
+		return
+	}
+	m, ok := toConfigMap(v)
+	if !ok {
+		*values = append(*values, MergedConfigValue{Key: key, Value: v, Origin: cast.ToString(origin)})
+		return
+	}
+	om, _ := toConfigMap(origin)
+	for k, vv := range m {
+		flattenMergedConfig(key+"."+k, vv, om[k], values)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigValue(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	lo := map[string]interface{}{
+		"a": "lo",
+		"b": "lo",
+		"m": map[string]interface{}{"x": "lo", "y": "lo"},
+	}
+
+	hi := func(strategy string) map[string]interface{} {
+		m := map[string]interface{}{
+			"a": "hi",
+			"M": map[string]interface{}{"x": "hi"},
+		}
+		if strategy != "" {
+			m["_merge"] = strategy
+		}
+		return m
+	}
+
+	assert.Equal(map[string]interface{}{
+		"a": "hi",
+		"b": "lo",
+		"m": map[string]interface{}{"x": "hi"},
+	}, mergeConfigValue(hi(""), lo, mergeShallow))
+
+	assert.Equal(map[string]interface{}{
+		"a": "hi",
+		"b": "lo",
+		"m": map[string]interface{}{"x": "hi", "y": "lo"},
+	}, mergeConfigValue(hi("deep"), lo, mergeShallow))
+
+	assert.Equal(map[string]interface{}{
+		"a": "hi",
+		"m": map[string]interface{}{"x": "hi"},
+	}, mergeConfigValue(hi(""), lo, mergeNone))
+
+	// The _merge on the lower level applies if not set on the higher.
+	lo["_merge"] = "deep"
+	assert.Equal(map[string]interface{}{
+		"a": "hi",
+		"b": "lo",
+		"m": map[string]interface{}{"x": "hi", "y": "lo"},
+	}, mergeConfigValue(hi(""), lo, mergeNone))
+
+	assert.Equal("hi", mergeConfigValue("hi", lo, mergeDeep))
+	assert.Equal(map[string]interface{}{"a": "lo", "b": "lo", "m": map[string]interface{}{"x": "lo", "y": "lo"}}, mergeConfigValue(nil, lo, mergeDeep))
+
+	assert.NoError(validateMergeStrategy(hi("Deep")))
+	assert.Error(validateMergeStrategy(map[string]interface{}{"m": map[string]interface{}{"_merge": "all"}}))
+}
+
+func TestThemeAndLanguageConfigMerge(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	mf := afero.NewMemMapFs()
+
+	writeToFs(t, mf, "themes/mytheme/config.toml", `
+[params]
+themeParam = "theme"
+author = "Theme Author"
+[params.social]
+twitter = "theme"
+mastodon = "theme"
+
+[menu]
+[[menu.main]]
+name = "Theme"
+url = "/theme/"
+`)
+
+	config := `
+baseURL = "http://example.com/"
+theme = "mytheme"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[params]
+_merge = "deep"
+author = "Project Author"
+[params.social]
+twitter = "project"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.en.params]
+author = "English Author"
+[languages.de]
+weight = 2
+[languages.de.params]
+_merge = "none"
+author = "Deutscher Autor"
+[languages.de.menu]
+_merge = "shallow"
+[[languages.de.menu.footer]]
+name = "Impressum"
+url = "/impressum/"
+`
+
+	_, h := newTestSitesFromConfig(t, mf, config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"layouts/_default/single.html", "Single",
+	)
+	assert.NoError(h.Build(BuildCfg{SkipRender: true}))
+
+	en, de := h.Sites[0], h.Sites[1]
+
+	assert.Equal("English Author", en.Info.Params["author"])
+	assert.Equal("theme", en.Info.Params["themeparam"])
+	assert.Equal(map[string]interface{}{"twitter": "project", "mastodon": "theme"}, en.Info.Params["social"])
+	assert.Len(*en.Menus["main"], 1)
+
+	assert.Equal("Deutscher Autor", de.Info.Params["author"])
+	assert.Nil(de.Info.Params["themeparam"])
+	assert.Nil(de.Info.Params["social"])
+	assert.Len(*de.Menus["main"], 1)
+	assert.Len(*de.Menus["footer"], 1)
+
+	origins := make(map[string]string)
+	for _, v := range MergedConfig(h.Cfg, en.Language) {
+		origins[v.Key] = v.Origin
+	}
+	assert.Equal("en", origins["params.author"])
+	assert.Equal("theme", origins["params.themeparam"])
+	assert.Equal("project", origins["params.social.twitter"])
+	assert.Equal("theme", origins["params.social.mastodon"])
+	assert.Equal("theme", origins["menu.main"])
+
+	origins = make(map[string]string)
+	for _, v := range MergedConfig(h.Cfg, de.Language) {
+		origins[v.Key] = v.Origin
+	}
+	assert.Equal("de", origins["params.author"])
+	assert.Equal("", origins["params.themeparam"])
+	assert.Equal("de", origins["menu.footer"])
+	assert.Equal("theme", origins["menu.main"])
+}
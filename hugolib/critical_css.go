@@ -0,0 +1,79 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/resource"
+	"github.com/gohugoio/hugo/source"
+	"github.com/spf13/afero"
+)
+
+// loadCriticalCSS reads the stylesheet with the given path relative to the
+// site root, e.g. "/css/main.css", for the critical CSS transform, see
+// resources.Critical. It is read from the static dirs or the source of the
+// page resource published at p.
+func (s *Site) loadCriticalCSS(p string) ([]byte, error) {
+	s.criticalCSSInit.Do(func() {
+		dirs, err := source.NewDirs(s.Fs, s.Language, s.Log)
+		if err == nil {
+			s.criticalCSSFs, err = dirs.CreateStaticFs()
+		}
+		if err != nil {
+			s.Log.ERROR.Println("Failed to read the static dirs for the critical CSS:", err)
+		}
+	})
+
+	filename := filepath.FromSlash(p)
+
+	if s.criticalCSSFs != nil {
+		if b, err := afero.ReadFile(s.criticalCSSFs, filename); err == nil {
+			return b, nil
+		}
+	}
+
+	if source, found := s.criticalCSSResourceSource(p); found {
+		return afero.ReadFile(s.Fs.Source, source)
+	}
+
+	return nil, errors.New("stylesheet not found: " + p)
+}
+
+// criticalCSSResourceSource returns the source filename of the page resource
+// published at p, relative to the site root. The resources are collected on
+// first use in a build.
+func (s *Site) criticalCSSResourceSource(p string) (string, bool) {
+	s.criticalCSSMu.Lock()
+	defer s.criticalCSSMu.Unlock()
+
+	if s.criticalCSSResources == nil {
+		s.criticalCSSResources = make(map[string]string)
+		for _, page := range s.rawAllPages {
+			for _, r := range page.Resources {
+				src, ok := r.(resource.Source)
+				if !ok {
+					continue
+				}
+				rel := strings.TrimPrefix(r.RelPermalink(), s.PathSpec.BasePath)
+				s.criticalCSSResources["/"+strings.TrimPrefix(rel, "/")] = src.AbsSourceFilename()
+			}
+		}
+	}
+
+	source, found := s.criticalCSSResources[p]
+	return source, found
+}
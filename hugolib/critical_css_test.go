@@ -0,0 +1,63 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriticalCSS(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"static/css/main.css", `header { margin: 0 } .hero { color: red } .footer { color: blue } .modal { color: green }`,
+		"layouts/_default/single.html", `<html><head>{{ resources.Critical "/css/main.css" (dict "selectors" (slice ".modal") "elements" 2) }}</head><body><header><div class="hero">H</div></header><div class="footer">F</div></body></html>`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/p/index.html",
+		`<style>header{margin: 0}.hero{color: red}.modal{color: green}</style>`,
+		`<link rel="preload" href="/css/main.css" as="style"`,
+		`<noscript><link rel="stylesheet" href="/css/main.css"></noscript>`)
+	require.NotContains(t, readDestination(t, th.Fs, "public/p/index.html"), ".footer{")
+}
+
+func TestCriticalCSSBundleResource(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/docs/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/b/index.md", "---\ntitle: B\n---\n",
+		"content/b/style.css", `.hero { background: url(img/hero.png) } @keyframes fade { to { opacity: 0 } } .footer { color: blue }`,
+		"layouts/_default/single.html", `<html><head>{{ with .Resources.GetByPrefix "style" }}{{ resources.Critical . (dict "elements" 1) }}{{ end }}</head><body><div class="hero">H</div><div class="footer">F</div></body></html>`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/b/index.html",
+		`<style>.hero{background: url("/docs/b/img/hero.png")}@keyframes fade{to { opacity: 0 }}</style>`,
+		`<link rel="preload" href="/docs/b/style.css" as="style"`)
+}
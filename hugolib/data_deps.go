@@ -0,0 +1,440 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"html/template"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gohugoio/hugo/tpl"
+)
+
+// dataKeyAll is used for templates that use .Site.Data in a way we cannot
+// follow, e.g. range over it or pass it to a partial. These depend on all
+// the data.
+const dataKeyAll = "*"
+
+// dataDependencies keeps track of the top level keys in .Site.Data used by
+// each page when rendered, so only the pages depending on a changed data
+// file need to be rendered again in server mode.
+type dataDependencies struct {
+	mu sync.RWMutex
+
+	// Page key => data keys used by its templates.
+	pages map[string]map[string]bool
+
+	// Page key => data keys used by its shortcodes. The content of these
+	// pages must be processed again when the data changes.
+	content map[string]map[string]bool
+
+	// Template name => data keys used, including the partials and
+	// templates it calls.
+	templates map[string]map[string]bool
+}
+
+func newDataDependencies() *dataDependencies {
+	d := &dataDependencies{}
+	d.reset()
+	return d
+}
+
+func (d *dataDependencies) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pages = make(map[string]map[string]bool)
+	d.content = make(map[string]map[string]bool)
+	d.templates = make(map[string]map[string]bool)
+}
+
+// resetTemplates clears the template analysis, needed when the templates
+// change.
+func (d *dataDependencies) resetTemplates() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.templates = make(map[string]map[string]bool)
+}
+
+// dataPageKey returns the key used for the page in the dependency maps. The
+// pages are recreated when the content changes, so we cannot use them as keys.
+func dataPageKey(p *Page) string {
+	return p.Lang() + "|" + p.Kind + "|" + p.RelPermalink()
+}
+
+// addTemplate records that the page is rendered with the given template.
+func (d *dataDependencies) addTemplate(p *Page, templ tpl.Template, finder tpl.TemplateFinder) {
+	if d == nil || p == nil {
+		return
+	}
+	d.add(d.pages, dataPageKey(p), d.templateKeys(templ, finder))
+}
+
+// addShortcode records that the page content is rendered with the given
+// shortcode template.
+func (d *dataDependencies) addShortcode(p *Page, templ tpl.Template, finder tpl.TemplateFinder) {
+	if d == nil || p == nil {
+		return
+	}
+	d.add(d.content, dataPageKey(p), d.templateKeys(templ, finder))
+}
+
+func (d *dataDependencies) add(m map[string]map[string]bool, key string, dataKeys map[string]bool) {
+	if len(dataKeys) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, found := m[key]
+	if !found {
+		keys = make(map[string]bool)
+		m[key] = keys
+	}
+	for k := range dataKeys {
+		keys[k] = true
+	}
+}
+
+// pagesFor returns the keys of the pages depending on any of the given data
+// keys, and whether the content of any of them needs to be processed again.
+func (d *dataDependencies) pagesFor(dataKeys map[string]bool) (pages map[string]bool, contentChanged bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	pages = make(map[string]bool)
+
+	for pageKey, keys := range d.pages {
+		if dependsOn(keys, dataKeys) {
+			pages[pageKey] = true
+		}
+	}
+
+	for pageKey, keys := range d.content {
+		if dependsOn(keys, dataKeys) {
+			pages[pageKey] = true
+			contentChanged = true
+		}
+	}
+
+	return
+}
+
+func dependsOn(keys, dataKeys map[string]bool) bool {
+	if keys[dataKeyAll] || dataKeys[dataKeyAll] {
+		return true
+	}
+	for k := range dataKeys {
+		if keys[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// templateKeys returns the top level keys in .Site.Data used by the template.
+func (d *dataDependencies) templateKeys(templ tpl.Template, finder tpl.TemplateFinder) map[string]bool {
+	if templ == nil {
+		return nil
+	}
+
+	d.mu.RLock()
+	keys, found := d.templates[templ.Name()]
+	d.mu.RUnlock()
+	if found {
+		return keys
+	}
+
+	keys = make(map[string]bool)
+	w := newTemplateWalker(templ, finder)
+	w.unknown = func() { keys[dataKeyAll] = true }
+	w.command = func(n *parse.CommandNode) bool {
+		// index .Site.Data "key"
+		fn, ok := n.Args[0].(*parse.IdentifierNode)
+		if !ok || fn.Ident != "index" || len(n.Args) < 3 || !isSiteData(n.Args[1]) {
+			return false
+		}
+		if key, ok := n.Args[2].(*parse.StringNode); ok {
+			keys[key.Text] = true
+		} else {
+			keys[dataKeyAll] = true
+		}
+		for _, arg := range n.Args[3:] {
+			w.walk(arg)
+		}
+		return true
+	}
+	w.ident = func(ident []string) {
+		collectDataKeys(keys, ident)
+	}
+	w.walkTemplate(templ.Name(), templateTree(templ, ""))
+
+	d.mu.Lock()
+	d.templates[templ.Name()] = keys
+	d.mu.Unlock()
+
+	return keys
+}
+
+// templateWalker walks the parse trees of a template and the templates and
+// partials it calls.
+type templateWalker struct {
+	root   tpl.Template
+	finder tpl.TemplateFinder
+	seen   map[string]bool
+
+	// Called for every field or variable chain, e.g. .Site.Data.key.
+	ident func(ident []string)
+
+	// Called for every command. If it returns true, the arguments are not
+	// walked.
+	command func(n *parse.CommandNode) bool
+
+	// Called when the walker cannot follow a template call, e.g. a partial
+	// with a dynamic name.
+	unknown func()
+}
+
+func newTemplateWalker(root tpl.Template, finder tpl.TemplateFinder) *templateWalker {
+	return &templateWalker{
+		root:    root,
+		finder:  finder,
+		seen:    make(map[string]bool),
+		ident:   func([]string) {},
+		command: func(*parse.CommandNode) bool { return false },
+		unknown: func() {},
+	}
+}
+
+func (w *templateWalker) walkTemplate(name string, tree *parse.Tree) {
+	if w.seen[name] {
+		return
+	}
+	w.seen[name] = true
+
+	if tree == nil || tree.Root == nil {
+		return
+	}
+
+	w.walk(tree.Root)
+}
+
+func (w *templateWalker) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, nn := range n.Nodes {
+			w.walk(nn)
+		}
+	case *parse.ActionNode:
+		w.walk(n.Pipe)
+	case *parse.IfNode:
+		w.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		w.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		w.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		w.walk(n.Pipe)
+		w.walkTemplate(n.Name, w.lookup(n.Name))
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			w.walk(cmd)
+		}
+	case *parse.CommandNode:
+		w.walkCommand(n)
+	case *parse.FieldNode:
+		w.ident(n.Ident)
+	case *parse.VariableNode:
+		w.ident(n.Ident)
+	case *parse.ChainNode:
+		w.walk(n.Node)
+	}
+}
+
+func (w *templateWalker) walkBranch(n *parse.BranchNode) {
+	w.walk(n.Pipe)
+	w.walk(n.List)
+	if n.ElseList != nil {
+		w.walk(n.ElseList)
+	}
+}
+
+func (w *templateWalker) walkCommand(n *parse.CommandNode) {
+	if len(n.Args) == 0 {
+		return
+	}
+
+	if w.command(n) {
+		return
+	}
+
+	if fn, ok := n.Args[0].(*parse.IdentifierNode); ok {
+		switch fn.Ident {
+		case "partial", "partialCached":
+			if len(n.Args) > 1 {
+				if name, ok := n.Args[1].(*parse.StringNode); ok {
+					partial := "partials/" + name.Text
+					w.walkTemplate(partial, w.lookup(partial))
+				} else {
+					// We don't know which partial is called.
+					w.unknown()
+				}
+			}
+		}
+	}
+
+	for _, arg := range n.Args {
+		w.walk(arg)
+	}
+}
+
+// collectDataKeys records the data key in field chains such as
+// .Site.Data.key or $.Page.Site.Data.key.
+func collectDataKeys(keys map[string]bool, ident []string) {
+	if len(ident) > 0 && ident[len(ident)-1] == "Render" {
+		// The content view templates executed by .Render are
+		// looked up when executed.
+		keys[dataKeyAll] = true
+		return
+	}
+
+	for i := 0; i < len(ident)-1; i++ {
+		if ident[i] == "Site" && ident[i+1] == "Data" {
+			if i+2 < len(ident) {
+				keys[ident[i+2]] = true
+			} else {
+				keys[dataKeyAll] = true
+			}
+			return
+		}
+	}
+}
+
+func (w *templateWalker) lookup(name string) *parse.Tree {
+	if tree := templateTree(w.root, name); tree != nil {
+		return tree
+	}
+	if w.finder == nil {
+		return nil
+	}
+	if templ := w.finder.Lookup(name); templ != nil {
+		return templateTree(templ, "")
+	}
+	return nil
+}
+
+// isSiteData returns whether the node is a field chain ending in .Site.Data.
+func isSiteData(node parse.Node) bool {
+	var ident []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		ident = n.Ident
+	case *parse.VariableNode:
+		ident = n.Ident
+	default:
+		return false
+	}
+	l := len(ident)
+	return l >= 2 && ident[l-2] == "Site" && ident[l-1] == "Data"
+}
+
+// templateTree returns the parse tree of the template, or of the template
+// with the given name associated with it.
+func templateTree(templ tpl.Template, name string) *parse.Tree {
+	switch t := templ.(type) {
+	case *tpl.TemplateAdapter:
+		return templateTree(t.Template, name)
+	case *template.Template:
+		if name != "" {
+			if t = t.Lookup(name); t == nil {
+				return nil
+			}
+		}
+		return t.Tree
+	case *texttemplate.Template:
+		if name != "" {
+			if t = t.Lookup(name); t == nil {
+				return nil
+			}
+		}
+		return t.Tree
+	}
+	return nil
+}
+
+// dataKeyForFilename returns the top level key in .Site.Data for the changed
+// data file, i.e. its first path element below the data dir.
+func dataKeyForFilename(dataDir, filename string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(filename, dataDir), string(filepath.Separator))
+	if rel == "" {
+		return dataKeyAll
+	}
+	first := strings.Split(rel, string(filepath.Separator))[0]
+	return strings.TrimSuffix(first, filepath.Ext(first))
+}
+
+// dataKeyForRemoteEvent returns the top level key in .Site.Data for the
+// remote data in the event, see RemoteDataChangedEvent.
+func dataKeyForRemoteEvent(e fsnotify.Event) string {
+	return strings.Split(strings.TrimPrefix(e.Name, remoteDataEventPrefix), ".")[0]
+}
+
+func (s *Site) dataDeps() *dataDependencies {
+	if s.owner == nil || !s.running() {
+		return nil
+	}
+	return s.owner.dataDeps
+}
+
+// dataKeysChanged returns the top level keys in .Site.Data changed by the
+// data events.
+func (s *Site) dataKeysChanged(events []fsnotify.Event) map[string]bool {
+	keys := make(map[string]bool)
+	for _, ev := range events {
+		if isRemoteDataEvent(ev) {
+			keys[dataKeyForRemoteEvent(ev)] = true
+			continue
+		}
+		dir := s.getDataDir(ev.Name)
+		if dir == "" {
+			dir = s.getThemeDataDir(ev.Name)
+		}
+		keys[dataKeyForFilename(dir, ev.Name)] = true
+	}
+	return keys
+}
+
+// findPagesByDataKeys returns the content pages with the given page keys,
+// see dataPageKey.
+func (h *HugoSites) findPagesByDataKeys(pageKeys map[string]bool) Pages {
+	var pages Pages
+	for _, s := range h.Sites {
+		for _, p := range s.rawAllPages {
+			if p.File != nil && pageKeys[dataPageKey(p)] {
+				pages = append(pages, p)
+			}
+		}
+	}
+	return pages
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataDependencies(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["section", "taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", "---\ntitle: P1\n---\n",
+		"content/p2.md", "---\ntitle: P2\n---\nShortcode: {{< sc >}}",
+		"data/a.toml", `v = "a1"`,
+		"data/b.toml", `v = "b1"`,
+		"data/c.toml", `v = "c1"`,
+		"data/d/e.toml", `v = "d1"`,
+		"layouts/index.html", `Home: {{ .Site.Data.a.v }}|{{ partial "p.html" . }}`,
+		"layouts/partials/p.html", `{{ (index .Site.Data "b").v }}`,
+		"layouts/_default/single.html", `Single: {{ if eq .Title "P1" }}{{ $.Site.Data.c.v }}{{ end }}|{{ .Content }}`,
+		"layouts/shortcodes/sc.html", `{{ .Page.Site.Data.d.e.v }}`,
+	)
+
+	h.running = true
+	h.ContentChanges = &contentChangeMap{symContent: make(map[string]map[string]bool)}
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "Home: a1|b1")
+	th.assertFileContent("public/p1/index.html", "Single: c1")
+	th.assertFileContent("public/p2/index.html", "Shortcode: d1")
+
+	s := h.Sites[0]
+	home := dataPageKey(s.getPage(KindHome))
+	p1 := dataPageKey(s.RegularPages[0])
+	p2 := dataPageKey(s.RegularPages[1])
+
+	for i, test := range []struct {
+		keys           []string
+		expectPages    []string
+		contentChanged bool
+	}{
+		{[]string{"a"}, []string{home}, false},
+		{[]string{"b"}, []string{home}, false},
+		{[]string{"c"}, []string{p1, p2}, false},
+		{[]string{"d"}, []string{p2}, true},
+		{[]string{"zz"}, nil, false},
+	} {
+		keys := make(map[string]bool)
+		for _, k := range test.keys {
+			keys[k] = true
+		}
+		pages, contentChanged := h.dataDeps.pagesFor(keys)
+		assert.Len(pages, len(test.expectPages), "[%d]", i)
+		for _, p := range test.expectPages {
+			assert.True(pages[p], "[%d] %s", i, p)
+		}
+		assert.Equal(test.contentChanged, contentChanged, "[%d]", i)
+	}
+
+	// Only the home page depends on data/b.
+	assert.NoError(th.Fs.Destination.Remove("public/p1/index.html"))
+	writeSource(t, th.Fs, "data/b.toml", `v = "b2"`)
+	assert.NoError(h.Build(BuildCfg{}, fsnotify.Event{Name: filepath.Join(s.absDataDir(), "b.toml"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/index.html", "Home: a1|b2")
+	th.assertFileNotExist("public/p1/index.html")
+
+	// The shortcode in p2 depends on data/d.
+	writeSource(t, th.Fs, "data/d/e.toml", `v = "d2"`)
+	assert.NoError(h.Build(BuildCfg{}, fsnotify.Event{Name: filepath.Join(s.absDataDir(), "d", "e.toml"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/p2/index.html", "Shortcode: d2")
+}
+
+func TestDataKeyForFilename(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	dataDir := filepath.FromSlash("/work/data")
+
+	assert.Equal("a", dataKeyForFilename(dataDir, filepath.FromSlash("/work/data/a.toml")))
+	assert.Equal("api", dataKeyForFilename(dataDir, filepath.FromSlash("/work/data/api/v1/b.json")))
+	assert.Equal(dataKeyAll, dataKeyForFilename(dataDir, dataDir))
+	assert.Equal("api", dataKeyForRemoteEvent(RemoteDataChangedEvent("api.pricing")))
+}
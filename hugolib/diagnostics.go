@@ -0,0 +1,184 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+const (
+	// DiagnosticContentInStatic is reported for content files, e.g. Markdown,
+	// found in a static dir. These are copied as is and never rendered.
+	DiagnosticContentInStatic = "contentInStatic"
+
+	// DiagnosticTemplatesInContent is reported for files in the content dir
+	// that look like templates. Templates belong in the layouts dir.
+	DiagnosticTemplatesInContent = "templatesInContent"
+)
+
+// The levels for the diagnostics, set in the site config, e.g.:
+//
+//   [diagnostics]
+//   contentInStatic = "error"
+//   templatesInContent = "ignore"
+const (
+	diagnosticLevelIgnore = "ignore"
+	diagnosticLevelWarn   = "warn"
+	diagnosticLevelError  = "error"
+)
+
+var templateActionRe = regexp.MustCompile(`{{-?\s*(define|block|partial|template|range|with|if|end|\.)`)
+
+// Diagnostic is a likely misplaced file found by the diagnostics pass.
+type Diagnostic struct {
+	// One of DiagnosticContentInStatic or DiagnosticTemplatesInContent.
+	ID       string
+	Filename string
+	Message  string
+}
+
+// FindMisplacedFiles looks for files that are likely in the wrong place, i.e.
+// content files in the static dirs and templates in the content dirs.
+func (h *HugoSites) FindMisplacedFiles() ([]Diagnostic, error) {
+	var (
+		diagnostics []Diagnostic
+		staticDirs  = make(map[string]bool)
+		contentDirs = make(map[string]bool)
+		fs          = h.Fs.Source
+	)
+
+	for _, s := range h.Sites {
+		for _, dir := range s.PathSpec.StaticDirs() {
+			staticDirs[s.PathSpec.AbsPathify(dir)] = true
+		}
+		if dir, err := s.PathSpec.GetThemeStaticDirPath(); err == nil && dir != "" {
+			staticDirs[dir] = true
+		}
+		contentDirs[s.PathSpec.AbsPathify(s.PathSpec.ContentDir())] = true
+	}
+
+	for _, dir := range sortedKeys(staticDirs) {
+		err := walkExisting(fs, dir, func(filename string, info os.FileInfo) error {
+			if markup := helpers.GuessType(strings.TrimPrefix(helpers.Ext(filename), ".")); markup != "unknown" && markup != "html" {
+				diagnostics = append(diagnostics, Diagnostic{
+					ID:       DiagnosticContentInStatic,
+					Filename: filename,
+					Message:  "content file in static dir will be copied as is and not rendered; move it to the content dir",
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range sortedKeys(contentDirs) {
+		err := walkExisting(fs, dir, func(filename string, info os.FileInfo) error {
+			if helpers.GuessType(strings.TrimPrefix(helpers.Ext(filename), ".")) != "html" {
+				return nil
+			}
+			b, err := afero.ReadFile(fs, filename)
+			if err != nil {
+				return err
+			}
+			if templateActionRe.Match(b) {
+				diagnostics = append(diagnostics, Diagnostic{
+					ID:       DiagnosticTemplatesInContent,
+					Filename: filename,
+					Message:  "file in content dir looks like a template, but templates in content are not executed; move it to the layouts dir",
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// checkMisplacedFiles logs the diagnostics found by FindMisplacedFiles
+// according to their configured level. It returns an error if any of them
+// is configured to be an error.
+func (h *HugoSites) checkMisplacedFiles() error {
+	diagnostics, err := h.FindMisplacedFiles()
+	if err != nil {
+		return err
+	}
+
+	var numErrors int
+
+	for _, d := range diagnostics {
+		switch h.diagnosticLevel(d.ID) {
+		case diagnosticLevelIgnore:
+		case diagnosticLevelError:
+			numErrors++
+			h.Log.ERROR.Printf("[%s] %s: %s", d.ID, d.Filename, d.Message)
+		default:
+			h.Log.WARN.Printf("[%s] %s: %s", d.ID, d.Filename, d.Message)
+		}
+	}
+
+	if numErrors > 0 {
+		return fmt.Errorf("found %d misplaced file(s), see the diagnostics config to change this check", numErrors)
+	}
+
+	return nil
+}
+
+func (h *HugoSites) diagnosticLevel(id string) string {
+	levels := h.Cfg.GetStringMapString("diagnostics")
+	level := strings.ToLower(levels[strings.ToLower(id)])
+	switch level {
+	case diagnosticLevelIgnore, diagnosticLevelError:
+		return level
+	default:
+		return diagnosticLevelWarn
+	}
+}
+
+// walkExisting walks the files in dir, if it exists.
+func walkExisting(fs afero.Fs, dir string, walkFn func(filename string, info os.FileInfo) error) error {
+	if exists, _ := helpers.Exists(dir, fs); !exists {
+		return nil
+	}
+
+	return helpers.SymbolicWalk(fs, dir, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return walkFn(filename, info)
+	})
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMisplacedFiles(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cfg, fs := newTestCfg()
+
+	writeSource(t, fs, filepath.Join("content", "page.md"), "---\ntitle: Page\n---\nContent")
+	writeSource(t, fs, filepath.Join("content", "plain.html"), "---\ntitle: Plain\n---\n<p>Some {{ braces }}</p>")
+	writeSource(t, fs, filepath.Join("content", "single.html"), `<h1>{{ .Title }}</h1>`)
+	writeSource(t, fs, filepath.Join("static", "docs", "readme.md"), "# Readme")
+	writeSource(t, fs, filepath.Join("static", "css", "main.css"), "body {}")
+	writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), "{{ .Content }}")
+
+	h, err := NewHugoSites(deps.DepsCfg{Fs: fs, Cfg: cfg})
+	assert.NoError(err)
+
+	diagnostics, err := h.FindMisplacedFiles()
+	assert.NoError(err)
+	assert.Len(diagnostics, 2)
+
+	assert.Equal(DiagnosticContentInStatic, diagnostics[0].ID)
+	assert.Equal(filepath.Join("static", "docs", "readme.md"), diagnostics[0].Filename[len(diagnostics[0].Filename)-len(filepath.Join("static", "docs", "readme.md")):])
+	assert.Equal(DiagnosticTemplatesInContent, diagnostics[1].ID)
+	assert.Contains(diagnostics[1].Filename, "single.html")
+
+	// Warnings by default.
+	assert.NoError(h.Build(BuildCfg{}))
+
+	cfg.Set("diagnostics", map[string]interface{}{"templatesInContent": "ignore", "contentInStatic": "error"})
+	assert.Equal(diagnosticLevelIgnore, h.diagnosticLevel(DiagnosticTemplatesInContent))
+	assert.Error(h.checkMisplacedFiles())
+
+	cfg.Set("diagnostics", map[string]interface{}{"contentInStatic": "ignore"})
+	assert.Equal(diagnosticLevelWarn, h.diagnosticLevel(DiagnosticTemplatesInContent))
+	assert.NoError(h.checkMisplacedFiles())
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableLanguages(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+disableLanguages = ["fr", "sv"]
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+title = "Le Site"
+[languages.sv]
+weight = 3
+[languages.nn]
+weight = 4
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a.md", "---\ntitle: A\n---\n",
+		"content/a.fr.md", "---\ntitle: A fr\n---\n",
+		"content/b.fr.md", "---\ntitle: B fr\n---\n",
+		"content/a.nn.md", "---\ntitle: A nn\n---\n",
+		"layouts/_default/single.html", `{{ .Title }}|{{ range .Translations }}{{ .Lang }}|{{ end }}`,
+		"layouts/index.html", `{{ range .Site.Languages }}{{ .Lang }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	assert.Len(h.Sites, 2)
+	assert.Equal("en", h.Sites[0].Language.Lang)
+	assert.Equal("nn", h.Sites[1].Language.Lang)
+	assert.Len(h.Sites[0].RegularPages, 1)
+
+	th.assertFileContent("public/a/index.html", "A|nn|")
+	th.assertFileContent("public/nn/a/index.html", "A nn|en|")
+	th.assertFileContent("public/index.html", "en|nn|")
+	th.assertFileNotExist("public/fr/a/index.html")
+	th.assertFileNotExist("public/fr/index.html")
+}
+
+func TestDisableDefaultLanguage(t *testing.T) {
+	t.Parallel()
+
+	v := viper.New()
+	v.Set("defaultContentLanguage", "en")
+	v.Set("disableLanguages", []string{"en"})
+	v.Set("languages", map[string]interface{}{
+		"en": map[string]interface{}{"weight": 1},
+		"fr": map[string]interface{}{"weight": 2},
+	})
+
+	err := loadLanguageSettings(v, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot be disabled")
+}
+
+func TestRenderSitesCollectsErrors(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languages.de]
+weight = 3
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a.md", "---\ntitle: A\n---\n",
+		"layouts/_default/single.html", `{{ .Title }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{SkipRender: true}))
+
+	errs := map[string]error{"fr": errors.New("fr failed"), "de": errors.New("de failed")}
+	err := collectSiteErrors(h.Sites, func(s *Site) error {
+		return errs[s.Language.Lang]
+	})
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to render 2 languages")
+	assert.Contains(err.Error(), "fr: fr failed")
+	assert.Contains(err.Error(), "de: de failed")
+	assert.False(strings.Contains(err.Error(), "en:"))
+
+	err = collectSiteErrors(h.Sites, func(s *Site) error {
+		return errs[s.Language.Lang+"-nope"]
+	})
+	assert.NoError(err)
+
+	single := errors.New("fr failed")
+	err = collectSiteErrors(h.Sites, func(s *Site) error {
+		if s.Language.Lang == "fr" {
+			return single
+		}
+		return nil
+	})
+	assert.Equal(single, err)
+}
@@ -0,0 +1,249 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// DuplicateTitle is used for pages sharing the same title.
+	DuplicateTitle = "title"
+
+	// DuplicatePermalink is used for pages that end up with the same permalink.
+	DuplicatePermalink = "permalink"
+
+	// DuplicateContent is used for pages with identical or near identical content.
+	DuplicateContent = "content"
+
+	// Pages with less words than this are not checked for duplicate content.
+	duplicateContentMinWords = 10
+
+	// The maximum number of differing bits in the content fingerprints of
+	// two pages considered to be near duplicates.
+	duplicateContentMaxDistance = 3
+
+	duplicateContentShingleSize = 3
+)
+
+// Duplicates represents a group of pages in a site that look like duplicates.
+type Duplicates struct {
+	// Why these pages are considered to be duplicates, one of DuplicateTitle,
+	// DuplicatePermalink or DuplicateContent.
+	Reason string
+
+	// The shared title or permalink. Empty for DuplicateContent.
+	Key string
+
+	Pages Pages
+}
+
+// FindDuplicates returns the groups of pages with identical titles, identical
+// permalinks or near identical content, within the same language.
+// This must be run after the content is rendered.
+func (h *HugoSites) FindDuplicates() []Duplicates {
+	var dups []Duplicates
+	for _, s := range h.Sites {
+		dups = append(dups, s.findDuplicates()...)
+	}
+	return dups
+}
+
+// PrintDuplicatesReport writes a report of the duplicate pages found to w and
+// returns the number of duplicate groups.
+func (h *HugoSites) PrintDuplicatesReport(w io.Writer) int {
+	dups := h.FindDuplicates()
+
+	for _, d := range dups {
+		var lang string
+		if h.multilingual != nil && len(h.Sites) > 1 {
+			lang = fmt.Sprintf(" (%s)", d.Pages[0].Lang())
+		}
+
+		if d.Key != "" {
+			fmt.Fprintf(w, "Duplicate %s %q%s:\n", d.Reason, d.Key, lang)
+		} else {
+			fmt.Fprintf(w, "Duplicate %s%s:\n", d.Reason, lang)
+		}
+
+		for _, p := range d.Pages {
+			fmt.Fprintf(w, "  %s\n", p.pathOrTitle())
+		}
+	}
+
+	return len(dups)
+}
+
+func (s *Site) findDuplicates() []Duplicates {
+	var (
+		dups       []Duplicates
+		titles     = make(map[string]Pages)
+		permalinks = make(map[string]Pages)
+		content    Pages
+	)
+
+	for _, p := range s.Pages {
+		if p.Path() == "" {
+			// Not backed by a content file.
+			continue
+		}
+
+		permalink := strings.ToLower(p.RelPermalink())
+		permalinks[permalink] = append(permalinks[permalink], p)
+
+		if p.Kind != KindPage {
+			continue
+		}
+
+		if title := strings.TrimSpace(p.Title); title != "" {
+			titles[title] = append(titles[title], p)
+		}
+
+		content = append(content, p)
+	}
+
+	dups = append(dups, duplicatesFromMap(DuplicateTitle, titles)...)
+	dups = append(dups, duplicatesFromMap(DuplicatePermalink, permalinks)...)
+	dups = append(dups, findDuplicateContent(content)...)
+
+	return dups
+}
+
+func duplicatesFromMap(reason string, m map[string]Pages) []Duplicates {
+	var dups []Duplicates
+	for k, pages := range m {
+		if len(pages) < 2 {
+			continue
+		}
+		dups = append(dups, Duplicates{Reason: reason, Key: k, Pages: sortedByPath(pages)})
+	}
+
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i].Key < dups[j].Key
+	})
+
+	return dups
+}
+
+func findDuplicateContent(pages Pages) []Duplicates {
+	var (
+		candidates   Pages
+		fingerprints []uint64
+	)
+
+	for _, p := range pages {
+		words := p.PlainWords()
+		if len(words) < duplicateContentMinWords {
+			continue
+		}
+		candidates = append(candidates, p)
+		fingerprints = append(fingerprints, simhash(words))
+	}
+
+	// Group the near identical pages using a simple union-find.
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			if hammingDistance(fingerprints[i], fingerprints[j]) <= duplicateContentMaxDistance {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int]Pages)
+	for i, p := range candidates {
+		root := find(i)
+		groups[root] = append(groups[root], p)
+	}
+
+	var dups []Duplicates
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		dups = append(dups, Duplicates{Reason: DuplicateContent, Pages: sortedByPath(group)})
+	}
+
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i].Pages[0].Path() < dups[j].Pages[0].Path()
+	})
+
+	return dups
+}
+
+func sortedByPath(pages Pages) Pages {
+	sorted := make(Pages, len(pages))
+	copy(sorted, pages)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path() < sorted[j].Path()
+	})
+	return sorted
+}
+
+// simhash creates a 64 bit fingerprint of the given words where similar
+// texts get fingerprints with few differing bits.
+func simhash(words []string) uint64 {
+	var v [64]int
+
+	h := fnv.New64a()
+
+	for i := 0; i+duplicateContentShingleSize <= len(words); i++ {
+		h.Reset()
+		for _, w := range words[i : i+duplicateContentShingleSize] {
+			h.Write([]byte(strings.ToLower(w)))
+			h.Write([]byte{' '})
+		}
+		sum := h.Sum64()
+		for b := uint(0); b < 64; b++ {
+			if sum&(1<<b) != 0 {
+				v[b]++
+			} else {
+				v[b]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for b := uint(0); b < 64; b++ {
+		if v[b] > 0 {
+			fingerprint |= 1 << b
+		}
+	}
+
+	return fingerprint
+}
+
+func hammingDistance(a, b uint64) int {
+	var count int
+	for x := a ^ b; x != 0; x &= x - 1 {
+		count++
+	}
+	return count
+}
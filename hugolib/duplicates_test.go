@@ -0,0 +1,86 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	th, h := newTestSitesFromConfigWithDefaultTemplates(t, `baseURL = "http://example.com/"`)
+	fs := th.Fs
+
+	text := `Hugo is a static site generator written in Go. It is optimized for speed, easy use and
+configurability. Hugo takes a directory with content and templates and renders them into a full HTML
+website. Hugo relies on Markdown files with front matter for meta data, and you can run Hugo from any
+directory. This works well for shared hosts and other systems where you don't have a privileged account.
+Hugo renders a typical website of moderate size in a fraction of a second. A good rule of thumb is that
+each piece of content renders in around 1 millisecond. Hugo is designed to work well for any kind of website
+including blogs, tumbles, and docs.`
+
+	pageTemplate := `---
+title: %q
+%s
+---
+%s
+`
+
+	writeSource(t, fs, "content/sect/p1.md", fmt.Sprintf(pageTemplate, "Same", "", text))
+	writeSource(t, fs, "content/sect/p2.md", fmt.Sprintf(pageTemplate, "Same", "", "Unique content for this page that does not look like anything else at all in here."))
+	// Near duplicate of p1 with one word changed.
+	writeSource(t, fs, "content/sect/p3.md", fmt.Sprintf(pageTemplate, "P3", "", text+" Indeed."))
+	writeSource(t, fs, "content/other/p4.md", fmt.Sprintf(pageTemplate, "P4", `url: "/sect/p1/"`, "Short."))
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	dups := h.FindDuplicates()
+	assert.Len(dups, 3)
+
+	assert.Equal(DuplicateTitle, dups[0].Reason)
+	assert.Equal("Same", dups[0].Key)
+	assert.Equal(filepath.FromSlash("sect/p1.md"), dups[0].Pages[0].Path())
+	assert.Equal(filepath.FromSlash("sect/p2.md"), dups[0].Pages[1].Path())
+
+	assert.Equal(DuplicatePermalink, dups[1].Reason)
+	assert.Equal("/sect/p1/", dups[1].Key)
+	assert.Len(dups[1].Pages, 2)
+
+	assert.Equal(DuplicateContent, dups[2].Reason)
+	assert.Equal(filepath.FromSlash("sect/p1.md"), dups[2].Pages[0].Path())
+	assert.Equal(filepath.FromSlash("sect/p3.md"), dups[2].Pages[1].Path())
+
+	var b bytes.Buffer
+	assert.Equal(3, h.PrintDuplicatesReport(&b))
+	assert.Contains(b.String(), "Duplicate title \"Same\":\n")
+}
+
+func TestSimhash(t *testing.T) {
+	t.Parallel()
+
+	a := simhash([]string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"})
+	b := simhash([]string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "L"})
+	c := simhash([]string{"m", "n", "o", "p", "q", "r", "s", "t", "u", "v", "w", "x"})
+
+	require.Equal(t, 0, hammingDistance(a, b))
+	require.True(t, hammingDistance(a, c) > duplicateContentMaxDistance)
+}
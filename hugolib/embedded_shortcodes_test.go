@@ -14,9 +14,13 @@
 package hugolib
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -228,6 +232,134 @@ title: Shorty
 
 }
 
+func TestShortcodePrivacy(t *testing.T) {
+	t.Parallel()
+
+	for _, this := range []struct {
+		in        string
+		privacy   map[string]interface{}
+		remote    map[string]string
+		published map[string]string
+		expected  string
+	}{
+		{
+			`{{< youtube w7Ft2ymGmfc >}}`,
+			map[string]interface{}{"youtube": map[string]interface{}{"privacyEnhanced": true}},
+			nil, nil,
+			"(?s)<iframe src=\"//www.youtube-nocookie.com/embed/w7Ft2ymGmfc\"",
+		},
+		{
+			`{{< youtube id="w7Ft2ymGmfc" >}}`,
+			map[string]interface{}{"youtube": map[string]interface{}{"simple": true}},
+			map[string]string{"https://i.ytimg.com/vi/w7Ft2ymGmfc/hqdefault.jpg": "thumbnail"},
+			map[string]string{filepath.Join("public", "images", "youtube", "w7Ft2ymGmfc.jpg"): "thumbnail"},
+			"(?s)^<div class=\"youtube\"><a href=\"https://www.youtube.com/watch\\?v=w7Ft2ymGmfc\"><img src=\"/images/youtube/w7Ft2ymGmfc.jpg\" alt=\"YouTube Video\" /></a></div>",
+		},
+		{
+			`{{< vimeo 146022717 >}}`,
+			map[string]interface{}{"vimeo": map[string]interface{}{"enableDNT": true}},
+			nil, nil,
+			"(?s)<iframe src=\"//player.vimeo.com/video/146022717\\?dnt=1\"",
+		},
+		{
+			`Before{{< vimeo 146022717 >}}After`,
+			map[string]interface{}{"vimeo": map[string]interface{}{"disable": true}},
+			nil, nil,
+			"(?s)^<p>BeforeAfter</p>",
+		},
+	} {
+		var (
+			cfg, fs = newTestCfg()
+			th      = testHelper{cfg, fs, t}
+		)
+
+		cfg.Set("privacy", this.privacy)
+
+		// Serve the remote files from the getJSON cache.
+		for url, content := range this.remote {
+			hash := md5.Sum([]byte(url))
+			writeSource(t, fs, cfg.GetString("cacheDir")+hex.EncodeToString(hash[:]), content)
+		}
+
+		writeSource(t, fs, filepath.Join("content", "simple.md"), fmt.Sprintf(`---
+title: Shorty
+---
+%s`, this.in))
+		writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), `{{ .Content }}`)
+
+		buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{})
+
+		th.assertFileContentRegexp(filepath.Join("public", "simple", "index.html"), this.expected)
+
+		for filename, content := range this.published {
+			th.assertFileContent(filename, content)
+		}
+	}
+}
+
+func TestShortcodeEmbed(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "http://example.org/videos/1":
+			w.Write([]byte(`{"type": "video", "html": "<iframe src=\"http://example.org/player/1\"></iframe>"}`))
+		case "http://example.org/photos/1":
+			w.Write([]byte(`{"type": "photo", "url": "http://example.org/photo.jpg", "title": "Sunset", "width": 640, "height": 480}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	for _, this := range []struct {
+		in, expected string
+	}{
+		{
+			`{{< embed "http://example.org/videos/1" >}}`,
+			"(?s)^<div class=\"embed embed-video\"><iframe src=\"http://example.org/player/1\"></iframe></div>",
+		},
+		{
+			`{{< embed url="http://example.org/photos/1" >}}`,
+			"(?s)^<div class=\"embed embed-photo\"><a href=\"http://example.org/photos/1\"><img src=\"http://example.org/photo.jpg\" alt=\"Sunset\" width=\"640\" height=\"480\" /></a></div>",
+		},
+		// Provider unreachable.
+		{
+			`{{< embed "http://example.org/videos/2" >}}`,
+			"(?s)^<p class=\"embed embed-fallback\"><a href=\"http://example.org/videos/2\">http://example.org/videos/2</a></p>",
+		},
+		// Not allowed.
+		{
+			`{{< embed "https://vimeo.com/146022717" >}}`,
+			"(?s)^<p class=\"embed embed-fallback\"><a href=\"https://vimeo.com/146022717\">https://vimeo.com/146022717</a></p>",
+		},
+	} {
+		var (
+			cfg, fs = newTestCfg()
+			th      = testHelper{cfg, fs, t}
+		)
+
+		cfg.Set("security", map[string]interface{}{
+			"oembed": map[string]interface{}{
+				"allow": []string{"example"},
+				"providers": []map[string]interface{}{
+					{"name": "example", "endpoint": srv.URL + "/oembed", "schemes": []string{"http://example.org/*"}},
+				},
+			},
+		})
+
+		writeSource(t, fs, filepath.Join("content", "simple.md"), fmt.Sprintf(`---
+title: Shorty
+---
+%s`, this.in))
+		writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), `{{ .Content }}`)
+
+		buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{})
+
+		th.assertFileContentRegexp(filepath.Join("public", "simple", "index.html"), this.expected)
+	}
+}
+
 func TestShortcodeVimeo(t *testing.T) {
 	t.Parallel()
 
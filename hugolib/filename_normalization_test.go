@@ -0,0 +1,58 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilenameNormalization(t *testing.T) {
+	t.Parallel()
+
+	// "été" created on macOS (NFD) and on Linux (NFC).
+	nfd := "\x65\xcc\x81t\x65\xcc\x81"
+	nfc := "été"
+
+	var logBuf bytes.Buffer
+
+	cfg, fs := newTestCfg()
+
+	writeSource(t, fs, filepath.Join("content", "bundle", nfd, "index.md"), "---\ntitle: Bundle\n---\n")
+	writeSource(t, fs, filepath.Join("content", "bundle", nfd, nfd+".png"), "PNG")
+	writeSource(t, fs, filepath.Join("content", "dup", nfd+".md"), "---\ntitle: NFD\n---\n")
+	writeSource(t, fs, filepath.Join("content", "dup", nfc+".md"), "---\ntitle: NFC\n---\n")
+	writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), "{{ .Title }}|{{ .RelPermalink }}|{{ range .Resources }}{{ .RelPermalink }}{{ end }}")
+
+	s := buildSingleSite(t, deps.DepsCfg{
+		Fs:     fs,
+		Cfg:    cfg,
+		Logger: jww.NewNotepad(jww.LevelWarn, jww.LevelError, &logBuf, ioutil.Discard, "", log.Ldate|log.Ltime)},
+		BuildCfg{})
+
+	th := testHelper{s.Cfg, s.Fs, t}
+
+	// The URLs are escaped NFC.
+	esc := "%C3%A9t%C3%A9"
+	th.assertFileContent("public/bundle/"+nfc+"/index.html", "Bundle|/bundle/"+esc+"/|/bundle/"+esc+"/"+esc+".png")
+
+	require.Contains(t, logBuf.String(), "differ only by Unicode normalization form")
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/resource"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+)
+
+// FontSubset configures a font to subset to the characters used in the
+// rendered pages, e.g. to make CJK fonts a lot smaller:
+//
+//   [[fontSubsets]]
+//   source = "assets/fonts/NotoSansJP-Regular.ttf"
+//   target = "fonts/NotoSansJP-Regular.woff"
+//   text = "0123456789"
+type FontSubset struct {
+	// The TrueType font, relative to the working dir.
+	Source string
+
+	// Where to write the subset, relative to the publish dir. Use the .woff
+	// extension to get a WOFF.
+	Target string
+
+	// Characters to always include, e.g. for text set by JavaScript.
+	Text string
+}
+
+func decodeFontSubsets(cfg config.Provider) ([]FontSubset, error) {
+	v := cfg.Get("fontSubsets")
+	if v == nil {
+		return nil, nil
+	}
+
+	var subsets []FontSubset
+	if err := mapstructure.WeakDecode(v, &subsets); err != nil {
+		return nil, fmt.Errorf("failed to decode fontSubsets config: %s", err)
+	}
+
+	for _, s := range subsets {
+		if s.Source == "" || s.Target == "" {
+			return nil, fmt.Errorf("fontSubsets config must have both source and target set, got %q and %q", s.Source, s.Target)
+		}
+	}
+
+	return subsets, nil
+}
+
+// runeSet collects the characters in the rendered pages.
+type runeSet struct {
+	mu    sync.Mutex
+	runes map[rune]bool
+}
+
+func newRuneSet() *runeSet {
+	return &runeSet{runes: make(map[rune]bool)}
+}
+
+// addHTML adds the characters in the text of the given HTML, skipping the
+// tags, scripts and styles.
+func (s *runeSet) addHTML(b []byte) {
+	var text bytes.Buffer
+
+	for len(b) > 0 {
+		lt := bytes.IndexByte(b, '<')
+		if lt == -1 {
+			text.Write(b)
+			break
+		}
+		text.Write(b[:lt])
+		b = b[lt:]
+
+		for _, tag := range []string{"script", "style"} {
+			if len(b) > len(tag) && strings.EqualFold(string(b[1:len(tag)+1]), tag) {
+				if end := bytes.Index(bytes.ToLower(b), []byte("</"+tag)); end != -1 {
+					b = b[end:]
+				}
+				break
+			}
+		}
+
+		gt := bytes.IndexByte(b, '>')
+		if gt == -1 {
+			break
+		}
+		b = b[gt+1:]
+		text.WriteByte(' ')
+	}
+
+	unescaped := html.UnescapeString(text.String())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range unescaped {
+		if unicode.IsPrint(r) {
+			s.runes[r] = true
+		}
+	}
+}
+
+// String returns the characters collected, sorted.
+func (s *runeSet) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runes := make([]rune, 0, len(s.runes))
+	for r := range s.runes {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	return string(runes)
+}
+
+// renderFontSubsets writes the subsets of the fonts in the fontSubsets
+// config with the characters in the rendered pages.
+func (h *HugoSites) renderFontSubsets() error {
+	if len(h.fontSubsets) == 0 {
+		return nil
+	}
+
+	text := h.fontText.String()
+
+	for _, subset := range h.fontSubsets {
+		src, err := afero.ReadFile(h.Fs.Source, h.PathSpec.AbsPathify(subset.Source))
+		if err != nil {
+			return fmt.Errorf("failed to read font %q: %s", subset.Source, err)
+		}
+
+		woff := strings.EqualFold(filepath.Ext(subset.Target), ".woff")
+
+		b, err := resource.SubsetFont(src, subset.Text+text, woff)
+		if err != nil {
+			return fmt.Errorf("failed to subset font %q: %s", subset.Source, err)
+		}
+
+		for i, s := range h.Sites {
+			if i > 0 && !h.IsMultihost() {
+				break
+			}
+			if err := s.publish(&s.PathSpec.ProcessingStats.Files, subset.Target, bytes.NewReader(b)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestFontSubsets(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg, fs := newTestCfg()
+	cfg.Set("fontSubsets", []map[string]interface{}{
+		{"source": "assets/fonts/goregular.ttf", "target": "fonts/goregular.woff", "text": "0123456789"},
+	})
+
+	assert.NoError(afero.WriteFile(fs.Source, filepath.FromSlash("assets/fonts/goregular.ttf"), goregular.TTF, 0755))
+	writeSource(t, fs, filepath.Join("content", "a.md"), "---\ntitle: A\n---\nHello &amp; goodbye.\n")
+	writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), `<html><head><style>body { color: red }</style></head><body>{{ .Content }}</body></html>`)
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{SkipRender: false})
+
+	assert.Contains(s.owner.fontText.String(), "&")
+	assert.NotContains(s.owner.fontText.String(), "{")
+
+	b, err := afero.ReadFile(fs.Destination, filepath.FromSlash("public/fonts/goregular.woff"))
+	assert.NoError(err)
+	assert.Equal("wOFF", string(b[:4]))
+	assert.True(len(b) < len(goregular.TTF)/2)
+}
+
+func TestRuneSetAddHTML(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	s := newRuneSet()
+	s.addHTML([]byte(`<p class="x">日本 &lt;b&gt;</p><script>var zz = 1;</script><STYLE>q{}</STYLE>ab`))
+
+	assert.Equal(" <>ab日本", s.String())
+}
+
+func TestDecodeFontSubsets(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg, _ := newTestCfg()
+	subsets, err := decodeFontSubsets(cfg)
+	assert.NoError(err)
+	assert.Len(subsets, 0)
+
+	cfg.Set("fontSubsets", []map[string]interface{}{{"source": "a.ttf"}})
+	_, err = decodeFontSubsets(cfg)
+	assert.Error(err)
+}
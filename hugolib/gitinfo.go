@@ -58,7 +58,7 @@ func (h *HugoSites) assembleGitInfo() {
 		filename := path.Join(filepath.ToSlash(contentRoot), contentDir, filepath.ToSlash(p.Path()))
 		g, ok := gitMap[filename]
 		if !ok {
-			h.Log.WARN.Printf("Failed to find GitInfo for %q", filename)
+			helpers.Warnidf(h.Log, "warning-gitinfo-missing", "Failed to find GitInfo for %q", filename)
 			return
 		}
 
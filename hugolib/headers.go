@@ -0,0 +1,294 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/source"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// Headers is a set of HTTP headers for the paths matching a pattern. They
+// are configured for both the development server and the production hosting
+// in one place:
+//
+//   [[server.headers]]
+//   for = "/*.css"
+//   [server.headers.values]
+//   Cache-Control = "public, max-age=31536000"
+//
+// Headers in deployment.headers are only written to the headers files for
+// the hosting, see deployment.headersFiles.
+type Headers struct {
+	// The path pattern, where * matches any sequence of characters,
+	// e.g. /* or /css/*.css.
+	For string
+
+	// The header values, keyed by the canonical header name.
+	Values map[string]string
+
+	re *regexp.Regexp
+}
+
+// Matches reports whether the headers apply to the given URL path.
+func (h Headers) Matches(path string) bool {
+	return h.re != nil && h.re.MatchString(path)
+}
+
+// The supported headers files, see deployment.headersFiles.
+const (
+	// Netlify and Cloudflare Pages.
+	headersFileNetlify = "_headers"
+	headersFileVercel  = "vercel.json"
+
+	// Cloudflare Pages' routes file, which configures what requests invoke
+	// Functions and cannot hold headers. Rejected with a hint to use _headers.
+	headersFileCloudflareRoutes = "_routes.json"
+)
+
+// DecodeHeaders decodes the headers in server.headers. If production is set,
+// the headers in deployment.headers are appended.
+func DecodeHeaders(cfg config.Provider, production bool) ([]Headers, error) {
+	headers, err := decodeHeaders(cfg.Get("server.headers"))
+	if err != nil || !production {
+		return headers, err
+	}
+
+	deployment, err := decodeHeaders(cfg.Get("deployment.headers"))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(headers, deployment...), nil
+}
+
+func decodeHeaders(v interface{}) ([]Headers, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var headers []Headers
+	if err := mapstructure.WeakDecode(v, &headers); err != nil {
+		return nil, fmt.Errorf("failed to decode headers config: %s", err)
+	}
+
+	for i, h := range headers {
+		if h.For == "" || !strings.HasPrefix(h.For, "/") {
+			return nil, fmt.Errorf("invalid headers pattern %q, must start with a /", h.For)
+		}
+
+		values := make(map[string]string)
+		for k, v := range h.Values {
+			// The config keys are lower case.
+			values[http.CanonicalHeaderKey(k)] = v
+		}
+		headers[i].Values = values
+
+		pattern := strings.Replace(regexp.QuoteMeta(h.For), `\*`, ".*", -1)
+		headers[i].re = regexp.MustCompile("^" + pattern + "$")
+	}
+
+	return headers, nil
+}
+
+// MatchHeaders returns the header values for the given URL path. When more
+// than one set of headers sets the same header, the last one wins.
+func MatchHeaders(headers []Headers, path string) map[string]string {
+	var values map[string]string
+	for _, h := range headers {
+		if !h.Matches(path) {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		for k, v := range h.Values {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+func sortedHeaderNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// netlifyHeaders creates a _headers file as used by Netlify and
+// Cloudflare Pages.
+func netlifyHeaders(headers []Headers) []byte {
+	var b bytes.Buffer
+	for _, h := range headers {
+		fmt.Fprintln(&b, h.For)
+		for _, k := range sortedHeaderNames(h.Values) {
+			fmt.Fprintf(&b, "  %s: %s\n", k, h.Values[k])
+		}
+	}
+	return b.Bytes()
+}
+
+type vercelHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type vercelHeaders struct {
+	Source  string         `json:"source"`
+	Headers []vercelHeader `json:"headers"`
+}
+
+// vercelHeadersJSON creates the headers section of a vercel.json file.
+func vercelHeadersJSON(headers []Headers) ([]byte, error) {
+	var config struct {
+		Headers []vercelHeaders `json:"headers"`
+	}
+
+	for _, h := range headers {
+		vh := vercelHeaders{Source: strings.Replace(h.For, "*", "(.*)", -1)}
+		for _, k := range sortedHeaderNames(h.Values) {
+			vh.Headers = append(vh.Headers, vercelHeader{Key: k, Value: h.Values[k]})
+		}
+		config.Headers = append(config.Headers, vh)
+	}
+
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// renderHeadersFiles writes the headers files listed in
+// deployment.headersFiles to the root of the publish dir(s).
+func (h *HugoSites) renderHeadersFiles() error {
+	files := cast.ToStringSlice(h.Cfg.Get("deployment.headersFiles"))
+
+	preload, err := decodePreloadConfig(h.Cfg.Get("deployment.preload"))
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 && preload.Manifest == "" {
+		return nil
+	}
+
+	headers, err := DecodeHeaders(h.Cfg, true)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range h.Sites {
+		if i > 0 && !h.IsMultihost() {
+			break
+		}
+
+		siteHeaders := headers
+
+		if preload.enabled() {
+			sites := h.Sites
+			if h.IsMultihost() {
+				sites = []*Site{s}
+			}
+
+			links, err := collectPreloadLinks(preload, sites...)
+			if err != nil {
+				return err
+			}
+
+			if preload.Headers {
+				siteHeaders = append(siteHeaders[:len(siteHeaders):len(siteHeaders)], preloadHeaders(links)...)
+			}
+
+			if preload.Manifest != "" {
+				manifest, err := preloadManifest(links)
+				if err != nil {
+					return err
+				}
+				if err := s.publish(&s.PathSpec.ProcessingStats.Files, preload.Manifest, bytes.NewReader(manifest)); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, filename := range files {
+			var content []byte
+			switch strings.ToLower(filename) {
+			case headersFileNetlify:
+				content = netlifyHeaders(siteHeaders)
+			case headersFileVercel:
+				if content, err = vercelHeadersJSON(siteHeaders); err != nil {
+					return err
+				}
+			case headersFileCloudflareRoutes:
+				return fmt.Errorf("%s configures the routes of Cloudflare Pages Functions and cannot hold headers, use %s for Cloudflare Pages", filename, headersFileNetlify)
+			default:
+				return fmt.Errorf("unsupported headers file %q, must be one of %s or %s", filename, headersFileNetlify, headersFileVercel)
+			}
+
+			// The static files are copied to the publish dir alongside the
+			// build, so one of them would overwrite the generated file,
+			// and the rest of its configuration would be lost.
+			staticFilename, err := h.findStaticFile(s, filename)
+			if err != nil {
+				return err
+			}
+			if staticFilename != "" {
+				return fmt.Errorf("%s is listed in deployment.headersFiles and would overwrite %s, remove one of them", filename, staticFilename)
+			}
+
+			if err := s.publish(&s.PathSpec.ProcessingStats.Files, filename, bytes.NewReader(content)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// findStaticFile returns the filename of the named file in the static dirs
+// copied to the publish dir of the given site, or an empty string if there
+// is none.
+func (h *HugoSites) findStaticFile(s *Site, name string) (string, error) {
+	var cfg config.Provider = h.Cfg
+	if h.IsMultihost() {
+		cfg = s.Language
+	}
+
+	dirs, err := source.NewDirs(s.Fs, cfg, s.Log)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range dirs.AbsStaticDirs {
+		filename := filepath.Join(dir, name)
+		if _, err := s.Fs.Source.Stat(filename); err == nil {
+			return filename, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
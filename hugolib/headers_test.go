@@ -0,0 +1,132 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const headersTestConfig = `
+baseURL = "http://example.com/"
+disableKinds = ["page", "section", "taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[[server.headers]]
+for = "/*"
+[server.headers.values]
+X-Frame-Options = "DENY"
+
+[[server.headers]]
+for = "/css/*.css"
+[server.headers.values]
+Cache-Control = "public, max-age=31536000"
+
+[deployment]
+headersFiles = ["_headers", "vercel.json"]
+[[deployment.headers]]
+for = "/*"
+[deployment.headers.values]
+Strict-Transport-Security = "max-age=63072000"
+`
+
+func TestDecodeHeaders(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg, _ := newTestCfg()
+	cfg.Set("server", map[string]interface{}{
+		"headers": []map[string]interface{}{
+			{"for": "/*", "values": map[string]interface{}{"x-frame-options": "DENY"}},
+			{"for": "/css/*.css", "values": map[string]interface{}{"cache-control": "max-age=60"}},
+		},
+	})
+	cfg.Set("deployment", map[string]interface{}{
+		"headers": []map[string]interface{}{
+			{"for": "/*", "values": map[string]interface{}{"x-frame-options": "SAMEORIGIN"}},
+		},
+	})
+
+	headers, err := DecodeHeaders(cfg, false)
+	assert.NoError(err)
+	assert.Len(headers, 2)
+
+	assert.Equal(map[string]string{"X-Frame-Options": "DENY"}, MatchHeaders(headers, "/about/"))
+	assert.Equal(map[string]string{"X-Frame-Options": "DENY", "Cache-Control": "max-age=60"}, MatchHeaders(headers, "/css/main.css"))
+	assert.Equal(map[string]string{"X-Frame-Options": "DENY"}, MatchHeaders(headers, "/css/main.css.map"))
+
+	headers, err = DecodeHeaders(cfg, true)
+	assert.NoError(err)
+	assert.Len(headers, 3)
+	assert.Equal("SAMEORIGIN", MatchHeaders(headers, "/")["X-Frame-Options"])
+
+	_, err = decodeHeaders([]map[string]interface{}{{"for": "css/*"}})
+	assert.Error(err)
+}
+
+func TestRenderHeadersFiles(t *testing.T) {
+	t.Parallel()
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), headersTestConfig,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"layouts/index.html", "Home",
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/_headers", `/*
+  X-Frame-Options: DENY
+/css/*.css
+  Cache-Control: public, max-age=31536000
+/*
+  Strict-Transport-Security: max-age=63072000
+`)
+
+	th.assertFileContent("public/vercel.json",
+		`"source": "/(.*)"`,
+		`"source": "/css/(.*).css"`,
+		`"key": "Cache-Control",`,
+		`"value": "max-age=63072000"`,
+	)
+}
+
+func TestRenderHeadersFilesErrors(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"_routes.json"}, "use _headers"},
+		{[]string{"vercel.json", "static/vercel.json"}, "would overwrite"},
+		{[]string{"_headers", "themes/mytheme/static/_headers"}, "would overwrite"},
+	} {
+		cfg := fmt.Sprintf("baseURL = \"http://example.com/\"\ntheme = \"mytheme\"\n[deployment]\nheadersFiles = [%q]\n", test.files[0])
+
+		mf := afero.NewMemMapFs()
+		writeToFs(t, mf, "content/p.md", "---\ntitle: P\n---\n")
+		writeToFs(t, mf, "themes/mytheme/layouts/index.html", "Home")
+		for _, filename := range test.files[1:] {
+			writeToFs(t, mf, filename, "{}")
+		}
+
+		_, h := newTestSitesFromConfig(t, mf, cfg)
+
+		err := h.Build(BuildCfg{})
+		require.Error(t, err, "[%d]", i)
+		require.Contains(t, err.Error(), test.expected, "[%d]", i)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadlessBundle(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/gallery/index.md", "---\ntitle: Gallery\nheadless: true\n---\nThe **gallery**.\n",
+		"content/gallery/one.txt", "One",
+		"content/gallery/two.txt", "Two",
+		"content/blog/post.md", "---\ntitle: Post\n---\n",
+		// Only leaf bundles can be headless.
+		"content/blog/regular.md", "---\ntitle: Regular\nheadless: true\n---\n",
+		"layouts/_default/single.html", `Single: {{ .Title }}|{{ with .Site.GetPage "page" "gallery/index.md" }}{{ .Title }}|{{ .Headless }}|{{ .Content }}|{{ range .Resources }}{{ .RelPermalink }}|{{ end }}{{ end }}`,
+		"layouts/_default/list.html", `List: {{ range .Pages }}{{ .Title }}|{{ end }}`,
+		"layouts/index.html", `Home: {{ range .Site.Pages }}{{ .Title }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/blog/post/index.html", "Single: Post|Gallery|true|<p>The <strong>gallery</strong>.</p>", "/gallery/one.txt|/gallery/two.txt|")
+	th.assertFileContent("public/blog/regular/index.html", "Single: Regular|Gallery")
+	th.assertFileContent("public/gallery/one.txt", "One")
+	th.assertFileContent("public/gallery/two.txt", "Two")
+	th.assertFileNotExist("public/gallery/index.html")
+
+	for _, filename := range []string{"public/index.html", "public/sitemap.xml", "public/index.xml"} {
+		content := readDestination(t, th.Fs, filename)
+		assert.NotContains(content, "Gallery", filename)
+		assert.NotContains(content, "/gallery/", filename)
+	}
+}
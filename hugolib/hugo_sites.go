@@ -15,12 +15,15 @@ package hugolib
 
 import (
 	"errors"
+	"hash/fnv"
 	"io"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/gohugoio/hugo/resource"
 
 	"github.com/gohugoio/hugo/deps"
@@ -47,6 +50,42 @@ type HugoSites struct {
 
 	// Keeps track of bundle directories and symlinks to enable partial rebuilding.
 	ContentChanges *contentChangeMap
+
+	// The data fetched for the remote data sources in the site config.
+	remoteData *remoteData
+
+	// Keeps track of the data used by each page to enable partial rebuilding.
+	dataDeps *dataDependencies
+
+	// Keeps track of the pages using other pages when rendered to enable
+	// partial rebuilding.
+	pageDeps *pageDependencies
+
+	// The handlers of the build events, see OnBuildEvent.
+	eventHandlers []func(e BuildEvent)
+	eventsMu      sync.Mutex
+
+	// When the current build started, see exitBuild.
+	buildStart time.Time
+
+	// The sites are rendered in parallel and may publish the same files,
+	// e.g. robots.txt, so the writes to a file are serialized.
+	publishLocks [64]sync.Mutex
+
+	// Limits the number of images processed concurrently by all sites.
+	imageProcessor *resource.ImageProcessor
+
+	// The fonts to subset and the characters in the rendered pages, see
+	// FontSubset.
+	fontSubsets []FontSubset
+	fontText    *runeSet
+}
+
+// publishLock returns the lock for writes to the file with the given path.
+func (h *HugoSites) publishLock(path string) *sync.Mutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(path))
+	return &h.publishLocks[hash.Sum32()%uint32(len(h.publishLocks))]
 }
 
 func (h *HugoSites) IsMultihost() bool {
@@ -129,13 +168,36 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 		contentChangeTracker = &contentChangeMap{symContent: make(map[string]map[string]bool)}
 	}
 
+	remoteData, err := newRemoteData(cfg.Cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	fontSubsets, err := decodeFontSubsets(cfg.Cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	h := &HugoSites{
 		running:        cfg.Running,
 		multilingual:   langConfig,
 		multihost:      cfg.Cfg.GetBool("multihost"),
 		ContentChanges: contentChangeTracker,
+		remoteData:     remoteData,
+		dataDeps:       newDataDependencies(),
+		pageDeps:       newPageDependencies(),
+		fontSubsets:    fontSubsets,
 		Sites:          sites}
 
+	if len(fontSubsets) > 0 {
+		h.fontText = newRuneSet()
+	}
+
+	h.imageProcessor = resource.NewImageProcessor(cfg.Cfg.GetInt("imageWorkers"))
+	h.imageProcessor.Progress = func(done, queued int) {
+		h.Log.FEEDBACK.Printf("Processing images: %d done, %d queued\n", done, queued)
+	}
+
 	for _, s := range sites {
 		s.owner = h
 	}
@@ -197,6 +259,14 @@ func applyDepsIfNeeded(cfg deps.DepsCfg, sites ...*Site) error {
 		if err != nil {
 			return err
 		}
+		if s.owner != nil {
+			s.resourceSpec.Running = s.owner.running
+			if s.owner.imageProcessor != nil {
+				s.resourceSpec.ImageProcessor = s.owner.imageProcessor
+			}
+		} else {
+			s.resourceSpec.Running = cfg.Running
+		}
 
 	}
 
@@ -348,7 +418,7 @@ func (h *HugoSites) renderCrossSitesArtifacts() error {
 	}
 
 	// TODO(bep) DRY
-	sitemapDefault := parseSitemap(h.Cfg.GetStringMap("sitemap"))
+	sitemapDefault := parseSitemap(h.Cfg.GetStringMap("sitemap"), h.Log)
 
 	s := h.Sites[0]
 
@@ -410,7 +480,7 @@ func (h *HugoSites) createMissingPages() error {
 		newPages = append(newPages, newSections...)
 
 		// taxonomy list and terms pages
-		taxonomies := s.Language.GetStringMapString("taxonomies")
+		taxonomies := s.taxonomiesConfig
 		if len(taxonomies) > 0 {
 			taxonomyPages := s.findPagesByKind(KindTaxonomy)
 			taxonomyTermsPages := s.findPagesByKind(KindTaxonomyTerm)
@@ -507,7 +577,12 @@ func (h *HugoSites) setupTranslations() {
 
 			shouldBuild := p.shouldBuild()
 			s.updateBuildStats(p)
-			if shouldBuild {
+			if !shouldBuild {
+				continue
+			}
+			if p.Headless {
+				s.headlessPages = append(s.headlessPages, p)
+			} else {
 				s.Pages = append(s.Pages, p)
 			}
 		}
@@ -556,7 +631,7 @@ func (s *Site) preparePagesForRender(cfg *BuildCfg) {
 		}(pageChan, wg)
 	}
 
-	for _, p := range s.Pages {
+	for _, p := range s.pagesToPrepare() {
 		pageChan <- p
 	}
 
@@ -564,6 +639,10 @@ func (s *Site) preparePagesForRender(cfg *BuildCfg) {
 
 	wg.Wait()
 
+	// The content may differ between the output formats.
+	s.backlinks = &siteBacklinks{}
+	s.search = &siteSearch{}
+
 }
 
 // Pages returns all pages for all sites.
@@ -620,6 +699,28 @@ func (h *HugoSites) findAllPagesByKindNotIn(kind string) Pages {
 	return h.findPagesByKindNotIn(kind, h.Sites[0].AllPages)
 }
 
+// languagesForTranslationFiles returns the languages of the sites using the
+// given translation files, or nil if all sites do. All sites fall back to the
+// translations of the default content language.
+func (h *HugoSites) languagesForTranslationFiles(events []fsnotify.Event) map[string]bool {
+	defaultLang := strings.ToLower(h.Cfg.GetString("defaultContentLanguage"))
+
+	languages := make(map[string]bool)
+	for _, ev := range events {
+		lang := i18n.LanguageForFile(ev.Name)
+		if lang == defaultLang {
+			return nil
+		}
+		for _, s := range h.Sites {
+			if strings.ToLower(s.Language.Lang) == lang {
+				languages[s.Language.Lang] = true
+			}
+		}
+	}
+
+	return languages
+}
+
 func (h *HugoSites) findPagesByShortcode(shortcode string) Pages {
 	var pages Pages
 	for _, s := range h.Sites {
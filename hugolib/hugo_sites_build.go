@@ -17,18 +17,48 @@ import (
 	"bytes"
 
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
 )
 
 // Build builds all sites. If filesystem events are provided,
 // this is considered to be a potential partial rebuild.
 func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
+	start := time.Now()
+	h.sendBuildEvent(BuildEvent{Type: BuildStarted, Time: start})
+
+	if err := h.build(config, events...); err != nil {
+		h.sendBuildEvent(BuildEvent{Type: BuildFailed, Duration: time.Since(start), Err: err})
+		return err
+	}
+
+	h.sendBuildEvent(BuildEvent{Type: BuildFinished, Duration: time.Since(start)})
+
+	return nil
+}
+
+func (h *HugoSites) build(config BuildCfg, events ...fsnotify.Event) error {
 	if h.Metrics != nil {
 		h.Metrics.Reset()
 	}
 
+	// Partial rebuilds do not execute all of the templates, so the coverage
+	// is only reported for full builds.
+	reportCoverage := h.Coverage != nil && len(events) == 0
+	if reportCoverage {
+		h.Coverage.Reset()
+	}
+
+	if h.imageProcessor != nil {
+		h.imageProcessor.Reset()
+	}
+
 	//t0 := time.Now()
 
 	// Need a pointer as this may be modified.
@@ -48,12 +78,18 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		if err := h.init(conf); err != nil {
 			return err
 		}
+
+		if err := h.checkMisplacedFiles(); err != nil {
+			return err
+		}
 	}
 
 	if err := h.process(conf, events...); err != nil {
 		return err
 	}
 
+	h.sendBuildEvent(BuildEvent{Type: BuildContentCaptured})
+
 	if err := h.assemble(conf); err != nil {
 		return err
 	}
@@ -71,6 +107,10 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		h.Log.FEEDBACK.Println()
 	}
 
+	if reportCoverage {
+		h.reportTemplateCoverage()
+	}
+
 	return nil
 
 }
@@ -90,6 +130,15 @@ func (h *HugoSites) init(config *BuildCfg) error {
 		h.reset()
 	}
 
+	// The dependencies are recorded during a full build, and only when
+	// running, i.e. in server or watch mode, for the partial rebuilds to
+	// render the affected pages only, see Site.dataDeps and Site.pageDeps.
+	h.dataDeps.reset()
+	h.pageDeps.reset()
+	for _, s := range h.Sites {
+		s.resourceSpec.ResetBuildState()
+	}
+
 	if config.CreateSitesFromConfig {
 		if err := h.createSitesFromConfig(); err != nil {
 			return err
@@ -123,7 +172,7 @@ func (h *HugoSites) initRebuild(config *BuildCfg) error {
 		s.resetBuildState()
 	}
 
-	helpers.InitLoggers()
+	helpers.InitLoggers(cast.ToStringSlice(h.Cfg.Get("ignoreLogs"))...)
 
 	return nil
 }
@@ -179,7 +228,7 @@ func (h *HugoSites) assemble(config *BuildCfg) error {
 	}
 
 	for _, s := range h.Sites {
-		for _, p := range s.Pages {
+		for _, p := range append(s.headlessPages, s.Pages...) {
 			// May have been set in front matter
 			if len(p.outputFormats) == 0 {
 				p.outputFormats = s.outputFormats[p.Kind]
@@ -188,6 +237,8 @@ func (h *HugoSites) assemble(config *BuildCfg) error {
 				r.(*Page).outputFormats = p.outputFormats
 			}
 
+			p.outputFormats = p.withSectionDataFormat(p.outputFormats)
+
 			if err := p.initPaths(); err != nil {
 				return err
 			}
@@ -207,16 +258,43 @@ func (h *HugoSites) assemble(config *BuildCfg) error {
 }
 
 func (h *HugoSites) render(config *BuildCfg) error {
+	var sites []*Site
 	for _, s := range h.Sites {
+		if config.whatChanged != nil && config.whatChanged.languages != nil && !config.whatChanged.languages[s.Language.Lang] {
+			continue
+		}
 		s.initRenderFormats()
-		for i, rf := range s.renderFormats {
-			s.rc = &siteRenderingContext{Format: rf}
+		sites = append(sites, s)
+	}
+
+	// The output converters are done with the published files.
+	defer func() {
+		for _, s := range sites {
+			s.closeConverterAssets()
+		}
+	}()
+
+	// The sites are rendered in parallel, one output format at a time. The
+	// pages of all sites are prepared for the output format first, as pages
+	// may refer to pages in other languages, e.g. translations.
+	for i := 0; ; i++ {
+		var formatSites []*Site
+		for _, s := range sites {
+			if i >= len(s.renderFormats) {
+				continue
+			}
+			s.rc = &siteRenderingContext{Format: s.renderFormats[i]}
 			s.preparePagesForRender(config)
+			formatSites = append(formatSites, s)
+		}
 
-			if !config.SkipRender {
-				if err := s.render(config, i); err != nil {
-					return err
-				}
+		if len(formatSites) == 0 {
+			break
+		}
+
+		if !config.SkipRender {
+			if err := renderSites(formatSites, config, i); err != nil {
+				return err
 			}
 		}
 	}
@@ -225,7 +303,63 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		if err := h.renderCrossSitesArtifacts(); err != nil {
 			return err
 		}
+		if err := h.renderHeadersFiles(); err != nil {
+			return err
+		}
+		if err := h.renderGraph(); err != nil {
+			return err
+		}
+		if err := h.renderFontSubsets(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// renderSites renders the given output format of the sites in parallel. A
+// failing language does not stop the others, the errors are collected.
+func renderSites(sites []*Site, config *BuildCfg, outFormatIdx int) error {
+	return collectSiteErrors(sites, func(s *Site) error {
+		return s.render(config, outFormatIdx)
+	})
+}
+
+// collectSiteErrors runs fn for every site in parallel and returns the errors
+// prefixed with the language, or the error itself if only one site failed.
+func collectSiteErrors(sites []*Site, fn func(s *Site) error) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(sites))
+	)
+
+	for i, s := range sites {
+		wg.Add(1)
+		go func(i int, s *Site) {
+			defer wg.Done()
+			errs[i] = fn(s)
+		}(i, s)
+	}
+
+	wg.Wait()
+
+	var (
+		first error
+		msgs  []string
+	)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s", sites[i].Language.Lang, err))
+	}
+
+	if len(msgs) <= 1 {
+		return first
+	}
+
+	return fmt.Errorf("failed to render %d languages:\n%s", len(msgs), strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestI18nRebuildAffectedLanguages(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+defaultContentLanguageInSubdir = true
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languages.de]
+weight = 3
+`
+
+	afs := afero.NewMemMapFs()
+	writeToFs(t, afs, "config.toml", config)
+	cfg, err := LoadConfig(afs, "", "config.toml")
+	assert.NoError(err)
+	fs := hugofs.NewFrom(afs, cfg)
+	th := testHelper{cfg, fs, t}
+
+	writeSource(t, fs, "i18n/en.toml", "[hello]\nother = \"Hello\"\n")
+	writeSource(t, fs, "i18n/fr.toml", "[hello]\nother = \"Bonjour\"\n")
+	writeSource(t, fs, "layouts/index.html", `{{ i18n "hello" }}|{{ .Lang }}`)
+	writeSource(t, fs, "content/_index.md", "---\ntitle: Home\n---\n")
+
+	h, err := NewHugoSites(deps.DepsCfg{Fs: fs, Cfg: cfg, Running: true})
+	assert.NoError(err)
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/en/index.html", "Hello|en")
+	th.assertFileContent("public/fr/index.html", "Bonjour|fr")
+	// No German translations, so the default language is used.
+	th.assertFileContent("public/de/index.html", "Hello|de")
+
+	removeHomes := func() {
+		for _, lang := range []string{"en", "fr", "de"} {
+			fs.Destination.Remove(filepath.FromSlash("public/" + lang + "/index.html"))
+		}
+	}
+	homeExists := func(lang string) bool {
+		exists, _ := afero.Exists(fs.Destination, filepath.FromSlash("public/"+lang+"/index.html"))
+		return exists
+	}
+
+	// Only the French site uses the French translations.
+	removeHomes()
+	writeSource(t, fs, "i18n/fr.toml", "[hello]\nother = \"Salut\"\n")
+	assert.NoError(h.Build(BuildCfg{}, fsnotify.Event{Name: filepath.FromSlash("i18n/fr.toml"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/fr/index.html", "Salut|fr")
+	assert.False(homeExists("en"))
+	assert.False(homeExists("de"))
+
+	// All sites fall back to the translations of the default language.
+	removeHomes()
+	writeSource(t, fs, "i18n/en.toml", "[hello]\nother = \"Hi\"\n")
+	assert.NoError(h.Build(BuildCfg{}, fsnotify.Event{Name: filepath.FromSlash("i18n/en.toml"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/en/index.html", "Hi|en")
+	th.assertFileContent("public/fr/index.html", "Salut|fr")
+	th.assertFileContent("public/de/index.html", "Hi|de")
+}
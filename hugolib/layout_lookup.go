@@ -0,0 +1,120 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/tpl"
+)
+
+// LayoutLookup describes the template lookup for a page in one output
+// format.
+type LayoutLookup struct {
+	Page         *Page
+	OutputFormat output.Format
+
+	// The layouts tried, in order.
+	Layouts []string
+
+	// The index in Layouts of the layout used, -1 if none was found.
+	Found int
+
+	// The template file of the layout used, if loaded from file.
+	Filename string
+}
+
+// LayoutLookups returns the template lookups for the pages matching the
+// given path in all languages and output formats. The path is either the
+// path of the content file, e.g. posts/my-post.md, or the relative
+// permalink, e.g. /posts/my-post/.
+func (h *HugoSites) LayoutLookups(path string) []LayoutLookup {
+	var lookups []LayoutLookup
+
+	for _, s := range h.Sites {
+		files := templateFiles(s.Tmpl)
+
+		// AllPages holds the pages in all languages, so use the pages of
+		// this site, including those left out of the collections.
+		for _, p := range s.pagesToPrepare() {
+			if !p.matchesLookupPath(path) {
+				continue
+			}
+
+			for _, f := range p.outputFormats {
+				lookups = append(lookups, s.layoutLookup(p, f, files))
+			}
+		}
+	}
+
+	return lookups
+}
+
+func (s *Site) layoutLookup(p *Page, f output.Format, files map[string]string) LayoutLookup {
+	lookup := LayoutLookup{Page: p, OutputFormat: f, Found: -1}
+
+	if p.selfLayout != "" {
+		lookup.Layouts = []string{p.selfLayout}
+	} else {
+		layouts, err := s.layoutHandler.For(p.layoutDescriptor, "", f)
+		if err != nil {
+			return lookup
+		}
+		lookup.Layouts = layouts
+	}
+
+	for i, layout := range lookup.Layouts {
+		if s.Tmpl.Lookup(layout) != nil {
+			lookup.Found = i
+			lookup.Filename = files[strings.TrimPrefix(layout, "_text/")]
+			break
+		}
+	}
+
+	return lookup
+}
+
+// matchesLookupPath reports whether the page has the given content file
+// path or relative permalink.
+func (p *Page) matchesLookupPath(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	if p.Source.File != nil && p.Source.Path() != "" {
+		filename := filepath.ToSlash(p.Source.Path())
+		path := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		contentDir := strings.Trim(filepath.ToSlash(p.s.Cfg.GetString("contentDir")), "/")
+		if filename == path || filename == strings.TrimPrefix(path, contentDir+"/") {
+			return true
+		}
+	}
+
+	rel := p.RelPermalink()
+	return rel == path || rel == path+"/"
+}
+
+// templateFiles returns the files of the templates loaded from file, keyed
+// by template name.
+func templateFiles(t tpl.TemplateFinder) map[string]string {
+	files := make(map[string]string)
+	if provider, ok := t.(tpl.TemplateInfoProvider); ok {
+		for _, info := range provider.TemplateInfos() {
+			files[info.Name] = info.Filename
+		}
+	}
+	return files
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutLookups(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\n---\n",
+		"content/about.md", "---\ntitle: About\n---\n",
+		"layouts/_default/single.html", "Single",
+		"layouts/_default/list.html", "List",
+		"layouts/posts/single.html", "Post",
+	)
+
+	assert.NoError(h.Build(BuildCfg{SkipRender: true}))
+
+	for _, path := range []string{"posts/p1.md", "content/posts/p1.md", "/posts/p1/", "/posts/p1"} {
+		lookups := h.LayoutLookups(path)
+		assert.Len(lookups, 1, path)
+		l := lookups[0]
+		assert.Equal("HTML", l.OutputFormat.Name)
+		assert.Equal("posts/single.html.html", l.Layouts[0])
+		assert.Equal(1, l.Found)
+		assert.Equal("posts/single.html", l.Layouts[l.Found])
+		assert.True(strings.HasSuffix(l.Filename, "posts/single.html"), l.Filename)
+	}
+
+	lookups := h.LayoutLookups("about.md")
+	assert.Len(lookups, 1)
+	assert.Equal("_default/single.html", lookups[0].Layouts[lookups[0].Found])
+
+	// The section has both HTML and RSS.
+	lookups = h.LayoutLookups("/posts/")
+	assert.Len(lookups, 2)
+	assert.Equal("_default/list.html", lookups[0].Layouts[lookups[0].Found])
+	assert.Equal("RSS", lookups[1].OutputFormat.Name)
+	assert.Equal("_internal/_default/rss.xml", lookups[1].Layouts[lookups[1].Found])
+	assert.Equal("", lookups[1].Filename)
+
+	assert.Len(h.LayoutLookups("posts/nope.md"), 0)
+}
+
+func TestLayoutLookupsMultilingual(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404", "RSS"]
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", "---\ntitle: P1\n---\n",
+		"content/p1.fr.md", "---\ntitle: P1 FR\n---\n",
+		"content/secret.md", "---\ntitle: Secret\nunlisted: true\n---\n",
+		"layouts/_default/single.html", "Single",
+		"layouts/_default/list.html", "List",
+	)
+
+	assert.NoError(h.Build(BuildCfg{SkipRender: true}))
+
+	lookups := h.LayoutLookups("/p1/")
+	assert.Len(lookups, 1)
+	assert.Equal("en", lookups[0].Page.Lang())
+
+	lookups = h.LayoutLookups("p1.fr.md")
+	assert.Len(lookups, 1)
+	assert.Equal("fr", lookups[0].Page.Lang())
+
+	lookups = h.LayoutLookups("secret.md")
+	assert.Len(lookups, 1)
+	assert.Equal("_default/single.html", lookups[0].Layouts[lookups[0].Found])
+}
@@ -117,6 +117,10 @@ func toSortedLanguages(cfg config.Provider, l map[string]interface{}) (helpers.L
 			language.SetParam(loki, v)
 		}
 
+		if err := mergeLanguageConfig(cfg, language, langsMap); err != nil {
+			return nil, err
+		}
+
 		langs[i] = language
 		i++
 	}
@@ -136,6 +136,14 @@ type Page struct {
 	Draft     bool
 	Status    string
 
+	// Unlisted pages are rendered, but left out of the page collections,
+	// sitemaps and feeds, e.g. for pages shared by link only.
+	Unlisted bool
+
+	// Headless leaf bundles are never rendered, but their resources are
+	// published and the page can be fetched with GetPage.
+	Headless bool
+
 	PublishDate time.Time
 	ExpiryDate  time.Time
 
@@ -319,6 +327,10 @@ type pageInit struct {
 	plainInit           sync.Once
 	plainWordsInit      sync.Once
 	renderingConfigInit sync.Once
+
+	// Set when the markup type is determined, which may be from another
+	// page's render, see Region.
+	markupInit sync.Once
 }
 
 // IsNode returns whether this is an item of one of the list types in Hugo,
@@ -510,31 +522,20 @@ func traverse(keys []string, m map[string]interface{}) interface{} {
 	return traverse(rest, cast.ToStringMap(result))
 }
 
+// Author returns the page's first author, see AuthorsSorted.
 func (p *Page) Author() Author {
-	authors := p.Authors()
-
-	for _, author := range authors {
-		return author
+	if authors := p.AuthorsSorted(); len(authors) > 0 {
+		return authors[0]
 	}
 	return Author{}
 }
 
+// Authors returns the page's authors set in the front matter with a profile
+// in data/authors, keyed by ID.
 func (p *Page) Authors() AuthorList {
-	authorKeys, ok := p.Params["authors"]
-	if !ok {
-		return AuthorList{}
-	}
-	authors := authorKeys.([]string)
-	if len(authors) < 1 || len(p.Site.Authors) < 1 {
-		return AuthorList{}
-	}
-
 	al := make(AuthorList)
-	for _, author := range authors {
-		a, ok := p.Site.Authors[author]
-		if ok {
-			al[author] = a
-		}
+	for _, a := range p.AuthorsSorted() {
+		al[a.ID] = a
 	}
 	return al
 }
@@ -581,7 +582,7 @@ func replaceDivider(content, from, to []byte) ([]byte, bool) {
 func (p *Page) replaceDivider(content []byte) []byte {
 	summaryDivider := helpers.SummaryDivider
 	// TODO(bep) handle better.
-	if p.Ext() == "org" || p.Markup == "org" {
+	if p.Ext() == "org" || p.determineMarkupType() == "org" {
 		summaryDivider = []byte("# more")
 	}
 
@@ -595,7 +596,7 @@ func (p *Page) replaceDivider(content []byte) []byte {
 // Returns the page as summary and main if a user defined split is provided.
 func (p *Page) setUserDefinedSummaryIfProvided(rawContentCopy []byte) (*summaryContent, error) {
 
-	sc, err := splitUserDefinedSummaryAndContent(p.Markup, rawContentCopy)
+	sc, err := splitUserDefinedSummaryAndContent(p.determineMarkupType(), rawContentCopy)
 
 	if err != nil {
 		return nil, err
@@ -712,7 +713,8 @@ func (p *Page) renderContent(content []byte) []byte {
 		Content: content, RenderTOC: true, PageFmt: p.determineMarkupType(),
 		Cfg:        p.Language(),
 		DocumentID: p.UniqueID(), DocumentName: p.Path(),
-		Config: p.getRenderingConfig()})
+		Config:      p.getRenderingConfig(),
+		RenderHooks: p.renderHooks()})
 }
 
 func (p *Page) getRenderingConfig() *helpers.BlackFriday {
@@ -891,6 +893,42 @@ func (p *Page) AllTranslations() Pages {
 	return p.translations
 }
 
+// AllTranslationEntries returns one entry per site language, in the language
+// order, with the translation of the current Page or nil if it is not
+// translated to that language. This is useful for building language
+// switchers:
+//
+//	{{ range .AllTranslationEntries }}
+//	  {{ if .Page }}<a href="{{ .Page.RelPermalink }}">{{ .Language.LanguageName }}</a>
+//	  {{ else }}<a href="{{ .Home.RelPermalink }}">{{ .Language.LanguageName }}</a>{{ end }}
+//	{{ end }}
+func (p *Page) AllTranslationEntries() []TranslationEntry {
+	if p.s == nil || p.s.owner == nil {
+		return nil
+	}
+
+	translations := make(map[string]*Page)
+	for _, t := range p.translations {
+		translations[t.Lang()] = t
+	}
+	if len(translations) == 0 {
+		translations[p.Lang()] = p
+	}
+
+	entries := make([]TranslationEntry, len(p.s.owner.Sites))
+	for i, s := range p.s.owner.Sites {
+		lang := s.Language.Lang
+		entries[i] = TranslationEntry{
+			Language:  s.Language,
+			Page:      translations[lang],
+			Home:      s.getPage(KindHome),
+			IsCurrent: lang == p.Lang(),
+		}
+	}
+
+	return entries
+}
+
 // IsTranslated returns whether this content file is translated to
 // other language(s).
 func (p *Page) IsTranslated() bool {
@@ -993,6 +1031,35 @@ func (p *Page) subResourceLinkFactory(base string) string {
 	return path.Join(p.relPermalinkBase, base)
 }
 
+// resourceLinkFactory returns the link factory for the page's resources of
+// the given type, e.g. "image". The resources are published in the page's
+// directory unless there is a pattern for the type, or "*", in
+// resourcePermalinks:
+//
+//   [resourcePermalinks]
+//   image = "/img/:section/:slug/"
+func (p *Page) resourceLinkFactory(resourceType string) func(base string) string {
+	pattern, found := p.Site.resourcePermalinks[resourceType]
+	if !found {
+		pattern, found = p.Site.resourcePermalinks["*"]
+	}
+	if !found {
+		return p.subResourceLinkFactory
+	}
+
+	return func(base string) string {
+		dir, err := pattern.Expand(p)
+		if err != nil {
+			p.s.Log.ERROR.Printf("Failed to expand resource permalink for page %q: %s", p.FullFilePath(), err)
+			return p.subResourceLinkFactory(base)
+		}
+		if !p.s.owner.IsMultihost() && p.targetPathDescriptorPrototype.LangPrefix != "" {
+			dir = path.Join("/", p.targetPathDescriptorPrototype.LangPrefix, dir)
+		}
+		return path.Join(dir, base)
+	}
+}
+
 func (p *Page) prepareForRender(cfg *BuildCfg) error {
 	s := p.s
 
@@ -1028,7 +1095,7 @@ func (p *Page) prepareForRender(cfg *BuildCfg) error {
 		workContentCopy = p.workContent
 	}
 
-	if p.Markup == "markdown" {
+	if p.determineMarkupType() == "markdown" {
 		tmpContent, tmpTableOfContents := helpers.ExtractTOC(workContentCopy)
 		p.TableOfContents = helpers.BytesToHTML(tmpTableOfContents)
 		workContentCopy = tmpContent
@@ -1039,7 +1106,7 @@ func (p *Page) prepareForRender(cfg *BuildCfg) error {
 		s.Log.ERROR.Printf("Failed to handle shortcodes for page %s: %s", p.BaseFileName(), err)
 	}
 
-	if p.Markup != "html" {
+	if p.determineMarkupType() != "html" {
 
 		// Now we know enough to create a summary of the page and count some words
 		summaryContent, err := p.setUserDefinedSummaryIfProvided(workContentCopy)
@@ -1122,18 +1189,18 @@ func (p *Page) update(f interface{}) error {
 			p.Keywords = cast.ToStringSlice(v)
 			p.Params[loki] = p.Keywords
 		case "date":
-			p.Date, err = cast.ToTimeE(v)
+			p.Date, err = p.toTime(v)
 			if err != nil {
 				p.s.Log.ERROR.Printf("Failed to parse date '%v' in page %s", v, p.File.Path())
 			}
 			p.Params[loki] = p.Date
 		case "lastmod":
-			p.Lastmod, err = cast.ToTimeE(v)
+			p.Lastmod, err = p.toTime(v)
 			if err != nil {
 				p.s.Log.ERROR.Printf("Failed to parse lastmod '%v' in page %s", v, p.File.Path())
 			}
 		case "modified":
-			vv, err := cast.ToTimeE(v)
+			vv, err := p.toTime(v)
 			if err == nil {
 				p.Params[loki] = vv
 				modified = vv
@@ -1156,26 +1223,35 @@ func (p *Page) update(f interface{}) error {
 			}
 			//p.Params[loki] = p.Keywords
 		case "publishdate", "pubdate":
-			p.PublishDate, err = cast.ToTimeE(v)
+			p.PublishDate, err = p.toTime(v)
 			if err != nil {
 				p.s.Log.ERROR.Printf("Failed to parse publishdate '%v' in page %s", v, p.File.Path())
 			}
 			p.Params[loki] = p.PublishDate
 		case "expirydate", "unpublishdate":
-			p.ExpiryDate, err = cast.ToTimeE(v)
+			p.ExpiryDate, err = p.toTime(v)
 			if err != nil {
 				p.s.Log.ERROR.Printf("Failed to parse expirydate '%v' in page %s", v, p.File.Path())
 			}
 		case "draft":
 			draft = new(bool)
 			*draft = cast.ToBool(v)
+		case "unlisted":
+			p.Unlisted = cast.ToBool(v)
+			p.Params[loki] = p.Unlisted
+		case "headless":
+			// Only leaf bundles, i.e. index.md, can be headless.
+			if fi, ok := p.Source.File.(*fileInfo); ok && fi.bundleTp == bundleLeaf {
+				p.Headless = cast.ToBool(v)
+			}
+			p.Params[loki] = p.Headless
 		case "published": // Intentionally undocumented
 			vv, err := cast.ToBoolE(v)
 			if err == nil {
 				published = &vv
 			} else {
 				// Some sites use this as the publishdate
-				vv, err := cast.ToTimeE(v)
+				vv, err := p.toTime(v)
 				if err == nil {
 					p.PublishDate = vv
 					p.Params[loki] = p.PublishDate
@@ -1204,7 +1280,7 @@ func (p *Page) update(f interface{}) error {
 			p.Status = cast.ToString(v)
 			p.Params[loki] = p.Status
 		case "sitemap":
-			p.Sitemap = parseSitemap(cast.ToStringMap(v))
+			p.Sitemap = parseSitemap(cast.ToStringMap(v), p.s.Log)
 			p.Params[loki] = p.Sitemap
 		case "iscjklanguage":
 			isCJKLanguage = new(bool)
@@ -1522,15 +1598,33 @@ func (p *Page) shouldRenderTo(f output.Format) bool {
 	return found
 }
 
+// determineMarkupType returns the markup type of the content and sets
+// p.Markup to it on first use.
 func (p *Page) determineMarkupType() string {
+	p.markupInit.Do(func() {
+		p.Markup = p.markupType()
+	})
+	return p.Markup
+}
+
+func (p *Page) markupType() string {
 	// Try markup explicitly set in the frontmatter
-	p.Markup = helpers.GuessType(p.Markup)
-	if p.Markup == "unknown" {
+	markup := helpers.GuessType(p.Markup)
+	if markup == "unknown" && p.s.ContentSpec.HasConverter(strings.ToLower(p.Markup)) {
+		// A markup format of a plugin.
+		markup = strings.ToLower(p.Markup)
+	} else if markup == "unknown" {
+		if p.Markup != "" {
+			helpers.Warnidf(p.s.Log, "warning-markup-unknown", "Unknown markup %q in page %q, using the file extension instead", p.Markup, p.Path())
+		}
 		// Fall back to file extension (might also return "unknown")
-		p.Markup = helpers.GuessType(p.Source.Ext())
+		markup = helpers.GuessType(p.Source.Ext())
+		if markup == "markdown" {
+			markup = p.s.ContentSpec.Markup.DefaultMarkdownHandler
+		}
 	}
 
-	return p.Markup
+	return markup
 }
 
 func (p *Page) parse(reader io.Reader) error {
@@ -1549,6 +1643,11 @@ func (p *Page) parse(reader io.Reader) error {
 		return fmt.Errorf("failed to parse page metadata for %q: %s", p.File.Path(), err)
 	}
 
+	if len(p.s.frontMatterDefaults) > 0 {
+		m, _ := meta.(map[string]interface{})
+		meta = p.s.frontMatterDefaults.apply(p.File.Path(), p.Section(), m)
+	}
+
 	if meta != nil {
 		if err = p.update(meta); err != nil {
 			return err
@@ -1823,6 +1922,12 @@ func (p *Page) Scratch() *Scratch {
 	return p.scratch
 }
 
+// toTime converts the front matter value v to a time.Time, in the site's
+// time zone if v has none.
+func (p *Page) toTime(v interface{}) (time.Time, error) {
+	return helpers.ToTimeInLocationE(v, p.s.timeZone)
+}
+
 func (p *Page) Language() *helpers.Language {
 	p.initLanguage()
 	return p.language
@@ -1911,7 +2016,7 @@ func (p *Page) initLanguage() {
 
 		if language == nil {
 			// It can be a file named stefano.chiodino.md.
-			p.s.Log.WARN.Printf("Page language (if it is that) not found in multilang setup: %s.", p.lang)
+			helpers.Warnidf(p.s.Log, "warning-language-not-found", "Page language (if it is that) not found in multilang setup: %s.", p.lang)
 			language = ml.DefaultLang
 		}
 
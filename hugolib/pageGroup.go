@@ -19,6 +19,12 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/unicode/norm"
 )
 
 // PageGroup represents a group of pages, grouped by the key.
@@ -37,13 +43,17 @@ type mapKeyByInt struct{ mapKeyValues }
 
 func (s mapKeyByInt) Less(i, j int) bool { return s.mapKeyValues[i].Int() < s.mapKeyValues[j].Int() }
 
-type mapKeyByStr struct{ mapKeyValues }
+type mapKeyByStr struct {
+	mapKeyValues
+	c *collate.Collator
+}
 
 func (s mapKeyByStr) Less(i, j int) bool {
-	return s.mapKeyValues[i].String() < s.mapKeyValues[j].String()
+	return helpers.CompareStrings(s.c, s.mapKeyValues[i].String(), s.mapKeyValues[j].String()) < 0
 }
 
-func sortKeys(v []reflect.Value, order string) []reflect.Value {
+// sortKeys sorts the group keys, the string keys by the collator's rules.
+func sortKeys(v []reflect.Value, order string, c *collate.Collator) []reflect.Value {
 	if len(v) <= 1 {
 		return v
 	}
@@ -57,9 +67,9 @@ func sortKeys(v []reflect.Value, order string) []reflect.Value {
 		}
 	case reflect.String:
 		if order == "desc" {
-			sort.Sort(sort.Reverse(mapKeyByStr{v}))
+			sort.Sort(sort.Reverse(mapKeyByStr{v, c}))
 		} else {
-			sort.Sort(mapKeyByStr{v})
+			sort.Sort(mapKeyByStr{v, c})
 		}
 	}
 	return v
@@ -84,7 +94,8 @@ var (
 )
 
 // GroupBy groups by the value in the given field or method name and with the given order.
-// Valid values for order is asc, desc, rev and reverse.
+// Valid values for order is asc, desc, rev and reverse. String keys are
+// sorted by the collation rules of the pages' language.
 func (p Pages) GroupBy(key string, order ...string) (PagesGroup, error) {
 	if len(p) < 1 {
 		return nil, nil
@@ -142,7 +153,7 @@ func (p Pages) GroupBy(key string, order ...string) (PagesGroup, error) {
 		tmp.SetMapIndex(fv, reflect.Append(tmp.MapIndex(fv), ppv))
 	}
 
-	sortedKeys := sortKeys(tmp.MapKeys(), direction)
+	sortedKeys := sortKeys(tmp.MapKeys(), direction, p.newCollator())
 	r := make([]PageGroup, len(sortedKeys))
 	for i, k := range sortedKeys {
 		r[i] = PageGroup{Key: k.Interface(), Pages: tmp.MapIndex(k).Interface().([]*Page)}
@@ -152,7 +163,8 @@ func (p Pages) GroupBy(key string, order ...string) (PagesGroup, error) {
 }
 
 // GroupByParam groups by the given page parameter key's value and with the given order.
-// Valid values for order is asc, desc, rev and reverse.
+// Valid values for order is asc, desc, rev and reverse. String keys are
+// sorted by the collation rules of the pages' language.
 func (p Pages) GroupByParam(key string, order ...string) (PagesGroup, error) {
 	if len(p) < 1 {
 		return nil, nil
@@ -193,7 +205,7 @@ func (p Pages) GroupByParam(key string, order ...string) (PagesGroup, error) {
 	}
 
 	var r []PageGroup
-	for _, k := range sortKeys(tmp.MapKeys(), direction) {
+	for _, k := range sortKeys(tmp.MapKeys(), direction, p.newCollator()) {
 		r = append(r, PageGroup{Key: k.Interface(), Pages: tmp.MapIndex(k).Interface().([]*Page)})
 	}
 
@@ -296,3 +308,137 @@ func (p Pages) GroupByParamDate(key string, format string, order ...string) (Pag
 	}
 	return p.groupByDateField(sorter, formatter, order...)
 }
+
+// The key of the GroupByFirstLetter group with the pages not starting with a
+// letter.
+const firstLetterOther = "#"
+
+// GroupByFirstLetter groups by the first letter of the value in the given
+// field or method name or, if neither, page parameter, e.g. for A–Z index
+// pages:
+//
+//   {{ range .Pages.GroupByFirstLetter "Title" }}
+//   <h2>{{ .Key }}</h2>
+//   {{ range .Pages }}...{{ end }}
+//   {{ end }}
+//
+// The letters are upper case, with the accents removed unless the letter is
+// a letter of its own in the pages' language, e.g. Å in Swedish, and sorted
+// by the collation rules of the language. Valid values for order are asc,
+// desc, rev and reverse. The values not starting with a letter are grouped
+// in the last group, with the key "#".
+func (p Pages) GroupByFirstLetter(key string, order ...string) (PagesGroup, error) {
+	if len(p) < 1 {
+		return nil, nil
+	}
+
+	direction := "asc"
+
+	if len(order) > 0 && (strings.ToLower(order[0]) == "desc" || strings.ToLower(order[0]) == "rev" || strings.ToLower(order[0]) == "reverse") {
+		direction = "desc"
+	}
+
+	value, err := pageStringValueFunc(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lang      = p[0].Language()
+		toUpper   = unicode.ToUpper
+		collator  *collate.Collator
+		primary   *collate.Collator
+		keys      []string
+		groups    = make(map[string]Pages)
+		otherPage Pages
+	)
+
+	if lang != nil {
+		collator = lang.NewCollator()
+		primary = lang.NewCollator(collate.IgnoreCase, collate.IgnoreDiacritics, collate.IgnoreWidth)
+		switch strings.ToLower(lang.Lang) {
+		case "tr", "az":
+			toUpper = unicode.TurkishCase.ToUpper
+		}
+	}
+
+	for _, e := range p {
+		letter, ok := firstLetter(value(e), toUpper, primary)
+		if !ok {
+			otherPage = append(otherPage, e)
+			continue
+		}
+		if _, found := groups[letter]; !found {
+			keys = append(keys, letter)
+		}
+		groups[letter] = append(groups[letter], e)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		c := helpers.CompareStrings(collator, keys[i], keys[j])
+		if direction == "desc" {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	r := make(PagesGroup, 0, len(keys)+1)
+	for _, k := range keys {
+		r = append(r, PageGroup{Key: k, Pages: groups[k]})
+	}
+	if len(otherPage) > 0 {
+		r = append(r, PageGroup{Key: firstLetterOther, Pages: otherPage})
+	}
+
+	return r, nil
+}
+
+// firstLetter returns the first letter in s, skipping leading punctuation,
+// upper cased and without accents if the collator considers it the same
+// letter.
+func firstLetter(s string, toUpper func(rune) rune, primary *collate.Collator) (string, bool) {
+	for _, r := range s {
+		if unicode.IsPunct(r) || unicode.IsSpace(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		if !unicode.IsLetter(r) {
+			return "", false
+		}
+
+		letter := string(toUpper(r))
+
+		if primary != nil {
+			decomposed := []rune(norm.NFD.String(letter))
+			if len(decomposed) > 1 && primary.CompareString(letter, string(decomposed[0])) == 0 {
+				letter = string(decomposed[0])
+			}
+		}
+
+		return letter, true
+	}
+
+	return "", false
+}
+
+// pageStringValueFunc returns a func returning the string value of the
+// given field, method without arguments or param of a page.
+func pageStringValueFunc(key string) (func(p *Page) string, error) {
+	if m, ok := pagePtrType.MethodByName(key); ok {
+		if m.Type.NumIn() != 1 || m.Type.NumOut() == 0 || m.Type.NumOut() > 2 || m.Type.Out(0).Implements(errorType) {
+			return nil, errors.New(key + " is a Page method but you can't use it with GroupByFirstLetter")
+		}
+		return func(p *Page) string {
+			return cast.ToString(reflect.ValueOf(p).Method(m.Index).Call(nil)[0].Interface())
+		}, nil
+	}
+
+	if f, ok := pagePtrType.Elem().FieldByName(key); ok && f.PkgPath == "" {
+		return func(p *Page) string {
+			return cast.ToString(reflect.ValueOf(p).Elem().FieldByIndex(f.Index).Interface())
+		}, nil
+	}
+
+	return func(p *Page) string {
+		return cast.ToString(p.getParam(key, false))
+	}, nil
+}
@@ -15,10 +15,13 @@ package hugolib
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/gohugoio/hugo/helpers"
 	"github.com/spf13/cast"
 )
 
@@ -455,3 +458,61 @@ func TestGroupByParamDateWithEmptyPages(t *testing.T) {
 		t.Errorf("PagesGroup isn't empty. It should be %#v, got %#v", nil, groups)
 	}
 }
+
+func TestGroupByFirstLetter(t *testing.T) {
+	t.Parallel()
+
+	groupKeys := func(lang string, titles ...string) string {
+		s := newTestSite(t)
+		language := helpers.NewLanguage(lang, s.Cfg)
+		var pages Pages
+		for i, title := range titles {
+			p, err := s.NewPage(filepath.FromSlash(fmt.Sprintf("/p%d.md", i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.Title = title
+			p.language = language
+			pages = append(pages, p)
+		}
+
+		groups, err := pages.GroupByFirstLetter("Title")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var keys []string
+		for _, g := range groups {
+			keys = append(keys, fmt.Sprintf("%s:%d", g.Key, len(g.Pages)))
+		}
+		return strings.Join(keys, " ")
+	}
+
+	for i, test := range []struct {
+		lang   string
+		titles []string
+		expect string
+	}{
+		{"en", []string{"banana", "Apple", "Äpple", "\"Quoted\"", "42", "Éclair", "ångström"}, "A:3 B:1 E:1 Q:1 #:1"},
+		{"sv", []string{"banana", "Apple", "Äpple", "ångström", "Öl", "Éclair"}, "A:1 B:1 E:1 Å:1 Ä:1 Ö:1"},
+		{"tr", []string{"istanbul", "Izmir"}, "I:1 İ:1"},
+	} {
+		if got := groupKeys(test.lang, test.titles...); got != test.expect {
+			t.Errorf("[%d] got %q, expected %q", i, got, test.expect)
+		}
+	}
+
+	pages := preparePageGroupTestPages(t)
+
+	groups, err := pages.GroupByFirstLetter("custom_param", "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Key != "F" || groups[1].Key != "B" || len(groups[1].Pages) != 3 {
+		t.Errorf("unexpected groups %v", groups)
+	}
+
+	if _, err := pages.GroupByFirstLetter("Render"); err == nil {
+		t.Error("expected error for method with arguments")
+	}
+}
@@ -14,8 +14,11 @@
 package hugolib
 
 import (
-	"github.com/spf13/cast"
 	"sort"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
+	"golang.org/x/text/collate"
 )
 
 var spc = newPageCache()
@@ -119,7 +122,17 @@ func (p Pages) ByWeight() Pages {
 	return pages
 }
 
-// ByTitle sorts the Pages by title and returns a copy.
+// newCollator returns a new collator for the language of the pages, taken
+// from the first page.
+func (p Pages) newCollator() *collate.Collator {
+	if len(p) == 0 || p[0].Language() == nil {
+		return nil
+	}
+	return p[0].Language().NewCollator()
+}
+
+// ByTitle sorts the Pages by title, using the collation rules of the pages'
+// language, and returns a copy.
 //
 // Adjacent invocations on the same receiver will return a cached result.
 //
@@ -128,15 +141,18 @@ func (p Pages) ByTitle() Pages {
 
 	key := "pageSort.ByTitle"
 
+	c := p.newCollator()
+
 	title := func(p1, p2 *Page) bool {
-		return p1.Title < p2.Title
+		return helpers.CompareStrings(c, p1.Title, p2.Title) < 0
 	}
 
 	pages, _ := spc.get(key, p, pageBy(title).Sort)
 	return pages
 }
 
-// ByLinkTitle sorts the Pages by link title and returns a copy.
+// ByLinkTitle sorts the Pages by link title, using the collation rules of
+// the pages' language, and returns a copy.
 //
 // Adjacent invocations on the same receiver will return a cached result.
 //
@@ -145,8 +161,10 @@ func (p Pages) ByLinkTitle() Pages {
 
 	key := "pageSort.ByLinkTitle"
 
+	c := p.newCollator()
+
 	linkTitle := func(p1, p2 *Page) bool {
-		return p1.linkTitle < p2.linkTitle
+		return helpers.CompareStrings(c, p1.linkTitle, p2.linkTitle) < 0
 	}
 
 	pages, _ := spc.get(key, p, pageBy(linkTitle).Sort)
@@ -0,0 +1,100 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var contentLinkRe = regexp.MustCompile(`(?i)<a\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// siteBacklinks holds the pages linking to each page in a site, collected
+// from the links in the pages' rendered content.
+type siteBacklinks struct {
+	init  sync.Once
+	pages map[*Page]Pages
+}
+
+// Backlinks returns the pages in this site linking to this page in their
+// content, e.g. to show a "referenced by" list. Links added by the templates
+// are not included.
+func (p *Page) Backlinks() Pages {
+	b := p.s.backlinks
+	if b == nil {
+		return nil
+	}
+
+	b.init.Do(func() {
+		b.pages = collectBacklinks(p.s)
+	})
+
+	return b.pages[p]
+}
+
+func collectBacklinks(s *Site) map[*Page]Pages {
+	var (
+		byURL   = make(map[string]*Page)
+		links   = make(map[*Page]Pages)
+		baseURL = s.PathSpec.BaseURL.URL()
+	)
+
+	for _, p := range s.Pages {
+		byURL[backlinkKey(p.RelPermalink())] = p
+	}
+
+	for _, p := range s.Pages {
+		base, err := url.Parse(p.RelPermalink())
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[*Page]bool)
+
+		for _, m := range contentLinkRe.FindAllStringSubmatch(string(p.Content), -1) {
+			href := m[1]
+			if href == "" {
+				href = m[2]
+			}
+
+			u, err := url.Parse(href)
+			if err != nil || (u.Host != "" && u.Host != baseURL.Host) || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+				continue
+			}
+
+			target, found := byURL[backlinkKey(base.ResolveReference(u).Path)]
+			if !found || target == p || seen[target] {
+				continue
+			}
+
+			seen[target] = true
+			links[target] = append(links[target], p)
+		}
+	}
+
+	for _, pages := range links {
+		pages.Sort()
+	}
+
+	return links
+}
+
+// backlinkKey normalizes the URL path so /posts/p1, /posts/p1/ and
+// /posts/p1/index.html are the same page.
+func backlinkKey(p string) string {
+	p = strings.TrimSuffix(p, "index.html")
+	return strings.TrimSuffix(p, "/")
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageBacklinks(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/notes/a.md", "---\ntitle: A\nweight: 1\n---\nSee [B](/notes/b/) and [C]({{< relref \"c.md\" >}}).",
+		"content/notes/b.md", "---\ntitle: B\nweight: 2\n---\nBack to [A](../a/#top), [A again](http://example.com/notes/a/) and [myself](/notes/b/).",
+		"content/notes/c.md", "---\ntitle: C\nweight: 3\n---\nSee [B](https://example.com/notes/b/index.html) and [elsewhere](https://example.org/notes/a/).",
+		"layouts/_default/single.html", `Backlinks:{{ range .Backlinks }}{{ .Title }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/notes/a/index.html", "Backlinks:B|")
+	th.assertFileContent("public/notes/b/index.html", "Backlinks:A|C|")
+	th.assertFileContent("public/notes/c/index.html", "Backlinks:A|")
+
+	s := h.Sites[0]
+	assert.Len(s.getPage(KindPage, "notes/a.md").Backlinks(), 1)
+	assert.Len(s.getPage(KindHome).Backlinks(), 0)
+}
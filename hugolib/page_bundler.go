@@ -124,7 +124,9 @@ func (s *siteContentProcessor) process(ctx context.Context) error {
 						return nil
 					}
 					for _, filename := range filenames {
-						name := strings.TrimPrefix(filename, s.baseDir)
+						name := s.site.SourceSpec.NormalizeFilename(strings.TrimPrefix(filename, s.baseDir))
+						name = s.site.PathSpec.MakeWindowsSafePublishPath(name)
+						s.site.checkPublishPath(name, filename)
 						f, err := s.site.Fs.Source.Open(filename)
 						if err != nil {
 							return err
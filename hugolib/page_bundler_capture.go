@@ -22,13 +22,16 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/gohugoio/hugo/source"
 	"github.com/spf13/afero"
 	jww "github.com/spf13/jwalterweatherman"
+	"golang.org/x/text/unicode/norm"
 )
 
 var errSkipCyclicDir = errors.New("skip potential cyclic dir")
@@ -43,6 +46,7 @@ type capturer struct {
 	sourceSpec *source.SourceSpec
 	fs         afero.Fs
 	logger     *jww.Notepad
+	warnLog    *helpers.DistinctLogger
 
 	baseDir string
 
@@ -53,6 +57,13 @@ type capturer struct {
 	// Used to determine how to handle content changes in server mode.
 	contentChanges *contentChangeMap
 
+	// The absolute filenames/directories set in renderOnly. If set, only these
+	// and the sections owning them are captured. Only used in server mode.
+	renderOnly []string
+
+	// The patterns in the .hugoignore file in the content root, nil if none.
+	ignore *source.IgnorePatterns
+
 	// Semaphore used to throttle the concurrent sub directory handling.
 	sem chan bool
 }
@@ -74,9 +85,17 @@ func newCapturer(
 		handler:        handler,
 		sourceSpec:     sourceSpec,
 		logger:         logger,
+		warnLog:        helpers.NewDistinctLogger(logger.WARN),
 		contentChanges: contentChanges,
 		fs:             sourceSpec.Fs.Source, baseDir: baseDir, seen: make(map[string]bool),
-		filenames: filenames}
+		filenames:  filenames,
+		renderOnly: renderOnlyFilenames(sourceSpec.Cfg, baseDir)}
+
+	ignore, err := source.LoadIgnoreFile(c.fs, baseDir)
+	if err != nil {
+		logger.ERROR.Println(err)
+	}
+	c.ignore = ignore
 
 	return c
 }
@@ -122,6 +141,10 @@ func (c *capturer) capturePartial(filenames ...string) error {
 	handled := make(map[string]bool)
 
 	for _, filename := range filenames {
+		if !c.isRenderOnly(filename) {
+			continue
+		}
+
 		dir, resolvedFilename, tp := c.contentChanges.resolveAndRemove(filename)
 		if handled[resolvedFilename] {
 			continue
@@ -145,6 +168,10 @@ func (c *capturer) capturePartial(filenames ...string) error {
 				continue
 			}
 
+			if c.isIgnored(resolvedFilename, false) {
+				continue
+			}
+
 			// Just in case the owning dir is a new symlink -- this will
 			// create the proper mapping for it.
 			c.getRealFileInfo(dir)
@@ -162,6 +189,10 @@ func (c *capturer) capture() error {
 		return c.capturePartial(c.filenames...)
 	}
 
+	if len(c.renderOnly) > 0 {
+		return c.captureRenderOnly()
+	}
+
 	err := c.handleDir(c.baseDir)
 	if err != nil {
 		return err
@@ -170,6 +201,135 @@ func (c *capturer) capture() error {
 	return nil
 }
 
+// renderOnlyFilenames returns the absolute filenames of the content
+// files/directories in the renderOnly config, relative to the content dir.
+func renderOnlyFilenames(cfg config.Provider, baseDir string) []string {
+	var filenames []string
+	for _, p := range cast.ToStringSlice(cfg.Get("renderOnly")) {
+		p = filepath.Clean(filepath.FromSlash(p))
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(baseDir, p)
+		}
+		filenames = append(filenames, p)
+	}
+	return filenames
+}
+
+// isRenderOnly reports whether the file is one of the renderOnly files, or
+// below one of the renderOnly directories, or is a branch bundle owning one
+// of them, e.g. a section's _index.md.
+func (c *capturer) isRenderOnly(filename string) bool {
+	if len(c.renderOnly) == 0 {
+		return true
+	}
+
+	for _, p := range c.renderOnly {
+		if filename == p || strings.HasPrefix(filename, p+helpers.FilePathSeparator) {
+			return true
+		}
+	}
+
+	dir := filepath.Dir(filename)
+	for ancestor := range c.renderOnlyAncestors() {
+		if dir == ancestor {
+			if tp, _ := classifyBundledFile(filepath.Base(filename)); tp == bundleBranch {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// renderOnlyAncestors returns the directories owning the renderOnly files,
+// up to and including the content dir.
+func (c *capturer) renderOnlyAncestors() map[string]bool {
+	ancestors := make(map[string]bool)
+	for _, p := range c.renderOnly {
+		for dir := filepath.Dir(p); strings.HasPrefix(dir, c.baseDir); dir = filepath.Dir(dir) {
+			ancestors[dir] = true
+			if dir == c.baseDir || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return ancestors
+}
+
+// captureRenderOnly captures the content in renderOnly, and the sections
+// owning it, instead of the full content dir. This makes it possible to
+// quickly preview single pages of big sites.
+func (c *capturer) captureRenderOnly() error {
+	handled := make(map[string]bool)
+
+	for _, filename := range c.renderOnly {
+		fi, _, err := c.getRealFileInfo(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				c.warnLog.Printidf("warning-render-only-missing", "renderOnly: %q not found", filename)
+				continue
+			}
+			return err
+		}
+
+		if !fi.IsDir() {
+			switch tp, _ := classifyBundledFile(fi.Name()); tp {
+			case bundleLeaf:
+				// Capture the full bundle.
+				filename = filepath.Dir(filename)
+				fi, _, err = c.getRealFileInfo(filename)
+				if err != nil {
+					return err
+				}
+			case bundleBranch:
+				// Handled below.
+				continue
+			}
+		}
+
+		if handled[filename] {
+			continue
+		}
+		handled[filename] = true
+
+		if fi.IsDir() {
+			if err := c.handleDir(filename); err != nil {
+				return err
+			}
+		} else {
+			c.copyOrHandleSingle(c.newFileInfo(filename, fi, bundleNot))
+		}
+	}
+
+	for dir := range c.renderOnlyAncestors() {
+		if handled[dir] {
+			continue
+		}
+
+		files, err := c.readDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		for _, fi := range files {
+			if fi.IsDir() {
+				continue
+			}
+			if tp, _ := classifyBundledFile(fi.Name()); tp == bundleBranch {
+				if err := c.handleBranchDir(dir); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *capturer) handleNestedDir(dirname string) error {
 	select {
 	case c.sem <- true:
@@ -484,6 +644,8 @@ func (c *capturer) readDir(dirname string) ([]fileInfoName, error) {
 		return nil, err
 	}
 
+	c.checkNormalizationForms(dirname, names)
+
 	fis := make([]fileInfoName, 0, len(names))
 
 	for _, name := range names {
@@ -499,6 +661,10 @@ func (c *capturer) readDir(dirname string) ([]fileInfoName, error) {
 				return nil, err
 			}
 
+			if c.isIgnored(filename, fi.IsDir()) {
+				continue
+			}
+
 			fis = append(fis, fileInfoName{filename: filename, FileInfo: fi})
 		}
 	}
@@ -506,6 +672,36 @@ func (c *capturer) readDir(dirname string) ([]fileInfoName, error) {
 	return fis, nil
 }
 
+// isIgnored reports whether the file, or any of the directories above it, is
+// matched by the patterns in the .hugoignore file in the content root.
+func (c *capturer) isIgnored(filename string, isDir bool) bool {
+	if c.ignore == nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(c.baseDir, filename)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	return c.ignore.MatchWithParents(filepath.ToSlash(rel), isDir)
+}
+
+// checkNormalizationForms warns about the names in the dir that differ by
+// Unicode normalization form only, e.g. a file created on macOS (NFD) and
+// a copy created on Linux (NFC). They resolve to the same path.
+func (c *capturer) checkNormalizationForms(dirname string, names []string) {
+	seen := make(map[string]string)
+	for _, name := range names {
+		nfc := norm.NFC.String(name)
+		if other, found := seen[nfc]; found && other != name {
+			c.warnLog.Printf("%q and %q in %s differ only by Unicode normalization form and resolve to the same path", other, name, dirname)
+			continue
+		}
+		seen[nfc] = name
+	}
+}
+
 func (c *capturer) newFileInfo(filename string, fi os.FileInfo, tp bundleDirType) *fileInfo {
 	return newFileInfo(c.sourceSpec, c.baseDir, filename, fi, tp)
 }
@@ -603,7 +799,7 @@ func (c *capturer) isSeen(dirname string) bool {
 	seen := c.seen[dirname]
 	c.seen[dirname] = true
 	if seen {
-		c.logger.WARN.Printf("Content dir %q already processed; skipped to avoid infinite recursion.", dirname)
+		c.warnLog.Printidf("warning-content-dir-recursion", "Content dir %q already processed; skipped to avoid infinite recursion.", dirname)
 		return true
 
 	}
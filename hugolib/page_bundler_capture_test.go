@@ -253,3 +253,58 @@ func BenchmarkPageBundlerCapture(b *testing.B) {
 		}
 	}
 }
+
+func TestPageBundlerCaptureIgnoreFile(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+	cfg, fs := newTestCfg()
+
+	for _, filename := range []string{
+		"content/a/1.md",
+		"content/a/1.md.swp",
+		"content/scratch/2.md",
+		"content/b/scratch/3.md",
+		"content/b/index.md",
+		"content/b/logo.png",
+		"content/b/logo.png.bak",
+		"content/drafts/4.md",
+		"content/c/drafts/5.md",
+	} {
+		writeSource(t, fs, filename, "content")
+	}
+
+	writeSource(t, fs, "content/.hugoignore", "*.swp\n*.bak\nscratch/\n/drafts\n")
+
+	sourceSpec := source.NewSourceSpec(cfg, fs)
+	fileStore := &storeFilenames{}
+	c := newCapturer(newErrorLogger(), sourceSpec, fileStore, nil, "content")
+
+	assert.NoError(c.capture())
+
+	expected := `
+F:
+content/a/1.md
+content/c/drafts/5.md
+D:
+__bundle/en/content/b/index.md/resources/en/content/b/logo.png
+C:
+
+`
+
+	got := fileStore.sortedStr()
+
+	if expected != got {
+		diff := helpers.DiffStringSlices(strings.Fields(expected), strings.Fields(got))
+		t.Log(got)
+		t.Fatalf("Failed:\n%s", diff)
+	}
+
+	// Partial builds.
+	fileStore = &storeFilenames{}
+	c = newCapturer(newErrorLogger(), sourceSpec, fileStore, &contentChangeMap{symContent: make(map[string]map[string]bool)}, "content",
+		filepath.FromSlash("content/a/1.md"), filepath.FromSlash("content/scratch/2.md"), filepath.FromSlash("content/a/1.md.swp"))
+
+	assert.NoError(c.capture())
+	assert.Equal([]string{"content/a/1.md"}, fileStore.filenames)
+}
@@ -225,8 +225,10 @@ func (c *contentHandlers) parsePage(h contentHandler) contentHandler {
 
 		if ctx.bundle != nil {
 			// Add the bundled files
+			targets := make(map[string]bool)
 			for _, fi := range ctx.bundle.resources {
 				childCtx := ctx.childCtx(fi)
+				targets[childCtx.target] = true
 				res := c.rootHandler(childCtx)
 				if res.err != nil {
 					return res
@@ -236,6 +238,10 @@ func (c *contentHandlers) parsePage(h contentHandler) contentHandler {
 				}
 			}
 
+			if err := c.addSharedResources(ctx, targets); err != nil {
+				return handlerResult{err: err}
+			}
+
 			sort.SliceStable(p.Resources, func(i, j int) bool {
 				if p.Resources[i].ResourceType() < p.Resources[j].ResourceType() {
 					return true
@@ -252,7 +258,7 @@ func (c *contentHandlers) parsePage(h contentHandler) contentHandler {
 					return defaultPageSort(p1, p2)
 				}
 
-				return p.Resources[i].RelPermalink() < p.Resources[j].RelPermalink()
+				return resourceSortKey(p.Resources[i]) < resourceSortKey(p.Resources[j])
 			})
 		}
 
@@ -319,7 +325,7 @@ func (c *contentHandlers) createResource() contentHandler {
 		}
 
 		resource, err := c.s.resourceSpec.NewResourceFromFilename(
-			ctx.parentPage.subResourceLinkFactory,
+			ctx.parentPage.resourceLinkFactory(c.s.resourceSpec.ResourceTypeFor(ctx.target)),
 			c.s.absPublishDir(),
 			ctx.source.Filename(), ctx.target)
 
@@ -334,7 +340,9 @@ func (c *contentHandlers) copyFile() contentHandler {
 			return handlerResult{err: err}
 		}
 
-		target := ctx.targetPath()
+		target := c.s.SourceSpec.NormalizeFilename(ctx.targetPath())
+		target = c.s.PathSpec.MakeWindowsSafePublishPath(target)
+		c.s.checkPublishPath(target, ctx.source.Filename())
 
 		defer f.Close()
 		if err := c.s.publish(&c.s.PathSpec.ProcessingStats.Files, target, f); err != nil {
@@ -344,3 +352,15 @@ func (c *contentHandlers) copyFile() contentHandler {
 		return handlerResult{handled: true}
 	}
 }
+
+// resourceSortKey returns the key to sort the bundled resources by. This is
+// the path in the bundle, which is stable even if the resource is published to
+// another location, see deduplicateResources and resourcePermalinks.
+func resourceSortKey(r resource.Resource) string {
+	if n, ok := r.(interface {
+		Name() string
+	}); ok {
+		return n.Name()
+	}
+	return r.RelPermalink()
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// resourcesFrom returns the directories, relative to the content dir, to add
+// to the bundle's resources, set in front matter or as resourcesFrom in the
+// site config:
+//
+//   resourcesFrom = ["shared-images"]
+func (p *Page) resourcesFrom() []string {
+	if v, found := p.Params["resourcesfrom"]; found {
+		return cast.ToStringSlice(v)
+	}
+	return cast.ToStringSlice(p.s.Cfg.Get("resourcesFrom"))
+}
+
+// addSharedResources adds the files in the page's resourcesFrom directories
+// to its resources as if they were in the bundle. The bundle's own files
+// with the same path take precedence.
+func (c *contentHandlers) addSharedResources(ctx *handlerContext, targets map[string]bool) error {
+	p := ctx.currentPage
+	dirs := p.resourcesFrom()
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	contentDir := c.s.absContentDir()
+
+	for _, dir := range dirs {
+		dir = filepath.Join(contentDir, filepath.FromSlash(strings.Trim(dir, "/")))
+		if !strings.HasPrefix(dir, contentDir) {
+			return fmt.Errorf("%s: resourcesFrom %q must be inside the content dir", p.File.Path(), dir)
+		}
+
+		if exists, _ := helpers.DirExists(dir, c.s.Fs.Source); !exists {
+			c.s.Log.WARN.Printf("%s: resourcesFrom directory %q not found", p.File.Path(), dir)
+			continue
+		}
+
+		var filenames []string
+		err := afero.Walk(c.s.Fs.Source, dir, func(filename string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return nil
+			}
+			name := fi.Name()
+			if strings.HasPrefix(name, ".") || contentFileExtensionsSet[strings.TrimPrefix(filepath.Ext(name), ".")] {
+				// Content files are not shareable, they are pages in
+				// their own section.
+				return nil
+			}
+			filenames = append(filenames, filename)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(filenames)
+
+		for _, filename := range filenames {
+			target := strings.TrimPrefix(filename, dir+helpers.FilePathSeparator)
+			if targets[target] {
+				continue
+			}
+			targets[target] = true
+
+			r, err := c.s.resourceSpec.NewResourceFromFilename(
+				p.resourceLinkFactory(c.s.resourceSpec.ResourceTypeFor(target)),
+				c.s.absPublishDir(),
+				filename, target)
+			if err != nil {
+				return err
+			}
+
+			p.Resources = append(p.Resources, r)
+		}
+	}
+
+	return nil
+}
@@ -440,3 +440,108 @@ TheContent.
 
 	return cfg, fs, workDir
 }
+
+func TestPageBundlerDeduplicateResources(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	sunset, err := ioutil.ReadFile("testdata/sunset.jpg")
+	assert.NoError(err)
+
+	config := `
+baseURL = "http://example.com/"
+deduplicateResources = true
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a/index.md", "---\ntitle: A\n---\n",
+		"content/a/sunset.jpg", string(sunset),
+		"content/a/data.json", "{}",
+		"content/b/index.md", "---\ntitle: B\n---\n",
+		"content/b/sunset.jpg", string(sunset),
+		"layouts/_default/single.html", `{{ range .Resources }}{{ .RelPermalink }}|{{ end }}{{ with .Resources.GetMatch "*.jpg" }}{{ (.Resize "10x").RelPermalink }}{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	s := h.Sites[0]
+	a := s.getPage(KindPage, "a/index.md")
+	assert.NotNil(a)
+	assert.Len(a.Resources, 2)
+
+	shared := a.Resources.GetMatch("*.jpg").RelPermalink()
+	assert.Regexp(`^/_shared/[a-f0-9]{32}/sunset.jpg$`, shared)
+
+	th.assertFileContent("public/a/index.html", shared+"|", "/a/data.json|", strings.TrimSuffix(shared, ".jpg")+"_hu")
+	th.assertFileContent("public/b/index.html", shared+"|", strings.TrimSuffix(shared, ".jpg")+"_hu")
+	th.assertFileNotExist("public/a/sunset.jpg")
+	th.assertFileNotExist("public/b/sunset.jpg")
+	_, err = th.Fs.Destination.Stat(filepath.FromSlash("public" + shared))
+	assert.NoError(err)
+}
+
+func TestPageBundlerResourcesFrom(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a/index.md", "---\ntitle: A\nresourcesFrom: [\"shared-images\"]\n---\n",
+		"content/a/logo.png", "own logo",
+		"content/b/index.md", "---\ntitle: B\n---\n",
+		"content/b/logo.png", "b logo",
+		"content/shared-images/logo.png", "shared logo",
+		"content/shared-images/icons/star.svg", "star",
+		"content/shared-images/about.md", "---\ntitle: About\n---\n",
+		"layouts/_default/single.html", `{{ range .Resources }}{{ .RelPermalink }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	s := h.Sites[0]
+	a := s.getPage(KindPage, "a/index.md")
+	assert.NotNil(a)
+	assert.Len(a.Resources, 2)
+	assert.NotNil(a.Resources.GetMatch("icons/star.svg"))
+
+	th.assertFileContent("public/a/index.html", "/a/logo.png|", "/a/icons/star.svg|")
+	th.assertFileContent("public/a/logo.png", "own logo")
+	th.assertFileContent("public/a/icons/star.svg", "star")
+
+	b := s.getPage(KindPage, "b/index.md")
+	assert.NotNil(b)
+	assert.Len(b.Resources, 1)
+}
+
+func TestPageBundlerResourcePermalinks(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[resourcePermalinks]
+image = "/img/:section/:slug/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/blog/a/index.md", "---\ntitle: A\nslug: my-a\n---\n",
+		"content/blog/a/logo.png", "logo",
+		"content/blog/a/photos/1.jpg", "photo",
+		"content/blog/a/data.json", "{}",
+		"layouts/_default/single.html", `{{ range .Resources }}{{ .RelPermalink }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/blog/a/my-a/index.html", "/img/blog/my-a/logo.png|", "/img/blog/my-a/photos/1.jpg|", "/blog/a/my-a/data.json|")
+	th.assertFileContent("public/img/blog/my-a/logo.png", "logo")
+	th.assertFileContent("public/img/blog/my-a/photos/1.jpg", "photo")
+	th.assertFileContent("public/blog/a/my-a/data.json", "{}")
+	th.assertFileNotExist("public/blog/a/my-a/logo.png")
+}
@@ -43,10 +43,21 @@ type PageCollections struct {
 	// Includes absolute all pages (of all types), including drafts etc.
 	rawAllPages Pages
 
+	// The unlisted pages, left out of the collections above when the site
+	// is assembled, but still rendered. This is for the current language only.
+	unlistedPages Pages
+
+	// The headless pages. These are left out of all of the collections above
+	// and never rendered. This is for the current language only.
+	headlessPages Pages
+
 	pageCache *cache.PartitionedLazyCache
 }
 
 func (c *PageCollections) refreshPageCaches() {
+	c.Pages, c.unlistedPages = splitUnlistedPages(c.Pages)
+	c.AllPages, _ = splitUnlistedPages(c.AllPages)
+
 	c.indexPages = c.findPagesByKindNotIn(KindPage, c.Pages)
 	c.RegularPages = c.findPagesByKindIn(KindPage, c.Pages)
 	c.AllRegularPages = c.findPagesByKindIn(KindPage, c.AllPages)
@@ -60,7 +71,8 @@ func (c *PageCollections) refreshPageCaches() {
 				// in this cache, as we intend to use this in the ref and relref
 				// shortcodes. If the user says "sect/doc1.en.md", he/she knows
 				// what he/she is looking for.
-				for _, p := range c.AllRegularPages {
+				pages := append(c.findPagesByKindIn(KindPage, c.unlistedPages), c.headlessPages...)
+				for _, p := range append(pages, c.AllRegularPages...) {
 					cache[filepath.ToSlash(p.Source.Path())] = p
 					// Ref/Relref supports this potentially ambiguous lookup.
 					cache[p.Source.LogicalName()] = p
@@ -85,6 +97,35 @@ func (c *PageCollections) refreshPageCaches() {
 	c.pageCache = cache.NewPartitionedLazyCache(partitions...)
 }
 
+// splitUnlistedPages splits the pages into the listed and the unlisted pages.
+func splitUnlistedPages(pages Pages) (listed, unlisted Pages) {
+	for _, p := range pages {
+		if p.Unlisted {
+			unlisted = append(unlisted, p)
+		} else {
+			listed = append(listed, p)
+		}
+	}
+	return
+}
+
+// pagesToRender returns the pages to render, including the unlisted pages.
+func (c *PageCollections) pagesToRender() Pages {
+	if len(c.unlistedPages) == 0 {
+		return c.Pages
+	}
+	return append(append(Pages{}, c.Pages...), c.unlistedPages...)
+}
+
+// pagesToPrepare returns the pages to prepare for rendering. The headless
+// pages are not rendered, but their content may be used by other pages.
+func (c *PageCollections) pagesToPrepare() Pages {
+	if len(c.headlessPages) == 0 {
+		return c.pagesToRender()
+	}
+	return append(append(Pages{}, c.pagesToRender()...), c.headlessPages...)
+}
+
 func newPageCollections() *PageCollections {
 	return &PageCollections{}
 }
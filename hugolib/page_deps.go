@@ -0,0 +1,361 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template/parse"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/tpl"
+)
+
+const (
+	// The template uses other pages in a way we cannot narrow down, e.g.
+	// .Site.Pages, .Site.Taxonomies or .NextInSection.
+	pageDepAll = "*"
+
+	// The template uses the pages in the page's own list, e.g. .Pages or
+	// .Paginator.
+	pageDepOwn = "."
+)
+
+// Field and method names giving access to other pages than the current.
+var (
+	sitePageFields = map[string]bool{
+		"Pages":        true,
+		"RegularPages": true,
+		"AllPages":     true,
+		"Sections":     true,
+		"Taxonomies":   true,
+		"Home":         true,
+		"Menus":        true,
+		"Collections":  true,
+	}
+
+	pageNeighbourFields = map[string]bool{
+		"Next":          true,
+		"Prev":          true,
+		"NextPage":      true,
+		"PrevPage":      true,
+		"NextInSection": true,
+		"PrevInSection": true,
+		"NextInSeries":  true,
+		"PrevInSeries":  true,
+		"Series":        true,
+		"Parent":        true,
+		"Sections":      true,
+		"Backlinks":     true,
+	}
+
+	pageListFields = map[string]bool{
+		"Pages":     true,
+		"Paginator": true,
+		"Paginate":  true,
+	}
+)
+
+// pageDependencies keeps track of the pages rendered with templates using
+// other pages, e.g. a list with summaries or a .GetPage call, so these can
+// be rendered again in fast render mode when the other pages change. Without
+// this, only the changed page itself and the recently visited pages are
+// rendered, and the other pages are stale until the next full build.
+type pageDependencies struct {
+	mu sync.RWMutex
+
+	// Page key, see dataPageKey => page dependencies. These are
+	// pageDepAll, pageDepOwn or the path of a page looked up by .GetPage.
+	pages map[string]map[string]bool
+
+	// Page key => page dependencies of the shortcodes in the page content.
+	// The content needs to be processed again when these change.
+	content map[string]map[string]bool
+
+	// Template name => page dependencies, including the partials and
+	// templates it calls.
+	templates map[string]map[string]bool
+}
+
+func newPageDependencies() *pageDependencies {
+	d := &pageDependencies{}
+	d.reset()
+	return d
+}
+
+func (d *pageDependencies) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pages = make(map[string]map[string]bool)
+	d.content = make(map[string]map[string]bool)
+	d.templates = make(map[string]map[string]bool)
+}
+
+// resetTemplates clears the template analysis, needed when the templates
+// change.
+func (d *pageDependencies) resetTemplates() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.templates = make(map[string]map[string]bool)
+}
+
+// addTemplate records that the page is rendered with the given template.
+func (d *pageDependencies) addTemplate(p *Page, templ tpl.Template, finder tpl.TemplateFinder) {
+	if d == nil || p == nil {
+		return
+	}
+
+	d.add(d.pages, p, d.templateDeps(templ, finder))
+}
+
+// addShortcode records that the page content is rendered with the given
+// shortcode template.
+func (d *pageDependencies) addShortcode(p *Page, templ tpl.Template, finder tpl.TemplateFinder) {
+	if d == nil || p == nil {
+		return
+	}
+
+	d.add(d.content, p, d.templateDeps(templ, finder))
+}
+
+// addContentPage records that the page content includes the content of the
+// page with the given dependency path, see pageDepPath.
+func (d *pageDependencies) addContentPage(p *Page, depPath string) {
+	if d == nil || p == nil {
+		return
+	}
+
+	d.add(d.content, p, map[string]bool{depPath: true})
+}
+
+func (d *pageDependencies) add(m map[string]map[string]bool, p *Page, deps map[string]bool) {
+	if len(deps) == 0 {
+		return
+	}
+
+	key := dataPageKey(p)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pageDeps, found := m[key]
+	if !found {
+		pageDeps = make(map[string]bool)
+		m[key] = pageDeps
+	}
+	for k := range deps {
+		pageDeps[k] = true
+	}
+}
+
+// dependsOn reports whether the page needs to be rendered again when the
+// content files given change. If structural is set, pages may have been
+// added or removed.
+func (d *pageDependencies) dependsOn(p *Page, changed map[string]bool, structural bool) bool {
+	if d == nil || len(changed) == 0 {
+		return false
+	}
+
+	if p.File != nil && changed[p.File.Filename()] {
+		return true
+	}
+
+	return d.depsOn(d.pages, p, changed, structural)
+}
+
+// contentDependsOn reports whether the content of the page needs to be
+// processed again when the content files given change, as its shortcodes
+// use them.
+func (d *pageDependencies) contentDependsOn(p *Page, changed map[string]bool, structural bool) bool {
+	if d == nil || len(changed) == 0 {
+		return false
+	}
+
+	return d.depsOn(d.content, p, changed, structural)
+}
+
+func (d *pageDependencies) depsOn(m map[string]map[string]bool, p *Page, changed map[string]bool, structural bool) bool {
+	d.mu.RLock()
+	deps := m[dataPageKey(p)]
+	d.mu.RUnlock()
+
+	if len(deps) == 0 {
+		return false
+	}
+
+	if deps[pageDepAll] {
+		return true
+	}
+
+	if deps[pageDepOwn] {
+		if structural && p.Kind != KindPage {
+			return true
+		}
+		for _, pp := range p.Pages {
+			if pp.File != nil && changed[pp.File.Filename()] {
+				return true
+			}
+		}
+	}
+
+	for filename := range changed {
+		depPath := pageDepPath(filename, p.s.absContentDir())
+		if deps[depPath] {
+			return true
+		}
+
+		// Translations, e.g. posts/my-post.fr.md, may be looked up without
+		// the language.
+		if lang := path.Ext(depPath); lang != "" {
+			if _, ok := p.s.SourceSpec.Languages[lang[1:]]; ok && deps[strings.TrimSuffix(depPath, lang)] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// pageDepPath returns the path used for the content file in the page
+// dependencies, its path relative to the content dir without extension,
+// e.g. posts/my-post.
+func pageDepPath(filename, contentDir string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(filename, contentDir), helpers.FilePathSeparator)
+	rel = filepath.ToSlash(rel)
+	return strings.ToLower(strings.TrimSuffix(rel, path.Ext(rel)))
+}
+
+// templateDeps returns the page dependencies of the template.
+func (d *pageDependencies) templateDeps(templ tpl.Template, finder tpl.TemplateFinder) map[string]bool {
+	if templ == nil {
+		return nil
+	}
+
+	d.mu.RLock()
+	deps, found := d.templates[templ.Name()]
+	d.mu.RUnlock()
+	if found {
+		return deps
+	}
+
+	deps = make(map[string]bool)
+	w := newTemplateWalker(templ, finder)
+	w.unknown = func() { deps[pageDepAll] = true }
+	w.command = func(n *parse.CommandNode) bool {
+		collectGetPageDeps(deps, n)
+		return false
+	}
+	w.ident = func(ident []string) {
+		collectPageDeps(deps, ident)
+	}
+	w.walkTemplate(templ.Name(), templateTree(templ, ""))
+
+	d.mu.Lock()
+	d.templates[templ.Name()] = deps
+	d.mu.Unlock()
+
+	return deps
+}
+
+// collectPageDeps records the page dependencies in field chains such as
+// .Site.RegularPages, .Paginator.Pages or .NextInSection.
+func collectPageDeps(deps map[string]bool, ident []string) {
+	for i, field := range ident {
+		if field == "Site" && i+1 < len(ident) && sitePageFields[ident[i+1]] {
+			deps[pageDepAll] = true
+			return
+		}
+		if field == "Site" {
+			// .Site.Title etc.
+			return
+		}
+		if pageNeighbourFields[field] {
+			deps[pageDepAll] = true
+			return
+		}
+		if pageListFields[field] || (field == "Data" && i+1 < len(ident) && ident[i+1] == "Pages") {
+			deps[pageDepOwn] = true
+			return
+		}
+	}
+}
+
+// collectGetPageDeps records the page looked up in commands such as
+// .Site.GetPage "page" "posts" "my-post.md".
+func collectGetPageDeps(deps map[string]bool, n *parse.CommandNode) {
+	var ident []string
+	switch fn := n.Args[0].(type) {
+	case *parse.FieldNode:
+		ident = fn.Ident
+	case *parse.VariableNode:
+		ident = fn.Ident
+	case *parse.ChainNode:
+		ident = fn.Field
+	default:
+		return
+	}
+
+	if len(ident) == 0 || ident[len(ident)-1] != "GetPage" {
+		return
+	}
+
+	var parts []string
+	for i, arg := range n.Args[1:] {
+		s, ok := arg.(*parse.StringNode)
+		if !ok {
+			deps[pageDepAll] = true
+			return
+		}
+		if i == 0 {
+			if s.Text != KindPage {
+				// Sections etc. are built from many pages.
+				deps[pageDepAll] = true
+				return
+			}
+			continue
+		}
+		parts = append(parts, s.Text)
+	}
+
+	if len(parts) == 0 {
+		deps[pageDepAll] = true
+		return
+	}
+
+	p := strings.TrimPrefix(path.Join(parts...), "/")
+	deps[strings.ToLower(strings.TrimSuffix(p, path.Ext(p)))] = true
+}
+
+// findPagesByContentDeps returns the content pages with shortcodes using the
+// changed content files, see pageDependencies.contentDependsOn.
+func (h *HugoSites) findPagesByContentDeps(changed map[string]bool, structural bool) Pages {
+	var pages Pages
+	for _, s := range h.Sites {
+		for _, p := range s.rawAllPages {
+			if p.File != nil && !changed[p.File.Filename()] && s.pageDeps().contentDependsOn(p, changed, structural) {
+				pages = append(pages, p)
+			}
+		}
+	}
+	return pages
+}
+
+func (s *Site) pageDeps() *pageDependencies {
+	if s.owner == nil || !s.running() {
+		return nil
+	}
+	return s.owner.pageDeps
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageDependencies(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/a.md", "---\ntitle: A1\n---\n",
+		"content/posts/b.md", "---\ntitle: B\nlayout: getpage\n---\n",
+		"content/posts/c.md", "---\ntitle: C\n---\n",
+		"content/other/d.md", "---\ntitle: D\n---\n",
+		"content/other/e.md", "---\ntitle: E\nlayout: content\n---\n{{< title >}}\n",
+		"content/other/f.md", "---\ntitle: F\ndraft: true\n---\n",
+		"layouts/shortcodes/title.html", `{{ with .Site.GetPage "page" "posts/a.md" }}{{ .Title }}{{ end }}`,
+		"layouts/_default/content.html", `Content: {{ .Content }}`,
+		"layouts/index.html", `Home: {{ range first 1 .Site.RegularPages.ByTitle }}{{ .Title }}{{ end }}`,
+		"layouts/_default/list.html", `List: {{ range .Pages.ByTitle }}{{ .Title }}|{{ end }}`,
+		"layouts/_default/single.html", `Single: {{ .Title }}`,
+		"layouts/_default/getpage.html", `Single: {{ .Title }}|{{ with .Site.GetPage "page" "posts/a.md" }}{{ .Title }}{{ end }}`,
+	)
+
+	h.running = true
+	h.ContentChanges = &contentChangeMap{symContent: make(map[string]map[string]bool)}
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "Home: A1")
+	th.assertFileContent("public/posts/index.html", "List: A1|B|C|")
+	th.assertFileContent("public/posts/b/index.html", "Single: B|A1")
+	th.assertFileContent("public/other/e/index.html", "Content: A1")
+	th.assertFileContent("public/other/index.html", "List: D|E|")
+
+	for _, filename := range []string{"public/posts/c/index.html", "public/other/index.html", "public/other/d/index.html"} {
+		assert.NoError(th.Fs.Destination.Remove(filename))
+	}
+
+	s := h.Sites[0]
+	writeSource(t, th.Fs, "content/posts/a.md", "---\ntitle: A2\n---\n")
+	assert.NoError(h.Build(BuildCfg{RecentlyVisited: map[string]bool{"/posts/a/": true}},
+		fsnotify.Event{Name: filepath.Join(s.absContentDir(), "posts", "a.md"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/posts/a/index.html", "Single: A2")
+	th.assertFileContent("public/index.html", "Home: A2")
+	th.assertFileContent("public/posts/index.html", "List: A2|B|C|")
+	th.assertFileContent("public/posts/b/index.html", "Single: B|A2")
+
+	// The shortcode uses posts/a, and the list the content of other/e.
+	th.assertFileContent("public/other/e/index.html", "Content: A2")
+	th.assertFileContent("public/other/index.html", "List: D|E|")
+
+	// These do not depend on posts/a.
+	th.assertFileNotExist("public/posts/c/index.html")
+	th.assertFileNotExist("public/other/d/index.html")
+
+	// A draft no longer a draft is added to the lists.
+	writeSource(t, th.Fs, "content/other/f.md", "---\ntitle: F\n---\n")
+	assert.NoError(h.Build(BuildCfg{RecentlyVisited: map[string]bool{"/other/f/": true}},
+		fsnotify.Event{Name: filepath.Join(s.absContentDir(), "other", "f.md"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/other/index.html", "List: D|E|F|")
+	th.assertFileNotExist("public/other/d/index.html")
+
+	// And removed when a draft again.
+	writeSource(t, th.Fs, "content/other/f.md", "---\ntitle: F\ndraft: true\n---\n")
+	assert.NoError(h.Build(BuildCfg{RecentlyVisited: map[string]bool{"/other/f/": true}},
+		fsnotify.Event{Name: filepath.Join(s.absContentDir(), "other", "f.md"), Op: fsnotify.Write}))
+
+	assert.Equal("List: D|E|", readDestination(t, th.Fs, "public/other/index.html"))
+}
+
+func TestPageDependenciesTranslations(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"content/p.fr.md", "---\ntitle: P FR\n---\n",
+		"content/q.fr.md", "---\ntitle: Q FR\nlayout: getpage\n---\n",
+		"layouts/_default/single.html", `Single: {{ .Title }}`,
+		"layouts/_default/getpage.html", `Single: {{ .Title }}|{{ with .Site.GetPage "page" "p.md" }}{{ .Title }}{{ end }}`,
+		"layouts/_default/list.html", `List`,
+	)
+
+	h.running = true
+	h.ContentChanges = &contentChangeMap{symContent: make(map[string]map[string]bool)}
+	assert.NoError(h.Build(BuildCfg{}))
+
+	fr := h.Sites[1]
+	assert.Equal("fr", fr.Language.Lang)
+	q := fr.getPage(KindPage, "q.fr.md")
+	assert.NotNil(q)
+
+	changed := func(filename string) map[string]bool {
+		return map[string]bool{filepath.Join(fr.absContentDir(), filename): true}
+	}
+
+	assert.True(h.pageDeps.dependsOn(q, changed("p.fr.md"), false))
+	assert.False(h.pageDeps.dependsOn(q, changed("p.de.md"), false))
+}
+
+func TestPageDepPath(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	contentDir := filepath.FromSlash("/work/content")
+
+	assert.Equal("posts/my-post", pageDepPath(filepath.FromSlash("/work/content/posts/My-Post.md"), contentDir))
+	assert.Equal("about", pageDepPath(filepath.FromSlash("/work/content/about.html"), contentDir))
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// frontMatterDefault holds front matter values set for the pages matching a
+// content path glob and/or a type, e.g.:
+//
+//   [[frontMatterDefaults]]
+//   path = "/posts/**"
+//   [frontMatterDefaults.values]
+//   type = "blog"
+//   show_toc = true
+//
+// The values are used for the keys not set in the page's front matter.
+type frontMatterDefault struct {
+	// A glob matched against the content file path relative to the content
+	// dir, where ** matches any number of directories.
+	Path string
+
+	// Matched against the type set in the page's front matter or an earlier
+	// default or, if not set, its section.
+	Type string
+
+	Values map[string]interface{}
+
+	pathRe *regexp.Regexp
+}
+
+// frontMatterDefaults are applied in order, so the values of a later match
+// win over the values of an earlier one.
+type frontMatterDefaults []frontMatterDefault
+
+func decodeFrontMatterDefaults(v interface{}) (frontMatterDefaults, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var defaults frontMatterDefaults
+	if err := mapstructure.WeakDecode(v, &defaults); err != nil {
+		return nil, fmt.Errorf("failed to decode frontMatterDefaults config: %s", err)
+	}
+
+	for i, d := range defaults {
+		if d.Path == "" && d.Type == "" {
+			return nil, fmt.Errorf("frontMatterDefaults[%d]: path or type must be set", i)
+		}
+
+		if d.Path != "" {
+			d.pathRe = helpers.GlobRegexp(strings.TrimPrefix(filepath.ToSlash(d.Path), "/"))
+			if d.pathRe == nil {
+				return nil, fmt.Errorf("frontMatterDefaults[%d]: invalid path %q", i, d.Path)
+			}
+		}
+
+		d.Values = cast.ToStringMap(d.Values)
+		helpers.ToLowerMap(d.Values)
+
+		defaults[i] = d
+	}
+
+	return defaults, nil
+}
+
+func (d frontMatterDefault) matches(path, typ string) bool {
+	if d.pathRe != nil && !d.pathRe.MatchString(path) {
+		return false
+	}
+
+	return d.Type == "" || strings.EqualFold(d.Type, typ)
+}
+
+// apply adds the default values for the page with the given content path
+// and section to its front matter in meta. Keys set in meta win.
+func (defaults frontMatterDefaults) apply(path, section string, meta map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return meta
+	}
+
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+
+	helpers.ToLowerMap(meta)
+
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+
+	values := make(map[string]interface{})
+	for _, d := range defaults {
+		// The type may be set by an earlier default.
+		typ := section
+		if v, found := meta["type"]; found {
+			typ = cast.ToString(v)
+		} else if v, found := values["type"]; found {
+			typ = cast.ToString(v)
+		}
+
+		if !d.matches(path, typ) {
+			continue
+		}
+		for k, v := range d.Values {
+			values[k] = v
+		}
+	}
+
+	for k, v := range values {
+		if _, found := meta[k]; !found {
+			meta[k] = v
+		}
+	}
+
+	return meta
+}
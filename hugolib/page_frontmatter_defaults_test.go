@@ -0,0 +1,78 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontMatterDefaultsApply(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	defaults, err := decodeFrontMatterDefaults([]interface{}{
+		map[string]interface{}{"path": "/posts/**", "values": map[string]interface{}{"type": "blog", "Show_TOC": true}},
+		map[string]interface{}{"type": "blog", "values": map[string]interface{}{"comments": true}},
+		map[string]interface{}{"path": "posts/drafts/*", "values": map[string]interface{}{"show_toc": false}},
+	})
+	assert.NoError(err)
+	assert.Len(defaults, 3)
+
+	assert.Equal(map[string]interface{}{"type": "blog", "show_toc": true, "comments": true, "title": "A"},
+		defaults.apply("posts/a.md", "posts", map[string]interface{}{"Title": "A"}))
+	assert.Equal(map[string]interface{}{"type": "blog", "show_toc": false, "comments": true},
+		defaults.apply("posts/drafts/b.md", "posts", nil))
+	assert.Equal(map[string]interface{}{"type": "blog", "show_toc": true, "comments": true},
+		defaults.apply("posts/c.md", "posts", map[string]interface{}{"show_toc": true}))
+	assert.Equal(map[string]interface{}{"type": "blog", "comments": true},
+		defaults.apply("about.md", "", map[string]interface{}{"type": "blog"}))
+	assert.Equal(map[string]interface{}{"title": "D"},
+		defaults.apply("docs/d.md", "docs", map[string]interface{}{"title": "D"}))
+
+	_, err = decodeFrontMatterDefaults([]interface{}{map[string]interface{}{"values": map[string]interface{}{"a": 1}}})
+	assert.Error(err)
+}
+
+func TestFrontMatterDefaults(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+
+[[frontMatterDefaults]]
+path = "/posts/**"
+[frontMatterDefaults.values]
+type = "blog"
+show_toc = true
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/a.md", "---\ntitle: A\n---\n",
+		"content/posts/b/index.md", "---\ntitle: B\nshow_toc: false\n---\n",
+		"content/posts/c.md", "No front matter.",
+		"content/about.md", "---\ntitle: About\n---\n",
+		"layouts/_default/single.html", `default|{{ .Title }}|{{ .Params.show_toc }}`,
+		"layouts/blog/single.html", `blog|{{ .Title }}|{{ .Params.show_toc }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/a/index.html", "blog|A|true")
+	th.assertFileContent("public/posts/b/index.html", "blog|B|false")
+	th.assertFileContent("public/posts/c/index.html", "blog||true")
+	th.assertFileContent("public/about/index.html", "default|About|")
+}
@@ -174,7 +174,7 @@ func (p *Page) Render(layout ...string) template.HTML {
 // for list pages.
 func (p *Page) checkRender() bool {
 	if p.Kind != KindPage {
-		helpers.DistinctWarnLog.Printf(".Render only available for regular pages, not for of kind %q. You probably meant .Site.RegularPages and not.Site.Pages.", p.Kind)
+		helpers.Warnidf(p.s.Log, "warning-render-kind", ".Render only available for regular pages, not for of kind %q. You probably meant .Site.RegularPages and not.Site.Pages.", p.Kind)
 		return false
 	}
 	return true
@@ -0,0 +1,98 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+
+	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/resource"
+)
+
+// PageOutputFile is a file published for a page in one output format.
+type PageOutputFile struct {
+	Page         *Page
+	OutputFormat output.Format
+
+	// The absolute filename in the destination filesystem.
+	Filename string
+
+	// The name of the template the file is rendered with, e.g.
+	// _default/single.html. Empty if there is none.
+	Template string
+}
+
+// PageOutputFiles returns the files published for the pages in all
+// languages. Only the first pager of paginated lists is included.
+func (h *HugoSites) PageOutputFiles() []PageOutputFile {
+	var files []PageOutputFile
+
+	for _, s := range h.Sites {
+		templates := templateFiles(s.Tmpl)
+		for _, p := range s.Pages {
+			for _, f := range p.outputFormats {
+				target, err := p.createTargetPath(f, false)
+				if err != nil || target == "" {
+					continue
+				}
+				file := PageOutputFile{
+					Page:         p,
+					OutputFormat: f,
+					Filename:     filepath.Join(s.absPublishDir(), target),
+				}
+				if lookup := s.layoutLookup(p, f, templates); lookup.Found >= 0 {
+					file.Template = lookup.Layouts[lookup.Found]
+				}
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files
+}
+
+// PageResourceFile is a bundled resource published with its page, e.g. an
+// image in a page bundle.
+type PageResourceFile struct {
+	Page     *Page
+	Resource resource.Resource
+
+	// The absolute filename of the resource's source file.
+	SourceFilename string
+}
+
+// PageResourceFiles returns the resources published for the pages in all
+// languages, including the resources of headless bundles. Pages bundled as
+// resources are not included.
+func (h *HugoSites) PageResourceFiles() []PageResourceFile {
+	var (
+		files []PageResourceFile
+		seen  = make(map[string]bool)
+	)
+
+	for _, s := range h.Sites {
+		for _, p := range append(s.pagesToRender(), s.headlessPages...) {
+			for _, r := range p.Resources {
+				src, ok := r.(resource.Source)
+				if !ok || seen[r.RelPermalink()] {
+					continue
+				}
+				seen[r.RelPermalink()] = true
+				files = append(files, PageResourceFile{Page: p, Resource: r, SourceFilename: src.AbsSourceFilename()})
+			}
+		}
+	}
+
+	return files
+}
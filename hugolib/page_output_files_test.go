@@ -0,0 +1,96 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageOutputFiles(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\n---\n",
+		"layouts/_default/single.html", "Single",
+		"layouts/_default/list.html", "List",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	files := make(map[string]PageOutputFile)
+	for _, f := range h.PageOutputFiles() {
+		files[f.Filename] = f
+	}
+
+	publishDir := h.Sites[0].absPublishDir()
+
+	p1, found := files[filepath.Join(publishDir, "posts", "p1", "index.html")]
+	assert.True(found)
+	assert.Equal("P1", p1.Page.Title)
+	assert.Equal("HTML", p1.OutputFormat.Name)
+	assert.Equal("_default/single.html", p1.Template)
+
+	rss, found := files[filepath.Join(publishDir, "posts", "index.xml")]
+	assert.True(found)
+	assert.Equal(KindSection, rss.Page.Kind)
+	assert.Equal("_internal/_default/rss.xml", rss.Template)
+
+	for filename := range files {
+		exists, _ := afero.Exists(th.Fs.Destination, filename)
+		assert.True(exists, filename)
+	}
+}
+
+func TestPageResourceFiles(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1/index.md", "---\ntitle: P1\n---\n",
+		"content/posts/p1/data.txt", "Data",
+		"content/posts/p1/sub.md", "---\ntitle: Sub\n---\n",
+		"content/gallery/index.md", "---\ntitle: Gallery\nheadless: true\n---\n",
+		"content/gallery/a.txt", "A",
+		"layouts/_default/single.html", "Single",
+		"layouts/_default/list.html", "List",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	files := make(map[string]PageResourceFile)
+	for _, f := range h.PageResourceFiles() {
+		files[f.Resource.RelPermalink()] = f
+	}
+
+	assert.Len(files, 2)
+	assert.Equal("P1", files["/posts/p1/data.txt"].Page.Title)
+	assert.True(strings.HasSuffix(filepath.ToSlash(files["/posts/p1/data.txt"].SourceFilename), "content/posts/p1/data.txt"))
+	assert.Equal("Gallery", files["/gallery/a.txt"].Page.Title)
+}
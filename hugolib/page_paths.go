@@ -264,7 +264,9 @@ func createTargetPath(d targetPathDescriptor) string {
 
 	// Note: MakePathSanitized will lower case the path if
 	// disablePathToLower isn't set.
-	return d.PathSpec.MakePathSanitized(pagePath)
+	pagePath = d.PathSpec.MakePathSanitized(pagePath)
+
+	return d.PathSpec.MakeWindowsSafePublishPath(pagePath)
 }
 
 func (p *Page) createRelativePermalink() string {
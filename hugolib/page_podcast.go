@@ -0,0 +1,202 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/resource"
+	"github.com/spf13/cast"
+)
+
+// Podcast is the data rendered in the "Podcast" output format, i.e. a podcast
+// feed with the iTunes tags.
+type Podcast struct {
+	Channel  PodcastChannel
+	Episodes []PodcastEpisode
+}
+
+// PodcastChannel holds the channel settings of a podcast. These are set in
+// the podcast map in site config and in the front matter of the list page,
+// the latter taking precedence.
+type PodcastChannel struct {
+	Title       string
+	Description string
+	Author      string
+	OwnerName   string
+	OwnerEmail  string
+	Image       string
+	Category    string
+	Subcategory string
+	Language    string
+	Copyright   string
+	Explicit    bool
+
+	// Either "episodic" or "serial".
+	Type string
+}
+
+// PodcastEpisode is an episode in a podcast, i.e. a page bundle with an audio
+// resource.
+type PodcastEpisode struct {
+	Page  *Page
+	Audio *resource.Media
+
+	Duration time.Duration
+
+	// The episode and season number, set with episode and season in front
+	// matter. 0 if not set.
+	Episode int
+	Season  int
+
+	// Either "full", "trailer" or "bonus".
+	EpisodeType string
+
+	Explicit bool
+}
+
+// FormattedDuration returns the episode's duration as HH:MM:SS.
+func (e PodcastEpisode) FormattedDuration() string {
+	// Round to the nearest second, Duration.Round needs Go 1.9.
+	d := (e.Duration + time.Second/2) / time.Second * time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// podcastRequiredChannelFields are the channel fields required by the podcast
+// directories.
+var podcastRequiredChannelFields = []struct {
+	name  string
+	value func(c PodcastChannel) string
+}{
+	{"title", func(c PodcastChannel) string { return c.Title }},
+	{"description", func(c PodcastChannel) string { return c.Description }},
+	{"image", func(c PodcastChannel) string { return c.Image }},
+	{"language", func(c PodcastChannel) string { return c.Language }},
+	{"category", func(c PodcastChannel) string { return c.Category }},
+}
+
+// missingFields returns the names of the required fields that are not set.
+func (c PodcastChannel) missingFields() []string {
+	var missing []string
+	for _, f := range podcastRequiredChannelFields {
+		if f.value(c) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+// Podcast returns the podcast with the page's regular pages as episodes.
+// Pages without an audio resource are skipped. Missing required channel
+// fields are logged as errors.
+func (p *Page) Podcast() Podcast {
+	podcast := Podcast{Channel: p.podcastChannel()}
+
+	if missing := podcast.Channel.missingFields(); len(missing) > 0 {
+		helpers.DistinctErrorLog.Printf("Podcast %q is missing the required channel fields: %s", p.pathOrTitle(), strings.Join(missing, ", "))
+	}
+
+	for _, pp := range p.Pages {
+		if pp.Kind != KindPage {
+			continue
+		}
+
+		audio := pp.podcastAudio()
+		if audio == nil {
+			helpers.DistinctWarnLog.Printf("Podcast episode %q has no audio resource, skipping it", pp.pathOrTitle())
+			continue
+		}
+
+		e := PodcastEpisode{
+			Page:        pp,
+			Audio:       audio,
+			Duration:    audio.Duration(),
+			Episode:     cast.ToInt(pp.Params["episode"]),
+			Season:      cast.ToInt(pp.Params["season"]),
+			EpisodeType: cast.ToString(pp.Params["episodetype"]),
+			Explicit:    podcast.Channel.Explicit,
+		}
+
+		if v, found := pp.Params["explicit"]; found {
+			e.Explicit = cast.ToBool(v)
+		}
+		if e.EpisodeType == "" {
+			e.EpisodeType = "full"
+		}
+
+		podcast.Episodes = append(podcast.Episodes, e)
+	}
+
+	return podcast
+}
+
+// podcastAudio returns the audio resource of an episode. This is the resource
+// named in the audio front matter param, or the first audio resource.
+func (p *Page) podcastAudio() *resource.Media {
+	var r resource.Resource
+	if name := cast.ToString(p.Params["audio"]); name != "" {
+		r = p.Resources.GetByPrefix(name)
+	} else if audio := p.Resources.ByType("audio"); len(audio) > 0 {
+		r = audio[0]
+	}
+
+	m, _ := r.(*resource.Media)
+	return m
+}
+
+func (p *Page) podcastChannel() PodcastChannel {
+	config := make(map[string]interface{})
+	for k, v := range p.s.Cfg.GetStringMap("podcast") {
+		config[strings.ToLower(k)] = v
+	}
+	if v, found := p.Params["podcast"]; found {
+		for k, v := range cast.ToStringMap(v) {
+			config[strings.ToLower(k)] = v
+		}
+	}
+
+	get := func(key, defaultValue string) string {
+		if v := cast.ToString(config[key]); v != "" {
+			return v
+		}
+		return defaultValue
+	}
+
+	c := PodcastChannel{
+		Title:       get("title", p.Title),
+		Description: get("description", p.Description),
+		Author:      get("author", cast.ToString(p.Site.Author["name"])),
+		OwnerName:   get("ownername", cast.ToString(p.Site.Author["name"])),
+		OwnerEmail:  get("owneremail", cast.ToString(p.Site.Author["email"])),
+		Image:       get("image", ""),
+		Category:    get("category", ""),
+		Subcategory: get("subcategory", ""),
+		Language:    get("language", p.Site.LanguageCode),
+		Copyright:   get("copyright", p.Site.Copyright),
+		Explicit:    cast.ToBool(config["explicit"]),
+		Type:        get("type", "episodic"),
+	}
+
+	if c.Title == "" {
+		c.Title = p.Site.Title
+	}
+	if c.Image != "" {
+		c.Image = p.s.PathSpec.AbsURL(c.Image, false)
+	}
+
+	return c
+}
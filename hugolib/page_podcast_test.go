@@ -0,0 +1,112 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestMP3 creates an MP3 file of the given number of 128 kbit/s frames,
+// each about 26 ms long.
+func createTestMP3(frames int) string {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	return string(bytes.Repeat(frame, frames))
+}
+
+func TestPodcastOutput(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+languageCode = "en-us"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[author]
+name = "Jo Doe"
+email = "jo@example.com"
+
+[podcast]
+category = "Technology"
+image = "/cover.jpg"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/episodes/_index.md", `---
+title: The Show
+description: A show about things & stuff.
+outputs: ["HTML", "Podcast"]
+podcast:
+  subcategory: Podcasting
+  type: serial
+---
+`,
+		"content/episodes/one/index.md", "---\ntitle: Episode One\ndate: 2018-01-01\nepisode: 1\nseason: 2\n---\nThe first one.\n",
+		"content/episodes/one/audio.mp3", createTestMP3(2400),
+		"content/episodes/two/index.md", "---\ntitle: Episode Two\ndate: 2018-01-08\nepisodeType: bonus\nexplicit: true\naudio: b\n---\n",
+		"content/episodes/two/a.mp3", createTestMP3(10),
+		"content/episodes/two/b.mp3", createTestMP3(20),
+		"content/episodes/no-audio.md", "---\ntitle: No Audio\n---\n",
+		"content/other/_index.md", "---\ntitle: Other\noutputs: [\"Podcast\"]\n---\n",
+		"layouts/_default/list.html", `List: {{ .Title }}{{ with .OutputFormats.Get "Podcast" }}|{{ .RelPermalink }}{{ end }}`,
+		"layouts/_default/single.html", "Single: {{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/episodes/index.html", "List: The Show|/episodes/podcast.xml")
+	th.assertFileContent("public/episodes/podcast.xml",
+		"<title>The Show</title>",
+		"<description>A show about things &amp; stuff.</description>",
+		"<language>en-us</language>",
+		"<itunes:author>Jo Doe</itunes:author>",
+		"<itunes:email>jo@example.com</itunes:email>",
+		`<itunes:image href="http://example.com/cover.jpg" />`,
+		`<itunes:category text="Technology">`,
+		`<itunes:category text="Podcasting" />`,
+		"<itunes:type>serial</itunes:type>",
+		"<itunes:explicit>false</itunes:explicit>",
+		`<atom:link href="http://example.com/episodes/podcast.xml" rel="self" type="application/rss+xml" />`,
+		`<enclosure url="http://example.com/episodes/one/audio.mp3" length="1000800" type="audio/mpeg" />`,
+		"<itunes:duration>00:01:03</itunes:duration>",
+		"<itunes:episode>1</itunes:episode>",
+		"<itunes:season>2</itunes:season>",
+		`<enclosure url="http://example.com/episodes/two/b.mp3" length="8340" type="audio/mpeg" />`,
+		"<itunes:episodeType>bonus</itunes:episodeType>",
+		"<itunes:explicit>true</itunes:explicit>",
+	)
+
+	content := readDestination(t, th.Fs, "public/episodes/podcast.xml")
+	assert.NotContains(content, "No Audio")
+	assert.True(strings.Index(content, "Episode Two") < strings.Index(content, "Episode One"))
+
+	other := h.Sites[0].getPage(KindSection, "other")
+	assert.NotNil(other)
+	channel := other.Podcast().Channel
+	assert.Equal("Other", channel.Title)
+	assert.Equal("episodic", channel.Type)
+	assert.Equal([]string{"description"}, channel.missingFields())
+	assert.Empty(h.Sites[0].getPage(KindSection, "episodes").Podcast().Channel.missingFields())
+
+	assert.Equal("01:02:03", PodcastEpisode{Duration: time.Hour + 2*time.Minute + 3*time.Second}.FormattedDuration())
+	assert.Equal("00:00:02", PodcastEpisode{Duration: 1500 * time.Millisecond}.FormattedDuration())
+	assert.Equal("00:01:00", PodcastEpisode{Duration: 59*time.Second + 600*time.Millisecond}.FormattedDuration())
+}
@@ -0,0 +1,200 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PrintPage is a page in a print document, see PrintPages.
+type PrintPage struct {
+	*Page
+
+	// The id of this page in the print document.
+	Anchor string
+
+	// The depth of this page in the section tree, starting at 0 for the
+	// page being printed.
+	Depth int
+
+	// The page content with its ids prefixed with Anchor and with links to
+	// other pages in the print document rewritten to point into it.
+	Content template.HTML
+
+	// The page's table of contents with the links rewritten as in Content.
+	TableOfContents template.HTML
+}
+
+var (
+	// The attribute names must be preceded by whitespace so e.g. data-id
+	// attributes are left alone.
+	printIDRe   = regexp.MustCompile(`(\s)id="([^"]*)"`)
+	printHrefRe = regexp.MustCompile(`(\s)href="([^"]*)"`)
+)
+
+// PrintPages returns this page and all of its descendants in reading order,
+// i.e. a section's regular pages before its sub sections, for rendering as
+// one document. This is used in the "print" output format.
+func (p *Page) PrintPages() []*PrintPage {
+	var pages []*PrintPage
+	p.collectPrintPages(0, &pages)
+
+	anchors := make(map[string]string)
+	for _, pp := range pages {
+		pp.Anchor = printAnchor(pp.Page)
+		anchors[pp.Page.RelPermalink()] = pp.Anchor
+	}
+
+	for _, pp := range pages {
+		pp.Content = template.HTML(rewritePrintHTML(pp.Page, string(pp.Page.Content), pp.Anchor, anchors, true))
+		pp.TableOfContents = template.HTML(rewritePrintHTML(pp.Page, string(pp.Page.TableOfContents), pp.Anchor, anchors, false))
+	}
+
+	return pages
+}
+
+// PrintTableOfContents returns a combined table of contents for the pages in
+// PrintPages.
+func (p *Page) PrintTableOfContents() template.HTML {
+	return printTableOfContents(p.PrintPages())
+}
+
+func (p *Page) collectPrintPages(depth int, pages *[]*PrintPage) {
+	*pages = append(*pages, &PrintPage{Page: p, Depth: depth})
+
+	switch p.Kind {
+	case KindHome:
+		for _, child := range p.Pages {
+			if child.Kind == KindPage && len(child.sections) == 0 {
+				child.collectPrintPages(depth+1, pages)
+			}
+		}
+	case KindSection:
+		for _, child := range p.Pages {
+			child.collectPrintPages(depth+1, pages)
+		}
+	default:
+		return
+	}
+
+	for _, sect := range p.Sections() {
+		sect.collectPrintPages(depth+1, pages)
+	}
+}
+
+func printTableOfContents(pages []*PrintPage) template.HTML {
+	var (
+		buf   bytes.Buffer
+		depth = -1
+	)
+
+	buf.WriteString(`<nav id="TableOfContents">`)
+
+	// The pages are in tree order, so the depth increases by at most one
+	// from one page to the next.
+	for _, pp := range pages {
+		if pp.Depth > depth {
+			buf.WriteString("<ul>")
+		} else {
+			buf.WriteString("</li>")
+			for d := depth; d > pp.Depth; d-- {
+				buf.WriteString("</ul></li>")
+			}
+		}
+		depth = pp.Depth
+
+		buf.WriteString(`<li><a href="#` + pp.Anchor + `">` + template.HTMLEscapeString(pp.Title) + `</a>`)
+		buf.WriteString(printTOCList(string(pp.TableOfContents)))
+	}
+
+	if depth >= 0 {
+		buf.WriteString("</li>")
+		for d := depth; d > 0; d-- {
+			buf.WriteString("</ul></li>")
+		}
+		buf.WriteString("</ul>")
+	}
+
+	buf.WriteString("</nav>")
+
+	return template.HTML(buf.String())
+}
+
+// printTOCList returns the list inside a page's TOC nav element.
+func printTOCList(toc string) string {
+	toc = strings.TrimSpace(toc)
+	toc = strings.TrimPrefix(toc, `<nav id="TableOfContents">`)
+	toc = strings.TrimSuffix(toc, `</nav>`)
+	return strings.TrimSpace(toc)
+}
+
+// printAnchor creates an id for the given page from its permalink,
+// e.g. "docs-install" for "/docs/install/".
+func printAnchor(p *Page) string {
+	rel := strings.Trim(p.RelPermalink(), "/")
+	rel = strings.TrimSuffix(rel, ".html")
+	if rel == "" {
+		return "home"
+	}
+	return strings.Replace(rel, "/", "-", -1)
+}
+
+// rewritePrintHTML prefixes the ids in the given HTML with the page's anchor
+// and rewrites links to pages in the print document to their anchors.
+func rewritePrintHTML(p *Page, s, anchor string, anchors map[string]string, rewriteIDs bool) string {
+	if s == "" {
+		return s
+	}
+
+	if rewriteIDs {
+		s = printIDRe.ReplaceAllStringFunc(s, func(m string) string {
+			sm := printIDRe.FindStringSubmatch(m)
+			return sm[1] + `id="` + anchor + `--` + sm[2] + `"`
+		})
+	}
+
+	base, err := url.Parse(p.RelPermalink())
+	if err != nil {
+		return s
+	}
+
+	return printHrefRe.ReplaceAllStringFunc(s, func(m string) string {
+		sm := printHrefRe.FindStringSubmatch(m)
+		space, href := sm[1], sm[2]
+
+		if strings.HasPrefix(href, "#") {
+			return space + `href="#` + anchor + `--` + href[1:] + `"`
+		}
+
+		u, err := url.Parse(href)
+		if err != nil || (u.Host != "" && u.Host != p.s.PathSpec.BaseURL.URL().Host) {
+			return m
+		}
+
+		target := base.ResolveReference(u)
+		targetAnchor, found := anchors[target.Path]
+		if !found {
+			return m
+		}
+
+		if target.Fragment != "" {
+			return space + `href="#` + targetAnchor + `--` + target.Fragment + `"`
+		}
+		return space + `href="#` + targetAnchor + `"`
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cfg, fs := newTestCfg()
+
+	writeSource(t, fs, filepath.Join("content", "docs", "_index.md"), `---
+title: Docs
+outputs: ["HTML", "Print"]
+---
+Welcome to the docs.
+`)
+	writeSource(t, fs, filepath.Join("content", "docs", "install.md"), `---
+title: Install
+weight: 1
+---
+## Requirements
+
+See [usage](/docs/usage/#flags) and [the intro](#requirements).
+
+<span data-id="keep" data-href="#keep">Kept</span>
+`)
+	writeSource(t, fs, filepath.Join("content", "docs", "usage.md"), `---
+title: Usage
+weight: 2
+---
+## Flags
+
+Back to [install](../install/). Not in [the blog](/blog/).
+`)
+	writeSource(t, fs, filepath.Join("content", "docs", "advanced", "_index.md"), `---
+title: Advanced
+---
+`)
+	writeSource(t, fs, filepath.Join("content", "docs", "advanced", "tuning.md"), `---
+title: Tuning
+---
+Tuning.
+`)
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{})
+	th := testHelper{s.Cfg, s.Fs, t}
+
+	docs := s.getPage(KindSection, "docs")
+	assert.NotNil(docs)
+
+	pages := docs.PrintPages()
+	assert.Len(pages, 5)
+
+	var titles []string
+	var depths []int
+	for _, p := range pages {
+		titles = append(titles, p.Title)
+		depths = append(depths, p.Depth)
+	}
+	assert.Equal([]string{"Docs", "Install", "Usage", "Advanced", "Tuning"}, titles)
+	assert.Equal([]int{0, 1, 1, 1, 2}, depths)
+	assert.Equal("docs-install", pages[1].Anchor)
+
+	install := string(pages[1].Content)
+	assert.Contains(install, `id="docs-install--requirements"`)
+	assert.Contains(install, `<span data-id="keep" data-href="#keep">`)
+	assert.Contains(install, `href="#docs-usage--flags"`)
+	assert.Contains(install, `href="#docs-install--requirements"`)
+
+	usage := string(pages[2].Content)
+	assert.Contains(usage, `href="#docs-install"`)
+	assert.Contains(usage, `href="/blog/"`)
+
+	toc := string(docs.PrintTableOfContents())
+	assert.Contains(toc, `<nav id="TableOfContents"><ul><li><a href="#docs">Docs</a><ul><li><a href="#docs-install">Install</a>`)
+	assert.Contains(toc, `<li><a href="#docs-install--requirements">Requirements</a></li>`)
+	assert.Contains(toc, `<li><a href="#docs-advanced">Advanced</a><ul><li><a href="#docs-advanced-tuning">Tuning</a></li></ul></li></ul></li></ul></nav>`)
+
+	th.assertFileContent(filepath.Join("public", "docs", "print.html"),
+		`<a href="#docs-usage--flags">Flags</a>`,
+		`<article id="docs-advanced-tuning" class="print-page print-depth-2">`,
+		`<h2 id="docs-usage--flags">Flags</h2>`)
+
+	th.assertFileNotExist(filepath.Join("public", "print.html"))
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/output"
+)
+
+var (
+	// A named region in a content file, e.g.
+	//
+	//   <!-- region install -->
+	//   ...
+	//   <!-- endregion install -->
+	//
+	// The name in the end marker is optional, it closes the innermost
+	// region if not set.
+	regionMarkerRe = regexp.MustCompile(`(?m)^[ \t]*<!--\s*(region|endregion)(?:\s+([\w.-]+))?\s*-->[ \t]*\r?\n?`)
+
+	regionLinkRe = regexp.MustCompile(`(?i)(\s(?:href|src)\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+)
+
+// Region returns the rendered content of the region with the given name in
+// this page's content file, e.g. to include it in another page with the
+// include shortcode:
+//
+//   {{< include "docs/setup.md" "install" >}}
+//
+// The relative links and images in the region are resolved relative to this
+// page, so they work wherever the region is included. Shortcodes in the
+// region are rendered with this page as their .Page.
+func (p *Page) Region(name string) (template.HTML, error) {
+	// This is usually called while rendering another page, possibly at the
+	// same time as this page is rendered. The raw content is not modified
+	// after the page is read, and the markup type is determined once.
+	regions, err := parseContentRegions(p.rawContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the regions in %q: %s", p.Path(), err)
+	}
+
+	region, found := regions[name]
+	if !found {
+		return "", fmt.Errorf("region %q not found in %q", name, p.Path())
+	}
+
+	// The region's shortcodes are kept apart from the page's own, which may
+	// be in use by the page's render.
+	shortcodes := newShortcodeHandler(p)
+	withPlaceholders, err := shortcodes.extractShortcodes(string(region), p)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the shortcodes in region %q in %q: %s", name, p.Path(), err)
+	}
+
+	content := p.s.ContentSpec.RenderBytes(&helpers.RenderingContext{
+		Content: []byte(withPlaceholders), PageFmt: p.determineMarkupType(),
+		Cfg:        p.Language(),
+		DocumentID: p.UniqueID() + "-" + name, DocumentName: p.Path(),
+		Config:      p.getRenderingConfig(),
+		RenderHooks: p.renderHooks()})
+
+	if len(shortcodes.shortcodes) > 0 {
+		rendered := make(map[string]string)
+		for placeholder, sc := range shortcodes.shortcodes {
+			key := newScKeyFromLangAndOutputFormat(p.Lang(), output.HTMLFormat, placeholder)
+			rendered[placeholder] = renderShortcode(key, sc, nil, p)
+		}
+		if content, err = replaceShortcodeTokens(content, shortcodePlaceholderPrefix, rendered); err != nil {
+			return "", fmt.Errorf("failed to render the shortcodes in region %q in %q: %s", name, p.Path(), err)
+		}
+	}
+
+	base, err := url.Parse(p.RelPermalink())
+	if err != nil {
+		return template.HTML(content), nil
+	}
+
+	return template.HTML(resolveRegionLinks(content, base)), nil
+}
+
+// Include returns the rendered region with the given name in the content page
+// at path, see Page.Region. The server processes the content of the page
+// with the shortcode again when the included page changes.
+func (scp *ShortcodeWithPage) Include(path, name string) (template.HTML, error) {
+	p, err := scp.Page.Site.GetPage(KindPage, path)
+	if err != nil {
+		return "", err
+	}
+	if p == nil {
+		return "", fmt.Errorf("include: page %q not found in %q", path, scp.Page.Path())
+	}
+
+	if p.File != nil {
+		scp.Page.s.pageDeps().addContentPage(scp.Page, pageDepPath(p.File.Filename(), p.s.absContentDir()))
+	}
+
+	return p.Region(name)
+}
+
+// parseContentRegions returns the content of the named regions in content,
+// without the region markers.
+func parseContentRegions(content []byte) (map[string][]byte, error) {
+	type open struct {
+		name  string
+		start int
+	}
+
+	var (
+		regions = make(map[string][]byte)
+		stack   []open
+	)
+
+	markers := regionMarkerRe.FindAllSubmatchIndex(content, -1)
+
+	for _, m := range markers {
+		var name string
+		if m[4] != -1 {
+			name = string(content[m[4]:m[5]])
+		}
+
+		if string(content[m[2]:m[3]]) == "region" {
+			if name == "" {
+				return nil, fmt.Errorf("region without a name at offset %d", m[0])
+			}
+			if _, found := regions[name]; found {
+				return nil, fmt.Errorf("duplicate region %q", name)
+			}
+			for _, o := range stack {
+				if o.name == name {
+					return nil, fmt.Errorf("duplicate region %q", name)
+				}
+			}
+			stack = append(stack, open{name: name, start: m[1]})
+			continue
+		}
+
+		if len(stack) == 0 {
+			return nil, fmt.Errorf("endregion %q without a region", name)
+		}
+
+		o := stack[len(stack)-1]
+		if name != "" && name != o.name {
+			return nil, fmt.Errorf("endregion %q closes region %q", name, o.name)
+		}
+		stack = stack[:len(stack)-1]
+
+		regions[o.name] = regionMarkerRe.ReplaceAll(content[o.start:m[0]], nil)
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("region %q is not closed", stack[len(stack)-1].name)
+	}
+
+	return regions, nil
+}
+
+// resolveRegionLinks resolves the relative links and image sources in the
+// rendered content against the base URL.
+func resolveRegionLinks(content []byte, base *url.URL) []byte {
+	return regionLinkRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		sm := regionLinkRe.FindSubmatch(m)
+		quote, link := `"`, sm[2]
+		if sm[3] != nil {
+			quote, link = "'", sm[3]
+		}
+
+		s := string(link)
+		if s == "" || strings.HasPrefix(s, "/") || strings.HasPrefix(s, "#") {
+			return m
+		}
+
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme != "" || u.Host != "" {
+			return m
+		}
+
+		var b bytes.Buffer
+		b.Write(sm[1])
+		b.WriteString(quote)
+		b.WriteString(base.ResolveReference(u).String())
+		b.WriteString(quote)
+		return b.Bytes()
+	})
+}
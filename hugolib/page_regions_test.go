@@ -0,0 +1,116 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContentRegions(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	regions, err := parseContentRegions([]byte(`Intro.
+<!-- region outer -->
+Outer.
+  <!-- region inner -->
+Inner.
+<!-- endregion -->
+More.
+<!-- endregion outer -->
+Outro.
+`))
+	assert.NoError(err)
+	assert.Equal(map[string][]byte{
+		"outer": []byte("Outer.\nInner.\nMore.\n"),
+		"inner": []byte("Inner.\n"),
+	}, regions)
+
+	for i, content := range []string{
+		"<!-- region a -->\nA\n",
+		"<!-- endregion a -->\n",
+		"<!-- region a -->\n<!-- endregion b -->\n",
+		"<!-- region a -->\n<!-- endregion -->\n<!-- region a -->\n<!-- endregion -->\n",
+		"<!-- region -->\n<!-- endregion -->\n",
+	} {
+		_, err := parseContentRegions([]byte(content))
+		assert.Error(err, "[%d]", i)
+	}
+}
+
+func TestResolveRegionLinks(t *testing.T) {
+	t.Parallel()
+
+	base, _ := url.Parse("/docs/setup/")
+
+	require.Equal(t,
+		`<a href="/docs/next/">N</a> <img src="/docs/setup/diagram.png"> <a href='#top'>T</a> <a href="https://gohugo.io/">H</a> <a href="/abs/">A</a>`,
+		string(resolveRegionLinks([]byte(`<a href="../next/">N</a> <img src="diagram.png"> <a href='#top'>T</a> <a href="https://gohugo.io/">H</a> <a href="/abs/">A</a>`), base)))
+}
+
+func TestIncludeRegion(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/docs/setup/index.md", `---
+title: Setup
+---
+Before.
+
+<!-- region install -->
+Run **install**, see ![Diagram](diagram.png) and [the next step](../next/).
+
+{{< version >}}
+<!-- endregion install -->
+
+After.
+`,
+		"content/guide.md", "---\ntitle: Guide\n---\nGuide.\n\n{{< include \"docs/setup/index.md\" \"install\" >}}\n",
+		"layouts/_default/single.html", `{{ .Content }}`,
+		"layouts/shortcodes/version.html", `Version of {{ .Page.Title }}`,
+	)
+
+	h.running = true
+	h.ContentChanges = &contentChangeMap{symContent: make(map[string]map[string]bool)}
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/guide/index.html",
+		`<strong>install</strong>`,
+		`<img src="/docs/setup/diagram.png" alt="Diagram" />`,
+		`<a href="/docs/next/">the next step</a>`,
+		`Version of Setup`)
+
+	content := readDestination(t, th.Fs, "public/guide/index.html")
+	require.NotContains(t, content, "Before.")
+	require.NotContains(t, content, "After.")
+	require.NotContains(t, content, "HAHA")
+
+	// The guide is rendered again when the included page changes.
+	s := h.Sites[0]
+	writeSource(t, th.Fs, "content/docs/setup/index.md", "---\ntitle: Setup\n---\n<!-- region install -->\nRun **setup**.\n<!-- endregion install -->\n")
+	require.NoError(t, h.Build(BuildCfg{RecentlyVisited: map[string]bool{"/docs/setup/": true}},
+		fsnotify.Event{Name: filepath.Join(s.absContentDir(), "docs", "setup", "index.md"), Op: fsnotify.Write}))
+
+	th.assertFileContent("public/guide/index.html", `<strong>setup</strong>`)
+}
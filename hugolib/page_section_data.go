@@ -0,0 +1,141 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/output"
+	"github.com/spf13/cast"
+)
+
+// SectionData is the JSON representation of a list page and its pages, see
+// the "SectionData" output format.
+type SectionData struct {
+	Title        string            `json:"title"`
+	Permalink    string            `json:"permalink"`
+	RelPermalink string            `json:"relPermalink"`
+	Pages        []SectionDataPage `json:"pages"`
+}
+
+// SectionDataPage is a page in the SectionData.
+type SectionDataPage struct {
+	Title        string        `json:"title"`
+	Kind         string        `json:"kind"`
+	Permalink    string        `json:"permalink"`
+	RelPermalink string        `json:"relPermalink"`
+	Date         string        `json:"date,omitempty"`
+	Summary      template.HTML `json:"summary,omitempty"`
+
+	// The params listed in sectionDataParams, in site config or in the
+	// section's front matter.
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// SectionData returns the data rendered in the "SectionData" output format,
+// i.e. the list page and its pages, followed by its sub sections, with their
+// title, permalink, summary and a subset of their params.
+func (p *Page) SectionData() SectionData {
+	pages := p.Pages
+	if p.Kind == KindSection {
+		pages = append(pages[:len(pages):len(pages)], p.Sections()...)
+	}
+
+	data := SectionData{
+		Title:        p.Title,
+		Permalink:    p.Permalink(),
+		RelPermalink: p.RelPermalink(),
+		Pages:        make([]SectionDataPage, 0, len(pages)),
+	}
+
+	params := p.sectionDataParams()
+
+	for _, pp := range pages {
+		dp := SectionDataPage{
+			Title:        pp.Title,
+			Kind:         pp.Kind,
+			Permalink:    pp.Permalink(),
+			RelPermalink: pp.RelPermalink(),
+			Summary:      pp.Summary,
+		}
+
+		if !pp.Date.IsZero() {
+			dp.Date = pp.Date.Format(time.RFC3339)
+		}
+
+		for _, param := range params {
+			if v, found := pp.Params[param]; found {
+				if dp.Params == nil {
+					dp.Params = make(map[string]interface{})
+				}
+				dp.Params[param] = v
+			}
+		}
+
+		data.Pages = append(data.Pages, dp)
+	}
+
+	return data
+}
+
+func (p *Page) sectionDataParams() []string {
+	v, found := p.Params["sectiondataparams"]
+	if !found {
+		v = p.s.Cfg.Get("sectionDataParams")
+	}
+
+	params := cast.ToStringSlice(v)
+	for i, param := range params {
+		params[i] = strings.ToLower(param)
+	}
+
+	return params
+}
+
+// hasSectionData reports whether the page should be rendered in the
+// "SectionData" output format. This is set with sectionData in site config
+// for all sections or in the front matter of a list page.
+func (p *Page) hasSectionData() bool {
+	if !p.IsNode() || p.Kind == KindTaxonomyTerm {
+		return false
+	}
+
+	if v, found := p.Params["sectiondata"]; found {
+		return cast.ToBool(v)
+	}
+
+	return p.Kind == KindSection && p.s.Cfg.GetBool("sectionData")
+}
+
+// withSectionDataFormat adds the "SectionData" output format to the given
+// formats if the page should be rendered in it.
+func (p *Page) withSectionDataFormat(formats output.Formats) output.Formats {
+	if !p.hasSectionData() {
+		return formats
+	}
+
+	if _, found := formats.GetByName(output.SectionDataFormat.Name); found {
+		return formats
+	}
+
+	f, found := p.s.outputFormatsConfig.GetByName(output.SectionDataFormat.Name)
+	if !found {
+		return formats
+	}
+
+	// Do not modify the site's formats.
+	return append(formats[:len(formats):len(formats)], f)
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectionDataOutput(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+sectionData = true
+sectionDataParams = ["image"]
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/_index.md", "---\ntitle: Home\nsectionData: true\n---\n",
+		"content/posts/_index.md", "---\ntitle: Posts\n---\n",
+		"content/posts/p1.md", "---\ntitle: P1\ndate: 2018-01-02\nimage: p1.jpg\ntags: [\"a\"]\n---\nSummary 1.\n",
+		"content/posts/p2.md", "---\ntitle: P2\ndate: 2018-01-01\n---\nSummary 2.\n",
+		"content/posts/sub/_index.md", "---\ntitle: Sub\n---\n",
+		"content/docs/_index.md", "---\ntitle: Docs\nsectionData: false\n---\n",
+		"content/docs/d1.md", "---\ntitle: D1\n---\n",
+		"content/custom/_index.md", "---\ntitle: Custom\nsectionDataParams: [\"tags\"]\n---\n",
+		"content/custom/c1.md", "---\ntitle: C1\ntags: [\"b\"]\nimage: c1.jpg\n---\n",
+		"layouts/_default/list.html", "List: {{ .Title }}{{ with .OutputFormats.Get \"SectionData\" }}|{{ .RelPermalink }}{{ end }}",
+		"layouts/_default/single.html", "Single: {{ .Title }}",
+		"layouts/custom/list.sectiondata.json", `{{ len .Pages }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/index.html", "List: Posts|/posts/pages.json")
+	th.assertFileContent("public/index.html", "List: Home|/pages.json")
+	th.assertFileNotExist("public/docs/pages.json")
+	th.assertFileNotExist("public/posts/sub/pages.json/index.html")
+	th.assertFileContent("public/custom/pages.json", "1")
+
+	var data SectionData
+	assert.NoError(json.Unmarshal([]byte(readDestination(t, th.Fs, "public/posts/pages.json")), &data))
+
+	assert.Equal("Posts", data.Title)
+	assert.Equal("http://example.com/posts/", data.Permalink)
+	assert.Len(data.Pages, 3)
+
+	p1 := data.Pages[0]
+	assert.Equal("P1", p1.Title)
+	assert.Equal(KindPage, p1.Kind)
+	assert.Equal("/posts/p1/", p1.RelPermalink)
+	assert.Contains(string(p1.Summary), "Summary 1.")
+	assert.Equal("2018-01-02T00:00:00Z", p1.Date)
+	assert.Equal(map[string]interface{}{"image": "p1.jpg"}, p1.Params)
+
+	assert.Nil(data.Pages[1].Params)
+	assert.Equal(KindSection, data.Pages[2].Kind)
+
+	s := h.Sites[0]
+	custom := s.getPage(KindSection, "custom")
+	assert.NotNil(custom)
+	assert.Equal([]string{"tags"}, custom.sectionDataParams())
+}
@@ -1647,3 +1647,25 @@ func BenchmarkParsePage(b *testing.B) {
 		page.ReadFrom(bytes.NewReader(buf.Bytes()))
 	}
 }
+
+func TestPageMarkupSelection(t *testing.T) {
+	t.Parallel()
+
+	cfg, fs := newTestCfg()
+	cfg.Set("markup", map[string]interface{}{"defaultMarkdownHandler": "mmark"})
+
+	writeSource(t, fs, filepath.Join("content", "default.md"), "---\ntitle: Default\n---\nDefault")
+	writeSource(t, fs, filepath.Join("content", "blackfriday.md"), "---\ntitle: Blackfriday\nmarkup: blackfriday\n---\nBlackfriday")
+	writeSource(t, fs, filepath.Join("content", "org.md"), "---\ntitle: Org\nmarkup: org\n---\nOrg *bold*")
+	writeSource(t, fs, filepath.Join("content", "unknown.md"), "---\ntitle: Unknown\nmarkup: goldmark\n---\nUnknown")
+	writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"),
+		`{{ .Markup }}|{{ .Content }}|{{ with .Site.Config.Markup }}{{ .DefaultMarkdownHandler }}|{{ .Formats.org.Converter }}|{{ .Formats.markdown.Supports "tables" }}{{ end }}`)
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{SkipRender: false})
+	th := testHelper{s.Cfg, s.Fs, t}
+
+	th.assertFileContent(filepath.Join("public", "default", "index.html"), "mmark|", "|mmark|goorgeous|true")
+	th.assertFileContent(filepath.Join("public", "blackfriday", "index.html"), "markdown|<p>Blackfriday</p>")
+	th.assertFileContent(filepath.Join("public", "org", "index.html"), "org|<p>Org <strong>bold</strong></p>")
+	th.assertFileContent(filepath.Join("public", "unknown", "index.html"), "mmark|")
+}
@@ -70,6 +70,13 @@ type paginator struct {
 	size    int
 	source  interface{}
 	options []interface{}
+
+	// The scheme and host prepended to the URLs in Permalink.
+	host string
+
+	// Whether the pages after the first should not be indexed, see
+	// paginateNoIndex.
+	noIndex bool
 }
 
 type paginationURLFactory func(int) string
@@ -84,6 +91,34 @@ func (p *Pager) URL() template.HTML {
 	return template.HTML(p.paginationURLFactory(p.PageNumber()))
 }
 
+// Permalink returns the absolute URL to the current page.
+func (p *Pager) Permalink() string {
+	return p.host + p.paginationURLFactory(p.PageNumber())
+}
+
+// Canonical returns the absolute URL to the first page, the canonical URL of
+// the paginated list.
+func (p *Pager) Canonical() string {
+	return p.First().Permalink()
+}
+
+// IsFirst tests whether this is the first page.
+func (p *Pager) IsFirst() bool {
+	return p.PageNumber() == 1
+}
+
+// IsLast tests whether this is the last page.
+func (p *Pager) IsLast() bool {
+	return !p.HasNext()
+}
+
+// NoIndex tests whether the current page should not be indexed by search
+// engines. This is set for the pages after the first with paginateNoIndex in
+// site config.
+func (p *Pager) NoIndex() bool {
+	return p.noIndex && !p.IsFirst()
+}
+
 // Pages returns the Pages on this page.
 // Note: If this return a non-empty result, then PageGroups() will return empty.
 func (p *Pager) Pages() Pages {
@@ -300,6 +335,7 @@ func (p *PageOutput) Paginator(options ...interface{}) (*Pager, error) {
 			p.paginator = pagers[0]
 			p.paginator.source = "paginator"
 			p.paginator.options = options
+			p.initPaginatorSEO(p.paginator.paginator)
 		}
 
 	})
@@ -352,6 +388,7 @@ func (p *PageOutput) Paginate(seq interface{}, options ...interface{}) (*Pager,
 			p.paginator = pagers[0]
 			p.paginator.source = seq
 			p.paginator.options = options
+			p.initPaginatorSEO(p.paginator.paginator)
 		}
 
 	})
@@ -371,6 +408,13 @@ func (p *PageOutput) Paginate(seq interface{}, options ...interface{}) (*Pager,
 	return p.paginator, nil
 }
 
+// initPaginatorSEO sets the host used in the pager permalinks and whether the
+// pages after the first should be indexed.
+func (p *PageOutput) initPaginatorSEO(pag *paginator) {
+	pag.host = strings.TrimSuffix(p.Permalink(), p.RelPermalink())
+	pag.noIndex = p.s.Language.GetBool("paginateNoIndex")
+}
+
 func resolvePagerSize(cfg config.Provider, options ...interface{}) (int, error) {
 	if len(options) == 0 {
 		return cfg.GetInt("paginate"), nil
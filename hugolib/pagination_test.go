@@ -22,6 +22,7 @@ import (
 
 	"github.com/gohugoio/hugo/deps"
 	"github.com/gohugoio/hugo/output"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -577,3 +578,84 @@ func createTestPages(s *Site, num int) Pages {
 
 	return pages
 }
+
+func TestPaginatorSEO(t *testing.T) {
+	t.Parallel()
+
+	for _, aliasFirstPage := range []bool{true, false} {
+		config := fmt.Sprintf(`
+baseURL = "http://example.com/blog/"
+paginate = 1
+paginateNoIndex = true
+paginateAliasFirstPage = %t
+`, aliasFirstPage)
+
+		th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+			"content/posts/a.md", "---\ntitle: A\n---\n",
+			"content/posts/b.md", "---\ntitle: B\n---\n",
+			"content/posts/c.md", "---\ntitle: C\n---\n",
+			"layouts/_default/single.html", "{{ .Title }}",
+			"layouts/_default/list.html", `{{ template "_internal/pagination_head.html" . }}{{ with .Paginator }}{{ .PageNumber }}/{{ .TotalPages }}|first:{{ .IsFirst }}|last:{{ .IsLast }}{{ end }}`,
+		)
+
+		require.NoError(t, h.Build(BuildCfg{}))
+
+		th.assertFileContent("public/posts/index.html",
+			"1/3|first:true|last:false",
+			`<link rel="next" href="http://example.com/blog/posts/page/2/">`,
+			`<link rel="canonical" href="http://example.com/blog/posts/">`)
+		content := readDestination(t, th.Fs, "public/posts/index.html")
+		require.NotContains(t, content, `rel="prev"`)
+		require.NotContains(t, content, "noindex")
+
+		th.assertFileContent("public/posts/page/2/index.html",
+			"2/3|first:false|last:false",
+			`<link rel="prev" href="http://example.com/blog/posts/">`,
+			`<link rel="next" href="http://example.com/blog/posts/page/3/">`,
+			`<link rel="canonical" href="http://example.com/blog/posts/">`,
+			`<meta name="robots" content="noindex, follow">`)
+
+		th.assertFileContent("public/posts/page/3/index.html", "3/3|first:false|last:true")
+
+		if aliasFirstPage {
+			th.assertFileContent("public/posts/page/1/index.html", `<meta http-equiv="refresh" content="0; url=http://example.com/blog/posts/" />`)
+		} else {
+			th.assertFileNotExist("public/posts/page/1/index.html")
+		}
+	}
+}
+
+func TestPaginatorSEOPerLanguage(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+paginate = 1
+defaultContentLanguage = "en"
+
+[Languages]
+[Languages.en]
+weight = 1
+[Languages.nn]
+weight = 2
+paginateNoIndex = true
+paginateAliasFirstPage = false
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/a.md", "---\ntitle: A\n---\n",
+		"content/posts/b.md", "---\ntitle: B\n---\n",
+		"content/posts/a.nn.md", "---\ntitle: A\n---\n",
+		"content/posts/b.nn.md", "---\ntitle: B\n---\n",
+		"layouts/_default/single.html", "{{ .Title }}",
+		"layouts/_default/list.html", `{{ template "_internal/pagination_head.html" . }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	require.NotContains(t, readDestination(t, th.Fs, "public/posts/page/2/index.html"), "noindex")
+	th.assertFileContent("public/nn/posts/page/2/index.html", `<meta name="robots" content="noindex, follow">`)
+
+	th.assertFileContent("public/posts/page/1/index.html", `<meta http-equiv="refresh"`)
+	th.assertFileNotExist("public/nn/posts/page/1/index.html")
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/plugins"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPluginHelperProcess is not a real test. It is started by
+// TestPlugins as a plugin process.
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("HUGO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID      int
+			Method  string
+			Name    string
+			Args    []interface{}
+			Content string
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+
+		var result interface{}
+		switch req.Method {
+		case "describe":
+			result = map[string][]string{"funcs": {"shout"}, "converters": {"textile"}}
+		case "call":
+			result = strings.ToUpper(fmt.Sprint(req.Args[0])) + "!"
+		case "convert":
+			result = "<h1>" + strings.TrimSpace(strings.TrimPrefix(req.Content, "h1.")) + "</h1>"
+		}
+
+		b, _ := json.Marshal(map[string]interface{}{"id": req.ID, "result": result})
+		fmt.Println(string(b))
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	defer plugins.StopAll()
+
+	config := fmt.Sprintf(`
+baseURL = "http://example.com/"
+
+[[plugins]]
+name = "test"
+command = %q
+args = ["-test.run=TestPluginHelperProcess"]
+env = ["HUGO_WANT_HELPER_PROCESS=1"]
+
+[security.plugins]
+allow = ["test"]
+`, os.Args[0])
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/page.md", "---\ntitle: Page\nmarkup: textile\n---\nh1. Hello Textile\n",
+		"layouts/_default/single.html", `{{ shout .Title }}|{{ .Content }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/page/index.html", "PAGE!|<h1>Hello Textile</h1>")
+}
+
+func TestPluginsNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+
+[[plugins]]
+name = "test"
+command = "hugo-does-not-exist"
+
+[security.plugins]
+allow = ["other"]
+`
+
+	fs := afero.NewMemMapFs()
+	writeToFs(t, fs, "config.toml", config)
+
+	_, err := Build(nil, WithFs(fs), WithConfigFile("config.toml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `plugin "test" is not allowed`)
+
+	// Not allowed by default.
+	writeToFs(t, fs, "config.toml", strings.Split(config, "[security.plugins]")[0])
+
+	_, err = Build(nil, WithFs(fs), WithConfigFile("config.toml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `plugin "test" is not allowed`)
+}
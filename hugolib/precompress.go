@@ -0,0 +1,301 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/media"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	precompressGzip   = "gzip"
+	precompressBrotli = "brotli"
+)
+
+var precompressSuffixes = map[string]string{
+	precompressGzip:   ".gz",
+	precompressBrotli: ".br",
+}
+
+// precompressConfig configures the pre-compression of the published files,
+// set in the precompress section in site config.
+type precompressConfig struct {
+	// The compressed siblings to create, gzip and/or brotli.
+	Formats []string
+
+	// The media types to compress, e.g. "text/*" or "image/svg+xml".
+	MediaTypes []string
+
+	// Files smaller than this are not compressed.
+	MinSize int64
+
+	// The command used for brotli, which must read from stdin and write to
+	// stdout.
+	BrotliCommand string
+}
+
+func newDefaultPrecompressConfig() precompressConfig {
+	return precompressConfig{
+		Formats: []string{precompressGzip},
+		MediaTypes: []string{
+			"text/*",
+			"application/javascript",
+			"application/json",
+			"application/xml",
+			"application/rss+xml",
+			"image/svg+xml",
+		},
+		MinSize:       1024,
+		BrotliCommand: "brotli --stdout --quality=11",
+	}
+}
+
+func decodePrecompressConfig(m map[string]interface{}) (precompressConfig, error) {
+	c := newDefaultPrecompressConfig()
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	for i, f := range c.Formats {
+		f = strings.ToLower(f)
+		if _, found := precompressSuffixes[f]; !found {
+			return c, fmt.Errorf("invalid precompress format %q, must be gzip or brotli", f)
+		}
+		c.Formats[i] = f
+	}
+
+	for _, pattern := range c.MediaTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return c, fmt.Errorf("invalid precompress media type %q: %s", pattern, err)
+		}
+	}
+
+	return c, nil
+}
+
+// Precompress writes gzip and/or brotli compressed siblings, e.g.
+// main.css.gz, of the published files matching the precompress config, so
+// web servers and hosts that serve precompressed files can use them. Files
+// already compressed in an earlier build are skipped. It returns the number
+// of compressed files written.
+func (h *HugoSites) Precompress() (int, error) {
+	s := h.Sites[0]
+
+	if !s.Cfg.IsSet("precompress") {
+		return 0, nil
+	}
+
+	conf, err := decodePrecompressConfig(s.Cfg.GetStringMap("precompress"))
+	if err != nil {
+		return 0, err
+	}
+
+	p := &precompressor{
+		conf:       conf,
+		fs:         s.Fs.Destination,
+		mediaTypes: s.mediaTypesConfig,
+	}
+
+	return p.compressDir(s.absPublishDir())
+}
+
+type precompressor struct {
+	conf       precompressConfig
+	fs         afero.Fs
+	mediaTypes media.Types
+
+	counter uint64
+}
+
+func (p *precompressor) compressDir(dir string) (int, error) {
+	if exists, _ := helpers.DirExists(dir, p.fs); !exists {
+		return 0, nil
+	}
+
+	filenames := make(chan string)
+
+	g := &errgroup.Group{}
+
+	numWorkers := runtime.NumCPU()
+	for i := 0; i < numWorkers; i++ {
+		g.Go(func() error {
+			var err error
+			for filename := range filenames {
+				// Keep draining the channel on errors.
+				if err == nil {
+					err = p.compressFile(filename)
+				}
+			}
+			return err
+		})
+	}
+
+	err := afero.Walk(p.fs, dir, func(filename string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || fi.Size() < p.conf.MinSize || !p.matches(filename) {
+			return nil
+		}
+		filenames <- filename
+		return nil
+	})
+
+	close(filenames)
+
+	if werr := g.Wait(); err == nil {
+		err = werr
+	}
+
+	return int(p.counter), err
+}
+
+// matches reports whether the file has one of the configured media types.
+func (p *precompressor) matches(filename string) bool {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return false
+	}
+
+	var mediaType string
+	if m, found := p.mediaTypes.GetBySuffix(ext[1:]); found {
+		mediaType = m.Type()
+	} else {
+		mediaType = mime.TypeByExtension(ext)
+		if i := strings.Index(mediaType, ";"); i != -1 {
+			mediaType = mediaType[:i]
+		}
+	}
+
+	if mediaType == "" {
+		return false
+	}
+
+	for _, pattern := range p.conf.MediaTypes {
+		if match, _ := path.Match(pattern, mediaType); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *precompressor) compressFile(filename string) error {
+	fi, err := p.fs.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range p.conf.Formats {
+		target := filename + precompressSuffixes[format]
+
+		// The compressed file gets the modification time of the source, so
+		// unchanged files, e.g. static files, are not compressed again.
+		if tfi, err := p.fs.Stat(target); err == nil && tfi.ModTime().Equal(fi.ModTime()) {
+			continue
+		}
+
+		if err := p.compress(format, filename, target); err != nil {
+			return err
+		}
+
+		if err := p.fs.Chtimes(target, fi.ModTime(), fi.ModTime()); err != nil {
+			return err
+		}
+
+		atomic.AddUint64(&p.counter, 1)
+	}
+
+	return nil
+}
+
+func (p *precompressor) compress(format, filename, target string) error {
+	in, err := p.fs.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := p.fs.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch format {
+	case precompressBrotli:
+		err = p.brotli(in, out)
+	default:
+		err = p.gzip(in, out)
+	}
+
+	if err != nil {
+		out.Close()
+		p.fs.Remove(target)
+		return fmt.Errorf("failed to compress %q with %s: %s", filename, format, err)
+	}
+
+	return nil
+}
+
+func (p *precompressor) gzip(r io.Reader, w io.Writer) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(gz, r); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (p *precompressor) brotli(r io.Reader, w io.Writer) error {
+	args := strings.Fields(p.conf.BrotliCommand)
+	if len(args) == 0 {
+		return fmt.Errorf("brotliCommand is empty")
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return fmt.Errorf("%s; install brotli or set brotliCommand in the precompress config", err)
+		}
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecompress(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip on Windows")
+	}
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[precompress]
+formats = ["gzip", "brotli"]
+minSize = 100
+# A stand-in for brotli.
+brotliCommand = "cat"
+`
+
+	long := strings.Repeat("Hugo ", 100)
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", "---\ntitle: P1\n---\n"+long,
+		"content/p2.md", "---\ntitle: P2\n---\n",
+		"layouts/_default/single.html", "{{ .Content }}",
+		"layouts/index.html", "Home",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	count, err := h.Precompress()
+	assert.NoError(err)
+	assert.Equal(2, count)
+
+	f, err := th.Fs.Destination.Open(filepath.FromSlash("public/p1/index.html.gz"))
+	assert.NoError(err)
+	gz, err := gzip.NewReader(f)
+	assert.NoError(err)
+	b, err := ioutil.ReadAll(gz)
+	f.Close()
+	assert.NoError(err)
+	assert.Contains(string(b), "Hugo Hugo")
+
+	th.assertFileContent("public/p1/index.html.br", "<p>Hugo Hugo")
+
+	// Too small.
+	th.assertFileNotExist("public/p2/index.html.gz")
+	th.assertFileNotExist("public/index.html.gz")
+
+	// Unchanged.
+	count, err = h.Precompress()
+	assert.NoError(err)
+	assert.Equal(0, count)
+}
+
+func TestDecodePrecompressConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	c, err := decodePrecompressConfig(map[string]interface{}{"formats": []string{"Brotli"}})
+	assert.NoError(err)
+	assert.Equal([]string{"brotli"}, c.Formats)
+	assert.Contains(c.MediaTypes, "text/*")
+	assert.Equal(int64(1024), c.MinSize)
+
+	_, err = decodePrecompressConfig(map[string]interface{}{"formats": []string{"zip"}})
+	assert.Error(err)
+
+	_, err = decodePrecompressConfig(map[string]interface{}{"mediaTypes": []string{"text/["}})
+	assert.Error(err)
+
+	p := &precompressor{conf: newDefaultPrecompressConfig()}
+	assert.True(p.matches("main.css"))
+	assert.True(p.matches("index.html"))
+	assert.True(p.matches("logo.svg"))
+	assert.False(p.matches("photo.jpg"))
+	assert.False(p.matches("main.css.gz"))
+	assert.False(p.matches("README"))
+}
@@ -0,0 +1,383 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/output"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"golang.org/x/net/html"
+)
+
+// The preload link types, i.e. the as attribute.
+const (
+	preloadStyle  = "style"
+	preloadScript = "script"
+	preloadFont   = "font"
+)
+
+// PreloadLink is a resource referenced by a page to preload, e.g. with a
+// Link header.
+type PreloadLink struct {
+	Href string `json:"href"`
+	As   string `json:"as"`
+}
+
+// String returns the link as a Link header value.
+func (l PreloadLink) String() string {
+	s := fmt.Sprintf("<%s>; rel=preload; as=%s", l.Href, l.As)
+	if l.As == preloadFont {
+		// Fonts are always fetched in CORS mode.
+		s += "; crossorigin"
+	}
+	return s
+}
+
+// preloadConfig configures the preload links collected from the stylesheets,
+// scripts and fonts referenced by the rendered HTML pages, set in
+// deployment.preload:
+//
+//   [deployment.preload]
+//   headers = true
+//   manifest = "preload.json"
+type preloadConfig struct {
+	// Add Link headers to the files in deployment.headersFiles.
+	Headers bool
+
+	// The filename of a JSON file with the preload links per page, relative
+	// to the publish dir.
+	Manifest string
+
+	// The link types to preload, style, script and/or font.
+	As []string
+}
+
+func decodePreloadConfig(v interface{}) (preloadConfig, error) {
+	var c preloadConfig
+	if v != nil {
+		if err := mapstructure.WeakDecode(v, &c); err != nil {
+			return c, fmt.Errorf("failed to decode preload config: %s", err)
+		}
+	}
+
+	if len(c.As) == 0 {
+		c.As = []string{preloadStyle, preloadScript, preloadFont}
+	}
+
+	for i, as := range c.As {
+		as = strings.ToLower(as)
+		switch as {
+		case preloadStyle, preloadScript, preloadFont:
+		default:
+			return c, fmt.Errorf("invalid preload type %q, must be one of style, script or font", as)
+		}
+		c.As[i] = as
+	}
+
+	return c, nil
+}
+
+func (c preloadConfig) enabled() bool {
+	return c.Headers || c.Manifest != ""
+}
+
+func (c preloadConfig) includes(as string) bool {
+	for _, v := range c.As {
+		if v == as {
+			return true
+		}
+	}
+	return false
+}
+
+var preloadFontRe = regexp.MustCompile(`url\(\s*['"]?([^'")]+\.(?:woff2|woff|ttf|otf))(?:[?#][^'")]*)?['"]?\s*\)`)
+
+// preloadCollector collects the preload links from the rendered pages.
+type preloadCollector struct {
+	conf          preloadConfig
+	fs            afero.Fs
+	absPublishDir string
+	baseURL       *url.URL
+
+	// Stylesheet => its fonts.
+	fonts map[string][]string
+}
+
+// collectPreloadLinks returns the preload links for the HTML pages in the
+// given sites, keyed by the page's path.
+func collectPreloadLinks(conf preloadConfig, sites ...*Site) (map[string][]PreloadLink, error) {
+	links := make(map[string][]PreloadLink)
+	if len(sites) == 0 {
+		return links, nil
+	}
+
+	s := sites[0]
+	c := &preloadCollector{
+		conf:          conf,
+		fs:            s.Fs.Destination,
+		absPublishDir: s.absPublishDir(),
+		baseURL:       s.PathSpec.BaseURL.URL(),
+		fonts:         make(map[string][]string),
+	}
+
+	for _, s := range sites {
+		for _, p := range s.Pages {
+			for _, f := range p.outputFormats {
+				if !f.IsHTML {
+					continue
+				}
+
+				target, err := p.createTargetPath(f, false)
+				if err != nil || target == "" {
+					continue
+				}
+
+				b, err := afero.ReadFile(c.fs, filepath.Join(s.absPublishDir(), target))
+				if err != nil {
+					// Not rendered, e.g. in partial rebuilds.
+					continue
+				}
+
+				pageURL := p.pageOutputRelPermalink(f)
+				pl, err := c.extract(bytes.NewReader(b), pageURL)
+				if err != nil {
+					return nil, fmt.Errorf("failed to collect preload links for %q: %s", target, err)
+				}
+				if len(pl) > 0 {
+					links[pageURL] = pl
+				}
+			}
+		}
+	}
+
+	return links, nil
+}
+
+// extract returns the preload links for the stylesheets and scripts in the
+// HTML document and the fonts referenced by these stylesheets.
+func (c *preloadCollector) extract(r io.Reader, pageURL string) ([]PreloadLink, error) {
+	var (
+		links []PreloadLink
+		seen  = make(map[string]bool)
+		z     = html.NewTokenizer(r)
+	)
+
+	add := func(href, as string) {
+		if href == "" || seen[href] || !c.conf.includes(as) {
+			return
+		}
+		seen[href] = true
+		links = append(links, PreloadLink{Href: href, As: as})
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return links, err
+			}
+			break
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		t := z.Token()
+		switch t.Data {
+		case "link":
+			if !strings.EqualFold(tokenAttr(t, "rel"), "stylesheet") {
+				continue
+			}
+			href := c.localPath(pageURL, tokenAttr(t, "href"))
+			add(href, preloadStyle)
+			if href != "" && c.conf.includes(preloadFont) {
+				for _, font := range c.stylesheetFonts(href) {
+					add(font, preloadFont)
+				}
+			}
+		case "script":
+			add(c.localPath(pageURL, tokenAttr(t, "src")), preloadScript)
+		}
+	}
+
+	return links, nil
+}
+
+// stylesheetFonts returns the fonts referenced in the published stylesheet.
+func (c *preloadCollector) stylesheetFonts(href string) []string {
+	if fonts, found := c.fonts[href]; found {
+		return fonts
+	}
+
+	var fonts []string
+	b, err := afero.ReadFile(c.fs, filepath.Join(c.absPublishDir, filepath.FromSlash(c.publishPath(href))))
+	if err == nil {
+		for _, m := range preloadFontRe.FindAllSubmatch(b, -1) {
+			if font := c.localPath(href, string(m[1])); font != "" {
+				fonts = append(fonts, font)
+			}
+		}
+	}
+
+	c.fonts[href] = fonts
+	return fonts
+}
+
+// localPath resolves the reference relative to the given path and returns
+// its path, if it is on this site.
+func (c *preloadCollector) localPath(base, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	if u.Host != "" && u.Host != c.baseURL.Host {
+		return ""
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+
+	return b.ResolveReference(u).Path
+}
+
+// publishPath returns the path relative to the publish dir for the given
+// URL path, i.e. without the base path of the site.
+func (c *preloadCollector) publishPath(p string) string {
+	basePath := strings.TrimSuffix(c.baseURL.Path, "/")
+	return strings.TrimPrefix(strings.TrimPrefix(p, basePath), "/")
+}
+
+func tokenAttr(t html.Token, name string) string {
+	for _, a := range t.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// preloadHeaders creates the Link headers for the preload links. The links
+// shared by all pages are set for the page paths only, see
+// preloadPagePatterns, so they are not sent with the preloaded files.
+func preloadHeaders(links map[string][]PreloadLink) []Headers {
+	if len(links) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(links))
+	for p := range links {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	counts := make(map[PreloadLink]int)
+	for _, pl := range links {
+		for _, l := range pl {
+			counts[l]++
+		}
+	}
+
+	var (
+		headers []Headers
+		common  []string
+	)
+
+	for _, l := range links[paths[0]] {
+		if counts[l] == len(links) {
+			common = append(common, l.String())
+		}
+	}
+
+	if len(common) > 0 {
+		for _, pattern := range preloadPagePatterns(paths) {
+			headers = append(headers, Headers{For: pattern, Values: map[string]string{"Link": strings.Join(common, ", ")}})
+		}
+	}
+
+	for _, p := range paths {
+		var values []string
+		for _, l := range links[p] {
+			if counts[l] != len(links) {
+				values = append(values, l.String())
+			}
+		}
+		if len(values) > 0 {
+			headers = append(headers, Headers{For: p, Values: map[string]string{"Link": strings.Join(values, ", ")}})
+		}
+	}
+
+	return headers
+}
+
+// preloadPagePatterns returns the path patterns matching the given page
+// paths but not the CSS, JS and font files they preload: / for the home page,
+// /*/ and /*.html for the pretty and ugly page URLs, and the path itself for
+// any other page.
+func preloadPagePatterns(paths []string) []string {
+	var (
+		patterns []string
+		seen     = make(map[string]bool)
+	)
+
+	for _, p := range paths {
+		pattern := p
+		switch {
+		case p == "/":
+		case strings.HasSuffix(p, "/"):
+			pattern = "/*/"
+		case strings.HasSuffix(p, ".html"):
+			pattern = "/*.html"
+		}
+		if !seen[pattern] {
+			seen[pattern] = true
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	sort.Strings(patterns)
+
+	return patterns
+}
+
+// preloadManifest creates the JSON manifest with the preload links per page.
+func preloadManifest(links map[string][]PreloadLink) ([]byte, error) {
+	return json.MarshalIndent(links, "", "  ")
+}
+
+// pageOutputRelPermalink returns the relative permalink of the page in the
+// given output format.
+func (p *Page) pageOutputRelPermalink(f output.Format) string {
+	if o := p.OutputFormats().Get(f.Name); o != nil {
+		return o.RelPermalink()
+	}
+	return p.RelPermalink()
+}
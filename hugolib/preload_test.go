@@ -0,0 +1,120 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePreloadConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	c, err := decodePreloadConfig(nil)
+	assert.NoError(err)
+	assert.False(c.enabled())
+	assert.True(c.includes(preloadFont))
+
+	c, err = decodePreloadConfig(map[string]interface{}{"headers": true, "as": []string{"Style"}})
+	assert.NoError(err)
+	assert.True(c.enabled())
+	assert.True(c.includes(preloadStyle))
+	assert.False(c.includes(preloadScript))
+
+	_, err = decodePreloadConfig(map[string]interface{}{"as": []string{"image"}})
+	assert.Error(err)
+}
+
+func TestPreloadLinkString(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	assert.Equal("</css/main.css>; rel=preload; as=style", PreloadLink{Href: "/css/main.css", As: preloadStyle}.String())
+	assert.Equal("</fonts/a.woff2>; rel=preload; as=font; crossorigin", PreloadLink{Href: "/fonts/a.woff2", As: preloadFont}.String())
+}
+
+func TestPreloadHeaders(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	style := PreloadLink{Href: "/css/main.css", As: preloadStyle}
+	script := PreloadLink{Href: "/js/search.js", As: preloadScript}
+
+	headers := preloadHeaders(map[string][]PreloadLink{
+		"/":           {style},
+		"/about/":     {style, script},
+		"/print.html": {style},
+	})
+
+	assert.Len(headers, 4)
+	assert.Equal("/", headers[0].For)
+	assert.Equal("/*.html", headers[1].For)
+	assert.Equal("/*/", headers[2].For)
+	for _, h := range headers[:3] {
+		assert.Equal(style.String(), h.Values["Link"])
+
+		// The preloaded files must not preload themselves.
+		decoded, err := decodeHeaders([]map[string]interface{}{{"for": h.For, "values": h.Values}})
+		assert.NoError(err)
+		assert.True(decoded[0].Matches("/about/") || decoded[0].Matches("/") || decoded[0].Matches("/print.html"))
+		assert.False(decoded[0].Matches(style.Href))
+	}
+	assert.Equal("/about/", headers[3].For)
+	assert.Equal(script.String(), headers[3].Values["Link"])
+
+	assert.Nil(preloadHeaders(nil))
+}
+
+func TestRenderPreload(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+[deployment]
+headersFiles = ["_headers"]
+[deployment.preload]
+headers = true
+manifest = "preload.json"
+`
+
+	fs := afero.NewMemMapFs()
+	writeToFs(t, fs, "public/css/main.css", `@font-face { src: url("../fonts/a.woff2") format("woff2"), url(/fonts/a.woff); }`)
+
+	th, h := newTestSitesFromConfig(t, fs, config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"layouts/index.html", `<link rel="stylesheet" href="/css/main.css"><script src="https://cdn.example.org/lib.js"></script>`,
+		"layouts/_default/single.html", `<link rel="stylesheet" href="../css/main.css"><script src="/js/p.js"></script><img src="/a.png">`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/_headers", `/
+  Link: </css/main.css>; rel=preload; as=style, </fonts/a.woff2>; rel=preload; as=font; crossorigin, </fonts/a.woff>; rel=preload; as=font; crossorigin
+/*/
+  Link: </css/main.css>; rel=preload; as=style, </fonts/a.woff2>; rel=preload; as=font; crossorigin, </fonts/a.woff>; rel=preload; as=font; crossorigin
+/p/
+  Link: </js/p.js>; rel=preload; as=script
+`)
+
+	manifest := readDestination(t, th.Fs, "public/preload.json")
+	assert.True(strings.Contains(manifest, `"/p/": [`), manifest)
+	assert.True(strings.Contains(manifest, `"href": "/js/p.js",`), manifest)
+	assert.False(strings.Contains(manifest, "cdn.example.org"), manifest)
+	assert.False(strings.Contains(manifest, "a.png"), manifest)
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+)
+
+func TestPygmentsCSS(t *testing.T) {
+	t.Parallel()
+
+	cfg, fs := newTestCfg()
+	cfg.Set("pygmentsCSS", "css/syntax.css")
+	cfg.Set("pygmentsStyle", "mystyle")
+	cfg.Set("pygmentsCustomStyles", map[string]interface{}{
+		"mystyle": map[string]interface{}{
+			"base":    "monokai",
+			"keyword": "bold #ff79c6",
+		},
+	})
+
+	writeSource(t, fs, filepath.Join("content", "a.md"), "---\ntitle: A\n---\nContent")
+	writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), "{{ .Content }}")
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{})
+	th := testHelper{s.Cfg, s.Fs, t}
+
+	th.assertFileContent("public/css/syntax.css",
+		"/* Background */ .chroma { ",
+		"/* Keyword */ .chroma .k { color: #ff79c6; font-weight: bold }")
+}
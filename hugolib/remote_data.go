@@ -0,0 +1,277 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// The prefix used for the names of the events sent when remote data has
+// changed, see RemoteDataChangedEvent.
+const remoteDataEventPrefix = "remoteData:"
+
+// RemoteDataSource is site data fetched from a URL. The sources are set in
+// the site config, e.g.:
+//
+//   [[remoteData]]
+//   key = "pricing"
+//   url = "https://api.example.com/pricing.json"
+//   refresh = "5m"
+type RemoteDataSource struct {
+	// The key in .Site.Data. Use dots for nested keys, e.g. "api.pricing".
+	Key string
+
+	URL string
+
+	// One of json, yaml or toml. If not set, the extension of the URL is
+	// used, then the Content-Type returned by the server.
+	Format string
+
+	// How often to refresh the data in server mode. If not set, the data is
+	// only refreshed when the server gets a SIGHUP.
+	Refresh time.Duration
+}
+
+// maxRemoteDataSize is the maximum size in bytes of the data fetched for a
+// remote data source.
+const maxRemoteDataSize = 10 << 20
+
+// remoteData holds the data fetched for the remote data sources. It is
+// shared by all the sites and kept between rebuilds.
+type remoteData struct {
+	sources []RemoteDataSource
+	client  *http.Client
+	maxSize int64
+
+	mu   sync.RWMutex
+	raw  map[string][]byte
+	data map[string]interface{}
+}
+
+func newRemoteData(cfg config.Provider) (*remoteData, error) {
+	var sources []RemoteDataSource
+
+	if v := cfg.Get("remoteData"); v != nil {
+		for _, m := range cast.ToSlice(v) {
+			var source RemoteDataSource
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+				WeaklyTypedInput: true,
+				Result:           &source,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(m); err != nil {
+				return nil, fmt.Errorf("failed to decode remoteData config: %s", err)
+			}
+			if source.Key == "" || source.URL == "" {
+				return nil, fmt.Errorf("remoteData config must have both key and url set, got %q and %q", source.Key, source.URL)
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	comments, err := decodeCommentsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if comments.URL != "" {
+		sources = append(sources, RemoteDataSource{Key: comments.DataKey, URL: comments.URL, Format: "json"})
+	}
+
+	return &remoteData{
+		sources: sources,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		maxSize: maxRemoteDataSize,
+		raw:     make(map[string][]byte),
+		data:    make(map[string]interface{}),
+	}, nil
+}
+
+// get returns the data for all the sources, fetching those not fetched
+// before. Sources that fail are logged to logger and left out.
+func (r *remoteData) get(logger *jww.Notepad) map[string]interface{} {
+	for _, source := range r.sources {
+		r.mu.RLock()
+		_, found := r.raw[source.Key]
+		r.mu.RUnlock()
+		if found {
+			continue
+		}
+		if _, err := r.fetch(source); err != nil {
+			helpers.Warnidf(logger, "warning-remote-data-failed", "Failed to fetch remote data %q from %s: %s", source.Key, source.URL, err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data := make(map[string]interface{}, len(r.data))
+	for k, v := range r.data {
+		data[k] = v
+	}
+	return data
+}
+
+// refresh fetches the sources with the given keys again, all if none given.
+// It returns the keys of the sources that changed.
+func (r *remoteData) refresh(keys ...string) ([]string, error) {
+	var (
+		changed []string
+		errs    []string
+	)
+
+	for _, source := range r.sources {
+		if len(keys) > 0 && !helpers.InStringArray(keys, source.Key) {
+			continue
+		}
+		isChanged, err := r.fetch(source)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", source.Key, err))
+			continue
+		}
+		if isChanged {
+			changed = append(changed, source.Key)
+		}
+	}
+
+	if len(errs) > 0 {
+		return changed, fmt.Errorf("failed to refresh remote data: %s", strings.Join(errs, "; "))
+	}
+
+	return changed, nil
+}
+
+// fetch fetches and parses the source. It returns whether the content
+// changed since it was last fetched. On failure the previous data is kept.
+func (r *remoteData) fetch(source RemoteDataSource) (bool, error) {
+	resp, err := r.client.Get(source.URL)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, r.maxSize))
+	if err != nil {
+		return false, fmt.Errorf("failed to read the data, it must be at most %d bytes: %s", r.maxSize, err)
+	}
+
+	r.mu.RLock()
+	prev, found := r.raw[source.Key]
+	r.mu.RUnlock()
+	if found && bytes.Equal(prev, b) {
+		return false, nil
+	}
+
+	data, err := unmarshalData(remoteDataFormat(source, resp.Header.Get("Content-Type")), b)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.raw[source.Key] = b
+	r.data[source.Key] = data
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// remoteDataFormat returns the data format of the source, see
+// RemoteDataSource.Format.
+func remoteDataFormat(source RemoteDataSource, contentType string) string {
+	if source.Format != "" {
+		return strings.ToLower(source.Format)
+	}
+
+	if u, err := url.Parse(source.URL); err == nil {
+		if ext := strings.TrimPrefix(path.Ext(u.Path), "."); ext != "" {
+			return ext
+		}
+	}
+
+	switch {
+	case strings.Contains(contentType, "yaml"):
+		return "yaml"
+	case strings.Contains(contentType, "toml"):
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// RemoteDataSources returns the remote data sources set in the site config.
+func (h *HugoSites) RemoteDataSources() []RemoteDataSource {
+	return h.remoteData.sources
+}
+
+// RefreshRemoteData fetches the remote data sources with the given keys
+// again, all if none given. It returns the events to pass to Build to
+// rebuild the sites with the sources that changed.
+func (h *HugoSites) RefreshRemoteData(keys ...string) ([]fsnotify.Event, error) {
+	changed, err := h.remoteData.refresh(keys...)
+
+	var events []fsnotify.Event
+	for _, key := range changed {
+		events = append(events, RemoteDataChangedEvent(key))
+	}
+
+	return events, err
+}
+
+// RemoteDataChangedEvent returns the event to pass to Build when the remote
+// data with the given key has changed.
+func RemoteDataChangedEvent(key string) fsnotify.Event {
+	return fsnotify.Event{Name: remoteDataEventPrefix + key, Op: fsnotify.Write}
+}
+
+func isRemoteDataEvent(e fsnotify.Event) bool {
+	return strings.HasPrefix(e.Name, remoteDataEventPrefix)
+}
+
+// loadRemoteData inserts the remote data into the site data.
+func (s *Site) loadRemoteData() {
+	for key, data := range s.owner.remoteData.get(s.Log) {
+		current := s.Data
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = data
+	}
+}
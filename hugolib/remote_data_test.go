@@ -0,0 +1,142 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteData(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	var (
+		mu    sync.Mutex
+		price = 10
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/pricing":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"basic": %d}`, price)
+		case "/plans.yaml":
+			fmt.Fprint(w, "pro: Pro\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	config := fmt.Sprintf(`
+baseURL = "http://example.com/"
+disableKinds = ["page", "section", "taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[[remoteData]]
+key = "api.pricing"
+url = "%s/pricing"
+refresh = "5m"
+
+[[remoteData]]
+key = "plans"
+url = "%s/plans.yaml"
+`, srv.URL, srv.URL)
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p.md", "---\ntitle: P\n---\n",
+		"data/api/local.toml", `v = "local"`,
+		"layouts/index.html", "Basic: {{ .Site.Data.api.pricing.basic }}|Local: {{ .Site.Data.api.local.v }}|Plans: {{ .Site.Data.plans.pro }}",
+	)
+
+	sources := h.RemoteDataSources()
+	assert.Len(sources, 2)
+	assert.Equal(5*time.Minute, sources[0].Refresh)
+
+	// The data dependencies are only recorded when running, as in server
+	// mode, and the partial rebuild below needs them from the first build.
+	h.running = true
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "Basic: 10|Local: local|Plans: Pro")
+
+	events, err := h.RefreshRemoteData()
+	assert.NoError(err)
+	assert.Len(events, 0)
+
+	mu.Lock()
+	price = 20
+	mu.Unlock()
+
+	events, err = h.RefreshRemoteData("api.pricing")
+	assert.NoError(err)
+	assert.Equal(RemoteDataChangedEvent("api.pricing"), events[0])
+
+	assert.NoError(h.Build(BuildCfg{}, events...))
+
+	th.assertFileContent("public/index.html", "Basic: 20|Local: local")
+}
+
+func TestRemoteDataFormat(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	assert.Equal("toml", remoteDataFormat(RemoteDataSource{URL: "http://a.com/b", Format: "TOML"}, "application/json"))
+	assert.Equal("yml", remoteDataFormat(RemoteDataSource{URL: "http://a.com/b.yml?v=1"}, "application/json"))
+	assert.Equal("yaml", remoteDataFormat(RemoteDataSource{URL: "http://a.com/b"}, "application/x-yaml"))
+	assert.Equal("json", remoteDataFormat(RemoteDataSource{URL: "http://a.com/b"}, ""))
+}
+
+func TestRemoteDataInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, _ := newTestCfg()
+	cfg.Set("remoteData", []map[string]interface{}{{"key": "pricing"}})
+
+	_, err := newRemoteData(cfg)
+	require.Error(t, err)
+}
+
+func TestRemoteDataMaxSize(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"basic": 10}`)
+	}))
+	defer srv.Close()
+
+	cfg, _ := newTestCfg()
+	r, err := newRemoteData(cfg)
+	assert.NoError(err)
+	r.maxSize = 5
+
+	_, err = r.fetch(RemoteDataSource{Key: "pricing", URL: srv.URL})
+	assert.Error(err)
+	assert.Contains(err.Error(), "at most 5 bytes")
+
+	r.maxSize = maxRemoteDataSize
+	changed, err := r.fetch(RemoteDataSource{Key: "pricing", URL: srv.URL})
+	assert.NoError(err)
+	assert.True(changed)
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	bp "github.com/gohugoio/hugo/bufferpool"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/tpl"
+)
+
+// The render hook templates, relative to the layouts dir.
+const (
+	renderHookTable      = "_markup/render-table.html"
+	renderHookBlockquote = "_markup/render-blockquote.html"
+)
+
+// pageRenderHooks renders Markdown tables and blockquotes in the page
+// content with the render hook templates.
+type pageRenderHooks struct {
+	p          *Page
+	table      tpl.Template
+	blockquote tpl.Template
+}
+
+// renderHooks returns the render hooks for the page, or nil if there are
+// none.
+func (p *Page) renderHooks() helpers.RenderHooks {
+	if p.determineMarkupType() != "markdown" {
+		return nil
+	}
+
+	h := &pageRenderHooks{
+		p:          p,
+		table:      p.s.lookupRenderHook(renderHookTable),
+		blockquote: p.s.lookupRenderHook(renderHookBlockquote),
+	}
+
+	if h.table == nil && h.blockquote == nil {
+		return nil
+	}
+
+	return h
+}
+
+func (s *Site) lookupRenderHook(name string) tpl.Template {
+	if s.Tmpl == nil {
+		return nil
+	}
+	if templ := s.Tmpl.Lookup(name); templ != nil {
+		return templ
+	}
+	if templ := s.Tmpl.Lookup("theme/" + name); templ != nil {
+		return templ
+	}
+	return nil
+}
+
+func (h *pageRenderHooks) RenderTable(ctx *helpers.TableContext) ([]byte, bool) {
+	ctx.Page = h.p
+	return h.render(h.table, ctx)
+}
+
+func (h *pageRenderHooks) RenderBlockquote(ctx *helpers.BlockquoteContext) ([]byte, bool) {
+	ctx.Page = h.p
+	return h.render(h.blockquote, ctx)
+}
+
+func (h *pageRenderHooks) render(templ tpl.Template, ctx interface{}) ([]byte, bool) {
+	if templ == nil {
+		return nil, false
+	}
+
+	b := bp.GetBuffer()
+	defer bp.PutBuffer(b)
+
+	if err := templ.Execute(b, ctx); err != nil {
+		h.p.s.Log.ERROR.Printf("Failed to execute render hook %q for page %q: %s", templ.Name(), h.p.Path(), err)
+		return nil, false
+	}
+
+	return append([]byte(nil), b.Bytes()...), true
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHooks(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[blackfriday]
+attributes = true
+`
+
+	content := `---
+title: P
+---
+
+| Name | Size |
+|------|-----:|
+| a    | 1    |
+{.striped}
+
+> [!TIP]
+> Read the docs.
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", content,
+		"content/p2.org", "#+TITLE: Org\n\n| a | b |\n",
+		"layouts/_default/single.html", "Single: {{ .Content }}",
+		"layouts/_markup/render-table.html", `<div class="table-responsive" data-page="{{ .Page.Title }}"><table class="{{ .Attributes.class }}">{{ range .THead }}<tr>{{ range . }}<th class="{{ .Alignment }}">{{ .Text }}</th>{{ end }}</tr>{{ end }}{{ range .TBody }}<tr>{{ range . }}<td class="{{ .Alignment }}">{{ .Text }}</td>{{ end }}</tr>{{ end }}</table></div>`,
+		"layouts/_markup/render-blockquote.html", `{{ if eq .Type "alert" }}<div class="admonition {{ .AlertType }}">{{ .Text }}</div>{{ else }}<blockquote>{{ .Text }}</blockquote>{{ end }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/p1/index.html",
+		`<div class="table-responsive" data-page="P"><table class="striped"><tr><th class="">Name</th><th class="right">Size</th></tr><tr><td class="">a</td><td class="right">1</td></tr></table></div>`,
+		`<div class="admonition tip"><p>Read the docs.</p></div>`,
+	)
+
+	// Only Markdown is supported.
+	th.assertFileContent("public/p2/index.html", "<table>")
+}
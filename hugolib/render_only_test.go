@@ -0,0 +1,81 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOnly(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+renderOnly = ["posts/p1.md", "docs/bundle/index.md"]
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+`
+
+	th, _ := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/_index.md", "---\ntitle: Home\n---\n",
+		"content/posts/_index.md", "---\ntitle: Posts\n---\n",
+		"content/posts/p1.md", "---\ntitle: P1\n---\n",
+		"content/posts/p2.md", "---\ntitle: P2\n---\n",
+		"content/docs/bundle/index.md", "---\ntitle: Bundle\n---\n",
+		"content/docs/bundle/data.json", "{}",
+		"content/docs/other.md", "---\ntitle: Other\n---\n",
+		"content/blog/b1.md", "---\ntitle: B1\n---\n",
+		"layouts/_default/single.html", "Single: {{ .Title }}|{{ range .Resources }}{{ .RelPermalink }}{{ end }}",
+		"layouts/_default/list.html", "List: {{ .Title }}|{{ range .Pages }}{{ .Title }}|{{ end }}",
+	)
+
+	// renderOnly is only honoured in server mode.
+	h, err := NewHugoSites(deps.DepsCfg{Fs: th.Fs, Cfg: th.Cfg, Running: true})
+	require.NoError(t, err)
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "List: Home|")
+	th.assertFileContent("public/posts/index.html", "List: Posts|P1|")
+	th.assertFileContent("public/posts/index.xml", "P1")
+	th.assertFileContent("public/posts/p1/index.html", "Single: P1")
+	th.assertFileContent("public/docs/bundle/index.html", "Single: Bundle|/docs/bundle/data.json")
+	th.assertFileNotExist("public/posts/p2/index.html")
+	th.assertFileNotExist("public/docs/other/index.html")
+	th.assertFileNotExist("public/blog/b1/index.html")
+}
+
+func TestRenderOnlyNotRunning(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+renderOnly = ["posts/p1.md"]
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\n---\n",
+		"content/posts/p2.md", "---\ntitle: P2\n---\n",
+		"layouts/_default/single.html", "Single: {{ .Title }}",
+		"layouts/_default/list.html", "List: {{ .Title }}",
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/p1/index.html", "Single: P1")
+	th.assertFileContent("public/posts/p2/index.html", "Single: P2")
+}
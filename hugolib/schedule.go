@@ -0,0 +1,109 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	// ScheduledPublish is used for a page with a publishDate in the future.
+	ScheduledPublish = "publish"
+
+	// ScheduledExpiry is used for a page with an expiryDate in the future.
+	ScheduledExpiry = "expire"
+)
+
+// ScheduledChange is an upcoming publishDate or expiryDate of a page, when
+// the site needs to be built again to be up to date.
+type ScheduledChange struct {
+	Date time.Time `json:"date"`
+
+	// One of ScheduledPublish or ScheduledExpiry.
+	Kind string `json:"kind"`
+
+	// The path to the content file, relative to the content dir.
+	Path string `json:"path"`
+
+	Lang string `json:"lang,omitempty"`
+}
+
+// ScheduledChanges returns the publish and expiry dates after now for all
+// the pages, including those not built, sorted by date. The drafts are left
+// out unless buildDrafts is set, as they are not built whatever their dates.
+func (h *HugoSites) ScheduledChanges(now time.Time) []ScheduledChange {
+	var changes []ScheduledChange
+
+	buildDrafts := h.Cfg.GetBool("buildDrafts")
+
+	for _, s := range h.Sites {
+		var lang string
+		if len(h.Sites) > 1 {
+			lang = s.Language.Lang
+		}
+
+		for _, p := range s.rawAllPages {
+			if p.Path() == "" || (p.Draft && !buildDrafts) {
+				continue
+			}
+			if p.PublishDate.After(now) {
+				changes = append(changes, ScheduledChange{Date: p.PublishDate, Kind: ScheduledPublish, Path: p.Path(), Lang: lang})
+			}
+			if p.ExpiryDate.After(now) {
+				changes = append(changes, ScheduledChange{Date: p.ExpiryDate, Kind: ScheduledExpiry, Path: p.Path(), Lang: lang})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Date.Equal(changes[j].Date) {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Date.Before(changes[j].Date)
+	})
+
+	return changes
+}
+
+// NextScheduledChange returns the first scheduled change after now that
+// changes the built site, i.e. ignoring the publish dates when buildFuture
+// is set and the expiry dates when buildExpired is set.
+func (h *HugoSites) NextScheduledChange(now time.Time) (ScheduledChange, bool) {
+	buildFuture, buildExpired := h.Cfg.GetBool("buildFuture"), h.Cfg.GetBool("buildExpired")
+
+	for _, c := range h.ScheduledChanges(now) {
+		if (c.Kind == ScheduledPublish && buildFuture) || (c.Kind == ScheduledExpiry && buildExpired) {
+			continue
+		}
+		return c, true
+	}
+
+	return ScheduledChange{}, false
+}
+
+// PrintNextScheduledChange writes the next scheduled change to w, if any, so
+// the next build can be scheduled, and returns whether one was found.
+func (h *HugoSites) PrintNextScheduledChange(w io.Writer, now time.Time) bool {
+	c, found := h.NextScheduledChange(now)
+	if !found {
+		return false
+	}
+
+	fmt.Fprintf(w, "Next scheduled change at %s: %s %s\n", c.Date.Format(time.RFC3339), c.Kind, c.Path)
+
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledChanges(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/past.md", "---\ntitle: Past\ndate: 2017-01-01\nexpiryDate: 2030-06-01\n---\n",
+		"content/future.md", "---\ntitle: Future\npublishDate: 2030-03-01\n---\n",
+		"content/draft.md", "---\ntitle: Draft\ndraft: true\npublishDate: 2030-01-01\n---\n",
+		"content/expired.md", "---\ntitle: Expired\nexpiryDate: 2017-01-01\n---\n",
+		"layouts/_default/single.html", "{{ .Title }}",
+		"layouts/_default/list.html", "List",
+	)
+
+	assert.NoError(h.Build(BuildCfg{SkipRender: true}))
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	changes := h.ScheduledChanges(now)
+	assert.Len(changes, 2)
+	assert.Equal(ScheduledPublish, changes[0].Kind)
+	assert.Equal("future.md", changes[0].Path)
+	assert.Equal(ScheduledExpiry, changes[1].Kind)
+	assert.Equal("past.md", changes[1].Path)
+
+	next, found := h.NextScheduledChange(now)
+	assert.True(found)
+	assert.Equal(changes[0], next)
+
+	var b bytes.Buffer
+	assert.True(h.PrintNextScheduledChange(&b, now))
+	assert.Equal("Next scheduled change at 2030-03-01T00:00:00Z: publish future.md\n", b.String())
+
+	h.Cfg.Set("buildFuture", true)
+	next, _ = h.NextScheduledChange(now)
+	assert.Equal(changes[1], next)
+
+	h.Cfg.Set("buildDrafts", true)
+	changes = h.ScheduledChanges(now)
+	assert.Len(changes, 3)
+	assert.Equal("draft.md", changes[0].Path)
+
+	_, found = h.NextScheduledChange(time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(found)
+}
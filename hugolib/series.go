@@ -0,0 +1,177 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/spf13/cast"
+)
+
+// The front matter field with the series a page belongs to, also the name of
+// the taxonomy generating the series pages when series.generatePages is set
+// in the site config:
+//
+//   [series]
+//   generatePages = true
+const seriesTaxonomy = "series"
+
+// Series is an ordered sequence of pages, e.g. the parts of a tutorial,
+// with the series name set in the series front matter field.
+type Series struct {
+	// The name as set in the front matter of the first page.
+	Name string
+
+	// The pages in reading order: by weight, then by date, oldest first.
+	Pages Pages
+
+	s *Site
+}
+
+// Page returns the series' term page, nil if the series pages are not
+// generated, see series.generatePages.
+func (s *Series) Page() *Page {
+	return s.s.getPage(KindTaxonomy, seriesTaxonomy, s.s.getTaxonomyKey(s.Name))
+}
+
+// indexOf returns the index of the page in the series, -1 if not found.
+func (s *Series) indexOf(p *Page) int {
+	for i, pp := range s.Pages {
+		if pp == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// siteSeries holds the series assembled for a build.
+type siteSeries struct {
+	init   sync.Once
+	series map[string]*Series
+}
+
+// Series returns all the series in the site, keyed by the normalized series
+// name as used in the taxonomy. They are assembled once per build.
+func (s *SiteInfo) Series() map[string]*Series {
+	site := s.s
+	ss := site.series
+
+	ss.init.Do(func() {
+		ss.series = make(map[string]*Series)
+
+		for _, p := range site.RegularPages {
+			for _, name := range seriesNames(p.Params[seriesTaxonomy]) {
+				key := site.getTaxonomyKey(name)
+				series, found := ss.series[key]
+				if !found {
+					series = &Series{Name: name, s: site}
+					ss.series[key] = series
+				}
+				series.Pages = append(series.Pages, p)
+			}
+		}
+
+		for _, series := range ss.series {
+			sortSeriesPages(series.Pages)
+		}
+	})
+
+	return ss.series
+}
+
+func sortSeriesPages(pages Pages) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		pi, pj := pages[i], pages[j]
+		if pi.Weight != pj.Weight {
+			if pi.Weight == 0 || pj.Weight == 0 {
+				// Pages with a weight go first.
+				return pj.Weight == 0
+			}
+			return pi.Weight < pj.Weight
+		}
+		if !pi.Date.Equal(pj.Date) {
+			return pi.Date.Before(pj.Date)
+		}
+		return pi.Title < pj.Title
+	})
+}
+
+// seriesNames returns the series names in the front matter, a single name
+// or a list.
+func seriesNames(v interface{}) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return cast.ToStringSlice(v)
+}
+
+// Series returns the series the page belongs to, the first one listed if
+// more than one, or nil if none.
+func (p *Page) Series() *Series {
+	names := seriesNames(p.Params[seriesTaxonomy])
+	if len(names) == 0 {
+		return nil
+	}
+	return p.s.Info.Series()[p.s.getTaxonomyKey(names[0])]
+}
+
+// AllSeries returns all the series the page belongs to, in the order listed
+// in the front matter.
+func (p *Page) AllSeries() []*Series {
+	var all []*Series
+	siteSeries := p.s.Info.Series()
+	for _, name := range seriesNames(p.Params[seriesTaxonomy]) {
+		if series, found := siteSeries[p.s.getTaxonomyKey(name)]; found {
+			all = append(all, series)
+		}
+	}
+	return all
+}
+
+// SeriesPosition returns the page's position in its series, starting at 1,
+// or 0 if the page is not in a series.
+func (p *Page) SeriesPosition() int {
+	series := p.Series()
+	if series == nil {
+		return 0
+	}
+	return series.indexOf(p) + 1
+}
+
+// NextInSeries returns the next page in the page's series, nil if this is
+// the last.
+func (p *Page) NextInSeries() *Page {
+	series := p.Series()
+	if series == nil {
+		return nil
+	}
+	if i := series.indexOf(p); i >= 0 && i < len(series.Pages)-1 {
+		return series.Pages[i+1]
+	}
+	return nil
+}
+
+// PrevInSeries returns the previous page in the page's series, nil if this
+// is the first.
+func (p *Page) PrevInSeries() *Page {
+	series := p.Series()
+	if series == nil {
+		return nil
+	}
+	if i := series.indexOf(p); i > 0 {
+		return series.Pages[i-1]
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+
+[series]
+generatePages = true
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/part2.md", "---\ntitle: Part 2\ndate: 2018-01-02\nseries: Go Tutorial\n---\n",
+		"content/part1.md", "---\ntitle: Part 1\ndate: 2018-01-03\nweight: 1\nseries: [Go Tutorial]\n---\n",
+		"content/part3.md", "---\ntitle: Part 3\ndate: 2018-01-05\nseries: [go tutorial]\n---\n",
+		"content/other.md", "---\ntitle: Other\n---\n",
+		"layouts/_default/single.html", `{{ .Title }}|{{ with .Series }}{{ .Name }}|{{ range .Pages }}{{ .Title }},{{ end }}|{{ with .Page }}{{ .RelPermalink }}{{ end }}{{ end }}|Pos: {{ .SeriesPosition }}|Prev: {{ with .PrevInSeries }}{{ .Title }}{{ end }}|Next: {{ with .NextInSeries }}{{ .Title }}{{ end }}`,
+		"layouts/_default/list.html", `List: {{ range .Pages }}{{ .Title }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	assert.Len(h.Sites[0].Info.Series(), 1)
+
+	th.assertFileContent("public/part1/index.html", "Part 1|Go Tutorial|Part 1,Part 2,Part 3,|/series/go-tutorial/|Pos: 1|Prev: |Next: Part 2")
+	th.assertFileContent("public/part2/index.html", "Part 2|Go Tutorial|Part 1,Part 2,Part 3,|/series/go-tutorial/|Pos: 2|Prev: Part 1|Next: Part 3")
+	th.assertFileContent("public/part3/index.html", "Part 3|Go Tutorial|Part 1,Part 2,Part 3,|/series/go-tutorial/|Pos: 3|Prev: Part 2|Next: ")
+	th.assertFileContent("public/other/index.html", "Other||Pos: 0|Prev: |Next: ")
+	th.assertFileContent("public/series/go-tutorial/index.html", "List: ")
+}
+
+func TestSeriesOpenGraph(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/both.md", "---\ntitle: Both\nseries: [Go Tutorial, Rust Tutorial]\n---\n",
+		"content/go.md", "---\ntitle: Go\nseries: Go Tutorial\n---\n",
+		"content/rust.md", "---\ntitle: Rust\nseries: Rust Tutorial\n---\n",
+		"layouts/_default/single.html", `All: {{ range .AllSeries }}{{ .Name }},{{ end }}|{{ template "_internal/opengraph.html" . }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/both/index.html",
+		"All: Go Tutorial,Rust Tutorial,|",
+		`<meta property="og:see_also" content="http://example.com/go/" />`,
+		`<meta property="og:see_also" content="http://example.com/rust/" />`)
+	th.assertFileContent("public/go/index.html", "All: Go Tutorial,|")
+}
@@ -277,6 +277,9 @@ func renderShortcode(
 		return ""
 	}
 
+	p.s.dataDeps().addShortcode(p, tmpl, p.s.Tmpl)
+	p.s.pageDeps().addShortcode(p, tmpl, p.s.Tmpl)
+
 	data := &ShortcodeWithPage{Params: sc.params, Page: p, Parent: parent}
 	if sc.params != nil {
 		data.IsNamedParams = reflect.TypeOf(sc.params).Kind() == reflect.Map
@@ -303,7 +306,8 @@ func renderShortcode(
 				Cfg:          p.Language(),
 				DocumentID:   p.UniqueID(),
 				DocumentName: p.Path(),
-				Config:       p.getRenderingConfig()})
+				Config:       p.getRenderingConfig(),
+				RenderHooks:  p.renderHooks()})
 
 			// If the type is “unknown” or “markdown”, we assume the markdown
 			// generation has been performed. Given the input: `a line`, markdown
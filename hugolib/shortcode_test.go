@@ -15,6 +15,7 @@ package hugolib
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -308,6 +309,45 @@ func TestFigureImgWidthAndHeight(t *testing.T) {
 	CheckShortCodeMatch(t, `{{% figure src="/found/here" class="bananas orange" alt="apple" width="50" height="100" %}}`, "\n<figure class=\"bananas orange\">\n    \n        <img src=\"/found/here\" alt=\"apple\" width=\"50\" height=\"100\" />\n    \n    \n</figure>\n", nil)
 }
 
+func TestFigureAndGalleryWithBundleImages(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	sunset, err := ioutil.ReadFile("testdata/sunset.jpg")
+	assert.NoError(err)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[params.figure]
+widths = [300, 600]
+sizes = "50vw"
+[params.gallery]
+thumbnail = "100x50"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/post/index.md", `---
+title: Post
+---
+{{< figure src="sunset-a.jpg" caption="Sunset" >}}
+{{< gallery match="sunset-*" title="My Sunsets" >}}
+`,
+		"content/post/sunset-a.jpg", string(sunset),
+		"content/post/sunset-b.jpg", string(sunset),
+		"layouts/_default/single.html", "{{ .Content }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/post/index.html",
+		`srcset="/post/sunset-a_`, `300x0_resize_`, ` 300w, /post/sunset-a_`, `600x0_resize_`, ` 600w, /post/sunset-a.jpg 900w"`,
+		`sizes="50vw" width="900" height="562" alt="Sunset"`,
+		`<div class="gallery" role="group" aria-label="My Sunsets">`,
+		`<a href="/post/sunset-b.jpg"><img src="/post/sunset-b_`, `100x50_fill_`, `width="100" height="50" alt="Sunset b" /></a>`,
+	)
+}
+
 const testScPlaceholderRegexp = "HAHAHUGOSHORTCODE-\\d+HBHB"
 
 func TestExtractShortcodes(t *testing.T) {
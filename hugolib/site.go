@@ -14,17 +14,20 @@
 package hugolib
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"mime"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gohugoio/hugo/resource"
@@ -44,6 +47,7 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/output"
 	"github.com/gohugoio/hugo/parser"
+	"github.com/gohugoio/hugo/privacy"
 	"github.com/gohugoio/hugo/related"
 	"github.com/gohugoio/hugo/source"
 	"github.com/gohugoio/hugo/tpl"
@@ -85,6 +89,10 @@ type Site struct {
 
 	Taxonomies TaxonomyList
 
+	// The taxonomies, singular to plural, configured or found in the front
+	// matter, see taxonomyNamespace.
+	taxonomiesConfig map[string]string
+
 	// Plural is what we get in the folder, so keep track of this mapping
 	// to get the singular form from that value.
 	taxonomiesPluralSingular map[string]string
@@ -121,6 +129,59 @@ type Site struct {
 	outputFormatsConfig output.Formats
 	mediaTypesConfig    media.Types
 
+	// The page collections defined in the site config and the collections
+	// materialized for the current build.
+	collectionQueries []collectionQuery
+	collections       *siteCollections
+
+	// The page series assembled for the current build.
+	series *siteSeries
+
+	// How the taxonomy terms are normalized and the terms merged in the
+	// last build.
+	termNormalization taxonomyNormalization
+	taxonomyMerges    []*TaxonomyTermMerge
+
+	// The time zone of the front matter dates without one, nil for UTC.
+	timeZone *time.Location
+
+	// The versions of the documentation, see Version.
+	versionsConfig []Version
+	versions       *siteVersions
+
+	// The privacy settings for the built-in shortcodes and templates.
+	privacyConfig privacy.Config
+
+	// The static comments read from the site data.
+	commentsConfig commentsConfig
+	comments       *Comments
+
+	// Set when the asset links are rewritten to a CDN, see cdnURL.
+	cdn *cdnConfig
+
+	// The front matter values applied to the pages matching a path or type.
+	frontMatterDefaults frontMatterDefaults
+
+	// The static files the critical CSS is read from, see loadCriticalCSS.
+	criticalCSSInit sync.Once
+	criticalCSSFs   afero.Fs
+
+	// The source filenames of the page resources by their path relative to
+	// the site root, see criticalCSSResourceSource. Reset on every build.
+	criticalCSSMu        sync.Mutex
+	criticalCSSResources map[string]string
+
+	// Serves the published files to the output converters while rendering,
+	// see converterAssetsURL.
+	converterAssetsMu       sync.Mutex
+	converterAssetsListener net.Listener
+
+	// The links between the pages, collected from their content on first use.
+	backlinks *siteBacklinks
+
+	// The full-text index of the pages for .Site.Search.
+	search *siteSearch
+
 	// We render each site for all the relevant output formats in serial with
 	// this rendering context pointing to the current one.
 	rc *siteRenderingContext
@@ -148,7 +209,7 @@ type siteRenderingContext struct {
 func (s *Site) initRenderFormats() {
 	formatSet := make(map[string]bool)
 	formats := output.Formats{}
-	for _, p := range s.Pages {
+	for _, p := range s.pagesToRender() {
 		for _, f := range p.outputFormats {
 			if !formatSet[f.Name] {
 				formats = append(formats, f)
@@ -178,6 +239,17 @@ func (s *Site) reset() *Site {
 		outputFormats:       s.outputFormats,
 		outputFormatsConfig: s.outputFormatsConfig,
 		mediaTypesConfig:    s.mediaTypesConfig,
+		collectionQueries:   s.collectionQueries,
+		collections:         &siteCollections{},
+		series:              &siteSeries{},
+		versionsConfig:      s.versionsConfig,
+		termNormalization:   s.termNormalization,
+		timeZone:            s.timeZone,
+		versions:            &siteVersions{},
+		privacyConfig:       s.privacyConfig,
+		commentsConfig:      s.commentsConfig,
+		cdn:                 s.cdn,
+		frontMatterDefaults: s.frontMatterDefaults,
 		resourceSpec:        s.resourceSpec,
 		Language:            s.Language,
 		owner:               s.owner,
@@ -246,6 +318,48 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		}
 	}
 
+	collectionQueries, err := decodeCollections(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	privacyConfig, err := privacy.DecodeConfig(cfg.Language.Get("privacy"))
+	if err != nil {
+		return nil, err
+	}
+
+	commentsConfig, err := decodeCommentsConfig(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	cdn, err := decodeCDNConfig(cfg.Language.Get("cdnURL"))
+	if err != nil {
+		return nil, err
+	}
+
+	frontMatterDefaults, err := decodeFrontMatterDefaults(cfg.Language.Get("frontMatterDefaults"))
+	if err != nil {
+		return nil, err
+	}
+
+	versionsConfig, err := decodeVersionsConfig(cfg.Language.Get("versions"))
+	if err != nil {
+		return nil, err
+	}
+
+	termNormalization, err := decodeTaxonomyNormalization(cfg.Language.Get("taxonomyNormalization"))
+	if err != nil {
+		return nil, err
+	}
+
+	var timeZone *time.Location
+	if tz := cfg.Language.GetString("timeZone"); tz != "" {
+		if timeZone, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid timeZone %q: %s", tz, err)
+		}
+	}
+
 	titleFunc := helpers.GetTitleFunc(cfg.Language.GetString("titleCaseStyle"))
 
 	s := &Site{
@@ -258,6 +372,17 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		outputFormats:       outputFormats,
 		outputFormatsConfig: siteOutputFormatsConfig,
 		mediaTypesConfig:    siteMediaTypesConfig,
+		collectionQueries:   collectionQueries,
+		collections:         &siteCollections{},
+		series:              &siteSeries{},
+		versionsConfig:      versionsConfig,
+		termNormalization:   termNormalization,
+		timeZone:            timeZone,
+		versions:            &siteVersions{},
+		privacyConfig:       privacyConfig,
+		commentsConfig:      commentsConfig,
+		cdn:                 cdn,
+		frontMatterDefaults: frontMatterDefaults,
 	}
 
 	s.Info = newSiteInfo(siteBuilderCfg{s: s, pageCollections: c, language: s.Language})
@@ -353,10 +478,12 @@ type SiteInfo struct {
 	Copyright             string
 	LastChange            time.Time
 	Permalinks            PermalinkOverrides
+	resourcePermalinks    PermalinkOverrides
 	Params                map[string]interface{}
 	BuildDrafts           bool
 	canonifyURLs          bool
 	relativeURLs          bool
+	offlineURLs           bool
 	uglyURLs              bool
 	preserveTaxonomyNames bool
 	Data                  *map[string]interface{}
@@ -371,6 +498,31 @@ type SiteInfo struct {
 	sectionPagesMenu               string
 }
 
+// SiteConfig holds the parts of the site configuration available to the templates.
+type SiteConfig struct {
+	// The markup configuration with the features supported per markup format.
+	Markup helpers.MarkupConfig
+
+	// The privacy settings for the built-in shortcodes and templates.
+	Privacy privacy.Config
+}
+
+// TimeZone returns the time zone of the front matter dates without one, set
+// in the timeZone site config, e.g. "Europe/Oslo", UTC if not set. Use it to
+// show dates in the site's time zone: {{ .Date.In .Site.TimeZone }}.
+func (s *SiteInfo) TimeZone() *time.Location {
+	if s.s.timeZone == nil {
+		return time.UTC
+	}
+	return s.s.timeZone
+}
+
+// Config returns the site configuration available to the templates,
+// e.g. .Site.Config.Markup.Formats.asciidoc.Available.
+func (s *SiteInfo) Config() SiteConfig {
+	return SiteConfig{Markup: s.s.ContentSpec.Markup, Privacy: s.s.privacyConfig}
+}
+
 func (s *SiteInfo) Files() []source.File {
 	helpers.Deprecated(".Site", "Files", "", true)
 	return nil
@@ -539,6 +691,21 @@ func (s *Site) timerStep(step string) {
 type whatChanged struct {
 	source bool
 	other  bool
+
+	// If set, only data changed and only these pages, see dataPageKey,
+	// need to be rendered.
+	dataPages map[string]bool
+
+	// If set, only translations changed and only the sites for these
+	// languages need to be rendered.
+	languages map[string]bool
+
+	// The content files changed. Used to find the pages depending on them
+	// in fast render mode.
+	contentFiles map[string]bool
+
+	// Whether content files were created or removed.
+	contentStructure bool
 }
 
 // RegisterMediaTypes will register the Site's media types in the mime
@@ -624,7 +791,16 @@ func (s *Site) translateFileEvents(events []fsnotify.Event) []fsnotify.Event {
 // TODO(bep) clean up/rewrite this method.
 func (s *Site) processPartial(events []fsnotify.Event) (whatChanged, error) {
 
-	events = s.filterFileEvents(events)
+	var remoteDataChanged, fileEvents []fsnotify.Event
+	for _, ev := range events {
+		if isRemoteDataEvent(ev) {
+			remoteDataChanged = append(remoteDataChanged, ev)
+		} else {
+			fileEvents = append(fileEvents, ev)
+		}
+	}
+
+	events = s.filterFileEvents(fileEvents)
 	events = s.translateFileEvents(events)
 
 	s.Log.DEBUG.Printf("Rebuild for events %q", events)
@@ -670,10 +846,20 @@ func (s *Site) processPartial(events []fsnotify.Event) (whatChanged, error) {
 		}
 		if s.isI18nEvent(ev) {
 			logger.Println("i18n changed", ev)
-			i18nChanged = append(dataChanged, ev)
+			i18nChanged = append(i18nChanged, ev)
 		}
 	}
 
+	for _, ev := range remoteDataChanged {
+		logger.Println("Remote data changed", strings.TrimPrefix(ev.Name, remoteDataEventPrefix))
+		dataChanged = append(dataChanged, ev)
+	}
+
+	if len(tmplChanged) > 0 {
+		h.dataDeps.resetTemplates()
+		h.pageDeps.resetTemplates()
+	}
+
 	if len(tmplChanged) > 0 || len(i18nChanged) > 0 {
 		sites := s.owner.Sites
 		first := sites[0]
@@ -697,10 +883,24 @@ func (s *Site) processPartial(events []fsnotify.Event) (whatChanged, error) {
 		s.timerStep("template prep")
 	}
 
+	var dataPages map[string]bool
+
 	if len(dataChanged) > 0 {
 		if err := s.readDataFromSourceFS(); err != nil {
 			s.Log.ERROR.Println(err)
 		}
+
+		if len(sourceChanged) == 0 && len(tmplChanged) == 0 && len(i18nChanged) == 0 && len(shortcodesChanged) == 0 {
+			var contentChanged bool
+			dataPages, contentChanged = h.dataDeps.pagesFor(s.dataKeysChanged(dataChanged))
+			if contentChanged {
+				// The shortcodes are rendered with the content.
+				for _, p := range h.findPagesByDataKeys(dataPages) {
+					contentFilesChanged = append(contentFilesChanged, p.File.Filename())
+				}
+				dataPages = nil
+			}
+		}
 	}
 
 	for _, ev := range sourceChanged {
@@ -742,6 +942,30 @@ func (s *Site) processPartial(events []fsnotify.Event) (whatChanged, error) {
 		}
 	}
 
+	contentFiles := make(map[string]bool)
+	contentStructure := false
+	for _, ev := range sourceReallyChanged {
+		contentFiles[ev.Name] = true
+		if ev.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+			contentStructure = true
+		}
+	}
+
+	// The content of the pages with shortcodes using the changed pages,
+	// e.g. with .GetPage, is processed again.
+	for _, p := range h.findPagesByContentDeps(contentFiles, contentStructure) {
+		contentFilesChanged = append(contentFilesChanged, p.File.Filename())
+	}
+
+	// Pages becoming drafts or no longer drafts, or the same for the
+	// publish and expiry dates, are added to or removed from the lists.
+	built := make(map[string]bool)
+	for filename := range contentFiles {
+		if p := h.GetContentPage(filename); p != nil && p.File != nil && p.File.Filename() == filename {
+			built[filename] = p.shouldBuild()
+		}
+	}
+
 	if len(sourceReallyChanged) > 0 || len(contentFilesChanged) > 0 {
 		var filenamesChanged []string
 		for _, e := range sourceReallyChanged {
@@ -758,9 +982,28 @@ func (s *Site) processPartial(events []fsnotify.Event) (whatChanged, error) {
 		}
 	}
 
+	for filename, wasBuilt := range built {
+		if p := h.GetContentPage(filename); p == nil || p.shouldBuild() != wasBuilt {
+			contentStructure = true
+		}
+	}
+
+	for _, filename := range contentFilesChanged {
+		contentFiles[filename] = true
+	}
+
+	var languages map[string]bool
+	if len(i18nChanged) > 0 && len(sourceChanged) == 0 && len(tmplChanged) == 0 && len(dataChanged) == 0 {
+		languages = h.languagesForTranslationFiles(i18nChanged)
+	}
+
 	changed := whatChanged{
-		source: len(sourceChanged) > 0,
-		other:  len(tmplChanged) > 0 || len(i18nChanged) > 0 || len(dataChanged) > 0,
+		source:           len(sourceChanged) > 0,
+		other:            len(tmplChanged) > 0 || len(i18nChanged) > 0 || len(dataChanged) > 0,
+		dataPages:        dataPages,
+		languages:        languages,
+		contentFiles:     contentFiles,
+		contentStructure: contentStructure,
 	}
 
 	return changed, nil
@@ -804,7 +1047,7 @@ func (s *Site) handleDataFile(r source.ReadableFile) error {
 
 	data, err := s.readData(r)
 	if err != nil {
-		s.Log.WARN.Printf("Failed to read data from %s: %s", filepath.Join(r.Path(), r.LogicalName()), err)
+		helpers.Warnidf(s.Log, "warning-data-read", "Failed to read data from %s: %s", filepath.Join(r.Path(), r.LogicalName()), err)
 		return nil
 	}
 
@@ -822,7 +1065,7 @@ func (s *Site) handleDataFile(r source.ReadableFile) error {
 				// this warning could happen if
 				// 1. A theme uses the same key; the main data folder wins
 				// 2. A sub folder uses the same key: the sub folder wins
-				s.Log.WARN.Printf("Data for key '%s' in path '%s' is overridden in subfolder", key, r.Path())
+				helpers.Warnidf(s.Log, "warning-data-override", "Data for key '%s' in path '%s' is overridden in subfolder", key, r.Path())
 			}
 			data[key] = value
 		}
@@ -842,7 +1085,12 @@ func (s *Site) readData(f source.ReadableFile) (interface{}, error) {
 	defer file.Close()
 	content := helpers.ReaderToBytes(file)
 
-	switch f.Extension() {
+	return unmarshalData(f.Extension(), content)
+}
+
+// unmarshalData parses data in the format given by the file extension, e.g. "json".
+func unmarshalData(ext string, content []byte) (interface{}, error) {
+	switch ext {
 	case "yaml", "yml":
 		return parser.HandleYAMLMetaData(content)
 	case "json":
@@ -850,7 +1098,7 @@ func (s *Site) readData(f source.ReadableFile) (interface{}, error) {
 	case "toml":
 		return parser.HandleTOMLMetaData(content)
 	default:
-		return nil, fmt.Errorf("Data not supported for extension '%s'", f.Extension())
+		return nil, fmt.Errorf("Data not supported for extension '%s'", ext)
 	}
 }
 
@@ -866,9 +1114,18 @@ func (s *Site) readDataFromSourceFS() error {
 
 	}
 
-	err = s.loadData(dataSourceDirs)
+	if err = s.loadData(dataSourceDirs); err != nil {
+		return err
+	}
+	s.loadRemoteData()
+	s.comments = newComments(s.Data, s.commentsConfig.DataKey)
+
+	if s.Info.Authors, err = newAuthorList(s, s.Data[authorsDataKey]); err != nil {
+		return err
+	}
+
 	s.timerStep("load data")
-	return err
+	return nil
 }
 
 func (s *Site) process(config BuildCfg) (err error) {
@@ -941,7 +1198,7 @@ func (s *Site) render(config *BuildCfg, outFormatIdx int) (err error) {
 
 	}
 
-	if err = s.renderPages(config.RecentlyVisited); err != nil {
+	if err = s.renderPages(config); err != nil {
 		return
 	}
 
@@ -952,6 +1209,11 @@ func (s *Site) render(config *BuildCfg, outFormatIdx int) (err error) {
 		return
 	}
 
+	if err = s.renderHeadlessResources(); err != nil {
+		return
+	}
+	s.timerStep("render and write headless bundle resources")
+
 	if err = s.renderSitemap(); err != nil {
 		return
 	}
@@ -962,6 +1224,11 @@ func (s *Site) render(config *BuildCfg, outFormatIdx int) (err error) {
 	}
 	s.timerStep("render and write robots.txt")
 
+	if err = s.renderPygmentsCSS(); err != nil {
+		return
+	}
+	s.timerStep("render and write Pygments CSS")
+
 	if err = s.render404(); err != nil {
 		return
 	}
@@ -996,7 +1263,7 @@ func (s *SiteInfo) HomeAbsURL() string {
 
 // SitemapAbsURL is a convenience method giving the absolute URL to the sitemap.
 func (s *SiteInfo) SitemapAbsURL() string {
-	sitemapDefault := parseSitemap(s.s.Cfg.GetStringMap("sitemap"))
+	sitemapDefault := parseSitemap(s.s.Cfg.GetStringMap("sitemap"), s.s.Log)
 	p := s.HomeAbsURL()
 	if !strings.HasSuffix(p, "/") {
 		p += "/"
@@ -1022,6 +1289,11 @@ func (s *Site) initializeSiteInfo() {
 		permalinks[k] = pathPattern(v)
 	}
 
+	resourcePermalinks := make(PermalinkOverrides)
+	for k, v := range s.Cfg.GetStringMapString("resourcePermalinks") {
+		resourcePermalinks[k] = pathPattern("/" + strings.Trim(v, "/"))
+	}
+
 	defaultContentInSubDir := s.Cfg.GetBool("defaultContentLanguageInSubdir")
 	defaultContentLanguage := s.Cfg.GetString("defaultContentLanguage")
 
@@ -1052,12 +1324,14 @@ func (s *Site) initializeSiteInfo() {
 		BuildDrafts:                    s.Cfg.GetBool("buildDrafts"),
 		canonifyURLs:                   s.Cfg.GetBool("canonifyURLs"),
 		relativeURLs:                   s.Cfg.GetBool("relativeURLs"),
+		offlineURLs:                    s.Cfg.GetBool("offlineURLs"),
 		uglyURLs:                       s.Cfg.GetBool("uglyURLs"),
 		preserveTaxonomyNames:          lang.GetBool("preserveTaxonomyNames"),
 		PageCollections:                s.PageCollections,
 		Menus:                          &s.Menus,
 		Params:                         params,
 		Permalinks:                     permalinks,
+		resourcePermalinks:             resourcePermalinks,
 		Data:                           &s.Data,
 		owner:                          s.owner,
 		s:                              s,
@@ -1200,7 +1474,8 @@ func (c *contentCaptureResultHandler) handleSingles(fis ...*fileInfo) {
 		// May be connected to a language (content files)
 		proc, found := c.contentProcessors[fi.Lang()]
 		if !found {
-			panic("proc not found")
+			// The language is disabled.
+			continue
 		}
 		proc.fileSinglesChan <- fi
 
@@ -1212,7 +1487,8 @@ func (c *contentCaptureResultHandler) handleBundles(d *bundleDirs) {
 
 		proc, found := c.contentProcessors[lang]
 		if !found {
-			panic("proc not found")
+			// The language is disabled.
+			continue
 		}
 		proc.fileBundlesChan <- b
 
@@ -1261,6 +1537,11 @@ func (s *Site) readAndProcessContent(filenames ...string) error {
 	}
 
 	c := newCapturer(s.Log, sourceSpec, handler, bundleMap, baseDir, filenames...)
+	if !s.running() {
+		// renderOnly is for previews in the server, never publish a
+		// partial site.
+		c.renderOnly = nil
+	}
 
 	if err := c.capture(); err != nil {
 		return err
@@ -1432,8 +1713,8 @@ func (s *Site) getTaxonomyKey(key string) string {
 // to be able to determine the page Kind correctly.
 func (s *Site) createTaxonomiesEntries() {
 	s.Taxonomies = make(TaxonomyList)
-	taxonomies := s.Language.GetStringMapString("taxonomies")
-	for _, plural := range taxonomies {
+	s.taxonomiesConfig = s.collectTaxonomies(s.rawAllPages)
+	for _, plural := range s.taxonomiesConfig {
 		s.Taxonomies[plural] = make(Taxonomy)
 	}
 }
@@ -1442,19 +1723,43 @@ func (s *Site) assembleTaxonomies() {
 	s.taxonomiesPluralSingular = make(map[string]string)
 	s.taxonomiesOrigKey = make(map[string]string)
 
-	taxonomies := s.Language.GetStringMapString("taxonomies")
+	taxonomies := s.taxonomiesConfig
 
 	s.Log.INFO.Printf("found taxonomies: %#v\n", taxonomies)
 
-	for singular, plural := range taxonomies {
+	s.taxonomyMerges = nil
+
+	singulars := make([]string, 0, len(taxonomies))
+	for singular := range taxonomies {
+		singulars = append(singulars, singular)
+	}
+	sort.Strings(singulars)
+
+	for _, singular := range singulars {
+		plural := taxonomies[singular]
 		s.taxonomiesPluralSingular[plural] = singular
 
+		var normalizer *taxonomyTermNormalizer
+		if s.termNormalization.enabled() {
+			normalizer = newTaxonomyTermNormalizer(s.termNormalization, plural, !s.Info.preserveTaxonomyNames)
+		}
+
 		for _, p := range s.Pages {
-			vals := p.getParam(plural, !s.Info.preserveTaxonomyNames)
+			if p.Unlisted {
+				continue
+			}
+			vals := p.getTaxonomyParam(plural, !s.Info.preserveTaxonomyNames)
 			weight := p.getParamToLower(plural + "_weight")
 			if weight == nil {
 				weight = 0
 			}
+			if vals != nil && normalizer != nil {
+				if v, ok := vals.(string); ok {
+					vals = normalizer.normalizeTerms([]string{v}, p)[0]
+				} else if v, ok := vals.([]string); ok {
+					vals = normalizer.normalizeTerms(v, p)
+				}
+			}
 			if vals != nil {
 				if v, ok := vals.([]string); ok {
 					for _, idx := range v {
@@ -1480,6 +1785,10 @@ func (s *Site) assembleTaxonomies() {
 		for k := range s.Taxonomies[plural] {
 			s.Taxonomies[plural][k].Sort()
 		}
+
+		if normalizer != nil {
+			s.taxonomyMerges = append(s.taxonomyMerges, normalizer.order...)
+		}
 	}
 
 	s.Info.Taxonomies = s.Taxonomies
@@ -1493,11 +1802,18 @@ func (s *Site) resetBuildState() {
 	// TODO(bep) get rid of this double
 	s.Info.PageCollections = s.PageCollections
 
+	s.collections = &siteCollections{}
+	s.series = &siteSeries{}
+	s.versions = &siteVersions{}
+
 	s.draftCount = 0
 	s.futureCount = 0
 
 	s.expiredCount = 0
 
+	s.criticalCSSResources = nil
+	s.resourceSpec.ResetBuildState()
+
 	for _, p := range s.rawAllPages {
 		p.scratch = newScratch()
 		p.subSections = Pages{}
@@ -1526,7 +1842,7 @@ func (s *Site) layouts(p *PageOutput) ([]string, error) {
 func (s *Site) preparePages() error {
 	var errors []error
 
-	for _, p := range s.Pages {
+	for _, p := range s.pagesToRender() {
 		if err := p.prepareLayouts(); err != nil {
 			errors = append(errors, err)
 		}
@@ -1596,6 +1912,25 @@ func (s *SiteInfo) GetPage(typ string, path ...string) (*Page, error) {
 	return s.getPage(typ, path...), nil
 }
 
+// GetPageInLanguage looks up a page of a given type in the path given in the
+// site of the given language.
+//
+//    {{ with .Site.GetPageInLanguage "fr" "section" "blog" }}{{ .Title }}{{ end }}
+//
+// This will return nil when no page could be found, and an error if there is
+// no site for the language.
+func (s *SiteInfo) GetPageInLanguage(lang, typ string, path ...string) (*Page, error) {
+	if s.owner == nil {
+		return nil, fmt.Errorf("language %q not found", lang)
+	}
+	for _, site := range s.owner.Sites {
+		if site.Language.Lang == lang {
+			return site.getPage(typ, path...), nil
+		}
+	}
+	return nil, fmt.Errorf("language %q not found", lang)
+}
+
 func (s *Site) permalinkForOutputFormat(link string, f output.Format) (string, error) {
 	var (
 		baseURL string
@@ -1619,13 +1954,15 @@ func (s *Site) permalink(link string) string {
 }
 
 func (s *Site) renderAndWriteXML(statCounter *uint64, name string, dest string, d interface{}, layouts ...string) error {
+	s.checkPublishPath(dest, name)
+
 	s.Log.DEBUG.Printf("Render XML for %q to %q", name, dest)
 	renderBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(renderBuffer)
 	renderBuffer.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\" standalone=\"yes\" ?>\n")
 
 	if err := s.renderForLayouts(name, d, renderBuffer, layouts...); err != nil {
-		helpers.DistinctWarnLog.Println(err)
+		helpers.Warnidf(s.Log, "warning-render-failed", "%s", err)
 		return nil
 	}
 
@@ -1653,11 +1990,13 @@ func (s *Site) renderAndWriteXML(statCounter *uint64, name string, dest string,
 }
 
 func (s *Site) renderAndWritePage(statCounter *uint64, name string, dest string, p *PageOutput, layouts ...string) error {
+	s.checkPublishPath(dest, name)
+
 	renderBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(renderBuffer)
 
 	if err := s.renderForLayouts(p.Kind, p, renderBuffer, layouts...); err != nil {
-		helpers.DistinctWarnLog.Println(err)
+		helpers.Warnidf(s.Log, "warning-render-failed", "%s", err)
 		return nil
 	}
 
@@ -1673,10 +2012,21 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, dest string,
 	isHTML := p.outputFormat.IsHTML
 
 	if isHTML {
-		if s.Info.relativeURLs || s.Info.canonifyURLs {
+		// Must run before the links are rewritten below.
+		transformLinks = append(transformLinks, transform.CriticalCSS(s.PathSpec.BaseURL.String(), s.loadCriticalCSS))
+
+		if s.Info.offlineURLs {
+			transformLinks = append(transformLinks, transform.OfflineURL(s.PathSpec.BaseURL.String()))
+		} else if s.Info.relativeURLs || s.Info.canonifyURLs {
 			transformLinks = append(transformLinks, transform.AbsURL)
 		}
 
+		if s.cdn != nil && !s.running() {
+			transformLinks = append(transformLinks, transform.CDNURL(s.PathSpec.BaseURL.String(), s.cdn.URL, func(p string) bool {
+				return s.cdn.matches(s.mediaTypesConfig, p)
+			}))
+		}
+
 		if s.running() && s.Cfg.GetBool("watch") && !s.Cfg.GetBool("disableLiveReload") {
 			transformLinks = append(transformLinks, transform.LiveReloadInject(s.Cfg.GetInt("liveReloadPort")))
 		}
@@ -1684,14 +2034,14 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, dest string,
 		// For performance reasons we only inject the Hugo generator tag on the home page.
 		if p.IsHome() {
 			if !s.Cfg.GetBool("disableHugoGeneratorInject") {
-				transformLinks = append(transformLinks, transform.HugoGeneratorInject)
+				transformLinks = append(transformLinks, transform.HugoGeneratorInject(s.Log))
 			}
 		}
 	}
 
 	var path []byte
 
-	if s.Info.relativeURLs {
+	if s.Info.relativeURLs || s.Info.offlineURLs {
 		path = []byte(helpers.GetDottedRelativePath(dest))
 	} else if s.Info.canonifyURLs {
 		url := s.PathSpec.BaseURL.String()
@@ -1701,12 +2051,48 @@ func (s *Site) renderAndWritePage(statCounter *uint64, name string, dest string,
 		path = []byte(url)
 	}
 
+	isConverted := p.outputFormat.Converter != ""
+
+	if isConverted {
+		// Point root relative links to the published files, so the converter
+		// can embed images and stylesheets.
+		assetsURL, err := s.converterAssetsURL()
+		if err != nil {
+			return err
+		}
+		transformLinks = append(transformLinks, transform.AbsURL)
+		path = []byte(assetsURL)
+	}
+
 	transformer := transform.NewChain(transformLinks...)
 	if err := transformer.Apply(outBuffer, renderBuffer, path); err != nil {
 		helpers.DistinctErrorLog.Println(err)
 		return nil
 	}
 
+	if isHTML && s.owner.fontText != nil {
+		s.owner.fontText.addHTML(outBuffer.Bytes())
+	}
+
+	if isConverted {
+		convertBuffer := bp.GetBuffer()
+		defer bp.PutBuffer(convertBuffer)
+
+		if err := s.convertOutput(p.outputFormat, dest, outBuffer, convertBuffer); err != nil {
+			return err
+		}
+
+		return s.publish(statCounter, dest, convertBuffer)
+	}
+
+	if p.outputFormat.HasEncoding() {
+		b, err := p.outputFormat.Encode(outBuffer.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to write %q: %s", dest, err)
+		}
+		return s.publish(statCounter, dest, bytes.NewReader(b))
+	}
+
 	return s.publish(statCounter, dest, outBuffer)
 }
 
@@ -1721,8 +2107,10 @@ func (s *Site) renderForLayouts(name string, d interface{}, w io.Writer, layouts
 			}
 			helpers.DistinctErrorLog.Printf("Failed to render %q: %s", templName, r)
 			// TOD(bep) we really need to fix this. Also see below.
-			if !s.running() && !testMode {
-				os.Exit(-1)
+			if s.Cfg.GetBool("returnRenderErrors") {
+				err = fmt.Errorf("failed to render %q: %s", templName, r)
+			} else if !s.running() && !testMode {
+				s.exitBuild(fmt.Errorf("failed to render %q: %s", templName, r))
 			}
 		}
 	}()
@@ -1732,20 +2120,26 @@ func (s *Site) renderForLayouts(name string, d interface{}, w io.Writer, layouts
 		return fmt.Errorf("[%s] Unable to locate layout for %q: %s\n", s.Language.Lang, name, layouts)
 	}
 
+	if p, ok := d.(*PageOutput); ok {
+		s.dataDeps().addTemplate(p.Page, templ, s.Tmpl)
+		s.pageDeps().addTemplate(p.Page, templ, s.Tmpl)
+	}
+
 	if err = templ.Execute(w, d); err != nil {
+		err = withResourceErrorHint(err)
 		// Behavior here should be dependent on if running in server or watch mode.
 		if p, ok := d.(*PageOutput); ok {
 			if p.File != nil {
-				helpers.DistinctErrorLog.Printf("Error while rendering %q in %q: %s", name, p.File.Dir(), err)
+				helpers.DistinctErrorLog.Printf("Error while rendering %q in %q: %s", name, p.File.Path(), err)
 			} else {
 				helpers.DistinctErrorLog.Printf("Error while rendering %q: %s", name, err)
 			}
 		} else {
 			helpers.DistinctErrorLog.Printf("Error while rendering %q: %s", name, err)
 		}
-		if !s.running() && !testMode {
+		if !s.running() && !testMode && !s.Cfg.GetBool("returnRenderErrors") {
 			// TODO(bep) check if this can be propagated
-			os.Exit(-1)
+			s.exitBuild(err)
 		} else if testMode {
 			return
 		}
@@ -1754,6 +2148,16 @@ func (s *Site) renderForLayouts(name string, d interface{}, w io.Writer, layouts
 	return
 }
 
+// withResourceErrorHint adds a hint to template errors caused by calling a
+// method on a resource that was not found, e.g. a .Resize on the result of a
+// .Resources.GetByPrefix without a match.
+func withResourceErrorHint(err error) error {
+	if !strings.Contains(err.Error(), "nil pointer evaluating resource.") {
+		return err
+	}
+	return fmt.Errorf("%s (the resource was not found; check the name or wrap it in a with)", err)
+}
+
 func (s *Site) findFirstTemplate(layouts ...string) tpl.Template {
 	for _, layout := range layouts {
 		if templ := s.Tmpl.Lookup(layout); templ != nil {
@@ -1763,11 +2167,27 @@ func (s *Site) findFirstTemplate(layouts ...string) tpl.Template {
 	return nil
 }
 
+// checkPublishPath warns if the file published to the path from source can
+// not be written on Windows, see the windowsPaths config.
+func (s *Site) checkPublishPath(path, source string) {
+	if !s.PathSpec.WarnWindowsPaths() {
+		return
+	}
+
+	if err := helpers.CheckWindowsPath(filepath.Join(s.absPublishDir(), path)); err != nil {
+		s.Log.WARN.Printf("%s is published to %q, which is not portable to Windows: %s", source, path, err)
+	}
+}
+
 func (s *Site) publish(statCounter *uint64, path string, r io.Reader) (err error) {
 	s.PathSpec.ProcessingStats.Incr(statCounter)
 
 	path = filepath.Join(s.absPublishDir(), path)
 
+	mu := s.owner.publishLock(path)
+	mu.Lock()
+	defer mu.Unlock()
+
 	return helpers.WriteToDisk(path, r, s.Fs.Destination)
 }
 
@@ -0,0 +1,231 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/tpl/collections"
+	"github.com/mitchellh/mapstructure"
+)
+
+// collectionQuery is a page collection defined in the site config by a
+// where-style query. The query is a list of conditions joined by &&, e.g.:
+//
+//   [collections]
+//   featured = "params.featured == true"
+//   [collections.latest]
+//   where = "section == posts && params.hidden != true"
+//   limit = 5
+//
+// The collections are available in the templates as .Site.Collections, with
+// the names in lower case as for all config keys.
+type collectionQuery struct {
+	Name string
+
+	// The query, see above.
+	Where string
+
+	// The pages to query, "regular" (the default) or "all".
+	From string
+
+	// The maximum number of pages in the collection. Zero means no limit.
+	Limit int
+
+	conditions []collectionCondition
+}
+
+type collectionCondition struct {
+	path  string
+	op    string
+	value interface{}
+}
+
+var collectionConditionRe = regexp.MustCompile(`^([\w.]+)\s*(==|!=|<>|>=|<=|>|<|=|\bnot in\b|\bin\b|\bintersect\b)\s*(.+)$`)
+
+// decodeCollections decodes the collections in the site config.
+func decodeCollections(cfg config.Provider) ([]collectionQuery, error) {
+	var queries []collectionQuery
+
+	for name, v := range cfg.GetStringMap("collections") {
+		q := collectionQuery{Name: name}
+
+		switch vv := v.(type) {
+		case string:
+			q.Where = vv
+		default:
+			if err := mapstructure.WeakDecode(vv, &q); err != nil {
+				return nil, fmt.Errorf("failed to decode collection %q: %s", name, err)
+			}
+			q.Name = name
+		}
+
+		switch strings.ToLower(q.From) {
+		case "", "regular":
+			q.From = "regular"
+		case "all":
+			q.From = "all"
+		default:
+			return nil, fmt.Errorf("invalid from %q in collection %q, must be one of regular or all", q.From, name)
+		}
+
+		conditions, err := parseCollectionQuery(q.Where)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query in collection %q: %s", name, err)
+		}
+		q.conditions = conditions
+
+		queries = append(queries, q)
+	}
+
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+
+	return queries, nil
+}
+
+func parseCollectionQuery(query string) ([]collectionCondition, error) {
+	var conditions []collectionCondition
+
+	for _, part := range splitOutsideQuotes(query, "&&") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		m := collectionConditionRe.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("condition %q must be on the form <field> <operator> <value>", part)
+		}
+
+		// Allow lower case field names, e.g. params.featured.
+		path := m[1]
+		path = strings.ToUpper(path[:1]) + path[1:]
+
+		conditions = append(conditions, collectionCondition{path: path, op: m[2], value: parseCollectionValue(strings.TrimSpace(m[3]))})
+	}
+
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("query %q has no conditions", query)
+	}
+
+	return conditions, nil
+}
+
+// splitOutsideQuotes slices s into the substrings separated by sep, but not
+// where sep is in a single or double quoted value.
+func splitOutsideQuotes(s, sep string) []string {
+	var (
+		parts []string
+		quote byte
+		start int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i = start - 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// parseCollectionValue parses a value in a query, e.g. a quoted string,
+// a bool, a number or a list on the form [a, b].
+func parseCollectionValue(s string) interface{} {
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		var values []interface{}
+		for _, v := range splitOutsideQuotes(s[1:len(s)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, parseCollectionValue(v))
+			}
+		}
+		return values
+	}
+
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+
+	return s
+}
+
+// siteCollections holds the collections materialized for a build.
+type siteCollections struct {
+	init        sync.Once
+	collections map[string]Pages
+}
+
+// Collections returns the page collections defined in the site config. They
+// are evaluated once per build.
+func (s *SiteInfo) Collections() map[string]Pages {
+	site := s.s
+	c := site.collections
+
+	c.init.Do(func() {
+		c.collections = make(map[string]Pages)
+
+		ns := collections.New(site.Deps)
+
+		for _, q := range site.collectionQueries {
+			pages := site.RegularPages
+			if q.From == "all" {
+				pages = site.Pages
+			}
+
+			for _, cond := range q.conditions {
+				result, err := ns.Where(pages, cond.path, cond.op, cond.value)
+				if err != nil {
+					helpers.DistinctErrorLog.Printf("Failed to evaluate collection %q: %s", q.Name, err)
+					pages = nil
+					break
+				}
+				pages = result.(Pages)
+			}
+
+			if q.Limit > 0 && len(pages) > q.Limit {
+				pages = pages[:q.Limit]
+			}
+
+			c.collections[q.Name] = pages
+		}
+	})
+
+	return c.collections
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteCollections(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg, fs := newTestCfg()
+	cfg.Set("collections", map[string]interface{}{
+		"featured": "params.featured == true",
+		"latest": map[string]interface{}{
+			"where": "section == posts && params.hidden != true",
+			"limit": 2,
+		},
+		"tagged": "params.tags intersect ['go', 'rust']",
+	})
+
+	for i, section := range []string{"posts", "posts", "posts", "posts", "docs"} {
+		writeSource(t, fs, filepath.Join("content", section, fmt.Sprintf("p%d.md", i)), fmt.Sprintf(`---
+title: P%d
+weight: %d
+featured: %t
+hidden: %t
+tags: [%q]
+---
+`, i, i+1, i%2 == 0, i == 0, []string{"go", "js", "rust", "js", "go"}[i]))
+	}
+
+	writeSource(t, fs, filepath.Join("layouts", "index.html"), `
+{{ range $k, $v := .Site.Collections }}{{ $k }}:{{ range $v }}{{ .Title }} {{ end }}|{{ end }}`)
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{})
+
+	th := testHelper{s.Cfg, s.Fs, t}
+	th.assertFileContent("public/index.html", "featured:P0 P2 P4 |latest:P1 P2 |tagged:P0 P2 P4 |")
+
+	assert.Len(s.Info.Collections()["featured"], 3)
+}
+
+func TestParseCollectionQuery(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	conditions, err := parseCollectionQuery(`section == "blog" && params.weight >= 2.5 && type not in [a, b]`)
+	assert.NoError(err)
+	assert.Equal([]collectionCondition{
+		{path: "Section", op: "==", value: "blog"},
+		{path: "Params.weight", op: ">=", value: 2.5},
+		{path: "Type", op: "not in", value: []interface{}{"a", "b"}},
+	}, conditions)
+
+	conditions, err = parseCollectionQuery(`title == "Tips && Tricks" && params.tags in ["a, b", 'c']`)
+	assert.NoError(err)
+	assert.Equal([]collectionCondition{
+		{path: "Title", op: "==", value: "Tips && Tricks"},
+		{path: "Params.tags", op: "in", value: []interface{}{"a, b", "c"}},
+	}, conditions)
+
+	_, err = parseCollectionQuery("params.featured")
+	assert.Error(err)
+
+	_, err = parseCollectionQuery("")
+	assert.Error(err)
+}
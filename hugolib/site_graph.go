@@ -0,0 +1,233 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/gohugoio/hugo/resource"
+	"github.com/mitchellh/mapstructure"
+)
+
+// The edge types in the site graph.
+const (
+	graphEdgeLink        = "link"
+	graphEdgeTranslation = "translation"
+	graphEdgeTaxonomy    = "taxonomy"
+	graphEdgeResource    = "resource"
+)
+
+const graphNodeResource = "resource"
+
+// graphConfig configures the site graph export, which is disabled by default:
+//
+//   [graph]
+//   formats = ["json", "graphml"]
+//   filename = "graph"
+type graphConfig struct {
+	// The formats to write, json and/or graphml.
+	Formats []string
+
+	// The filename without the extension, relative to the publish dir.
+	Filename string
+}
+
+func decodeGraphConfig(v interface{}) (graphConfig, error) {
+	c := graphConfig{Filename: "graph"}
+	if v == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(v, &c); err != nil {
+		return c, fmt.Errorf("failed to decode graph config: %s", err)
+	}
+
+	for i, f := range c.Formats {
+		f = strings.ToLower(f)
+		if f != "json" && f != "graphml" {
+			return c, fmt.Errorf("unsupported graph format %q, must be json or graphml", f)
+		}
+		c.Formats[i] = f
+	}
+
+	if c.Filename == "" {
+		c.Filename = "graph"
+	}
+
+	return c, nil
+}
+
+// SiteGraph describes the sites as a graph, with the pages, including the
+// sections and taxonomy terms, and the bundle resources as nodes.
+type SiteGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a page or resource in the site graph, identified by its
+// permalink.
+type GraphNode struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title,omitempty"`
+	Lang  string `json:"lang,omitempty"`
+}
+
+// GraphEdge is a link, translation, taxonomy membership or bundle resource in
+// the site graph.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// Graph returns the graph of all the sites.
+func (h *HugoSites) Graph() *SiteGraph {
+	g := &SiteGraph{}
+
+	seen := make(map[string]bool)
+	addNode := func(n GraphNode) {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	for _, s := range h.Sites {
+		for _, p := range s.Pages {
+			id := p.Permalink()
+			addNode(GraphNode{ID: id, Kind: p.Kind, Title: p.Title, Lang: p.Lang()})
+
+			for _, source := range p.Backlinks() {
+				g.Edges = append(g.Edges, GraphEdge{Source: source.Permalink(), Target: id, Type: graphEdgeLink})
+			}
+
+			for _, t := range p.Translations() {
+				// One edge per translation pair.
+				if t.Permalink() > id {
+					g.Edges = append(g.Edges, GraphEdge{Source: id, Target: t.Permalink(), Type: graphEdgeTranslation})
+				}
+			}
+
+			if p.Kind == KindTaxonomy {
+				for _, member := range p.Pages {
+					g.Edges = append(g.Edges, GraphEdge{Source: member.Permalink(), Target: id, Type: graphEdgeTaxonomy})
+				}
+			}
+
+			for _, r := range p.Resources {
+				if _, isPage := r.(*Page); isPage {
+					continue
+				}
+				addNode(GraphNode{ID: r.Permalink(), Kind: graphNodeResource, Title: resourceTitle(r), Lang: p.Lang()})
+				g.Edges = append(g.Edges, GraphEdge{Source: id, Target: r.Permalink(), Type: graphEdgeResource})
+			}
+		}
+	}
+
+	return g
+}
+
+func resourceTitle(r resource.Resource) string {
+	if n, ok := r.(interface {
+		Name() string
+	}); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// JSON returns the graph as JSON.
+func (g *SiteGraph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// GraphML returns the graph in the GraphML format.
+func (g *SiteGraph) GraphML() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="kind" for="node" attr.name="kind" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="title" for="node" attr.name="title" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="lang" for="node" attr.name="lang" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="type" for="edge" attr.name="type" attr.type="string"/>` + "\n")
+	b.WriteString(`  <graph id="site" edgedefault="directed">` + "\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", xmlEscape(n.ID))
+		fmt.Fprintf(&b, "      <data key=\"kind\">%s</data>\n", xmlEscape(n.Kind))
+		if n.Title != "" {
+			fmt.Fprintf(&b, "      <data key=\"title\">%s</data>\n", xmlEscape(n.Title))
+		}
+		if n.Lang != "" {
+			fmt.Fprintf(&b, "      <data key=\"lang\">%s</data>\n", xmlEscape(n.Lang))
+		}
+		b.WriteString("    </node>\n")
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "    <edge source=\"%s\" target=\"%s\">\n", xmlEscape(e.Source), xmlEscape(e.Target))
+		fmt.Fprintf(&b, "      <data key=\"type\">%s</data>\n", xmlEscape(e.Type))
+		b.WriteString("    </edge>\n")
+	}
+
+	b.WriteString("  </graph>\n</graphml>\n")
+
+	return b.Bytes(), nil
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// renderGraph writes the site graph in the configured formats.
+func (h *HugoSites) renderGraph() error {
+	conf, err := decodeGraphConfig(h.Cfg.Get("graph"))
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Formats) == 0 {
+		return nil
+	}
+
+	g := h.Graph()
+	s := h.Sites[0]
+
+	for _, f := range conf.Formats {
+		var content []byte
+		switch f {
+		case "json":
+			content, err = g.JSON()
+		case "graphml":
+			content, err = g.GraphML()
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.publish(&s.PathSpec.ProcessingStats.Files, conf.Filename+"."+f, bytes.NewReader(content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeGraphConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	c, err := decodeGraphConfig(nil)
+	assert.NoError(err)
+	assert.Empty(c.Formats)
+	assert.Equal("graph", c.Filename)
+
+	c, err = decodeGraphConfig(map[string]interface{}{"formats": []string{"JSON", "graphml"}, "filename": "site"})
+	assert.NoError(err)
+	assert.Equal([]string{"json", "graphml"}, c.Formats)
+	assert.Equal("site", c.Filename)
+
+	_, err = decodeGraphConfig(map[string]interface{}{"formats": []string{"dot"}})
+	assert.Error(err)
+}
+
+func TestSiteGraph(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+[graph]
+formats = ["json", "graphml"]
+[languages]
+[languages.en]
+weight = 1
+[languages.nn]
+weight = 2
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a/index.en.md", "---\ntitle: A\ntags: [\"go\"]\n---\nSee [B](/b/).",
+		"content/a/index.nn.md", "---\ntitle: A nn\n---\n",
+		"content/a/logo.png", "logo",
+		"content/b.en.md", "---\ntitle: B & C\n---\n",
+		"layouts/_default/single.html", "{{ .Content }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	g := h.Graph()
+
+	hasEdge := func(source, target, tp string) bool {
+		for _, e := range g.Edges {
+			if e.Source == source && e.Target == target && e.Type == tp {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.True(hasEdge("http://example.com/a/", "http://example.com/b/", graphEdgeLink))
+	assert.True(hasEdge("http://example.com/a/", "http://example.com/nn/a/", graphEdgeTranslation))
+	assert.True(hasEdge("http://example.com/a/", "http://example.com/tags/go/", graphEdgeTaxonomy))
+	assert.True(hasEdge("http://example.com/a/", "http://example.com/a/logo.png", graphEdgeResource))
+
+	th.assertFileContent("public/graph.json", `"id": "http://example.com/a/logo.png",`, `"type": "translation"`)
+	th.assertFileContent("public/graph.graphml", `<graph id="site" edgedefault="directed">`, `<data key="title">B &amp; C</data>`, `<edge source="http://example.com/a/" target="http://example.com/b/">`)
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/output"
+	"github.com/spf13/afero"
+)
+
+// convertOutput runs the converter of the given output format with the
+// rendered HTML in r as input and writes the result to w.
+// The converter is run in the working dir with the publish dir and the target
+// path available in the HUGO_PUBLISHDIR and HUGO_TARGETPATH env variables.
+func (s *Site) convertOutput(f output.Format, targetPath string, r io.Reader, w io.Writer) error {
+	args := strings.Fields(os.ExpandEnv(f.Converter))
+	if len(args) == 0 {
+		return fmt.Errorf("converter %q for output format %q is empty after expansion", f.Converter, f.Name)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"HUGO_PUBLISHDIR="+s.absPublishDir(),
+		"HUGO_TARGETPATH="+targetPath,
+	)
+
+	if dir := s.PathSpec.WorkingDir(); dir != "" {
+		if exists, _ := helpers.DirExists(dir, hugofs.Os); exists {
+			cmd.Dir = dir
+		}
+	}
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return fmt.Errorf("failed to convert %q to %s: %s; install it or set the converter for the %q output format in your site config", targetPath, f.Name, err, f.Name)
+		}
+		return fmt.Errorf("failed to convert %q to %s: %s: %s", targetPath, f.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// converterAssetsURL returns the URL of a HTTP server on localhost serving
+// the published files, used to resolve root relative links in the HTML
+// passed to output converters. The files are served from the destination
+// file system, so this works when rendering to memory. The server is started
+// on first use and stopped by closeConverterAssets when the site is rendered.
+func (s *Site) converterAssetsURL() (string, error) {
+	s.converterAssetsMu.Lock()
+	defer s.converterAssetsMu.Unlock()
+
+	if s.converterAssetsListener == nil {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return "", fmt.Errorf("failed to serve the published files to the output converters: %s", err)
+		}
+		fs := afero.NewHttpFs(s.Fs.Destination)
+		go http.Serve(l, http.FileServer(fs.Dir(s.absPublishDir())))
+		s.converterAssetsListener = l
+	}
+
+	return "http://" + s.converterAssetsListener.Addr().String() + "/", nil
+}
+
+// closeConverterAssets stops the server started by converterAssetsURL.
+func (s *Site) closeConverterAssets() {
+	s.converterAssetsMu.Lock()
+	defer s.converterAssetsMu.Unlock()
+
+	if s.converterAssetsListener != nil {
+		s.converterAssetsListener.Close()
+		s.converterAssetsListener = nil
+	}
+}
@@ -14,7 +14,11 @@
 package hugolib
 
 import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -373,3 +377,162 @@ baseName = "customdelimbase"
 	require.Equal(t, "/blog/customdelimbase_del", outputs.Get("CUS").RelPermalink())
 
 }
+
+func TestConvertedOutputFormat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip on Windows")
+	}
+
+	siteConfig := `
+baseURL = "http://example.com/blog"
+
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+
+[outputFormats]
+[outputFormats.PDF]
+converter = "tr a-z A-Z"
+`
+
+	mf := afero.NewMemMapFs()
+	writeToFs(t, mf, "content/manual/_index.md", `---
+title: The Manual
+outputs: ["HTML", "PDF"]
+---
+`)
+	writeToFs(t, mf, "layouts/_default/list.html", `list: {{ .Title }}`)
+	writeToFs(t, mf, "layouts/_default/list.pdf.html", `pdf: {{ .Title }} <img src="/images/logo.png">`)
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	s := h.Sites[0]
+
+	th.assertFileContent("public/manual/index.html", "list: The Manual")
+	th.assertFileContent("public/manual/index.pdf", "PDF: THE MANUAL", `<IMG SRC="HTTP://127.0.0.1:`, `/IMAGES/LOGO.PNG">`)
+	require.Nil(t, s.converterAssetsListener)
+
+	manual := s.getPage(KindSection, "manual")
+	require.NotNil(t, manual)
+	require.Equal(t, "/blog/manual/index.pdf", manual.OutputFormats().Get("PDF").RelPermalink())
+}
+
+func TestConvertedOutputFormatError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip on Windows")
+	}
+
+	siteConfig := `
+baseURL = "http://example.com/blog"
+
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"]
+
+[outputFormats]
+[outputFormats.EPUB]
+converter = "hugo-converter-does-not-exist"
+`
+
+	mf := afero.NewMemMapFs()
+	writeToFs(t, mf, "content/manual/_index.md", `---
+title: The Manual
+outputs: ["EPUB"]
+---
+`)
+	writeToFs(t, mf, "layouts/_default/list.epub.html", `epub: {{ .Title }}`)
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig)
+
+	err := h.Build(BuildCfg{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hugo-converter-does-not-exist")
+
+	th.assertFileNotExist("public/manual/index.epub")
+	require.Nil(t, h.Sites[0].converterAssetsListener)
+}
+
+func TestConverterAssetsURL(t *testing.T) {
+	t.Parallel()
+
+	mf := afero.NewMemMapFs()
+	writeToFs(t, mf, "content/_index.md", "---\ntitle: Home\n---\n")
+
+	th, h := newTestSitesFromConfig(t, mf, `baseURL = "http://example.com/"`)
+	s := h.Sites[0]
+
+	writeToFs(t, th.Fs.Destination, filepath.Join(s.absPublishDir(), "images", "logo.png"), "logo")
+
+	u, err := s.converterAssetsURL()
+	require.NoError(t, err)
+	defer s.closeConverterAssets()
+
+	resp, err := http.Get(u + "images/logo.png")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "logo", string(b))
+}
+
+func TestEncodedOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["page", "section", "taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[outputs]
+home = ["HTML", "Calendar", "ExcelCSV"]
+
+[outputFormats]
+[outputFormats.Calendar]
+newline = "crlf"
+[outputFormats.ExcelCSV]
+mediaType = "text/csv"
+baseName = "excel"
+isPlainText = true
+bom = true
+`
+
+	mf := afero.NewMemMapFs()
+	writeToFs(t, mf, "content/_index.md", "---\ntitle: Home\n---\n")
+	writeToFs(t, mf, "layouts/index.html", `home`)
+	writeToFs(t, mf, "layouts/index.ics", "BEGIN:VCALENDAR\nEND:VCALENDAR\n")
+	writeToFs(t, mf, "layouts/index.excelcsv.csv", "a,b\n1,2\n")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	require.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", readDestination(t, th.Fs, "public/index.ics"))
+	require.Equal(t, "\xef\xbb\xbfa,b\n1,2\n", readDestination(t, th.Fs, "public/excel.csv"))
+}
+
+func TestEncodedOutputFormatError(t *testing.T) {
+	t.Parallel()
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["page", "section", "taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[outputs]
+home = ["HTML", "ExcelCSV"]
+
+[outputFormats]
+[outputFormats.ExcelCSV]
+mediaType = "text/csv"
+baseName = "excel"
+isPlainText = true
+charset = "iso-8859-1"
+`
+
+	mf := afero.NewMemMapFs()
+	writeToFs(t, mf, "content/_index.md", "---\ntitle: Home\n---\n")
+	writeToFs(t, mf, "layouts/index.html", `home`)
+	writeToFs(t, mf, "layouts/index.excelcsv.csv", "a,b\n漢,2\n")
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig)
+
+	err := h.Build(BuildCfg{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "excel.csv")
+}
@@ -16,19 +16,21 @@ package hugolib
 import (
 	"fmt"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/gohugoio/hugo/helpers"
 
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/resource"
 
 	bp "github.com/gohugoio/hugo/bufferpool"
 )
 
 // renderPages renders pages each corresponding to a markdown file.
 // TODO(bep np doc
-func (s *Site) renderPages(filter map[string]bool) error {
+func (s *Site) renderPages(config *BuildCfg) error {
 
 	results := make(chan error)
 	pages := make(chan *Page)
@@ -45,10 +47,25 @@ func (s *Site) renderPages(filter map[string]bool) error {
 		go pageRenderer(s, pages, results, wg)
 	}
 
+	filter := config.RecentlyVisited
 	hasFilter := filter != nil && len(filter) > 0
 
-	for _, page := range s.Pages {
-		if hasFilter && !filter[page.RelPermalink()] {
+	var (
+		dataPages        map[string]bool
+		contentFiles     map[string]bool
+		contentStructure bool
+	)
+	if config.whatChanged != nil {
+		dataPages = config.whatChanged.dataPages
+		contentFiles = config.whatChanged.contentFiles
+		contentStructure = config.whatChanged.contentStructure
+	}
+
+	for _, page := range s.pagesToRender() {
+		if hasFilter && !filter[page.RelPermalink()] && !s.pageDeps().dependsOn(page, contentFiles, contentStructure) {
+			continue
+		}
+		if dataPages != nil && !dataPages[dataPageKey(page)] {
 			continue
 		}
 		pages <- page
@@ -135,6 +152,8 @@ func pageRenderer(s *Site, pages <-chan *Page, results chan<- error, wg *sync.Wa
 			case "RSS":
 				if err := s.renderRSS(pageOutput); err != nil {
 					results <- err
+				} else {
+					s.sendPageRenderedEvent(pageOutput)
 				}
 			default:
 				targetPath, err := pageOutput.targetPath()
@@ -147,6 +166,8 @@ func pageRenderer(s *Site, pages <-chan *Page, results chan<- error, wg *sync.Wa
 
 				if err := s.renderAndWritePage(&s.PathSpec.ProcessingStats.Pages, "page "+pageOutput.FullFilePath(), targetPath, pageOutput, layouts...); err != nil {
 					results <- err
+				} else {
+					s.sendPageRenderedEvent(pageOutput)
 				}
 
 				if pageOutput.IsNode() {
@@ -167,16 +188,18 @@ func (s *Site) renderPaginator(p *PageOutput) error {
 		paginatePath := s.Cfg.GetString("paginatePath")
 
 		// write alias for page 1
-		addend := fmt.Sprintf("/%s/%d", paginatePath, 1)
-		target, err := p.createTargetPath(p.outputFormat, false, addend)
-		if err != nil {
-			return err
-		}
+		if s.Language.GetBool("paginateAliasFirstPage") {
+			addend := fmt.Sprintf("/%s/%d", paginatePath, 1)
+			target, err := p.createTargetPath(p.outputFormat, false, addend)
+			if err != nil {
+				return err
+			}
 
-		// TODO(bep) do better
-		link := newOutputFormat(p.Page, p.outputFormat).Permalink()
-		if err := s.writeDestAlias(target, link, nil); err != nil {
-			return err
+			// TODO(bep) do better
+			link := newOutputFormat(p.Page, p.outputFormat).Permalink()
+			if err := s.writeDestAlias(target, link, nil); err != nil {
+				return err
+			}
 		}
 
 		pagers := p.paginator.Pagers()
@@ -304,7 +327,7 @@ func (s *Site) renderSitemap() error {
 		return nil
 	}
 
-	sitemapDefault := parseSitemap(s.Cfg.GetStringMap("sitemap"))
+	sitemapDefault := parseSitemap(s.Cfg.GetStringMap("sitemap"), s.Log)
 
 	n := s.newNodePage(kindSitemap)
 
@@ -320,6 +343,10 @@ func (s *Site) renderSitemap() error {
 	page.Sitemap.Priority = sitemapDefault.Priority
 	page.Sitemap.Filename = sitemapDefault.Filename
 
+	if sitemapDefault.NewsName == "" {
+		sitemapDefault.NewsName = s.Info.Title
+	}
+
 	n.Data["Pages"] = pages
 	n.Pages = pages
 
@@ -341,6 +368,16 @@ func (s *Site) renderSitemap() error {
 		if page.Sitemap.Filename == "" {
 			page.Sitemap.Filename = sitemapDefault.Filename
 		}
+
+		if page.Sitemap.NewsName == "" {
+			page.Sitemap.NewsName = sitemapDefault.NewsName
+		}
+	}
+
+	// The extensions may be inherited from the page's section, so they are
+	// resolved on a copy, leaving the front matter and config untouched.
+	for _, page := range pages {
+		page.Sitemap.extensions = append([]string{}, page.sitemapExtensions(sitemapDefault.Extensions)...)
 	}
 
 	smLayouts := []string{"sitemap.xml", "_default/sitemap.xml", "_internal/_default/sitemap.xml"}
@@ -370,7 +407,7 @@ func (s *Site) renderRobotsTXT() error {
 	outBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(outBuffer)
 	if err := s.renderForLayouts("robots", n, outBuffer, s.appendThemeTemplates(rLayouts)...); err != nil {
-		helpers.DistinctWarnLog.Println(err)
+		helpers.Warnidf(s.Log, "warning-render-failed", "%s", err)
 		return nil
 	}
 
@@ -381,9 +418,56 @@ func (s *Site) renderRobotsTXT() error {
 	return s.publish(&s.PathSpec.ProcessingStats.Pages, "robots.txt", outBuffer)
 }
 
+// renderPygmentsCSS writes the CSS for the configured highlighting style,
+// which may be a custom style, to the pygmentsCSS path if set.
+func (s *Site) renderPygmentsCSS() error {
+	target := s.Cfg.GetString("pygmentsCSS")
+	if target == "" {
+		return nil
+	}
+
+	style, err := s.ContentSpec.ChromaStyle(s.Cfg.GetString("pygmentsStyle"))
+	if err != nil {
+		return err
+	}
+
+	selector := helpers.ChromaCSSSelector
+	if s.Cfg.GetBool("pygmentsUseClassic") {
+		selector = helpers.PygmentsCSSSelector
+	}
+
+	outBuffer := bp.GetBuffer()
+	defer bp.PutBuffer(outBuffer)
+	if err := helpers.WriteChromaCSS(outBuffer, style, selector); err != nil {
+		return err
+	}
+
+	return s.publish(&s.PathSpec.ProcessingStats.Files, filepath.FromSlash(target), outBuffer)
+}
+
+// renderHeadlessResources publishes the resources of the headless bundles.
+// The bundles themselves are not rendered, but other pages may link to their
+// resources.
+func (s *Site) renderHeadlessResources() error {
+	for _, p := range s.headlessPages {
+		for _, r := range p.Resources {
+			src, ok := r.(resource.Source)
+			if !ok {
+				continue
+			}
+			if err := src.Publish(); err != nil {
+				s.Log.ERROR.Printf("Failed to publish %q for headless bundle %q: %s", src.AbsSourceFilename(), p.pathOrTitle(), err)
+				continue
+			}
+			s.PathSpec.ProcessingStats.Incr(&s.PathSpec.ProcessingStats.Files)
+		}
+	}
+	return nil
+}
+
 // renderAliases renders shell pages that simply have a redirect in the header.
 func (s *Site) renderAliases() error {
-	for _, p := range s.Pages {
+	for _, p := range s.pagesToRender() {
 		if len(p.Aliases) == 0 {
 			continue
 		}
@@ -416,6 +500,10 @@ func (s *Site) renderAliases() error {
 		}
 	}
 
+	if err := s.renderLatestVersionAliases(); err != nil {
+		return err
+	}
+
 	if s.owner.multilingual.enabled() && !s.owner.IsMultihost() {
 		mainLang := s.owner.multilingual.DefaultLang
 		if s.Info.defaultContentLanguageInSubdir {
@@ -0,0 +1,274 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+// The BM25 ranking parameters.
+const (
+	searchK1 = 1.2
+	searchB  = 0.75
+
+	// The title terms count this many times the content terms.
+	searchTitleBoost = 3
+)
+
+// siteSearch holds the full-text index of the regular pages in a site, built
+// from the pages' rendered content on first use.
+type siteSearch struct {
+	init  sync.Once
+	index *searchIndex
+}
+
+type searchIndex struct {
+	docs      []*searchDoc
+	docFreq   map[string]int
+	avgLength float64
+	lang      string
+}
+
+type searchDoc struct {
+	page   *Page
+	freq   map[string]int
+	length int
+}
+
+// Search returns the regular pages in this site matching the query, a list
+// of words, in their plain text content or title, the most relevant first.
+// The text is split into words by the rules of the site's language; Chinese
+// and Japanese text, not separated by spaces, is split into pairs of
+// characters.
+//
+// The optional options map may set "limit", the maximum number of pages
+// returned, "section", to only search the pages in that section, and
+// "match", "all" (the default) to only return pages matching all the words
+// or "any" to return pages matching any of them, e.g. to find pages related
+// to a page by their text:
+//
+//   {{ $related := .Site.Search .Title (dict "match" "any" "limit" 5) }}
+func (s *SiteInfo) Search(query string, options ...interface{}) (Pages, error) {
+	var (
+		limit    int
+		section  string
+		matchAll = true
+	)
+
+	if len(options) > 1 {
+		return nil, errors.New("too many arguments")
+	}
+
+	if len(options) == 1 {
+		opts, err := cast.ToStringMapE(options[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode search options: %s", err)
+		}
+
+		for k, v := range opts {
+			switch strings.ToLower(k) {
+			case "limit":
+				if limit, err = cast.ToIntE(v); err != nil || limit < 0 {
+					return nil, fmt.Errorf("invalid search limit: %v", v)
+				}
+			case "section":
+				section = cast.ToString(v)
+			case "match":
+				switch m := cast.ToString(v); m {
+				case "all", "any":
+					matchAll = m == "all"
+				default:
+					return nil, fmt.Errorf("invalid search match %q, must be all or any", m)
+				}
+			default:
+				return nil, fmt.Errorf("unknown search option %q", k)
+			}
+		}
+	}
+
+	ss := s.s.search
+	if ss == nil {
+		return nil, nil
+	}
+
+	ss.init.Do(func() {
+		ss.index = newSearchIndex(s.s.Language.Lang, s.s.RegularPages)
+	})
+
+	return ss.index.search(query, section, matchAll, limit), nil
+}
+
+func newSearchIndex(lang string, pages Pages) *searchIndex {
+	idx := &searchIndex{docFreq: make(map[string]int), lang: lang}
+
+	var total int
+
+	for _, p := range pages {
+		doc := &searchDoc{page: p, freq: make(map[string]int)}
+
+		for _, term := range tokenizeSearchText(lang, p.Plain()) {
+			doc.freq[term]++
+			doc.length++
+		}
+		for _, term := range tokenizeSearchText(lang, p.Title) {
+			doc.freq[term] += searchTitleBoost
+			doc.length += searchTitleBoost
+		}
+
+		for term := range doc.freq {
+			idx.docFreq[term]++
+		}
+
+		total += doc.length
+		idx.docs = append(idx.docs, doc)
+	}
+
+	if len(idx.docs) > 0 {
+		idx.avgLength = float64(total) / float64(len(idx.docs))
+	}
+
+	return idx
+}
+
+func (idx *searchIndex) search(query, section string, matchAll bool, limit int) Pages {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range tokenizeSearchText(idx.lang, query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		page  *Page
+		score float64
+	}
+
+	var (
+		hits []hit
+		n    = float64(len(idx.docs))
+	)
+
+	for _, doc := range idx.docs {
+		if section != "" && doc.page.Section() != section {
+			continue
+		}
+
+		var (
+			score   float64
+			matched int
+		)
+
+		for _, term := range terms {
+			f := float64(doc.freq[term])
+			if f == 0 {
+				continue
+			}
+			matched++
+			df := float64(idx.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * f * (searchK1 + 1) / (f + searchK1*(1-searchB+searchB*float64(doc.length)/idx.avgLength))
+		}
+
+		if matched == 0 || (matchAll && matched < len(terms)) {
+			continue
+		}
+
+		hits = append(hits, hit{page: doc.page, score: score})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	pages := make(Pages, len(hits))
+	for i, h := range hits {
+		pages[i] = h.page
+	}
+
+	return pages
+}
+
+// tokenizeSearchText splits the text into lower case words, letters and
+// digits, using the case rules of the language. Runs of Chinese and Japanese
+// characters are split into overlapping pairs of characters.
+func tokenizeSearchText(lang, s string) []string {
+	toLower := unicode.ToLower
+	switch strings.ToLower(lang) {
+	case "tr", "az":
+		toLower = unicode.TurkishCase.ToLower
+	}
+
+	var (
+		terms []string
+		word  []rune
+		cjk   []rune
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			terms = append(terms, string(cjk))
+		default:
+			for i := 0; i < len(cjk)-1; i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			flushCJK()
+			word = append(word, toLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+
+	flushWord()
+	flushCJK()
+
+	return terms
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeSearchText(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range []struct {
+		lang   string
+		in     string
+		expect []string
+	}{
+		{"en", "Hello, World! Hugo's 2nd-best", []string{"hello", "world", "hugo", "s", "2nd", "best"}},
+		{"de", "Größe ÄNDERN", []string{"größe", "ändern"}},
+		{"tr", "İSTANBUL", []string{"istanbul"}},
+		{"en", "静的サイト生成 Hugo", []string{"静的", "的サ", "サイ", "イト", "ト生", "生成", "hugo"}},
+		{"zh", "我", []string{"我"}},
+	} {
+		require.Equal(t, test.expect, tokenizeSearchText(test.lang, test.in), "[%d]", i)
+	}
+}
+
+func TestSiteSearch(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/blog/a.md", "---\ntitle: Static Sites\n---\nHugo builds static sites fast.",
+		"content/blog/b.md", "---\ntitle: Templates\n---\nHugo templates use Go. Static content is fine.",
+		"content/docs/c.md", "---\ntitle: Images\n---\nImage processing in Hugo.",
+		"content/docs/d.md", "---\ntitle: Deploy\n---\nDeploy to a CDN.",
+		"layouts/index.html", `
+All: {{ range .Site.Search "hugo STATIC" }}{{ .Title }}|{{ end }}
+Any: {{ range .Site.Search "static deploy" (dict "match" "any") }}{{ .Title }}|{{ end }}
+Section: {{ range .Site.Search "hugo" (dict "section" "docs") }}{{ .Title }}|{{ end }}
+Limit: {{ range .Site.Search "hugo" (dict "limit" 1) }}{{ .Title }}|{{ end }}
+None: {{ len (.Site.Search "kubernetes") }}
+`,
+		"layouts/_default/single.html", `{{ .Title }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html",
+		"All: Static Sites|Templates|\n",
+		"Any: Deploy|Static Sites|Templates|\n",
+		"Section: Images|\n",
+		"Limit: Images|\n",
+		"None: 0")
+
+	s := h.Sites[0]
+	_, err := s.Info.Search("hugo", map[string]interface{}{"match": "some"})
+	require.Error(t, err)
+	_, err = s.Info.Search("hugo", map[string]interface{}{"foo": 1})
+	require.Error(t, err)
+}
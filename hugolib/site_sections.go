@@ -268,7 +268,9 @@ func (s *Site) assembleSections() Pages {
 
 		// Regular page
 		p.parent = currentSection
-		children = append(children, p)
+		if !p.Unlisted {
+			children = append(children, p)
+		}
 		return false
 	})
 
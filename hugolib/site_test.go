@@ -14,6 +14,7 @@
 package hugolib
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -946,3 +947,13 @@ func TestRefLinking(t *testing.T) {
 
 	// TODO: and then the failure cases.
 }
+
+func TestWithResourceErrorHint(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New(`template: _default/single.html:1:52: executing "_default/single.html" at <.Resize>: nil pointer evaluating resource.Resource.Resize`)
+	require.Contains(t, withResourceErrorHint(err).Error(), "the resource was not found")
+
+	err = errors.New("some other error")
+	require.Equal(t, err, withResourceErrorHint(err))
+}
@@ -20,6 +20,7 @@ import (
 	"html/template"
 
 	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -87,3 +88,27 @@ func TestPageCount(t *testing.T) {
 		}
 	}
 }
+
+func TestOfflineURLs(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/docs/"
+offlineURLs = true
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/_index.md", "---\ntitle: Home\n---\n",
+		"content/guide/_index.md", "---\ntitle: Guide\n---\n",
+		"content/guide/install.md", "---\ntitle: Install\n---\n",
+		"layouts/_default/list.html", `{{ range .Pages }}<a href="{{ .RelPermalink }}">{{ .Title }}</a>{{ end }}`,
+		"layouts/_default/single.html", `<a href="{{ .Site.Home.Permalink }}">Home</a><img src="{{ "logo.png" | relURL }}">`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", `<a href="./guide/install/index.html">Install</a>`)
+	th.assertFileContent("public/guide/index.html", `<a href="../guide/install/index.html">Install</a>`)
+	th.assertFileContent("public/guide/install/index.html", `<a href="../../index.html">Home</a><img src="../../logo.png">`)
+}
@@ -14,6 +14,9 @@
 package hugolib
 
 import (
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
 	"github.com/spf13/cast"
 	jww "github.com/spf13/jwalterweatherman"
 )
@@ -23,9 +26,49 @@ type Sitemap struct {
 	ChangeFreq string
 	Priority   float64
 	Filename   string
+
+	// The sitemap extensions to add for the page, any of "image", "video" and
+	// "news". If not set in the page's front matter, the extensions set for
+	// its closest section, and then in the site config, are used.
+	Extensions []string
+
+	// The publication name in the news extension. Defaults to the site
+	// title.
+	NewsName string
+
+	// The extensions resolved for the page when rendering the sitemap, with
+	// Extensions left as set in the front matter.
+	extensions []string
 }
 
-func parseSitemap(input map[string]interface{}) Sitemap {
+// HasExtension reports whether the given sitemap extension, e.g. "image", is
+// enabled.
+func (s Sitemap) HasExtension(name string) bool {
+	extensions := s.extensions
+	if extensions == nil {
+		extensions = s.Extensions
+	}
+	for _, ext := range extensions {
+		if strings.EqualFold(ext, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SitemapVideo is a video in the sitemap video extension.
+type SitemapVideo struct {
+	Title        string
+	Description  string
+	ThumbnailLoc string
+	ContentLoc   string
+	PlayerLoc    string
+
+	// The duration in seconds.
+	Duration int
+}
+
+func parseSitemap(input map[string]interface{}, logger *jww.Notepad) Sitemap {
 	sitemap := Sitemap{Priority: -1, Filename: "sitemap.xml"}
 
 	for key, value := range input {
@@ -36,10 +79,109 @@ func parseSitemap(input map[string]interface{}) Sitemap {
 			sitemap.Priority = cast.ToFloat64(value)
 		case "filename":
 			sitemap.Filename = cast.ToString(value)
+		case "extensions":
+			sitemap.Extensions = helpers.SliceToLower(cast.ToStringSlice(value))
+		case "newsname":
+			sitemap.NewsName = cast.ToString(value)
 		default:
-			jww.WARN.Printf("Unknown Sitemap field: %s\n", key)
+			helpers.Warnidf(logger, "warning-sitemap-field", "Unknown Sitemap field: %s", key)
 		}
 	}
 
 	return sitemap
 }
+
+// sitemapExtensions returns the sitemap extensions set for the page or its
+// closest section, or defaults if not set.
+func (p *Page) sitemapExtensions(defaults []string) []string {
+	for pp := p; pp != nil; pp = pp.parent {
+		if pp.Sitemap.Extensions != nil {
+			return pp.Sitemap.Extensions
+		}
+	}
+	return defaults
+}
+
+// SitemapImages returns the absolute URLs of the page's images for the sitemap
+// image extension, i.e. its image resources and the images set in the
+// "images" param.
+func (p *Page) SitemapImages() []string {
+	var images []string
+
+	for _, r := range p.Resources.ByType("image") {
+		images = append(images, r.Permalink())
+	}
+
+	for _, image := range cast.ToStringSlice(p.Params["images"]) {
+		images = append(images, p.s.PathSpec.AbsURL(image, false))
+	}
+
+	return helpers.UniqueStrings(images)
+}
+
+// SitemapVideos returns the page's videos for the sitemap video extension,
+// i.e. the videos set in the "videos" param, e.g.
+//
+//   [[videos]]
+//   title = "Intro"
+//   description = "An introduction"
+//   thumbnail = "/images/intro.jpg"
+//   content = "/videos/intro.mp4"
+//   duration = 120
+//
+// and its video resources, with the page's title and description and its
+// first image as thumbnail.
+func (p *Page) SitemapVideos() []SitemapVideo {
+	var videos []SitemapVideo
+
+	absURL := func(v interface{}) string {
+		if s := cast.ToString(v); s != "" {
+			return p.s.PathSpec.AbsURL(s, false)
+		}
+		return ""
+	}
+
+	if v, found := p.Params["videos"]; found {
+		for _, item := range cast.ToSlice(v) {
+			m, err := cast.ToStringMapE(item)
+			if err != nil {
+				continue
+			}
+			helpers.ToLowerMap(m)
+
+			videos = append(videos, SitemapVideo{
+				Title:        cast.ToString(m["title"]),
+				Description:  cast.ToString(m["description"]),
+				ThumbnailLoc: absURL(m["thumbnail"]),
+				ContentLoc:   absURL(m["content"]),
+				PlayerLoc:    absURL(m["player"]),
+				Duration:     cast.ToInt(m["duration"]),
+			})
+		}
+	}
+
+	var thumbnail string
+	if images := p.SitemapImages(); len(images) > 0 {
+		thumbnail = images[0]
+	}
+
+	description := p.Description
+	if description == "" {
+		description = helpers.StripHTML(string(p.Summary))
+	}
+
+	for _, r := range p.Resources.ByType("video") {
+		if thumbnail == "" {
+			// Required by the video extension.
+			continue
+		}
+		videos = append(videos, SitemapVideo{
+			Title:        p.Title,
+			Description:  description,
+			ThumbnailLoc: thumbnail,
+			ContentLoc:   r.Permalink(),
+		})
+	}
+
+	return videos
+}
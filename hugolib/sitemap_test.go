@@ -14,10 +14,12 @@
 package hugolib
 
 import (
+	"strings"
 	"testing"
 
 	"reflect"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gohugoio/hugo/deps"
@@ -93,10 +95,82 @@ func TestParseSitemap(t *testing.T) {
 		"filename":   "doo.xml",
 		"unknown":    "ignore",
 	}
-	result := parseSitemap(input)
+	result := parseSitemap(input, newErrorLogger())
 
 	if !reflect.DeepEqual(expected, result) {
 		t.Errorf("Got \n%v expected \n%v", result, expected)
 	}
 
 }
+
+func TestSitemapExtensions(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+title = "The Daily"
+
+[sitemap]
+extensions = ["image"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/news/_index.md", "---\ntitle: News\nsitemap:\n  extensions: [image, news]\n---\n",
+		"content/news/a/index.md", "---\ntitle: Story A\npublishDate: 2018-01-25T10:00:00Z\n---\n",
+		"content/news/a/photo.jpg", "image",
+		"content/posts/b.md", `+++
+title = "Post B"
+images = ["/images/b.png"]
+[sitemap]
+extensions = ["video"]
+[[videos]]
+title = "Clip"
+description = "A clip"
+thumbnail = "/images/clip.jpg"
+content = "/videos/clip.mp4"
+duration = 60
++++
+`,
+		"content/posts/c.md", "---\ntitle: Post C\nimages: [\"/images/c.png\"]\n---\n",
+		"layouts/_default/single.html", "{{ .Title }}",
+		"layouts/_default/list.html", "{{ .Title }}",
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/sitemap.xml",
+		`xmlns:news="http://www.google.com/schemas/sitemap-news/0.9"`,
+		"<loc>http://example.com/news/a/</loc>",
+		"<image:loc>http://example.com/news/a/photo.jpg</image:loc>",
+		"<news:name>The Daily</news:name>",
+		"<news:language>en</news:language>",
+		"<news:publication_date>2018-01-25T10:00:00+00:00</news:publication_date>",
+		"<news:title>Story A</news:title>",
+		"<video:thumbnail_loc>http://example.com/images/clip.jpg</video:thumbnail_loc>",
+		"<video:title>Clip</video:title>",
+		"<video:content_loc>http://example.com/videos/clip.mp4</video:content_loc>",
+		"<video:duration>60</video:duration>",
+		"<image:loc>http://example.com/images/c.png</image:loc>",
+	)
+
+	content := readDestination(t, th.Fs, "public/sitemap.xml")
+	require.NotContains(t, content, "images/b.png")
+	require.Equal(t, 1, strings.Count(content, "<news:news>"))
+
+	// The front matter and the config are left as set.
+	s := h.Sites[0]
+	require.Nil(t, s.getPage(KindPage, "news/a/index.md").Sitemap.Extensions)
+	require.Equal(t, []string{"video"}, s.getPage(KindPage, "posts/b.md").Sitemap.Extensions)
+	require.Nil(t, s.getPage(KindPage, "posts/c.md").Sitemap.Extensions)
+}
+
+func TestSitemapHasExtension(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	sitemap := parseSitemap(map[string]interface{}{"extensions": []interface{}{"Image", "news"}}, newErrorLogger())
+	assert.True(sitemap.HasExtension("image"))
+	assert.True(sitemap.HasExtension("news"))
+	assert.False(sitemap.HasExtension("video"))
+	assert.False(Sitemap{}.HasExtension("image"))
+}
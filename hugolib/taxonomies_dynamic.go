@@ -0,0 +1,131 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
+)
+
+// taxonomyNamespace returns the front matter key set with taxonomyNamespace,
+// below which every key is a taxonomy, e.g. with
+//
+//   taxonomyNamespace = "classification"
+//
+// the front matter
+//
+//   [classification]
+//   audience = ["developers"]
+//   region = "emea"
+//
+// adds the page to the audience and region taxonomies without declaring them
+// in the taxonomies config. The key is used as both the singular and the
+// plural name of the taxonomy.
+func (s *Site) taxonomyNamespace() string {
+	return strings.ToLower(s.Language.GetString("taxonomyNamespace"))
+}
+
+// collectTaxonomies returns the configured taxonomies, singular to plural,
+// and the taxonomies found below the taxonomyNamespace in the front matter
+// of the given pages. A configured taxonomy wins over a found one with the
+// same plural name. The pages not built, e.g. drafts, and the unlisted pages
+// are not listed in the taxonomies, so they add none.
+func (s *Site) collectTaxonomies(pages Pages) map[string]string {
+	taxonomies := make(map[string]string)
+	plurals := make(map[string]bool)
+
+	for singular, plural := range s.Language.GetStringMapString("taxonomies") {
+		taxonomies[singular] = plural
+		plurals[plural] = true
+	}
+
+	ns := s.taxonomyNamespace()
+	if ns == "" {
+		return taxonomies
+	}
+
+	for _, p := range pages {
+		if !p.shouldBuild() || p.Unlisted {
+			continue
+		}
+		for key := range p.taxonomyNamespaceParams(ns) {
+			if plurals[key] {
+				continue
+			}
+			if _, found := taxonomies[key]; found {
+				continue
+			}
+			taxonomies[key] = key
+			plurals[key] = true
+		}
+	}
+
+	return taxonomies
+}
+
+// taxonomyNamespaceParams returns the params below ns in the page's front
+// matter.
+func (p *Page) taxonomyNamespaceParams(ns string) map[string]interface{} {
+	v, found := p.Params[ns]
+	if !found {
+		return nil
+	}
+
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return nil
+	}
+
+	return m
+}
+
+// getTaxonomyParam returns the page's terms in the given taxonomy, either a
+// string or a []string, set in the front matter or below the taxonomy
+// namespace.
+func (p *Page) getTaxonomyParam(plural string, stringToLower bool) interface{} {
+	if v := p.getParam(plural, stringToLower); v != nil {
+		return v
+	}
+
+	ns := p.s.taxonomyNamespace()
+	if ns == "" {
+		return nil
+	}
+
+	var v interface{}
+	for key, vv := range p.taxonomyNamespaceParams(ns) {
+		if strings.EqualFold(key, plural) {
+			v = vv
+			break
+		}
+	}
+
+	switch vv := v.(type) {
+	case string:
+		if stringToLower {
+			return strings.ToLower(vv)
+		}
+		return vv
+	case []string, []interface{}:
+		terms := cast.ToStringSlice(vv)
+		if stringToLower {
+			return helpers.SliceToLower(terms)
+		}
+		return terms
+	}
+
+	return v
+}
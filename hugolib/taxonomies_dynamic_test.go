@@ -0,0 +1,70 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomyNamespace(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+taxonomyNamespace = "classification"
+
+[taxonomies]
+tag = "tags"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/p1.md", `+++
+title = "P1"
+tags = ["a"]
+[classification]
+audience = ["Developers", "Editors"]
+region = "EMEA"
++++
+`,
+		"content/p2.md", `---
+title: P2
+classification:
+  audience: developers
+  tags: ["b"]
+---
+`,
+		"content/draft.md", "---\ntitle: Draft\ndraft: true\nclassification:\n  level: beginner\n---\n",
+		"content/audience/_index.md", "---\ntitle: The Audiences\n---\n",
+		"layouts/_default/list.html", `{{ .Kind }}|{{ .Title }}|{{ range .Pages }}{{ .Title }},{{ end }}`,
+		"layouts/_default/terms.html", `{{ .Kind }}|{{ .Title }}|{{ .Data.Singular }}|{{ range .Data.Terms.Alphabetical }}{{ .Name }}:{{ .Count }},{{ end }}`,
+		"layouts/_default/single.html", `{{ .Title }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	s := h.Sites[0]
+	require.Equal(t, map[string]string{"tag": "tags", "audience": "audience", "region": "region"}, s.taxonomiesConfig)
+	require.Len(t, s.Taxonomies["tags"], 2)
+
+	th.assertFileContent("public/audience/index.html", "taxonomyTerm|The Audiences|audience|developers:2,editors:1,")
+	th.assertFileContent("public/audience/developers/index.html", "taxonomy|Developers|P1,P2,")
+	th.assertFileContent("public/region/emea/index.html", "taxonomy|Emea|P1,")
+	th.assertFileContent("public/tags/b/index.html", "taxonomy|B|P2,")
+
+	// The draft adds no taxonomy.
+	th.assertFileNotExist("public/level/index.html")
+}
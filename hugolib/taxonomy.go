@@ -16,6 +16,9 @@ package hugolib
 import (
 	"fmt"
 	"sort"
+
+	"github.com/gohugoio/hugo/helpers"
+	"golang.org/x/text/collate"
 )
 
 // The TaxonomyList is a list of all taxonomies and their values
@@ -80,10 +83,23 @@ func (i Taxonomy) TaxonomyArray() OrderedTaxonomy {
 	return ies
 }
 
-// Alphabetical returns an ordered taxonomy sorted by key name.
+// newCollator returns a new collator for the language of the taxonomy's
+// pages.
+func (i Taxonomy) newCollator() *collate.Collator {
+	for _, wp := range i {
+		if len(wp) > 0 && wp[0].Page != nil && wp[0].Page.Language() != nil {
+			return wp[0].Page.Language().NewCollator()
+		}
+	}
+	return nil
+}
+
+// Alphabetical returns an ordered taxonomy sorted by key name, using the
+// collation rules of the pages' language.
 func (i Taxonomy) Alphabetical() OrderedTaxonomy {
+	c := i.newCollator()
 	name := func(i1, i2 *OrderedTaxonomyEntry) bool {
-		return i1.Name < i2.Name
+		return helpers.CompareStrings(c, i1.Name, i2.Name) < 0
 	}
 
 	ia := i.TaxonomyArray()
@@ -94,12 +110,13 @@ func (i Taxonomy) Alphabetical() OrderedTaxonomy {
 // ByCount returns an ordered taxonomy sorted by # of pages per key.
 // If taxonomies have the same # of pages, sort them alphabetical
 func (i Taxonomy) ByCount() OrderedTaxonomy {
+	c := i.newCollator()
 	count := func(i1, i2 *OrderedTaxonomyEntry) bool {
 		li1 := len(i1.WeightedPages)
 		li2 := len(i2.WeightedPages)
 
 		if li1 == li2 {
-			return i1.Name < i2.Name
+			return helpers.CompareStrings(c, i1.Name, i2.Name) < 0
 		}
 		return li1 > li2
 	}
@@ -0,0 +1,217 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// taxonomyNormalization configures how the terms in the front matter are
+// normalized when building the taxonomies, e.g.:
+//
+//   [taxonomyNormalization]
+//   caseInsensitive = true
+//   foldAccents = true
+//   [taxonomyNormalization.synonyms]
+//   golang = "go"
+//
+// Terms considered equal are merged into the first one found, see
+// PrintTaxonomyMergesReport.
+type taxonomyNormalization struct {
+	// Merge terms only differing in case, e.g. "Go" and "go". This is only
+	// relevant with preserveTaxonomyNames, the terms are lower cased
+	// otherwise.
+	CaseInsensitive bool
+
+	// Merge terms only differing in accents, e.g. "café" and "cafe".
+	FoldAccents bool
+
+	// Terms merged into another term, matched case insensitively.
+	Synonyms map[string]string
+}
+
+func decodeTaxonomyNormalization(v interface{}) (taxonomyNormalization, error) {
+	var n taxonomyNormalization
+	if v == nil {
+		return n, nil
+	}
+
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return n, fmt.Errorf("failed to decode taxonomyNormalization config: %s", err)
+	}
+
+	if err := mapstructure.WeakDecode(m, &n); err != nil {
+		return n, fmt.Errorf("failed to decode taxonomyNormalization config: %s", err)
+	}
+
+	synonyms := make(map[string]string)
+	for from, to := range n.Synonyms {
+		if to == "" {
+			return n, fmt.Errorf("taxonomyNormalization: empty synonym for %q", from)
+		}
+		synonyms[n.synonymKey(from)] = to
+	}
+	n.Synonyms = synonyms
+
+	return n, nil
+}
+
+func (n taxonomyNormalization) enabled() bool {
+	return n.CaseInsensitive || n.FoldAccents || len(n.Synonyms) > 0
+}
+
+// key returns the key of terms considered equal.
+func (n taxonomyNormalization) key(term string) string {
+	if n.FoldAccents {
+		term = helpers.RemoveAccents(term)
+	}
+	if n.CaseInsensitive {
+		term = strings.ToLower(term)
+	}
+	return term
+}
+
+func (n taxonomyNormalization) synonymKey(term string) string {
+	if n.FoldAccents {
+		term = helpers.RemoveAccents(term)
+	}
+	return strings.ToLower(term)
+}
+
+// TaxonomyTermMerge is a term in the front matter merged into another term
+// by the taxonomyNormalization config.
+type TaxonomyTermMerge struct {
+	// The plural name of the taxonomy, e.g. "tags".
+	Taxonomy string
+
+	// The term as written in the front matter.
+	Term string
+
+	// The term it was merged into.
+	Into string
+
+	// The pages with the term in the front matter.
+	Pages Pages
+}
+
+// taxonomyTermNormalizer normalizes the terms of one taxonomy in a build.
+type taxonomyTermNormalizer struct {
+	cfg       taxonomyNormalization
+	plural    string
+	lower     bool
+	canonical map[string]string
+	merges    map[string]*TaxonomyTermMerge
+	order     []*TaxonomyTermMerge
+}
+
+func newTaxonomyTermNormalizer(cfg taxonomyNormalization, plural string, lower bool) *taxonomyTermNormalizer {
+	return &taxonomyTermNormalizer{
+		cfg:       cfg,
+		plural:    plural,
+		lower:     lower,
+		canonical: make(map[string]string),
+		merges:    make(map[string]*TaxonomyTermMerge),
+	}
+}
+
+// normalize returns the term the term found in p is merged into, the term
+// itself if none.
+func (n *taxonomyTermNormalizer) normalize(term string, p *Page) string {
+	normalized := term
+
+	if to, found := n.cfg.Synonyms[n.cfg.synonymKey(term)]; found {
+		normalized = to
+		if n.lower {
+			normalized = strings.ToLower(normalized)
+		}
+	}
+
+	key := n.cfg.key(normalized)
+	if c, found := n.canonical[key]; found {
+		normalized = c
+	} else {
+		n.canonical[key] = normalized
+	}
+
+	if normalized != term {
+		m, found := n.merges[term]
+		if !found {
+			m = &TaxonomyTermMerge{Taxonomy: n.plural, Term: term, Into: normalized}
+			n.merges[term] = m
+			n.order = append(n.order, m)
+		}
+		m.Pages = append(m.Pages, p)
+	}
+
+	return normalized
+}
+
+// normalizeTerms normalizes the terms of the taxonomy found in p, removing
+// the duplicates. The page's front matter is left as written.
+func (n *taxonomyTermNormalizer) normalizeTerms(terms []string, p *Page) []string {
+	var (
+		normalized = make([]string, 0, len(terms))
+		seen       = make(map[string]bool)
+	)
+
+	for _, term := range terms {
+		t := n.normalize(term, p)
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+
+	return normalized
+}
+
+// TaxonomyMerges returns the terms merged into other terms by the
+// taxonomyNormalization config in the last build, for all sites.
+func (h *HugoSites) TaxonomyMerges() []*TaxonomyTermMerge {
+	var merges []*TaxonomyTermMerge
+	for _, s := range h.Sites {
+		merges = append(merges, s.taxonomyMerges...)
+	}
+	return merges
+}
+
+// PrintTaxonomyMergesReport writes a report of the taxonomy terms merged into
+// other terms to w, so the front matter can be cleaned up, and returns the
+// number of merged terms.
+func (h *HugoSites) PrintTaxonomyMergesReport(w io.Writer) int {
+	merges := h.TaxonomyMerges()
+
+	for _, m := range merges {
+		var lang string
+		if h.multilingual != nil && len(h.Sites) > 1 {
+			lang = fmt.Sprintf(" (%s)", m.Pages[0].Lang())
+		}
+
+		fmt.Fprintf(w, "Merged %s %q into %q%s:\n", m.Taxonomy, m.Term, m.Into, lang)
+
+		for _, p := range m.Pages {
+			fmt.Fprintf(w, "  %s\n", p.pathOrTitle())
+		}
+	}
+
+	return len(merges)
+}
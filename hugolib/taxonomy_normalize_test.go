@@ -0,0 +1,87 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTaxonomyNormalization(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	n, err := decodeTaxonomyNormalization(map[string]interface{}{
+		"foldAccents": true,
+		"synonyms":    map[string]interface{}{"GoLang": "go", "Café": "coffee"},
+	})
+	assert.NoError(err)
+	assert.True(n.enabled())
+	assert.Equal(map[string]string{"golang": "go", "cafe": "coffee"}, n.Synonyms)
+	assert.Equal("Cafe", n.key("Café"))
+
+	n, err = decodeTaxonomyNormalization(nil)
+	assert.NoError(err)
+	assert.False(n.enabled())
+
+	_, err = decodeTaxonomyNormalization(map[string]interface{}{"synonyms": map[string]interface{}{"a": ""}})
+	assert.Error(err)
+}
+
+func TestTaxonomyNormalization(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+preserveTaxonomyNames = true
+
+[taxonomies]
+tag = "tags"
+category = "categories"
+
+[taxonomyNormalization]
+caseInsensitive = true
+foldAccents = true
+[taxonomyNormalization.synonyms]
+golang = "Go"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/a.md", "---\ntitle: A\nweight: 1\ntags: [Go, Café]\ncategories: Dev\n---\n",
+		"content/b.md", "---\ntitle: B\nweight: 2\ntags: [golang, go, cafe]\ncategories: dev\n---\n",
+		"content/c.md", "---\ntitle: C\nweight: 3\ntags: [Hugo]\n---\n",
+		"layouts/index.html", `{{ range $k, $v := .Site.Taxonomies.tags }}{{ $k }}:{{ len $v }}|{{ end }}`,
+		"layouts/_default/single.html", `{{ range .Params.tags }}{{ . }}|{{ end }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "Café:2|Go:2|Hugo:1|")
+	th.assertFileContent("public/b/index.html", "golang|go|cafe|")
+
+	var b bytes.Buffer
+	require.Equal(t, 4, h.PrintTaxonomyMergesReport(&b))
+	require.Equal(t, `Merged categories "dev" into "Dev":
+  b.md
+Merged tags "golang" into "Go":
+  b.md
+Merged tags "go" into "Go":
+  b.md
+Merged tags "cafe" into "Café":
+  b.md
+`, b.String())
+}
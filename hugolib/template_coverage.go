@@ -0,0 +1,155 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/gohugoio/hugo/tpl"
+)
+
+// UnusedTemplate is a template file that was never executed in the last
+// build, see the templateCoverage config.
+type UnusedTemplate struct {
+	// The template name, e.g. "posts/single.html". Empty for base templates.
+	Name string
+
+	// The template file.
+	Filename string
+
+	// The file of the template with the same name in the project, if this is
+	// a theme template, i.e. the template that was used instead.
+	OverriddenBy string
+}
+
+// UnusedTemplates returns the templates loaded from file that were never
+// executed in the last build, sorted by filename. A base template is unused
+// if none of the templates extending it were executed. This requires
+// templateCoverage to be set.
+func (h *HugoSites) UnusedTemplates() []UnusedTemplate {
+	if h.Coverage == nil {
+		return nil
+	}
+
+	provider, ok := h.Tmpl.(tpl.TemplateInfoProvider)
+	if !ok {
+		return nil
+	}
+
+	infos := provider.TemplateInfos()
+
+	files := make(map[string]string)
+	for _, info := range infos {
+		files[info.Name] = info.Filename
+	}
+
+	// The templates included with the template action are executed by the
+	// including template, so they are not recorded on their own.
+	included := make(map[string]bool)
+	for _, info := range infos {
+		if h.Coverage.Executed(info.Name) {
+			for name := range templateActionNames(h.Tmpl, info.Name) {
+				included[name] = true
+			}
+		}
+	}
+
+	var (
+		unused    []UnusedTemplate
+		baseUsed  = make(map[string]bool)
+		baseFiles []string
+	)
+
+	for _, info := range infos {
+		executed := h.Coverage.Executed(info.Name) || included[info.Name]
+
+		for _, base := range info.BaseFilenames {
+			if _, found := baseUsed[base]; !found {
+				baseFiles = append(baseFiles, base)
+			}
+			baseUsed[base] = baseUsed[base] || executed
+		}
+
+		if executed {
+			continue
+		}
+
+		t := UnusedTemplate{Name: info.Name, Filename: info.Filename}
+		if strings.HasPrefix(info.Name, "theme/") {
+			t.OverriddenBy = files[strings.TrimPrefix(info.Name, "theme/")]
+		}
+		unused = append(unused, t)
+	}
+
+	for _, base := range baseFiles {
+		if !baseUsed[base] {
+			unused = append(unused, UnusedTemplate{Filename: base})
+		}
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].Filename < unused[j].Filename
+	})
+
+	return unused
+}
+
+// templateActionNames returns the names of the templates included with the
+// template action by the named template, directly or by the templates it
+// includes.
+func templateActionNames(finder tpl.TemplateFinder, name string) map[string]bool {
+	templ := finder.Lookup(name)
+	if templ == nil {
+		return nil
+	}
+
+	w := newTemplateWalker(templ, finder)
+	// The partials are recorded when executed.
+	w.command = func(*parse.CommandNode) bool { return true }
+	w.walkTemplate(name, templateTree(templ, ""))
+
+	delete(w.seen, name)
+
+	return w.seen
+}
+
+// reportTemplateCoverage logs the templates never executed in the build.
+func (h *HugoSites) reportTemplateCoverage() {
+	unused := h.UnusedTemplates()
+	if len(unused) == 0 {
+		h.Log.FEEDBACK.Printf("\nTemplate Coverage: all templates were executed\n\n")
+		return
+	}
+
+	workingDir := h.Cfg.GetString("workingDir")
+	rel := func(filename string) string {
+		if r, err := filepath.Rel(workingDir, filename); err == nil && !strings.HasPrefix(r, "..") {
+			return filepath.ToSlash(r)
+		}
+		return filename
+	}
+
+	h.Log.FEEDBACK.Printf("\nTemplate Coverage: %d templates were never executed:\n\n", len(unused))
+	for _, t := range unused {
+		if t.OverriddenBy != "" {
+			h.Log.FEEDBACK.Printf("  %s (overridden by %s)\n", rel(t.Filename), rel(t.OverriddenBy))
+		} else {
+			h.Log.FEEDBACK.Printf("  %s\n", rel(t.Filename))
+		}
+	}
+	h.Log.FEEDBACK.Println()
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnusedTemplates(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+theme = "mytheme"
+templateCoverage = true
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+
+[outputs]
+home = ["HTML", "JSON"]
+`
+
+	_, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/p1.md", "---\ntitle: P1\n---\n{{< used >}}\n",
+		"layouts/_default/baseof.html", `Base: {{ block "main" . }}{{ end }}`,
+		"layouts/_default/single.html", `{{ define "main" }}Single: {{ partial "used.html" . }}{{ .Content }}{{ end }}`,
+		"layouts/_default/list.html", `List`,
+		"layouts/index.json", `{"title": {{ .Title | jsonify }}}`,
+		// A typo in the type, so this is never used.
+		"layouts/post/single.html", `Never`,
+		"layouts/other/baseof.html", `Other base: {{ block "main" . }}{{ end }}`,
+		"layouts/other/single.html", `{{ define "main" }}Other{{ end }}`,
+		"layouts/partials/used.html", `Used partial {{ template "partials/included.html" . }}`,
+		"layouts/partials/included.html", `Included {{ template "partials/nested.html" . }}`,
+		"layouts/partials/nested.html", `Nested`,
+		"layouts/partials/unused.html", `Unused {{ template "partials/included-unused.html" . }}`,
+		"layouts/partials/included-unused.html", `Included by an unused partial`,
+		"layouts/shortcodes/used.html", `Used shortcode`,
+		"layouts/shortcodes/unused.html", `Unused shortcode`,
+		"themes/mytheme/layouts/_default/single.html", `Theme single`,
+		"themes/mytheme/layouts/partials/theme.html", `Theme partial`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	var got []string
+	for _, t := range h.UnusedTemplates() {
+		s := filepath.ToSlash(t.Filename)
+		s = s[strings.Index(s, "layouts/"):]
+		if t.OverriddenBy != "" {
+			s += " -> " + filepath.ToSlash(t.OverriddenBy)
+		}
+		got = append(got, t.Name+"|"+s)
+	}
+
+	assert.Equal([]string{
+		"|layouts/other/baseof.html",
+		"other/single.html|layouts/other/single.html",
+		"partials/included-unused.html|layouts/partials/included-unused.html",
+		"partials/unused.html|layouts/partials/unused.html",
+		"post/single.html|layouts/post/single.html",
+		"shortcodes/unused.html|layouts/shortcodes/unused.html",
+		"theme/_default/single.html|layouts/_default/single.html -> layouts/_default/single.html",
+		"theme/partials/theme.html|layouts/partials/theme.html",
+	}, got)
+}
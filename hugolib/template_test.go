@@ -14,12 +14,21 @@
 package hugolib
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gohugoio/hugo/deps"
 	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/tpl"
+	"github.com/gohugoio/hugo/tpl/tplimpl"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/stretchr/testify/require"
 
 	"github.com/spf13/viper"
 )
@@ -213,3 +222,100 @@ Some content
 
 	}
 }
+
+func TestTemplateBaseofLevels(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/docs/d1.md", "---\ntitle: D1\n---\n",
+		"content/blog/b1.md", "---\ntitle: B1\n---\n",
+		"content/news/n1.md", "---\ntitle: N1\n---\n",
+		"layouts/_default/baseof.html", `Site Base|{{ block "main" . }}default main{{ end }}|{{ block "footer" . }}site footer{{ end }}`,
+		"layouts/_default/single.html", `{{ define "main" }}Single: {{ .Title }}{{ end }}`,
+		"layouts/docs/baseof.html", `{{ define "main" }}Docs Base|{{ block "content" . }}docs content{{ end }}{{ end }}`,
+		"layouts/docs/single.html", `{{ define "content" }}Doc: {{ .Title }}{{ end }}`,
+		// A section baseof with content outside its define blocks is used on
+		// its own, as in the sites built before base templates could extend
+		// other base templates.
+		"layouts/news/baseof.html", `{{ define "footer" }}news footer{{ end }}News Base|{{ block "main" . }}{{ end }}|{{ template "footer" . }}`,
+		"layouts/news/single.html", `{{ define "main" }}News: {{ .Title }}{{ end }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/docs/d1/index.html", "Site Base|Docs Base|Doc: D1|site footer")
+	th.assertFileContent("public/blog/b1/index.html", "Site Base|Single: B1|site footer")
+	th.assertFileContent("public/news/n1/index.html", "News Base|News: N1|news footer")
+
+	var docs tpl.TemplateInfo
+	for _, info := range h.Sites[0].Tmpl.(tpl.TemplateInfoProvider).TemplateInfos() {
+		if info.Name == "docs/single.html" {
+			docs = info
+		}
+	}
+
+	require.Len(t, docs.BaseFilenames, 2)
+	require.True(t, strings.HasSuffix(docs.BaseFilenames[0], filepath.FromSlash("layouts/docs/baseof.html")))
+	require.True(t, strings.HasSuffix(docs.BaseFilenames[1], filepath.FromSlash("layouts/_default/baseof.html")))
+}
+
+func TestEmbeddedTemplatePinWarning(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	rss, _ := tplimpl.GetEmbeddedTemplate("_default/rss.xml")
+	figure, _ := tplimpl.GetEmbeddedTemplate("shortcodes/figure.html")
+
+	cfg, fs := newTestCfg()
+	cfg.Set("disableKinds", []string{"taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404"})
+
+	writeSource(t, fs, "content/p.md", "---\ntitle: P\n---\n")
+	writeSource(t, fs, "layouts/_default/single.html", "Single")
+	writeSource(t, fs, "layouts/_default/list.html", "List")
+	writeSource(t, fs, "layouts/_default/rss.xml", rss.Pinned())
+	writeSource(t, fs, "layouts/shortcodes/figure.html", strings.Replace(figure.Pinned(), figure.Hash(), "0123456789ab", 1))
+
+	var warnings bytes.Buffer
+	logger := jww.NewNotepad(jww.LevelWarn, jww.LevelError, &warnings, ioutil.Discard, "", log.Ldate|log.Ltime)
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg, Logger: logger}, BuildCfg{})
+	th := testHelper{s.Cfg, s.Fs, t}
+
+	th.assertFileContent("public/index.xml", "<rss version=\"2.0\"")
+	assert.NotContains(readDestination(t, th.Fs, "public/index.xml"), "hugo-embedded")
+
+	assert.Contains(warnings.String(), `older version of the embedded template "_internal/shortcodes/figure.html"`)
+	assert.NotContains(warnings.String(), "rss.xml")
+}
+
+func TestSchemaTemplateFuncs(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "RSS", "sitemap", "robotsTXT", "404"]
+[schema.organization]
+name = "Acme"
+url = "http://example.com/"
+logo = "logo.png"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/blog/p.md", "---\ntitle: My Post\ndate: 2018-01-02\nauthor: Jane\nimages: [\"images/p.jpg\"]\n---\nContent.",
+		"layouts/_default/single.html", `{{ schema.Article . }}|{{ schema.Breadcrumb . }}|{{ schema.Organization }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/blog/p/index.html",
+		`<script type="application/ld+json">{"@context":"https://schema.org","@type":"Article","author":{"@type":"Person","name":"Jane"},"dateModified":"2018-01-02T00:00:00Z","datePublished":"2018-01-02T00:00:00Z","description":"Content.","headline":"My Post","image":["http://example.com/images/p.jpg"],"mainEntityOfPage":"http://example.com/blog/p/"`,
+		`"itemListElement":[{"@type":"ListItem","item":"http://example.com/","name":"`,
+		`{"@type":"ListItem","item":"http://example.com/blog/p/","name":"My Post","position":3}]`,
+		`"logo":"http://example.com/logo.png","name":"Acme"`,
+	)
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeZone(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+timeZone = "America/New_York"
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/local.md", "---\ntitle: Local\ndate: \"2018-01-02T20:00:00\"\npublishDate: \"2018-01-02\"\n---\n",
+		"content/utc.md", "---\ntitle: UTC\ndate: \"2018-01-02T20:00:00Z\"\n---\n",
+		"layouts/_default/single.html", `Date: {{ .Date.Format "2006-01-02T15:04:05Z07:00" }}|Publish: {{ .PublishDate.Format "2006-01-02T15:04:05Z07:00" }}|Zone: {{ .Date.Location }}|In: {{ (.Date.In .Site.TimeZone).Format "15:04 MST" }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/local/index.html", "Date: 2018-01-02T20:00:00-05:00|Publish: 2018-01-02T00:00:00-05:00|Zone: America/New_York|In: 20:00 EST")
+	th.assertFileContent("public/utc/index.html", "Date: 2018-01-02T20:00:00Z|", "Zone: UTC|In: 15:00 EST")
+
+	cfg, fs := newTestCfg()
+	cfg.Set("timeZone", "Nowhere/Nothing")
+
+	_, err := NewSiteForCfg(deps.DepsCfg{Cfg: cfg, Fs: fs})
+	require.Error(t, err)
+}
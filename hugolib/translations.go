@@ -13,11 +13,37 @@
 
 package hugolib
 
+import (
+	"github.com/gohugoio/hugo/helpers"
+)
+
 // Translations represent the other translations for a given page. The
 // string here is the language code, as affected by the `post.LANG.md`
 // filename.
 type Translations map[string]*Page
 
+// TranslationEntry is a site language with the translation of a page, if any.
+// See Page.AllTranslationEntries.
+type TranslationEntry struct {
+	Language *helpers.Language
+
+	// Page is the translation, nil if the page is not translated to the
+	// language.
+	Page *Page
+
+	// Home is the home page of the language, to link to when the page is
+	// not translated.
+	Home *Page
+
+	// IsCurrent is whether this is the language of the current page.
+	IsCurrent bool
+}
+
+// IsTranslated returns whether the page is translated to the language.
+func (e TranslationEntry) IsTranslated() bool {
+	return e.Page != nil
+}
+
 func pagesToTranslationsMap(pages []*Page) map[string]Translations {
 	out := make(map[string]Translations)
 
@@ -0,0 +1,71 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllTranslationEntriesAndGetPageInLanguage(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languages.de]
+weight = 3
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/blog/_index.md", "---\ntitle: Blog\n---\n",
+		"content/blog/_index.fr.md", "---\ntitle: Le Blog\n---\n",
+		"content/blog/a.md", "---\ntitle: A\n---\n",
+		"content/blog/a.de.md", "---\ntitle: A de\n---\n",
+		"layouts/_default/single.html", `{{ range .AllTranslationEntries }}{{ .Language.Lang }}:{{ if .IsTranslated }}{{ .Page.Title }}{{ else }}missing:{{ .Home.RelPermalink }}{{ end }}{{ if .IsCurrent }}*{{ end }}|{{ end }}`,
+		"layouts/_default/list.html", `{{ with .Site.GetPageInLanguage "fr" "section" "blog" }}{{ .Title }}{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/blog/a/index.html", "en:A*|fr:missing:/fr/|de:A de|")
+	th.assertFileContent("public/de/blog/a/index.html", "en:A|fr:missing:/fr/|de:A de*|")
+	th.assertFileContent("public/blog/index.html", "Le Blog")
+
+	en := h.Sites[0].Info
+	p, err := en.GetPageInLanguage("fr", KindSection, "blog")
+	assert.NoError(err)
+	assert.NotNil(p)
+	assert.Equal("fr", p.Lang())
+
+	p, err = en.GetPageInLanguage("de", KindSection, "blog")
+	assert.NoError(err)
+	assert.Equal("de", p.Lang())
+
+	p, err = en.GetPageInLanguage("de", KindSection, "news")
+	assert.NoError(err)
+	assert.Nil(p)
+
+	_, err = en.GetPageInLanguage("sv", KindSection, "blog")
+	assert.Error(err)
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlistedPages(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	config := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomyTerm", "robotsTXT", "404"]
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/posts/listed.md", "---\ntitle: Listed\ntags: [a]\n---\n",
+		"content/posts/unlisted.md", "---\ntitle: Unlisted\nunlisted: true\ntags: [a, b]\naliases: [/secret/]\n---\n",
+		"layouts/_default/single.html", `Single: {{ .Title }}|{{ .Unlisted }}|{{ .Parent.Title }}|{{ with .Site.GetPage "page" "posts/unlisted.md" }}{{ .Title }}{{ end }}`,
+		"layouts/_default/list.html", `List: {{ range .Pages }}{{ .Title }}|{{ end }}`,
+		"layouts/index.html", `Home: {{ range .Site.Pages }}{{ .Title }}|{{ end }}Regular: {{ range .Site.RegularPages }}{{ .Title }}|{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/posts/unlisted/index.html", "Single: Unlisted|true|Posts|Unlisted")
+	th.assertFileContent("public/posts/listed/index.html", "Single: Listed|false|Posts|Unlisted")
+	th.assertFileContent("public/secret/index.html", "/posts/unlisted/")
+	th.assertFileContent("public/posts/index.html", "List: Listed|")
+	th.assertFileContent("public/tags/a/index.html", "List: Listed|")
+	th.assertFileNotExist("public/tags/b/index.html")
+	th.assertFileContent("public/index.html", "Regular: Listed|")
+
+	for _, filename := range []string{"public/index.html", "public/sitemap.xml", "public/index.xml", "public/posts/index.xml"} {
+		content := readDestination(t, th.Fs, filename)
+		assert.NotContains(content, "Unlisted", filename)
+		assert.NotContains(content, "/posts/unlisted/", filename)
+	}
+}
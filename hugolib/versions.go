@@ -0,0 +1,300 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/output"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Version is a version of the documentation in a site, a content dir with
+// its own tree of pages, e.g. /v1/ and /v2/, all rendered with the same
+// templates. The versions are listed in the site config:
+//
+//   [[versions]]
+//   name = "v2"
+//   title = "2.x"
+//   latest = true
+//   [[versions]]
+//   name = "v1"
+//   dir = "archive/v1"
+//
+// A page in one version links to the same page in the other versions with
+// .Versions or .InVersion. The pages in the latest version are also
+// redirected to from /latest/, e.g. /latest/install/ to /v2/install/, so
+// links to the latest documentation keep working across releases.
+type Version struct {
+	// The name identifying the version, e.g. "v1".
+	Name string
+
+	// The title shown to the users, defaults to the name.
+	Title string
+
+	// The dir relative to the content dir with the version's pages,
+	// defaults to the name.
+	Dir string
+
+	// Whether this is the latest version, defaults to the first version. See
+	// SiteInfo.LatestVersion.
+	Latest bool
+
+	s *Site
+}
+
+func decodeVersionsConfig(v interface{}) ([]Version, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var versions []Version
+	if err := mapstructure.WeakDecode(v, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode versions config: %s", err)
+	}
+
+	var (
+		names  = make(map[string]bool)
+		latest bool
+	)
+
+	for i, version := range versions {
+		if version.Name == "" {
+			return nil, fmt.Errorf("versions[%d]: name must be set", i)
+		}
+		if names[version.Name] {
+			return nil, fmt.Errorf("versions[%d]: duplicate name %q", i, version.Name)
+		}
+		names[version.Name] = true
+
+		if version.Title == "" {
+			version.Title = version.Name
+		}
+		if version.Dir == "" {
+			version.Dir = version.Name
+		}
+		version.Dir = strings.Trim(path.Clean(filepath.ToSlash(version.Dir)), "/")
+
+		if version.Latest {
+			if latest {
+				return nil, fmt.Errorf("versions[%d]: only one version can be the latest", i)
+			}
+			latest = true
+		}
+
+		versions[i] = version
+	}
+
+	if !latest && len(versions) > 0 {
+		versions[0].Latest = true
+	}
+
+	return versions, nil
+}
+
+// Page returns the section page at the root of the version's dir, nil if
+// not found.
+func (v *Version) Page() *Page {
+	return v.s.Info.versions().pages[v][""]
+}
+
+// Pages returns the regular pages in the version.
+func (v *Version) Pages() Pages {
+	var pages Pages
+	for _, p := range v.s.RegularPages {
+		if version, _ := p.versionKey(); version == v {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+func (v *Version) String() string {
+	return v.Name
+}
+
+// siteVersions holds the pages of each version, keyed by their path relative
+// to the version's dir, assembled for a build.
+type siteVersions struct {
+	init     sync.Once
+	versions []*Version
+	pages    map[*Version]map[string]*Page
+}
+
+// Versions returns the versions of the documentation in the site as
+// configured, see Version.
+func (s *SiteInfo) Versions() []*Version {
+	return s.versions().versions
+}
+
+// LatestVersion returns the latest version of the documentation, nil if no
+// versions are configured.
+func (s *SiteInfo) LatestVersion() *Version {
+	for _, v := range s.Versions() {
+		if v.Latest {
+			return v
+		}
+	}
+	return nil
+}
+
+// latestVersionPath is the path below which the pages in the latest version
+// are redirected to.
+const latestVersionPath = "latest"
+
+// renderLatestVersionAliases writes the redirects from /latest/ to the pages
+// in the latest version, unless a version uses the latest dir itself.
+func (s *Site) renderLatestVersionAliases() error {
+	latest := s.Info.LatestVersion()
+	if latest == nil {
+		return nil
+	}
+
+	for _, v := range s.Info.Versions() {
+		if v.Dir == latestVersionPath {
+			return nil
+		}
+	}
+
+	for key, p := range s.Info.versions().pages[latest] {
+		if _, found := p.outputFormats.GetByName(output.HTMLFormat.Name); !found {
+			continue
+		}
+		a := p.addLangPathPrefix(path.Join(latestVersionPath, key))
+		if err := s.writeDestAlias(a, p.Permalink(), p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SiteInfo) versions() *siteVersions {
+	site := s.s
+	sv := site.versions
+
+	sv.init.Do(func() {
+		sv.pages = make(map[*Version]map[string]*Page)
+
+		for _, cfg := range site.versionsConfig {
+			v := cfg
+			v.s = site
+			sv.versions = append(sv.versions, &v)
+			sv.pages[&v] = make(map[string]*Page)
+		}
+
+		if len(sv.versions) == 0 {
+			return
+		}
+
+		for _, p := range site.Pages {
+			if p.Kind != KindPage && p.Kind != KindSection {
+				continue
+			}
+			if v, key := p.versionKeyIn(sv.versions); v != nil {
+				sv.pages[v][key] = p
+			}
+		}
+	})
+
+	return sv
+}
+
+// Version returns the version the page belongs to, nil if none.
+func (p *Page) Version() *Version {
+	v, _ := p.versionKey()
+	return v
+}
+
+// InVersion returns the same page in the version with the given name, i.e.
+// the page with the same path relative to the version's dir, nil if not
+// found.
+func (p *Page) InVersion(name string) *Page {
+	current, key := p.versionKey()
+	if current == nil {
+		return nil
+	}
+
+	sv := p.s.Info.versions()
+	for _, v := range sv.versions {
+		if v.Name == name {
+			return sv.pages[v][key]
+		}
+	}
+
+	return nil
+}
+
+// PageVersion is a version of a page, see Page.Versions.
+type PageVersion struct {
+	Version *Version
+
+	// The page in the version, nil if the page does not exist in the
+	// version.
+	Page *Page
+}
+
+// Versions returns the page in all the versions, e.g. to show "view this
+// page in version X" links, nil if the page is not in a version.
+func (p *Page) Versions() []PageVersion {
+	current, key := p.versionKey()
+	if current == nil {
+		return nil
+	}
+
+	sv := p.s.Info.versions()
+
+	versions := make([]PageVersion, len(sv.versions))
+	for i, v := range sv.versions {
+		versions[i] = PageVersion{Version: v, Page: sv.pages[v][key]}
+	}
+
+	return versions
+}
+
+// versionKey returns the version of the page and its path relative to the
+// version's dir.
+func (p *Page) versionKey() (*Version, string) {
+	if p.Kind != KindPage && p.Kind != KindSection {
+		return nil, ""
+	}
+	return p.versionKeyIn(p.s.Info.versions().versions)
+}
+
+func (p *Page) versionKeyIn(versions []*Version) (*Version, string) {
+	var key string
+	if p.Kind == KindSection {
+		key = path.Join(p.sections...)
+	} else {
+		key = path.Join(filepath.ToSlash(p.Source.Dir()), p.Source.TranslationBaseName())
+		if key == "index" || strings.HasSuffix(key, "/index") {
+			key = strings.TrimSuffix(strings.TrimSuffix(key, "index"), "/")
+		}
+	}
+
+	for _, v := range versions {
+		if key == v.Dir {
+			return v, ""
+		}
+		if strings.HasPrefix(key, v.Dir+"/") {
+			return v, key[len(v.Dir)+1:]
+		}
+	}
+
+	return nil, ""
+}
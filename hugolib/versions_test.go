@@ -0,0 +1,89 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeVersionsConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	versions, err := decodeVersionsConfig([]map[string]interface{}{
+		{"name": "v2"},
+		{"name": "v1", "title": "1.x", "dir": "/archive/v1/"},
+	})
+	assert.NoError(err)
+	assert.Equal([]Version{
+		{Name: "v2", Title: "v2", Dir: "v2", Latest: true},
+		{Name: "v1", Title: "1.x", Dir: "archive/v1"},
+	}, versions)
+
+	for i, v := range []interface{}{
+		[]map[string]interface{}{{"title": "No name"}},
+		[]map[string]interface{}{{"name": "v1"}, {"name": "v1"}},
+		[]map[string]interface{}{{"name": "v1", "latest": true}, {"name": "v2", "latest": true}},
+		"v1",
+	} {
+		_, err := decodeVersionsConfig(v)
+		assert.Error(err, "[%d]", i)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	config := `
+baseURL = "http://example.com/"
+
+[[versions]]
+name = "v1"
+title = "1.x"
+[[versions]]
+name = "v2"
+title = "2.x"
+latest = true
+`
+
+	th, h := newTestSitesFromConfig(t, afero.NewMemMapFs(), config,
+		"content/v1/_index.md", "---\ntitle: Docs 1\n---\n",
+		"content/v1/install.md", "---\ntitle: Install 1\n---\n",
+		"content/v1/old.md", "---\ntitle: Old 1\n---\n",
+		"content/v2/_index.md", "---\ntitle: Docs 2\n---\n",
+		"content/v2/install/index.md", "---\ntitle: Install 2\n---\n",
+		"content/blog/post.md", "---\ntitle: Post\n---\n",
+		"layouts/index.html", `{{ range .Site.Versions }}{{ .Name }}:{{ .Title }}:{{ .Latest }}:{{ .Page.Title }}:{{ len .Pages }}|{{ end }}`,
+		"layouts/_default/single.html", `{{ with .Version }}Version: {{ .Name }}{{ end }}
+{{ range .Versions }}{{ .Version.Title }}: {{ with .Page }}{{ .RelPermalink }}{{ else }}{{ .Version.Page.RelPermalink }}{{ end }}|{{ end }}
+In v1: {{ with .InVersion "v1" }}{{ .Title }}{{ end }}`,
+		"layouts/_default/list.html", `{{ range .Versions }}{{ .Version.Name }}: {{ .Page.Title }}|{{ end }}`,
+	)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/index.html", "v1:1.x:false:Docs 1:2|v2:2.x:true:Docs 2:1|")
+	th.assertFileContent("public/v2/install/index.html", "Version: v2", "1.x: /v1/install/|2.x: /v2/install/|", "In v1: Install 1")
+	th.assertFileContent("public/v1/old/index.html", "Version: v1", "1.x: /v1/old/|2.x: /v2/|")
+	th.assertFileContent("public/v1/index.html", "v1: Docs 1|v2: Docs 2|")
+	th.assertFileContent("public/blog/post/index.html", "\n\nIn v1: ")
+
+	require.Equal(t, "v2", h.Sites[0].Info.LatestVersion().Name)
+	th.assertFileContent("public/latest/index.html", `<meta http-equiv="refresh" content="0; url=http://example.com/v2/" />`)
+	th.assertFileContent("public/latest/install/index.html", `<meta http-equiv="refresh" content="0; url=http://example.com/v2/install/" />`)
+	th.assertFileNotExist("public/latest/old/index.html")
+}
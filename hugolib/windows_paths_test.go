@@ -0,0 +1,85 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowsPaths(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 300)
+
+	for _, mode := range []string{"warn", "mangle", "ignore"} {
+		var logBuf bytes.Buffer
+
+		cfg, fs := newTestCfg()
+		cfg.Set("windowsPaths", mode)
+
+		writeSource(t, fs, filepath.Join("content", "con.md"), "---\ntitle: CON\naliases: [/aux/]\n---\n")
+		writeSource(t, fs, filepath.Join("content", "blog", long+".md"), "---\ntitle: Long\n---\n")
+		writeSource(t, fs, filepath.Join("content", "nul", "index.md"), "---\ntitle: NUL\n---\n")
+		writeSource(t, fs, filepath.Join("content", "nul", "aux.txt"), "aux")
+		writeSource(t, fs, filepath.Join("content", "nul", long, "data.txt"), "data")
+		writeSource(t, fs, filepath.Join("layouts", "_default", "single.html"), "{{ .Title }}|{{ .RelPermalink }}|{{ range .Resources }}{{ .RelPermalink }}|{{ end }}")
+
+		s := buildSingleSite(t, deps.DepsCfg{
+			Fs:     fs,
+			Cfg:    cfg,
+			Logger: jww.NewNotepad(jww.LevelWarn, jww.LevelError, &logBuf, ioutil.Discard, "", log.Ldate|log.Ltime)},
+			BuildCfg{SkipRender: false})
+
+		th := testHelper{s.Cfg, s.Fs, t}
+		logs := logBuf.String()
+
+		switch mode {
+		case "mangle":
+			th.assertFileContent("public/con_/index.html", "CON|/con_/")
+			require.Equal(t, 1, strings.Count(s.RegularPages[0].RelPermalink(), "/con_/"), mode)
+			require.NotContains(t, logs, `"con" is a reserved name`, mode)
+			require.Contains(t, logs, `alias in page con.md is published to "aux/index.html"`, mode)
+			require.NotContains(t, logs, "Long", mode)
+			require.NotContains(t, logs, "longer than Windows allows", mode)
+
+			content := readDestination(t, s.Fs, "public/nul_/index.html")
+			require.Contains(t, content, "NUL|/nul_/|", mode)
+			require.Contains(t, content, "|/nul_/aux_.txt|", mode)
+			th.assertFileContent("public/nul_/aux_.txt", "aux")
+			for _, link := range strings.Split(content, "|") {
+				if strings.HasSuffix(link, "/data.txt") {
+					th.assertFileContent("public"+link, "data")
+					require.True(t, len(link) < len(long), mode)
+				}
+			}
+		case "warn":
+			th.assertFileContent("public/con/index.html", "CON|/con/")
+			require.Contains(t, logs, `"con" is a reserved name on Windows`, mode)
+			require.Contains(t, logs, `alias in page con.md is published to "aux/index.html"`, mode)
+			require.Contains(t, logs, "longer than Windows allows", mode)
+		case "ignore":
+			th.assertFileContent("public/con/index.html", "CON|/con/")
+			require.NotContains(t, logs, "Windows", mode)
+		}
+	}
+}
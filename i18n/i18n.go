@@ -44,11 +44,11 @@ func (t Translator) Func(lang string) bundle.TranslateFunc {
 	if f, ok := t.translateFuncs[lang]; ok {
 		return f
 	}
-	t.logger.WARN.Printf("Translation func for language %v not found, use default.", lang)
+	helpers.Warnidf(t.logger, "warning-i18n-language-missing", "Translation func for language %v not found, use default.", lang)
 	if f, ok := t.translateFuncs[t.cfg.GetString("defaultContentLanguage")]; ok {
 		return f
 	}
-	t.logger.WARN.Println("i18n not initialized, check that you have language file (in i18n) that matches the site language or the default language.")
+	helpers.Warnidf(t.logger, "warning-i18n-not-initialized", "i18n not initialized, check that you have language file (in i18n) that matches the site language or the default language.")
 	return func(translationID string, args ...interface{}) string {
 		return ""
 	}
@@ -60,7 +60,7 @@ func (t Translator) initFuncs(bndl *bundle.Bundle) {
 
 	defaultT, err := bndl.Tfunc(defaultContentLanguage)
 	if err != nil {
-		jww.WARN.Printf("No translation bundle found for default language %q", defaultContentLanguage)
+		helpers.Warnidf(t.logger, "warning-i18n-default-missing", "No translation bundle found for default language %q", defaultContentLanguage)
 	}
 
 	enableMissingTranslationPlaceholders := t.cfg.GetBool("enableMissingTranslationPlaceholders")
@@ -70,7 +70,7 @@ func (t Translator) initFuncs(bndl *bundle.Bundle) {
 		t.translateFuncs[currentLang] = func(translationID string, args ...interface{}) string {
 			tFunc, err := bndl.Tfunc(currentLang)
 			if err != nil {
-				jww.WARN.Printf("could not load translations for language %q (%s), will use default content language.\n", lang, err)
+				helpers.Warnidf(t.logger, "warning-i18n-load-failed", "could not load translations for language %q (%s), will use default content language.", lang, err)
 			}
 
 			translated := tFunc(translationID, args...)
@@ -216,3 +216,17 @@ func TestI18nTranslate(t *testing.T) {
 		}
 	}
 }
+
+func TestLanguageForFile(t *testing.T) {
+	for _, this := range []struct {
+		filename string
+		expect   string
+	}{
+		{filepath.FromSlash("i18n/fr.toml"), "fr"},
+		{filepath.FromSlash("i18n/en-US.yaml"), "en-us"},
+		{filepath.FromSlash("themes/mytheme/i18n/pt_BR.all.json"), "pt-br"},
+		{filepath.FromSlash("i18n/tlh.toml"), "tlh"},
+	} {
+		require.Equal(t, this.expect, LanguageForFile(this.filename), this.filename)
+	}
+}
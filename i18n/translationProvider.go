@@ -16,6 +16,8 @@ package i18n
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/gohugoio/hugo/helpers"
 
@@ -102,6 +104,16 @@ func addTranslationFile(bundle *bundle.Bundle, r source.ReadableFile) error {
 	return nil
 }
 
+// LanguageForFile returns the language tag of the given translation file, in
+// lower case, e.g. "fr" for "i18n/fr.toml".
+func LanguageForFile(filename string) string {
+	base := filepath.Base(filename)
+	if langs := language.Parse(base); len(langs) == 1 {
+		return langs[0].Tag
+	}
+	return language.NormalizeTag(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
 // Clone sets the language func for the new language.
 func (tp *TranslationProvider) Clone(d *deps.Deps) error {
 	d.Translate = tp.t.Func(d.Language.Lang)
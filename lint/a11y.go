@@ -0,0 +1,145 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The accessibility rules checked by CheckA11y.
+const (
+	// An img or area element without an alt attribute. An empty alt is
+	// fine for decorative images.
+	A11yRuleImageAlt = "img-alt"
+
+	// A heading more than one level below the previous heading, e.g. an h4
+	// following an h2.
+	A11yRuleHeadingOrder = "heading-order"
+
+	// A link without any text, e.g. an icon link without a label.
+	A11yRuleEmptyLink = "empty-link"
+)
+
+// A11yProblem describes an accessibility problem in a HTML document.
+type A11yProblem struct {
+	Rule    string
+	Message string
+}
+
+func (p A11yProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Rule, p.Message)
+}
+
+// CheckA11y checks the HTML document for some common accessibility
+// problems, see the A11yRule constants.
+func CheckA11y(r io.Reader) ([]A11yProblem, error) {
+	var (
+		problems []A11yProblem
+		z        = html.NewTokenizer(r)
+
+		lastHeading int
+
+		// The link currently open, if any.
+		link     *html.Token
+		linkText bytes.Buffer
+	)
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return problems, err
+			}
+			return problems, nil
+		case html.TextToken:
+			if link != nil {
+				linkText.Write(z.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			switch t.Data {
+			case "img", "area":
+				alt, hasAlt := attr(t, "alt")
+				if !hasAlt && !hasAccessibleName(t) {
+					problems = append(problems, A11yProblem{Rule: A11yRuleImageAlt, Message: fmt.Sprintf("%s is missing an alt attribute", describeTag(t))})
+				}
+				if link != nil {
+					linkText.WriteString(alt)
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				level := int(t.Data[1] - '0')
+				if lastHeading > 0 && level > lastHeading+1 {
+					problems = append(problems, A11yProblem{Rule: A11yRuleHeadingOrder, Message: fmt.Sprintf("<%s> follows <h%d>, skipping heading levels", t.Data, lastHeading)})
+				}
+				lastHeading = level
+			case "a":
+				if _, hasHref := attr(t, "href"); hasHref && tt == html.StartTagToken {
+					tc := t
+					link = &tc
+					linkText.Reset()
+				}
+			default:
+				if link != nil && hasAccessibleName(t) {
+					// E.g. an svg with an aria-label.
+					linkText.WriteString("label")
+				}
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			if t.Data == "a" && link != nil {
+				if strings.TrimSpace(linkText.String()) == "" && !hasAccessibleName(*link) {
+					problems = append(problems, A11yProblem{Rule: A11yRuleEmptyLink, Message: fmt.Sprintf("%s has no text", describeTag(*link))})
+				}
+				link = nil
+			}
+		}
+	}
+}
+
+// hasAccessibleName reports whether the element is labeled by an
+// attribute.
+func hasAccessibleName(t html.Token) bool {
+	for _, name := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, found := attr(t, name); found && strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(t html.Token, name string) (string, bool) {
+	for _, a := range t.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// describeTag returns a short description of the element to help find it,
+// e.g. <img src="/images/logo.png">.
+func describeTag(t html.Token) string {
+	for _, name := range []string{"src", "href", "id", "class"} {
+		if v, found := attr(t, name); found {
+			return fmt.Sprintf("<%s %s=%q>", t.Data, name, v)
+		}
+	}
+	return "<" + t.Data + ">"
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckA11y(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	problems, err := CheckA11y(strings.NewReader(`<!DOCTYPE html>
+<html><body>
+<h1>Title</h1>
+<img src="/logo.png">
+<img src="/decoration.png" alt="">
+<h3>Skipped</h3>
+<h2>Back</h2>
+<h3>Fine</h3>
+<a href="/about/"></a>
+<a href="/icon/"><img src="/icon.png" alt="Icon"></a>
+<a href="/svg/"><svg aria-label="Home"></svg></a>
+<a href="/labeled/" aria-label="Labeled"><i class="icon"></i></a>
+<a href="/space/"> <span> </span> </a>
+<a id="anchor"></a>
+<a href="/ok/">OK</a>
+</body></html>`))
+
+	assert.NoError(err)
+	assert.Equal([]A11yProblem{
+		{Rule: A11yRuleImageAlt, Message: `<img src="/logo.png"> is missing an alt attribute`},
+		{Rule: A11yRuleHeadingOrder, Message: `<h3> follows <h1>, skipping heading levels`},
+		{Rule: A11yRuleEmptyLink, Message: `<a href="/about/"> has no text`},
+		{Rule: A11yRuleEmptyLink, Message: `<a href="/space/"> has no text`},
+	}, problems)
+
+	assert.Equal(`img-alt: <img src="/logo.png"> is missing an alt attribute`, problems[0].String())
+}
@@ -0,0 +1,299 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint contains checks that report problems in a Hugo site.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/parser"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// DefaultSchemaKey is the key in the schema holding the rules that apply to
+// all content files.
+const DefaultSchemaKey = "_default"
+
+// FrontMatterSchema holds front matter rules keyed by section, page kind
+// (home, section or page) or DefaultSchemaKey.
+// For a given content file, the default rules apply first, then the rules
+// for its kind, then the rules for its section, the last one winning for any
+// given field.
+type FrontMatterSchema map[string]map[string]FieldRule
+
+// FieldRule describes the rules for a front matter field.
+type FieldRule struct {
+	Required bool
+
+	// The expected type, one of string, bool, int, float, date, array or map.
+	// The empty string matches any type.
+	Type string
+
+	// If set, the allowed values. For arrays this applies to every element.
+	Allowed []string
+}
+
+// Problem describes a front matter field that does not validate.
+type Problem struct {
+	Filename string
+	Field    string
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %q %s", p.Filename, p.Field, p.Message)
+}
+
+// ParseFrontMatterSchema parses the schema in data, formatted as TOML, YAML or
+// JSON depending on the filename extension.
+func ParseFrontMatterSchema(filename string, data []byte) (FrontMatterSchema, error) {
+	format := parser.FormatSanitize(strings.TrimPrefix(filepath.Ext(filename), "."))
+	fm := parser.DetectFrontMatter(parser.FormatToLeadRune(format))
+
+	v, err := fm.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %q: %s", filename, err)
+	}
+
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %s", filename, err)
+	}
+
+	schema := make(FrontMatterSchema)
+
+	for key, fields := range m {
+		fieldsm, err := cast.ToStringMapE(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %q for %q: %s", filename, key, err)
+		}
+
+		rules := make(map[string]FieldRule)
+		for field, rule := range fieldsm {
+			rulem, err := cast.ToStringMapE(rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema %q for %q: %s", filename, key+"."+field, err)
+			}
+			r := FieldRule{
+				Required: cast.ToBool(rulem["required"]),
+				Type:     strings.ToLower(cast.ToString(rulem["type"])),
+				Allowed:  cast.ToStringSlice(rulem["allowed"]),
+			}
+			if !isValidFieldType(r.Type) {
+				return nil, fmt.Errorf("invalid schema %q: unknown type %q for %q", filename, r.Type, key+"."+field)
+			}
+			rules[strings.ToLower(field)] = r
+		}
+		schema[strings.ToLower(key)] = rules
+	}
+
+	return schema, nil
+}
+
+func isValidFieldType(tp string) bool {
+	switch tp {
+	case "", "string", "bool", "int", "float", "date", "array", "map":
+		return true
+	}
+	return false
+}
+
+// Rules returns the merged rules for the given section and page kind.
+func (s FrontMatterSchema) Rules(section, kind string) map[string]FieldRule {
+	rules := make(map[string]FieldRule)
+	for _, key := range []string{DefaultSchemaKey, kind, section} {
+		if key == "" {
+			continue
+		}
+		for field, rule := range s[strings.ToLower(key)] {
+			rules[field] = rule
+		}
+	}
+	return rules
+}
+
+// Validate validates the front matter of the given content file.
+func (s FrontMatterSchema) Validate(filename, section, kind string, frontMatter map[string]interface{}) []Problem {
+	var problems []Problem
+
+	values := make(map[string]interface{})
+	for k, v := range frontMatter {
+		values[strings.ToLower(k)] = v
+	}
+
+	rules := s.Rules(section, kind)
+
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		rule := rules[field]
+		v, found := values[field]
+		if !found || v == nil || v == "" {
+			if rule.Required {
+				problems = append(problems, Problem{Filename: filename, Field: field, Message: "is required"})
+			}
+			continue
+		}
+
+		if !isOfType(v, rule.Type) {
+			problems = append(problems, Problem{Filename: filename, Field: field, Message: fmt.Sprintf("must be of type %s", rule.Type)})
+			continue
+		}
+
+		if len(rule.Allowed) == 0 {
+			continue
+		}
+
+		for _, vv := range toValues(v) {
+			if !helpers.InStringArray(rule.Allowed, vv) {
+				problems = append(problems, Problem{Filename: filename, Field: field, Message: fmt.Sprintf("has invalid value %q, must be one of %s", vv, strings.Join(rule.Allowed, ", "))})
+			}
+		}
+	}
+
+	return problems
+}
+
+func isOfType(v interface{}, tp string) bool {
+	switch tp {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "bool":
+		_, ok := v.(bool)
+		return ok
+	case "int":
+		switch v.(type) {
+		case int, int64, int32, uint64:
+			return true
+		}
+		return false
+	case "float":
+		switch v.(type) {
+		case float64, float32, int, int64, int32, uint64:
+			return true
+		}
+		return false
+	case "date":
+		switch vv := v.(type) {
+		case time.Time:
+			return true
+		case string:
+			_, err := cast.ToTimeE(vv)
+			return err == nil
+		}
+		return false
+	case "array":
+		switch v.(type) {
+		case []interface{}, []string:
+			return true
+		}
+		return false
+	case "map":
+		_, err := cast.ToStringMapE(v)
+		return err == nil
+	}
+
+	return true
+}
+
+func toValues(v interface{}) []string {
+	switch vv := v.(type) {
+	case []interface{}, []string:
+		return cast.ToStringSlice(vv)
+	}
+	return []string{cast.ToString(v)}
+}
+
+// CheckFrontMatter validates the front matter of every content file in
+// contentDir against the schema.
+func CheckFrontMatter(fs afero.Fs, contentDir string, schema FrontMatterSchema) ([]Problem, error) {
+	var problems []Problem
+
+	walker := func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() || helpers.GuessType(strings.TrimPrefix(filepath.Ext(path), ".")) == "unknown" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		p, err := parser.ReadFrom(f)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %s", rel, err)
+		}
+
+		meta, err := p.Metadata()
+		if err != nil {
+			return fmt.Errorf("failed to parse front matter in %q: %s", rel, err)
+		}
+
+		frontMatter, _ := cast.ToStringMapE(meta)
+		section, kind := sectionAndKind(rel)
+
+		problems = append(problems, schema.Validate(filepath.ToSlash(rel), section, kind, frontMatter)...)
+
+		return nil
+	}
+
+	if err := helpers.SymbolicWalk(fs, contentDir, walker); err != nil {
+		return nil, err
+	}
+
+	return problems, nil
+}
+
+// sectionAndKind returns the section and page kind for the given filename
+// relative to the content dir.
+func sectionAndKind(rel string) (string, string) {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	base := helpers.Filename(parts[len(parts)-1])
+
+	var section string
+	if len(parts) > 1 {
+		section = parts[0]
+	}
+
+	if base == "_index" || strings.HasPrefix(base, "_index.") {
+		if len(parts) == 1 {
+			return section, "home"
+		}
+		return section, "section"
+	}
+
+	return section, "page"
+}
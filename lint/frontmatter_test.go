@@ -0,0 +1,101 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+[_default.title]
+required = true
+
+[page.date]
+type = "date"
+
+[posts.description]
+required = true
+type = "string"
+
+[posts.categories]
+type = "array"
+allowed = ["go", "hugo"]
+`
+
+func TestParseFrontMatterSchema(t *testing.T) {
+	assert := require.New(t)
+
+	schema, err := ParseFrontMatterSchema("schema.toml", []byte(testSchema))
+	assert.NoError(err)
+
+	rules := schema.Rules("posts", "page")
+	assert.Len(rules, 4)
+	assert.True(rules["title"].Required)
+	assert.Equal("date", rules["date"].Type)
+	assert.Equal([]string{"go", "hugo"}, rules["categories"].Allowed)
+
+	rules = schema.Rules("", "home")
+	assert.Len(rules, 1)
+
+	_, err = ParseFrontMatterSchema("schema.yaml", []byte("posts:\n  title:\n    type: uuid\n"))
+	assert.Error(err)
+
+	schema, err = ParseFrontMatterSchema("schema.yaml", []byte("posts:\n  Title:\n    required: true\n"))
+	assert.NoError(err)
+	assert.True(schema.Rules("posts", "page")["title"].Required)
+}
+
+func TestCheckFrontMatter(t *testing.T) {
+	assert := require.New(t)
+
+	schema, err := ParseFrontMatterSchema("schema.toml", []byte(testSchema))
+	assert.NoError(err)
+
+	fs := afero.NewMemMapFs()
+	contentDir := filepath.FromSlash("/content")
+
+	for _, f := range []struct {
+		name    string
+		content string
+	}{
+		{"_index.md", "---\ntitle: Home\n---\n"},
+		{"posts/_index.md", "---\ntitle: Posts\n---\n"},
+		{"posts/ok.md", "---\ntitle: OK\ndate: 2018-01-01\ndescription: Desc\ncategories: [go]\n---\n"},
+		{"posts/bad.md", "+++\nTitle = \"Bad\"\ndate = \"invalid\"\ncategories = [\"go\", \"rust\"]\n+++\n"},
+		{"about.md", "Content without front matter."},
+		{"posts/image.jpg", "not content"},
+	} {
+		assert.NoError(afero.WriteFile(fs, filepath.Join(contentDir, filepath.FromSlash(f.name)), []byte(f.content), 0755))
+	}
+
+	problems, err := CheckFrontMatter(fs, contentDir, schema)
+	assert.NoError(err)
+
+	var messages []string
+	for _, p := range problems {
+		messages = append(messages, p.String())
+	}
+
+	assert.Equal([]string{
+		`about.md: "title" is required`,
+		`posts/_index.md: "description" is required`,
+		`posts/bad.md: "categories" has invalid value "rust", must be one of go, hugo`,
+		`posts/bad.md: "date" must be of type date`,
+		`posts/bad.md: "description" is required`,
+	}, messages)
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The HTML conformance rules checked by CheckHTML.
+const (
+	// No <!DOCTYPE html> before the first element.
+	HTMLRuleDoctype = "doctype"
+
+	// An element without an end tag, e.g. a div left open by a partial.
+	HTMLRuleUnclosed = "unclosed"
+
+	// An end tag without a matching start tag.
+	HTMLRuleStrayEndTag = "stray-end-tag"
+
+	// An end tag closing an element while elements opened inside of it are
+	// still open, e.g. <div><span></div>.
+	HTMLRuleMisnested = "misnested"
+
+	// An end tag for a void element, e.g. </br>.
+	HTMLRuleVoidEndTag = "void-end-tag"
+
+	// The same attribute set more than once on an element.
+	HTMLRuleDuplicateAttribute = "duplicate-attribute"
+
+	// The same id used on more than one element.
+	HTMLRuleDuplicateID = "duplicate-id"
+)
+
+// HTMLProblem describes a conformance problem in a HTML document.
+type HTMLProblem struct {
+	Rule    string
+	Line    int
+	Message string
+}
+
+func (p HTMLProblem) String() string {
+	return fmt.Sprintf("%d: %s: %s", p.Line, p.Rule, p.Message)
+}
+
+var (
+	voidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true,
+		"hr": true, "img": true, "input": true, "link": true, "meta": true,
+		"param": true, "source": true, "track": true, "wbr": true,
+	}
+
+	// The elements whose end tag may be omitted.
+	optionalEndElements = map[string]bool{
+		"html": true, "head": true, "body": true, "p": true, "li": true,
+		"dt": true, "dd": true, "rt": true, "rp": true, "optgroup": true,
+		"option": true, "colgroup": true, "caption": true, "thead": true,
+		"tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+	}
+)
+
+type openElement struct {
+	name string
+	line int
+}
+
+// CheckHTML checks the HTML document for problems the browsers silently
+// repair, but that are usually mistakes in the templates, see the HTMLRule
+// constants.
+func CheckHTML(r io.Reader) ([]HTMLProblem, error) {
+	var (
+		problems []HTMLProblem
+		z        = html.NewTokenizer(r)
+
+		line       = 1
+		hasDoctype bool
+		seenTag    bool
+		open       []openElement
+		ids        = make(map[string]int)
+	)
+
+	add := func(rule string, line int, format string, args ...interface{}) {
+		problems = append(problems, HTMLProblem{Rule: rule, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for {
+		tt := z.Next()
+		raw := z.Raw()
+		tokenLine := line + bytes.Count(raw[:len(raw)-len(bytes.TrimLeft(raw, " \t\r\n"))], []byte("\n"))
+		line += bytes.Count(raw, []byte("\n"))
+
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return problems, err
+			}
+
+			for i := len(open) - 1; i >= 0; i-- {
+				if !optionalEndElements[open[i].name] {
+					add(HTMLRuleUnclosed, open[i].line, "<%s> is never closed", open[i].name)
+				}
+			}
+
+			return problems, nil
+		case html.DoctypeToken:
+			hasDoctype = true
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if !seenTag {
+				seenTag = true
+				if !hasDoctype {
+					add(HTMLRuleDoctype, tokenLine, "the document has no <!DOCTYPE html>")
+				}
+			}
+
+			seen := make(map[string]bool)
+			for _, a := range t.Attr {
+				if seen[a.Key] {
+					add(HTMLRuleDuplicateAttribute, tokenLine, "<%s> has more than one %s attribute", t.Data, a.Key)
+				}
+				seen[a.Key] = true
+
+				if a.Key == "id" && a.Val != "" {
+					if first, found := ids[a.Val]; found {
+						add(HTMLRuleDuplicateID, tokenLine, "the id %q is already used on line %d", a.Val, first)
+					} else {
+						ids[a.Val] = tokenLine
+					}
+				}
+			}
+
+			if tt == html.StartTagToken && !voidElements[t.Data] {
+				open = append(open, openElement{name: t.Data, line: tokenLine})
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			if voidElements[t.Data] {
+				add(HTMLRuleVoidEndTag, tokenLine, "</%s> is not allowed, <%s> is a void element", t.Data, t.Data)
+				continue
+			}
+
+			i := len(open) - 1
+			for ; i >= 0; i-- {
+				if open[i].name == t.Data {
+					break
+				}
+			}
+
+			if i < 0 {
+				add(HTMLRuleStrayEndTag, tokenLine, "</%s> has no matching start tag", t.Data)
+				continue
+			}
+
+			var unclosed []string
+			for _, e := range open[i+1:] {
+				if !optionalEndElements[e.name] {
+					unclosed = append(unclosed, fmt.Sprintf("<%s> on line %d", e.name, e.line))
+				}
+			}
+			if len(unclosed) > 0 {
+				add(HTMLRuleMisnested, tokenLine, "</%s> closes %s", t.Data, strings.Join(unclosed, ", "))
+			}
+
+			open = open[:i]
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHTML(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	problems, err := CheckHTML(strings.NewReader(`<!DOCTYPE html>
+<html><head><title>T</title>
+<script>if (a < b) { document.write("</div>") }</script>
+</head>
+<body>
+<ul><li>One<li>Two</ul>
+<p>Text<br></br>
+<div id="main"><span class="a" class="b">Text</div>
+<div id="main">Dup</div>
+</section>
+<table><tr><td>Cell</table>
+<article>
+<img src="/a.png"/>
+</body></html>`))
+
+	assert.NoError(err)
+	assert.Equal([]HTMLProblem{
+		{Rule: HTMLRuleVoidEndTag, Line: 7, Message: "</br> is not allowed, <br> is a void element"},
+		{Rule: HTMLRuleDuplicateAttribute, Line: 8, Message: "<span> has more than one class attribute"},
+		{Rule: HTMLRuleMisnested, Line: 8, Message: "</div> closes <span> on line 8"},
+		{Rule: HTMLRuleDuplicateID, Line: 9, Message: `the id "main" is already used on line 8`},
+		{Rule: HTMLRuleStrayEndTag, Line: 10, Message: "</section> has no matching start tag"},
+		{Rule: HTMLRuleMisnested, Line: 14, Message: "</body> closes <article> on line 12"},
+	}, problems)
+
+	assert.Equal("7: void-end-tag: </br> is not allowed, <br> is a void element", problems[0].String())
+
+	problems, err = CheckHTML(strings.NewReader("<p>No doctype\n<div>"))
+	assert.NoError(err)
+	assert.Equal([]HTMLProblem{
+		{Rule: HTMLRuleDoctype, Line: 1, Message: "the document has no <!DOCTYPE html>"},
+		{Rule: HTMLRuleUnclosed, Line: 2, Message: "<div> is never closed"},
+	}, problems)
+}
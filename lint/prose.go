@@ -0,0 +1,201 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ProseLinter is an external spell checker or prose linter run over the
+// content files, set in the site config, e.g.:
+//
+//	[[proseLinters]]
+//	name = "vale"
+//
+//	[[proseLinters]]
+//	name = "codespell"
+//	args = ["--ignore-words", "words.txt"]
+//
+// The vale and codespell linters have defaults for all but the name.
+type ProseLinter struct {
+	Name string
+
+	// The command to run, defaults to the name. The content files are
+	// appended to the arguments.
+	Command string
+	Args    []string
+
+	// A regular expression matching one problem in the output, with the
+	// named groups file, line, col (optional) and message.
+	Format string
+
+	re *regexp.Regexp
+}
+
+// ProseProblem is a problem reported by a prose linter.
+type ProseProblem struct {
+	Filename string
+	Line     int
+	Column   int
+	Linter   string
+	Message  string
+}
+
+func (p ProseProblem) String() string {
+	if p.Column > 0 {
+		return fmt.Sprintf("%d:%d %s: %s", p.Line, p.Column, p.Linter, p.Message)
+	}
+	return fmt.Sprintf("%d %s: %s", p.Line, p.Linter, p.Message)
+}
+
+// The defaults for the known linters.
+var proseLinterDefaults = map[string]ProseLinter{
+	"vale": {
+		Args:   []string{"--output=line"},
+		Format: `^(?P<file>.+?):(?P<line>\d+):(?P<col>\d+):(?P<message>.*)$`,
+	},
+	"codespell": {
+		Format: `^(?P<file>.+?):(?P<line>\d+): (?P<message>.*)$`,
+	},
+}
+
+const defaultProseFormat = `^(?P<file>.+?):(?P<line>\d+):(?:(?P<col>\d+):)? ?(?P<message>.*)$`
+
+// DecodeProseLinters decodes the proseLinters config.
+func DecodeProseLinters(v interface{}) ([]ProseLinter, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var linters []ProseLinter
+	if err := mapstructure.WeakDecode(v, &linters); err != nil {
+		return nil, fmt.Errorf("failed to decode proseLinters config: %s", err)
+	}
+
+	for i, l := range linters {
+		if l.Name == "" {
+			return nil, fmt.Errorf("proseLinters config must have a name")
+		}
+
+		defaults := proseLinterDefaults[strings.ToLower(l.Name)]
+		if l.Command == "" {
+			l.Command = l.Name
+		}
+		if l.Args == nil {
+			l.Args = defaults.Args
+		}
+		if l.Format == "" {
+			l.Format = defaults.Format
+		}
+		if l.Format == "" {
+			l.Format = defaultProseFormat
+		}
+
+		re, err := regexp.Compile(l.Format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format for prose linter %q: %s", l.Name, err)
+		}
+		for _, group := range []string{"file", "line", "message"} {
+			if !hasSubexp(re, group) {
+				return nil, fmt.Errorf("invalid format for prose linter %q: missing the %s group", l.Name, group)
+			}
+		}
+		l.re = re
+
+		linters[i] = l
+	}
+
+	return linters, nil
+}
+
+func hasSubexp(re *regexp.Regexp, name string) bool {
+	for _, n := range re.SubexpNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the linter over the given content files from the given working
+// dir and returns the problems found.
+func (l ProseLinter) Run(workingDir string, filenames []string) ([]ProseProblem, error) {
+	if len(filenames) == 0 {
+		return nil, nil
+	}
+
+	cmd := exec.Command(l.Command, append(append([]string{}, l.Args...), filenames...)...)
+	cmd.Dir = workingDir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		// The linters exit with a non-zero status when they find
+		// problems.
+		if _, ok := err.(*exec.ExitError); !ok || len(out) == 0 {
+			return nil, fmt.Errorf("failed to run prose linter %q: %s %s", l.Name, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	problems := l.parse(out)
+	for i, p := range problems {
+		if !filepath.IsAbs(p.Filename) {
+			problems[i].Filename = filepath.Join(workingDir, p.Filename)
+		}
+	}
+
+	return problems, nil
+}
+
+// parse parses the problems in the linter output. Lines not matching the
+// format are ignored.
+func (l ProseLinter) parse(out []byte) []ProseProblem {
+	var problems []ProseProblem
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		m := l.re.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		p := ProseProblem{Linter: l.Name}
+		for i, name := range l.re.SubexpNames() {
+			switch name {
+			case "file":
+				p.Filename = filepath.FromSlash(m[i])
+			case "line":
+				p.Line, _ = strconv.Atoi(m[i])
+			case "col":
+				p.Column, _ = strconv.Atoi(m[i])
+			case "message":
+				p.Message = strings.TrimSpace(m[i])
+			}
+		}
+		problems = append(problems, p)
+	}
+
+	return problems
+}
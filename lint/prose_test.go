@@ -0,0 +1,95 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeProseLinters(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	linters, err := DecodeProseLinters([]map[string]interface{}{
+		{"name": "vale"},
+		{"name": "codespell", "args": []string{"-q", "3"}},
+		{"name": "mylint", "command": "bin/mylint"},
+	})
+	assert.NoError(err)
+	assert.Len(linters, 3)
+
+	assert.Equal("vale", linters[0].Command)
+	assert.Equal([]string{"--output=line"}, linters[0].Args)
+	assert.Equal([]string{"-q", "3"}, linters[1].Args)
+	assert.Equal("bin/mylint", linters[2].Command)
+	assert.Equal(defaultProseFormat, linters[2].Format)
+
+	_, err = DecodeProseLinters([]map[string]interface{}{{"command": "vale"}})
+	assert.Error(err)
+	_, err = DecodeProseLinters([]map[string]interface{}{{"name": "l", "format": `^(?P<file>.*):(?P<message>.*)$`}})
+	assert.Error(err)
+}
+
+func TestProseLinterParse(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	linters, err := DecodeProseLinters([]map[string]interface{}{{"name": "vale"}, {"name": "codespell"}, {"name": "other"}})
+	assert.NoError(err)
+
+	problems := linters[0].parse([]byte(`content/posts/p1.md:12:4:Vale.Spelling:Did you really mean 'teh'?
+Some summary line
+content/about.md:3:1:write-good.Weasel:'very' is a weasel word!
+`))
+	assert.Len(problems, 2)
+	assert.Equal(ProseProblem{Filename: filepath.FromSlash("content/posts/p1.md"), Line: 12, Column: 4, Linter: "vale", Message: "Vale.Spelling:Did you really mean 'teh'?"}, problems[0])
+	assert.Equal("3:1 vale: write-good.Weasel:'very' is a weasel word!", problems[1].String())
+
+	problems = linters[1].parse([]byte("content/about.md:7: teh ==> the\n"))
+	assert.Len(problems, 1)
+	assert.Equal(7, problems[0].Line)
+	assert.Equal(0, problems[0].Column)
+	assert.Equal("7 codespell: teh ==> the", problems[0].String())
+
+	problems = linters[2].parse([]byte("a.md:1:2: col\nb.md:3: nocol\n"))
+	assert.Len(problems, 2)
+	assert.Equal(2, problems[0].Column)
+	assert.Equal("col", problems[0].Message)
+	assert.Equal("nocol", problems[1].Message)
+}
+
+func TestProseLinterRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Parallel()
+	assert := require.New(t)
+
+	linters, err := DecodeProseLinters([]map[string]interface{}{
+		{"name": "fake", "command": "sh", "args": []string{"-c", `for f in "$@"; do echo "$f:1:2: found a problem"; done; exit 1`, "sh"}},
+	})
+	assert.NoError(err)
+
+	dir := os.TempDir()
+	problems, err := linters[0].Run(dir, []string{filepath.Join(dir, "content", "a.md"), filepath.FromSlash("content/b.md")})
+	assert.NoError(err)
+	assert.Len(problems, 2)
+	assert.Equal(filepath.Join(dir, "content", "a.md"), problems[0].Filename)
+	assert.Equal(filepath.Join(dir, "content", "b.md"), problems[1].Filename)
+}
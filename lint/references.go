@@ -0,0 +1,145 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// The url() and @import references in CSS.
+	cssReferenceRe = regexp.MustCompile(`url\(\s*['"]?([^'")\s]+)['"]?\s*\)|@import\s+['"]([^'"]+)['"]`)
+
+	// Quoted strings that look like paths or URLs, e.g. in JSON, XML and
+	// JavaScript.
+	quotedReferenceRe = regexp.MustCompile(`["']([^"'\s<>]*[./][^"'\s<>]*)["']`)
+
+	// Absolute URLs anywhere in the text, e.g. in RSS and sitemaps.
+	absoluteReferenceRe = regexp.MustCompile(`https?://[^\s"'<>()\\]+`)
+)
+
+// ExtractReferences returns the URLs referenced in the document, as written,
+// so they may be relative. In HTML these are the URL attributes, e.g. href,
+// src and srcset, and the references in style elements and attributes and in
+// scripts. In other text files, e.g. CSS, JSON and XML, these are the url()
+// and @import references, the quoted paths and the absolute URLs.
+// Duplicates are removed.
+func ExtractReferences(r io.Reader, isHTML bool) ([]string, error) {
+	var refs []string
+	var err error
+
+	if isHTML {
+		refs, err = extractHTMLReferences(r)
+	} else {
+		var b []byte
+		b, err = ioutil.ReadAll(r)
+		refs = extractTextReferences(string(b))
+	}
+
+	seen := make(map[string]bool)
+	unique := refs[:0]
+	for _, ref := range refs {
+		if !seen[ref] {
+			seen[ref] = true
+			unique = append(unique, ref)
+		}
+	}
+
+	return unique, err
+}
+
+func extractHTMLReferences(r io.Reader) ([]string, error) {
+	var (
+		refs     []string
+		z        = html.NewTokenizer(r)
+		inRawTag bool
+	)
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return refs, nil
+			}
+			return refs, z.Err()
+		case html.TextToken:
+			if inRawTag {
+				refs = append(refs, extractTextReferences(string(z.Text()))...)
+			}
+		case html.EndTagToken:
+			inRawTag = false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			inRawTag = t.Type == html.StartTagToken && (t.Data == "style" || t.Data == "script")
+			for _, a := range t.Attr {
+				refs = append(refs, attributeReferences(a)...)
+			}
+		}
+	}
+}
+
+func attributeReferences(a html.Attribute) []string {
+	name := strings.ToLower(a.Key)
+	if a.Namespace != "" {
+		name = strings.ToLower(a.Namespace) + ":" + name
+	}
+	value := strings.TrimSpace(a.Val)
+
+	switch {
+	case value == "":
+		return nil
+	case name == "srcset" || strings.HasSuffix(name, "-srcset"):
+		var refs []string
+		for _, candidate := range strings.Split(value, ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				refs = append(refs, fields[0])
+			}
+		}
+		return refs
+	case name == "style":
+		return extractTextReferences(value)
+	case name == "href", name == "src", name == "poster", name == "data", name == "action",
+		name == "content", name == "xlink:href", strings.HasPrefix(name, "data-"),
+		strings.HasSuffix(name, "-src"), strings.HasSuffix(name, "-href"):
+		return []string{value}
+	}
+
+	return nil
+}
+
+func extractTextReferences(s string) []string {
+	var refs []string
+
+	for _, m := range cssReferenceRe.FindAllStringSubmatch(s, -1) {
+		if m[1] != "" {
+			refs = append(refs, m[1])
+		} else {
+			refs = append(refs, m[2])
+		}
+	}
+
+	for _, m := range quotedReferenceRe.FindAllStringSubmatch(s, -1) {
+		// JSON escapes the slashes in some cases.
+		refs = append(refs, strings.Replace(m[1], `\/`, "/", -1))
+	}
+
+	refs = append(refs, absoluteReferenceRe.FindAllString(s, -1)...)
+
+	return refs
+}
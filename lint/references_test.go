@@ -0,0 +1,73 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractReferencesHTML(t *testing.T) {
+	assert := require.New(t)
+
+	doc := `<!DOCTYPE html>
+<html>
+<head>
+<link rel="stylesheet" href="/css/main.css">
+<meta property="og:image" content="https://example.com/og.png">
+<style>body { background: url('/images/bg.png'); }</style>
+<script>var data = {"logo": "/images/logo.svg"};</script>
+</head>
+<body>
+<a href="../other/">Other</a>
+<img src="photo.jpg" srcset="photo-small.jpg 480w, photo-large.jpg 1024w" alt="">
+<div style="background-image: url(hero.jpg)" data-src="lazy.jpg">Text with "quoted.txt" is not a reference.</div>
+<video poster="poster.png"><source src="clip.mp4"></video>
+<svg><use xlink:href="/icons.svg#home"></use></svg>
+</body>
+</html>`
+
+	refs, err := ExtractReferences(strings.NewReader(doc), true)
+	assert.NoError(err)
+	assert.Equal([]string{
+		"/css/main.css",
+		"https://example.com/og.png",
+		"/images/bg.png",
+		"/images/logo.svg",
+		"../other/",
+		"photo.jpg", "photo-small.jpg", "photo-large.jpg",
+		"hero.jpg", "lazy.jpg",
+		"poster.png", "clip.mp4",
+		"/icons.svg#home",
+	}, refs)
+}
+
+func TestExtractReferencesText(t *testing.T) {
+	assert := require.New(t)
+
+	refs, err := ExtractReferences(strings.NewReader(`@import "print.css";
+.a { background: url( "img/a.png" ) }`), false)
+	assert.NoError(err)
+	assert.Equal([]string{"print.css", "img/a.png"}, refs)
+
+	refs, err = ExtractReferences(strings.NewReader(`<rss><item><link>http://example.com/post/</link><enclosure url="http://example.com/post/audio.mp3" /></item></rss>`), false)
+	assert.NoError(err)
+	assert.Equal([]string{"http://example.com/post/audio.mp3", "http://example.com/post/"}, refs)
+
+	refs, err = ExtractReferences(strings.NewReader(`{"image": "images\/a.jpg", "title": "No reference"}`), false)
+	assert.NoError(err)
+	assert.Equal([]string{"images/a.jpg"}, refs)
+}
@@ -0,0 +1,235 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mitchellh/mapstructure"
+	"golang.org/x/net/html"
+)
+
+// The SEO rules checked by CheckSEO.
+const (
+	// No or an empty title element.
+	SEORuleTitle = "title"
+
+	// A title longer than the maximum length.
+	SEORuleTitleLength = "title-length"
+
+	// No or an empty description meta element.
+	SEORuleDescription = "description"
+
+	// A description longer than the maximum length.
+	SEORuleDescriptionLength = "description-length"
+
+	// No canonical link element.
+	SEORuleCanonical = "canonical"
+
+	// No og:image meta element.
+	SEORuleOpenGraphImage = "og-image"
+
+	// No twitter:image meta element.
+	SEORuleTwitterImage = "twitter-image"
+)
+
+// SEOConfig holds the SEO rules and their per section overrides, set in the
+// seo site config:
+//
+//	[seo]
+//	maxTitleLength = 60
+//	disable = ["twitter-image"]
+//	[seo.sections.blog]
+//	maxTitleLength = 70
+type SEOConfig struct {
+	SEORules `mapstructure:",squash"`
+
+	// Section => the rules overriding the default rules.
+	Sections map[string]SEORules
+}
+
+// SEORules holds the thresholds and disabled rules checked by CheckSEO.
+type SEORules struct {
+	// The maximum number of characters in the title and description.
+	MaxTitleLength       int
+	MaxDescriptionLength int
+
+	// The rules not to check, see the SEORule constants.
+	Disable []string
+}
+
+// DefaultSEOConfig holds the default SEO thresholds, the lengths most search
+// engines show in the results.
+var DefaultSEOConfig = SEOConfig{SEORules: SEORules{MaxTitleLength: 60, MaxDescriptionLength: 160}}
+
+// DecodeSEOConfig decodes the seo config.
+func DecodeSEOConfig(v interface{}) (SEOConfig, error) {
+	c := DefaultSEOConfig
+	if v == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(v, &c); err != nil {
+		return c, fmt.Errorf("failed to decode seo config: %s", err)
+	}
+
+	return c, nil
+}
+
+// ForSection returns the rules that apply to the pages in the given section.
+func (c SEOConfig) ForSection(section string) SEORules {
+	rules := c.SEORules
+	override, found := c.Sections[section]
+	if !found {
+		return rules
+	}
+
+	if override.MaxTitleLength > 0 {
+		rules.MaxTitleLength = override.MaxTitleLength
+	}
+	if override.MaxDescriptionLength > 0 {
+		rules.MaxDescriptionLength = override.MaxDescriptionLength
+	}
+	if override.Disable != nil {
+		rules.Disable = override.Disable
+	}
+
+	return rules
+}
+
+func (r SEORules) enabled(rule string) bool {
+	for _, disabled := range r.Disable {
+		if strings.EqualFold(disabled, rule) {
+			return false
+		}
+	}
+	return true
+}
+
+// SEOProblem describes missing or too long metadata in a HTML document.
+type SEOProblem struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p SEOProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Rule, p.Message)
+}
+
+// CheckSEO checks the metadata in the head of the HTML document, see the
+// SEORule constants.
+func CheckSEO(r io.Reader, rules SEORules) ([]SEOProblem, error) {
+	var (
+		title, description    string
+		hasTitle, inTitle     bool
+		hasDescription        bool
+		canonical             bool
+		ogImage, twitterImage bool
+
+		z = html.NewTokenizer(r)
+	)
+
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			break loop
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			if t.Data == "title" {
+				inTitle = false
+			} else if t.Data == "head" {
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			switch t.Data {
+			case "title":
+				if !hasTitle {
+					hasTitle = true
+					inTitle = tt == html.StartTagToken
+				}
+			case "meta":
+				name, _ := attr(t, "name")
+				if name == "" {
+					name, _ = attr(t, "property")
+				}
+				content, _ := attr(t, "content")
+				content = strings.TrimSpace(content)
+				switch strings.ToLower(name) {
+				case "description":
+					hasDescription = true
+					description = content
+				case "og:image":
+					ogImage = ogImage || content != ""
+				case "twitter:image", "twitter:image:src":
+					twitterImage = twitterImage || content != ""
+				}
+			case "link":
+				rel, _ := attr(t, "rel")
+				href, _ := attr(t, "href")
+				if strings.EqualFold(strings.TrimSpace(rel), "canonical") && strings.TrimSpace(href) != "" {
+					canonical = true
+				}
+			case "body":
+				break loop
+			}
+		}
+	}
+
+	var problems []SEOProblem
+	add := func(rule, format string, args ...interface{}) {
+		if rules.enabled(rule) {
+			problems = append(problems, SEOProblem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		add(SEORuleTitle, "the page has no title")
+	} else if n := utf8.RuneCountInString(title); rules.MaxTitleLength > 0 && n > rules.MaxTitleLength {
+		add(SEORuleTitleLength, "the title %q is %d characters, more than %d", title, n, rules.MaxTitleLength)
+	}
+
+	if !hasDescription || description == "" {
+		add(SEORuleDescription, "the page has no description")
+	} else if n := utf8.RuneCountInString(description); rules.MaxDescriptionLength > 0 && n > rules.MaxDescriptionLength {
+		add(SEORuleDescriptionLength, "the description is %d characters, more than %d", n, rules.MaxDescriptionLength)
+	}
+
+	if !canonical {
+		add(SEORuleCanonical, "the page has no canonical URL")
+	}
+
+	if !ogImage {
+		add(SEORuleOpenGraphImage, "the page has no og:image")
+	}
+
+	if !twitterImage {
+		add(SEORuleTwitterImage, "the page has no twitter:image")
+	}
+
+	return problems, nil
+}
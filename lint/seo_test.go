@@ -0,0 +1,75 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSEO(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	problems, err := CheckSEO(strings.NewReader(`<!DOCTYPE html>
+<html><head>
+<title>
+  A very long title
+</title>
+<meta name="description" content="Short.">
+<link rel="canonical" href="https://example.com/p/">
+<meta property="og:image" content="https://example.com/p.png">
+<meta name="twitter:image" content="https://example.com/p.png">
+</head><body><title>Ignored</title></body></html>`), SEORules{MaxTitleLength: 10, MaxDescriptionLength: 160})
+
+	assert.NoError(err)
+	assert.Equal([]SEOProblem{
+		{Rule: SEORuleTitleLength, Message: `the title "A very long title" is 17 characters, more than 10`},
+	}, problems)
+
+	problems, err = CheckSEO(strings.NewReader(`<html><head><meta name="description" content=""></head><body></body></html>`), SEORules{Disable: []string{"Twitter-Image"}})
+	assert.NoError(err)
+	assert.Equal([]SEOProblem{
+		{Rule: SEORuleTitle, Message: "the page has no title"},
+		{Rule: SEORuleDescription, Message: "the page has no description"},
+		{Rule: SEORuleCanonical, Message: "the page has no canonical URL"},
+		{Rule: SEORuleOpenGraphImage, Message: "the page has no og:image"},
+	}, problems)
+	assert.Equal("title: the page has no title", problems[0].String())
+}
+
+func TestDecodeSEOConfig(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	c, err := DecodeSEOConfig(nil)
+	assert.NoError(err)
+	assert.Equal(60, c.ForSection("blog").MaxTitleLength)
+
+	c, err = DecodeSEOConfig(map[string]interface{}{
+		"maxDescriptionLength": 120,
+		"disable":              []string{"twitter-image"},
+		"sections": map[string]interface{}{
+			"blog": map[string]interface{}{"maxTitleLength": 70},
+			"docs": map[string]interface{}{"disable": []string{}},
+		},
+	})
+	assert.NoError(err)
+
+	assert.Equal(SEORules{MaxTitleLength: 60, MaxDescriptionLength: 120, Disable: []string{"twitter-image"}}, c.ForSection(""))
+	assert.Equal(SEORules{MaxTitleLength: 70, MaxDescriptionLength: 120, Disable: []string{"twitter-image"}}, c.ForSection("blog"))
+	assert.Empty(c.ForSection("docs").Disable)
+}
@@ -86,9 +86,11 @@ var (
 	CalendarType   = Type{"text", "calendar", "ics", defaultDelimiter}
 	CSSType        = Type{"text", "css", "css", defaultDelimiter}
 	CSVType        = Type{"text", "csv", "csv", defaultDelimiter}
+	EPUBType       = Type{"application", "epub+zip", "epub", defaultDelimiter}
 	HTMLType       = Type{"text", "html", "html", defaultDelimiter}
 	JavascriptType = Type{"application", "javascript", "js", defaultDelimiter}
 	JSONType       = Type{"application", "json", "json", defaultDelimiter}
+	PDFType        = Type{"application", "pdf", "pdf", defaultDelimiter}
 	RSSType        = Type{"application", "rss", "xml", defaultDelimiter}
 	XMLType        = Type{"application", "xml", "xml", defaultDelimiter}
 	TextType       = Type{"text", "plain", "txt", defaultDelimiter}
@@ -98,9 +100,11 @@ var DefaultTypes = Types{
 	CalendarType,
 	CSSType,
 	CSVType,
+	EPUBType,
 	HTMLType,
 	JavascriptType,
 	JSONType,
+	PDFType,
 	RSSType,
 	XMLType,
 	TextType,
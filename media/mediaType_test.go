@@ -31,9 +31,11 @@ func TestDefaultTypes(t *testing.T) {
 		{CalendarType, "text", "calendar", "ics", "text/calendar", "text/calendar+ics"},
 		{CSSType, "text", "css", "css", "text/css", "text/css+css"},
 		{CSVType, "text", "csv", "csv", "text/csv", "text/csv+csv"},
+		{EPUBType, "application", "epub+zip", "epub", "application/epub+zip", "application/epub+zip+epub"},
 		{HTMLType, "text", "html", "html", "text/html", "text/html+html"},
 		{JavascriptType, "application", "javascript", "js", "application/javascript", "application/javascript+js"},
 		{JSONType, "application", "json", "json", "application/json", "application/json+json"},
+		{PDFType, "application", "pdf", "pdf", "application/pdf", "application/pdf+pdf"},
 		{RSSType, "application", "rss", "xml", "application/rss", "application/rss+xml"},
 		{TextType, "text", "plain", "txt", "text/plain", "text/plain+txt"},
 	} {
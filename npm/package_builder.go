@@ -0,0 +1,225 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package npm contains helpers to build a project level NPM package.json
+// from the package.json files provided by the project and its themes.
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+const (
+	// PackageJSONName is the name of the file written by Pack.
+	PackageJSONName = "package.json"
+
+	// PackageHugoJSONName is the name of the optional project file used as
+	// the base for the generated package.json. We need this to be able to
+	// regenerate the package.json without losing the project's own settings.
+	PackageHugoJSONName = "package.hugo.json"
+
+	commentsKey = "comments"
+	projectName = "project"
+)
+
+var dependencyKeys = []string{"dependencies", "devDependencies"}
+
+// Source represents a provider of a package.json file, typically a theme.
+type Source struct {
+	// Name is used in the comments section of the generated file and in
+	// the conflict warnings.
+	Name string
+
+	// Dir is the absolute directory holding the package.json file.
+	Dir string
+}
+
+// Pack writes a package.json to the project directory that contains the
+// union of the dependencies declared by the project and the given sources.
+//
+// The project's dependencies always win. For the sources, the first one
+// declaring a dependency wins, which matches the theme lookup order.
+// Version conflicts are logged as warnings.
+func Pack(fs afero.Fs, logger *jww.Notepad, projectDir string, sources ...Source) error {
+	base, err := readProjectPackage(fs, projectDir)
+	if err != nil {
+		return err
+	}
+
+	b := newPackageBuilder(base, logger)
+
+	for _, s := range sources {
+		m, err := readPackageFile(fs, filepath.Join(s.Dir, PackageJSONName))
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			continue
+		}
+		b.addSource(s.Name, m)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b.build()); err != nil {
+		return err
+	}
+
+	return helpers.WriteToDisk(filepath.Join(projectDir, PackageJSONName), &buf, fs)
+}
+
+func readProjectPackage(fs afero.Fs, projectDir string) (map[string]interface{}, error) {
+	m, err := readPackageFile(fs, filepath.Join(projectDir, PackageHugoJSONName))
+	if err != nil || m != nil {
+		return m, err
+	}
+
+	m, err = readPackageFile(fs, filepath.Join(projectDir, PackageJSONName))
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return make(map[string]interface{}), nil
+	}
+
+	// The existing package.json may have been created by us. Remove any
+	// dependency we have added to make the operation repeatable.
+	comments, ok := m[commentsKey].(map[string]interface{})
+	if !ok {
+		return m, nil
+	}
+
+	for _, key := range dependencyKeys {
+		deps, _ := m[key].(map[string]interface{})
+		from, _ := comments[key].(map[string]interface{})
+		for name, source := range from {
+			if source != projectName {
+				delete(deps, name)
+			}
+		}
+	}
+
+	delete(m, commentsKey)
+
+	return m, nil
+}
+
+func readPackageFile(fs afero.Fs, filename string) (map[string]interface{}, error) {
+	if exists, _ := helpers.Exists(filename, fs); !exists {
+		return nil, nil
+	}
+
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %s", filename, err)
+	}
+
+	return m, nil
+}
+
+type packageBuilder struct {
+	logger *jww.Notepad
+
+	base map[string]interface{}
+
+	// Maps dependency key (e.g. "devDependencies") to name and version.
+	dependencies map[string]map[string]string
+
+	// Maps dependency key to name and source.
+	sources map[string]map[string]string
+}
+
+func newPackageBuilder(base map[string]interface{}, logger *jww.Notepad) *packageBuilder {
+	b := &packageBuilder{
+		logger:       logger,
+		base:         base,
+		dependencies: make(map[string]map[string]string),
+		sources:      make(map[string]map[string]string),
+	}
+
+	for _, key := range dependencyKeys {
+		b.dependencies[key] = make(map[string]string)
+		b.sources[key] = make(map[string]string)
+	}
+
+	b.addSource(projectName, base)
+
+	return b
+}
+
+func (b *packageBuilder) addSource(name string, m map[string]interface{}) {
+	for _, key := range dependencyKeys {
+		deps, ok := m[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Sort to get stable conflict warnings.
+		depNames := make([]string, 0, len(deps))
+		for dep := range deps {
+			depNames = append(depNames, dep)
+		}
+		sort.Strings(depNames)
+
+		for _, dep := range depNames {
+			version := fmt.Sprint(deps[dep])
+			if existing, found := b.dependencies[key][dep]; found {
+				if existing != version && b.logger != nil {
+					b.logger.WARN.Printf("%s: %q version %q in %s conflicts with %q in %s; using %q", key, dep, version, name, existing, b.sources[key][dep], existing)
+				}
+				continue
+			}
+			b.dependencies[key][dep] = version
+			b.sources[key][dep] = name
+		}
+	}
+}
+
+func (b *packageBuilder) build() map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range b.base {
+		m[k] = v
+	}
+
+	comments := make(map[string]interface{})
+
+	for _, key := range dependencyKeys {
+		if len(b.dependencies[key]) == 0 {
+			continue
+		}
+		m[key] = b.dependencies[key]
+		comments[key] = b.sources[key]
+	}
+
+	if len(comments) > 0 {
+		m[commentsKey] = comments
+	}
+
+	return m
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package npm
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectPackage = `{
+  "name": "mysite",
+  "dependencies": {
+    "react": "^16.0.0"
+  },
+  "devDependencies": {
+    "postcss-cli": "^5.0.0"
+  }
+}`
+
+	testThemePackage1 = `{
+  "name": "theme1",
+  "dependencies": {
+    "react": "^15.0.0",
+    "lodash": "^4.17.0"
+  },
+  "devDependencies": {
+    "tailwindcss": "^0.5.0"
+  }
+}`
+
+	testThemePackage2 = `{
+  "devDependencies": {
+    "tailwindcss": "^0.4.0",
+    "autoprefixer": "^8.0.0"
+  }
+}`
+)
+
+func TestPack(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	projectDir := filepath.FromSlash("/mysite")
+	theme1 := filepath.Join(projectDir, "themes", "theme1")
+	theme2 := filepath.Join(projectDir, "themes", "theme2")
+
+	assert.NoError(afero.WriteFile(fs, filepath.Join(projectDir, PackageHugoJSONName), []byte(testProjectPackage), 0755))
+	assert.NoError(afero.WriteFile(fs, filepath.Join(theme1, PackageJSONName), []byte(testThemePackage1), 0755))
+	assert.NoError(afero.WriteFile(fs, filepath.Join(theme2, PackageJSONName), []byte(testThemePackage2), 0755))
+
+	sources := []Source{{Name: "theme1", Dir: theme1}, {Name: "theme2", Dir: theme2}, {Name: "nopackage", Dir: "/nope"}}
+
+	assert.NoError(Pack(fs, nil, projectDir, sources...))
+
+	m := readTestPackage(t, fs, projectDir)
+
+	assert.Equal("mysite", m["name"])
+	assert.Equal(map[string]interface{}{
+		"react":  "^16.0.0",
+		"lodash": "^4.17.0",
+	}, m["dependencies"])
+	assert.Equal(map[string]interface{}{
+		"postcss-cli":  "^5.0.0",
+		"tailwindcss":  "^0.5.0",
+		"autoprefixer": "^8.0.0",
+	}, m["devDependencies"])
+
+	comments := m["comments"].(map[string]interface{})
+	assert.Equal(map[string]interface{}{
+		"react":  "project",
+		"lodash": "theme1",
+	}, comments["dependencies"])
+
+	// Running it again without the package.hugo.json should give the same result.
+	assert.NoError(fs.Remove(filepath.Join(projectDir, PackageHugoJSONName)))
+	assert.NoError(Pack(fs, nil, projectDir, sources...))
+	assert.Equal(m, readTestPackage(t, fs, projectDir))
+}
+
+func readTestPackage(t *testing.T, fs afero.Fs, dir string) map[string]interface{} {
+	b, err := afero.ReadFile(fs, filepath.Join(dir, PackageJSONName))
+	require.NoError(t, err)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
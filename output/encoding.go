@@ -0,0 +1,113 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// The line endings supported in Format.Newline.
+const (
+	NewlineLF   = "lf"
+	NewlineCRLF = "crlf"
+)
+
+// The byte order mark, encoded according to the charset.
+const bom = "\ufeff"
+
+// HasEncoding reports whether the rendered output must be encoded before it
+// is published, see Encode.
+func (f Format) HasEncoding() bool {
+	return f.BOM || strings.EqualFold(f.Newline, NewlineCRLF) || !isUTF8(f.Charset)
+}
+
+// Encode applies the newline, charset and BOM settings of the format to the
+// rendered output, which is UTF-8 with Unix line endings.
+func (f Format) Encode(b []byte) ([]byte, error) {
+	if !f.HasEncoding() {
+		return b, nil
+	}
+
+	if strings.EqualFold(f.Newline, NewlineCRLF) {
+		b = bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+		b = bytes.Replace(b, []byte("\n"), []byte("\r\n"), -1)
+	}
+
+	if f.BOM {
+		b = append([]byte(bom), b...)
+	}
+
+	if isUTF8(f.Charset) {
+		return b, nil
+	}
+
+	enc, err := charsetEncoding(f.Charset)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := enc.NewEncoder().Bytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output format %q as %s: %s", f.Name, f.Charset, err)
+	}
+
+	return encoded, nil
+}
+
+// validateEncoding validates the charset and newline settings.
+func (f Format) validateEncoding() error {
+	switch strings.ToLower(f.Newline) {
+	case "", NewlineLF, NewlineCRLF:
+	default:
+		return fmt.Errorf("invalid newline %q for output format %q, must be %s or %s", f.Newline, f.Name, NewlineLF, NewlineCRLF)
+	}
+
+	if isUTF8(f.Charset) {
+		return nil
+	}
+
+	enc, err := charsetEncoding(f.Charset)
+	if err != nil {
+		return fmt.Errorf("invalid charset for output format %q: %s", f.Name, err)
+	}
+
+	if f.BOM {
+		if _, err := enc.NewEncoder().String(bom); err != nil {
+			return fmt.Errorf("output format %q cannot have a BOM with charset %s", f.Name, f.Charset)
+		}
+	}
+
+	return nil
+}
+
+func isUTF8(charset string) bool {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8":
+		return true
+	}
+	return false
+}
+
+func charsetEncoding(charset string) (encoding.Encoding, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unknown charset %q", charset)
+	}
+	return enc, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/media"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatEncode(t *testing.T) {
+	assert := require.New(t)
+
+	f := Format{Name: "Plain"}
+	assert.False(f.HasEncoding())
+	b, err := f.Encode([]byte("a\nb"))
+	assert.NoError(err)
+	assert.Equal("a\nb", string(b))
+
+	f = Format{Name: "ICS", Newline: "CRLF"}
+	assert.True(f.HasEncoding())
+	b, err = f.Encode([]byte("a\nb\r\nc\n"))
+	assert.NoError(err)
+	assert.Equal("a\r\nb\r\nc\r\n", string(b))
+
+	f = Format{Name: "Excel", BOM: true}
+	b, err = f.Encode([]byte("a,b"))
+	assert.NoError(err)
+	assert.Equal("\xef\xbb\xbfa,b", string(b))
+
+	f = Format{Name: "UTF16", Charset: "utf-16le", BOM: true}
+	b, err = f.Encode([]byte("ab"))
+	assert.NoError(err)
+	assert.Equal([]byte{0xff, 0xfe, 'a', 0, 'b', 0}, b)
+
+	f = Format{Name: "Latin", Charset: "iso-8859-1"}
+	b, err = f.Encode([]byte("é"))
+	assert.NoError(err)
+	assert.Equal([]byte{0xe9}, b)
+
+	_, err = f.Encode([]byte("☃"))
+	assert.Error(err)
+}
+
+func TestDecodeFormatsEncoding(t *testing.T) {
+	assert := require.New(t)
+
+	formats, err := DecodeFormats(media.DefaultTypes, map[string]interface{}{
+		"Calendar": map[string]interface{}{"newline": "crlf"},
+		"ExcelCSV": map[string]interface{}{
+			"mediaType": "text/csv",
+			"bom":       true,
+			"charset":   "utf-8",
+		},
+	})
+	assert.NoError(err)
+
+	f, _ := formats.GetByName("Calendar")
+	assert.Equal("crlf", f.Newline)
+	f, _ = formats.GetByName("ExcelCSV")
+	assert.True(f.BOM)
+
+	for _, invalid := range []map[string]interface{}{
+		{"newline": "cr"},
+		{"charset": "no-such-charset"},
+		{"charset": "windows-1252", "bom": true},
+	} {
+		_, err := DecodeFormats(media.DefaultTypes, map[string]interface{}{"Calendar": invalid})
+		assert.Error(err, invalid)
+	}
+}
@@ -65,6 +65,21 @@ const (
 	layoutsRSSTaxonomy     = `taxonomy/SECTION.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
 	layoutsRSSTaxonomyTerm = `taxonomy/SECTION.terms.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
 
+	// The print templates fall back to the internal print template and not to
+	// the regular HTML templates.
+	layoutsPrintHome    = `index.VARIATIONS _default/list.VARIATIONS _internal/_default/print.html`
+	layoutsPrintSection = `section/SECTION.VARIATIONS SECTION/list.VARIATIONS _default/section.VARIATIONS _default/list.VARIATIONS _internal/_default/print.html`
+	layoutsPrintPage    = "_internal/_default/print.html"
+
+	// The section data templates fall back to the internal JSON template.
+	layoutsSectionDataHome     = `index.VARIATIONS _default/list.VARIATIONS _internal/_default/sectiondata.json`
+	layoutsSectionDataSection  = `section/SECTION.VARIATIONS SECTION/list.VARIATIONS _default/section.VARIATIONS _default/list.VARIATIONS _internal/_default/sectiondata.json`
+	layoutsSectionDataTaxonomy = `taxonomy/SECTION.VARIATIONS _default/taxonomy.VARIATIONS _default/list.VARIATIONS _internal/_default/sectiondata.json`
+
+	// The podcast templates fall back to the internal podcast feed template.
+	layoutsPodcastHome    = `index.VARIATIONS _default/list.VARIATIONS _internal/_default/podcast.xml`
+	layoutsPodcastSection = `section/SECTION.VARIATIONS SECTION/list.VARIATIONS _default/section.VARIATIONS _default/list.VARIATIONS _internal/_default/podcast.xml`
+
 	layoutsHome    = "index.VARIATIONS _default/list.VARIATIONS"
 	layoutsSection = `
 section/SECTION.VARIATIONS
@@ -113,14 +128,44 @@ func (l *LayoutHandler) For(d LayoutDescriptor, layoutOverride string, f Format)
 	}
 
 	isRSS := f.Name == RSSFormat.Name
+	isPrint := f.Name == PrintFormat.Name
+	isSectionData := f.Name == SectionDataFormat.Name
+	isPodcast := f.Name == PodcastFormat.Name
 
 	if d.Kind == "page" {
-		if isRSS {
+		if isRSS || isSectionData || isPodcast {
 			return []string{}, nil
 		}
 		layouts = regularPageLayouts(d.Type, layout, f)
+		if isPrint {
+			var printLayouts []string
+			for _, l := range layouts {
+				if strings.Contains(l, "."+strings.ToLower(f.Name)+".") {
+					printLayouts = append(printLayouts, l)
+				}
+			}
+			layouts = append(printLayouts, layoutsPrintPage)
+		}
 	} else {
-		if isRSS {
+		if isPrint {
+			layouts = resolveListTemplate(d, f,
+				layoutsPrintHome,
+				layoutsPrintSection,
+				"",
+				"")
+		} else if isPodcast {
+			layouts = resolveListTemplate(d, f,
+				layoutsPodcastHome,
+				layoutsPodcastSection,
+				"",
+				"")
+		} else if isSectionData {
+			layouts = resolveListTemplate(d, f,
+				layoutsSectionDataHome,
+				layoutsSectionDataSection,
+				layoutsSectionDataTaxonomy,
+				"")
+		} else if isRSS {
 			layouts = resolveListTemplate(d, f,
 				layoutsRSSHome,
 				layoutsRSSSection,
@@ -200,21 +245,26 @@ func resolveTemplate(templ string, d LayoutDescriptor, f Format) []string {
 	var replacementValues []string
 
 	name := strings.ToLower(f.Name)
+	suffix := f.TemplateSuffix()
 
 	if d.Lang != "" {
-		replacementValues = append(replacementValues, fmt.Sprintf("%s.%s.%s", d.Lang, name, f.MediaType.Suffix))
+		replacementValues = append(replacementValues, fmt.Sprintf("%s.%s.%s", d.Lang, name, suffix))
 	}
 
-	replacementValues = append(replacementValues, fmt.Sprintf("%s.%s", name, f.MediaType.Suffix))
-
-	if d.Lang != "" {
-		replacementValues = append(replacementValues, fmt.Sprintf("%s.%s", d.Lang, f.MediaType.Suffix))
-	}
+	replacementValues = append(replacementValues, fmt.Sprintf("%s.%s", name, suffix))
 
 	isRSS := f.Name == RSSFormat.Name
 
-	if !isRSS {
-		replacementValues = append(replacementValues, f.MediaType.Suffix)
+	// These formats share the template suffix with other formats, so their
+	// templates must be qualified with the format name.
+	qualifiedOnly := f.Name == PrintFormat.Name || f.Name == SectionDataFormat.Name || f.Name == PodcastFormat.Name
+
+	if d.Lang != "" && !qualifiedOnly {
+		replacementValues = append(replacementValues, fmt.Sprintf("%s.%s", d.Lang, suffix))
+	}
+
+	if !isRSS && !qualifiedOnly {
+		replacementValues = append(replacementValues, suffix)
 	}
 
 	var layouts []string
@@ -276,7 +326,7 @@ func regularPageLayouts(types string, layout string, f Format) []string {
 		delimiter = ""
 	}
 
-	suffix := delimiter + f.MediaType.Suffix
+	suffix := delimiter + f.TemplateSuffix()
 	name := strings.ToLower(f.Name)
 
 	if types != "" {
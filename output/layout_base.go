@@ -37,6 +37,11 @@ type TemplateNames struct {
 
 	OverlayFilename string
 	MasterFilename  string
+
+	// The base templates the master template in turn extends, innermost
+	// first. A section baseof with only define blocks may e.g. extend the
+	// _default baseof.
+	MasterBaseFilenames []string
 }
 
 type TemplateLookupDescriptor struct {
@@ -66,6 +71,7 @@ type TemplateLookupDescriptor struct {
 
 	FileExists  func(filename string) (bool, error)
 	ContainsAny func(filename string, subslices [][]byte) (bool, error)
+	ReadFile    func(filename string) ([]byte, error)
 }
 
 func CreateTemplateNames(d TemplateLookupDescriptor) (TemplateNames, error) {
@@ -188,16 +194,34 @@ func CreateTemplateNames(d TemplateLookupDescriptor) (TemplateNames, error) {
 			}
 		}
 
-	Loop:
-		for _, pair := range pairsToCheck {
-			pathsToCheck := basePathsToCheck(pair, baseLayoutDir, baseWorkLayoutDir, baseThemeLayoutDir)
+		i, master := findBaseTemplate(d, pairsToCheck, 0, baseLayoutDir, baseWorkLayoutDir, baseThemeLayoutDir)
+		if master == "" {
+			return id, nil
+		}
+		id.MasterFilename = master
 
-			for _, pathToCheck := range pathsToCheck {
-				if ok, err := d.FileExists(pathToCheck); err == nil && ok {
-					id.MasterFilename = pathToCheck
-					break Loop
-				}
+		if ext == "ace" {
+			// Only one level of base templates in Ace.
+			return id, nil
+		}
+
+		// A base template with nothing but define blocks cannot be
+		// rendered on its own, so it extends the next base template in
+		// the lookup order, e.g. layouts/posts/baseof.html extending
+		// layouts/_default/baseof.html. Any other base template is used as
+		// is, as it always has been.
+		for {
+			b, err := d.ReadFile(master)
+			if err != nil {
+				return id, err
+			}
+			if !isDefinesOnly(string(b)) {
+				break
 			}
+			if i, master = findBaseTemplate(d, pairsToCheck, i+1, baseLayoutDir, baseWorkLayoutDir, baseThemeLayoutDir); master == "" {
+				break
+			}
+			id.MasterBaseFilenames = append(id.MasterBaseFilenames, master)
 		}
 	}
 
@@ -205,6 +229,85 @@ func CreateTemplateNames(d TemplateLookupDescriptor) (TemplateNames, error) {
 
 }
 
+// findBaseTemplate returns the index of the first of the pairs from the
+// given index with an existing base template, and its filename, or -1 and ""
+// if none exists. A pair naming the same template as an earlier one is
+// skipped, so the project's template shadows the theme's with the same path.
+func findBaseTemplate(d TemplateLookupDescriptor, pairs [][]string, from int, layoutDir, workLayoutDir, themeLayoutDir string) (int, string) {
+	for i := from; i < len(pairs); i++ {
+		if containsPair(pairs[:i], pairs[i]) {
+			continue
+		}
+		for _, pathToCheck := range basePathsToCheck(pairs[i], layoutDir, workLayoutDir, themeLayoutDir) {
+			if ok, err := d.FileExists(pathToCheck); err == nil && ok {
+				return i, pathToCheck
+			}
+		}
+	}
+	return -1, ""
+}
+
+func containsPair(pairs [][]string, pair []string) bool {
+	for _, p := range pairs {
+		if filepath.Join(p...) == filepath.Join(pair...) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDefinesOnly reports whether the Go template has nothing but define
+// blocks and comments outside its define blocks.
+func isDefinesOnly(templ string) bool {
+	var depth, defines int
+
+	for {
+		start := strings.Index(templ, "{{")
+		if start == -1 {
+			break
+		}
+		if depth == 0 && strings.TrimSpace(templ[:start]) != "" {
+			return false
+		}
+		end := strings.Index(templ[start:], "}}")
+		if end == -1 {
+			return false
+		}
+		action := strings.TrimSpace(strings.Trim(templ[start+2:start+end], "-"))
+		templ = templ[start+end+2:]
+
+		if strings.HasPrefix(action, "/*") {
+			continue
+		}
+
+		keyword := action
+		if i := strings.IndexAny(action, " \t\r\n("); i != -1 {
+			keyword = action[:i]
+		}
+
+		switch keyword {
+		case "define":
+			if depth == 0 {
+				defines++
+			}
+			depth++
+		case "if", "range", "with", "block":
+			if depth == 0 {
+				return false
+			}
+			depth++
+		case "end":
+			depth--
+		default:
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+
+	return defines > 0 && depth == 0 && strings.TrimSpace(templ) == ""
+}
+
 func createPairsToCheck(baseTemplatedDir, baseFilename, currBaseFilename string) [][]string {
 	return [][]string{
 		{baseTemplatedDir, currBaseFilename},
@@ -14,6 +14,7 @@
 package output
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -162,11 +163,18 @@ func TestLayoutBase(t *testing.T) {
 				return this.needsBase, nil
 			}
 
+			// The base templates do not extend other base templates here,
+			// see TestLayoutBaseChain.
+			readFile := func(filename string) ([]byte, error) {
+				return []byte(`<html>{{ block "main" . }}{{ end }}</html>`), nil
+			}
+
 			this.d.OutputFormats = Formats{AMPFormat, HTMLFormat, RSSFormat, JSONFormat}
 			this.d.WorkingDir = filepath.FromSlash(this.d.WorkingDir)
 			this.d.LayoutDir = filepath.FromSlash(this.d.LayoutDir)
 			this.d.RelPath = filepath.FromSlash(this.d.RelPath)
 			this.d.ContainsAny = needsBase
+			this.d.ReadFile = readFile
 			this.d.FileExists = fileExists
 
 			this.expect.MasterFilename = filepath.FromSlash(this.expect.MasterFilename)
@@ -186,3 +194,90 @@ func TestLayoutBase(t *testing.T) {
 	}
 
 }
+
+func TestLayoutBaseChain(t *testing.T) {
+	t.Parallel()
+
+	var (
+		workingDir     = filepath.FromSlash("/sites/mysite")
+		themeDir       = filepath.FromSlash("/themes/mytheme")
+		layoutDir      = filepath.Join(workingDir, "layouts")
+		themeLayoutDir = filepath.Join(themeDir, "layouts")
+
+		definesOnly = `{{ define "main" }}{{ block "content" . }}{{ end }}{{ end }}`
+		page        = `<html>{{ block "main" . }}{{ end }}</html>`
+	)
+
+	files := map[string]string{
+		filepath.Join(layoutDir, "posts", "single.html"):         `{{ define "content" }}{{ end }}`,
+		filepath.Join(layoutDir, "posts", "baseof.html"):         definesOnly,
+		filepath.Join(themeLayoutDir, "posts", "baseof.html"):    page,
+		filepath.Join(themeLayoutDir, "_default", "baseof.html"): definesOnly,
+	}
+
+	d := TemplateLookupDescriptor{
+		WorkingDir:    workingDir,
+		TemplateDir:   workingDir,
+		LayoutDir:     "layouts",
+		ThemeDir:      themeDir,
+		RelPath:       filepath.FromSlash("posts/single.html"),
+		OutputFormats: Formats{HTMLFormat},
+		FileExists: func(filename string) (bool, error) {
+			_, found := files[filename]
+			return found, nil
+		},
+		ContainsAny: func(filename string, subslices [][]byte) (bool, error) {
+			return strings.Contains(files[filename], "define"), nil
+		},
+		ReadFile: func(filename string) ([]byte, error) {
+			return []byte(files[filename]), nil
+		},
+	}
+
+	// The section baseof in the project shadows the one in the theme. It
+	// has only define blocks, so it extends the theme's _default baseof,
+	// which in turn extends the next one, but there is none.
+	id, err := CreateTemplateNames(d)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(layoutDir, "posts", "baseof.html"), id.MasterFilename)
+	require.Equal(t, []string{
+		filepath.Join(themeLayoutDir, "_default", "baseof.html"),
+	}, id.MasterBaseFilenames)
+
+	// The project's _default baseof shadows the theme's.
+	files[filepath.Join(layoutDir, "_default", "baseof.html")] = page
+
+	id, err = CreateTemplateNames(d)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(layoutDir, "posts", "baseof.html"), id.MasterFilename)
+	require.Equal(t, []string{
+		filepath.Join(layoutDir, "_default", "baseof.html"),
+	}, id.MasterBaseFilenames)
+
+	// A base template with any content outside its define blocks is used
+	// as is, as in existing sites.
+	files[filepath.Join(layoutDir, "posts", "baseof.html")] = `{{ define "header" }}{{ end }}<html>{{ block "main" . }}{{ end }}</html>`
+	id, err = CreateTemplateNames(d)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(layoutDir, "posts", "baseof.html"), id.MasterFilename)
+	require.Empty(t, id.MasterBaseFilenames)
+}
+
+func TestIsDefinesOnly(t *testing.T) {
+	t.Parallel()
+
+	for i, this := range []struct {
+		templ  string
+		expect bool
+	}{
+		{`{{ define "main" }}{{ if .Title }}{{ .Title }}{{ end }}{{ end }}`, true},
+		{"{{/* Docs */}}\n{{- define \"main\" -}}\n  {{ block \"content\" . }}{{ end }}\n{{- end -}}\n{{ define \"footer\" }}{{ end }}\n", true},
+		{`<html>{{ block "main" . }}{{ end }}</html>`, false},
+		{`{{ define "main" }}{{ end }}<footer></footer>`, false},
+		{`{{ define "main" }}{{ end }}{{ partial "footer.html" . }}`, false},
+		{`{{ define "main" }}`, false},
+		{``, false},
+	} {
+		require.Equal(t, this.expect, isDefinesOnly(this.templ), fmt.Sprintf("[%d] %s", i, this.templ))
+	}
+}
@@ -69,6 +69,22 @@ func TestLayout(t *testing.T) {
 			[]string{"_default/single.nem", "theme/_default/single.nem"}},
 		{"Section", LayoutDescriptor{Kind: "section", Section: "sect1"}, false, "", ampType,
 			[]string{"section/sect1.amp.html", "section/sect1.html"}},
+		{"Section, converted format", LayoutDescriptor{Kind: "section", Section: "sect1"}, false, "", PDFFormat,
+			[]string{"section/sect1.pdf.html", "section/sect1.html"}},
+		{"Section, print", LayoutDescriptor{Kind: "section", Section: "sect1", Lang: "fr"}, false, "", PrintFormat,
+			[]string{"section/sect1.fr.print.html", "section/sect1.print.html", "sect1/list.fr.print.html", "sect1/list.print.html", "_default/section.fr.print.html", "_default/section.print.html",
+				"_default/list.fr.print.html", "_default/list.print.html", "_internal/_default/print.html"}},
+		{"Section, section data", LayoutDescriptor{Kind: "section", Section: "sect1"}, false, "", SectionDataFormat,
+			[]string{"_text/section/sect1.sectiondata.json", "_text/sect1/list.sectiondata.json", "_text/_default/section.sectiondata.json",
+				"_text/_default/list.sectiondata.json", "_text/_internal/_default/sectiondata.json"}},
+		{"Page, section data", LayoutDescriptor{Kind: "page"}, false, "", SectionDataFormat, []string{}},
+		{"Section, podcast", LayoutDescriptor{Kind: "section", Section: "sect1"}, false, "", PodcastFormat,
+			[]string{"section/sect1.podcast.xml", "sect1/list.podcast.xml", "_default/section.podcast.xml", "_default/list.podcast.xml", "_internal/_default/podcast.xml"}},
+		{"Page, podcast", LayoutDescriptor{Kind: "page"}, false, "", PodcastFormat, []string{}},
+		{"Page, print", LayoutDescriptor{Kind: "page", Type: "mytype"}, true, "", PrintFormat,
+			[]string{"mytype/single.print.html", "_default/single.print.html", "theme/mytype/single.print.html", "theme/_default/single.print.html", "_internal/_default/print.html"}},
+		{"Page, converted format", LayoutDescriptor{Kind: "page"}, false, "", EPUBFormat,
+			[]string{"_default/single.epub.html", "_default/single.html"}},
 		{"Taxonomy", LayoutDescriptor{Kind: "taxonomy", Section: "tag"}, false, "", ampType,
 			[]string{"taxonomy/tag.amp.html", "taxonomy/tag.html"}},
 		{"Taxonomy term", LayoutDescriptor{Kind: "taxonomyTerm", Section: "categories"}, false, "", ampType,
@@ -69,6 +69,25 @@ type Format struct {
 	// Note that we use the term "alternative" and not "alternate" here, as it
 	// does not necessarily replace the other format, it is an alternative representation.
 	NotAlternative bool `json:"notAlternative"`
+
+	// Converter is an external command that converts the rendered HTML into
+	// this format, e.g. PDF. The HTML is written to its stdin and the result
+	// is read from its stdout. Templates for formats with a converter are HTML
+	// templates, e.g. "single.pdf.html", falling back to the plain HTML ones.
+	Converter string `json:"converter"`
+
+	// Charset is the character encoding of the published files, e.g.
+	// "utf-16le" or "windows-1252". The templates are always executed as
+	// UTF-8. Defaults to UTF-8.
+	Charset string `json:"charset"`
+
+	// Enable to start the published files with a byte order mark, e.g. for
+	// CSV files opened in Excel. Requires a Unicode charset.
+	BOM bool `json:"bom"`
+
+	// The line endings in the published files, "lf" (default) or "crlf",
+	// e.g. for iCalendar files.
+	Newline string `json:"newline"`
 }
 
 var (
@@ -109,6 +128,16 @@ var (
 		Rel:         "alternate",
 	}
 
+	EPUBFormat = Format{
+		Name:           "EPUB",
+		MediaType:      media.EPUBType,
+		BaseName:       "index",
+		Rel:            "alternate",
+		NoUgly:         true,
+		NotAlternative: true,
+		Converter:      "pandoc --from html --to epub3 --output -",
+	}
+
 	HTMLFormat = Format{
 		Name:      "HTML",
 		MediaType: media.HTMLType,
@@ -125,6 +154,48 @@ var (
 		Rel:         "alternate",
 	}
 
+	PDFFormat = Format{
+		Name:           "PDF",
+		MediaType:      media.PDFType,
+		BaseName:       "index",
+		Rel:            "alternate",
+		NoUgly:         true,
+		NotAlternative: true,
+		Converter:      "wkhtmltopdf --quiet - -",
+	}
+
+	// PodcastFormat renders a section of episode bundles as a podcast feed
+	// with the iTunes tags. Enable it for a section by adding "Podcast" to its
+	// outputs.
+	PodcastFormat = Format{
+		Name:      "Podcast",
+		MediaType: media.RSSType,
+		BaseName:  "podcast",
+		NoUgly:    true,
+		Rel:       "alternate",
+	}
+
+	// PrintFormat renders a section and all of its descendants as one HTML
+	// document. Enable it for a section by adding "Print" to its outputs.
+	PrintFormat = Format{
+		Name:      "Print",
+		MediaType: media.HTMLType,
+		BaseName:  "print",
+		Rel:       "alternate",
+		IsHTML:    true,
+	}
+
+	// SectionDataFormat renders the pages in a section as JSON, e.g. for
+	// client-side navigation. Enable it with sectionData in site config or in
+	// a section's front matter.
+	SectionDataFormat = Format{
+		Name:        "SectionData",
+		MediaType:   media.JSONType,
+		BaseName:    "pages",
+		IsPlainText: true,
+		Rel:         "alternate",
+	}
+
 	RSSFormat = Format{
 		Name:      "RSS",
 		MediaType: media.RSSType,
@@ -139,9 +210,14 @@ var DefaultFormats = Formats{
 	CalendarFormat,
 	CSSFormat,
 	CSVFormat,
+	EPUBFormat,
 	HTMLFormat,
 	JSONFormat,
+	PDFFormat,
+	PodcastFormat,
+	PrintFormat,
 	RSSFormat,
+	SectionDataFormat,
 }
 
 func init() {
@@ -244,6 +320,9 @@ func DecodeFormats(mediaTypes media.Types, maps ...map[string]interface{}) (Form
 					if err := decode(mediaTypes, v, &f[i]); err != nil {
 						return f, err
 					}
+					if err := f[i].validateEncoding(); err != nil {
+						return f, err
+					}
 					found = true
 				}
 			}
@@ -253,6 +332,9 @@ func DecodeFormats(mediaTypes media.Types, maps ...map[string]interface{}) (Form
 				if err := decode(mediaTypes, v, &newOutFormat); err != nil {
 					return f, err
 				}
+				if err := newOutFormat.validateEncoding(); err != nil {
+					return f, err
+				}
 
 				// We need values for these
 				if newOutFormat.BaseName == "" {
@@ -312,6 +394,15 @@ func decode(mediaTypes media.Types, input, output interface{}) error {
 	return decoder.Decode(input)
 }
 
+// TemplateSuffix returns the file suffix of the templates used to render this
+// format, i.e. "html" for formats converted from HTML.
+func (formats Format) TemplateSuffix() string {
+	if formats.Converter != "" {
+		return HTMLFormat.MediaType.Suffix
+	}
+	return formats.MediaType.Suffix
+}
+
 func (formats Format) BaseFilename() string {
 	return formats.BaseName + "." + formats.MediaType.Suffix
 }
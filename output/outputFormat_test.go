@@ -64,6 +64,34 @@ func TestDefaultTypes(t *testing.T) {
 	require.True(t, RSSFormat.NoUgly)
 	require.False(t, CalendarFormat.IsHTML)
 
+	require.Equal(t, "PDF", PDFFormat.Name)
+	require.Equal(t, media.PDFType, PDFFormat.MediaType)
+	require.False(t, PDFFormat.IsPlainText)
+	require.False(t, PDFFormat.IsHTML)
+	require.NotEmpty(t, PDFFormat.Converter)
+	require.Equal(t, "html", PDFFormat.TemplateSuffix())
+
+	require.Equal(t, "Print", PrintFormat.Name)
+	require.Equal(t, media.HTMLType, PrintFormat.MediaType)
+	require.Equal(t, "print", PrintFormat.BaseName)
+	require.True(t, PrintFormat.IsHTML)
+
+	require.Equal(t, "Podcast", PodcastFormat.Name)
+	require.Equal(t, media.RSSType, PodcastFormat.MediaType)
+	require.Equal(t, "podcast", PodcastFormat.BaseName)
+	require.True(t, PodcastFormat.NoUgly)
+
+	require.Equal(t, "SectionData", SectionDataFormat.Name)
+	require.Equal(t, media.JSONType, SectionDataFormat.MediaType)
+	require.Equal(t, "pages", SectionDataFormat.BaseName)
+	require.True(t, SectionDataFormat.IsPlainText)
+
+	require.Equal(t, "EPUB", EPUBFormat.Name)
+	require.Equal(t, media.EPUBType, EPUBFormat.MediaType)
+	require.NotEmpty(t, EPUBFormat.Converter)
+	require.Equal(t, "html", EPUBFormat.TemplateSuffix())
+	require.Equal(t, "xml", RSSFormat.TemplateSuffix())
+
 }
 
 func TestGetFormatByName(t *testing.T) {
@@ -0,0 +1,252 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tomlKeyRe     = regexp.MustCompile(`^(?:[\w.-]+|"[^"]*"|'[^']*')\s*=`)
+	yamlKeyRe     = regexp.MustCompile(`^(?:[\w.\- ]+|"[^"]*"|'[^']*')\s*:(?:\s|$)`)
+	errorLineRe   = regexp.MustCompile(`\bline (\d+)`)
+	errorColumnRe = regexp.MustCompile(`\bcolumn (\d+)`)
+	errorPosRe    = regexp.MustCompile(`\bline \d+(?:, column \d+)?:?`)
+)
+
+// FrontMatterError is returned for front matter that cannot be parsed, with
+// the position of the error in the content file.
+type FrontMatterError struct {
+	// The front matter format, e.g. "yaml".
+	Format string
+
+	// The line and column, starting at 1, in the content file. The column is
+	// 0 if not known, the line if neither.
+	Line   int
+	Column int
+
+	// The text at the error, the line if the column is not known.
+	Token string
+
+	Err error
+}
+
+func (e *FrontMatterError) Error() string {
+	var pos string
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		pos = fmt.Sprintf(" at line %d, column %d", e.Line, e.Column)
+	case e.Line > 0:
+		pos = fmt.Sprintf(" at line %d", e.Line)
+	}
+
+	var near string
+	if e.Token != "" {
+		near = fmt.Sprintf(" near %q", e.Token)
+	}
+
+	return fmt.Sprintf("failed to parse %s front matter%s%s: %s", strings.ToUpper(e.Format), pos, near, cleanFrontMatterError(e.Err))
+}
+
+// cleanFrontMatterError removes the format prefix and the position from the
+// parser's error message, both are in the FrontMatterError.
+func cleanFrontMatterError(err error) string {
+	msg := err.Error()
+	msg = strings.TrimPrefix(msg, "yaml: ")
+	if i := strings.Index(msg, "): "); strings.HasPrefix(msg, "Near line") && i != -1 {
+		// TOML, e.g. "Near line 2 (last key parsed 'title'): ..."
+		msg = msg[i+3:]
+	}
+	msg = errorPosRe.ReplaceAllString(msg, "")
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+// DetectFrontMatterFormat detects the format of the front matter, with or
+// without its delimiters, by its content, e.g. "title: ..." for YAML. It
+// returns "yaml", "toml", "json", "org" or an empty string if not known.
+func DetectFrontMatterFormat(fm []byte) string {
+	fm, _ = trimFrontMatterDelims(fm)
+
+	for _, line := range strings.Split(string(fm), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#+"):
+			return "org"
+		case strings.HasPrefix(line, "#"):
+			// A comment in both YAML and TOML.
+			continue
+		case strings.HasPrefix(line, "{"):
+			return "json"
+		case strings.HasPrefix(line, "["):
+			return "toml"
+		case tomlKeyRe.MatchString(line):
+			return "toml"
+		case yamlKeyRe.MatchString(line), strings.HasPrefix(line, "- "):
+			return "yaml"
+		}
+		return ""
+	}
+
+	return ""
+}
+
+// trimFrontMatterDelims removes the YAML or TOML delimiters, if any, from the
+// front matter and returns the number of lines removed from the start.
+func trimFrontMatterDelims(fm []byte) ([]byte, int) {
+	var delim []byte
+	switch {
+	case bytes.HasPrefix(fm, []byte(YAMLDelim)):
+		delim = []byte(YAMLDelim)
+	case bytes.HasPrefix(fm, []byte(TOMLDelim)):
+		delim = []byte(TOMLDelim)
+	default:
+		return fm, 0
+	}
+
+	i := bytes.IndexByte(fm, '\n')
+	if i == -1 {
+		return nil, 0
+	}
+	body := fm[i+1:]
+
+	trimmed := bytes.TrimRight(body, " \r\n")
+	if bytes.HasSuffix(trimmed, delim) {
+		trimmed = trimmed[:len(trimmed)-len(delim)]
+		if len(trimmed) == 0 || trimmed[len(trimmed)-1] == '\n' {
+			body = trimmed
+		}
+	}
+
+	return body, 1
+}
+
+// parseFrontMatter parses the front matter, starting at the given line in
+// the content file, in the format detected by its content or, if not known,
+// its delimiters.
+func parseFrontMatter(fm []byte, line int) (interface{}, error) {
+	format := DetectFrontMatterFormat(fm)
+	if format == "" {
+		format = FormatSanitize(leadFormat(fm[0]))
+	}
+
+	var (
+		meta   interface{}
+		err    error
+		offset int
+		data   = fm
+	)
+
+	switch format {
+	case "json":
+		meta, err = HandleJSONMetaData(fm)
+	case "org":
+		meta, err = HandleOrgMetaData(fm)
+	default:
+		data, offset = trimFrontMatterDelims(fm)
+		if format == "yaml" {
+			meta, err = HandleYAMLMetaData(data)
+		} else {
+			meta, err = HandleTOMLMetaData(data)
+		}
+	}
+
+	if err != nil {
+		return meta, newFrontMatterError(format, data, line+offset, err)
+	}
+
+	return meta, nil
+}
+
+func leadFormat(lead byte) string {
+	switch lead {
+	case YAMLLead[0]:
+		return "yaml"
+	case JSONLead[0]:
+		return "json"
+	case '#':
+		return "org"
+	default:
+		return "toml"
+	}
+}
+
+// newFrontMatterError creates a FrontMatterError for the parser error, with
+// its position in data, starting at the given line, if found.
+func newFrontMatterError(format string, data []byte, line int, err error) *FrontMatterError {
+	fe := &FrontMatterError{Format: format, Err: err}
+
+	lines := strings.Split(string(data), "\n")
+
+	lineInData, col := 0, 0
+
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		lineInData, col = offsetToPosition(data, e.Offset)
+	case *json.UnmarshalTypeError:
+		lineInData, col = offsetToPosition(data, e.Offset)
+	default:
+		if m := errorLineRe.FindStringSubmatch(err.Error()); m != nil {
+			lineInData, _ = strconv.Atoi(m[1])
+		}
+		if m := errorColumnRe.FindStringSubmatch(err.Error()); m != nil {
+			col, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if lineInData < 1 || lineInData > len(lines) {
+		return fe
+	}
+
+	fe.Line = line + lineInData - 1
+	fe.Column = col
+
+	text := strings.TrimRight(lines[lineInData-1], "\r")
+	if col > 0 && col <= len(text) {
+		fe.Token = frontMatterToken(text[col-1:])
+	} else {
+		fe.Token = strings.TrimSpace(text)
+	}
+
+	return fe
+}
+
+// offsetToPosition returns the line and column, starting at 1, of the byte
+// before the offset, where the JSON decoder reports the error.
+func offsetToPosition(data []byte, offset int64) (int, int) {
+	if offset < 1 || offset > int64(len(data)) {
+		return 0, 0
+	}
+	before := data[:offset-1]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// frontMatterToken returns the token at the start of s.
+func frontMatterToken(s string) string {
+	if i := strings.IndexAny(s, " \t,:;]}"); i > 0 {
+		return s[:i]
+	}
+	if len(s) > 0 && strings.ContainsAny(s[:1], ",:;]}") {
+		return s[:1]
+	}
+	return s
+}
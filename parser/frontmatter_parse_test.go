@@ -0,0 +1,96 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFrontMatterFormat(t *testing.T) {
+	for i, test := range []struct {
+		fm     string
+		expect string
+	}{
+		{"---\ntitle: A\n---\n", "yaml"},
+		{"---\n# A comment\ntitle = \"A\"\n---\n", "toml"},
+		{"+++\ntitle: A\n+++\n", "yaml"},
+		{"+++\n[params]\na = 1\n+++\n", "toml"},
+		{"+++\n\"my key\" = 1\n+++\n", "toml"},
+		{"---\n- a\n---\n", "yaml"},
+		{"{\"title\": \"A\"}", "json"},
+		{"#+TITLE: A\n", "org"},
+		{"---\n---\n", ""},
+		{"---\n!!\n---\n", ""},
+	} {
+		require.Equal(t, test.expect, DetectFrontMatterFormat([]byte(test.fm)), "[%d]", i)
+	}
+}
+
+func TestMetadataDetectedByContent(t *testing.T) {
+	for i, test := range []struct {
+		content string
+		expect  map[string]interface{}
+	}{
+		{"---\ntitle = \"TOML\"\n---\nContent", map[string]interface{}{"title": "TOML"}},
+		{"+++\ntitle: YAML\n+++\nContent", map[string]interface{}{"title": "YAML"}},
+		{"<!--\n---\ntitle: HTML\n---\n-->\n<p>Content</p>", map[string]interface{}{"title": "HTML"}},
+		{"<!--\n+++\ntitle = \"HTML\"\n+++\n-->", map[string]interface{}{"title": "HTML"}},
+	} {
+		p, err := ReadFrom(strings.NewReader(test.content))
+		require.NoError(t, err, "[%d]", i)
+		meta, err := p.Metadata()
+		require.NoError(t, err, "[%d]", i)
+		require.Equal(t, test.expect, meta, "[%d]", i)
+		require.NotContains(t, string(p.Content()), "-->", "[%d]", i)
+	}
+}
+
+func TestFrontMatterError(t *testing.T) {
+	for i, test := range []struct {
+		content string
+		line    int
+		column  int
+		token   string
+		msg     string
+	}{
+		{"---\ntitle: A\n  b: c\n---\n", 2, 0, "title: A", `failed to parse YAML front matter at line 2 near "title: A": mapping values are not allowed in this context`},
+		{"\n\n<!--\n---\ntitle: A\ntags: [a, b\n---\n-->\n", 0, 0, "", ""},
+		{"+++\ntitle = \"A\"\ndate = 2018-13-45\n+++\n", 3, 0, "date = 2018-13-45", ""},
+		{"{\n  \"title\": \"A\",\n  \"tags\": [\"a\" \"b\"]\n}\n", 3, 16, `"b"`, ""},
+	} {
+		p, err := ReadFrom(strings.NewReader(test.content))
+		require.NoError(t, err, "[%d]", i)
+		_, err = p.Metadata()
+		require.Error(t, err, "[%d]", i)
+
+		fe, ok := err.(*FrontMatterError)
+		require.True(t, ok, "[%d] %T", i, err)
+
+		if test.line == 0 {
+			// The YAML parser reports the end of the unclosed flow sequence.
+			require.True(t, fe.Line >= 6, "[%d] %d", i, fe.Line)
+			continue
+		}
+
+		require.Equal(t, test.line, fe.Line, "[%d]", i)
+		require.Equal(t, test.column, fe.Column, "[%d]", i)
+		require.Equal(t, test.token, fe.Token, "[%d]", i)
+		if test.msg != "" {
+			require.Equal(t, test.msg, fe.Error(), "[%d]", i)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"regexp"
 	"strings"
 	"unicode"
@@ -82,6 +83,9 @@ type page struct {
 	render      bool
 	frontmatter []byte
 	content     []byte
+
+	// The line in the content file the front matter starts at.
+	frontmatterLine int
 }
 
 // Content returns the raw page content.
@@ -99,25 +103,27 @@ func (p *page) IsRenderable() bool {
 	return p.render
 }
 
-// Metadata returns the unmarshalled frontmatter data.
+// Metadata returns the unmarshalled frontmatter data. The format is detected
+// by the front matter's content, e.g. TOML in YAML delimiters, or, if not
+// known, its delimiters. Errors are returned as *FrontMatterError.
 func (p *page) Metadata() (meta interface{}, err error) {
 	frontmatter := p.FrontMatter()
 
 	if len(frontmatter) != 0 {
-		fm := DetectFrontMatter(rune(frontmatter[0]))
-		if fm != nil {
-			meta, err = fm.Parse(frontmatter)
-			if err != nil {
-				return
-			}
-		}
+		meta, err = parseFrontMatter(frontmatter, p.frontmatterLine)
 	}
 	return
 }
 
 // ReadFrom reads the content from an io.Reader and constructs a page.
 func ReadFrom(r io.Reader) (p Page, err error) {
-	reader := bufio.NewReader(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bytes.NewReader(data)
+	reader := bufio.NewReader(br)
 
 	// chomp BOM and assume UTF-8
 	if err = chompBOM(reader); err != nil && err != io.EOF {
@@ -138,6 +144,10 @@ func ReadFrom(r io.Reader) (p Page, err error) {
 	newp := new(page)
 	newp.render = shouldRender(firstLine)
 
+	// The position of the front matter in the content file.
+	start := len(data) - br.Len() - reader.Buffered()
+	newp.frontmatterLine = bytes.Count(data[:start], []byte("\n")) + 1
+
 	if newp.render && isFrontMatterDelim(firstLine) {
 		left, right := determineDelims(firstLine)
 		fm, err := extractFrontMatterDelims(reader, left, right)
@@ -195,8 +205,10 @@ func chompWhitespace(r io.RuneScanner) (err error) {
 // comment is found, it is read from r and then whitespace is trimmed from the
 // beginning of r.
 func chompFrontmatterStartComment(r *bufio.Reader) (err error) {
+	// Short content, e.g. a HTML file with just a front matter comment,
+	// returns less than asked for and io.EOF.
 	candidate, err := r.Peek(32)
-	if err != nil {
+	if err != nil && (err != io.EOF || len(candidate) == 0) {
 		return err
 	}
 
@@ -226,13 +238,18 @@ func chompFrontmatterStartComment(r *bufio.Reader) (err error) {
 // chompFrontmatterEndComment checks r for a trailing HTML comment.
 func chompFrontmatterEndComment(r *bufio.Reader) (err error) {
 	candidate, err := r.Peek(32)
-	if err != nil {
+	if err != nil && (err != io.EOF || len(candidate) == 0) {
 		return err
 	}
 
 	str := string(candidate)
 	lineEnd := strings.IndexAny(str, "\n")
 	if lineEnd == -1 {
+		if err == io.EOF && strings.TrimSpace(str) == HTMLCommentEnd {
+			// The comment ends the content.
+			_, err = r.Discard(len(str))
+			return err
+		}
 		return nil
 	}
 	testStr := strings.TrimSuffix(str[0:lineEnd], "\r")
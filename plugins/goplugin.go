@@ -0,0 +1,77 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+)
+
+// goPlugin is a Go plugin, built with go build -buildmode=plugin. It
+// exports its template functions and content converters in the variables
+//
+//   var HugoFuncs = map[string]interface{}{...}
+//   var HugoConverters = map[string]func(content []byte) ([]byte, error){...}
+//
+// Go plugins are only supported on Linux and macOS.
+type goPlugin struct {
+	name       string
+	funcs      map[string]interface{}
+	converters map[string]func(content []byte) ([]byte, error)
+}
+
+func openGoPlugin(c Config, workingDir string) (*goPlugin, error) {
+	path := c.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+
+	pl, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &goPlugin{name: c.Name}
+
+	if sym, err := pl.Lookup("HugoFuncs"); err == nil {
+		funcs, ok := sym.(*map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("HugoFuncs is a %T, expected a map[string]interface{}", sym)
+		}
+		p.funcs = *funcs
+	}
+
+	if sym, err := pl.Lookup("HugoConverters"); err == nil {
+		converters, ok := sym.(*map[string]func(content []byte) ([]byte, error))
+		if !ok {
+			return nil, fmt.Errorf("HugoConverters is a %T, expected a map[string]func([]byte) ([]byte, error)", sym)
+		}
+		p.converters = *converters
+	}
+
+	return p, nil
+}
+
+func (p *goPlugin) Name() string {
+	return p.name
+}
+
+func (p *goPlugin) Funcs() map[string]interface{} {
+	return p.funcs
+}
+
+func (p *goPlugin) Converters() map[string]func(content []byte) ([]byte, error) {
+	return p.converters
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugins runs the plugins declared in the site config. Plugins
+// register template functions and content converters, and are either
+// external programs talking JSON over stdin and stdout, or Go plugins.
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/security"
+	"github.com/mitchellh/mapstructure"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Config declares a plugin in the plugins section of the site config, e.g.:
+//
+//   [[plugins]]
+//   name = "textile"
+//   command = "hugo-textile"
+//
+//   [[plugins]]
+//   name = "funcs"
+//   path = "plugins/funcs.so"
+type Config struct {
+	// The name of the plugin, used in the security.plugins.allow list.
+	Name string
+
+	// The program to start for an external plugin, looked up in $PATH if
+	// not a path, and its arguments.
+	Command string
+	Args    []string
+
+	// Additional environment variables for the program, e.g. "KEY=value".
+	Env []string
+
+	// The path to a Go plugin, relative to the working dir.
+	Path string
+}
+
+// Plugin is a started plugin.
+type Plugin interface {
+	// Name returns the name of the plugin as declared in the site config.
+	Name() string
+
+	// Funcs returns the template functions of the plugin by name.
+	Funcs() map[string]interface{}
+
+	// Converters returns the content converters of the plugin by markup
+	// format. They convert the content to HTML.
+	Converters() map[string]func(content []byte) ([]byte, error)
+}
+
+// DecodeConfig decodes the plugins section of the site config.
+func DecodeConfig(in interface{}) ([]Config, error) {
+	var configs []Config
+
+	if in == nil {
+		return configs, nil
+	}
+
+	if err := mapstructure.WeakDecode(in, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode plugins config: %s", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("invalid plugin: name must be set")
+		}
+		if (c.Command == "") == (c.Path == "") {
+			return nil, fmt.Errorf("invalid plugin %q: either command or path must be set", c.Name)
+		}
+		if seen[strings.ToLower(c.Name)] {
+			return nil, fmt.Errorf("duplicate plugin %q", c.Name)
+		}
+		seen[strings.ToLower(c.Name)] = true
+	}
+
+	return configs, nil
+}
+
+var (
+	// The started plugins, shared by the sites and kept running between
+	// rebuilds.
+	started   = make(map[string]Plugin)
+	startedMu sync.Mutex
+)
+
+// Load starts the plugins declared in the site config, or returns them if
+// already started with the same settings. Plugins not listed in
+// security.plugins.allow are an error.
+func Load(cfg config.Provider) ([]Plugin, error) {
+	configs, err := DecodeConfig(cfg.Get("plugins"))
+	if err != nil || len(configs) == 0 {
+		return nil, err
+	}
+
+	sec, err := security.DecodeConfig(cfg.Get("security"))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if sec.Plugins.Timeout != "" {
+		timeout, _ = time.ParseDuration(sec.Plugins.Timeout)
+	}
+
+	workingDir := cfg.GetString("workingDir")
+
+	startedMu.Lock()
+	defer startedMu.Unlock()
+
+	var plugins []Plugin
+
+	for _, c := range configs {
+		if !sec.Plugins.IsAllowed(c.Name) {
+			return nil, fmt.Errorf("plugin %q is not allowed, see security.plugins.allow", c.Name)
+		}
+
+		key := pluginKey(c, workingDir, sec.Plugins)
+		if p, found := started[key]; found && !isStopped(p) {
+			plugins = append(plugins, p)
+			continue
+		}
+
+		var p Plugin
+		if c.Path != "" {
+			if !sec.Plugins.AllowGoPlugins {
+				return nil, fmt.Errorf("plugin %q is a Go plugin, which are not allowed, see security.plugins.allowGoPlugins", c.Name)
+			}
+			p, err = openGoPlugin(c, workingDir)
+		} else {
+			p, err = startProcess(c, workingDir, sec.Plugins.Env, timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to start plugin %q: %s", c.Name, err)
+		}
+
+		started[key] = p
+		plugins = append(plugins, p)
+	}
+
+	return plugins, nil
+}
+
+// StopAll stops the started plugin processes.
+func StopAll() {
+	startedMu.Lock()
+	defer startedMu.Unlock()
+
+	for key, p := range started {
+		if pp, ok := p.(*processPlugin); ok {
+			pp.stop()
+		}
+		delete(started, key)
+	}
+}
+
+func isStopped(p Plugin) bool {
+	pp, ok := p.(*processPlugin)
+	return ok && pp.isStopped()
+}
+
+func pluginKey(c Config, workingDir string, sec security.Plugins) string {
+	env := append([]string(nil), sec.Env...)
+	sort.Strings(env)
+	return strings.Join([]string{
+		c.Name, c.Command, strings.Join(c.Args, "\x00"), strings.Join(c.Env, "\x00"), c.Path,
+		workingDir, strings.Join(env, "\x00"), fmt.Sprint(sec.Timeout),
+	}, "\x01")
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess is not a real test. It is started by the tests below as
+// a plugin process.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("HUGO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Println("invalid")
+			continue
+		}
+
+		var (
+			result interface{}
+			errMsg string
+		)
+
+		switch req.Method {
+		case "describe":
+			result = description{Funcs: []string{"double", "env", "sleep"}, Converters: []string{"shout"}}
+		case "call":
+			switch req.Name {
+			case "double":
+				if len(req.Args) != 1 {
+					errMsg = "double takes one argument"
+				} else {
+					result = req.Args[0].(float64) * 2
+				}
+			case "env":
+				result = os.Getenv(req.Args[0].(string))
+			case "sleep":
+				time.Sleep(time.Second)
+			}
+		case "convert":
+			result = "<p>" + strings.ToUpper(req.Content) + "</p>"
+		}
+
+		b, _ := json.Marshal(map[string]interface{}{"id": req.ID, "result": result, "error": errMsg})
+		fmt.Println(string(b))
+	}
+}
+
+func newTestConfig(security map[string]interface{}) *viper.Viper {
+	v := viper.New()
+	v.Set("plugins", []map[string]interface{}{
+		{
+			"name":    "test",
+			"command": os.Args[0],
+			"args":    []string{"-test.run=TestHelperProcess"},
+			"env":     []string{"HUGO_WANT_HELPER_PROCESS=1", "HUGO_PLUGIN_VAR=set"},
+		},
+	})
+	if _, found := security["allow"]; !found {
+		security["allow"] = []string{"test"}
+	}
+	v.Set("security", map[string]interface{}{"plugins": security})
+	return v
+}
+
+func TestProcessPlugin(t *testing.T) {
+	assert := require.New(t)
+	defer StopAll()
+
+	os.Setenv("HUGO_TEST_SECRET", "secret")
+	defer os.Unsetenv("HUGO_TEST_SECRET")
+
+	ps, err := Load(newTestConfig(map[string]interface{}{"timeout": "200ms"}))
+	assert.NoError(err)
+	assert.Len(ps, 1)
+	assert.Equal("test", ps[0].Name())
+
+	funcs := ps[0].Funcs()
+	assert.Len(funcs, 3)
+
+	double := funcs["double"].(func(args ...interface{}) (interface{}, error))
+	v, err := double(21)
+	assert.NoError(err)
+	assert.Equal(float64(42), v)
+
+	_, err = double()
+	assert.EqualError(err, `plugin "test": double failed: double takes one argument`)
+
+	// Only the allowed variables are passed on.
+	env := funcs["env"].(func(args ...interface{}) (interface{}, error))
+	v, err = env("HUGO_PLUGIN_VAR")
+	assert.NoError(err)
+	assert.Equal("set", v)
+	v, err = env("HUGO_TEST_SECRET")
+	assert.NoError(err)
+	assert.Equal("", v)
+
+	shout := ps[0].Converters()["shout"]
+	b, err := shout([]byte("hello"))
+	assert.NoError(err)
+	assert.Equal("<p>HELLO</p>", string(b))
+
+	// Loading again returns the running plugin.
+	ps2, err := Load(newTestConfig(map[string]interface{}{"timeout": "200ms"}))
+	assert.NoError(err)
+	assert.True(ps[0] == ps2[0])
+
+	// A timeout stops the process.
+	sleep := funcs["sleep"].(func(args ...interface{}) (interface{}, error))
+	_, err = sleep()
+	assert.Error(err)
+	assert.Contains(err.Error(), "no response within 200ms")
+	_, err = double(1)
+	assert.Error(err)
+
+	// And it is restarted on the next load.
+	ps2, err = Load(newTestConfig(map[string]interface{}{"timeout": "200ms"}))
+	assert.NoError(err)
+	assert.False(ps[0] == ps2[0])
+}
+
+func TestProcessPluginAllowedEnv(t *testing.T) {
+	assert := require.New(t)
+	defer StopAll()
+
+	os.Setenv("HUGO_TEST_SECRET", "secret")
+	defer os.Unsetenv("HUGO_TEST_SECRET")
+
+	ps, err := Load(newTestConfig(map[string]interface{}{"env": []string{"HUGO_TEST_SECRET"}}))
+	assert.NoError(err)
+
+	v, err := ps[0].Funcs()["env"].(func(args ...interface{}) (interface{}, error))("HUGO_TEST_SECRET")
+	assert.NoError(err)
+	assert.Equal("secret", v)
+}
+
+func TestLoadNotAllowed(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := Load(newTestConfig(map[string]interface{}{"allow": []string{"other"}}))
+	assert.Error(err)
+	assert.Contains(err.Error(), "not allowed")
+
+	// Plugins are not allowed by default.
+	v := newTestConfig(map[string]interface{}{})
+	v.Set("security", nil)
+	_, err = Load(v)
+	assert.Error(err)
+	assert.Contains(err.Error(), `plugin "test" is not allowed`)
+
+	v = viper.New()
+	v.Set("security", map[string]interface{}{"plugins": map[string]interface{}{"allow": []string{"go", "missing"}}})
+	v.Set("plugins", []map[string]interface{}{{"name": "go", "path": "plugin.so"}})
+	_, err = Load(v)
+	assert.Error(err)
+	assert.Contains(err.Error(), "allowGoPlugins")
+
+	v.Set("plugins", []map[string]interface{}{{"name": "missing", "command": "hugo-does-not-exist"}})
+	_, err = Load(v)
+	assert.Error(err)
+
+	ps, err := Load(viper.New())
+	assert.NoError(err)
+	assert.Empty(ps)
+}
+
+func TestDecodeConfig(t *testing.T) {
+	assert := require.New(t)
+
+	configs, err := DecodeConfig([]map[string]interface{}{
+		{"name": "a", "command": "cmd", "args": []string{"-v"}},
+		{"name": "b", "path": "b.so"},
+	})
+	assert.NoError(err)
+	assert.Len(configs, 2)
+	assert.Equal([]string{"-v"}, configs[0].Args)
+	assert.Equal("b.so", configs[1].Path)
+
+	for _, invalid := range []interface{}{
+		[]map[string]interface{}{{"command": "cmd"}},
+		[]map[string]interface{}{{"name": "a"}},
+		[]map[string]interface{}{{"name": "a", "command": "cmd", "path": "a.so"}},
+		[]map[string]interface{}{{"name": "a", "command": "cmd"}, {"name": "A", "command": "cmd"}},
+		"invalid",
+	} {
+		_, err := DecodeConfig(invalid)
+		assert.Error(err, fmt.Sprint(invalid))
+	}
+}
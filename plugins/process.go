@@ -0,0 +1,277 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The environment variables always passed on to plugin processes.
+var defaultEnv = []string{"PATH", "HOME", "SYSTEMROOT", "TMPDIR", "TEMP", "TMP"}
+
+// The protocol of the external plugins is JSON over stdin and stdout, one
+// request and one response per line, e.g.:
+//
+//   {"id":1,"method":"describe"}
+//   {"id":1,"result":{"funcs":["double"],"converters":["textile"]}}
+//   {"id":2,"method":"call","name":"double","args":[21]}
+//   {"id":2,"result":42}
+//   {"id":3,"method":"convert","name":"textile","content":"h1. Title"}
+//   {"id":3,"result":"<h1>Title</h1>"}
+//
+// Errors are returned as {"id":2,"error":"the message"}. The process is
+// stopped by closing its stdin.
+type request struct {
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Name    string        `json:"name,omitempty"`
+	Args    []interface{} `json:"args,omitempty"`
+	Content string        `json:"content,omitempty"`
+}
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+type description struct {
+	Funcs      []string `json:"funcs"`
+	Converters []string `json:"converters"`
+}
+
+// processPlugin is an external plugin program.
+type processPlugin struct {
+	name    string
+	timeout time.Duration
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+
+	desc description
+
+	// Calls are sent one at a time.
+	mu     sync.Mutex
+	id     int
+	failed error
+}
+
+func startProcess(c Config, workingDir string, allowedEnv []string, timeout time.Duration) (*processPlugin, error) {
+	cmd := exec.Command(c.Command, c.Args...)
+	cmd.Dir = workingDir
+	cmd.Env = append(processEnv(allowedEnv), c.Env...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &processPlugin{
+		name:    c.Name,
+		timeout: timeout,
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  bufio.NewReader(stdout),
+	}
+
+	result, err := p.call(request{Method: "describe"})
+	if err != nil {
+		p.stop()
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &p.desc); err != nil {
+		p.stop()
+		return nil, fmt.Errorf("invalid description: %s", err)
+	}
+
+	return p, nil
+}
+
+// processEnv returns the variables in the environment allowed to be passed
+// on to plugin processes.
+func processEnv(allowed []string) []string {
+	var env []string
+	for _, name := range append(defaultEnv, allowed...) {
+		if v, found := os.LookupEnv(name); found {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+func (p *processPlugin) Name() string {
+	return p.name
+}
+
+func (p *processPlugin) Funcs() map[string]interface{} {
+	funcs := make(map[string]interface{})
+	for _, name := range p.desc.Funcs {
+		name := name
+		funcs[name] = func(args ...interface{}) (interface{}, error) {
+			result, err := p.call(request{Method: "call", Name: name, Args: args})
+			if err != nil {
+				return nil, fmt.Errorf("plugin %q: %s failed: %s", p.name, name, err)
+			}
+
+			var v interface{}
+			if err := json.Unmarshal(result, &v); err != nil {
+				return nil, fmt.Errorf("plugin %q: invalid result of %s: %s", p.name, name, err)
+			}
+
+			return v, nil
+		}
+	}
+	return funcs
+}
+
+func (p *processPlugin) Converters() map[string]func(content []byte) ([]byte, error) {
+	converters := make(map[string]func(content []byte) ([]byte, error))
+	for _, format := range p.desc.Converters {
+		format := format
+		converters[format] = func(content []byte) ([]byte, error) {
+			result, err := p.call(request{Method: "convert", Name: format, Content: string(content)})
+			if err != nil {
+				return nil, fmt.Errorf("plugin %q: converting %s failed: %s", p.name, format, err)
+			}
+
+			var html string
+			if err := json.Unmarshal(result, &html); err != nil {
+				return nil, fmt.Errorf("plugin %q: invalid %s conversion result: %s", p.name, format, err)
+			}
+
+			return []byte(html), nil
+		}
+	}
+	return converters
+}
+
+// call sends the request and waits for the response. The process is
+// stopped if it does not respond within the timeout.
+func (p *processPlugin) call(req request) (json.RawMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failed != nil {
+		return nil, p.failed
+	}
+
+	p.id++
+	req.ID = p.id
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request: %s", err)
+	}
+
+	if _, err := p.stdin.Write(append(b, '\n')); err != nil {
+		return nil, p.fail(fmt.Errorf("failed to send the request: %s", err))
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		line, err := p.stdout.ReadBytes('\n')
+		done <- result{line, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-time.After(p.timeout):
+		return nil, p.fail(fmt.Errorf("no response within %s", p.timeout))
+	}
+
+	if r.err != nil {
+		return nil, p.fail(fmt.Errorf("failed to read the response: %s", r.err))
+	}
+
+	var resp response
+	if err := json.Unmarshal(r.line, &resp); err != nil {
+		return nil, p.fail(fmt.Errorf("invalid response %q: %s", strings.TrimSpace(string(r.line)), err))
+	}
+
+	if resp.ID != req.ID {
+		return nil, p.fail(fmt.Errorf("response for request %d, expected %d", resp.ID, req.ID))
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	return resp.Result, nil
+}
+
+// fail stops the process after a protocol error. Later calls return err.
+func (p *processPlugin) fail(err error) error {
+	p.failed = err
+	p.kill()
+	return err
+}
+
+func (p *processPlugin) isStopped() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.failed != nil
+}
+
+func (p *processPlugin) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failed == nil {
+		p.failed = errors.New("stopped")
+	}
+	p.kill()
+}
+
+func (p *processPlugin) kill() {
+	p.stdin.Close()
+
+	done := make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		p.cmd.Process.Kill()
+	}
+}
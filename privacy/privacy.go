@@ -0,0 +1,104 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package privacy contains the privacy settings for the built-in embed
+// shortcodes and templates.
+package privacy
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Config is the privacy config, set in the privacy section of the site
+// config, e.g.:
+//
+//   [privacy.youtube]
+//   privacyEnhanced = true
+//   [privacy.twitter]
+//   simple = true
+type Config struct {
+	YouTube   YouTube
+	Vimeo     Vimeo
+	Twitter   Twitter
+	Instagram Instagram
+}
+
+// Service holds the settings common for all services.
+type Service struct {
+	// Disable the service, i.e. render nothing for its shortcode.
+	Disable bool
+}
+
+// YouTube holds the privacy settings for the youtube shortcode.
+type YouTube struct {
+	Service `mapstructure:",squash"`
+
+	// Use the privacy-enhanced mode, youtube-nocookie.com, which does not
+	// set cookies until the video is played.
+	PrivacyEnhanced bool
+
+	// Render a static thumbnail linking to the video instead of the player.
+	// The thumbnail is downloaded at build time and published with the site.
+	Simple bool
+}
+
+// Vimeo holds the privacy settings for the vimeo shortcode.
+type Vimeo struct {
+	Service `mapstructure:",squash"`
+
+	// Ask Vimeo not to track the visitor's session.
+	EnableDNT bool
+
+	// Render a static thumbnail linking to the video instead of the player.
+	// The thumbnail is looked up at build time, downloaded and published with
+	// the site.
+	Simple bool
+}
+
+// Twitter holds the privacy settings for the tweet shortcode.
+type Twitter struct {
+	Service `mapstructure:",squash"`
+
+	// Ask Twitter not to use the embedded tweets for personalization.
+	EnableDNT bool
+
+	// Render the tweet fetched at build time as static HTML, without the
+	// Twitter scripts.
+	Simple bool
+}
+
+// Instagram holds the privacy settings for the instagram shortcode.
+type Instagram struct {
+	Service `mapstructure:",squash"`
+
+	// Render the post fetched at build time as static HTML, without the
+	// Instagram scripts.
+	Simple bool
+}
+
+// DecodeConfig decodes the privacy section of the site config.
+func DecodeConfig(in interface{}) (Config, error) {
+	var c Config
+
+	if in == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(in, &c); err != nil {
+		return c, fmt.Errorf("failed to decode privacy config: %s", err)
+	}
+
+	return c, nil
+}
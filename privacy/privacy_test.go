@@ -0,0 +1,52 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := DecodeConfig(map[string]interface{}{
+		"youtube": map[string]interface{}{
+			"privacyenhanced": true,
+		},
+		"vimeo": map[string]interface{}{
+			"disable": "true",
+		},
+		"twitter": map[string]interface{}{
+			"enablednt": true,
+			"simple":    true,
+		},
+	})
+	assert.NoError(err)
+
+	assert.True(c.YouTube.PrivacyEnhanced)
+	assert.False(c.YouTube.Disable)
+	assert.True(c.Vimeo.Disable)
+	assert.True(c.Twitter.EnableDNT)
+	assert.True(c.Twitter.Simple)
+	assert.False(c.Instagram.Simple)
+
+	c, err = DecodeConfig(nil)
+	assert.NoError(err)
+	assert.False(c.YouTube.PrivacyEnhanced)
+
+	_, err = DecodeConfig("invalid")
+	assert.Error(err)
+}
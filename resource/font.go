@@ -0,0 +1,98 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+var (
+	_ Resource = (*Font)(nil)
+	_ Source   = (*Font)(nil)
+	_ Cloner   = (*Font)(nil)
+)
+
+var fontExtensions = map[string]bool{
+	".ttf":   true,
+	".otf":   true,
+	".woff":  true,
+	".woff2": true,
+}
+
+func isFontFilename(filename string) bool {
+	return fontExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// Font is a font resource.
+type Font struct {
+	// Set for subsets, which are published when created.
+	subset bool
+
+	*genericResource
+}
+
+// Implement the Cloner interface.
+func (f *Font) WithNewBase(base string) Resource {
+	return &Font{subset: f.subset, genericResource: f.genericResource.WithNewBase(base).(*genericResource)}
+}
+
+// Subset returns the font with only the glyphs needed for the characters
+// in text, see SubsetFont. The subset is published with a hash of the text
+// in the filename, e.g. font_subset_<hash>.ttf. Only TrueType (.ttf) and
+// WOFF fonts can be subset, .otf fonts with CFF outlines and WOFF2 fonts
+// return an error.
+//
+//   {{ with .Resources.GetByPrefix "NotoSansJP" }}{{ (.Subset $.Site.Params.fontText).RelPermalink }}{{ end }}
+func (f *Font) Subset(text string) (*Font, error) {
+	src, err := afero.ReadFile(f.spec.Fs.Source, f.AbsSourceFilename())
+	if err != nil {
+		return nil, err
+	}
+
+	woff := strings.EqualFold(filepath.Ext(f.rel), ".woff")
+
+	b, err := SubsetFont(src, text, woff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subset font %q: %s", f.rel, err)
+	}
+
+	nf := &Font{subset: true, genericResource: f.genericResource.withName(f.Name())}
+	p1, p2 := helpers.FileAndExt(f.rel)
+	nf.rel = p1 + "_subset_" + helpers.MD5String(text) + p2
+
+	target := filepath.Join(nf.absPublishDir, nf.target())
+	fs := nf.spec.Fs.Destination
+	if err := fs.MkdirAll(filepath.Dir(target), os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(fs, target, b, os.FileMode(0644)); err != nil {
+		return nil, err
+	}
+
+	return nf, nil
+}
+
+// Publish writes the font to the publish dir.
+func (f *Font) Publish() error {
+	if f.subset {
+		return nil
+	}
+	return f.genericResource.Publish()
+}
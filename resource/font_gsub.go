@@ -0,0 +1,192 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package resource
+
+import "encoding/binary"
+
+// fontData is a part of a font table, read with bounds checks: the reads
+// outside of it return -1, the sub tables nil.
+type fontData []byte
+
+func (d fontData) u16(off int) int {
+	if off < 0 || off+2 > len(d) {
+		return -1
+	}
+	return int(binary.BigEndian.Uint16(d[off:]))
+}
+
+func (d fontData) u32(off int) int64 {
+	if off < 0 || off+4 > len(d) {
+		return -1
+	}
+	return int64(binary.BigEndian.Uint32(d[off:]))
+}
+
+func (d fontData) sub(off int64) fontData {
+	if off < 0 || off > int64(len(d)) {
+		return nil
+	}
+	return d[off:]
+}
+
+// The GSUB lookup types.
+const (
+	gsubSingle         = 1
+	gsubMultiple       = 2
+	gsubAlternate      = 3
+	gsubLigature       = 4
+	gsubExtension      = 7
+	gsubReverseChained = 8
+)
+
+// addSubstitutedGlyphs adds the glyphs the GSUB lookups may substitute for
+// the glyphs in glyphs, e.g. ligatures and vertical forms, which would
+// else be blank in the subset. The contexts of the substitutions are not
+// checked, so a few glyphs too many may be kept.
+func addSubstitutedGlyphs(glyphs map[uint16]bool, gsub []byte) {
+	d := fontData(gsub)
+	lookupList := d.sub(int64(d.u16(8)))
+	lookupCount := lookupList.u16(0)
+
+	for {
+		added := false
+		add := func(gid int) {
+			if gid >= 0 && !glyphs[uint16(gid)] {
+				glyphs[uint16(gid)] = true
+				added = true
+			}
+		}
+
+		for i := 0; i < lookupCount; i++ {
+			lookup := lookupList.sub(int64(lookupList.u16(2 + 2*i)))
+			typ := lookup.u16(0)
+			for j := 0; j < lookup.u16(4); j++ {
+				addSubstitutes(glyphs, typ, lookup.sub(int64(lookup.u16(6+2*j))), add)
+			}
+		}
+
+		if !added {
+			return
+		}
+	}
+}
+
+// addSubstitutes adds the substitutes for the glyphs in glyphs in the GSUB
+// sub table st of the given lookup type.
+func addSubstitutes(glyphs map[uint16]bool, typ int, st fontData, add func(gid int)) {
+	if typ == gsubExtension {
+		typ = st.u16(2)
+		if typ == gsubExtension {
+			return
+		}
+		st = st.sub(st.u32(4))
+	}
+
+	coverage := readCoverage(st.sub(int64(st.u16(2))))
+
+	switch typ {
+	case gsubSingle:
+		if st.u16(0) == 1 {
+			delta := st.u16(4)
+			if delta < 0 {
+				return
+			}
+			for _, gid := range coverage {
+				if glyphs[gid] {
+					add((int(gid) + delta) & 0xFFFF)
+				}
+			}
+			return
+		}
+		for i, gid := range coverage {
+			if glyphs[gid] && i < st.u16(4) {
+				add(st.u16(6 + 2*i))
+			}
+		}
+	case gsubMultiple, gsubAlternate:
+		for i, gid := range coverage {
+			if !glyphs[gid] || i >= st.u16(4) {
+				continue
+			}
+			seq := st.sub(int64(st.u16(6 + 2*i)))
+			for j := 0; j < seq.u16(0); j++ {
+				add(seq.u16(2 + 2*j))
+			}
+		}
+	case gsubLigature:
+		for i, gid := range coverage {
+			if !glyphs[gid] || i >= st.u16(4) {
+				continue
+			}
+			set := st.sub(int64(st.u16(6 + 2*i)))
+			for j := 0; j < set.u16(0); j++ {
+				lig := set.sub(int64(set.u16(2 + 2*j)))
+				all := true
+				for k := 1; k < lig.u16(2); k++ {
+					comp := lig.u16(4 + 2*(k-1))
+					if comp < 0 || !glyphs[uint16(comp)] {
+						all = false
+						break
+					}
+				}
+				if all {
+					add(lig.u16(0))
+				}
+			}
+		}
+	case gsubReverseChained:
+		pos := 6 + 2*st.u16(4)
+		pos += 2 + 2*st.u16(pos)
+		for i, gid := range coverage {
+			if glyphs[gid] && i < st.u16(pos) {
+				add(st.u16(pos + 2 + 2*i))
+			}
+		}
+	}
+}
+
+// readCoverage returns the glyphs in the coverage table, in coverage index
+// order.
+func readCoverage(d fontData) []uint16 {
+	switch d.u16(0) {
+	case 1:
+		n := d.u16(2)
+		glyphs := make([]uint16, 0, n)
+		for i := 0; i < n; i++ {
+			gid := d.u16(4 + 2*i)
+			if gid < 0 {
+				break
+			}
+			glyphs = append(glyphs, uint16(gid))
+		}
+		return glyphs
+	case 2:
+		var glyphs []uint16
+		for i := 0; i < d.u16(2); i++ {
+			rec := d.sub(int64(4 + 6*i))
+			start, end, index := rec.u16(0), rec.u16(2), rec.u16(4)
+			if start < 0 || end < start || index < 0 || index+end-start > 0xFFFF {
+				break
+			}
+			for len(glyphs) <= index+end-start {
+				glyphs = append(glyphs, 0)
+			}
+			for gid := start; gid <= end; gid++ {
+				glyphs[index+gid-start] = uint16(gid)
+			}
+		}
+		return glyphs
+	}
+
+	return nil
+}
@@ -0,0 +1,508 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+var (
+	errWOFF2NotSupported  = errors.New("WOFF2 fonts are not supported, use a TTF or WOFF source")
+	errNoTrueTypeOutlines = errors.New("OpenType fonts with CFF outlines (.otf) are not supported, use a TrueType source")
+)
+
+type fontTable struct {
+	tag  string
+	data []byte
+}
+
+// SubsetFont returns the font in src with only the glyphs needed for the
+// characters in text. The source must be a TrueType font (.ttf) or a WOFF
+// of it; fonts with CFF outlines (.otf) and WOFF2 fonts are not supported.
+// The result is a WOFF if woff is set, else a TTF.
+//
+// The glyph ids are kept, the outlines of the glyphs not needed are
+// removed. As the outlines are most of a font, this is a simple way to
+// make large fonts, e.g. CJK fonts, a lot smaller while keeping all the
+// other tables valid. The glyphs the GSUB table may substitute for the
+// kept glyphs, e.g. ligatures and vertical forms, are kept too.
+func SubsetFont(src []byte, text string, woff bool) ([]byte, error) {
+	flavor, tables, err := readFont(src)
+	if err != nil {
+		return nil, err
+	}
+
+	byTag := make(map[string]*fontTable)
+	for i := range tables {
+		byTag[tables[i].tag] = &tables[i]
+	}
+
+	glyf, loca, head, maxp, cmap := byTag["glyf"], byTag["loca"], byTag["head"], byTag["maxp"], byTag["cmap"]
+	if glyf == nil || loca == nil {
+		return nil, errNoTrueTypeOutlines
+	}
+	if head == nil || maxp == nil || cmap == nil || len(head.data) < 54 || len(maxp.data) < 6 {
+		return nil, errors.New("invalid font: missing or short head, maxp or cmap table")
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp.data[4:]))
+	longLoca := binary.BigEndian.Uint16(head.data[50:]) == 1
+
+	offsets, err := readLoca(loca.data, numGlyphs, longLoca, len(glyf.data))
+	if err != nil {
+		return nil, err
+	}
+
+	glyphs := map[uint16]bool{0: true}
+	for _, r := range text {
+		if gid := lookupGlyph(cmap.data, r); gid != 0 {
+			glyphs[gid] = true
+		}
+	}
+
+	if gsub := byTag["GSUB"]; gsub != nil {
+		addSubstitutedGlyphs(glyphs, gsub.data)
+	}
+
+	if err := addComponentGlyphs(glyphs, glyf.data, offsets); err != nil {
+		return nil, err
+	}
+
+	// Write the outlines of the kept glyphs, with a long loca table.
+	var newGlyf bytes.Buffer
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(newLoca[4*gid:], uint32(newGlyf.Len()))
+		if !glyphs[uint16(gid)] {
+			continue
+		}
+		newGlyf.Write(glyf.data[offsets[gid]:offsets[gid+1]])
+		for newGlyf.Len()%4 != 0 {
+			newGlyf.WriteByte(0)
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(newGlyf.Len()))
+
+	glyf.data = newGlyf.Bytes()
+	loca.data = newLoca
+	head.data = append([]byte(nil), head.data...)
+	binary.BigEndian.PutUint16(head.data[50:], 1)
+
+	// The signature is not valid for the subset.
+	var out []fontTable
+	for _, t := range tables {
+		if t.tag != "DSIG" {
+			out = append(out, t)
+		}
+	}
+
+	sfnt := writeSFNT(flavor, out)
+	if woff {
+		return writeWOFF(sfnt)
+	}
+
+	return sfnt, nil
+}
+
+// readFont reads the tables of a TTF or WOFF font.
+func readFont(src []byte) (uint32, []fontTable, error) {
+	if len(src) < 12 {
+		return 0, nil, errors.New("invalid font: too short")
+	}
+
+	switch string(src[:4]) {
+	case "wOF2":
+		return 0, nil, errWOFF2NotSupported
+	case "wOFF":
+		return readWOFF(src)
+	case "OTTO":
+		return 0, nil, errNoTrueTypeOutlines
+	}
+
+	flavor := binary.BigEndian.Uint32(src)
+	numTables := int(binary.BigEndian.Uint16(src[4:]))
+	if len(src) < 12+16*numTables {
+		return 0, nil, errors.New("invalid font: truncated table directory")
+	}
+
+	tables := make([]fontTable, numTables)
+	for i := range tables {
+		rec := src[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(offset)+uint64(length) > uint64(len(src)) {
+			return 0, nil, fmt.Errorf("invalid font: table %q out of bounds", rec[:4])
+		}
+		tables[i] = fontTable{tag: string(rec[:4]), data: src[offset : offset+length]}
+	}
+
+	return flavor, tables, nil
+}
+
+func readWOFF(src []byte) (uint32, []fontTable, error) {
+	if len(src) < 44 {
+		return 0, nil, errors.New("invalid WOFF: too short")
+	}
+
+	flavor := binary.BigEndian.Uint32(src[4:])
+	numTables := int(binary.BigEndian.Uint16(src[12:]))
+	if len(src) < 44+20*numTables {
+		return 0, nil, errors.New("invalid WOFF: truncated table directory")
+	}
+
+	tables := make([]fontTable, numTables)
+	for i := range tables {
+		rec := src[44+20*i:]
+		offset, compLength, origLength := binary.BigEndian.Uint32(rec[4:]), binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(offset)+uint64(compLength) > uint64(len(src)) {
+			return 0, nil, fmt.Errorf("invalid WOFF: table %q out of bounds", rec[:4])
+		}
+
+		data := src[offset : offset+compLength]
+		if compLength < origLength {
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return 0, nil, err
+			}
+			data, err = ioutil.ReadAll(r)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+
+		tables[i] = fontTable{tag: string(rec[:4]), data: data}
+	}
+
+	return flavor, tables, nil
+}
+
+// readLoca reads the glyph offsets in the loca table and checks that they
+// are in order and within the glyf table of length glyfLen.
+func readLoca(loca []byte, numGlyphs int, long bool, glyfLen int) ([]uint32, error) {
+	offsets := make([]uint32, numGlyphs+1)
+	size := 2
+	if long {
+		size = 4
+	}
+	if len(loca) < size*(numGlyphs+1) {
+		return nil, errors.New("invalid font: short loca table")
+	}
+
+	for i := range offsets {
+		if long {
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			offsets[i] = uint32(binary.BigEndian.Uint16(loca[2*i:])) * 2
+		}
+		if i > 0 && offsets[i] < offsets[i-1] {
+			return nil, fmt.Errorf("invalid font: loca offset of glyph %d is before the one of glyph %d", i, i-1)
+		}
+	}
+
+	if int64(offsets[numGlyphs]) > int64(glyfLen) {
+		return nil, errors.New("invalid font: loca offsets past the end of the glyf table")
+	}
+
+	return offsets, nil
+}
+
+// lookupGlyph returns the glyph id for r in the Unicode cmap subtables, 0
+// if not found.
+func lookupGlyph(cmap []byte, r rune) uint16 {
+	if len(cmap) < 4 {
+		return 0
+	}
+
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+
+	var format4, format12 []byte
+	for i := 0; i < numTables && 4+8*i+8 <= len(cmap); i++ {
+		rec := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(rec), binary.BigEndian.Uint16(rec[2:])
+		offset := binary.BigEndian.Uint32(rec[4:])
+		if int(offset)+2 > len(cmap) {
+			continue
+		}
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		sub := cmap[offset:]
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			format4 = sub
+		case 12:
+			format12 = sub
+		}
+	}
+
+	if format12 != nil {
+		return lookupFormat12(format12, r)
+	}
+	if format4 != nil {
+		return lookupFormat4(format4, r)
+	}
+	return 0
+}
+
+func lookupFormat4(sub []byte, r rune) uint16 {
+	if r > 0xFFFF || len(sub) < 14 {
+		return 0
+	}
+	c := uint16(r)
+
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if len(sub) < idRangeOffsets+2*segCount {
+		return 0
+	}
+
+	for i := 0; i < segCount; i++ {
+		end := binary.BigEndian.Uint16(sub[endCodes+2*i:])
+		if end < c {
+			continue
+		}
+		start := binary.BigEndian.Uint16(sub[startCodes+2*i:])
+		if start > c {
+			return 0
+		}
+		delta := binary.BigEndian.Uint16(sub[idDeltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(sub[idRangeOffsets+2*i:]))
+		if rangeOffset == 0 {
+			return c + delta
+		}
+		addr := idRangeOffsets + 2*i + rangeOffset + 2*int(c-start)
+		if addr+2 > len(sub) {
+			return 0
+		}
+		gid := binary.BigEndian.Uint16(sub[addr:])
+		if gid == 0 {
+			return 0
+		}
+		return gid + delta
+	}
+
+	return 0
+}
+
+func lookupFormat12(sub []byte, r rune) uint16 {
+	if len(sub) < 16 {
+		return 0
+	}
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	c := uint32(r)
+
+	for i := 0; i < numGroups && 16+12*i+12 <= len(sub); i++ {
+		group := sub[16+12*i:]
+		start, end := binary.BigEndian.Uint32(group), binary.BigEndian.Uint32(group[4:])
+		if c >= start && c <= end {
+			return uint16(binary.BigEndian.Uint32(group[8:]) + c - start)
+		}
+	}
+
+	return 0
+}
+
+// The flags of the components of composite glyphs.
+const (
+	argsAreWords   = 0x0001
+	haveScale      = 0x0008
+	moreComponents = 0x0020
+	haveXYScale    = 0x0040
+	haveTwoByTwo   = 0x0080
+)
+
+// addComponentGlyphs adds the glyphs used by the composite glyphs in glyphs.
+func addComponentGlyphs(glyphs map[uint16]bool, glyf []byte, offsets []uint32) error {
+	queue := make([]uint16, 0, len(glyphs))
+	for gid := range glyphs {
+		queue = append(queue, gid)
+	}
+
+	for len(queue) > 0 {
+		gid := queue[0]
+		queue = queue[1:]
+
+		if int(gid)+1 >= len(offsets) {
+			continue
+		}
+		start, end := offsets[gid], offsets[gid+1]
+		if end <= start {
+			continue
+		}
+		if end > uint32(len(glyf)) {
+			return fmt.Errorf("invalid font: glyph %d out of bounds", gid)
+		}
+		data := glyf[start:end]
+		if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+			// A simple glyph.
+			continue
+		}
+
+		pos := 10
+		for {
+			if pos+4 > len(data) {
+				break
+			}
+			flags := binary.BigEndian.Uint16(data[pos:])
+			component := binary.BigEndian.Uint16(data[pos+2:])
+			if !glyphs[component] {
+				glyphs[component] = true
+				queue = append(queue, component)
+			}
+
+			pos += 4
+			if flags&argsAreWords != 0 {
+				pos += 4
+			} else {
+				pos += 2
+			}
+			switch {
+			case flags&haveScale != 0:
+				pos += 2
+			case flags&haveXYScale != 0:
+				pos += 4
+			case flags&haveTwoByTwo != 0:
+				pos += 8
+			}
+
+			if flags&moreComponents == 0 {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func fontChecksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// writeSFNT writes the tables as a TTF, sorted by tag as required.
+func writeSFNT(flavor uint32, tables []fontTable) []byte {
+	sort.Slice(tables, func(i, j int) bool { return tables[i].tag < tables[j].tag })
+
+	numTables := len(tables)
+	entrySelector := 0
+	for 1<<uint(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << uint(entrySelector)
+
+	size := 12 + 16*numTables
+	for _, t := range tables {
+		size += pad4(len(t.data))
+	}
+
+	b := make([]byte, size)
+	binary.BigEndian.PutUint32(b, flavor)
+	binary.BigEndian.PutUint16(b[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(b[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(b[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(b[10:], uint16(16*numTables-searchRange))
+
+	headOffset := -1
+	offset := 12 + 16*numTables
+	for i, t := range tables {
+		rec := b[12+16*i:]
+		copy(rec, t.tag)
+		data := t.data
+		if t.tag == "head" {
+			headOffset = offset
+			data = append([]byte(nil), data...)
+			binary.BigEndian.PutUint32(data[8:], 0)
+		}
+		binary.BigEndian.PutUint32(rec[4:], fontChecksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(offset))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+		copy(b[offset:], data)
+		offset += pad4(len(data))
+	}
+
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(b[headOffset+8:], 0xB1B0AFBA-fontChecksum(b))
+	}
+
+	return b
+}
+
+// writeWOFF writes the TTF in sfnt as a WOFF, with the tables compressed
+// with zlib.
+func writeWOFF(sfnt []byte) ([]byte, error) {
+	numTables := int(binary.BigEndian.Uint16(sfnt[4:]))
+
+	var data bytes.Buffer
+	dir := make([]byte, 20*numTables)
+	offset := 44 + len(dir)
+
+	for i := 0; i < numTables; i++ {
+		src := sfnt[12+16*i:]
+		tableOffset, length := binary.BigEndian.Uint32(src[8:]), binary.BigEndian.Uint32(src[12:])
+		table := sfnt[tableOffset : tableOffset+length]
+
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		if _, err := w.Write(table); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		stored := table
+		if compressed.Len() < len(table) {
+			stored = compressed.Bytes()
+		}
+
+		rec := dir[20*i:]
+		copy(rec, src[:4])
+		binary.BigEndian.PutUint32(rec[4:], uint32(offset+data.Len()))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(stored)))
+		binary.BigEndian.PutUint32(rec[12:], length)
+		copy(rec[16:20], src[4:8])
+
+		data.Write(stored)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	header := make([]byte, 44)
+	copy(header, "wOFF")
+	copy(header[4:8], sfnt[:4])
+	binary.BigEndian.PutUint32(header[8:], uint32(44+len(dir)+data.Len()))
+	binary.BigEndian.PutUint16(header[12:], uint16(numTables))
+	binary.BigEndian.PutUint32(header[16:], uint32(len(sfnt)))
+	binary.BigEndian.PutUint16(header[20:], 1)
+
+	return append(append(header, dir...), data.Bytes()...), nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/binary"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// fontGlyphSize returns the size of the outline of the glyph for r.
+func fontGlyphSize(assert *require.Assertions, font []byte, r rune) int {
+	_, tables, err := readFont(font)
+	assert.NoError(err)
+
+	byTag := make(map[string][]byte)
+	for _, t := range tables {
+		byTag[t.tag] = t.data
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(byTag["maxp"][4:]))
+	long := binary.BigEndian.Uint16(byTag["head"][50:]) == 1
+	offsets, err := readLoca(byTag["loca"], numGlyphs, long, len(byTag["glyf"]))
+	assert.NoError(err)
+
+	gid := lookupGlyph(byTag["cmap"], r)
+	assert.NotEqual(uint16(0), gid)
+
+	return int(offsets[gid+1] - offsets[gid])
+}
+
+func TestSubsetFont(t *testing.T) {
+	assert := require.New(t)
+
+	src := goregular.TTF
+
+	subset, err := SubsetFont(src, "Hi!", false)
+	assert.NoError(err)
+	assert.True(len(subset) < len(src)/2, "%d >= %d", len(subset), len(src))
+
+	assert.True(fontGlyphSize(assert, src, 'Z') > 0)
+	assert.True(fontGlyphSize(assert, subset, 'H') > 0)
+	assert.True(fontGlyphSize(assert, subset, '!') > 0)
+	assert.Equal(0, fontGlyphSize(assert, subset, 'Z'))
+
+	// The checksum adjustment makes the checksum of the font 0xB1B0AFBA.
+	assert.Equal(uint32(0xB1B0AFBA), fontChecksum(subset))
+
+	// The same font as a WOFF.
+	woff, err := SubsetFont(src, "Hi!", true)
+	assert.NoError(err)
+	assert.Equal("wOFF", string(woff[:4]))
+	assert.True(len(woff) < len(subset))
+
+	_, ttfTables, err := readFont(subset)
+	assert.NoError(err)
+	_, woffTables, err := readFont(woff)
+	assert.NoError(err)
+	assert.Equal(ttfTables, woffTables)
+
+	// A WOFF source.
+	subset2, err := SubsetFont(woff, "H", false)
+	assert.NoError(err)
+	assert.True(fontGlyphSize(assert, subset2, 'H') > 0)
+	assert.Equal(0, fontGlyphSize(assert, subset2, 'i'))
+
+	_, err = SubsetFont([]byte("wOF2 and then some more"), "H", false)
+	assert.Equal(errWOFF2NotSupported, err)
+	_, err = SubsetFont([]byte("OTTO and then some more"), "H", false)
+	assert.Equal(errNoTrueTypeOutlines, err)
+}
+
+// fontWithTables returns goregular with its tables changed by change.
+func fontWithTables(assert *require.Assertions, change func(byTag map[string]*fontTable) []fontTable) []byte {
+	flavor, tables, err := readFont(goregular.TTF)
+	assert.NoError(err)
+
+	byTag := make(map[string]*fontTable)
+	for i := range tables {
+		byTag[tables[i].tag] = &tables[i]
+	}
+
+	return writeSFNT(flavor, append(tables, change(byTag)...))
+}
+
+func TestSubsetFontGSUB(t *testing.T) {
+	assert := require.New(t)
+
+	gid := func(r rune) uint16 {
+		_, tables, err := readFont(goregular.TTF)
+		assert.NoError(err)
+		for _, t := range tables {
+			if t.tag == "cmap" {
+				return lookupGlyph(t.data, r)
+			}
+		}
+		return 0
+	}
+
+	u16s := func(v ...uint16) []byte {
+		b := make([]byte, 2*len(v))
+		for i, n := range v {
+			binary.BigEndian.PutUint16(b[2*i:], n)
+		}
+		return b
+	}
+
+	// H is substituted by Z, the ligature of f and i is Q.
+	single := u16s(1, 0, 1, 8, 2, 8, 1, gid('Z'), 1, 1, gid('H'))
+	ligature := u16s(4, 0, 1, 8, 1, 8, 1, 14, 1, 1, gid('f'), 1, 4, gid('Q'), 2, gid('i'))
+
+	gsub := u16s(1, 0, 0, 0, 10)
+	gsub = append(gsub, u16s(2, 6, uint16(6+len(single)))...)
+	gsub = append(gsub, single...)
+	gsub = append(gsub, ligature...)
+
+	src := fontWithTables(assert, func(map[string]*fontTable) []fontTable {
+		return []fontTable{{tag: "GSUB", data: gsub}}
+	})
+
+	subset, err := SubsetFont(src, "Hf", false)
+	assert.NoError(err)
+	assert.True(fontGlyphSize(assert, subset, 'Z') > 0)
+	assert.Equal(0, fontGlyphSize(assert, subset, 'Q'))
+
+	subset, err = SubsetFont(src, "fi", false)
+	assert.NoError(err)
+	assert.True(fontGlyphSize(assert, subset, 'Q') > 0)
+	assert.Equal(0, fontGlyphSize(assert, subset, 'Z'))
+
+	// A truncated GSUB table is ignored.
+	src = fontWithTables(assert, func(map[string]*fontTable) []fontTable {
+		return []fontTable{{tag: "GSUB", data: gsub[:20]}}
+	})
+	_, err = SubsetFont(src, "Hfi", false)
+	assert.NoError(err)
+}
+
+func TestSubsetFontInvalidLoca(t *testing.T) {
+	assert := require.New(t)
+
+	src := fontWithTables(assert, func(byTag map[string]*fontTable) []fontTable {
+		loca := append([]byte(nil), byTag["loca"].data...)
+		binary.BigEndian.PutUint16(loca[2:], 0xFFFF)
+		byTag["loca"].data = loca
+		return nil
+	})
+
+	_, err := SubsetFont(src, "H", false)
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid font: loca offset")
+}
+
+func TestFontResourceSubset(t *testing.T) {
+	assert := require.New(t)
+
+	spec := newTestResourceSpec(assert)
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b/goregular.ttf", goregular.TTF, 0755))
+
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/goregular.ttf", "goregular.ttf")
+	assert.NoError(err)
+	assert.IsType(&Font{}, r)
+
+	subset, err := r.(*Font).Subset("Hugo")
+	assert.NoError(err)
+	assert.Regexp(`^/a/goregular_subset_[0-9a-f]{32}\.ttf$`, subset.RelPermalink())
+
+	b, err := afero.ReadFile(spec.Fs.Destination, filepath.Join(subset.absPublishDir, subset.target()))
+	assert.NoError(err)
+	assert.True(fontGlyphSize(assert, b, 'H') > 0)
+	assert.Equal(0, fontGlyphSize(assert, b, 'Z'))
+}
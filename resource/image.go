@@ -30,7 +30,7 @@ import (
 
 	// Importing image codecs for image.DecodeConfig
 	"image"
-	_ "image/gif"
+	"image/gif"
 	"image/jpeg"
 	_ "image/png"
 
@@ -56,6 +56,15 @@ type Imaging struct {
 
 	// Resample filter used. See https://github.com/disintegration/imaging
 	ResampleFilter string
+
+	// Use a placeholder for images that fail to process instead of failing
+	// the build. This only applies when running the server.
+	ErrorPlaceholder bool
+
+	// How to process animated GIFs, either "preserve" to process all the
+	// frames or "firstFrame" to only keep the first.
+	// Only the first frame of animated PNGs is processed.
+	Animation string
 }
 
 const (
@@ -182,6 +191,9 @@ type imageConfig struct {
 
 	Anchor    imaging.Anchor
 	AnchorStr string
+
+	// Only keep the first frame of animated GIFs.
+	FirstFrame bool
 }
 
 func (i *Image) isJPEG() bool {
@@ -206,35 +218,75 @@ func (i *Image) doWithImageConfig(action, spec string, f func(src image.Image, c
 		conf.Filter = imageFilters[conf.FilterStr]
 	}
 
+	if i.isGIF() && i.imaging.Animation == animationFirstFrame {
+		conf.FirstFrame = true
+	}
+
+	if i.spec.SkipImages {
+		return i.placeholderImage(conf)
+	}
+
 	key := i.relPermalinkForRel(i.filenameFromConfig(conf), false)
 
-	return i.spec.imageCache.getOrCreate(i.spec, key, func(resourceCacheFilename string) (*Image, error) {
+	img, err := i.spec.imageCache.getOrCreate(i.spec, key, func(resourceCacheFilename string) (*Image, error) {
 		ci := i.clone()
 
 		ci.setBasePath(conf)
 
-		src, err := i.decodeSource()
-		if err != nil {
-			return nil, err
-		}
+		err := i.spec.ImageProcessor.Process(func() error {
+			if i.isGIF() && !conf.FirstFrame {
+				g, err := i.decodeAnimatedGIF()
+				if err != nil {
+					return i.newImageError(action, spec, err)
+				}
+				if g != nil {
+					converted, err := processAnimatedGIF(g, conf, f)
+					if err != nil {
+						return err
+					}
 
-		if conf.Rotate != 0 {
-			// Rotate it before any scaling to get the dimensions correct.
-			src = imaging.Rotate(src, float64(conf.Rotate), color.Transparent)
-		}
+					b := converted.Image[0].Bounds()
+					ci.config = image.Config{Width: b.Dx(), Height: b.Dy()}
+					ci.configLoaded = true
 
-		converted, err := f(src, conf)
-		if err != nil {
-			return ci, err
-		}
+					return i.writeToDestinations(resourceCacheFilename, ci.target(), func(w io.Writer) error {
+						return gif.EncodeAll(w, converted)
+					})
+				}
+			}
+
+			i.warnIfAPNG()
+
+			src, err := i.decodeSource()
+			if err != nil {
+				return i.newImageError(action, spec, err)
+			}
+
+			if conf.Rotate != 0 {
+				// Rotate it before any scaling to get the dimensions correct.
+				src = imaging.Rotate(src, float64(conf.Rotate), color.Transparent)
+			}
+
+			converted, err := f(src, conf)
+			if err != nil {
+				return err
+			}
+
+			b := converted.Bounds()
+			ci.config = image.Config{Width: b.Max.X, Height: b.Max.Y}
+			ci.configLoaded = true
 
-		b := converted.Bounds()
-		ci.config = image.Config{Width: b.Max.X, Height: b.Max.Y}
-		ci.configLoaded = true
+			return i.encodeToDestinations(converted, conf, resourceCacheFilename, ci.target())
+		})
 
-		return ci, i.encodeToDestinations(converted, conf, resourceCacheFilename, ci.target())
+		return ci, err
 	})
 
+	if imgErr, ok := err.(*ImageError); ok {
+		return i.handleImageError(conf, imgErr)
+	}
+
+	return img, err
 }
 
 func (i imageConfig) key() string {
@@ -248,6 +300,9 @@ func (i imageConfig) key() string {
 	if i.Rotate != 0 {
 		k += "_r" + strconv.Itoa(i.Rotate)
 	}
+	if i.FirstFrame {
+		k += "_first"
+	}
 	k += "_" + i.FilterStr + "_" + i.AnchorStr
 	return k
 }
@@ -447,6 +502,36 @@ func (i *Image) encodeToDestinations(img image.Image, conf imageConfig, resource
 		return imaging.ErrUnsupportedFormat
 	}
 
+	return i.writeToDestinations(resourceCacheFilename, filename, func(w io.Writer) error {
+		switch imgFormat {
+		case imaging.JPEG:
+
+			var rgba *image.RGBA
+			quality := conf.Quality
+
+			if nrgba, ok := img.(*image.NRGBA); ok {
+				if nrgba.Opaque() {
+					rgba = &image.RGBA{
+						Pix:    nrgba.Pix,
+						Stride: nrgba.Stride,
+						Rect:   nrgba.Rect,
+					}
+				}
+			}
+			if rgba != nil {
+				return jpeg.Encode(w, rgba, &jpeg.Options{Quality: quality})
+			} else {
+				return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+			}
+		default:
+			return imaging.Encode(w, img, imgFormat)
+		}
+	})
+}
+
+// writeToDestinations writes an image to the publish dir and, if
+// resourceCacheFilename is set, to the resource cache using write.
+func (i *Image) writeToDestinations(resourceCacheFilename, filename string, write func(w io.Writer) error) error {
 	target := filepath.Join(i.absPublishDir, filename)
 
 	file1, err := i.spec.Fs.Destination.Create(target)
@@ -485,30 +570,7 @@ func (i *Image) encodeToDestinations(img image.Image, conf imageConfig, resource
 		w = file1
 	}
 
-	switch imgFormat {
-	case imaging.JPEG:
-
-		var rgba *image.RGBA
-		quality := conf.Quality
-
-		if nrgba, ok := img.(*image.NRGBA); ok {
-			if nrgba.Opaque() {
-				rgba = &image.RGBA{
-					Pix:    nrgba.Pix,
-					Stride: nrgba.Stride,
-					Rect:   nrgba.Rect,
-				}
-			}
-		}
-		if rgba != nil {
-			return jpeg.Encode(w, rgba, &jpeg.Options{Quality: quality})
-		} else {
-			return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
-		}
-	default:
-		return imaging.Encode(w, img, imgFormat)
-	}
-
+	return write(w)
 }
 
 func (i *Image) clone() *Image {
@@ -558,6 +620,15 @@ func decodeImaging(m map[string]interface{}) (Imaging, error) {
 		i.Quality = defaultJPEGQuality
 	}
 
+	if i.Animation == "" {
+		i.Animation = animationPreserve
+	} else {
+		i.Animation = strings.ToLower(i.Animation)
+		if i.Animation != animationPreserve && i.Animation != animationFirstFrame {
+			return i, fmt.Errorf("%q is not a valid animation setting, must be one of preserve or firstFrame", i.Animation)
+		}
+	}
+
 	if i.ResampleFilter == "" {
 		i.ResampleFilter = defaultResampleFilter
 	} else {
@@ -0,0 +1,174 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+const (
+	// Process all the frames of animated GIFs.
+	animationPreserve = "preserve"
+
+	// Only use the first frame of animated GIFs.
+	animationFirstFrame = "firstframe"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func (i *Image) isGIF() bool {
+	return strings.HasSuffix(strings.ToLower(i.rel), ".gif")
+}
+
+func (i *Image) isPNG() bool {
+	return strings.HasSuffix(strings.ToLower(i.rel), ".png")
+}
+
+// decodeAnimatedGIF decodes the source if it is an animated GIF. It returns
+// nil if the source is not animated.
+func (i *Image) decodeAnimatedGIF() (*gif.GIF, error) {
+	file, err := i.spec.Fs.Source.Open(i.AbsSourceFilename())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(g.Image) < 2 {
+		return nil, nil
+	}
+
+	return g, nil
+}
+
+// warnIfAPNG logs a warning if the source is an animated PNG. There is no
+// APNG encoder, so only the default image, usually the first frame, is used.
+func (i *Image) warnIfAPNG() {
+	if !i.isPNG() {
+		return
+	}
+
+	file, err := i.spec.Fs.Source.Open(i.AbsSourceFilename())
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if isAPNG(file) {
+		helpers.DistinctWarnLog.Printf("%q is an animated PNG, only the first frame is processed", i.rel)
+	}
+}
+
+// isAPNG returns whether r is an animated PNG, i.e. has an acTL chunk before
+// the image data.
+func isAPNG(r io.Reader) bool {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return false
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return false
+		}
+
+		length := binary.BigEndian.Uint32(header[:4])
+
+		switch string(header[4:]) {
+		case "acTL":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+
+		// Skip the chunk data and the CRC.
+		if _, err := io.CopyN(ioutil.Discard, r, int64(length)+4); err != nil {
+			return false
+		}
+	}
+}
+
+// processAnimatedGIF applies f to all the frames of g. The frames of a GIF
+// may only cover parts of the image, so they are composed into full frames
+// before processing.
+func processAnimatedGIF(g *gif.GIF, conf imageConfig, f func(src image.Image, conf imageConfig) (image.Image, error)) (*gif.GIF, error) {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		bounds = g.Image[0].Bounds()
+	}
+
+	canvas := image.NewNRGBA(bounds)
+	previous := image.NewNRGBA(bounds)
+
+	out := &gif.GIF{
+		LoopCount: g.LoopCount,
+		Delay:     g.Delay,
+	}
+
+	for idx, frame := range g.Image {
+		disposal := byte(0)
+		if idx < len(g.Disposal) {
+			disposal = g.Disposal[idx]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		var src image.Image = imaging.Clone(canvas)
+		if conf.Rotate != 0 {
+			src = imaging.Rotate(src, float64(conf.Rotate), color.Transparent)
+		}
+
+		converted, err := f(src, conf)
+		if err != nil {
+			return nil, err
+		}
+
+		b := converted.Bounds()
+		paletted := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), frame.Palette)
+		draw.Draw(paletted, paletted.Bounds(), converted, b.Min, draw.Src)
+
+		out.Image = append(out.Image, paletted)
+		// Every frame is complete, so clear it before drawing the next.
+		out.Disposal = append(out.Disposal, gif.DisposalBackground)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+
+	return out, nil
+}
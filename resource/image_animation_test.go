@@ -0,0 +1,144 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func createAnimatedGIF(assert *require.Assertions, spec *Spec, filename string) *Image {
+	g := &gif.GIF{LoopCount: 0}
+	for i := 0; i < 3; i++ {
+		// The second and third frames only cover parts of the image.
+		rect := image.Rect(0, 0, 40, 20)
+		if i > 0 {
+			rect = image.Rect(i*10, 5, i*10+10, 15)
+		}
+		frame := image.NewPaletted(rect, palette.Plan9)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				frame.Set(x, y, color.RGBA{R: uint8(i * 100), A: 255})
+			}
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10*(i+1))
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	g.Config = image.Config{Width: 40, Height: 20}
+
+	var buf bytes.Buffer
+	assert.NoError(gif.EncodeAll(&buf, g))
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b/"+filename, buf.Bytes(), 0755))
+
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/"+filename, filename)
+	assert.NoError(err)
+	return r.(*Image)
+}
+
+func decodeTarget(assert *require.Assertions, img *Image) *gif.GIF {
+	f, err := img.spec.Fs.Destination.Open(filepath.Join(img.absPublishDir, img.target()))
+	assert.NoError(err)
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	assert.NoError(err)
+	return g
+}
+
+func TestResizeAnimatedGIF(t *testing.T) {
+	assert := require.New(t)
+
+	spec := newTestResourceSpec(assert)
+	img := createAnimatedGIF(assert, spec, "anim.gif")
+
+	resized, err := img.Resize("20x")
+	assert.NoError(err)
+	assert.Equal(20, resized.Width())
+	assert.Equal(10, resized.Height())
+
+	g := decodeTarget(assert, resized)
+	assert.Len(g.Image, 3)
+	assert.Equal([]int{10, 20, 30}, g.Delay)
+	for _, frame := range g.Image {
+		assert.Equal(image.Rect(0, 0, 20, 10), frame.Bounds())
+	}
+
+	// The partial frames are drawn on top of the first.
+	r, _, _, _ := g.Image[1].At(1, 1).RGBA()
+	assert.Equal(uint32(0), r>>8)
+	r, _, _, _ = g.Image[2].At(12, 5).RGBA()
+	assert.True(r>>8 > 150)
+
+	filled, err := img.Fill("10x10 TopLeft")
+	assert.NoError(err)
+	assert.Len(decodeTarget(assert, filled).Image, 3)
+}
+
+func TestResizeAnimatedGIFFirstFrame(t *testing.T) {
+	assert := require.New(t)
+
+	spec := newTestResourceSpec(assert)
+	spec.imaging.Animation = animationFirstFrame
+	img := createAnimatedGIF(assert, spec, "anim.gif")
+
+	resized, err := img.Resize("20x")
+	assert.NoError(err)
+	assert.Contains(resized.RelPermalink(), "_first")
+	assert.Len(decodeTarget(assert, resized).Image, 1)
+}
+
+func TestDecodeImagingAnimation(t *testing.T) {
+	assert := require.New(t)
+
+	imaging, err := decodeImaging(map[string]interface{}{})
+	assert.NoError(err)
+	assert.Equal(animationPreserve, imaging.Animation)
+
+	imaging, err = decodeImaging(map[string]interface{}{"animation": "firstFrame"})
+	assert.NoError(err)
+	assert.Equal(animationFirstFrame, imaging.Animation)
+
+	_, err = decodeImaging(map[string]interface{}{"animation": "loop"})
+	assert.Error(err)
+}
+
+func TestIsAPNG(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))))
+	b := buf.Bytes()
+	assert.False(isAPNG(bytes.NewReader(b)))
+
+	// Insert an acTL chunk after the IHDR chunk.
+	ihdrEnd := 8 + 8 + 13 + 4
+	actl := []byte{0, 0, 0, 8, 'a', 'c', 'T', 'L', 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0}
+	apng := append(append(append([]byte{}, b[:ihdrEnd]...), actl...), b[ihdrEnd:]...)
+	assert.True(isAPNG(bytes.NewReader(apng)))
+
+	assert.False(isAPNG(bytes.NewReader([]byte("GIF89a"))))
+}
@@ -14,6 +14,7 @@
 package resource
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -22,6 +23,7 @@ import (
 	"time"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
 )
 
 type imageCache struct {
@@ -30,11 +32,20 @@ type imageCache struct {
 	pathSpec      *helpers.PathSpec
 	mu            sync.RWMutex
 	store         map[string]*Image
+
+	// JSON encoded metadata about images, e.g. colors.
+	meta map[string][]byte
+
+	// Shares the cache between machines, if configured.
+	remote *remoteCache
 }
 
 func (c *imageCache) isInCache(key string) bool {
 	c.mu.RLock()
 	_, found := c.store[key]
+	if !found {
+		_, found = c.meta[key]
+	}
 	c.mu.RUnlock()
 	return found
 }
@@ -47,6 +58,68 @@ func (c *imageCache) deleteByPrefix(prefix string) {
 			delete(c.store, k)
 		}
 	}
+	for k := range c.meta {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.meta, k)
+		}
+	}
+}
+
+// getOrCreateMeta unmarshals the image metadata stored with the given key into
+// v. If not found in memory or in the file cache, create is invoked and the
+// result stored.
+func (c *imageCache) getOrCreateMeta(key string, v interface{}, create func() (interface{}, error)) error {
+	if c.pathSpec.Language != nil {
+		key = strings.TrimPrefix(key, "/"+c.pathSpec.Language.Lang)
+	}
+
+	c.mu.RLock()
+	b, found := c.meta[key]
+	c.mu.RUnlock()
+
+	if !found {
+		fs := c.pathSpec.Fs.Source
+		cacheFilename := filepath.Join(c.absCacheDir, key)
+
+		var err error
+		b, err = afero.ReadFile(fs, cacheFilename)
+		if err != nil && os.IsNotExist(err) {
+			if b = c.getRemote(key, cacheFilename); b != nil {
+				err = nil
+			}
+		}
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+
+			m, err := create()
+			if err != nil {
+				return err
+			}
+
+			b, err = json.Marshal(m)
+			if err != nil {
+				return err
+			}
+
+			if err := fs.MkdirAll(filepath.Dir(cacheFilename), os.FileMode(0755)); err != nil {
+				return err
+			}
+
+			if err := afero.WriteFile(fs, cacheFilename, b, os.FileMode(0644)); err != nil {
+				return err
+			}
+
+			c.putRemote(key, cacheFilename)
+		}
+
+		c.mu.Lock()
+		c.meta[key] = b
+		c.mu.Unlock()
+	}
+
+	return json.Unmarshal(b, v)
 }
 
 func (c *imageCache) getOrCreate(
@@ -79,6 +152,10 @@ func (c *imageCache) getOrCreate(
 
 	r, err := spec.NewResourceFromFilename(nil, c.absPublishDir, cacheFilename, relTargetFilename)
 	notFound := err != nil && os.IsNotExist(err)
+	if notFound && c.getRemote(key, cacheFilename) != nil {
+		r, err = spec.NewResourceFromFilename(nil, c.absPublishDir, cacheFilename, relTargetFilename)
+		notFound = err != nil && os.IsNotExist(err)
+	}
 	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
@@ -88,6 +165,7 @@ func (c *imageCache) getOrCreate(
 		if err != nil {
 			return nil, err
 		}
+		c.putRemote(key, cacheFilename)
 	} else {
 		img = r.(*Image)
 	}
@@ -111,8 +189,54 @@ func (c *imageCache) getOrCreate(
 
 }
 
+// getRemote fetches the entry with the given key from the remote cache into
+// the file cache. It returns nil if there is no remote cache or the entry is
+// not found. Failures are logged, the entry is then created locally.
+func (c *imageCache) getRemote(key, cacheFilename string) []byte {
+	if c.remote == nil {
+		return nil
+	}
+
+	b, err := c.remote.get(key)
+	if err != nil {
+		helpers.DistinctWarnLog.Println(err)
+		return nil
+	}
+	if b == nil {
+		return nil
+	}
+
+	fs := c.pathSpec.Fs.Source
+	if err := fs.MkdirAll(filepath.Dir(cacheFilename), os.FileMode(0755)); err != nil {
+		helpers.DistinctWarnLog.Println(err)
+		return nil
+	}
+	if err := afero.WriteFile(fs, cacheFilename, b, os.FileMode(0644)); err != nil {
+		helpers.DistinctWarnLog.Println(err)
+		return nil
+	}
+
+	return b
+}
+
+// putRemote stores the file cache entry with the given key in the remote
+// cache, if configured.
+func (c *imageCache) putRemote(key, cacheFilename string) {
+	if c.remote == nil || c.remote.cfg.ReadOnly {
+		return
+	}
+
+	b, err := afero.ReadFile(c.pathSpec.Fs.Source, cacheFilename)
+	if err == nil {
+		err = c.remote.put(key, b)
+	}
+	if err != nil {
+		helpers.DistinctWarnLog.Println(err)
+	}
+}
+
 func newImageCache(ps *helpers.PathSpec, absCacheDir, absPublishDir string) *imageCache {
-	return &imageCache{pathSpec: ps, store: make(map[string]*Image), absCacheDir: absCacheDir, absPublishDir: absPublishDir}
+	return &imageCache{pathSpec: ps, store: make(map[string]*Image), meta: make(map[string][]byte), absCacheDir: absCacheDir, absPublishDir: absPublishDir}
 }
 
 func timeTrack(start time.Time, name string) {
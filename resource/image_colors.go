@@ -0,0 +1,168 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/disintegration/imaging"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+const (
+	// The number of dominant colors returned by Colors.
+	numDominantColors = 5
+
+	// Images are scaled down to fit this size before the colors are sampled.
+	colorsSampleSize = 100
+
+	// Below this luminance white text gives better contrast than black.
+	darkLuminanceThreshold = 0.179
+)
+
+// ImageColors holds color information about an image, e.g. to render
+// placeholder backgrounds or to pick a readable text color for overlays.
+type ImageColors struct {
+	// The dominant colors as hex strings, e.g. "#4a7ea8", most dominant first.
+	Dominant []string
+
+	// The average color as a hex string.
+	Average string
+
+	// The average relative luminance, from 0 (black) to 1 (white).
+	Luminance float64
+}
+
+// IsDark returns whether white text gives better contrast on this image than
+// black text.
+func (c *ImageColors) IsDark() bool {
+	return c.Luminance < darkLuminanceThreshold
+}
+
+// Colors returns the dominant colors and the luminance of the image.
+// The result is stored in the resource cache.
+func (i *Image) Colors() (*ImageColors, error) {
+	var colors ImageColors
+
+	key := i.relPermalinkForRel(i.metaFilename("colors"), false)
+
+	err := i.spec.imageCache.getOrCreateMeta(key, &colors, func() (interface{}, error) {
+		return i.processSource(func(src image.Image) (interface{}, error) {
+			return extractImageColors(src, numDominantColors), nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &colors, nil
+}
+
+// metaFilename returns the relative filename used to store metadata about
+// this image with the given name.
+func (i *Image) metaFilename(name string) string {
+	p1, _ := helpers.FileAndExt(i.rel)
+	return fmt.Sprintf("%s_hu%s_%d_%s.json", p1, i.hash, i.osFileInfo.Size(), name)
+}
+
+type colorBucket struct {
+	key     uint32
+	r, g, b uint64
+	count   uint64
+}
+
+func extractImageColors(src image.Image, count int) ImageColors {
+	img := imaging.Fit(src, colorsSampleSize, colorsSampleSize, imaging.Box)
+
+	var (
+		buckets          = make(map[uint32]*colorBucket)
+		sumR, sumG, sumB uint64
+		sumLuminance     float64
+		total            uint64
+	)
+
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b, a := img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]
+		if a < 128 {
+			// Mostly transparent.
+			continue
+		}
+
+		// Quantize to 4 bits per channel.
+		key := uint32(r>>4)<<8 | uint32(g>>4)<<4 | uint32(b>>4)
+		bucket, found := buckets[key]
+		if !found {
+			bucket = &colorBucket{key: key}
+			buckets[key] = bucket
+		}
+		bucket.r += uint64(r)
+		bucket.g += uint64(g)
+		bucket.b += uint64(b)
+		bucket.count++
+
+		sumR += uint64(r)
+		sumG += uint64(g)
+		sumB += uint64(b)
+		sumLuminance += relativeLuminance(r, g, b)
+		total++
+	}
+
+	var colors ImageColors
+
+	if total == 0 {
+		return colors
+	}
+
+	sorted := make([]*colorBucket, 0, len(buckets))
+	for _, b := range buckets {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count == sorted[j].count {
+			return sorted[i].key < sorted[j].key
+		}
+		return sorted[i].count > sorted[j].count
+	})
+
+	for i := 0; i < count && i < len(sorted); i++ {
+		b := sorted[i]
+		colors.Dominant = append(colors.Dominant, hexColor(b.r/b.count, b.g/b.count, b.b/b.count))
+	}
+
+	colors.Average = hexColor(sumR/total, sumG/total, sumB/total)
+	colors.Luminance = math.Floor(sumLuminance/float64(total)*1000+0.5) / 1000
+
+	return colors
+}
+
+func hexColor(r, g, b uint64) string {
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// relativeLuminance returns the relative luminance of a sRGB color as
+// defined in https://www.w3.org/TR/WCAG20/#relativeluminancedef
+func relativeLuminance(r, g, b uint8) float64 {
+	linear := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractImageColors(t *testing.T) {
+	assert := require.New(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if x < 7 {
+				c = color.NRGBA{R: 0, G: 0, B: 255, A: 255}
+			}
+			if y == 0 {
+				c.A = 0
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	colors := extractImageColors(img, 5)
+	assert.Equal([]string{"#0000ff", "#ffffff"}, colors.Dominant)
+	assert.Equal("#4c4cff", colors.Average)
+	assert.Equal(0.351, colors.Luminance)
+	assert.False(colors.IsDark())
+
+	transparent := extractImageColors(image.NewNRGBA(image.Rect(0, 0, 5, 5)), 5)
+	assert.Len(transparent.Dominant, 0)
+	assert.True(transparent.IsDark())
+}
+
+func TestImageColors(t *testing.T) {
+	assert := require.New(t)
+
+	image := fetchSunset(assert)
+
+	colors, err := image.Colors()
+	assert.NoError(err)
+	assert.Len(colors.Dominant, numDominantColors)
+	assert.Regexp("^#[0-9a-f]{6}$", colors.Average)
+	assert.True(colors.Luminance > 0 && colors.Luminance < 1)
+
+	cacheFilename := filepath.Join("/res/_gen/images", image.relPermalinkForRel(image.metaFilename("colors"), false))
+	exists, err := afero.Exists(image.spec.Fs.Source, cacheFilename)
+	assert.NoError(err)
+	assert.True(exists)
+	assert.True(image.spec.IsInCache(image.relPermalinkForRel(image.metaFilename("colors"), false)))
+
+	// Second call is served from the cache.
+	colors2, err := image.Colors()
+	assert.NoError(err)
+	assert.Equal(colors, colors2)
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gohugoio/hugo/helpers"
+)
+
+const defaultPlaceholderSize = 100
+
+var (
+	errorPlaceholderBackground = color.NRGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+	errorPlaceholderForeground = color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+)
+
+// ImageError is returned when an image operation fails, e.g. because the
+// source image is missing or corrupt.
+type ImageError struct {
+	// The operation, e.g. "resize".
+	Action string
+
+	// The spec given to the operation, e.g. "300x200".
+	Spec string
+
+	// The source filename, relative to the working dir if possible.
+	Filename string
+
+	Err error
+}
+
+func (e *ImageError) Error() string {
+	return fmt.Sprintf("failed to %s image %q with %q: %s", e.Action, e.Filename, e.Spec, e.Err)
+}
+
+func (i *Image) newImageError(action, spec string, err error) *ImageError {
+	filename := i.AbsSourceFilename()
+	if wd := i.spec.WorkingDir(); wd != "" {
+		if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+	}
+
+	return &ImageError{Action: action, Spec: spec, Filename: filepath.ToSlash(filename), Err: err}
+}
+
+// handleImageError returns a placeholder image for images that fail to
+// process if imaging.errorPlaceholder is set and we are running the server,
+// so a broken image does not stop development. Otherwise the error is
+// returned.
+func (i *Image) handleImageError(conf imageConfig, err *ImageError) (*Image, error) {
+	if !i.imaging.ErrorPlaceholder || !i.spec.Running {
+		return nil, err
+	}
+
+	helpers.DistinctErrorLog.Printf("%s; using a placeholder image", err)
+
+	return i.placeholderImage(conf)
+}
+
+// placeholderImage creates a placeholder image with the dimensions given in
+// conf in place of the processed image.
+func (i *Image) placeholderImage(conf imageConfig) (*Image, error) {
+	ci := i.clone()
+	ci.setBasePath(conf)
+
+	img := errorPlaceholder(conf.Width, conf.Height)
+	b := img.Bounds()
+	ci.config = image.Config{Width: b.Dx(), Height: b.Dy()}
+	ci.configLoaded = true
+
+	// The placeholder is not stored in the resource cache, so the real
+	// image is created as soon as the source is fixed.
+	if err := i.encodeToDestinations(img, conf, "", ci.target()); err != nil {
+		return nil, err
+	}
+
+	return ci, nil
+}
+
+// errorPlaceholder creates a grey image crossed out from corner to corner. If
+// only one dimension is given, the image is square.
+func errorPlaceholder(width, height int) image.Image {
+	if width <= 0 && height <= 0 {
+		width, height = defaultPlaceholderSize, defaultPlaceholderSize
+	} else if width <= 0 {
+		width = height
+	} else if height <= 0 {
+		height = width
+	}
+
+	img := imaging.New(width, height, errorPlaceholderBackground)
+
+	steps := width
+	if height > steps {
+		steps = height
+	}
+
+	for s := 0; s < steps; s++ {
+		x := s * width / steps
+		y := s * height / steps
+		img.Set(x, y, errorPlaceholderForeground)
+		img.Set(width-1-x, y, errorPlaceholderForeground)
+	}
+
+	return img
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fetchCorruptImage(assert *require.Assertions, spec *Spec) *Image {
+	out, err := spec.Fs.Source.Create("/b/broken.jpg")
+	assert.NoError(err)
+	_, err = out.Write([]byte("not a JPEG"))
+	assert.NoError(err)
+	out.Close()
+
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/broken.jpg", "broken.jpg")
+	assert.NoError(err)
+	return r.(*Image)
+}
+
+func TestImageError(t *testing.T) {
+	assert := require.New(t)
+
+	spec := newTestResourceSpec(assert)
+	img := fetchCorruptImage(assert, spec)
+
+	resized, err := img.Resize("300x200")
+	assert.Nil(resized)
+	assert.Error(err)
+	imgErr, ok := err.(*ImageError)
+	assert.True(ok)
+	assert.Equal("resize", imgErr.Action)
+	assert.Equal("/b/broken.jpg", imgErr.Filename)
+	assert.Contains(err.Error(), `failed to resize image "/b/broken.jpg" with "300x200"`)
+
+	// Placeholders are only used when running the server.
+	img.imaging.ErrorPlaceholder = true
+	_, err = img.Fill("300x200")
+	assert.Error(err)
+
+	spec.Running = true
+	filled, err := img.Fill("300x200")
+	assert.NoError(err)
+	assert.Equal(300, filled.Width())
+	assert.Equal(200, filled.Height())
+
+	exists, err := spec.Fs.Destination.Stat("/public" + filled.RelPermalink())
+	assert.NoError(err)
+	assert.NotNil(exists)
+
+	// Not cached.
+	assert.False(spec.IsInCache(filled.RelPermalink()))
+}
+
+func TestErrorPlaceholderDimensions(t *testing.T) {
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		width, height             int
+		expectWidth, expectHeight int
+	}{
+		{300, 200, 300, 200},
+		{300, 0, 300, 300},
+		{0, 50, 50, 50},
+		{0, 0, defaultPlaceholderSize, defaultPlaceholderSize},
+	} {
+		b := errorPlaceholder(test.width, test.height).Bounds()
+		assert.Equal(test.expectWidth, b.Dx())
+		assert.Equal(test.expectHeight, b.Dy())
+	}
+}
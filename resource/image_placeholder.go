@@ -0,0 +1,139 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// The width of the blurred placeholder image.
+	placeholderBlurWidth = 16
+	placeholderBlurSigma = 0.6
+
+	// The width of the grid used to trace the image.
+	placeholderTraceWidth = 32
+)
+
+// Placeholder returns a tiny version of the image as a data URI, to be shown
+// while the image itself is loading. Supported kinds are "blur", a small
+// blurred JPEG, and "trace", an SVG with the darker shapes of the image
+// drawn in its dominant dark color.
+// The result is stored in the resource cache.
+func (i *Image) Placeholder(kind string) (template.URL, error) {
+	kind = strings.ToLower(kind)
+
+	var create func(src image.Image) (string, error)
+
+	switch kind {
+	case "blur":
+		create = blurPlaceholder
+	case "trace":
+		create = tracePlaceholder
+	default:
+		return "", fmt.Errorf("unknown placeholder kind %q, must be one of blur or trace", kind)
+	}
+
+	var uri string
+
+	key := i.relPermalinkForRel(i.metaFilename("placeholder_"+kind), false)
+
+	err := i.spec.imageCache.getOrCreateMeta(key, &uri, func() (interface{}, error) {
+		return i.processSource(func(src image.Image) (interface{}, error) {
+			return create(src)
+		})
+	})
+
+	return template.URL(uri), err
+}
+
+func blurPlaceholder(src image.Image) (string, error) {
+	img := imaging.Resize(src, placeholderBlurWidth, 0, imaging.Box)
+	img = imaging.Blur(img, placeholderBlurSigma)
+
+	// JPEG does not support transparency, so flatten it on white.
+	bg := imaging.New(img.Bounds().Dx(), img.Bounds().Dy(), image.White)
+	flattened := imaging.Overlay(bg, img, image.Pt(0, 0), 1.0)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flattened, &jpeg.Options{Quality: 40}); err != nil {
+		return "", err
+	}
+
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func tracePlaceholder(src image.Image) (string, error) {
+	img := imaging.Resize(src, placeholderTraceWidth, 0, imaging.Box)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// Pixels darker than the average are part of the traced shapes.
+	var (
+		luminances       = make([]float64, w*h)
+		sum              float64
+		sumR, sumG, sumB uint64
+		dark             uint64
+	)
+
+	for i := 0; i < w*h; i++ {
+		r, g, b := img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2]
+		luminances[i] = relativeLuminance(r, g, b)
+		sum += luminances[i]
+	}
+
+	threshold := sum / float64(w*h)
+
+	var rects bytes.Buffer
+
+	for y := 0; y < h; y++ {
+		start := -1
+		for x := 0; x <= w; x++ {
+			isDark := x < w && luminances[y*w+x] < threshold
+			if isDark {
+				p := (y*w + x) * 4
+				sumR += uint64(img.Pix[p])
+				sumG += uint64(img.Pix[p+1])
+				sumB += uint64(img.Pix[p+2])
+				dark++
+				if start == -1 {
+					start = x
+				}
+				continue
+			}
+			if start != -1 {
+				// Merge the horizontal runs to keep the SVG small.
+				fmt.Fprintf(&rects, `<rect x="%d" y="%d" width="%d" height="1"/>`, start, y, x-start)
+				start = -1
+			}
+		}
+	}
+
+	fill := "#000000"
+	if dark > 0 {
+		fill = hexColor(sumR/dark, sumG/dark, sumB/dark)
+	}
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" preserveAspectRatio="none"><g fill="%s" shape-rendering="crispEdges">%s</g></svg>`,
+		w, h, fill, rects.String())
+
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg)), nil
+}
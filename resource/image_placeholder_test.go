@@ -0,0 +1,77 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/base64"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracePlaceholder(t *testing.T) {
+	assert := require.New(t)
+
+	// Black left half, white right half.
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if x < 32 {
+				c = color.NRGBA{A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	uri, err := tracePlaceholder(img)
+	assert.NoError(err)
+
+	const prefix = "data:image/svg+xml;base64,"
+	assert.True(strings.HasPrefix(uri, prefix))
+
+	svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, prefix))
+	assert.NoError(err)
+	assert.Contains(string(svg), `viewBox="0 0 32 16"`)
+	assert.Contains(string(svg), `fill="#000000"`)
+	assert.Contains(string(svg), `<rect x="0" y="0" width="16" height="1"/>`)
+	assert.Equal(16, strings.Count(string(svg), "<rect"))
+}
+
+func TestImagePlaceholder(t *testing.T) {
+	assert := require.New(t)
+
+	image := fetchSunset(assert)
+
+	blur, err := image.Placeholder("blur")
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(string(blur), "data:image/jpeg;base64,"))
+
+	trace, err := image.Placeholder("Trace")
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(string(trace), "data:image/svg+xml;base64,"))
+
+	assert.True(image.spec.IsInCache(image.relPermalinkForRel(image.metaFilename("placeholder_blur"), false)))
+
+	// Second call is served from the cache.
+	blur2, err := image.Placeholder("blur")
+	assert.NoError(err)
+	assert.Equal(blur, blur2)
+
+	_, err = image.Placeholder("pixelate")
+	assert.Error(err)
+}
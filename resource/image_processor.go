@@ -0,0 +1,116 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// How often to report the progress while images are processed.
+const imageProgressInterval = time.Second
+
+// ImageProcessor limits the number of images processed concurrently, so an
+// image heavy first build does not use all the CPU and memory.
+// Images are only processed when requested by the pages being rendered, so
+// the queue only holds images needed by those pages. They are processed in
+// the order requested; there is no other prioritization.
+type ImageProcessor struct {
+	sem chan struct{}
+
+	// Called with the number of images processed in this build and the
+	// number of images waiting or being processed, while images are
+	// processed, if set. The total number of images in the build is not
+	// known up front, as the images are requested by the templates.
+	Progress func(done, queued int)
+
+	progressInterval time.Duration
+
+	mu         sync.Mutex
+	done       int
+	queued     int
+	lastReport time.Time
+	reported   bool
+}
+
+// NewImageProcessor creates a new ImageProcessor processing at most the
+// given number of images at a time. If workers is 0 or less, the number of
+// CPUs is used.
+func NewImageProcessor(workers int) *ImageProcessor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &ImageProcessor{sem: make(chan struct{}, workers), progressInterval: imageProgressInterval}
+}
+
+// Reset starts counting the processed images again, e.g. for a rebuild.
+func (p *ImageProcessor) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done, p.reported = 0, false
+}
+
+// Process runs f when a worker is available.
+func (p *ImageProcessor) Process(f func() error) error {
+	p.mu.Lock()
+	if p.queued == 0 {
+		p.lastReport = time.Now()
+	}
+	p.queued++
+	p.mu.Unlock()
+
+	p.sem <- struct{}{}
+	defer func() {
+		<-p.sem
+		p.processed()
+	}()
+
+	return f()
+}
+
+func (p *ImageProcessor) processed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.queued--
+	finished := p.queued == 0
+
+	if p.Progress != nil {
+		// Only report when processing takes a while, and then also
+		// report when the queue is done.
+		if (finished && p.reported) || (!finished && time.Since(p.lastReport) >= p.progressInterval) {
+			p.Progress(p.done, p.queued)
+			p.lastReport = time.Now()
+			p.reported = !finished
+		}
+	}
+}
+
+// processSource decodes the source image and calls f with it when a worker
+// is available.
+func (i *Image) processSource(f func(src image.Image) (interface{}, error)) (interface{}, error) {
+	var v interface{}
+	err := i.spec.ImageProcessor.Process(func() error {
+		src, err := i.decodeSource()
+		if err != nil {
+			return err
+		}
+		v, err = f(src)
+		return err
+	})
+	return v, err
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageProcessorLimit(t *testing.T) {
+	assert := require.New(t)
+
+	p := NewImageProcessor(2)
+
+	var (
+		wg              sync.WaitGroup
+		running, maxRun int32
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Process(func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRun)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRun, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	assert.True(maxRun <= 2)
+	assert.True(maxRun > 0)
+
+	err := errors.New("failed")
+	assert.Equal(err, p.Process(func() error { return err }))
+}
+
+func TestImageProcessorProgress(t *testing.T) {
+	assert := require.New(t)
+
+	p := NewImageProcessor(1)
+
+	var reports [][2]int
+	p.Progress = func(done, queued int) {
+		reports = append(reports, [2]int{done, queued})
+	}
+
+	// Fast batches are not reported.
+	p.Process(func() error { return nil })
+	assert.Len(reports, 0)
+
+	p.progressInterval = 0
+
+	// Queue a second image while the first is processed.
+	started := make(chan bool)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.Process(func() error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}()
+	<-started
+	p.Process(func() error { return nil })
+	wg.Wait()
+
+	// The images processed before are counted until reset.
+	assert.Equal([][2]int{{2, 1}, {3, 0}}, reports)
+
+	reports = nil
+	p.Reset()
+	p.Process(func() error { return nil })
+	assert.Len(reports, 0)
+}
+
+func TestSkipImages(t *testing.T) {
+	assert := require.New(t)
+
+	image := fetchSunset(assert)
+	image.spec.SkipImages = true
+
+	resized, err := image.Resize("300x200")
+	assert.NoError(err)
+	assert.Equal(300, resized.Width())
+	assert.Equal(200, resized.Height())
+	assert.False(image.spec.IsInCache(resized.RelPermalink()))
+}
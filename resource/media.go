@@ -0,0 +1,495 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gohugoio/hugo/helpers"
+)
+
+var (
+	_ Resource = (*Media)(nil)
+	_ Source   = (*Media)(nil)
+	_ Cloner   = (*Media)(nil)
+)
+
+// The media types of the audio and video files we can read the metadata
+// of. These are also used when the system has no MIME type for them.
+var mediaFileTypes = map[string]string{
+	".mp3": "audio/mpeg",
+	".m4a": "audio/mp4",
+	".wav": "audio/wav",
+	".mp4": "video/mp4",
+	".m4v": "video/x-m4v",
+	".mov": "video/quicktime",
+}
+
+func mediaFileType(filename string) string {
+	return mediaFileTypes[strings.ToLower(filepath.Ext(filename))]
+}
+
+// Media is an audio or video resource, e.g. a podcast episode. The metadata
+// is read from the file when first needed.
+type Media struct {
+	metaInit sync.Once
+	meta     mediaMetadata
+
+	*genericResource
+}
+
+type mediaMetadata struct {
+	duration time.Duration
+
+	// In bits per second.
+	bitrate int
+
+	width  int
+	height int
+}
+
+// Implement the Cloner interface.
+func (m *Media) WithNewBase(base string) Resource {
+	return &Media{genericResource: m.genericResource.WithNewBase(base).(*genericResource)}
+}
+
+// Duration returns the playing time, e.g. for podcast feeds:
+//
+//   <itunes:duration>{{ int .Duration.Seconds }}</itunes:duration>
+func (m *Media) Duration() time.Duration {
+	m.initMetadata()
+	return m.meta.duration
+}
+
+// Bitrate returns the average bitrate in bits per second.
+func (m *Media) Bitrate() int {
+	m.initMetadata()
+	return m.meta.bitrate
+}
+
+// Width returns the width of the video, 0 for audio.
+func (m *Media) Width() int {
+	m.initMetadata()
+	return m.meta.width
+}
+
+// Height returns the height of the video, 0 for audio.
+func (m *Media) Height() int {
+	m.initMetadata()
+	return m.meta.height
+}
+
+// Size returns the file size in bytes, e.g. for the length of RSS
+// enclosures.
+func (m *Media) Size() int64 {
+	if m.osFileInfo == nil {
+		return 0
+	}
+	return m.osFileInfo.Size()
+}
+
+func (m *Media) initMetadata() {
+	m.metaInit.Do(func() {
+		f, err := m.spec.Fs.Source.Open(m.AbsSourceFilename())
+		if err != nil {
+			helpers.DistinctWarnLog.Printf("Failed to read the metadata of %q: %s", m.rel, err)
+			return
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			helpers.DistinctWarnLog.Printf("Failed to read the metadata of %q: %s", m.rel, err)
+			return
+		}
+
+		meta, err := readMediaMetadata(f, fi.Size(), strings.ToLower(filepath.Ext(m.rel)))
+		if err != nil {
+			helpers.DistinctWarnLog.Printf("Failed to read the metadata of %q: %s", m.rel, err)
+			return
+		}
+
+		m.meta = meta
+	})
+}
+
+func readMediaMetadata(r io.ReadSeeker, size int64, ext string) (mediaMetadata, error) {
+	switch ext {
+	case ".mp3":
+		return readMP3Metadata(r, size)
+	case ".wav":
+		return readWAVMetadata(r, size)
+	case ".m4a", ".mp4", ".m4v", ".mov":
+		return readMP4Metadata(r, size)
+	}
+	return mediaMetadata{}, fmt.Errorf("unsupported media file %q", ext)
+}
+
+func durationFromSeconds(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func bitrateFor(size int64, d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(float64(size*8) / d.Seconds())
+}
+
+// MP3
+
+var (
+	// In kbit/s, by MPEG version (1, 2 and 2.5) for Layer III.
+	mp3Bitrates = [2][16]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+
+	// By MPEG version 1, 2 and 2.5.
+	mp3SampleRates = [3][4]int{
+		{44100, 48000, 32000, 0},
+		{22050, 24000, 16000, 0},
+		{11025, 12000, 8000, 0},
+	}
+)
+
+type mp3Frame struct {
+	// 0 for MPEG 1, 1 for MPEG 2, 2 for MPEG 2.5.
+	version    int
+	mono       bool
+	bitrate    int
+	sampleRate int
+	samples    int
+}
+
+func parseMP3FrameHeader(h uint32) (mp3Frame, bool) {
+	var f mp3Frame
+
+	if h>>21 != 0x7FF {
+		return f, false
+	}
+
+	switch (h >> 19) & 3 {
+	case 3:
+		f.version = 0
+	case 2:
+		f.version = 1
+	case 0:
+		f.version = 2
+	default:
+		return f, false
+	}
+
+	// Layer III only.
+	if (h>>17)&3 != 1 {
+		return f, false
+	}
+
+	bitrateTable := 0
+	if f.version > 0 {
+		bitrateTable = 1
+	}
+
+	f.bitrate = mp3Bitrates[bitrateTable][(h>>12)&0xF] * 1000
+	f.sampleRate = mp3SampleRates[f.version][(h>>10)&3]
+	if f.bitrate == 0 || f.sampleRate == 0 {
+		return f, false
+	}
+
+	f.mono = (h>>6)&3 == 3
+	f.samples = 1152
+	if f.version > 0 {
+		f.samples = 576
+	}
+
+	return f, true
+}
+
+// xingOffset returns the offset of the Xing header from the frame start.
+func (f mp3Frame) xingOffset() int {
+	switch {
+	case f.version == 0 && !f.mono:
+		return 4 + 32
+	case f.version == 0, !f.mono:
+		return 4 + 17
+	default:
+		return 4 + 9
+	}
+}
+
+func readMP3Metadata(r io.ReadSeeker, size int64) (mediaMetadata, error) {
+	var meta mediaMetadata
+
+	// Skip the ID3v2 tag.
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return meta, err
+	}
+
+	var start int64
+	if string(header[:3]) == "ID3" {
+		tagSize := int64(header[6]&0x7F)<<21 | int64(header[7]&0x7F)<<14 | int64(header[8]&0x7F)<<7 | int64(header[9]&0x7F)
+		start = 10 + tagSize
+		if header[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return meta, err
+	}
+
+	// The first frame is within the first few KB.
+	buf := make([]byte, 64*1024)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return meta, err
+	}
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		f, ok := parseMP3FrameHeader(binary.BigEndian.Uint32(buf[i:]))
+		if !ok {
+			continue
+		}
+
+		frame := buf[i:]
+		audioSize := size - start - int64(i)
+
+		// VBR files have a Xing (or Info) or VBRI header with the number of
+		// frames in the first frame.
+		if xo := f.xingOffset(); xo+16 <= len(frame) {
+			if tag := string(frame[xo : xo+4]); tag == "Xing" || tag == "Info" {
+				flags := binary.BigEndian.Uint32(frame[xo+4:])
+				pos := xo + 8
+				if flags&1 != 0 {
+					frames := binary.BigEndian.Uint32(frame[pos:])
+					meta.duration = durationFromSeconds(float64(frames) * float64(f.samples) / float64(f.sampleRate))
+					pos += 4
+					if flags&2 != 0 && pos+4 <= len(frame) {
+						audioSize = int64(binary.BigEndian.Uint32(frame[pos:]))
+					}
+					meta.bitrate = bitrateFor(audioSize, meta.duration)
+					return meta, nil
+				}
+			}
+		}
+		if 36+18 <= len(frame) && string(frame[36:40]) == "VBRI" {
+			audioSize = int64(binary.BigEndian.Uint32(frame[36+10:]))
+			frames := binary.BigEndian.Uint32(frame[36+14:])
+			meta.duration = durationFromSeconds(float64(frames) * float64(f.samples) / float64(f.sampleRate))
+			meta.bitrate = bitrateFor(audioSize, meta.duration)
+			return meta, nil
+		}
+
+		// Constant bitrate.
+		meta.bitrate = f.bitrate
+		meta.duration = durationFromSeconds(float64(audioSize*8) / float64(f.bitrate))
+		return meta, nil
+	}
+
+	return meta, errors.New("no MP3 frame found")
+}
+
+// WAV
+
+func readWAVMetadata(r io.ReadSeeker, size int64) (mediaMetadata, error) {
+	var meta mediaMetadata
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return meta, err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return meta, errors.New("not a WAV file")
+	}
+
+	var byteRate uint32
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return meta, errors.New("no WAV data chunk found")
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch string(chunk[:4]) {
+		case "fmt ":
+			fmtChunk := make([]byte, 12)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return meta, err
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:])
+			chunkSize -= 12
+		case "data":
+			if byteRate == 0 {
+				return meta, errors.New("invalid WAV file, no format before the data")
+			}
+			meta.bitrate = int(byteRate) * 8
+			meta.duration = durationFromSeconds(float64(chunkSize) / float64(byteRate))
+			return meta, nil
+		}
+
+		// Chunks are padded to an even size.
+		if _, err := r.Seek(chunkSize+chunkSize%2, io.SeekCurrent); err != nil {
+			return meta, err
+		}
+	}
+}
+
+// MP4, including M4A and QuickTime.
+
+// The boxes containing the boxes we read.
+var mp4ContainerBoxes = map[string]bool{
+	"moov": true,
+	"trak": true,
+}
+
+func readMP4Metadata(r io.ReadSeeker, size int64) (mediaMetadata, error) {
+	var meta mediaMetadata
+
+	found := false
+	if err := walkMP4Boxes(r, 0, size, func(typ string, data []byte) {
+		switch typ {
+		case "mvhd":
+			if d, ok := parseMVHD(data); ok {
+				meta.duration = d
+				found = true
+			}
+		case "tkhd":
+			if w, h, ok := parseTKHD(data); ok && w*h > meta.width*meta.height {
+				meta.width, meta.height = w, h
+			}
+		}
+	}); err != nil {
+		return meta, err
+	}
+
+	if !found {
+		return meta, errors.New("no MP4 movie header found")
+	}
+
+	meta.bitrate = bitrateFor(size, meta.duration)
+
+	return meta, nil
+}
+
+// walkMP4Boxes calls fn with the mvhd and tkhd boxes in the given range,
+// descending into the container boxes.
+func walkMP4Boxes(r io.ReadSeeker, start, end int64, fn func(typ string, data []byte)) error {
+	header := make([]byte, 16)
+	pos := start
+
+	for pos+8 <= end {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return err
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		typ := string(header[4:8])
+		headerSize := int64(8)
+
+		switch boxSize {
+		case 0:
+			boxSize = end - pos
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+
+		if boxSize < headerSize || pos+boxSize > end {
+			return fmt.Errorf("invalid MP4 box %q", typ)
+		}
+
+		switch {
+		case mp4ContainerBoxes[typ]:
+			if err := walkMP4Boxes(r, pos+headerSize, pos+boxSize, fn); err != nil {
+				return err
+			}
+		case typ == "mvhd" || typ == "tkhd":
+			data := make([]byte, boxSize-headerSize)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return err
+			}
+			fn(typ, data)
+		}
+
+		pos += boxSize
+	}
+
+	return nil
+}
+
+func parseMVHD(data []byte) (time.Duration, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+
+	var timescale uint32
+	var duration uint64
+
+	switch data[0] {
+	case 0:
+		if len(data) < 20 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(data[12:])
+		duration = uint64(binary.BigEndian.Uint32(data[16:]))
+	case 1:
+		if len(data) < 32 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(data[20:])
+		duration = binary.BigEndian.Uint64(data[24:])
+	default:
+		return 0, false
+	}
+
+	if timescale == 0 {
+		return 0, false
+	}
+
+	return durationFromSeconds(float64(duration) / float64(timescale)), true
+}
+
+func parseTKHD(data []byte) (int, int, bool) {
+	offset := 76
+	if len(data) > 0 && data[0] == 1 {
+		offset = 88
+	}
+	if len(data) < offset+8 {
+		return 0, 0, false
+	}
+
+	// 16.16 fixed point.
+	width := int(binary.BigEndian.Uint32(data[offset:]) >> 16)
+	height := int(binary.BigEndian.Uint32(data[offset+4:]) >> 16)
+
+	return width, height, width > 0 && height > 0
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// MPEG 1 Layer III, 128 kbit/s, 44.1 kHz, stereo. The frames are 417 bytes.
+const testMP3FrameHeader = 0xFFFB9064
+
+func createMP3(frames int, xing bool) []byte {
+	var buf bytes.Buffer
+
+	// An ID3v2 tag of 20 bytes.
+	buf.WriteString("ID3\x03\x00\x00\x00\x00\x00\x14")
+	buf.Write(make([]byte, 20))
+
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, 417)
+		binary.BigEndian.PutUint32(frame, testMP3FrameHeader)
+		if i == 0 && xing {
+			copy(frame[36:], "Xing")
+			binary.BigEndian.PutUint32(frame[40:], 3)
+			// Pretend the file is twice as long as it is.
+			binary.BigEndian.PutUint32(frame[44:], uint32(2*frames))
+			binary.BigEndian.PutUint32(frame[48:], uint32(2*frames*417))
+		}
+		buf.Write(frame)
+	}
+
+	return buf.Bytes()
+}
+
+func createWAV(seconds int) []byte {
+	var buf bytes.Buffer
+
+	const byteRate = 44100 * 2 * 2
+	dataSize := seconds * byteRate
+
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(3))
+	buf.Write([]byte{1, 2, 3, 0})
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, 2})
+	binary.Write(&buf, binary.LittleEndian, []uint32{44100, byteRate})
+	binary.Write(&buf, binary.LittleEndian, []uint16{4, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+
+	return buf.Bytes()
+}
+
+func mp4Box(typ string, data ...[]byte) []byte {
+	content := bytes.Join(data, nil)
+	b := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(b, uint32(8+len(content)))
+	copy(b[4:], typ)
+	return append(b, content...)
+}
+
+func createMP4(width, height int) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 600)
+	binary.BigEndian.PutUint32(mvhd[16:], 600*90)
+
+	videoTkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(videoTkhd[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(videoTkhd[80:], uint32(height)<<16)
+
+	// Audio tracks have no dimensions.
+	audioTkhd := make([]byte, 84)
+
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom\x00\x00\x02\x00")),
+		mp4Box("mdat", make([]byte, 1000)),
+		mp4Box("moov",
+			mp4Box("mvhd", mvhd),
+			mp4Box("trak", mp4Box("tkhd", audioTkhd)),
+			mp4Box("trak", mp4Box("tkhd", videoTkhd), mp4Box("mdia", make([]byte, 20))),
+		),
+	}, nil)
+}
+
+func newTestMedia(assert *require.Assertions, filename string, content []byte) *Media {
+	spec := newTestResourceSpec(assert)
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b/"+filename, content, 0755))
+
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/"+filename, filename)
+	assert.NoError(err)
+	assert.IsType(&Media{}, r)
+	return r.(*Media)
+}
+
+func TestMediaMP3(t *testing.T) {
+	assert := require.New(t)
+
+	mp3 := createMP3(100, false)
+	m := newTestMedia(assert, "episode.mp3", mp3)
+
+	assert.Equal("audio", m.ResourceType())
+	assert.Equal("audio/mpeg", m.MediaType())
+	assert.Equal(128000, m.Bitrate())
+	assert.Equal(int64(len(mp3)), m.Size())
+	assert.Equal(0, m.Width())
+	// 100 frames of 417 bytes at 128 kbit/s.
+	assert.InDelta(2.606, m.Duration().Seconds(), 0.001)
+
+	m = newTestMedia(assert, "vbr.mp3", createMP3(100, true))
+	// 200 frames of 1152 samples at 44.1 kHz.
+	assert.InDelta(5.224, m.Duration().Seconds(), 0.001)
+	assert.InDelta(127700, m.Bitrate(), 500)
+}
+
+func TestMediaWAV(t *testing.T) {
+	assert := require.New(t)
+
+	m := newTestMedia(assert, "sound.wav", createWAV(2))
+	assert.Equal("audio", m.ResourceType())
+	assert.Equal(2*time.Second, m.Duration())
+	assert.Equal(44100*2*2*8, m.Bitrate())
+}
+
+func TestMediaMP4(t *testing.T) {
+	assert := require.New(t)
+
+	mp4 := createMP4(1280, 720)
+	m := newTestMedia(assert, "clip.mp4", mp4)
+
+	assert.Equal("video", m.ResourceType())
+	assert.Equal("video/mp4", m.MediaType())
+	assert.Equal(90*time.Second, m.Duration())
+	assert.Equal(1280, m.Width())
+	assert.Equal(720, m.Height())
+	assert.Equal(len(mp4)*8/90, m.Bitrate())
+}
+
+func TestMediaInvalid(t *testing.T) {
+	assert := require.New(t)
+
+	m := newTestMedia(assert, "broken.mp3", []byte("not an MP3 file"))
+	assert.Equal(time.Duration(0), m.Duration())
+	assert.Equal(0, m.Bitrate())
+}
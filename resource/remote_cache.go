@@ -0,0 +1,192 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gohugoio/hugo/config"
+	"github.com/mitchellh/mapstructure"
+)
+
+const defaultRemoteCacheTimeout = 30 * time.Second
+
+// RemoteCacheConfig configures a remote store backing the image cache in
+// resourceDir/_gen, so processed images can be shared between machines, e.g.
+// CI runners and teammates. Any HTTP server supporting GET and PUT with
+// static headers for authorization can be used. The requests are not
+// signed, so S3 or GCS buckets can only be used through a proxy or with
+// public access.
+//
+//   [resourceCache]
+//   url = "https://cache.example.com/hugo"
+//   [resourceCache.headers]
+//   Authorization = "$CACHE_TOKEN"
+//
+// The cache keys contain a hash of the source image, so a cached image is
+// never used for a changed source.
+type RemoteCacheConfig struct {
+	URL string
+
+	// Only read from the remote cache, e.g. on developer machines when the
+	// CI server fills the cache.
+	ReadOnly bool
+
+	// Headers added to the requests, e.g. for authorization. Values starting
+	// with a "$" are read from the environment.
+	Headers map[string]string
+
+	// The timeout for the requests. Defaults to 30s.
+	Timeout time.Duration
+}
+
+type remoteCache struct {
+	cfg    RemoteCacheConfig
+	client *http.Client
+
+	// Set to 1 on the first connection error. The remote cache is then not
+	// used for the rest of the build, so an unreachable server does not
+	// cost a timeout for every image.
+	disabled int32
+}
+
+func decodeRemoteCacheConfig(cfg config.Provider) (RemoteCacheConfig, error) {
+	var c RemoteCacheConfig
+
+	m := cfg.GetStringMap("resourceCache")
+	if len(m) == 0 {
+		return c, nil
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &c,
+	})
+	if err != nil {
+		return c, err
+	}
+	if err := decoder.Decode(m); err != nil {
+		return c, fmt.Errorf("failed to decode resourceCache config: %s", err)
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaultRemoteCacheTimeout
+	}
+
+	return c, nil
+}
+
+// newRemoteCache creates a new remote cache, nil if none is configured.
+func newRemoteCache(cfg config.Provider) (*remoteCache, error) {
+	c, err := decodeRemoteCacheConfig(cfg)
+	if err != nil || c.URL == "" {
+		return nil, err
+	}
+
+	return &remoteCache{cfg: c, client: &http.Client{Timeout: c.Timeout}}, nil
+}
+
+func (c *remoteCache) url(key string) string {
+	return strings.TrimSuffix(c.cfg.URL, "/") + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (c *remoteCache) newRequest(method, key string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.url(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.cfg.Headers {
+		if strings.HasPrefix(v, "$") {
+			v = os.Getenv(strings.TrimPrefix(v, "$"))
+		}
+		req.Header.Set(k, v)
+	}
+
+	return req, nil
+}
+
+// do sends the request. It returns a nil response without an error if the
+// remote cache is disabled.
+func (c *remoteCache) do(req *http.Request) (*http.Response, error) {
+	if atomic.LoadInt32(&c.disabled) == 1 {
+		return nil, nil
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if atomic.CompareAndSwapInt32(&c.disabled, 0, 1) {
+			return nil, fmt.Errorf("failed to connect to the resource cache, not using it for the rest of the build: %s", err)
+		}
+		return nil, nil
+	}
+
+	return resp, nil
+}
+
+// get fetches the entry with the given key. It returns nil if not found.
+func (c *remoteCache) get(key string) ([]byte, error) {
+	req, err := c.newRequest("GET", key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if resp == nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusForbidden:
+		// Some servers return 403 for missing keys if listing is not allowed.
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to get %q from the resource cache: %s", c.url(key), resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// put stores the entry with the given key, unless the cache is read only.
+func (c *remoteCache) put(key string, b []byte) error {
+	if c.cfg.ReadOnly {
+		return nil
+	}
+
+	req, err := c.newRequest("PUT", key, b)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if resp == nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to put %q to the resource cache: %s", c.url(key), resp.Status)
+	}
+
+	return nil
+}
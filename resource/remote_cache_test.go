@@ -0,0 +1,206 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/media"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+type testCacheServer struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	gets    int
+	puts    int
+	auth    []string
+}
+
+func (s *testCacheServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+
+	switch r.Method {
+	case "GET":
+		s.gets++
+		b, found := s.entries[r.URL.Path]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	case "PUT":
+		s.puts++
+		b, _ := ioutil.ReadAll(r.Body)
+		s.entries[r.URL.Path] = b
+	}
+}
+
+func newTestResourceSpecWithRemoteCache(assert *require.Assertions, cacheCfg map[string]interface{}) *Spec {
+	cfg := viper.New()
+	cfg.Set("baseURL", "https://example.com/")
+	cfg.Set("resourceDir", "/res")
+	cfg.Set("resourceCache", cacheCfg)
+	fs := hugofs.NewMem(cfg)
+
+	s, err := helpers.NewPathSpec(fs, cfg)
+	assert.NoError(err)
+
+	spec, err := NewSpec(s, media.DefaultTypes)
+	assert.NoError(err)
+
+	src, err := os.Open("testdata/sunset.jpg")
+	assert.NoError(err)
+	defer src.Close()
+	out, err := spec.Fs.Source.Create("/b/sunset.jpg")
+	assert.NoError(err)
+	_, err = io.Copy(out, src)
+	out.Close()
+	assert.NoError(err)
+
+	return spec
+}
+
+func fetchSunsetFromSpec(assert *require.Assertions, spec *Spec) *Image {
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/sunset.jpg", "sunset.jpg")
+	assert.NoError(err)
+	return r.(*Image)
+}
+
+func TestRemoteCache(t *testing.T) {
+	assert := require.New(t)
+
+	server := &testCacheServer{entries: make(map[string][]byte)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	os.Setenv("HUGO_TEST_CACHE_TOKEN", "Bearer secret")
+	defer os.Unsetenv("HUGO_TEST_CACHE_TOKEN")
+
+	cacheCfg := map[string]interface{}{
+		"url":     ts.URL + "/hugo/",
+		"headers": map[string]interface{}{"Authorization": "$HUGO_TEST_CACHE_TOKEN"},
+	}
+
+	spec := newTestResourceSpecWithRemoteCache(assert, cacheCfg)
+	resized, err := fetchSunsetFromSpec(assert, spec).Resize("300x200")
+	assert.NoError(err)
+	assert.Equal(300, resized.Width())
+
+	colors, err := fetchSunsetFromSpec(assert, spec).Colors()
+	assert.NoError(err)
+
+	assert.Equal(2, server.puts)
+	assert.Len(server.entries, 2)
+	for _, auth := range server.auth {
+		assert.Equal("Bearer secret", auth)
+	}
+
+	// A fresh clone gets the images from the remote cache.
+	spec = newTestResourceSpecWithRemoteCache(assert, cacheCfg)
+	resized2, err := fetchSunsetFromSpec(assert, spec).Resize("300x200")
+	assert.NoError(err)
+	assert.Equal(resized.RelPermalink(), resized2.RelPermalink())
+	assert.Equal(300, resized2.Width())
+	assert.Equal(200, resized2.Height())
+
+	colors2, err := fetchSunsetFromSpec(assert, spec).Colors()
+	assert.NoError(err)
+	assert.Equal(colors, colors2)
+
+	assert.Equal(2, server.puts)
+	assert.Equal(4, server.gets)
+
+	exists, err := spec.Fs.Destination.Stat(filepath.Join(resized2.absPublishDir, resized2.target()))
+	assert.NoError(err)
+	assert.NotNil(exists)
+
+	// Read only caches are not written to.
+	cacheCfg["readOnly"] = true
+	spec = newTestResourceSpecWithRemoteCache(assert, cacheCfg)
+	_, err = fetchSunsetFromSpec(assert, spec).Resize("100x")
+	assert.NoError(err)
+	assert.Equal(2, server.puts)
+}
+
+func TestRemoteCacheConnectionError(t *testing.T) {
+	assert := require.New(t)
+
+	var mu sync.Mutex
+	requests := 0
+
+	// Close the connections without a response.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer ts.Close()
+
+	spec := newTestResourceSpecWithRemoteCache(assert, map[string]interface{}{"url": ts.URL})
+
+	for _, size := range []string{"300x200", "100x", "x50"} {
+		_, err := fetchSunsetFromSpec(assert, spec).Resize(size)
+		assert.NoError(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(1, requests)
+}
+
+func TestDecodeRemoteCacheConfig(t *testing.T) {
+	assert := require.New(t)
+
+	cfg := viper.New()
+	c, err := decodeRemoteCacheConfig(cfg)
+	assert.NoError(err)
+	assert.Equal("", c.URL)
+
+	cfg.Set("resourceCache", map[string]interface{}{
+		"url":      "https://cache.example.com",
+		"readOnly": "true",
+		"timeout":  "5s",
+	})
+	c, err = decodeRemoteCacheConfig(cfg)
+	assert.NoError(err)
+	assert.Equal("https://cache.example.com", c.URL)
+	assert.True(c.ReadOnly)
+	assert.Equal(5*time.Second, c.Timeout)
+
+	rc := &remoteCache{cfg: c}
+	assert.Equal("https://cache.example.com/a/b.jpg", rc.url("/a/b.jpg"))
+}
@@ -55,17 +55,6 @@ type Resource interface {
 // I.e. both pages and images etc.
 type Resources []Resource
 
-func (r Resources) ByType(tp string) Resources {
-	var filtered Resources
-
-	for _, resource := range r {
-		if resource.ResourceType() == tp {
-			filtered = append(filtered, resource)
-		}
-	}
-	return filtered
-}
-
 // GetBySuffix gets the first resource matching the given filename prefix, e.g
 // "logo" will match logo.png. It returns nil of none found.
 // In potential ambiguous situations, combine it with ByType.
@@ -88,7 +77,23 @@ type Spec struct {
 
 	imageCache *imageCache
 
+	// Set when deduplicateResources is enabled.
+	dedup *resourceDedup
+
+	// The cached Match results, reset on every build.
+	matchCache *resourceMatchCache
+
 	AbsGenImagePath string
+
+	// Whether we are in running (server) mode.
+	Running bool
+
+	// Limits the number of images processed concurrently. This is shared
+	// between the languages.
+	ImageProcessor *ImageProcessor
+
+	// Use placeholders instead of processing images, see --skipImages.
+	SkipImages bool
 }
 
 func NewSpec(s *helpers.PathSpec, mimeTypes media.Types) (*Spec, error) {
@@ -101,14 +106,39 @@ func NewSpec(s *helpers.PathSpec, mimeTypes media.Types) (*Spec, error) {
 
 	genImagePath := s.AbsPathify(filepath.Join(s.Cfg.GetString("resourceDir"), "_gen", "images"))
 
-	return &Spec{AbsGenImagePath: genImagePath, PathSpec: s, imaging: &imaging, mimeTypes: mimeTypes, imageCache: newImageCache(
+	spec := &Spec{AbsGenImagePath: genImagePath, PathSpec: s, imaging: &imaging, mimeTypes: mimeTypes, matchCache: newResourceMatchCache(), imageCache: newImageCache(
 		s,
 		// We're going to write a cache pruning routine later, so make it extremely
 		// unlikely that the user shoots him or herself in the foot
 		// and this is set to a value that represents data he/she
 		// cares about. This should be set in stone once released.
 		genImagePath,
-		s.AbsPathify(s.Cfg.GetString("publishDir")))}, nil
+		s.AbsPathify(s.Cfg.GetString("publishDir")))}
+
+	remote, err := newRemoteCache(s.Cfg)
+	if err != nil {
+		return nil, err
+	}
+	spec.imageCache.remote = remote
+
+	spec.ImageProcessor = NewImageProcessor(s.Cfg.GetInt("imageWorkers"))
+	spec.SkipImages = s.Cfg.GetBool("skipImages")
+
+	if s.Cfg.GetBool("deduplicateResources") {
+		spec.dedup = newResourceDedup()
+	}
+
+	return spec, nil
+}
+
+// ResetBuildState clears the state kept for a build, e.g. the cached Match
+// results and the deduplicated resources published. It must be called before every build, when the resource lists
+// are recreated.
+func (r *Spec) ResetBuildState() {
+	r.matchCache.reset()
+	if r.dedup != nil {
+		r.dedup.reset(r.Fs.Source)
+	}
 }
 
 func (r *Spec) NewResourceFromFile(
@@ -131,26 +161,51 @@ func (r *Spec) NewResourceFromFilename(
 	return r.newResource(linker, absPublishDir, absSourceFilename, fi, relTargetFilename)
 }
 
+// ResourceTypeFor returns the resource type of the file with the given name,
+// e.g. "image" for logo.png.
+func (r *Spec) ResourceTypeFor(filename string) string {
+	ext := filepath.Ext(filename)
+	if m, found := r.mimeTypes.GetBySuffix(strings.TrimPrefix(ext, ".")); found {
+		return m.SubType
+	}
+
+	mimeType := mime.TypeByExtension(ext)
+	if mimeType == "" {
+		mimeType = mediaFileType(filename)
+	}
+	if mimeType == "" {
+		return DefaultResourceType
+	}
+	return mimeType[:strings.Index(mimeType, "/")]
+}
+
 func (r *Spec) newResource(
 	linker func(base string) string,
 	absPublishDir,
 	absSourceFilename string, fi os.FileInfo, relTargetFilename string) (Resource, error) {
 
-	var mimeType string
-	ext := filepath.Ext(relTargetFilename)
-	m, found := r.mimeTypes.GetBySuffix(strings.TrimPrefix(ext, "."))
-	if found {
-		mimeType = m.SubType
-	} else {
-		mimeType = mime.TypeByExtension(ext)
-		if mimeType == "" {
-			mimeType = DefaultResourceType
-		} else {
-			mimeType = mimeType[:strings.Index(mimeType, "/")]
+	mimeType := r.ResourceTypeFor(relTargetFilename)
+
+	gr := r.newGenericResource(linker, fi, absPublishDir, absSourceFilename, filepath.ToSlash(relTargetFilename), mimeType)
+
+	if r.dedup != nil && linker != nil {
+		// A bundle resource.
+		if err := r.addForDedup(gr); err != nil {
+			return nil, err
 		}
 	}
 
-	gr := r.newGenericResource(linker, fi, absPublishDir, absSourceFilename, filepath.ToSlash(relTargetFilename), mimeType)
+	if mediaFileType(relTargetFilename) != "" {
+		return &Media{genericResource: gr}, nil
+	}
+
+	if isFontFilename(relTargetFilename) {
+		return &Font{genericResource: gr}, nil
+	}
+
+	if mimeType == "image" && strings.EqualFold(filepath.Ext(relTargetFilename), ".svg") {
+		return &SVG{genericResource: gr}, nil
+	}
 
 	if mimeType == "image" {
 		f, err := r.Fs.Source.Open(absSourceFilename)
@@ -205,6 +260,9 @@ type genericResource struct {
 	// The relative path to this resource.
 	rel string
 
+	// The name to match the resource by if different from rel, see Mount.
+	name string
+
 	// Base is set when the output format's path has a offset, e.g. for AMP.
 	base string
 
@@ -214,10 +272,23 @@ type genericResource struct {
 	resourceType      string
 	osFileInfo        os.FileInfo
 
+	// Identifies resources with the same content when deduplicateResources
+	// is enabled.
+	contentID string
+
 	spec *Spec
 	link func(rel string) string
 }
 
+// specProvider is implemented by the resources created by a Spec.
+type specProvider interface {
+	resourceSpec() *Spec
+}
+
+func (l *genericResource) resourceSpec() *Spec {
+	return l.spec
+}
+
 func (l *genericResource) Permalink() string {
 	return l.spec.PermalinkForBaseURL(l.relPermalinkForRel(l.rel, false), l.spec.BaseURL.String())
 }
@@ -226,6 +297,38 @@ func (l *genericResource) RelPermalink() string {
 	return l.relPermalinkForRel(l.rel, true)
 }
 
+// Name returns the resource's name used in matching, by default its path
+// relative to the bundle, e.g. images/logo.png.
+func (l *genericResource) Name() string {
+	if l.name != "" {
+		return l.name
+	}
+	return l.rel
+}
+
+// MediaType returns the resource's full media type, e.g. image/svg+xml.
+func (l *genericResource) MediaType() string {
+	ext := strings.TrimPrefix(path.Ext(l.rel), ".")
+	if m, found := l.spec.mimeTypes.GetBySuffix(ext); found {
+		return m.Type()
+	}
+	if tp := mediaFileType(l.rel); tp != "" {
+		return tp
+	}
+	if tp := mime.TypeByExtension("." + ext); tp != "" {
+		if i := strings.Index(tp, ";"); i != -1 {
+			tp = tp[:i]
+		}
+		return strings.TrimSpace(tp)
+	}
+	return DefaultResourceType
+}
+
+func (l genericResource) withName(name string) *genericResource {
+	l.name = name
+	return &l
+}
+
 // Implement the Cloner interface.
 func (l genericResource) WithNewBase(base string) Resource {
 	l.base = base
@@ -233,7 +336,9 @@ func (l genericResource) WithNewBase(base string) Resource {
 }
 
 func (l *genericResource) relPermalinkForRel(rel string, addBasePath bool) string {
-	if l.link != nil {
+	if dir, ok := l.spec.dedup.sharedDir(l.contentID); ok {
+		rel = path.Join(dir, rel)
+	} else if l.link != nil {
 		rel = l.link(rel)
 	}
 
@@ -241,6 +346,8 @@ func (l *genericResource) relPermalinkForRel(rel string, addBasePath bool) strin
 		rel = path.Join(l.base, rel)
 	}
 
+	rel = filepath.ToSlash(l.spec.PathSpec.MakeWindowsSafePublishPath(filepath.FromSlash(rel)))
+
 	if addBasePath && l.spec.PathSpec.BasePath != "" {
 		rel = path.Join(l.spec.PathSpec.BasePath, rel)
 	}
@@ -261,14 +368,18 @@ func (l *genericResource) AbsSourceFilename() string {
 }
 
 func (l *genericResource) Publish() error {
+	target := filepath.Join(l.absPublishDir, l.target())
+
+	if _, ok := l.spec.dedup.sharedDir(l.contentID); ok && !l.spec.dedup.publishOnce(target) {
+		return nil
+	}
+
 	f, err := l.spec.Fs.Source.Open(l.AbsSourceFilename())
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	target := filepath.Join(l.absPublishDir, l.target())
-
 	return helpers.WriteToDisk(target, f, l.spec.Fs.Destination)
 }
 
@@ -0,0 +1,147 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+// sharedResourcesDir is where resources with identical content in several
+// bundles are published when deduplicateResources is set.
+const sharedResourcesDir = "_shared"
+
+// resourceDedup keeps track of the bundle resources with identical content,
+// so these, and the images processed from them, can be published once to a
+// shared location instead of once per bundle.
+type resourceDedup struct {
+	mu sync.RWMutex
+
+	// Content ID, see contentID => the source filenames with that content.
+	sources map[string]map[string]bool
+
+	// Source filename => its content ID.
+	ids map[string]string
+
+	// The shared targets published in the current build.
+	published map[string]bool
+}
+
+func newResourceDedup() *resourceDedup {
+	return &resourceDedup{sources: make(map[string]map[string]bool), ids: make(map[string]string), published: make(map[string]bool)}
+}
+
+// reset prepares for a new build. The sources removed since the last build
+// are forgotten and the shared targets are published again. The sources are
+// not cleared, as the resources of the bundles not changed are kept in
+// partial rebuilds.
+func (d *resourceDedup) reset(fs afero.Fs) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for filename, id := range d.ids {
+		if _, err := fs.Stat(filename); err != nil {
+			d.remove(id, filename)
+		}
+	}
+
+	d.published = make(map[string]bool)
+}
+
+func (d *resourceDedup) remove(id, filename string) {
+	delete(d.ids, filename)
+	if m, found := d.sources[id]; found {
+		delete(m, filename)
+		if len(m) == 0 {
+			delete(d.sources, id)
+		}
+	}
+}
+
+// contentID identifies resources with the same content and the same path in
+// the bundle, e.g. images/logo.png.
+func contentID(hash, rel string) string {
+	return hash + "/" + strings.TrimPrefix(rel, "/")
+}
+
+func (d *resourceDedup) add(id, filename string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The content may have changed.
+	if old, found := d.ids[filename]; found && old != id {
+		d.remove(old, filename)
+	}
+	d.ids[filename] = id
+
+	m, found := d.sources[id]
+	if !found {
+		m = make(map[string]bool)
+		d.sources[id] = m
+	}
+	m[filename] = true
+}
+
+// sharedDir returns the directory the resource with the given content ID is
+// published to, if it is found in more than one bundle.
+func (d *resourceDedup) sharedDir(id string) (string, bool) {
+	if d == nil || id == "" {
+		return "", false
+	}
+
+	d.mu.RLock()
+	n := len(d.sources[id])
+	d.mu.RUnlock()
+
+	if n < 2 {
+		return "", false
+	}
+
+	return path.Join(sharedResourcesDir, id[:strings.Index(id, "/")]), true
+}
+
+// publishOnce reports whether the shared target needs to be published, i.e.
+// it is the first time it is asked for.
+func (d *resourceDedup) publishOnce(target string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.published[target] {
+		return false
+	}
+	d.published[target] = true
+	return true
+}
+
+func (r *Spec) addForDedup(gr *genericResource) error {
+	f, err := r.Fs.Source.Open(gr.absSourceFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash, err := helpers.MD5FromFile(f)
+	if err != nil || hash == "" {
+		return err
+	}
+
+	gr.contentID = contentID(hash, gr.rel)
+	r.dedup.add(gr.contentID, gr.absSourceFilename)
+
+	return nil
+}
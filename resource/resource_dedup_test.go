@@ -0,0 +1,99 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicateResources(t *testing.T) {
+	assert := require.New(t)
+
+	spec := newTestResourceSpec(assert)
+	spec.dedup = newResourceDedup()
+
+	sunset, err := ioutil.ReadFile("testdata/sunset.jpg")
+	assert.NoError(err)
+
+	for _, filename := range []string{"/b1/sunset.jpg", "/b2/sunset.jpg", "/b3/other.jpg"} {
+		assert.NoError(afero.WriteFile(spec.Fs.Source, filename, sunset, 0755))
+	}
+	writeSource(t, spec.Fs, "/b1/data.json", "{}")
+
+	newResource := func(bundle, filename string) Resource {
+		linker := func(s string) string {
+			return path.Join("/", bundle, s)
+		}
+		r, err := spec.NewResourceFromFilename(linker, "/public", filepath.Join("/", bundle, filename), filename)
+		assert.NoError(err)
+		return r
+	}
+
+	r1, r2 := newResource("b1", "sunset.jpg"), newResource("b2", "sunset.jpg")
+	other, data := newResource("b3", "other.jpg"), newResource("b1", "data.json")
+
+	hash := r1.(*Image).contentID[:32]
+
+	assert.Equal("/_shared/"+hash+"/sunset.jpg", r1.RelPermalink())
+	assert.Equal(r1.RelPermalink(), r2.RelPermalink())
+	assert.Equal("/b3/other.jpg", other.RelPermalink())
+	assert.Equal("/b1/data.json", data.RelPermalink())
+
+	assert.NoError(r1.(Source).Publish())
+	assert.NoError(spec.Fs.Source.Remove("/b2/sunset.jpg"))
+	// Already published.
+	assert.NoError(r2.(Source).Publish())
+
+	resized1, err := r1.(*Image).Resize("100x")
+	assert.NoError(err)
+	resized2, err := r2.(*Image).Resize("100x")
+	assert.NoError(err)
+
+	assert.Equal(resized1, resized2)
+	assert.Contains(resized1.RelPermalink(), "/_shared/"+hash+"/sunset_hu")
+
+	assertFileExists := func(filename string) {
+		_, err := spec.Fs.Destination.Stat(filepath.FromSlash(path.Join("/public", filename)))
+		assert.NoError(err, filename)
+	}
+
+	assertFileExists(r1.RelPermalink())
+	assertFileExists(resized1.RelPermalink())
+
+	// The next build forgets the removed source, and changed ones.
+	spec.ResetBuildState()
+	assert.Equal("/b1/sunset.jpg", r1.RelPermalink())
+
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b3/sunset.jpg", sunset, 0755))
+	r3 := newResource("b3", "sunset.jpg")
+	assert.Equal("/_shared/"+hash+"/sunset.jpg", r3.RelPermalink())
+	writeSource(t, spec.Fs, "/b3/sunset.jpg", "changed")
+	newResource("b3", "sunset.jpg")
+	assert.Equal("/b1/sunset.jpg", r1.RelPermalink())
+
+	// The shared targets are published again.
+	assert.NoError(spec.Fs.Source.Remove("/b3/sunset.jpg"))
+	spec.ResetBuildState()
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b2/sunset.jpg", sunset, 0755))
+	r2 = newResource("b2", "sunset.jpg")
+	assert.NoError(spec.Fs.Destination.Remove(filepath.FromSlash(path.Join("/public", r1.RelPermalink()))))
+	assert.NoError(r2.(Source).Publish())
+	assertFileExists(r1.RelPermalink())
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/helpers"
+)
+
+// ByType returns the resources of the given types. A type is either a
+// resource type, e.g. "image", or a media type, e.g. "image/png" or
+// "image/*". Types prefixed with "!" are excluded, so
+//
+//   .Resources.ByType "image" "!image/svg+xml"
+//
+// returns all images but SVGs.
+func (r Resources) ByType(types ...string) Resources {
+	var include, exclude []string
+	for _, tp := range types {
+		if strings.HasPrefix(tp, "!") {
+			exclude = append(exclude, strings.TrimPrefix(tp, "!"))
+		} else {
+			include = append(include, tp)
+		}
+	}
+
+	var filtered Resources
+	for _, resource := range r {
+		if (len(include) == 0 || resourceIsOfType(resource, include...)) && !resourceIsOfType(resource, exclude...) {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+func resourceIsOfType(r Resource, types ...string) bool {
+	var mediaType string
+	if m, ok := r.(interface {
+		MediaType() string
+	}); ok {
+		mediaType = m.MediaType()
+	}
+
+	for _, tp := range types {
+		if !strings.Contains(tp, "/") {
+			if r.ResourceType() == tp {
+				return true
+			}
+			if mediaType != "" && strings.HasPrefix(mediaType, tp+"/") {
+				return true
+			}
+			continue
+		}
+
+		if match, _ := path.Match(strings.ToLower(tp), mediaType); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match gets all resources matching the given glob pattern, e.g. "*.jpg",
+// "images/*.png" or "gallery/**.jpg". The pattern is matched against the
+// last path elements of the resource's name, case insensitive. In addition
+// to the path.Match syntax, "**" matches across path separators and
+// "{a,b}" matches any of the comma separated alternatives.
+func (r Resources) Match(pattern string) Resources {
+	var cache *resourceMatchCache
+	key := matchCacheKey{pattern: pattern, n: len(r)}
+	if len(r) > 0 {
+		if sp, ok := r[0].(specProvider); ok && sp.resourceSpec() != nil {
+			cache = sp.resourceSpec().matchCache
+		}
+	}
+
+	if cache != nil {
+		key.first = &r[0]
+		if matches, found := cache.get(key); found {
+			return matches
+		}
+	}
+
+	var matches Resources
+	for _, resource := range r {
+		if resourceMatches(resource, pattern) {
+			matches = append(matches, resource)
+		}
+	}
+
+	if cache != nil {
+		cache.set(key, matches)
+	}
+
+	return matches
+}
+
+// GetMatch gets the first resource matching the given glob pattern, see Match.
+// It returns nil if none found.
+func (r Resources) GetMatch(pattern string) Resource {
+	matches := r.Match(pattern)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// Mount returns the resources with the names in the from directory moved to
+// the to directory, e.g. to match the resources in images/gallery with
+//
+//   (.Resources.Mount "images/gallery" "gallery").Match "gallery/*.jpg"
+//
+// An empty to moves the resources to the top level.
+func (r Resources) Mount(from, to string) Resources {
+	from = strings.Trim(from, "/")
+	to = strings.Trim(to, "/")
+
+	mounted := make(Resources, len(r))
+	for i, resource := range r {
+		mounted[i] = resource
+		name := resourceName(resource)
+		if !strings.HasPrefix(name, from+"/") {
+			continue
+		}
+		name = strings.TrimPrefix(path.Join(to, strings.TrimPrefix(name, from+"/")), "/")
+
+		switch v := resource.(type) {
+		case *Image:
+			mounted[i] = &Image{
+				imaging:         v.imaging,
+				hash:            v.hash,
+				genericResource: v.genericResource.withName(name)}
+		case *genericResource:
+			mounted[i] = v.withName(name)
+		}
+	}
+
+	return mounted
+}
+
+// resourceName returns the name to match the resource by, see Match.
+func resourceName(r Resource) string {
+	if n, ok := r.(interface {
+		Name() string
+	}); ok {
+		return strings.TrimPrefix(n.Name(), "/")
+	}
+	return strings.TrimPrefix(r.RelPermalink(), "/")
+}
+
+func resourceMatches(r Resource, pattern string) bool {
+	pattern = strings.ToLower(strings.TrimPrefix(pattern, "/"))
+	re := helpers.GlobRegexp(pattern)
+	if re == nil {
+		return false
+	}
+
+	parts := strings.Split(strings.ToLower(resourceName(r)), "/")
+
+	if !strings.Contains(pattern, "**") {
+		n := strings.Count(pattern, "/") + 1
+		if n > len(parts) {
+			return false
+		}
+		return re.MatchString(strings.Join(parts[len(parts)-n:], "/"))
+	}
+
+	for i := range parts {
+		if re.MatchString(strings.Join(parts[i:], "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchCacheKey identifies the result of a Match on a resource list, e.g. a
+// page's bundle resources. The pointer to the first element keeps the
+// list's backing array alive while cached, so it cannot be reused.
+type matchCacheKey struct {
+	first   *Resource
+	n       int
+	pattern string
+}
+
+type resourceMatchCache struct {
+	mu      sync.RWMutex
+	matches map[matchCacheKey]Resources
+}
+
+func newResourceMatchCache() *resourceMatchCache {
+	return &resourceMatchCache{matches: make(map[matchCacheKey]Resources)}
+}
+
+func (c *resourceMatchCache) get(key matchCacheKey) (Resources, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	matches, found := c.matches[key]
+	return matches, found
+}
+
+func (c *resourceMatchCache) set(key matchCacheKey, matches Resources) {
+	c.mu.Lock()
+	c.matches[key] = matches
+	c.mu.Unlock()
+}
+
+func (c *resourceMatchCache) reset() {
+	c.mu.Lock()
+	c.matches = make(map[matchCacheKey]Resources)
+	c.mu.Unlock()
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesMatchGlob(t *testing.T) {
+	assert := require.New(t)
+	spec := newTestResourceSpec(assert)
+	resources := Resources{
+		spec.newGenericResource(nil, nil, "/public", "/a/logo.png", "logo.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/g/1.jpg", "gallery/1.jpg", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/g/s/2.JPG", "gallery/summer/2.JPG", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/g/s/3.png", "gallery/summer/3.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/data.json", "data.json", "json")}
+
+	assert.Len(resources.Match("gallery/*.jpg"), 1)
+	assert.Len(resources.Match("gallery/**.jpg"), 2)
+	assert.Len(resources.Match("gallery/**/*.{jpg,png}"), 3)
+	assert.Len(resources.Match("**.png"), 2)
+	assert.Len(resources.Match("*/[0-9].*"), 3)
+	assert.Len(resources.Match("[!l]*.*"), 4)
+	assert.Len(resources.Match("gallery/summer/?.jpg"), 1)
+	assert.Equal("/gallery/summer/2.JPG", resources.GetMatch("summer/*.jpg").RelPermalink())
+	assert.Nil(resources.GetMatch("gallery/**.gif"))
+
+	// Cached.
+	assert.Equal(resources.Match("gallery/**.jpg"), resources.Match("gallery/**.jpg"))
+	assert.Len(resources[:1].Match("gallery/**.jpg"), 0)
+	assert.Len(spec.matchCache.matches, 10)
+
+	spec.ResetBuildState()
+	assert.Len(spec.matchCache.matches, 0)
+	assert.Len(resources.Match("gallery/**.jpg"), 2)
+}
+
+func TestResourcesByTypeFilters(t *testing.T) {
+	assert := require.New(t)
+	spec := newTestResourceSpec(assert)
+	resources := Resources{
+		spec.newGenericResource(nil, nil, "/public", "/a/logo.png", "logo.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/icon.svg", "icon.svg", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/photo.jpg", "photo.jpg", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/main.css", "main.css", "css")}
+
+	assert.Len(resources.ByType("image"), 3)
+	assert.Len(resources.ByType("image", "!image/svg+xml"), 2)
+	assert.Len(resources.ByType("image/png", "image/jpeg"), 2)
+	assert.Len(resources.ByType("image/*"), 3)
+	assert.Len(resources.ByType("text"), 1)
+	assert.Len(resources.ByType("!image"), 1)
+	assert.Equal("image/svg+xml", resources.ByType("image/svg+xml")[0].(*genericResource).MediaType())
+}
+
+func TestResourcesMount(t *testing.T) {
+	assert := require.New(t)
+	spec := newTestResourceSpec(assert)
+	resources := Resources{
+		spec.newGenericResource(nil, nil, "/public", "/a/logo.png", "logo.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/1.jpg", "images/gallery/1.jpg", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/a/2.jpg", "images/gallery/2.jpg", "image")}
+
+	mounted := resources.Mount("images/gallery", "gallery")
+	assert.Len(mounted, 3)
+	assert.Len(mounted.Match("gallery/*.jpg"), 2)
+	assert.Len(resources.Match("gallery/*.jpg"), 2)
+	assert.Len(mounted.Match("images/**"), 0)
+	assert.Equal("/images/gallery/1.jpg", mounted.GetMatch("gallery/1.jpg").RelPermalink())
+
+	top := resources.Mount("/images/gallery/", "")
+	assert.NotNil(top.GetMatch("2.jpg"))
+	assert.Equal("images/gallery/2.jpg", resources[2].(*genericResource).Name())
+}
@@ -125,3 +125,21 @@ func TestResourcesGetByPrefix(t *testing.T) {
 	assert.Nil(resources.GetByPrefix("asdfasdf"))
 
 }
+
+func TestResourcesMatch(t *testing.T) {
+	assert := require.New(t)
+	spec := newTestResourceSpec(assert)
+	resources := Resources{
+		spec.newGenericResource(nil, nil, "/public", "/a/foo1.css", "foo1.css", "css"),
+		spec.newGenericResource(nil, nil, "/public", "/a/logo1.png", "logo1.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/b/Logo2.png", "images/Logo2.png", "image"),
+		spec.newGenericResource(nil, nil, "/public", "/b/foo2.css", "foo2.css", "css")}
+
+	assert.Len(resources.Match("*.png"), 2)
+	assert.Len(resources.Match("foo*"), 2)
+	assert.Len(resources.Match("images/*"), 1)
+	assert.Len(resources.Match("*.jpg"), 0)
+	assert.Equal("/images/Logo2.png", resources.GetMatch("logo2.png").RelPermalink())
+	assert.Equal("/foo1.css", resources.GetMatch("*.css").RelPermalink())
+	assert.Nil(resources.GetMatch("asdf"))
+}
@@ -0,0 +1,528 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+var (
+	_ Resource = (*SVG)(nil)
+	_ Source   = (*SVG)(nil)
+	_ Cloner   = (*SVG)(nil)
+)
+
+// The elements kept by Sanitize. All other elements, e.g. script and
+// foreignObject, are removed with their content.
+var svgSafeElements = map[string]bool{
+	"a": true, "animate": true, "animatemotion": true, "animatetransform": true,
+	"circle": true, "clippath": true, "defs": true, "desc": true, "ellipse": true,
+	"feblend": true, "fecolormatrix": true, "fecomponenttransfer": true,
+	"fecomposite": true, "feconvolvematrix": true, "fediffuselighting": true,
+	"fedisplacementmap": true, "fedistantlight": true, "fedropshadow": true,
+	"feflood": true, "fefunca": true, "fefuncb": true, "fefuncg": true,
+	"fefuncr": true, "fegaussianblur": true, "feimage": true, "femerge": true,
+	"femergenode": true, "femorphology": true, "feoffset": true,
+	"fepointlight": true, "fespecularlighting": true, "fespotlight": true,
+	"fetile": true, "feturbulence": true, "filter": true, "g": true,
+	"image": true, "line": true, "lineargradient": true, "marker": true,
+	"mask": true, "metadata": true, "mpath": true, "path": true,
+	"pattern": true, "polygon": true, "polyline": true, "radialgradient": true,
+	"rect": true, "set": true, "stop": true, "style": true, "svg": true,
+	"switch": true, "symbol": true, "text": true, "textpath": true,
+	"title": true, "tspan": true, "use": true, "view": true,
+}
+
+// The attributes kept by Sanitize, besides the aria-* and data-* attributes
+// and the namespace declarations. All other attributes, e.g. the event
+// handlers such as onload, are removed.
+var svgSafeAttrs = map[string]bool{
+	// Core and geometry.
+	"class": true, "cx": true, "cy": true, "d": true, "dx": true, "dy": true,
+	"focusable": true, "fr": true, "fx": true, "fy": true, "height": true,
+	"href": true, "id": true, "lang": true, "media": true, "pathlength": true,
+	"points": true, "preserveaspectratio": true, "r": true, "requiredextensions": true,
+	"requiredfeatures": true, "role": true, "rx": true, "ry": true, "style": true,
+	"systemlanguage": true, "tabindex": true, "target": true, "title": true,
+	"type": true, "version": true, "viewbox": true, "width": true, "x": true,
+	"x1": true, "x2": true, "y": true, "y1": true, "y2": true,
+
+	// Presentation.
+	"alignment-baseline": true, "baseline-shift": true, "clip": true,
+	"clip-path": true, "clip-rule": true, "color": true,
+	"color-interpolation": true, "color-interpolation-filters": true,
+	"color-rendering": true, "cursor": true, "direction": true, "display": true,
+	"dominant-baseline": true, "fill": true, "fill-opacity": true,
+	"fill-rule": true, "filter": true, "flood-color": true,
+	"flood-opacity": true, "font-family": true, "font-size": true,
+	"font-size-adjust": true, "font-stretch": true, "font-style": true,
+	"font-variant": true, "font-weight": true, "image-rendering": true,
+	"letter-spacing": true, "lighting-color": true, "marker-end": true,
+	"marker-mid": true, "marker-start": true, "mask": true, "opacity": true,
+	"overflow": true, "paint-order": true, "pointer-events": true,
+	"shape-rendering": true, "stop-color": true, "stop-opacity": true,
+	"stroke": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	"stroke-linecap": true, "stroke-linejoin": true, "stroke-miterlimit": true,
+	"stroke-opacity": true, "stroke-width": true, "text-anchor": true,
+	"text-decoration": true, "text-rendering": true, "transform": true,
+	"transform-origin": true, "unicode-bidi": true, "vector-effect": true,
+	"visibility": true, "word-spacing": true, "writing-mode": true,
+
+	// Gradients, patterns, clip paths, masks, markers and text.
+	"clippathunits": true, "gradienttransform": true, "gradientunits": true,
+	"lengthadjust": true, "markerheight": true, "markerunits": true,
+	"markerwidth": true, "maskcontentunits": true, "maskunits": true,
+	"method": true, "offset": true, "orient": true, "patterncontentunits": true,
+	"patterntransform": true, "patternunits": true, "refx": true, "refy": true,
+	"rotate": true, "spacing": true, "spreadmethod": true, "startoffset": true,
+	"textlength": true,
+
+	// Filters.
+	"amplitude": true, "azimuth": true, "basefrequency": true, "bias": true,
+	"diffuseconstant": true, "divisor": true, "edgemode": true, "elevation": true,
+	"exponent": true, "filterunits": true, "in": true, "in2": true,
+	"intercept": true, "k1": true, "k2": true, "k3": true, "k4": true,
+	"kernelmatrix": true, "kernelunitlength": true, "limitingconeangle": true,
+	"mode": true, "numoctaves": true, "operator": true, "order": true,
+	"pointsatx": true, "pointsaty": true, "pointsatz": true,
+	"preservealpha": true, "primitiveunits": true, "radius": true,
+	"result": true, "scale": true, "seed": true, "slope": true,
+	"specularconstant": true, "specularexponent": true, "stddeviation": true,
+	"stitchtiles": true, "surfacescale": true, "tablevalues": true,
+	"targetx": true, "targety": true, "values": true, "xchannelselector": true,
+	"ychannelselector": true,
+
+	// Animations.
+	"accumulate": true, "additive": true, "attributename": true,
+	"attributetype": true, "begin": true, "by": true, "calcmode": true,
+	"dur": true, "end": true, "from": true, "keypoints": true,
+	"keysplines": true, "keytimes": true, "max": true, "min": true,
+	"path": true, "repeatcount": true, "repeatdur": true, "restart": true,
+	"to": true,
+}
+
+// The URL schemes allowed in links by Sanitize. Relative URLs are always
+// allowed.
+var svgSafeURLSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var (
+	svgURLRefRe     = regexp.MustCompile(`url\(\s*#([^)\s]+)\s*\)`)
+	svgURLSchemeRe  = regexp.MustCompile(`^([a-z][a-z0-9+.-]*):`)
+	svgStyleColorRe = regexp.MustCompile(`(?i)\b(fill|stroke)\s*:\s*([^;]+)`)
+
+	svgTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	svgAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+)
+
+// SVG is an SVG image resource. The transformations, e.g. Sanitize, return
+// a new SVG published with the transformations applied.
+type SVG struct {
+	opts svgOptions
+
+	*genericResource
+}
+
+type svgOptions struct {
+	sanitize     bool
+	minify       bool
+	currentColor bool
+}
+
+func (o svgOptions) key() string {
+	var parts []string
+	if o.sanitize {
+		parts = append(parts, "sanitized")
+	}
+	if o.minify {
+		parts = append(parts, "min")
+	}
+	if o.currentColor {
+		parts = append(parts, "currentcolor")
+	}
+	return strings.Join(parts, "_")
+}
+
+// Implement the Cloner interface.
+func (s *SVG) WithNewBase(base string) Resource {
+	return &SVG{
+		opts:            s.opts,
+		genericResource: s.genericResource.WithNewBase(base).(*genericResource)}
+}
+
+// Sanitize removes everything but a known safe set of elements and
+// attributes from the SVG, e.g. scripts, event handler attributes such as
+// onload and animations of links, and the links with a scheme other than
+// http, https and mailto, e.g. javascript: and data: links.
+func (s *SVG) Sanitize() (*SVG, error) {
+	opts := s.opts
+	opts.sanitize = true
+	return s.transform(opts)
+}
+
+// Minify removes comments, metadata and whitespace between the elements of
+// the SVG.
+func (s *SVG) Minify() (*SVG, error) {
+	opts := s.opts
+	opts.minify = true
+	return s.transform(opts)
+}
+
+// CurrentColor sets the fill and stroke colors of the SVG to currentColor,
+// so icons take the color of the surrounding text.
+func (s *SVG) CurrentColor() (*SVG, error) {
+	opts := s.opts
+	opts.currentColor = true
+	return s.transform(opts)
+}
+
+// Inline returns the SVG markup to be inlined in HTML. The ids in the SVG
+// and the references to them are prefixed with the given prefix, by default
+// the name of the SVG, to avoid collisions with other inlined SVGs:
+//
+//   {{ (.Resources.GetByPrefix "logo").Inline "header-" }}
+func (s *SVG) Inline(prefix ...string) (template.HTML, error) {
+	idPrefix := ""
+	if len(prefix) > 0 {
+		idPrefix = prefix[0]
+	} else {
+		base, _ := helpers.FileAndExt(filepath.Base(s.Name()))
+		idPrefix = base + "-"
+	}
+
+	b, err := s.content(idPrefix, true)
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(b), nil
+}
+
+// transform returns a new SVG with the given transformations applied,
+// published with the transformations in the filename, e.g.
+// icon_sanitized_min.svg.
+func (s *SVG) transform(opts svgOptions) (*SVG, error) {
+	ns := &SVG{opts: opts, genericResource: s.genericResource.withName(s.Name())}
+
+	p1, p2 := helpers.FileAndExt(s.rel)
+	if orig := s.opts.key(); orig != "" {
+		p1 = strings.TrimSuffix(p1, "_"+orig)
+	}
+	ns.rel = p1 + "_" + opts.key() + p2
+
+	b, err := ns.content("", false)
+	if err != nil {
+		return nil, err
+	}
+
+	target := filepath.Join(ns.absPublishDir, ns.target())
+	fs := ns.spec.Fs.Destination
+	if err := fs.MkdirAll(filepath.Dir(target), os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(fs, target, b, os.FileMode(0644)); err != nil {
+		return nil, err
+	}
+
+	return ns, nil
+}
+
+func (s *SVG) content(idPrefix string, inline bool) ([]byte, error) {
+	f, err := s.spec.Fs.Source.Open(s.AbsSourceFilename())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := transformSVG(f, s.opts, idPrefix, inline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform SVG %q: %s", s.rel, err)
+	}
+
+	return b, nil
+}
+
+// Publish writes the SVG to the publish dir. The transformed SVGs are
+// written when created.
+func (s *SVG) Publish() error {
+	if s.opts.key() != "" {
+		return nil
+	}
+	return s.genericResource.Publish()
+}
+
+// transformSVG applies the transformations to the SVG in r. If inline is
+// set, the XML declaration and doctype are removed and the ids prefixed with
+// idPrefix.
+func transformSVG(r io.Reader, opts svgOptions, idPrefix string, inline bool) ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		dec     = xml.NewDecoder(r)
+		skip    int
+		pending bool
+		inStyle bool
+	)
+
+	dec.Strict = false
+
+	closePending := func() {
+		if pending {
+			buf.WriteString(">")
+			pending = false
+		}
+	}
+
+	for {
+		t, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skip > 0 || (opts.sanitize && !isSafeSVGElement(t)) || (opts.minify && name == "metadata") {
+				skip++
+				continue
+			}
+			closePending()
+			buf.WriteString("<" + svgQName(t.Name))
+			for _, attr := range t.Attr {
+				value, keep := transformSVGAttr(attr, opts, idPrefix)
+				if !keep {
+					continue
+				}
+				buf.WriteString(" " + svgQName(attr.Name) + `="` + svgAttrEscaper.Replace(value) + `"`)
+			}
+			pending = true
+			inStyle = name == "style"
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			inStyle = false
+			if pending {
+				buf.WriteString("/>")
+				pending = false
+				continue
+			}
+			buf.WriteString("</" + svgQName(t.Name) + ">")
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			text := []byte(t)
+			if opts.minify && len(bytes.TrimSpace(text)) == 0 {
+				continue
+			}
+			closePending()
+			if inStyle {
+				style := string(text)
+				if idPrefix != "" {
+					style = svgURLRefRe.ReplaceAllString(style, "url(#"+idPrefix+"$1)")
+				}
+				if opts.currentColor {
+					style = svgCurrentColorStyle(style)
+				}
+				text = []byte(style)
+			}
+			buf.WriteString(svgTextEscaper.Replace(string(text)))
+		case xml.Comment:
+			if skip > 0 || opts.minify {
+				continue
+			}
+			closePending()
+			buf.WriteString("<!--")
+			buf.Write(t)
+			buf.WriteString("-->")
+		case xml.ProcInst:
+			if skip > 0 || opts.minify || inline {
+				continue
+			}
+			closePending()
+			buf.WriteString("<?" + t.Target + " ")
+			buf.Write(t.Inst)
+			buf.WriteString("?>")
+		case xml.Directive:
+			if skip > 0 || opts.minify || inline {
+				continue
+			}
+			closePending()
+			buf.WriteString("<!")
+			buf.Write(t)
+			buf.WriteString(">")
+		}
+	}
+
+	closePending()
+
+	b := buf.Bytes()
+	if inline {
+		b = bytes.TrimSpace(b)
+	}
+
+	return b, nil
+}
+
+// transformSVGAttr returns the new value of the attribute and whether to
+// keep it.
+func transformSVGAttr(attr xml.Attr, opts svgOptions, idPrefix string) (string, bool) {
+	name := strings.ToLower(attr.Name.Local)
+	value := attr.Value
+
+	if opts.sanitize {
+		if !isSafeSVGAttr(attr) {
+			return "", false
+		}
+		if name == "href" && !isSafeSVGURL(value) {
+			return "", false
+		}
+	}
+
+	if opts.currentColor {
+		switch name {
+		case "fill", "stroke":
+			if isSVGPaint(value) {
+				value = "currentColor"
+			}
+		case "style":
+			value = svgCurrentColorStyle(value)
+		}
+	}
+
+	if idPrefix != "" {
+		switch {
+		case name == "id":
+			value = idPrefix + value
+		case name == "href" && strings.HasPrefix(value, "#"):
+			value = "#" + idPrefix + value[1:]
+		default:
+			value = svgURLRefRe.ReplaceAllString(value, "url(#"+idPrefix+"$1)")
+		}
+	}
+
+	return value, true
+}
+
+// isSafeSVGElement returns whether Sanitize keeps the element. Animations
+// of links are removed, as they could set them to e.g. a javascript: URL.
+func isSafeSVGElement(t xml.StartElement) bool {
+	if t.Name.Space != "" && t.Name.Space != "svg" {
+		return false
+	}
+
+	name := strings.ToLower(t.Name.Local)
+	if !svgSafeElements[name] {
+		return false
+	}
+
+	switch name {
+	case "animate", "animatetransform", "set":
+		for _, attr := range t.Attr {
+			if strings.ToLower(attr.Name.Local) != "attributename" {
+				continue
+			}
+			target := strings.ToLower(strings.TrimSpace(attr.Value))
+			if i := strings.Index(target, ":"); i != -1 {
+				target = target[i+1:]
+			}
+			if target == "href" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isSafeSVGAttr returns whether Sanitize keeps the attribute.
+func isSafeSVGAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+
+	switch attr.Name.Space {
+	case "":
+		return name == "xmlns" || svgSafeAttrs[name] ||
+			strings.HasPrefix(name, "aria-") || strings.HasPrefix(name, "data-")
+	case "xmlns":
+		return true
+	case "xml":
+		return name == "lang" || name == "space"
+	case "xlink":
+		return name == "href" || name == "title"
+	}
+
+	return false
+}
+
+// isSafeSVGURL returns whether the URL is relative or has one of the
+// svgSafeURLSchemes. The browsers ignore whitespace and control characters
+// in the scheme, e.g. "java\tscript:", so these are removed before the
+// check.
+func isSafeSVGURL(value string) bool {
+	u := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+
+	m := svgURLSchemeRe.FindStringSubmatch(strings.ToLower(u))
+	if m == nil {
+		return true
+	}
+
+	return svgSafeURLSchemes[m[1]]
+}
+
+func svgCurrentColorStyle(style string) string {
+	return svgStyleColorRe.ReplaceAllStringFunc(style, func(s string) string {
+		m := svgStyleColorRe.FindStringSubmatch(s)
+		if !isSVGPaint(m[2]) {
+			return s
+		}
+		return m[1] + ":currentColor"
+	})
+}
+
+// isSVGPaint returns whether the fill or stroke value is a color to replace
+// with currentColor, i.e. not none or a reference to a gradient or pattern.
+func isSVGPaint(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return value != "" && value != "none" && value != "transparent" && !strings.HasPrefix(value, "url(")
+}
+
+func svgQName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 24 24" onload="alert(1)">
+  <!-- An icon -->
+  <metadata>Some editor</metadata>
+  <defs>
+    <linearGradient id="grad"><stop offset="0" stop-color="#fff"/></linearGradient>
+    <path id="shape" d="M0 0h24v24H0z"/>
+  </defs>
+  <script>alert("hi")</script>
+  <a xlink:href="javascript:alert(1)"><rect width="10" height="10" fill="#ff0000"/></a>
+  <circle r="5" fill="url(#grad)" stroke="black" style="fill:#000; opacity: 0.5"/>
+  <use xlink:href="#shape" fill="none"/>
+  <foreignObject><div>HTML</div></foreignObject>
+</svg>
+`
+
+func fetchTestSVG(assert *require.Assertions) *SVG {
+	spec := newTestResourceSpec(assert)
+	assert.NoError(afero.WriteFile(spec.Fs.Source, "/b/icon.svg", []byte(testSVG), 0755))
+
+	factory := func(s string) string {
+		return path.Join("/a", s)
+	}
+
+	r, err := spec.NewResourceFromFilename(factory, "/public", "/b/icon.svg", "icon.svg")
+	assert.NoError(err)
+	assert.IsType(&SVG{}, r)
+	assert.Equal("image", r.ResourceType())
+	return r.(*SVG)
+}
+
+func TestSVGSanitize(t *testing.T) {
+	assert := require.New(t)
+
+	svg, err := fetchTestSVG(assert).Sanitize()
+	assert.NoError(err)
+	assert.Equal("/a/icon_sanitized.svg", svg.RelPermalink())
+
+	b, err := afero.ReadFile(svg.spec.Fs.Destination, filepath.Join(svg.absPublishDir, svg.target()))
+	assert.NoError(err)
+	content := string(b)
+
+	assert.NotContains(content, "script")
+	assert.NotContains(content, "alert")
+	assert.NotContains(content, "onload")
+	assert.NotContains(content, "foreignObject")
+	assert.Contains(content, `<rect width="10" height="10" fill="#ff0000"/>`)
+	assert.Contains(content, `<a>`)
+	assert.Contains(content, `<!-- An icon -->`)
+	assert.Contains(content, `xmlns:xlink="http://www.w3.org/1999/xlink"`)
+}
+
+func TestSVGSanitizeAllowlist(t *testing.T) {
+	assert := require.New(t)
+
+	in := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape">
+<a href="java&#9;script:alert(1)">tab</a>
+<a xlink:href=" &#x0A;JavaScript:alert(1)">newline</a>
+<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">data</a>
+<a href="https://gohugo.io/">https</a>
+<a href="/about/">relative</a>
+<a href="#top">fragment</a>
+<set attributeName="href" to="javascript:alert(1)"/>
+<animate attributeName="xlink:href" values="javascript:alert(1)"/>
+<animate attributeName="opacity" from="0" to="1" dur="1s"/>
+<iframe src="https://example.com/"></iframe>
+<inkscape:layer><rect width="1"/></inkscape:layer>
+<rect width="1" inkscape:label="Layer" formaction="x" data-name="r" aria-hidden="true"/>
+</svg>`
+
+	b, err := transformSVG(strings.NewReader(in), svgOptions{sanitize: true}, "", false)
+	assert.NoError(err)
+	content := string(b)
+
+	assert.NotContains(content, "javascript")
+	assert.NotContains(content, "data:")
+	assert.NotContains(content, "<set")
+	assert.NotContains(content, "iframe")
+	assert.NotContains(content, "inkscape:layer")
+	assert.NotContains(content, "inkscape:label")
+	assert.NotContains(content, "formaction")
+	assert.Contains(content, `<a>tab</a>`)
+	assert.Contains(content, `<a>newline</a>`)
+	assert.Contains(content, `<a>data</a>`)
+	assert.Contains(content, `<a href="https://gohugo.io/">https</a>`)
+	assert.Contains(content, `<a href="/about/">relative</a>`)
+	assert.Contains(content, `<a href="#top">fragment</a>`)
+	assert.Contains(content, `<animate attributeName="opacity" from="0" to="1" dur="1s"/>`)
+	assert.Contains(content, `<rect width="1" data-name="r" aria-hidden="true"/>`)
+	assert.Contains(content, `xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"`)
+}
+
+func TestSVGMinifyAndCurrentColor(t *testing.T) {
+	assert := require.New(t)
+
+	svg, err := fetchTestSVG(assert).Sanitize()
+	assert.NoError(err)
+	svg, err = svg.Minify()
+	assert.NoError(err)
+	svg, err = svg.CurrentColor()
+	assert.NoError(err)
+	assert.Equal("/a/icon_sanitized_min_currentcolor.svg", svg.RelPermalink())
+
+	b, err := afero.ReadFile(svg.spec.Fs.Destination, filepath.Join(svg.absPublishDir, svg.target()))
+	assert.NoError(err)
+	content := string(b)
+
+	assert.True(strings.HasPrefix(content, `<svg xmlns="http://www.w3.org/2000/svg"`), content)
+	assert.NotContains(content, "<!--")
+	assert.NotContains(content, "metadata")
+	assert.NotContains(content, "\n")
+	assert.Contains(content, `<rect width="10" height="10" fill="currentColor"/>`)
+	assert.Contains(content, `fill="url(#grad)" stroke="currentColor" style="fill:currentColor; opacity: 0.5"`)
+	assert.Contains(content, `<use xlink:href="#shape" fill="none"/>`)
+}
+
+func TestSVGInline(t *testing.T) {
+	assert := require.New(t)
+
+	svg := fetchTestSVG(assert)
+
+	inlined, err := svg.Inline("x-")
+	assert.NoError(err)
+	content := string(inlined)
+
+	assert.True(strings.HasPrefix(content, "<svg"), content)
+	assert.Contains(content, `<linearGradient id="x-grad">`)
+	assert.Contains(content, `fill="url(#x-grad)"`)
+	assert.Contains(content, `<use xlink:href="#x-shape" fill="none"/>`)
+
+	inlined, err = svg.Inline()
+	assert.NoError(err)
+	assert.Contains(string(inlined), `id="icon-grad"`)
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package security contains the security settings for the features fetching
+// or embedding content from other hosts and running plugins.
+package security
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Config is the security config, set in the security section of the site
+// config, e.g.:
+//
+//   [security.oembed]
+//   allow = ["youtube", "vimeo"]
+type Config struct {
+	OEmbed  OEmbed
+	Plugins Plugins
+}
+
+// OEmbed holds the settings for the oEmbed lookups done by the embed
+// shortcode and getOEmbed.
+type OEmbed struct {
+	// The names of the providers allowed to be looked up. If not set, all the
+	// built-in providers and the providers configured below are allowed.
+	Allow []string
+
+	// Additional providers, e.g.:
+	//
+	//   [[security.oembed.providers]]
+	//   name = "example"
+	//   endpoint = "https://example.com/oembed"
+	//   schemes = ["https://example.com/videos/*"]
+	Providers []OEmbedProvider
+}
+
+// OEmbedProvider is an oEmbed provider.
+type OEmbedProvider struct {
+	// The name of the provider, used in the allow list.
+	Name string
+
+	// The oEmbed API endpoint.
+	Endpoint string
+
+	// The URL patterns handled by the provider, where * matches any
+	// sequence of characters.
+	Schemes []string
+}
+
+// IsAllowed reports whether the provider with the given name may be looked
+// up.
+func (c OEmbed) IsAllowed(name string) bool {
+	if c.Allow == nil {
+		return true
+	}
+	for _, allowed := range c.Allow {
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Plugins holds the settings for the plugins declared in the plugins section
+// of the site config, e.g.:
+//
+//   [security.plugins]
+//   allow = ["textile"]
+//   env = ["TEXTILE_OPTIONS"]
+//   timeout = "10s"
+type Plugins struct {
+	// The names of the plugins allowed to be started. Plugins run programs
+	// from the site config, so only the plugins listed are allowed.
+	Allow []string
+
+	// Whether Go plugins may be loaded. They run inside the Hugo process
+	// with all its permissions, so they are not allowed by default.
+	AllowGoPlugins bool
+
+	// The environment variables passed on to the plugin processes. Only
+	// PATH, HOME and the system variables needed to start a process are
+	// passed on by default.
+	Env []string
+
+	// The maximum duration of a call to a plugin process, e.g. "10s", 30
+	// seconds by default. The process is stopped when a call times out.
+	Timeout string
+}
+
+// IsAllowed reports whether the plugin with the given name may be started.
+func (c Plugins) IsAllowed(name string) bool {
+	for _, allowed := range c.Allow {
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeConfig decodes the security section of the site config.
+func DecodeConfig(in interface{}) (Config, error) {
+	var c Config
+
+	if in == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(in, &c); err != nil {
+		return c, fmt.Errorf("failed to decode security config: %s", err)
+	}
+
+	if c.Plugins.Timeout != "" {
+		if _, err := time.ParseDuration(c.Plugins.Timeout); err != nil {
+			return c, fmt.Errorf("invalid plugins timeout %q: %s", c.Plugins.Timeout, err)
+		}
+	}
+
+	for _, p := range c.OEmbed.Providers {
+		if p.Name == "" || p.Endpoint == "" || len(p.Schemes) == 0 {
+			return c, fmt.Errorf("invalid oEmbed provider %q: name, endpoint and schemes must be set", p.Name)
+		}
+	}
+
+	return c, nil
+}
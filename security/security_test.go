@@ -0,0 +1,85 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := DecodeConfig(map[string]interface{}{
+		"oembed": map[string]interface{}{
+			"allow": []interface{}{"YouTube", "example"},
+			"providers": []map[string]interface{}{
+				{
+					"name":     "example",
+					"endpoint": "https://example.com/oembed",
+					"schemes":  []interface{}{"https://example.com/videos/*"},
+				},
+			},
+		},
+	})
+	assert.NoError(err)
+
+	assert.True(c.OEmbed.IsAllowed("youtube"))
+	assert.True(c.OEmbed.IsAllowed("example"))
+	assert.False(c.OEmbed.IsAllowed("vimeo"))
+	assert.Len(c.OEmbed.Providers, 1)
+	assert.Equal("https://example.com/oembed", c.OEmbed.Providers[0].Endpoint)
+
+	c, err = DecodeConfig(nil)
+	assert.NoError(err)
+	assert.True(c.OEmbed.IsAllowed("vimeo"))
+
+	_, err = DecodeConfig(map[string]interface{}{
+		"oembed": map[string]interface{}{
+			"providers": []map[string]interface{}{{"name": "example"}},
+		},
+	})
+	assert.Error(err)
+
+	_, err = DecodeConfig("invalid")
+	assert.Error(err)
+}
+
+func TestDecodePluginsConfig(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := DecodeConfig(map[string]interface{}{
+		"plugins": map[string]interface{}{
+			"allow":   []interface{}{"Textile"},
+			"env":     []interface{}{"TEXTILE_OPTIONS"},
+			"timeout": "10s",
+		},
+	})
+	assert.NoError(err)
+
+	assert.True(c.Plugins.IsAllowed("textile"))
+	assert.False(c.Plugins.IsAllowed("other"))
+	assert.False(c.Plugins.AllowGoPlugins)
+	assert.Equal([]string{"TEXTILE_OPTIONS"}, c.Plugins.Env)
+
+	c, err = DecodeConfig(nil)
+	assert.NoError(err)
+	assert.False(c.Plugins.IsAllowed("other"))
+
+	_, err = DecodeConfig(map[string]interface{}{
+		"plugins": map[string]interface{}{"timeout": "soon"},
+	})
+	assert.Error(err)
+}
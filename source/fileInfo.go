@@ -177,6 +177,10 @@ func (sp *SourceSpec) NewFileInfo(baseDir, filename string, fi os.FileInfo) *Fil
 
 	relDir = strings.TrimPrefix(relDir, helpers.FilePathSeparator)
 
+	// The filename is kept as is to read the file, the rest is normalized.
+	relDir = sp.NormalizeFilename(relDir)
+	name = sp.NormalizeFilename(name)
+
 	relPath := filepath.Join(relDir, name)
 
 	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
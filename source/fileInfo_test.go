@@ -17,6 +17,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,3 +44,32 @@ func TestFileInfo(t *testing.T) {
 	}
 
 }
+
+func TestFileInfoNormalization(t *testing.T) {
+	assert := require.New(t)
+
+	nfd := "\x65\xcc\x81t\x65\xcc\x81"
+	nfc := "été"
+
+	for _, this := range []struct {
+		normalization string
+		expect        string
+	}{
+		{"", nfd},
+		{"none", nfd},
+		{"nfc", nfc},
+		{"NFC", nfc},
+		{"nfd", nfd},
+	} {
+		v := viper.New()
+		v.Set("filenameNormalization", this.normalization)
+		s := NewSourceSpec(v, hugofs.NewMem(v))
+
+		f := s.NewFileInfo(filepath.FromSlash("/a/"), filepath.FromSlash("/a/"+nfd+"/"+nfd+".md"), nil)
+
+		assert.Equal(filepath.FromSlash("/a/"+nfd+"/"+nfd+".md"), f.Filename(), this.normalization)
+		assert.Equal(filepath.FromSlash(this.expect+"/"+this.expect+".md"), f.Path(), this.normalization)
+		assert.Equal(this.expect, f.BaseFileName(), this.normalization)
+		assert.Equal(this.expect, f.TranslationBaseName(), this.normalization)
+	}
+}
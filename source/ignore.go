@@ -0,0 +1,195 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// IgnoreFilename is the name of the file with the ignore patterns in the
+// content root.
+const IgnoreFilename = ".hugoignore"
+
+// IgnorePatterns is a list of patterns in gitignore syntax, see
+// https://git-scm.com/docs/gitignore. The paths matched are relative to the
+// directory of the ignore file.
+type IgnorePatterns struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// LoadIgnoreFile reads the ignore file in the given directory. It returns nil
+// if there is no such file.
+func LoadIgnoreFile(fs afero.Fs, dir string) (*IgnorePatterns, error) {
+	f, err := fs.Open(filepath.Join(dir, IgnoreFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns, err := ParseIgnorePatterns(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", filepath.Join(dir, IgnoreFilename), err)
+	}
+	return patterns, nil
+}
+
+// ParseIgnorePatterns parses the patterns, one per line.
+func ParseIgnorePatterns(r io.Reader) (*IgnorePatterns, error) {
+	var patterns IgnorePatterns
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := newIgnorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %s", lineNo, line, err)
+		}
+		patterns.patterns = append(patterns.patterns, p)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &patterns, nil
+}
+
+func newIgnorePattern(line string) (ignorePattern, error) {
+	var p ignorePattern
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// Escaped leading "#" or "!".
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	// A pattern with a slash at the beginning or in the middle is relative
+	// to the ignore file, else it matches at any level.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var re bytes.Buffer
+	if anchored || strings.HasPrefix(line, "**/") {
+		re.WriteString("^")
+	} else {
+		re.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**") && i+2 == len(line):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end == -1 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(line[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	re.WriteString("$")
+
+	var err error
+	p.re, err = regexp.Compile(re.String())
+	return p, err
+}
+
+// Match reports whether the given path, relative to the ignore file and with
+// forward slashes, is ignored. As in gitignore, the last matching pattern
+// decides, so a negated pattern re-includes a path excluded by a previous
+// pattern. A file in an ignored directory can not be re-included.
+func (i *IgnorePatterns) Match(path string, isDir bool) bool {
+	if i == nil {
+		return false
+	}
+
+	ignored := false
+	for _, p := range i.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(path) {
+			ignored = !p.negate
+		}
+	}
+
+	return ignored
+}
+
+// MatchWithParents is like Match, but also reports the path as ignored if
+// one of the directories above it is ignored.
+func (i *IgnorePatterns) MatchWithParents(p string, isDir bool) bool {
+	if i.Match(p, isDir) {
+		return true
+	}
+
+	for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if i.Match(dir, true) {
+			return true
+		}
+	}
+
+	return false
+}
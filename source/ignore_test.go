@@ -0,0 +1,101 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	assert := require.New(t)
+
+	patterns, err := ParseIgnorePatterns(strings.NewReader(`
+# Editor artifacts
+*.swp
+*.bak
+!keep.bak
+
+# Scratch folders at any level
+scratch/
+/drafts
+docs/**/old
+archive/**
+\#notes
+file?.txt
+[ab].md
+`))
+	assert.NoError(err)
+
+	for i, test := range []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"post.md", false, false},
+		{"post.md.swp", false, true},
+		{"a/b/post.md.swp", false, true},
+		{"a/post.bak", false, true},
+		{"a/keep.bak", false, false},
+		{"scratch", true, true},
+		{"a/scratch", true, true},
+		{"scratch", false, false},
+		{"drafts", true, true},
+		{"drafts", false, true},
+		{"a/drafts", true, false},
+		{"docs/old", true, true},
+		{"docs/a/b/old", false, true},
+		{"a/docs/old", true, false},
+		{"archive/a/b.md", false, true},
+		{"archive", true, false},
+		{"#notes", false, true},
+		{"file1.txt", false, true},
+		{"file10.txt", false, false},
+		{"a.md", false, true},
+		{"c.md", false, false},
+	} {
+		assert.Equal(test.ignored, patterns.Match(test.path, test.isDir), "[%d] %s", i, test.path)
+	}
+
+	assert.False(patterns.Match("scratch/c.md", false))
+	assert.True(patterns.MatchWithParents("scratch/c.md", false))
+	assert.True(patterns.MatchWithParents("a/b/scratch/c/d.md", false))
+	assert.False(patterns.MatchWithParents("a/b/c.md", false))
+
+	var nilPatterns *IgnorePatterns
+	assert.False(nilPatterns.Match("a.md", false))
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	assert := require.New(t)
+
+	fs := afero.NewMemMapFs()
+
+	patterns, err := LoadIgnoreFile(fs, "/content")
+	assert.NoError(err)
+	assert.Nil(patterns)
+
+	assert.NoError(afero.WriteFile(fs, "/content/.hugoignore", []byte("*.tmp\n"), 0755))
+	patterns, err = LoadIgnoreFile(fs, "/content")
+	assert.NoError(err)
+	assert.True(patterns.Match("a/b.tmp", false))
+
+	assert.NoError(afero.WriteFile(fs, "/content/.hugoignore", []byte("[z-a].md\n"), 0755))
+	_, err = LoadIgnoreFile(fs, "/content")
+	assert.Error(err)
+	assert.Contains(err.Error(), "line 1")
+}
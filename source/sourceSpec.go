@@ -17,11 +17,13 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugofs"
 	"github.com/spf13/cast"
+	"golang.org/x/text/unicode/norm"
 )
 
 // SourceSpec abstracts language-specific file creation.
@@ -35,6 +37,11 @@ type SourceSpec struct {
 
 	Languages              map[string]interface{}
 	DefaultContentLanguage string
+
+	// The Unicode normalization form of the file paths, nil if not normalized.
+	// This is set by the filenameNormalization config, NFC by default, so
+	// files created on macOS (NFD) and Linux (NFC) resolve to the same paths.
+	filenameNormalization *norm.Form
 }
 
 // NewSourceSpec initializes SourceSpec using languages from a given configuration.
@@ -62,7 +69,31 @@ func NewSourceSpec(cfg config.Provider, fs *hugofs.Fs) *SourceSpec {
 		}
 	}
 
-	return &SourceSpec{ignoreFilesRe: regexps, Cfg: cfg, Fs: fs, Languages: languages, DefaultContentLanguage: defaultLang}
+	var form *norm.Form
+	switch n := strings.ToLower(cfg.GetString("filenameNormalization")); n {
+	case "", "none":
+	case "nfc":
+		form = formPtr(norm.NFC)
+	case "nfd":
+		form = formPtr(norm.NFD)
+	default:
+		helpers.DistinctErrorLog.Printf("Invalid filenameNormalization %q, must be one of nfc, nfd or none", n)
+	}
+
+	return &SourceSpec{ignoreFilesRe: regexps, Cfg: cfg, Fs: fs, Languages: languages, DefaultContentLanguage: defaultLang, filenameNormalization: form}
+}
+
+func formPtr(f norm.Form) *norm.Form {
+	return &f
+}
+
+// NormalizeFilename returns the filename in the Unicode normalization form
+// set in the filenameNormalization config.
+func (s *SourceSpec) NormalizeFilename(filename string) string {
+	if s.filenameNormalization == nil {
+		return filename
+	}
+	return s.filenameNormalization.String(filename)
 }
 
 func (s *SourceSpec) IgnoreFile(filename string) bool {
@@ -0,0 +1,60 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"sync"
+)
+
+// Coverage records the names of the templates executed, see the
+// templateCoverage config. Only the templates executed on their own are
+// recorded, e.g. page layouts, partials and shortcodes; the templates
+// included with the template action are executed as part of these.
+type Coverage struct {
+	mu       sync.RWMutex
+	executed map[string]bool
+}
+
+// NewCoverage creates a new Coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{executed: make(map[string]bool)}
+}
+
+// Track records that the named template was executed.
+func (c *Coverage) Track(name string) {
+	c.mu.RLock()
+	found := c.executed[name]
+	c.mu.RUnlock()
+	if found {
+		return
+	}
+
+	c.mu.Lock()
+	c.executed[name] = true
+	c.mu.Unlock()
+}
+
+// Executed reports whether the named template was executed.
+func (c *Coverage) Executed(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.executed[name]
+}
+
+// Reset clears the recorded executions, e.g. before a new build.
+func (c *Coverage) Reset() {
+	c.mu.Lock()
+	c.executed = make(map[string]bool)
+	c.mu.Unlock()
+}
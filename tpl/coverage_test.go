@@ -0,0 +1,45 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage(t *testing.T) {
+	assert := require.New(t)
+
+	c := NewCoverage()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Track("_default/single.html")
+			c.Track("partials/header.html")
+		}()
+	}
+	wg.Wait()
+
+	assert.True(c.Executed("_default/single.html"))
+	assert.True(c.Executed("partials/header.html"))
+	assert.False(c.Executed("_default/list.html"))
+
+	c.Reset()
+	assert.False(c.Executed("_default/single.html"))
+}
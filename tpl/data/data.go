@@ -18,19 +18,25 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/helpers"
 	jww "github.com/spf13/jwalterweatherman"
 )
 
 // New returns a new instance of the data-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
 	return &Namespace{
-		deps:   deps,
-		client: http.DefaultClient,
+		deps:    deps,
+		client:  http.DefaultClient,
+		openAPI: newOpenAPICache(),
+		oembed:  &oembedProviders{},
 	}
 }
 
@@ -39,6 +45,10 @@ type Namespace struct {
 	deps *deps.Deps
 
 	client *http.Client
+
+	openAPI *openAPICache
+
+	oembed *oembedProviders
 }
 
 // GetCSV expects a data separator and one or n-parts of a URL to a resource which
@@ -124,6 +134,41 @@ func (ns *Namespace) GetJSON(urlParts ...string) (v interface{}, err error) {
 	return
 }
 
+// publishLock locks a target path in the publish dir while a remote copy is
+// written to it.
+var publishLock = &remoteLock{m: make(map[string]*sync.Mutex)}
+
+// GetRemoteCopy expects a target path and one or n-parts of a URL to a remote
+// file. The file is downloaded, using the same cache as getJSON, and
+// published at the target path in the publish dir, so it can be served from
+// the site instead of the remote server, e.g. a video thumbnail. It returns
+// the relative URL to the published copy.
+func (ns *Namespace) GetRemoteCopy(targetPath string, urlParts ...string) (string, error) {
+	url := strings.Join(urlParts, "")
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for getRemoteCopy: %s", err)
+	}
+
+	c, err := getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, ns.client, ns.deps.Log)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote copy of %s: %s", url, err)
+	}
+
+	targetPath = strings.TrimPrefix(filepath.ToSlash(targetPath), "/")
+	filename := filepath.Join(ns.deps.PathSpec.AbsPathify(ns.deps.PathSpec.PublishDir), filepath.FromSlash(targetPath))
+
+	publishLock.URLLock(filename)
+	defer publishLock.URLUnlock(filename)
+
+	if err := helpers.WriteToDisk(filename, bytes.NewReader(c), ns.deps.Fs.Destination); err != nil {
+		return "", fmt.Errorf("failed to publish remote copy of %s: %s", url, err)
+	}
+
+	return ns.deps.PathSpec.RelURL(targetPath, false), nil
+}
+
 // parseCSV parses bytes of CSV data into a slice slice string or an error
 func parseCSV(c []byte, sep string) ([][]string, error) {
 	if len(sep) != 1 {
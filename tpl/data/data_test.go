@@ -21,6 +21,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -200,6 +202,43 @@ func TestGetJSON(t *testing.T) {
 	}
 }
 
+func TestGetRemoteCopy(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	cfg := viper.New()
+	cfg.Set("baseURL", "http://example.com/blog/")
+	cfg.Set("publishDir", "public")
+
+	d := newDeps(cfg)
+	ps, err := helpers.NewPathSpec(d.Fs, cfg)
+	assert.NoError(err)
+	d.PathSpec = ps
+
+	ns := New(d)
+
+	var srv *httptest.Server
+	srv, ns.client = getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/404" {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("thumbnail"))
+	})
+	defer srv.Close()
+
+	relURL, err := ns.GetRemoteCopy("/images/thumb.jpg", "http://remote/vi/", "abc.jpg")
+	assert.NoError(err)
+	assert.Equal("/blog/images/thumb.jpg", relURL)
+
+	b, err := afero.ReadFile(d.Fs.Destination, filepath.Join(ps.AbsPathify("public"), "images", "thumb.jpg"))
+	assert.NoError(err)
+	assert.Equal("thumbnail", string(b))
+
+	_, err = ns.GetRemoteCopy("images/missing.jpg", "http://remote/404")
+	assert.Error(err)
+}
+
 func TestParseCSV(t *testing.T) {
 	t.Parallel()
 
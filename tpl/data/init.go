@@ -38,6 +38,21 @@ func init() {
 			[]string{"getJSON"},
 			[][2]string{},
 		)
+
+		ns.AddMethodMapping(ctx.GetRemoteCopy,
+			[]string{"getRemoteCopy"},
+			[][2]string{},
+		)
+
+		ns.AddMethodMapping(ctx.GetOpenAPI,
+			[]string{"getOpenAPI"},
+			[][2]string{},
+		)
+
+		ns.AddMethodMapping(ctx.GetOEmbed,
+			[]string{"getOEmbed"},
+			[][2]string{},
+		)
 		return ns
 	}
 
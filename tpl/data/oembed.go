@@ -0,0 +1,162 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/security"
+)
+
+// The built-in oEmbed providers. More can be added in
+// security.oembed.providers.
+var defaultOEmbedProviders = []security.OEmbedProvider{
+	{
+		Name:     "youtube",
+		Endpoint: "https://www.youtube.com/oembed",
+		Schemes:  []string{"https://*.youtube.com/watch*", "https://youtube.com/watch*", "https://youtu.be/*"},
+	},
+	{
+		Name:     "vimeo",
+		Endpoint: "https://vimeo.com/api/oembed.json",
+		Schemes:  []string{"https://vimeo.com/*", "https://player.vimeo.com/video/*"},
+	},
+	{
+		Name:     "twitter",
+		Endpoint: "https://publish.twitter.com/oembed",
+		Schemes:  []string{"https://twitter.com/*/status/*"},
+	},
+	{
+		Name:     "flickr",
+		Endpoint: "https://www.flickr.com/services/oembed/",
+		Schemes:  []string{"https://*.flickr.com/photos/*", "https://flic.kr/p/*"},
+	},
+	{
+		Name:     "soundcloud",
+		Endpoint: "https://soundcloud.com/oembed",
+		Schemes:  []string{"https://soundcloud.com/*"},
+	},
+	{
+		Name:     "speakerdeck",
+		Endpoint: "https://speakerdeck.com/oembed.json",
+		Schemes:  []string{"https://speakerdeck.com/*"},
+	},
+}
+
+type oembedProvider struct {
+	security.OEmbedProvider
+	schemes []*regexp.Regexp
+}
+
+func (p oembedProvider) matches(u string) bool {
+	for _, re := range p.schemes {
+		if re.MatchString(u) {
+			return true
+		}
+	}
+	return false
+}
+
+type oembedProviders struct {
+	init sync.Once
+	err  error
+
+	allowed []oembedProvider
+}
+
+// oembedProviders returns the allowed providers, the configured ones first.
+func (ns *Namespace) oembedProviders() ([]oembedProvider, error) {
+	o := ns.oembed
+	o.init.Do(func() {
+		var cfg security.Config
+		cfg, o.err = security.DecodeConfig(ns.deps.Cfg.Get("security"))
+		if o.err != nil {
+			return
+		}
+
+		for _, p := range append(cfg.OEmbed.Providers, defaultOEmbedProviders...) {
+			if !cfg.OEmbed.IsAllowed(p.Name) {
+				continue
+			}
+			op := oembedProvider{OEmbedProvider: p}
+			for _, scheme := range p.Schemes {
+				pattern := strings.Replace(regexp.QuoteMeta(scheme), `\*`, ".*", -1)
+				op.schemes = append(op.schemes, regexp.MustCompile("^"+pattern+"$"))
+			}
+			o.allowed = append(o.allowed, op)
+		}
+	})
+	return o.allowed, o.err
+}
+
+// GetOEmbed looks up the oEmbed data for the given URL, e.g. a YouTube video,
+// from the first allowed provider handling it, see security.oembed. The
+// response is cached on disk as with getJSON.
+// If no allowed provider handles the URL, or the provider cannot be reached,
+// a warning is logged and nil is returned, so the caller can render some
+// fallback markup instead.
+func (ns *Namespace) GetOEmbed(u string) (map[string]interface{}, error) {
+	providers, err := ns.oembedProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	var provider *oembedProvider
+	for i, p := range providers {
+		if p.matches(u) {
+			provider = &providers[i]
+			break
+		}
+	}
+
+	if provider == nil {
+		helpers.Warnidf(ns.deps.Log, "warning-oembed-not-allowed", "No allowed oEmbed provider found for %q, see security.oembed.allow", u)
+		return nil, nil
+	}
+
+	endpoint := provider.Endpoint
+	if strings.Contains(endpoint, "?") {
+		endpoint += "&"
+	} else {
+		endpoint += "?"
+	}
+	endpoint += "format=json&url=" + url.QueryEscape(u)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	c, err := ns.getResource(req)
+	if err != nil {
+		helpers.Warnidf(ns.deps.Log, "warning-oembed-fetch", "Failed to look up oEmbed for %q from %s: %s", u, provider.Name, err)
+		return nil, nil
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(c, &v); err != nil {
+		helpers.Warnidf(ns.deps.Log, "warning-oembed-fetch", "Failed to decode oEmbed response for %q from %s: %s", u, provider.Name, err)
+		deleteCache(req.URL.String(), ns.deps.Fs.Source, ns.deps.Cfg)
+		return nil, nil
+	}
+
+	return v, nil
+}
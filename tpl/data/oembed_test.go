@@ -0,0 +1,103 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOEmbed(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	v := viper.New()
+	v.Set("security", map[string]interface{}{
+		"oembed": map[string]interface{}{
+			"allow": []string{"youtube", "example"},
+			"providers": []map[string]interface{}{
+				{
+					"name":     "example",
+					"endpoint": "https://example.com/oembed?maxwidth=600",
+					"schemes":  []string{"https://example.com/videos/*"},
+				},
+			},
+		},
+	})
+
+	ns := New(newDeps(v))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") == "https://example.com/videos/broken" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"type": "video", "title": "The Video", "html": "<iframe></iframe>"}`))
+	}))
+	defer srv.Close()
+
+	target, _ := url.Parse(srv.URL)
+	transport := &redirectTransport{target: target}
+	ns.client = &http.Client{Transport: transport}
+	requested := func() []string { return transport.requested }
+
+	data, err := ns.GetOEmbed("https://www.youtube.com/watch?v=w7Ft2ymGmfc")
+	assert.NoError(err)
+	assert.Equal("The Video", data["title"])
+	assert.Equal("<iframe></iframe>", data["html"])
+	assert.Equal("https://www.youtube.com/oembed?format=json&url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3Dw7Ft2ymGmfc", requested()[0])
+
+	data, err = ns.GetOEmbed("https://example.com/videos/42")
+	assert.NoError(err)
+	assert.Equal("video", data["type"])
+	assert.Equal("https://example.com/oembed?maxwidth=600&format=json&url=https%3A%2F%2Fexample.com%2Fvideos%2F42", requested()[1])
+
+	// Cached.
+	_, err = ns.GetOEmbed("https://example.com/videos/42")
+	assert.NoError(err)
+	assert.Len(requested(), 2)
+
+	// Not allowed.
+	data, err = ns.GetOEmbed("https://vimeo.com/146022717")
+	assert.NoError(err)
+	assert.Nil(data)
+	assert.Len(requested(), 2)
+
+	// Unreachable.
+	data, err = ns.GetOEmbed("https://example.com/videos/broken")
+	assert.NoError(err)
+	assert.Nil(data)
+}
+
+// redirectTransport sends all requests, including HTTPS requests, to the
+// target test server.
+type redirectTransport struct {
+	target    *url.URL
+	requested []string
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requested = append(t.requested, req.URL.String())
+	r := *req
+	u := *req.URL
+	u.Scheme, u.Host = t.target.Scheme, t.target.Host
+	r.URL = &u
+	return http.DefaultTransport.RoundTrip(&r)
+}
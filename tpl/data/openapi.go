@@ -0,0 +1,459 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cast"
+	jww "github.com/spf13/jwalterweatherman"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The HTTP methods of an OpenAPI path item, in the order they are listed.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPI is an OpenAPI 2 (Swagger) or 3 document with all the $ref
+// references resolved.
+type OpenAPI struct {
+	// The OpenAPI version, e.g. "3.0.1", or the Swagger version, i.e. "2.0".
+	Version string
+
+	// The info object, with title, description, version etc.
+	Info map[string]interface{}
+
+	// The operations sorted by path and method.
+	Operations []*OpenAPIOperation
+
+	// The named schemas, from "components/schemas" in OpenAPI 3 and
+	// "definitions" in OpenAPI 2.
+	Schemas map[string]interface{}
+
+	// The tags used, in the order they are declared, followed by any
+	// undeclared tags used by the operations.
+	Tags []string
+
+	// The full resolved document.
+	Doc map[string]interface{}
+}
+
+// OpenAPIOperation is an API operation, i.e. a HTTP method on a path.
+type OpenAPIOperation struct {
+	Path        string
+	Method      string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	// The path level and the operation level parameters.
+	Parameters []interface{}
+
+	// The request body (OpenAPI 3).
+	RequestBody map[string]interface{}
+
+	// The responses keyed by status code.
+	Responses map[string]interface{}
+
+	// The raw, resolved operation object.
+	Params map[string]interface{}
+}
+
+// ByTag returns the operations tagged with the given tag.
+func (o *OpenAPI) ByTag(tag string) []*OpenAPIOperation {
+	var ops []*OpenAPIOperation
+	for _, op := range o.Operations {
+		for _, t := range op.Tags {
+			if t == tag {
+				ops = append(ops, op)
+				break
+			}
+		}
+	}
+	return ops
+}
+
+type openAPICache struct {
+	sync.Mutex
+	docs map[string]*OpenAPI
+
+	// Locked while a document is resolved, so documents at different URLs
+	// are resolved in parallel, and every document only once.
+	urls *remoteLock
+}
+
+func newOpenAPICache() *openAPICache {
+	return &openAPICache{
+		docs: make(map[string]*OpenAPI),
+		urls: &remoteLock{m: make(map[string]*sync.Mutex)},
+	}
+}
+
+// GetOpenAPI expects one or n-parts of a URL to an OpenAPI 2 or 3 document in
+// JSON or YAML format, which can either be a local or a remote one.
+// All $ref references, including references to other documents relative
+// to this one, are resolved. Circular references are left as is, i.e. as a
+// map with only the "$ref" key.
+// Remote documents are cached on disk as with getJSON.
+func (ns *Namespace) GetOpenAPI(urlParts ...string) (*OpenAPI, error) {
+	url := strings.Join(urlParts, "")
+
+	ns.openAPI.urls.URLLock(url)
+	defer ns.openAPI.urls.URLUnlock(url)
+
+	ns.openAPI.Lock()
+	api, found := ns.openAPI.docs[url]
+	ns.openAPI.Unlock()
+	if found {
+		return api, nil
+	}
+
+	r := &openAPIResolver{load: ns.loadOpenAPIDocument, docs: make(map[string]map[string]interface{})}
+
+	doc, err := r.resolveDocument(url)
+	if err != nil {
+		jww.ERROR.Printf("Failed to read OpenAPI document %q: %s", url, err)
+		return nil, err
+	}
+
+	api = newOpenAPI(doc)
+
+	ns.openAPI.Lock()
+	ns.openAPI.docs[url] = api
+	ns.openAPI.Unlock()
+
+	return api, nil
+}
+
+func (ns *Namespace) loadOpenAPIDocument(url string) (map[string]interface{}, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Accept", "application/yaml")
+
+	c, err := ns.getResource(req)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%q not found", url)
+	}
+
+	return parseOpenAPIDocument(c)
+}
+
+// parseOpenAPIDocument parses a JSON or YAML document into maps with string keys.
+func parseOpenAPIDocument(c []byte) (map[string]interface{}, error) {
+	var v interface{}
+
+	// JSON is a subset of YAML, but the JSON parser is faster and stricter.
+	if err := json.Unmarshal(c, &v); err != nil {
+		if err := yaml.Unmarshal(c, &v); err != nil {
+			return nil, err
+		}
+	}
+
+	m, ok := toStringMaps(v).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %T", v)
+	}
+
+	return m, nil
+}
+
+// toStringMaps converts the map[interface{}]interface{} maps created by the
+// YAML parser to map[string]interface{}, which is easier to use in templates.
+func toStringMaps(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, v := range vv {
+			m[cast.ToString(k)] = toStringMaps(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range vv {
+			vv[k] = toStringMaps(v)
+		}
+		return vv
+	case []interface{}:
+		for i, v := range vv {
+			vv[i] = toStringMaps(v)
+		}
+		return vv
+	default:
+		return v
+	}
+}
+
+type openAPIResolver struct {
+	load func(url string) (map[string]interface{}, error)
+	docs map[string]map[string]interface{}
+}
+
+func (r *openAPIResolver) resolveDocument(url string) (map[string]interface{}, error) {
+	doc, err := r.document(url)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := r.resolve(url, doc, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.(map[string]interface{}), nil
+}
+
+func (r *openAPIResolver) document(url string) (map[string]interface{}, error) {
+	if doc, found := r.docs[url]; found {
+		return doc, nil
+	}
+	doc, err := r.load(url)
+	if err != nil {
+		return nil, err
+	}
+	r.docs[url] = doc
+	return doc, nil
+}
+
+// resolve returns a copy of v with all the references resolved. base is the
+// URL of the document v lives in and stack the references being resolved.
+func (r *openAPIResolver) resolve(base string, v interface{}, stack []string) (interface{}, error) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := vv["$ref"].(string); ok {
+			return r.resolveRef(base, ref, stack)
+		}
+		m := make(map[string]interface{}, len(vv))
+		for k, v := range vv {
+			resolved, err := r.resolve(base, v, stack)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = resolved
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(vv))
+		for i, v := range vv {
+			resolved, err := r.resolve(base, v, stack)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = resolved
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
+
+func (r *openAPIResolver) resolveRef(base, ref string, stack []string) (interface{}, error) {
+	docURL, pointer := ref, ""
+	if i := strings.Index(ref, "#"); i != -1 {
+		docURL, pointer = ref[:i], ref[i+1:]
+	}
+
+	if docURL == "" {
+		docURL = base
+	} else {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return nil, err
+		}
+		u, err := url.Parse(docURL)
+		if err != nil {
+			return nil, err
+		}
+		if baseURL.Scheme == "" && !u.IsAbs() && !strings.HasPrefix(u.Path, "/") {
+			// A local file, relative to the referencing document.
+			docURL = path.Join(path.Dir(baseURL.Path), u.Path)
+		} else {
+			docURL = baseURL.ResolveReference(u).String()
+		}
+	}
+
+	key := docURL + "#" + pointer
+	for _, s := range stack {
+		if s == key {
+			// Circular reference.
+			return map[string]interface{}{"$ref": ref}, nil
+		}
+	}
+
+	doc, err := r.document(docURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %s", ref, err)
+	}
+
+	target, err := jsonPointer(doc, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %s", ref, err)
+	}
+
+	return r.resolve(docURL, target, append(stack, key))
+}
+
+// jsonPointer looks up the value in doc for the given JSON pointer,
+// e.g. "/components/schemas/Pet".
+func jsonPointer(doc map[string]interface{}, pointer string) (interface{}, error) {
+	var v interface{} = doc
+
+	if pointer == "" || pointer == "/" {
+		return v, nil
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		part = strings.Replace(part, "~1", "/", -1)
+		part = strings.Replace(part, "~0", "~", -1)
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			var found bool
+			if v, found = vv[part]; !found {
+				return nil, fmt.Errorf("%q not found", part)
+			}
+		case []interface{}:
+			i, err := cast.ToIntE(part)
+			if err != nil || i < 0 || i >= len(vv) {
+				return nil, fmt.Errorf("invalid index %q", part)
+			}
+			v = vv[i]
+		default:
+			return nil, fmt.Errorf("%q not found", part)
+		}
+	}
+
+	return v, nil
+}
+
+func newOpenAPI(doc map[string]interface{}) *OpenAPI {
+	api := &OpenAPI{Doc: doc}
+
+	if v, ok := doc["openapi"]; ok {
+		api.Version = cast.ToString(v)
+	} else {
+		api.Version = cast.ToString(doc["swagger"])
+	}
+
+	api.Info, _ = doc["info"].(map[string]interface{})
+
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		api.Schemas, _ = components["schemas"].(map[string]interface{})
+	} else {
+		api.Schemas, _ = doc["definitions"].(map[string]interface{})
+	}
+
+	tagsSeen := make(map[string]bool)
+	if tags, ok := doc["tags"].([]interface{}); ok {
+		for _, t := range tags {
+			if tm, ok := t.(map[string]interface{}); ok {
+				name := cast.ToString(tm["name"])
+				if !tagsSeen[name] {
+					tagsSeen[name] = true
+					api.Tags = append(api.Tags, name)
+				}
+			}
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for p := range paths {
+		pathNames = append(pathNames, p)
+	}
+	sort.Strings(pathNames)
+
+	for _, p := range pathNames {
+		item, ok := paths[p].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		pathParams, _ := item["parameters"].([]interface{})
+
+		for _, method := range openAPIMethods {
+			opm, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			op := &OpenAPIOperation{
+				Path:        p,
+				Method:      strings.ToUpper(method),
+				OperationID: cast.ToString(opm["operationId"]),
+				Summary:     cast.ToString(opm["summary"]),
+				Description: cast.ToString(opm["description"]),
+				Tags:        cast.ToStringSlice(opm["tags"]),
+				Deprecated:  cast.ToBool(opm["deprecated"]),
+				Params:      opm,
+			}
+
+			opParams, _ := opm["parameters"].([]interface{})
+			op.Parameters = mergeOpenAPIParameters(pathParams, opParams)
+			op.RequestBody, _ = opm["requestBody"].(map[string]interface{})
+			op.Responses, _ = opm["responses"].(map[string]interface{})
+
+			for _, t := range op.Tags {
+				if !tagsSeen[t] {
+					tagsSeen[t] = true
+					api.Tags = append(api.Tags, t)
+				}
+			}
+
+			api.Operations = append(api.Operations, op)
+		}
+	}
+
+	return api
+}
+
+// mergeOpenAPIParameters returns the path level parameters followed by the
+// operation level parameters, the latter overriding the former when they
+// share name and location.
+func mergeOpenAPIParameters(pathParams, opParams []interface{}) []interface{} {
+	key := func(p interface{}) string {
+		m, _ := p.(map[string]interface{})
+		return cast.ToString(m["in"]) + ":" + cast.ToString(m["name"])
+	}
+
+	overridden := make(map[string]bool)
+	for _, p := range opParams {
+		overridden[key(p)] = true
+	}
+
+	var params []interface{}
+	for _, p := range pathParams {
+		if !overridden[key(p)] {
+			params = append(params, p)
+		}
+	}
+
+	return append(params, opParams...)
+}
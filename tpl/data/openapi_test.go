@@ -0,0 +1,241 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPI3 = `
+openapi: 3.0.1
+info:
+  title: Pet Store
+  version: 1.0.0
+tags:
+  - name: pets
+paths:
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        description: path level
+      - name: verbose
+        in: query
+    get:
+      operationId: getPet
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: id
+          in: path
+          description: operation level
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+        default:
+          $ref: "common.json#/responses/Error"
+  /owners:
+    post:
+      operationId: createOwner
+      tags: [owners]
+      deprecated: true
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Owner"
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+        owner:
+          $ref: "#/components/schemas/Owner"
+    Owner:
+      type: object
+      properties:
+        pets:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+`
+
+const testOpenAPICommon = `{
+  "responses": {
+    "Error": {
+      "description": "An error",
+      "content": {"application/json": {"schema": {"$ref": "#/schemas/Error"}}}
+    }
+  },
+  "schemas": {
+    "Error": {"type": "object", "properties": {"code": {"type": "integer"}}}
+  }
+}`
+
+const testOpenAPI2 = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy"},
+  "paths": {"/things": {"get": {"responses": {"200": {"schema": {"$ref": "#/definitions/Thing"}}}}}},
+  "definitions": {"Thing": {"type": "string"}}
+}`
+
+func TestGetOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	ns := New(newDeps(viper.New()))
+
+	workingDir := ns.deps.Cfg.GetString("workingDir")
+	writeFile := func(name, content string) {
+		assert.NoError(afero.WriteFile(ns.deps.Fs.Source, filepath.Join(workingDir, name), []byte(content), 0755))
+	}
+
+	writeFile("specs/petstore.yaml", testOpenAPI3)
+	writeFile("specs/common.json", testOpenAPICommon)
+
+	api, err := ns.GetOpenAPI("specs/", "petstore.yaml")
+	assert.NoError(err)
+
+	assert.Equal("3.0.1", api.Version)
+	assert.Equal("Pet Store", api.Info["title"])
+	assert.Equal([]string{"pets", "owners"}, api.Tags)
+	assert.Len(api.Schemas, 2)
+
+	assert.Len(api.Operations, 2)
+	owners, pet := api.Operations[0], api.Operations[1]
+
+	assert.Equal("/owners", owners.Path)
+	assert.Equal("POST", owners.Method)
+	assert.True(owners.Deprecated)
+	assert.NotNil(owners.RequestBody)
+
+	assert.Equal("getPet", pet.OperationID)
+	assert.Equal("GET", pet.Method)
+	assert.Equal("Get a pet", pet.Summary)
+	assert.Equal([]*OpenAPIOperation{pet}, api.ByTag("pets"))
+
+	// The operation level parameter overrides the path level one.
+	assert.Len(pet.Parameters, 2)
+	assert.Equal("verbose", pet.Parameters[0].(map[string]interface{})["name"])
+	assert.Equal("operation level", pet.Parameters[1].(map[string]interface{})["description"])
+
+	// Local reference.
+	schema := pet.Responses["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	assert.Equal("object", schema["type"])
+
+	// Circular reference Pet -> Owner -> Pet.
+	owner := schema["properties"].(map[string]interface{})["owner"].(map[string]interface{})
+	items := owner["properties"].(map[string]interface{})["pets"].(map[string]interface{})["items"]
+	assert.Equal(map[string]interface{}{"$ref": "#/components/schemas/Pet"}, items)
+
+	// Reference to another document, with a reference relative to that document.
+	errResponse := pet.Responses["default"].(map[string]interface{})
+	assert.Equal("An error", errResponse["description"])
+	errSchema := errResponse["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	assert.Equal("object", errSchema["type"])
+
+	// Cached.
+	api2, err := ns.GetOpenAPI("specs/petstore.yaml")
+	assert.NoError(err)
+	assert.True(api == api2)
+
+	_, err = ns.GetOpenAPI("specs/doesnotexist.yaml")
+	assert.Error(err)
+
+	writeFile("specs/broken.yaml", `paths: {"/a": {"get": {"$ref": "#/nope"}}}`)
+	_, err = ns.GetOpenAPI("specs/broken.yaml")
+	assert.Error(err)
+}
+
+func TestGetOpenAPIRemoteSwagger(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	ns := New(newDeps(viper.New()))
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testOpenAPI2))
+	})
+	defer srv.Close()
+	ns.client = cl
+
+	api, err := ns.GetOpenAPI("http://example.org/swagger.json")
+	assert.NoError(err)
+
+	assert.Equal("2.0", api.Version)
+	assert.Equal(map[string]interface{}{"Thing": map[string]interface{}{"type": "string"}}, api.Schemas)
+	assert.Len(api.Operations, 1)
+	assert.Equal(map[string]interface{}{"type": "string"}, api.Operations[0].Responses["200"].(map[string]interface{})["schema"])
+}
+
+func TestGetOpenAPIConcurrent(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	ns := New(newDeps(viper.New()))
+
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]int)
+		release  = make(chan bool)
+	)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/slow.json" {
+			<-release
+		}
+		w.Write([]byte(testOpenAPI2))
+	})
+	defer srv.Close()
+	ns.client = cl
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ns.GetOpenAPI("http://example.org/slow.json")
+			assert.NoError(err)
+		}()
+	}
+
+	// Another document is not blocked by the one being loaded.
+	api, err := ns.GetOpenAPI("http://example.org/fast.json")
+	assert.NoError(err)
+	assert.Equal("2.0", api.Version)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(map[string]int{"/slow.json": 1, "/fast.json": 1}, requests)
+}
@@ -18,12 +18,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gohugoio/hugo/config"
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/spf13/afero"
+	"github.com/spf13/cast"
 	jww "github.com/spf13/jwalterweatherman"
 )
 
@@ -63,10 +66,11 @@ func (l *remoteLock) URLUnlock(url string) {
 }
 
 // getRemote loads the content of a remote file. This method is thread safe.
-func getRemote(req *http.Request, fs afero.Fs, cfg config.Provider, hc *http.Client) ([]byte, error) {
+func getRemote(req *http.Request, fs afero.Fs, cfg config.Provider, hc *http.Client, logger *jww.Notepad) ([]byte, error) {
 	url := req.URL.String()
+	ignoreCache := cfg.GetBool("ignoreCache")
 
-	c, err := getCache(url, fs, cfg, cfg.GetBool("ignoreCache"))
+	c, err := getCache(url, fs, cfg, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +83,7 @@ func getRemote(req *http.Request, fs afero.Fs, cfg config.Provider, hc *http.Cli
 	defer func() { remoteURLLock.URLUnlock(url) }()
 
 	// avoid multiple locks due to calling getCache twice
-	c, err = getCache(url, fs, cfg, cfg.GetBool("ignoreCache"))
+	c, err = getCache(url, fs, cfg, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
@@ -87,29 +91,90 @@ func getRemote(req *http.Request, fs afero.Fs, cfg config.Provider, hc *http.Cli
 		return c, nil
 	}
 
+	tolerant := ignoreRemoteError(cfg, url)
+
 	jww.INFO.Printf("Downloading: %s ...", url)
+	c, err = download(req, hc)
+	if err != nil {
+		if !tolerant {
+			return nil, err
+		}
+		// Fall back to the last downloaded version, even if the cache
+		// is ignored.
+		if cached, cerr := getCache(url, fs, cfg, false); cerr == nil && cached != nil {
+			helpers.Warnidf(logger, "warning-remote-fallback", "Failed to retrieve %s, using the cached version: %s", url, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	// Keep a copy for the fallback above, even if the cache is ignored.
+	err = writeCache(url, c, fs, cfg, ignoreCache && !tolerant)
+	if err != nil {
+		return nil, err
+	}
+
+	jww.INFO.Printf("... and cached to: %s", getCacheFileID(cfg, url))
+	return c, nil
+}
+
+func download(req *http.Request, hc *http.Client) ([]byte, error) {
 	res, err := hc.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
 	if res.StatusCode < 200 || res.StatusCode > 299 {
 		return nil, fmt.Errorf("Failed to retrieve remote file: %s", http.StatusText(res.StatusCode))
 	}
 
-	c, err = ioutil.ReadAll(res.Body)
-	res.Body.Close()
-	if err != nil {
-		return nil, err
+	return ioutil.ReadAll(res.Body)
+}
+
+// ignoreRemoteError reports whether a failed download of the given URL
+// should fall back to the cached version, see the ignoreRemoteErrors
+// config. The patterns may use * to match any sequence of characters,
+// e.g. https://api.example.com/*.
+func ignoreRemoteError(cfg config.Provider, url string) bool {
+	v := cfg.Get("ignoreRemoteErrors")
+	if b, ok := v.(bool); ok {
+		return b
 	}
 
-	err = writeCache(url, c, fs, cfg, cfg.GetBool("ignoreCache"))
-	if err != nil {
-		return nil, err
+	for _, pattern := range cast.ToStringSlice(v) {
+		if ignoreRemoteErrorsRe.get(pattern).MatchString(url) {
+			return true
+		}
 	}
 
-	jww.INFO.Printf("... and cached to: %s", getCacheFileID(cfg, url))
-	return c, nil
+	return false
+}
+
+// ignoreRemoteErrorsRe holds the regexps compiled from the
+// ignoreRemoteErrors patterns, so they are compiled once.
+var ignoreRemoteErrorsRe = &patternRegexps{m: make(map[string]*regexp.Regexp)}
+
+type patternRegexps struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}
+
+func (p *patternRegexps) get(pattern string) *regexp.Regexp {
+	p.RLock()
+	re, found := p.m[pattern]
+	p.RUnlock()
+	if found {
+		return re
+	}
+
+	re = regexp.MustCompile("^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$")
+
+	p.Lock()
+	p.m[pattern] = re
+	p.Unlock()
+
+	return re
 }
 
 // getLocal loads the content of a local file
@@ -129,6 +194,6 @@ func (ns *Namespace) getResource(req *http.Request) ([]byte, error) {
 	case "":
 		return getLocal(req.URL.String(), ns.deps.Fs.Source, ns.deps.Cfg)
 	default:
-		return getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, ns.client)
+		return getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, ns.client, ns.deps.Log)
 	}
 }
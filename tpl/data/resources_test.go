@@ -16,9 +16,12 @@ package data
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -28,6 +31,7 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/hugofs"
 	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,7 +112,7 @@ func TestScpGetRemote(t *testing.T) {
 
 		cfg := viper.New()
 
-		c, err := getRemote(req, fs, cfg, cl)
+		c, err := getRemote(req, fs, cfg, cl, newErrorLogger())
 		require.NoError(t, err, msg)
 		assert.Equal(t, string(test.content), string(c))
 
@@ -124,6 +128,58 @@ func TestScpGetRemote(t *testing.T) {
 	}
 }
 
+func TestScpGetRemoteIgnoreErrors(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+	fs := new(afero.MemMapFs)
+
+	fail := false
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"v": 1}`))
+	})
+	defer func() { srv.Close() }()
+
+	cfg := viper.New()
+	cfg.Set("ignoreCache", true)
+	cfg.Set("ignoreRemoteErrors", []string{"http://api.example.com/*"})
+
+	get := func(u string) ([]byte, error) {
+		req, err := http.NewRequest("GET", u, nil)
+		assert.NoError(err)
+		return getRemote(req, fs, cfg, cl, newErrorLogger())
+	}
+
+	for _, u := range []string{"http://api.example.com/v1/data.json", "http://other.example.com/data.json"} {
+		c, err := get(u)
+		assert.NoError(err)
+		assert.Equal(`{"v": 1}`, string(c))
+	}
+
+	fail = true
+
+	c, err := get("http://api.example.com/v1/data.json")
+	assert.NoError(err)
+	assert.Equal(`{"v": 1}`, string(c))
+
+	_, err = get("http://other.example.com/data.json")
+	assert.Error(err)
+
+	// Nothing cached to fall back to.
+	_, err = get("http://api.example.com/v1/other.json")
+	assert.Error(err)
+
+	assert.True(ignoreRemoteError(cfg, "http://api.example.com/"))
+	assert.False(ignoreRemoteError(cfg, "http://api.example.com"))
+	cfg.Set("ignoreRemoteErrors", true)
+	assert.True(ignoreRemoteError(cfg, "http://api.example.com"))
+	cfg.Set("ignoreRemoteErrors", false)
+	assert.False(ignoreRemoteError(cfg, "http://api.example.com/"))
+}
+
 func TestScpGetRemoteParallel(t *testing.T) {
 	t.Parallel()
 
@@ -150,7 +206,7 @@ func TestScpGetRemoteParallel(t *testing.T) {
 			go func(gor int) {
 				defer wg.Done()
 				for j := 0; j < 10; j++ {
-					c, err := getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, cl)
+					c, err := getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, cl, newErrorLogger())
 					assert.NoError(t, err)
 					assert.Equal(t, string(content), string(c))
 
@@ -174,5 +230,10 @@ func newDeps(cfg config.Provider) *deps.Deps {
 		Cfg:         cfg,
 		Fs:          hugofs.NewMem(l),
 		ContentSpec: cs,
+		Log:         newErrorLogger(),
 	}
 }
+
+func newErrorLogger() *jww.Notepad {
+	return jww.NewNotepad(jww.LevelError, jww.LevelError, os.Stdout, ioutil.Discard, "", log.Ldate|log.Ltime)
+}
@@ -14,6 +14,7 @@
 package internal
 
 import (
+	"reflect"
 	"runtime"
 	"testing"
 
@@ -36,3 +37,16 @@ func TestMethodToName(t *testing.T) {
 		require.Equal(t, "MyTestMethod", methodToName(test.MyTestMethod))
 	}
 }
+
+func (t *Test) MyVariadicMethod(s string, v ...interface{}) (string, error) {
+	return s, nil
+}
+
+func TestFuncSignature(t *testing.T) {
+	test := &Test{}
+	ft := reflect.TypeOf(test.MyVariadicMethod)
+
+	require.Equal(t, "(string, ...interface {}) (string, error)", funcSignature(ft, nil))
+	require.Equal(t, "(s string, v ...interface {}) (string, error)", funcSignature(ft, []string{"s", "v"}))
+	require.Equal(t, "() string", funcSignature(reflect.TypeOf(test.MyTestMethod), nil))
+}
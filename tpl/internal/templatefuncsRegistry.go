@@ -105,6 +105,86 @@ func methodToName(m interface{}) string {
 	return name
 }
 
+// TemplateFuncInfo describes a template func in a namespace, e.g. strings.Trim.
+type TemplateFuncInfo struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases,omitempty"`
+
+	// The argument names and the signature, e.g.
+	// "(s interface {}, cutset interface {}) (string, error)". The argument
+	// names and the description are read from the Go doc, so they are only
+	// set when run from the Hugo source.
+	Args        []string `json:"args,omitempty"`
+	Signature   string   `json:"signature"`
+	Description string   `json:"description,omitempty"`
+
+	Examples [][2]string `json:"examples,omitempty"`
+}
+
+// Funcs returns info about the template funcs in the namespace, sorted by name.
+func (t *TemplateFuncsNamespace) Funcs() []TemplateFuncInfo {
+	var funcs []TemplateFuncInfo
+
+	godoc := getTplPackagesGoDocIfAvailable()[t.Name]
+
+	ctx := reflect.ValueOf(t.Context())
+	ctxType := ctx.Type()
+	for i := 0; i < ctxType.NumMethod(); i++ {
+		name := ctxType.Method(i).Name
+		methodGoDoc := godoc[name]
+
+		f := TemplateFuncInfo{
+			Namespace:   t.Name,
+			Name:        name,
+			Args:        methodGoDoc.Args,
+			Signature:   funcSignature(ctx.Method(i).Type(), methodGoDoc.Args),
+			Description: methodGoDoc.Description,
+		}
+
+		if mapping, ok := t.MethodMappings[name]; ok {
+			f.Aliases = mapping.Aliases
+			f.Examples = mapping.Examples
+		}
+
+		funcs = append(funcs, f)
+	}
+
+	return funcs
+}
+
+// funcSignature returns the signature of a func of type ft, with the argument
+// names if known.
+func funcSignature(ft reflect.Type, args []string) string {
+	var in, out []string
+
+	for i := 0; i < ft.NumIn(); i++ {
+		typ := ft.In(i).String()
+		if ft.IsVariadic() && i == ft.NumIn()-1 {
+			typ = "..." + ft.In(i).Elem().String()
+		}
+		if len(args) == ft.NumIn() {
+			typ = args[i] + " " + typ
+		}
+		in = append(in, typ)
+	}
+
+	for i := 0; i < ft.NumOut(); i++ {
+		out = append(out, ft.Out(i).String())
+	}
+
+	sig := "(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+	case 1:
+		sig += " " + out[0]
+	default:
+		sig += " (" + strings.Join(out, ", ") + ")"
+	}
+
+	return sig
+}
+
 type goDocFunc struct {
 	Name        string
 	Description string
@@ -208,15 +288,37 @@ type methodGoDocInfo struct {
 
 var (
 	tplPackagesGoDoc     map[string]map[string]methodGoDocInfo
+	tplPackagesGoDocErr  error
 	tplPackagesGoDocInit sync.Once
 )
 
+// getGetTplPackagesGoDoc returns the Go doc of the template func namespaces.
+// It needs to run from the Hugo source.
 func getGetTplPackagesGoDoc() map[string]map[string]methodGoDocInfo {
+	godoc, err := loadTplPackagesGoDoc()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return godoc
+}
+
+// getTplPackagesGoDocIfAvailable is like getGetTplPackagesGoDoc, but returns
+// nil if not run from the Hugo source.
+func getTplPackagesGoDocIfAvailable() map[string]map[string]methodGoDocInfo {
+	godoc, err := loadTplPackagesGoDoc()
+	if err != nil {
+		return nil
+	}
+	return godoc
+}
+
+func loadTplPackagesGoDoc() (map[string]map[string]methodGoDocInfo, error) {
 	tplPackagesGoDocInit.Do(func() {
 		tplPackagesGoDoc = make(map[string]map[string]methodGoDocInfo)
 		pwd, err := os.Getwd()
 		if err != nil {
-			log.Fatal(err)
+			tplPackagesGoDocErr = err
+			return
 		}
 
 		fset := token.NewFileSet()
@@ -231,7 +333,8 @@ func getGetTplPackagesGoDoc() map[string]map[string]methodGoDocInfo {
 
 		files, err := ioutil.ReadDir(basePath)
 		if err != nil {
-			log.Fatal(err)
+			tplPackagesGoDocErr = err
+			return
 		}
 
 		for _, fi := range files {
@@ -244,7 +347,8 @@ func getGetTplPackagesGoDoc() map[string]map[string]methodGoDocInfo {
 
 			d, err := parser.ParseDir(fset, packagePath, nil, parser.ParseComments)
 			if err != nil {
-				log.Fatal(err)
+				tplPackagesGoDocErr = err
+				return
 			}
 
 			for _, f := range d {
@@ -272,5 +376,5 @@ func getGetTplPackagesGoDoc() map[string]map[string]methodGoDocInfo {
 		}
 	})
 
-	return tplPackagesGoDoc
+	return tplPackagesGoDoc, tplPackagesGoDocErr
 }
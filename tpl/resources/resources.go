@@ -0,0 +1,118 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resources provides template functions for working with the
+// resources of a site, e.g. its stylesheets.
+package resources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/cast"
+)
+
+// New returns a new instance of the resources-namespaced template functions.
+func New(deps *deps.Deps) *Namespace {
+	return &Namespace{deps: deps}
+}
+
+// Namespace provides template functions for the "resources" namespace.
+type Namespace struct {
+	deps *deps.Deps
+}
+
+// Critical returns a stylesheet link for the given href, a string or a
+// resource, marked for critical CSS extraction. When the page is rendered,
+// the rules of the stylesheet needed to render the part of the page above
+// the fold are inlined and the full stylesheet is loaded deferred.
+//
+// The optional options map may set "selectors", a list of selectors always
+// included in the critical CSS, and "elements", the number of elements in
+// the body considered above the fold:
+//
+//   {{ resources.Critical "/css/main.css" (dict "selectors" (slice ".hero") "elements" 50) }}
+func (ns *Namespace) Critical(href interface{}, options ...interface{}) (template.HTML, error) {
+	var link string
+
+	switch v := href.(type) {
+	case interface {
+		RelPermalink() string
+	}:
+		link = v.RelPermalink()
+	default:
+		var err error
+		link, err = cast.ToStringE(href)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the link of %T: %s", href, err)
+		}
+	}
+
+	if link == "" {
+		return "", errors.New("missing stylesheet link")
+	}
+
+	if len(options) > 1 {
+		return "", errors.New("too many arguments")
+	}
+
+	var (
+		selectors []string
+		elements  = -1
+	)
+
+	if len(options) == 1 {
+		opts, err := cast.ToStringMapE(options[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to decode options: %s", err)
+		}
+
+		for k, v := range opts {
+			switch strings.ToLower(k) {
+			case "selectors":
+				if selectors, err = cast.ToStringSliceE(v); err != nil {
+					return "", fmt.Errorf("failed to decode selectors: %s", err)
+				}
+			case "elements":
+				if elements, err = cast.ToIntE(v); err != nil || elements < 0 {
+					return "", fmt.Errorf("invalid number of elements: %v", v)
+				}
+			default:
+				return "", fmt.Errorf("unknown option %q", k)
+			}
+		}
+	}
+
+	for _, s := range selectors {
+		if strings.Contains(s, ",") {
+			return "", fmt.Errorf("selector %q must not contain a comma", s)
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<link rel="stylesheet" href="`)
+	b.WriteString(template.HTMLEscapeString(link))
+	b.WriteString(`" data-critical="`)
+	b.WriteString(template.HTMLEscapeString(strings.Join(selectors, ", ")))
+	b.WriteString(`"`)
+	if elements >= 0 {
+		fmt.Fprintf(&b, ` data-critical-elements="%d"`, elements)
+	}
+	b.WriteString(">")
+
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"fmt"
+	"html/template"
+	"testing"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+type tstResource struct{}
+
+func (tstResource) RelPermalink() string {
+	return "/post/css/style.css"
+}
+
+func TestCritical(t *testing.T) {
+	t.Parallel()
+
+	ns := New(&deps.Deps{Cfg: viper.New()})
+
+	for i, test := range []struct {
+		href    interface{}
+		options []interface{}
+		expect  interface{}
+	}{
+		{"/css/main.css", nil, template.HTML(`<link rel="stylesheet" href="/css/main.css" data-critical="">`)},
+		{tstResource{}, nil, template.HTML(`<link rel="stylesheet" href="/post/css/style.css" data-critical="">`)},
+		{"/css/main.css", []interface{}{map[string]interface{}{"selectors": []string{".hero", "#nav > a"}, "elements": 20}},
+			template.HTML(`<link rel="stylesheet" href="/css/main.css" data-critical=".hero, #nav &gt; a" data-critical-elements="20">`)},
+		{"", nil, false},
+		{"/css/main.css", []interface{}{map[string]interface{}{"elements": -1}}, false},
+		{"/css/main.css", []interface{}{map[string]interface{}{"selectors": []string{"a, b"}}}, false},
+		{"/css/main.css", []interface{}{map[string]interface{}{"foo": 1}}, false},
+		{"/css/main.css", []interface{}{map[string]interface{}{}, 1}, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %v", i, test)
+
+		result, err := ns.Critical(test.href, test.options...)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		require.Equal(t, test.expect, result, errMsg)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema provides template functions for creating schema.org
+// structured data as JSON-LD.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/cast"
+)
+
+// New returns a new instance of the schema-namespaced template functions.
+func New(deps *deps.Deps) *Namespace {
+	return &Namespace{deps: deps}
+}
+
+// Namespace provides template functions for the "schema" namespace.
+//
+// The functions create the JSON-LD from the page's metadata, with defaults
+// in the schema site config:
+//
+//   [schema]
+//   author = "Jane Doe"
+//   currency = "EUR"
+//   [schema.organization]
+//   name = "Acme"
+//   url = "https://example.com/"
+//   logo = "images/logo.png"
+//   sameAs = ["https://twitter.com/acme"]
+//
+// A warning is logged for missing required properties.
+type Namespace struct {
+	deps *deps.Deps
+}
+
+// Article returns the Article JSON-LD for the given page. The headline is
+// the page's title, the images and author are read from the images and
+// author params.
+func (ns *Namespace) Article(page interface{}) (template.HTML, error) {
+	d := ns.newThing("Article")
+	d["headline"] = cast.ToString(field(page, "Title"))
+	d["description"] = description(page)
+	d["url"] = cast.ToString(field(page, "Permalink"))
+	d["mainEntityOfPage"] = d["url"]
+	d["image"] = ns.images(page)
+	d["datePublished"] = formatDate(field(page, "PublishDate"))
+	d["dateModified"] = formatDate(field(page, "Lastmod"))
+
+	if author := param(page, "author"); author != nil {
+		d["author"] = person(author)
+	} else if author := ns.config()["author"]; author != nil {
+		d["author"] = person(author)
+	}
+
+	if org := ns.organization(); org != nil {
+		d["publisher"] = org
+	}
+
+	return ns.render(page, d, "headline", "image", "datePublished", "author", "publisher")
+}
+
+// Breadcrumb returns the BreadcrumbList JSON-LD with the page and its
+// ancestors, starting with the home page.
+func (ns *Namespace) Breadcrumb(page interface{}) (template.HTML, error) {
+	var pages []interface{}
+	for p := page; !isNil(p); p = field(p, "Parent") {
+		pages = append([]interface{}{p}, pages...)
+	}
+
+	items := make([]interface{}, len(pages))
+	for i, p := range pages {
+		items[i] = map[string]interface{}{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     cast.ToString(field(p, "Title")),
+			"item":     cast.ToString(field(p, "Permalink")),
+		}
+	}
+
+	d := ns.newThing("BreadcrumbList")
+	d["itemListElement"] = items
+
+	return ns.render(page, d, "itemListElement")
+}
+
+// Organization returns the Organization JSON-LD from the schema.organization
+// site config.
+func (ns *Namespace) Organization() (template.HTML, error) {
+	d := ns.organization()
+	if d == nil {
+		d = ns.newThing("Organization")
+	}
+	d["@context"] = "https://schema.org"
+
+	return ns.render(nil, d, "name", "url")
+}
+
+// Product returns the Product JSON-LD for the given page. The name is the
+// page's title, the product details are read from the product param, e.g.
+//
+//   [product]
+//   sku = "A-123"
+//   brand = "Acme"
+//   price = 9.99
+//   availability = "InStock"
+func (ns *Namespace) Product(page interface{}) (template.HTML, error) {
+	product := cast.ToStringMap(param(page, "product"))
+
+	d := ns.newThing("Product")
+	d["name"] = cast.ToString(field(page, "Title"))
+	d["description"] = description(page)
+	d["url"] = cast.ToString(field(page, "Permalink"))
+	d["image"] = ns.images(page)
+	d["sku"] = cast.ToString(product["sku"])
+
+	if brand := cast.ToString(product["brand"]); brand != "" {
+		d["brand"] = map[string]interface{}{"@type": "Brand", "name": brand}
+	}
+
+	if price, found := product["price"]; found {
+		currency := cast.ToString(product["currency"])
+		if currency == "" {
+			currency = cast.ToString(ns.config()["currency"])
+		}
+		offer := map[string]interface{}{
+			"@type":         "Offer",
+			"price":         cast.ToString(price),
+			"priceCurrency": currency,
+			"url":           d["url"],
+		}
+		if availability := cast.ToString(product["availability"]); availability != "" {
+			if !strings.Contains(availability, "/") {
+				availability = "https://schema.org/" + availability
+			}
+			offer["availability"] = availability
+		}
+		d["offers"] = offer
+	}
+
+	return ns.render(page, d, "name", "image", "offers")
+}
+
+func (ns *Namespace) newThing(tp string) map[string]interface{} {
+	return map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    tp,
+	}
+}
+
+func (ns *Namespace) config() map[string]interface{} {
+	return cast.ToStringMap(ns.deps.Cfg.Get("schema"))
+}
+
+func (ns *Namespace) organization() map[string]interface{} {
+	conf := cast.ToStringMap(ns.config()["organization"])
+	if len(conf) == 0 {
+		return nil
+	}
+
+	d := map[string]interface{}{"@type": "Organization"}
+	for _, key := range []string{"name", "url", "sameAs"} {
+		if v, found := conf[strings.ToLower(key)]; found {
+			d[key] = v
+		}
+	}
+	if logo := cast.ToString(conf["logo"]); logo != "" {
+		d["logo"] = ns.absURL(logo)
+	}
+
+	return d
+}
+
+func (ns *Namespace) images(page interface{}) []string {
+	var images []string
+	for _, img := range cast.ToStringSlice(param(page, "images")) {
+		images = append(images, ns.absURL(img))
+	}
+	return images
+}
+
+func (ns *Namespace) absURL(s string) string {
+	if ns.deps.PathSpec == nil {
+		return s
+	}
+	return ns.deps.PathSpec.AbsURL(s, false)
+}
+
+// render removes the empty properties, logs a warning for the missing
+// required ones and returns the JSON-LD script element.
+func (ns *Namespace) render(page interface{}, d map[string]interface{}, required ...string) (template.HTML, error) {
+	for k, v := range d {
+		if isEmpty(v) {
+			delete(d, k)
+		}
+	}
+
+	var missing []string
+	for _, k := range required {
+		if _, found := d[k]; !found {
+			missing = append(missing, k)
+		}
+	}
+
+	if len(missing) > 0 {
+		what := "site config"
+		if page != nil {
+			what = cast.ToString(field(page, "Permalink"))
+		}
+		helpers.DistinctWarnLog.Printf("schema: %s for %s is missing the required %s", d["@type"], what, strings.Join(missing, ", "))
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	if err := enc.Encode(d); err != nil {
+		return "", fmt.Errorf("failed to create JSON-LD: %s", err)
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + strings.TrimSpace(b.String()) + `</script>`), nil
+}
+
+func person(v interface{}) interface{} {
+	if m, err := cast.ToStringMapE(v); err == nil {
+		p := map[string]interface{}{"@type": "Person"}
+		for k, v := range m {
+			p[k] = v
+		}
+		return p
+	}
+	return map[string]interface{}{"@type": "Person", "name": cast.ToString(v)}
+}
+
+func description(page interface{}) string {
+	if d := cast.ToString(field(page, "Description")); d != "" {
+		return d
+	}
+	return strings.TrimSpace(helpers.StripHTML(cast.ToString(field(page, "Summary"))))
+}
+
+func formatDate(v interface{}) string {
+	t, ok := v.(time.Time)
+	if !ok || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// param returns the page's param with the given key, falling back to the
+// site params.
+func param(page interface{}, key string) interface{} {
+	m := reflect.ValueOf(page).MethodByName("Param")
+	if !m.IsValid() {
+		return nil
+	}
+	out := m.Call([]reflect.Value{reflect.ValueOf(key)})
+	if len(out) == 0 || !out[0].IsValid() {
+		return nil
+	}
+	return out[0].Interface()
+}
+
+// field returns the value of the page's field or method without arguments
+// with the given name.
+func field(page interface{}, name string) interface{} {
+	v := reflect.ValueOf(page)
+	if !v.IsValid() {
+		return nil
+	}
+
+	if m := v.MethodByName(name); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() > 0 {
+		out := m.Call(nil)
+		return out[0].Interface()
+	}
+
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if f := v.FieldByName(name); f.IsValid() && f.CanInterface() {
+		return f.Interface()
+	}
+
+	return nil
+}
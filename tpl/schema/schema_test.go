@@ -0,0 +1,168 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gohugoio/hugo/deps"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+type testPage struct {
+	Title       string
+	Description string
+	Summary     template.HTML
+	PublishDate time.Time
+	Lastmod     time.Time
+	Params      map[string]interface{}
+
+	permalink string
+	parent    *testPage
+}
+
+func (p *testPage) Permalink() string {
+	return p.permalink
+}
+
+func (p *testPage) Parent() *testPage {
+	return p.parent
+}
+
+func (p *testPage) Param(key interface{}) (interface{}, error) {
+	return p.Params[key.(string)], nil
+}
+
+func decodeJSONLD(t *testing.T, h template.HTML) map[string]interface{} {
+	s := string(h)
+	require.True(t, strings.HasPrefix(s, `<script type="application/ld+json">`), s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, `<script type="application/ld+json">`), "</script>")
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+	return m
+}
+
+func newTestNamespace() *Namespace {
+	v := viper.New()
+	v.Set("schema", map[string]interface{}{
+		"author":   "Jane",
+		"currency": "EUR",
+		"organization": map[string]interface{}{
+			"name":   "Acme",
+			"url":    "https://example.com/",
+			"logo":   "https://example.com/logo.png",
+			"sameas": []string{"https://twitter.com/acme"},
+		},
+	})
+	return New(&deps.Deps{Cfg: v})
+}
+
+func TestArticle(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+	ns := newTestNamespace()
+
+	p := &testPage{
+		Title:       "Hello </script>",
+		Summary:     "<p>The summary.</p>",
+		PublishDate: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Params:      map[string]interface{}{"images": []string{"https://example.com/a.jpg"}},
+		permalink:   "https://example.com/hello/",
+	}
+
+	h, err := ns.Article(p)
+	assert.NoError(err)
+	assert.Contains(string(h), `"Hello \u003c/script\u003e"`)
+
+	d := decodeJSONLD(t, h)
+	assert.Equal("https://schema.org", d["@context"])
+	assert.Equal("Article", d["@type"])
+	assert.Equal("Hello </script>", d["headline"])
+	assert.Equal("The summary.", d["description"])
+	assert.Equal("2018-01-02T03:04:05Z", d["datePublished"])
+	assert.Equal(map[string]interface{}{"@type": "Person", "name": "Jane"}, d["author"])
+	assert.Equal("Acme", d["publisher"].(map[string]interface{})["name"])
+	assert.NotContains(d, "dateModified")
+}
+
+func TestBreadcrumb(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+	ns := newTestNamespace()
+
+	home := &testPage{Title: "Home", permalink: "https://example.com/"}
+	blog := &testPage{Title: "Blog", permalink: "https://example.com/blog/", parent: home}
+	post := &testPage{Title: "Post", permalink: "https://example.com/blog/post/", parent: blog}
+
+	h, err := ns.Breadcrumb(post)
+	assert.NoError(err)
+
+	items := decodeJSONLD(t, h)["itemListElement"].([]interface{})
+	assert.Len(items, 3)
+	assert.Equal(map[string]interface{}{"@type": "ListItem", "position": float64(1), "name": "Home", "item": "https://example.com/"}, items[0])
+	assert.Equal("Post", items[2].(map[string]interface{})["name"])
+}
+
+func TestOrganization(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	d := decodeJSONLD(t, mustHTML(newTestNamespace().Organization()))
+	assert.Equal("Organization", d["@type"])
+	assert.Equal("https://schema.org", d["@context"])
+	assert.Equal([]interface{}{"https://twitter.com/acme"}, d["sameAs"])
+
+	d = decodeJSONLD(t, mustHTML(New(&deps.Deps{Cfg: viper.New()}).Organization()))
+	assert.Equal(map[string]interface{}{"@context": "https://schema.org", "@type": "Organization"}, d)
+}
+
+func TestProduct(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+	ns := newTestNamespace()
+
+	p := &testPage{
+		Title:       "Widget",
+		Description: "A widget.",
+		Params: map[string]interface{}{"product": map[string]interface{}{
+			"sku": "W-1", "brand": "Acme", "price": 9.99, "availability": "InStock",
+		}},
+		permalink: "https://example.com/widget/",
+	}
+
+	d := decodeJSONLD(t, mustHTML(ns.Product(p)))
+	assert.Equal("Widget", d["name"])
+	assert.Equal("W-1", d["sku"])
+	assert.Equal(map[string]interface{}{"@type": "Brand", "name": "Acme"}, d["brand"])
+	assert.Equal(map[string]interface{}{
+		"@type":         "Offer",
+		"price":         "9.99",
+		"priceCurrency": "EUR",
+		"availability":  "https://schema.org/InStock",
+		"url":           "https://example.com/widget/",
+	}, d["offers"])
+}
+
+func mustHTML(h template.HTML, err error) template.HTML {
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
@@ -65,10 +65,36 @@ type TemplateDebugger interface {
 	Debug()
 }
 
+// TemplateInfo describes a template loaded from file.
+type TemplateInfo struct {
+	// The template name, e.g. "posts/single.html".
+	Name string
+
+	// The template file.
+	Filename string
+
+	// The base templates it extends, innermost first, e.g. a section baseof
+	// followed by the site baseof.
+	BaseFilenames []string
+}
+
+// TemplateInfoProvider provides information about the templates loaded from
+// file.
+type TemplateInfoProvider interface {
+	TemplateInfos() []TemplateInfo
+}
+
 // TemplateAdapter implements the TemplateExecutor interface.
 type TemplateAdapter struct {
 	Template
 	Metrics metrics.Provider
+
+	// Records the template executions if set.
+	Coverage *Coverage
+
+	// The name the template was looked up with. This differs from the
+	// template's name for templates extending a base template.
+	LookupName string
 }
 
 // Execute executes the current template. The actual execution is performed
@@ -78,6 +104,13 @@ func (t *TemplateAdapter) Execute(w io.Writer, data interface{}) error {
 	if t.Metrics != nil {
 		defer t.Metrics.MeasureSince(t.Name(), time.Now())
 	}
+	if t.Coverage != nil {
+		name := t.LookupName
+		if name == "" {
+			name = t.Name()
+		}
+		t.Coverage.Track(name)
+	}
 	return t.Template.Execute(w, data)
 }
 
@@ -65,6 +65,13 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.In,
+			nil,
+			[][2]string{
+				{`{{ (time.In "America/New_York" "2015-01-21T12:00:00Z").Hour }}`, `7`},
+			},
+		)
+
 		ns.AddMethodMapping(ctx.Duration,
 			[]string{"duration"},
 			[][2]string{
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	_time "time"
 
+	"github.com/gohugoio/hugo/helpers"
 	"github.com/spf13/cast"
 )
 
@@ -29,9 +30,19 @@ func New() *Namespace {
 type Namespace struct{}
 
 // AsTime converts the textual representation of the datetime string into
-// a time.Time interface.
-func (ns *Namespace) AsTime(v interface{}) (interface{}, error) {
-	t, err := cast.ToTimeE(v)
+// a time.Time interface. A datetime string without a time zone is in the
+// optional location, a name, e.g. "Europe/Oslo", or a *time.Location,
+// e.g. .Site.TimeZone, in UTC if not set.
+func (ns *Namespace) AsTime(v interface{}, location ...interface{}) (interface{}, error) {
+	var loc *_time.Location
+	if len(location) > 0 {
+		var err error
+		if loc, err = toLocation(location[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := helpers.ToTimeInLocationE(v, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +50,37 @@ func (ns *Namespace) AsTime(v interface{}) (interface{}, error) {
 	return t, nil
 }
 
+// In returns the time v in the location, a name, e.g. "America/New_York",
+// or a *time.Location, e.g. .Site.TimeZone.
+func (ns *Namespace) In(location interface{}, v interface{}) (_time.Time, error) {
+	loc, err := toLocation(location)
+	if err != nil {
+		return _time.Time{}, err
+	}
+
+	t, err := cast.ToTimeE(v)
+	if err != nil {
+		return _time.Time{}, err
+	}
+
+	return t.In(loc), nil
+}
+
+func toLocation(v interface{}) (*_time.Location, error) {
+	switch vv := v.(type) {
+	case *_time.Location:
+		return vv, nil
+	case string:
+		loc, err := _time.LoadLocation(vv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location %q: %s", vv, err)
+		}
+		return loc, nil
+	default:
+		return nil, fmt.Errorf("invalid location %v of type %T", v, v)
+	}
+}
+
 // Format converts the textual representation of the datetime string into
 // the other form or returns it of the time.Time value. These are formatted
 // with the layout string
@@ -98,3 +98,50 @@ func TestDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestAsTimeAndIn(t *testing.T) {
+	t.Parallel()
+
+	ns := New()
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range []struct {
+		value    interface{}
+		location []interface{}
+		expect   interface{}
+	}{
+		{"2015-01-21T12:00:00", nil, time.Date(2015, 1, 21, 12, 0, 0, 0, time.UTC)},
+		{"2015-01-21T12:00:00", []interface{}{"Europe/Oslo"}, time.Date(2015, 1, 21, 12, 0, 0, 0, oslo)},
+		{"2015-01-21T12:00:00", []interface{}{oslo}, time.Date(2015, 1, 21, 12, 0, 0, 0, oslo)},
+		{"2015-01-21T12:00:00Z", []interface{}{oslo}, time.Date(2015, 1, 21, 12, 0, 0, 0, time.UTC)},
+		{"2015-01-21T12:00:00", []interface{}{"Nowhere/Nothing"}, false},
+		{"2015-01-21T12:00:00", []interface{}{42}, false},
+	} {
+		result, err := ns.AsTime(test.value, test.location...)
+		if b, ok := test.expect.(bool); ok && !b {
+			if err == nil {
+				t.Errorf("[%d] AsTime didn't return an expected error, got %v", i, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] AsTime failed: %s", i, err)
+			continue
+		}
+		if !result.(time.Time).Equal(test.expect.(time.Time)) || result.(time.Time).Location().String() != test.expect.(time.Time).Location().String() {
+			t.Errorf("[%d] AsTime got %v but expected %v", i, result, test.expect)
+		}
+	}
+
+	result, err := ns.In("Europe/Oslo", "2015-01-21T12:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Hour() != 13 || result.Location() != oslo && result.Location().String() != "Europe/Oslo" {
+		t.Errorf("In got %v", result)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"html/template"
 	"path"
+	"sort"
 	"strings"
 	texttemplate "text/template"
 
@@ -42,6 +43,7 @@ const (
 var (
 	_ tpl.TemplateHandler       = (*templateHandler)(nil)
 	_ tpl.TemplateDebugger      = (*templateHandler)(nil)
+	_ tpl.TemplateInfoProvider  = (*templateHandler)(nil)
 	_ tpl.TemplateFuncsGetter   = (*templateHandler)(nil)
 	_ tpl.TemplateTestMocker    = (*templateHandler)(nil)
 	_ tpl.TemplateFinder        = (*htmlTemplates)(nil)
@@ -62,7 +64,7 @@ type templateErr struct {
 }
 
 type templateLoader interface {
-	handleMaster(name, overlayFilename, masterFilename string, onMissing func(filename string) (string, error)) error
+	handleMaster(name, overlayFilename string, masterFilenames []string, onMissing func(filename string) (string, error)) error
 	addTemplate(name, tpl string) error
 	addLateTemplate(name, tpl string) error
 }
@@ -84,6 +86,9 @@ type templateHandler struct {
 
 	errors []*templateErr
 
+	// The templates loaded from file, keyed by name.
+	infos map[string]tpl.TemplateInfo
+
 	*deps.Deps
 }
 
@@ -96,6 +101,29 @@ func (t *templateHandler) Debug() {
 	fmt.Println("\n\nText templates:\n", t.text.t.DefinedTemplates())
 }
 
+func (t *templateHandler) addTemplateInfo(id output.TemplateNames) {
+	info := tpl.TemplateInfo{
+		Name:     strings.TrimPrefix(id.Name, textTmplNamePrefix),
+		Filename: id.OverlayFilename,
+	}
+	if id.MasterFilename != "" {
+		info.BaseFilenames = append([]string{id.MasterFilename}, id.MasterBaseFilenames...)
+	}
+	t.infos[info.Name] = info
+}
+
+// TemplateInfos returns the templates loaded from file, sorted by name.
+func (t *templateHandler) TemplateInfos() []tpl.TemplateInfo {
+	infos := make([]tpl.TemplateInfo, 0, len(t.infos))
+	for _, info := range t.infos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
 // PrintErrors prints the accumulated errors as ERROR to the log.
 func (t *templateHandler) PrintErrors() {
 	for _, e := range t.errors {
@@ -131,6 +159,7 @@ func (t *templateHandler) clone(d *deps.Deps) *templateHandler {
 		html:   &htmlTemplates{t: template.Must(t.html.t.Clone()), overlays: make(map[string]*template.Template)},
 		text:   &textTemplates{t: texttemplate.Must(t.text.t.Clone()), overlays: make(map[string]*texttemplate.Template)},
 		errors: make([]*templateErr, 0),
+		infos:  t.infos,
 	}
 
 	d.Tmpl = c
@@ -172,6 +201,7 @@ func newTemplateAdapter(deps *deps.Deps) *templateHandler {
 		html:   htmlT,
 		text:   textT,
 		errors: make([]*templateErr, 0),
+		infos:  make(map[string]tpl.TemplateInfo),
 	}
 
 }
@@ -202,7 +232,7 @@ func (t *htmlTemplates) Lookup(name string) *tpl.TemplateAdapter {
 	if templ == nil {
 		return nil
 	}
-	return &tpl.TemplateAdapter{Template: templ, Metrics: t.funcster.Deps.Metrics}
+	return &tpl.TemplateAdapter{Template: templ, Metrics: t.funcster.Deps.Metrics, Coverage: t.funcster.Deps.Coverage, LookupName: name}
 }
 
 func (t *htmlTemplates) lookup(name string) *template.Template {
@@ -242,7 +272,7 @@ func (t *textTemplates) Lookup(name string) *tpl.TemplateAdapter {
 	if templ == nil {
 		return nil
 	}
-	return &tpl.TemplateAdapter{Template: templ, Metrics: t.funcster.Deps.Metrics}
+	return &tpl.TemplateAdapter{Template: templ, Metrics: t.funcster.Deps.Metrics, Coverage: t.funcster.Deps.Coverage, LookupName: name}
 }
 
 func (t *textTemplates) lookup(name string) *texttemplate.Template {
@@ -465,6 +495,9 @@ func (t *templateHandler) loadTemplates(absPath string, prefix string) {
 				ContainsAny: func(filename string, subslices [][]byte) (bool, error) {
 					return helpers.FileContainsAny(filename, subslices, t.Fs.Source)
 				},
+				ReadFile: func(filename string) ([]byte, error) {
+					return afero.ReadFile(t.Fs.Source, filename)
+				},
 			}
 
 			tplID, err := output.CreateTemplateNames(descriptor)
@@ -474,10 +507,13 @@ func (t *templateHandler) loadTemplates(absPath string, prefix string) {
 				return nil
 			}
 
-			if err := t.addTemplateFile(tplID.Name, tplID.MasterFilename, tplID.OverlayFilename); err != nil {
+			if err := t.addTemplateFile(tplID.Name, tplID.MasterFilename, tplID.OverlayFilename, tplID.MasterBaseFilenames...); err != nil {
 				t.Log.ERROR.Printf("Failed to add template %q in path %q: %s", tplID.Name, path, err)
 			}
 
+			t.addTemplateInfo(tplID)
+			t.checkEmbeddedPin(tplID.OverlayFilename)
+
 		}
 		return nil
 	}
@@ -527,16 +563,19 @@ func (t *templateHandler) getTemplateHandler(name string) templateLoader {
 	return t.html
 }
 
-func (t *templateHandler) handleMaster(name, overlayFilename, masterFilename string, onMissing func(filename string) (string, error)) error {
+// handleMaster adds the overlay template extending the given master
+// templates, innermost first, e.g. a section baseof and the site baseof.
+func (t *templateHandler) handleMaster(name, overlayFilename string, masterFilenames []string, onMissing func(filename string) (string, error)) error {
 	h := t.getTemplateHandler(name)
-	return h.handleMaster(name, overlayFilename, masterFilename, onMissing)
+	return h.handleMaster(name, overlayFilename, masterFilenames, onMissing)
 }
 
-func (t *htmlTemplates) handleMaster(name, overlayFilename, masterFilename string, onMissing func(filename string) (string, error)) error {
-	masterTpl := t.lookup(masterFilename)
+func (t *htmlTemplates) handleMaster(name, overlayFilename string, masterFilenames []string, onMissing func(filename string) (string, error)) error {
+	outermost := masterFilenames[len(masterFilenames)-1]
+	masterTpl := t.lookup(outermost)
 
 	if masterTpl == nil {
-		templ, err := onMissing(masterFilename)
+		templ, err := onMissing(outermost)
 		if err != nil {
 			return err
 		}
@@ -547,6 +586,18 @@ func (t *htmlTemplates) handleMaster(name, overlayFilename, masterFilename strin
 		}
 	}
 
+	for i := len(masterFilenames) - 2; i >= 0; i-- {
+		templ, err := onMissing(masterFilenames[i])
+		if err != nil {
+			return err
+		}
+
+		masterTpl, err = template.Must(masterTpl.Clone()).Parse(templ)
+		if err != nil {
+			return err
+		}
+	}
+
 	templ, err := onMissing(overlayFilename)
 	if err != nil {
 		return err
@@ -570,12 +621,13 @@ func (t *htmlTemplates) handleMaster(name, overlayFilename, masterFilename strin
 
 }
 
-func (t *textTemplates) handleMaster(name, overlayFilename, masterFilename string, onMissing func(filename string) (string, error)) error {
+func (t *textTemplates) handleMaster(name, overlayFilename string, masterFilenames []string, onMissing func(filename string) (string, error)) error {
 	name = strings.TrimPrefix(name, textTmplNamePrefix)
-	masterTpl := t.lookup(masterFilename)
+	outermost := masterFilenames[len(masterFilenames)-1]
+	masterTpl := t.lookup(outermost)
 
 	if masterTpl == nil {
-		templ, err := onMissing(masterFilename)
+		templ, err := onMissing(outermost)
 		if err != nil {
 			return err
 		}
@@ -586,6 +638,18 @@ func (t *textTemplates) handleMaster(name, overlayFilename, masterFilename strin
 		}
 	}
 
+	for i := len(masterFilenames) - 2; i >= 0; i-- {
+		templ, err := onMissing(masterFilenames[i])
+		if err != nil {
+			return err
+		}
+
+		masterTpl, err = texttemplate.Must(masterTpl.Clone()).Parse(templ)
+		if err != nil {
+			return err
+		}
+	}
+
 	templ, err := onMissing(overlayFilename)
 	if err != nil {
 		return err
@@ -606,7 +670,9 @@ func (t *textTemplates) handleMaster(name, overlayFilename, masterFilename strin
 
 }
 
-func (t *templateHandler) addTemplateFile(name, baseTemplatePath, path string) error {
+// addTemplateFile adds the template in path, extending the base template,
+// if set, which in turn may extend the base templates in basePaths.
+func (t *templateHandler) addTemplateFile(name, baseTemplatePath, path string, basePaths ...string) error {
 	t.checkState()
 
 	getTemplate := func(filename string) (string, error) {
@@ -670,7 +736,7 @@ func (t *templateHandler) addTemplateFile(name, baseTemplatePath, path string) e
 	default:
 
 		if baseTemplatePath != "" {
-			return t.handleMaster(name, path, baseTemplatePath, getTemplate)
+			return t.handleMaster(name, path, append([]string{baseTemplatePath}, basePaths...), getTemplate)
 		}
 
 		templ, err := getTemplate(path)
@@ -687,15 +753,22 @@ func (t *templateHandler) addTemplateFile(name, baseTemplatePath, path string) e
 }
 
 func (t *templateHandler) loadEmbedded() {
-	t.embedShortcodes()
-	t.embedTemplates()
+	embedShortcodes(t)
+	embedTemplates(t)
 }
 
+// addInternalTemplate adds an embedded template. Prefix the prefix with
+// _text/ for a plain text template, e.g. _text/_default.
 func (t *templateHandler) addInternalTemplate(prefix, name, tpl string) error {
+	var textPrefix string
+	if strings.HasPrefix(prefix, textTmplNamePrefix) {
+		textPrefix = textTmplNamePrefix
+		prefix = strings.TrimPrefix(prefix, textTmplNamePrefix)
+	}
 	if prefix != "" {
-		return t.AddTemplate("_internal/"+prefix+"/"+name, tpl)
+		return t.AddTemplate(textPrefix+"_internal/"+prefix+"/"+name, tpl)
 	}
-	return t.AddTemplate("_internal/"+name, tpl)
+	return t.AddTemplate(textPrefix+"_internal/"+name, tpl)
 }
 
 func (t *templateHandler) addInternalShortcode(name, content string) error {
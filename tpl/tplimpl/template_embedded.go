@@ -13,15 +13,30 @@
 
 package tplimpl
 
-func (t *templateHandler) embedShortcodes() {
+func embedShortcodes(t internalTemplateAdder) {
 	t.addInternalShortcode("ref.html", `{{ if len .Params | eq 2 }}{{ ref .Page (.Get 0) (.Get 1) }}{{ else }}{{ ref .Page (.Get 0) }}{{ end }}`)
 	t.addInternalShortcode("relref.html", `{{ if len .Params | eq 2 }}{{ relref .Page (.Get 0) (.Get 1) }}{{ else }}{{ relref .Page (.Get 0) }}{{ end }}`)
 	t.addInternalShortcode("highlight.html", `{{ if len .Params | eq 2 }}{{ highlight (trim .Inner "\n\r") (.Get 0) (.Get 1) }}{{ else }}{{ highlight (trim .Inner "\n\r") (.Get 0) "" }}{{ end }}`)
 	t.addInternalShortcode("test.html", `This is a simple Test`)
+	// Renders an img element with a srcset of resized versions of the image
+	// resource. The widths and sizes can be set in the figure and gallery
+	// site params.
+	t.addInternalTemplate("", "responsive_image.html", `{{- $img := .img -}}
+{{- $s := .scratch -}}
+{{- $s.Set "srcset" (slice) -}}
+{{- range (index .params "widths" | default (slice 480 800 1200)) -}}
+{{- if lt . $img.Width }}{{ $s.Add "srcset" (printf "%s %dw" ($img.Resize (printf "%dx" .)).RelPermalink .) }}{{ end -}}
+{{- end -}}
+{{- $s.Add "srcset" (printf "%s %dw" $img.RelPermalink $img.Width) -}}
+<img src="{{ $img.RelPermalink }}" srcset="{{ delimit ($s.Get "srcset") ", " }}" sizes="{{ index .params "sizes" | default "100vw" }}" width="{{ $img.Width }}" height="{{ $img.Height }}" alt="{{ .alt }}" />`)
 	t.addInternalShortcode("figure.html", `<!-- image -->
 <figure {{ with .Get "class" }}class="{{.}}"{{ end }}>
     {{ with .Get "link"}}<a href="{{.}}">{{ end }}
+        {{- with (.Page.Resources.ByType "image").GetMatch (.Get "src") }}
+        {{ template "_internal/responsive_image.html" (dict "img" . "alt" (or ($.Get "alt") ($.Get "caption") ($.Get "title")) "params" ($.Page.Site.Params.figure | default dict) "scratch" $.Scratch) }}
+        {{- else }}
         <img src="{{ .Get "src" }}" {{ if or (.Get "alt") (.Get "caption") }}alt="{{ with .Get "alt"}}{{.}}{{else}}{{ .Get "caption" }}{{ end }}" {{ end }}{{ with .Get "width" }}width="{{.}}" {{ end }}{{ with .Get "height" }}height="{{.}}" {{ end }}/>
+        {{- end }}
     {{ if .Get "link"}}</a>{{ end }}
     {{ if or (or (.Get "title") (.Get "caption")) (.Get "attr")}}
     <figcaption>{{ if isset .Params "title" }}
@@ -36,29 +51,83 @@ func (t *templateHandler) embedShortcodes() {
     {{ end }}
 </figure>
 <!-- image -->`)
+	// Renders the images in the page bundle matching the glob in match as
+	// thumbnails linking to the full images. The thumbnail size can be set
+	// in the gallery site params or with the thumbnail parameter.
+	t.addInternalShortcode("gallery.html", `{{- $params := .Page.Site.Params.gallery | default dict -}}
+{{- $thumbnail := .Get "thumbnail" | default (index $params "thumbnail") | default "300x200" -}}
+<div class="gallery{{ with .Get "class" }} {{ . }}{{ end }}" role="group"{{ with .Get "title" }} aria-label="{{ . }}"{{ end }}>
+{{- range (.Page.Resources.ByType "image").Match (.Get "match" | default "*") }}
+{{- $thumb := .Fill $thumbnail }}
+  <figure class="gallery-item">
+    <a href="{{ .RelPermalink }}"><img src="{{ $thumb.RelPermalink }}" width="{{ $thumb.Width }}" height="{{ $thumb.Height }}" alt="{{ replaceRE "^.*/([^/]+)\\.\\w+$" "$1" .RelPermalink | humanize }}" /></a>
+  </figure>
+{{- end }}
+</div>`)
+	t.addInternalShortcode("include.html", `{{ .Include (.Get 0) (.Get 1) }}`)
+	t.addInternalShortcode("markup.html", `{{ renderMarkup (.Get 0) .Inner }}`)
 	t.addInternalShortcode("speakerdeck.html", "<script async class='speakerdeck-embed' data-id='{{ index .Params 0 }}' data-ratio='1.33333333333333' src='//speakerdeck.com/assets/embed.js'></script>")
-	t.addInternalShortcode("youtube.html", `{{ if .IsNamedParams }}
+	t.addInternalShortcode("youtube.html", `{{- $pc := .Page.Site.Config.Privacy.YouTube -}}
+{{- if not $pc.Disable -}}
+{{- $host := cond $pc.PrivacyEnhanced "www.youtube-nocookie.com" "www.youtube.com" -}}
+{{- if $pc.Simple -}}
+{{- $id := cond .IsNamedParams (.Get "id") (.Get 0) -}}
+<div class="youtube"><a href="https://www.youtube.com/watch?v={{ $id }}"><img src="{{ getRemoteCopy (printf "images/youtube/%s.jpg" $id) "https://i.ytimg.com/vi/" $id "/hqdefault.jpg" }}" alt="YouTube Video" /></a></div>
+{{- else -}}
+{{ if .IsNamedParams }}
 <div {{ if .Get "class" }}class="{{ .Get "class" }}"{{ else }}style="position: relative; padding-bottom: 56.25%; padding-top: 30px; height: 0; overflow: hidden;"{{ end }}>
-  <iframe src="//www.youtube.com/embed/{{ .Get "id" }}?{{ with .Get "autoplay" }}{{ if eq . "true" }}autoplay=1{{ end }}{{ end }}"
+  <iframe src="//{{ $host }}/embed/{{ .Get "id" }}?{{ with .Get "autoplay" }}{{ if eq . "true" }}autoplay=1{{ end }}{{ end }}"
   {{ if not (.Get "class") }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}allowfullscreen frameborder="0" title="YouTube Video"></iframe>
 </div>{{ else }}
 <div {{ if len .Params | eq 2 }}class="{{ .Get 1 }}"{{ else }}style="position: relative; padding-bottom: 56.25%; padding-top: 30px; height: 0; overflow: hidden;"{{ end }}>
-  <iframe src="//www.youtube.com/embed/{{ .Get 0 }}" {{ if len .Params | eq 1 }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}allowfullscreen frameborder="0" title="YouTube Video"></iframe>
+  <iframe src="//{{ $host }}/embed/{{ .Get 0 }}" {{ if len .Params | eq 1 }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}allowfullscreen frameborder="0" title="YouTube Video"></iframe>
  </div>
-{{ end }}`)
-	t.addInternalShortcode("vimeo.html", `{{ if .IsNamedParams }}<div {{ if .Get "class" }}class="{{ .Get "class" }}"{{ else }}style="position: relative; padding-bottom: 56.25%; padding-top: 30px; height: 0; overflow: hidden;"{{ end }}>
-  <iframe src="//player.vimeo.com/video/{{ .Get "id" }}" {{ if not (.Get "class") }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}webkitallowfullscreen mozallowfullscreen allowfullscreen></iframe>
+{{ end }}{{- end -}}
+{{- end -}}`)
+	t.addInternalShortcode("vimeo.html", `{{- $pc := .Page.Site.Config.Privacy.Vimeo -}}
+{{- if not $pc.Disable -}}
+{{- if $pc.Simple -}}
+{{- $id := cond .IsNamedParams (.Get "id") (.Get 0) -}}
+{{- with getJSON "https://vimeo.com/api/oembed.json?url=https://vimeo.com/" $id (cond $pc.EnableDNT "&dnt=1" "") -}}
+<div class="vimeo"><a href="https://vimeo.com/{{ $id }}"><img src="{{ getRemoteCopy (printf "images/vimeo/%s.jpg" $id) .thumbnail_url }}" alt="{{ .title }}" width="{{ .thumbnail_width }}" height="{{ .thumbnail_height }}" /></a></div>
+{{- end -}}
+{{- else -}}
+{{ if .IsNamedParams }}<div {{ if .Get "class" }}class="{{ .Get "class" }}"{{ else }}style="position: relative; padding-bottom: 56.25%; padding-top: 30px; height: 0; overflow: hidden;"{{ end }}>
+  <iframe src="//player.vimeo.com/video/{{ .Get "id" }}{{ if $pc.EnableDNT }}?dnt=1{{ end }}" {{ if not (.Get "class") }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}webkitallowfullscreen mozallowfullscreen allowfullscreen></iframe>
  </div>{{ else }}
 <div {{ if len .Params | eq 2 }}class="{{ .Get 1 }}"{{ else }}style="position: relative; padding-bottom: 56.25%; padding-top: 30px; height: 0; overflow: hidden;"{{ end }}>
-  <iframe src="//player.vimeo.com/video/{{ .Get 0 }}" {{ if len .Params | eq 1 }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}webkitallowfullscreen mozallowfullscreen allowfullscreen></iframe>
+  <iframe src="//player.vimeo.com/video/{{ .Get 0 }}{{ if $pc.EnableDNT }}?dnt=1{{ end }}" {{ if len .Params | eq 1 }}style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;" {{ end }}webkitallowfullscreen mozallowfullscreen allowfullscreen></iframe>
  </div>
-{{ end }}`)
+{{ end }}{{- end -}}
+{{- end -}}`)
 	t.addInternalShortcode("gist.html", `<script src="//gist.github.com/{{ index .Params 0 }}/{{ index .Params 1 }}.js{{if len .Params | eq 3 }}?file={{ index .Params 2 }}{{end}}"></script>`)
-	t.addInternalShortcode("tweet.html", `{{ (getJSON "https://api.twitter.com/1/statuses/oembed.json?id=" (index .Params 0)).html | safeHTML }}`)
-	t.addInternalShortcode("instagram.html", `{{ if len .Params | eq 2 }}{{ if eq (.Get 1) "hidecaption" }}{{ with getJSON "https://api.instagram.com/oembed/?url=https://instagram.com/p/" (index .Params 0) "/&hidecaption=1" }}{{ .html | safeHTML }}{{ end }}{{ end }}{{ else }}{{ with getJSON "https://api.instagram.com/oembed/?url=https://instagram.com/p/" (index .Params 0) "/&hidecaption=0" }}{{ .html | safeHTML }}{{ end }}{{ end }}`)
+	t.addInternalShortcode("tweet.html", `{{- $pc := .Page.Site.Config.Privacy.Twitter -}}
+{{- if not $pc.Disable -}}
+{{ (getJSON "https://api.twitter.com/1/statuses/oembed.json?id=" (index .Params 0) (cond $pc.EnableDNT "&dnt=true" "") (cond $pc.Simple "&omit_script=true" "")).html | safeHTML }}
+{{- end -}}`)
+	t.addInternalShortcode("instagram.html", `{{- $pc := .Page.Site.Config.Privacy.Instagram -}}
+{{- if not $pc.Disable -}}
+{{- $omit := cond $pc.Simple "&omitscript=true" "" -}}
+{{ if len .Params | eq 2 }}{{ if eq (.Get 1) "hidecaption" }}{{ with getJSON "https://api.instagram.com/oembed/?url=https://instagram.com/p/" (index .Params 0) "/&hidecaption=1" $omit }}{{ .html | safeHTML }}{{ end }}{{ end }}{{ else }}{{ with getJSON "https://api.instagram.com/oembed/?url=https://instagram.com/p/" (index .Params 0) "/&hidecaption=0" $omit }}{{ .html | safeHTML }}{{ end }}{{ end }}
+{{- end -}}`)
+	t.addInternalShortcode("embed.html", `{{- $url := cond .IsNamedParams (.Get "url") (.Get 0) -}}
+{{- with getOEmbed $url -}}
+{{- $type := .type | default "link" -}}
+<div class="embed embed-{{ $type }}">
+{{- if eq $type "photo" -}}
+<a href="{{ $url }}"><img src="{{ .url }}" alt="{{ .title | default $url }}"{{ with .width }} width="{{ . }}"{{ end }}{{ with .height }} height="{{ . }}"{{ end }} /></a>
+{{- else if .html -}}
+{{ .html | safeHTML }}
+{{- else -}}
+<a href="{{ $url }}">{{ .title | default $url }}</a>
+{{- end -}}
+</div>
+{{- else -}}
+<p class="embed embed-fallback"><a href="{{ $url }}">{{ $url }}</a></p>
+{{- end -}}`)
 }
 
-func (t *templateHandler) embedTemplates() {
+func embedTemplates(t internalTemplateAdder) {
 
 	t.addInternalTemplate("_default", "rss.xml", `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
@@ -79,7 +148,7 @@ func (t *templateHandler) embedTemplates() {
       <title>{{ .Title }}</title>
       <link>{{ .Permalink }}</link>
       <pubDate>{{ .Date.Format "Mon, 02 Jan 2006 15:04:05 -0700" | safeHTML }}</pubDate>
-      {{ with .Site.Author.email }}<author>{{.}}{{ with $.Site.Author.name }} ({{.}}){{end}}</author>{{end}}
+      {{ if .Author.Email }}<author>{{ .Author.Email }} ({{ .Author.Name }})</author>{{ else }}{{ with .Site.Author.email }}<author>{{.}}{{ with $.Site.Author.name }} ({{.}}){{end}}</author>{{end}}{{ end }}
       <guid>{{ .Permalink }}</guid>
       <description>{{ .Summary | html }}</description>
     </item>
@@ -88,7 +157,10 @@ func (t *templateHandler) embedTemplates() {
 </rss>`)
 
 	t.addInternalTemplate("_default", "sitemap.xml", `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
-  xmlns:xhtml="http://www.w3.org/1999/xhtml">
+  xmlns:xhtml="http://www.w3.org/1999/xhtml"
+  xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"
+  xmlns:video="http://www.google.com/schemas/sitemap-video/1.1"
+  xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
   {{ range .Data.Pages }}
   <url>
     <loc>{{ .Permalink }}</loc>{{ if not .Lastmod.IsZero }}
@@ -104,7 +176,26 @@ func (t *templateHandler) embedTemplates() {
                 rel="alternate"
                 hreflang="{{ .Lang }}"
                 href="{{ .Permalink }}"
-                />{{ end }}
+                />{{ end }}{{ if .Sitemap.HasExtension "image" }}{{ range .SitemapImages }}
+    <image:image>
+      <image:loc>{{ . }}</image:loc>
+    </image:image>{{ end }}{{ end }}{{ if .Sitemap.HasExtension "video" }}{{ range .SitemapVideos }}
+    <video:video>
+      <video:thumbnail_loc>{{ .ThumbnailLoc }}</video:thumbnail_loc>
+      <video:title>{{ .Title }}</video:title>
+      <video:description>{{ .Description }}</video:description>{{ with .ContentLoc }}
+      <video:content_loc>{{ . }}</video:content_loc>{{ end }}{{ with .PlayerLoc }}
+      <video:player_loc>{{ . }}</video:player_loc>{{ end }}{{ if gt .Duration 0 }}
+      <video:duration>{{ .Duration }}</video:duration>{{ end }}
+    </video:video>{{ end }}{{ end }}{{ if and .IsPage (.Sitemap.HasExtension "news") }}
+    <news:news>
+      <news:publication>
+        <news:name>{{ .Sitemap.NewsName }}</news:name>
+        <news:language>{{ .Lang }}</news:language>
+      </news:publication>
+      <news:publication_date>{{ safeHTML ( .PublishDate.Format "2006-01-02T15:04:05-07:00" ) }}</news:publication_date>
+      <news:title>{{ .Title }}</news:title>
+    </news:news>{{ end }}
   </url>
   {{ end }}
 </urlset>`)
@@ -120,6 +211,12 @@ func (t *templateHandler) embedTemplates() {
 	</sitemap>
 	{{ end }}
 </sitemapindex>
+`)
+
+	t.addInternalTemplate("", "pagination_head.html", `{{ $pag := $.Paginator }}{{ with $pag.Prev }}<link rel="prev" href="{{ .Permalink }}">
+{{ end }}{{ with $pag.Next }}<link rel="next" href="{{ .Permalink }}">
+{{ end }}<link rel="canonical" href="{{ $pag.Canonical }}">{{ if $pag.NoIndex }}
+<meta name="robots" content="noindex, follow">{{ end }}
 `)
 
 	t.addInternalTemplate("", "pagination.html", `{{ $pag := $.Paginator }}
@@ -211,22 +308,20 @@ func (t *templateHandler) embedTemplates() {
 
 <!-- If it is part of a series, link to related articles -->
 {{ $permalink := .Permalink }}
-{{ $siteSeries := .Site.Taxonomies.series }}{{ with .Params.series }}
-{{ range $name := . }}
-  {{ $series := index $siteSeries $name }}
-  {{ range $page := first 6 $series.Pages }}
+{{ range .AllSeries }}
+  {{ range $page := first 6 .Pages }}
     {{ if ne $page.Permalink $permalink }}<meta property="og:see_also" content="{{ $page.Permalink }}" />{{ end }}
   {{ end }}
-{{ end }}{{ end }}
+{{ end }}
 
 {{ if .IsPage }}
-{{ range .Site.Authors }}{{ with .Social.facebook }}
-<meta property="article:author" content="https://www.facebook.com/{{ . }}" />{{ end }}{{ with .Site.Social.facebook }}
+{{ range .AuthorsSorted }}{{ with .Social.facebook }}
+<meta property="article:author" content="https://www.facebook.com/{{ . }}" />{{ end }}{{ end }}{{ with .Site.Social.facebook }}
 <meta property="article:publisher" content="https://www.facebook.com/{{ . }}" />{{ end }}
 <meta property="article:section" content="{{ .Section }}" />
 {{ with .Params.tags }}{{ range first 6 . }}
   <meta property="article:tag" content="{{ . }}" />{{ end }}{{ end }}
-{{ end }}{{ end }}
+{{ end }}
 
 <!-- Facebook Page Admin ID for Domain Insights -->
 {{ with .Site.Social.facebook_admin }}<meta property="fb:admins" content="{{ . }}" />{{ end }}`)
@@ -242,8 +337,8 @@ func (t *templateHandler) embedTemplates() {
 {{ with .Site.Social.twitter -}}
 <meta name="twitter:site" content="@{{ . }}"/>
 {{ end -}}
-{{ range .Site.Authors }}
-{{ with .twitter -}}
+{{ range .AuthorsSorted }}
+{{ with .Social.twitter -}}
 <meta name="twitter:creator" content="@{{ . }}"/>
 {{ end -}}
 {{ end -}}`)
@@ -289,5 +384,75 @@ ga('send', 'pageview');
 <script async src='//www.google-analytics.com/analytics.js'></script>
 {{ end }}`)
 
+	t.addInternalTemplate("_default", "print.html", `<!DOCTYPE html>
+<html lang="{{ .Site.LanguageCode }}">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}{{ with .Site.Title }} | {{ . }}{{ end }}</title>
+<style>
+.print-page { page-break-before: always; }
+.print-page:first-of-type { page-break-before: avoid; }
+</style>
+</head>
+<body>
+{{ .PrintTableOfContents }}
+{{ range .PrintPages }}
+<article id="{{ .Anchor }}" class="print-page print-depth-{{ .Depth }}">
+<h1>{{ .Title }}</h1>
+{{ .Content }}
+</article>
+{{ end }}
+</body>
+</html>`)
+
+	t.addInternalTemplate("_default", "podcast.xml", `{{- $podcast := .Podcast -}}
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:googleplay="http://www.google.com/schemas/play-podcasts/1.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    {{- with $podcast.Channel }}
+    <title>{{ .Title }}</title>
+    <link>{{ $.Permalink }}</link>
+    <description>{{ .Description }}</description>
+    <generator>Hugo -- gohugo.io</generator>
+    <language>{{ .Language }}</language>{{ with .Copyright }}
+    <copyright>{{ . }}</copyright>{{ end }}{{ with .Author }}
+    <itunes:author>{{ . }}</itunes:author>
+    <googleplay:author>{{ . }}</googleplay:author>{{ end }}
+    <itunes:summary>{{ .Description }}</itunes:summary>
+    <itunes:type>{{ .Type }}</itunes:type>{{ if or .OwnerName .OwnerEmail }}
+    <itunes:owner>{{ with .OwnerName }}
+      <itunes:name>{{ . }}</itunes:name>{{ end }}{{ with .OwnerEmail }}
+      <itunes:email>{{ . }}</itunes:email>{{ end }}
+    </itunes:owner>{{ end }}{{ with .Image }}
+    <itunes:image href="{{ . }}" />
+    <googleplay:image href="{{ . }}" />{{ end }}{{ with .Category }}
+    <itunes:category text="{{ . }}">{{ with $podcast.Channel.Subcategory }}
+      <itunes:category text="{{ . }}" />{{ end }}
+    </itunes:category>{{ end }}
+    <itunes:explicit>{{ .Explicit }}</itunes:explicit>
+    {{- end }}{{ if not .Date.IsZero }}
+    <lastBuildDate>{{ .Date.Format "Mon, 02 Jan 2006 15:04:05 -0700" | safeHTML }}</lastBuildDate>{{ end }}
+    {{ with .OutputFormats.Get "Podcast" }}
+	{{ printf "<atom:link href=%q rel=\"self\" type=%q />" .Permalink .MediaType | safeHTML }}
+    {{ end }}
+    {{- range $podcast.Episodes }}
+    <item>
+      <title>{{ .Page.Title }}</title>
+      <link>{{ .Page.Permalink }}</link>
+      <pubDate>{{ .Page.Date.Format "Mon, 02 Jan 2006 15:04:05 -0700" | safeHTML }}</pubDate>
+      <guid>{{ .Page.Permalink }}</guid>
+      <description>{{ .Page.Summary | html }}</description>
+      <enclosure url="{{ .Audio.Permalink }}" length="{{ .Audio.Size }}" type="{{ .Audio.MediaType }}" />
+      <itunes:duration>{{ .FormattedDuration }}</itunes:duration>{{ with .Episode }}
+      <itunes:episode>{{ . }}</itunes:episode>{{ end }}{{ with .Season }}
+      <itunes:season>{{ . }}</itunes:season>{{ end }}
+      <itunes:episodeType>{{ .EpisodeType }}</itunes:episodeType>
+      <itunes:explicit>{{ .Explicit }}</itunes:explicit>
+    </item>
+    {{- end }}
+  </channel>
+</rss>`)
+
+	t.addInternalTemplate("_text/_default", "sectiondata.json", `{{ .SectionData | jsonify }}`)
+
 	t.addInternalTemplate("_default", "robots.txt", "User-agent: *")
 }
@@ -0,0 +1,164 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/spf13/afero"
+)
+
+const internalPathPrefix = "_internal/"
+
+// internalTemplateAdder adds the embedded templates, see embedShortcodes and
+// embedTemplates.
+type internalTemplateAdder interface {
+	addInternalTemplate(prefix, name, tpl string) error
+	addInternalShortcode(name, content string) error
+}
+
+// EmbeddedTemplate is one of the templates embedded in Hugo.
+type EmbeddedTemplate struct {
+	// The template name, e.g. _internal/_default/rss.xml.
+	Name string
+
+	Content string
+}
+
+// Path returns the path relative to the layouts dir to extract the template
+// to so it overrides the embedded template, e.g. _default/rss.xml. The
+// embedded partials, e.g. _internal/disqus.html, cannot be overridden and
+// are extracted to the partials dir, to be used with the partial func.
+func (e EmbeddedTemplate) Path() string {
+	name := strings.TrimPrefix(e.Name, internalPathPrefix)
+	if !strings.Contains(name, "/") {
+		return "partials/" + name
+	}
+	return name
+}
+
+// Hash returns a short hash of the template content, used to detect if a
+// template extracted from an older Hugo version differs from the current.
+func (e EmbeddedTemplate) Hash() string {
+	return helpers.MD5String(e.Content)[:12]
+}
+
+// Pinned returns the template content with a template comment recording the
+// embedded version it was extracted from. The comment renders as nothing.
+func (e EmbeddedTemplate) Pinned() string {
+	return fmt.Sprintf("%s{{/* hugo-embedded: %s %s */}}\n", e.Content, e.Name, e.Hash())
+}
+
+var embeddedPinRe = regexp.MustCompile(`{{/\* hugo-embedded: (\S+) (\S+) \*/}}`)
+
+// ParseEmbeddedPin returns the name and hash of the embedded template the
+// given template content was extracted from, if any.
+func ParseEmbeddedPin(content []byte) (name, hash string, found bool) {
+	m := embeddedPinRe.FindSubmatch(content)
+	if m == nil {
+		return "", "", false
+	}
+	return string(m[1]), string(m[2]), true
+}
+
+type embeddedTemplateCollector map[string]string
+
+func (c embeddedTemplateCollector) addInternalTemplate(prefix, name, tpl string) error {
+	prefix = strings.TrimPrefix(prefix, textTmplNamePrefix)
+	if prefix != "" {
+		name = prefix + "/" + name
+	}
+	c[internalPathPrefix+name] = tpl
+	return nil
+}
+
+func (c embeddedTemplateCollector) addInternalShortcode(name, content string) error {
+	return c.addInternalTemplate("shortcodes", name, content)
+}
+
+var (
+	embeddedTemplatesInit sync.Once
+	embeddedTemplates     map[string]EmbeddedTemplate
+)
+
+func loadEmbeddedTemplates() map[string]EmbeddedTemplate {
+	embeddedTemplatesInit.Do(func() {
+		c := make(embeddedTemplateCollector)
+		embedShortcodes(c)
+		embedTemplates(c)
+
+		embeddedTemplates = make(map[string]EmbeddedTemplate)
+		for name, content := range c {
+			embeddedTemplates[name] = EmbeddedTemplate{Name: name, Content: content}
+		}
+	})
+	return embeddedTemplates
+}
+
+// EmbeddedTemplates returns the templates embedded in Hugo, sorted by name.
+func EmbeddedTemplates() []EmbeddedTemplate {
+	m := loadEmbeddedTemplates()
+	templates := make([]EmbeddedTemplate, 0, len(m))
+	for _, e := range m {
+		templates = append(templates, e)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// GetEmbeddedTemplate returns the embedded template with the given name or
+// path, e.g. _internal/_default/rss.xml or _default/rss.xml.
+func GetEmbeddedTemplate(name string) (EmbeddedTemplate, bool) {
+	m := loadEmbeddedTemplates()
+	if e, found := m[name]; found {
+		return e, true
+	}
+	for _, e := range m {
+		if e.Path() == name || e.Name == internalPathPrefix+name {
+			return e, true
+		}
+	}
+	return EmbeddedTemplate{}, false
+}
+
+// checkEmbeddedPin warns if the template file was extracted from an
+// embedded template that has changed since.
+func (t *templateHandler) checkEmbeddedPin(filename string) {
+	b, err := afero.ReadFile(t.Fs.Source, filename)
+	if err != nil {
+		return
+	}
+
+	name, hash, found := ParseEmbeddedPin(b)
+	if !found {
+		return
+	}
+
+	e, found := loadEmbeddedTemplates()[name]
+	if !found {
+		t.Log.WARN.Printf("Template %q was extracted from the embedded template %q, which no longer exists.", filename, name)
+		return
+	}
+
+	if e.Hash() != hash {
+		t.Log.WARN.Printf("Template %q was extracted from an older version of the embedded template %q. Run \"hugo gen templates --diff %s\" to see the changes.", filename, name, e.Path())
+	}
+}
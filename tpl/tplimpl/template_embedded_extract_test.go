@@ -0,0 +1,63 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedTemplates(t *testing.T) {
+	t.Parallel()
+	assert := require.New(t)
+
+	templates := EmbeddedTemplates()
+	assert.True(len(templates) > 10)
+
+	paths := make(map[string]string)
+	for i, e := range templates {
+		if i > 0 {
+			assert.True(templates[i-1].Name < e.Name)
+		}
+		paths[e.Name] = e.Path()
+	}
+
+	assert.Equal("_default/rss.xml", paths["_internal/_default/rss.xml"])
+	assert.Equal("shortcodes/figure.html", paths["_internal/shortcodes/figure.html"])
+	assert.Equal("partials/disqus.html", paths["_internal/disqus.html"])
+
+	for _, name := range []string{"_internal/_default/rss.xml", "_default/rss.xml"} {
+		e, found := GetEmbeddedTemplate(name)
+		assert.True(found, name)
+		assert.Equal("_internal/_default/rss.xml", e.Name)
+	}
+	e, found := GetEmbeddedTemplate("disqus.html")
+	assert.True(found)
+	assert.Equal("_internal/disqus.html", e.Name)
+	_, found = GetEmbeddedTemplate("_default/nope.html")
+	assert.False(found)
+
+	e, _ = GetEmbeddedTemplate("_default/robots.txt")
+	pinned := e.Pinned()
+	assert.Equal("User-agent: *{{/* hugo-embedded: _internal/_default/robots.txt "+e.Hash()+" */}}\n", pinned)
+
+	name, hash, found := ParseEmbeddedPin([]byte(pinned))
+	assert.True(found)
+	assert.Equal(e.Name, name)
+	assert.Equal(e.Hash(), hash)
+
+	_, _, found = ParseEmbeddedPin([]byte("User-agent: *"))
+	assert.False(found)
+}
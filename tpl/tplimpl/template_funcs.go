@@ -16,8 +16,14 @@
 package tplimpl
 
 import (
+	"fmt"
 	"html/template"
+	"reflect"
+	"regexp"
+	"sort"
 
+	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/plugins"
 	"github.com/gohugoio/hugo/tpl/internal"
 
 	// Init the namespaces
@@ -34,7 +40,9 @@ import (
 	_ "github.com/gohugoio/hugo/tpl/math"
 	_ "github.com/gohugoio/hugo/tpl/os"
 	_ "github.com/gohugoio/hugo/tpl/partials"
+	_ "github.com/gohugoio/hugo/tpl/resources"
 	_ "github.com/gohugoio/hugo/tpl/safe"
+	_ "github.com/gohugoio/hugo/tpl/schema"
 	_ "github.com/gohugoio/hugo/tpl/strings"
 	_ "github.com/gohugoio/hugo/tpl/time"
 	_ "github.com/gohugoio/hugo/tpl/transform"
@@ -62,6 +70,79 @@ func (t *templateFuncster) initFuncMap() {
 		}
 	}
 
+	// The funcs of the plugins declared in the site config.
+	ps, err := plugins.Load(t.Cfg)
+	if err != nil {
+		t.Log.ERROR.Println(err)
+	}
+	for _, p := range ps {
+		for name, f := range p.Funcs() {
+			if _, exists := funcMap[name]; exists || builtinTemplateFuncs[name] {
+				t.Log.ERROR.Printf("plugin %q: %s is a duplicate template func", p.Name(), name)
+				continue
+			}
+			if err := validatePluginFunc(name, f); err != nil {
+				t.Log.ERROR.Printf("plugin %q: %s", p.Name(), err)
+				continue
+			}
+			funcMap[name] = f
+		}
+	}
+
 	t.funcMap = funcMap
 	t.Tmpl.(*templateHandler).setFuncs(funcMap)
 }
+
+// TemplateFuncs returns info about the funcs in the registered template func
+// namespaces, sorted by namespace and name. The funcs of the plugins are
+// not included.
+func TemplateFuncs(d *deps.Deps) []internal.TemplateFuncInfo {
+	var namespaces []*internal.TemplateFuncsNamespace
+	for _, nsf := range internal.TemplateFuncsNamespaceRegistry {
+		namespaces = append(namespaces, nsf(d))
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	var funcs []internal.TemplateFuncInfo
+	for _, ns := range namespaces {
+		funcs = append(funcs, ns.Funcs()...)
+	}
+
+	return funcs
+}
+
+// The functions built into Go templates.
+var builtinTemplateFuncs = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "js": true, "len": true, "not": true, "or": true,
+	"print": true, "printf": true, "println": true, "urlquery": true,
+	"eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+var templateFuncNameRe = regexp.MustCompile(`^[\pL_][\pL\p{Nd}_]*$`)
+
+// validatePluginFunc returns an error if f can not be used as a template
+// func with the given name.
+func validatePluginFunc(name string, f interface{}) error {
+	if !templateFuncNameRe.MatchString(name) {
+		return fmt.Errorf("%q is not a valid template func name", name)
+	}
+
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		return fmt.Errorf("%s is a %T, not a func", name, f)
+	}
+
+	switch v.Type().NumOut() {
+	case 1:
+	case 2:
+		if v.Type().Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+			return fmt.Errorf("the second return value of %s must be an error", name)
+		}
+	default:
+		return fmt.Errorf("%s must return one value, or a value and an error", name)
+	}
+
+	return nil
+}
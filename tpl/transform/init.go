@@ -81,6 +81,13 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.RenderMarkup,
+			[]string{"renderMarkup"},
+			[][2]string{
+				{`{{ "* Hello" | renderMarkup "org" }}`, "<h1 id=\"hello\">Hello</h1>\n"},
+			},
+		)
+
 		ns.AddMethodMapping(ctx.Plainify,
 			[]string{"plainify"},
 			[][2]string{
@@ -15,6 +15,7 @@ package transform
 
 import (
 	"bytes"
+	"fmt"
 	"html"
 	"html/template"
 
@@ -113,6 +114,36 @@ func (ns *Namespace) Markdownify(s interface{}) (template.HTML, error) {
 	return template.HTML(m), nil
 }
 
+// RenderMarkup renders the given input from the given markup format, e.g.
+// "asciidoc", "rst", "org" or "markdown", to HTML.
+func (ns *Namespace) RenderMarkup(format string, s interface{}) (template.HTML, error) {
+	ss, err := cast.ToStringE(s)
+	if err != nil {
+		return "", err
+	}
+
+	pageFmt := helpers.GuessType(format)
+	if pageFmt == "unknown" {
+		return "", fmt.Errorf("unknown markup format %q", format)
+	}
+
+	if pageFmt == "html" {
+		return template.HTML(ss), nil
+	}
+
+	m := ns.deps.ContentSpec.RenderBytes(
+		&helpers.RenderingContext{
+			Cfg:          ns.deps.Cfg,
+			Content:      []byte(ss),
+			PageFmt:      pageFmt,
+			DocumentName: "renderMarkup",
+			Config:       ns.deps.ContentSpec.BlackFriday,
+		},
+	)
+
+	return template.HTML(m), nil
+}
+
 // Plainify returns a copy of s with all HTML tags removed.
 func (ns *Namespace) Plainify(s interface{}) (string, error) {
 	ss, err := cast.ToStringE(s)
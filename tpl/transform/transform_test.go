@@ -170,6 +170,36 @@ func TestMarkdownify(t *testing.T) {
 	}
 }
 
+func TestRenderMarkup(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(viper.New()))
+
+	for i, test := range []struct {
+		format string
+		s      interface{}
+		expect interface{}
+	}{
+		{"md", "Hello **World!**", template.HTML("<p>Hello <strong>World!</strong></p>\n")},
+		{"org", "Hello *World!*", template.HTML("<p>Hello <strong>World!</strong></p>\n")},
+		{"html", "<b>Hello</b>", template.HTML("<b>Hello</b>")},
+		{"docx", "Hello", false},
+		{"md", tstNoStringer{}, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %s", i, test.s)
+
+		result, err := ns.RenderMarkup(test.format, test.s)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
+
 // Issue #3040
 func TestMarkdownifyBlocksOfText(t *testing.T) {
 	t.Parallel()
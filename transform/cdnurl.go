@@ -0,0 +1,102 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"strings"
+)
+
+// CDNURL returns a function that rewrites the links to the site's assets in
+// a HTML document to the given CDN URL, e.g. "/images/logo.png" to
+// "https://cdn.example.com/images/logo.png". Root relative links and absolute
+// links to the site, i.e. starting with the given base URL, are rewritten if
+// match returns true for the link's path relative to the site root, e.g.
+// "/images/logo.png". This includes srcset and url(...) in inline styles.
+func CDNURL(baseURL, cdnURL string, match func(path string) bool) func(ct contentTransformer) {
+	o := newOfflineURLs(baseURL)
+	cdnURL = strings.TrimSuffix(cdnURL, "/")
+
+	rewrite := func(u string) string {
+		if u == "" || u[0] == '#' {
+			return u
+		}
+
+		var rel string
+
+		switch {
+		case strings.HasPrefix(u, "//") || strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://"):
+			i := strings.Index(u, "//")
+			if o.host == "" || !strings.HasPrefix(u[i+2:], o.host+"/") {
+				return u
+			}
+			var ok bool
+			if rel, ok = o.trimBasePath(u[i+2+len(o.host):]); !ok {
+				return u
+			}
+		case u[0] == '/':
+			var ok bool
+			if rel, ok = o.trimBasePath(u); !ok {
+				return u
+			}
+		default:
+			// Relative links and other schemes.
+			return u
+		}
+
+		p := rel
+		if i := strings.IndexAny(p, "?#"); i != -1 {
+			p = p[:i]
+		}
+
+		if !match("/" + p) {
+			return u
+		}
+
+		return cdnURL + "/" + rel
+	}
+
+	return func(ct contentTransformer) {
+		content := offlineAttrRe.ReplaceAllFunc(ct.Content(), func(m []byte) []byte {
+			sm := offlineAttrRe.FindSubmatch(m)
+			quote, value := `"`, string(sm[2])
+			if sm[3] != nil {
+				quote, value = "'", string(sm[3])
+			}
+
+			if strings.HasSuffix(strings.ToLower(strings.TrimRight(string(sm[1]), " =\t\n")), "srcset") {
+				candidates := strings.Split(value, ",")
+				for i, c := range candidates {
+					fields := strings.Fields(c)
+					if len(fields) == 0 {
+						continue
+					}
+					fields[0] = rewrite(fields[0])
+					candidates[i] = strings.Join(fields, " ")
+				}
+				value = strings.Join(candidates, ", ")
+			} else {
+				value = rewrite(value)
+			}
+
+			return []byte(string(sm[1]) + quote + value + quote)
+		})
+
+		content = offlineCSSURLRe.ReplaceAllFunc(content, func(m []byte) []byte {
+			sm := offlineCSSURLRe.FindSubmatch(m)
+			return []byte("url(" + string(sm[1]) + rewrite(string(sm[2])) + string(sm[3]) + ")")
+		})
+
+		ct.Write(content)
+	}
+}
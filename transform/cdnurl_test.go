@@ -0,0 +1,48 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCDNURL(t *testing.T) {
+	assert := require.New(t)
+
+	images := func(p string) bool {
+		return strings.HasSuffix(p, ".png") || strings.HasSuffix(p, ".jpg")
+	}
+
+	for i, test := range []struct {
+		baseURL string
+		in      string
+		expect  string
+	}{
+		{"http://example.com/", `<img src="/img/logo.png"> <a href="/posts/">Posts</a>`, `<img src="https://cdn.com/img/logo.png"> <a href="/posts/">Posts</a>`},
+		{"http://example.com/", `<img src='http://example.com/a.jpg?v=1'>`, `<img src='https://cdn.com/a.jpg?v=1'>`},
+		{"http://example.com/blog/", `<img src="/blog/a.jpg"> <img src="/other/b.jpg">`, `<img src="https://cdn.com/a.jpg"> <img src="/other/b.jpg">`},
+		{"http://example.com/", `<img src="a.jpg"> <img src="https://other.com/a.jpg"> <a href="mailto:a@b.png">M</a>`, `<img src="a.jpg"> <img src="https://other.com/a.jpg"> <a href="mailto:a@b.png">M</a>`},
+		{"http://example.com/", `<img srcset="/img/small.jpg 200w, /img/big.jpg 700w">`, `<img srcset="https://cdn.com/img/small.jpg 200w, https://cdn.com/img/big.jpg 700w">`},
+		{"http://example.com/", `<div style="background: url('/img/bg.png')"></div>`, `<div style="background: url('https://cdn.com/img/bg.png')"></div>`},
+	} {
+		var b bytes.Buffer
+		tr := NewChain(CDNURL(test.baseURL, "https://cdn.com/", images))
+		assert.NoError(tr.Apply(&b, bytes.NewBufferString(test.in), nil))
+		assert.Equal(test.expect, b.String(), "[%d]", i)
+	}
+}
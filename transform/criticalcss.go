@@ -0,0 +1,445 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultCriticalElements is the number of elements in the body, in document
+// order, considered above the fold if not set on the stylesheet link.
+const DefaultCriticalElements = 100
+
+var (
+	criticalLinkRe = regexp.MustCompile(`(?is)<link\s[^>]*\bdata-critical\b[^>]*>`)
+	linkAttrRe     = regexp.MustCompile(`(?is)\b([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// CriticalCSS returns a function that inlines the critical CSS of the
+// stylesheets linked with a data-critical attribute, e.g.
+//
+//   <link rel="stylesheet" href="/css/main.css" data-critical=".hero, .nav" data-critical-elements="50">
+//
+// and defers loading the full stylesheet. The critical CSS are the rules
+// with selectors matching the elements above the fold, i.e. the first
+// data-critical-elements elements in the body, and the rules for the
+// selectors listed in data-critical. The relative URLs in the critical CSS
+// are rebased on the stylesheet link. The stylesheet is read with load,
+// given the path relative to the site root, e.g. "/css/main.css", of links
+// to the site. Links that cannot be loaded are left as is.
+func CriticalCSS(baseURL string, load func(path string) ([]byte, error)) func(ct contentTransformer) {
+	o := newOfflineURLs(baseURL)
+
+	resolve := func(href string) (string, bool) {
+		switch {
+		case strings.HasPrefix(href, "//") || strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://"):
+			i := strings.Index(href, "//")
+			if o.host == "" || !strings.HasPrefix(href[i+2:], o.host+"/") {
+				return "", false
+			}
+			href = href[i+2+len(o.host):]
+		case !strings.HasPrefix(href, "/"):
+			return "", false
+		}
+
+		rel, ok := o.trimBasePath(href)
+		if !ok {
+			return "", false
+		}
+		if i := strings.IndexAny(rel, "?#"); i != -1 {
+			rel = rel[:i]
+		}
+		return "/" + rel, true
+	}
+
+	return func(ct contentTransformer) {
+		content := ct.Content()
+
+		if !bytes.Contains(content, []byte("data-critical")) {
+			ct.Write(content)
+			return
+		}
+
+		var used *usedSelectors
+
+		content = criticalLinkRe.ReplaceAllFunc(content, func(m []byte) []byte {
+			attrs := make(map[string]string)
+			for _, am := range linkAttrRe.FindAllSubmatch(m, -1) {
+				v := am[2]
+				if v == nil {
+					v = am[3]
+				}
+				attrs[strings.ToLower(string(am[1]))] = html.UnescapeString(string(v))
+			}
+
+			href := attrs["href"]
+			p, ok := resolve(href)
+			if !ok {
+				return m
+			}
+
+			css, err := load(p)
+			if err != nil {
+				return m
+			}
+
+			elements := DefaultCriticalElements
+			if v, err := strconv.Atoi(attrs["data-critical-elements"]); err == nil && v >= 0 {
+				elements = v
+			}
+
+			if used == nil || used.elements != elements {
+				used = collectUsedSelectors(ct.Content(), elements)
+			}
+
+			var allow []string
+			for _, s := range strings.Split(attrs["data-critical"], ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					allow = append(allow, s)
+				}
+			}
+
+			critical := rebaseCSSURLs(extractCriticalCSS(css, used, allow), href)
+
+			var b bytes.Buffer
+			if critical != "" {
+				b.WriteString("<style>")
+				b.WriteString(critical)
+				b.WriteString("</style>")
+			}
+			escaped := html.EscapeString(href)
+			b.WriteString(`<link rel="preload" href="` + escaped + `" as="style" onload="this.onload=null;this.rel='stylesheet'">`)
+			b.WriteString(`<noscript><link rel="stylesheet" href="` + escaped + `"></noscript>`)
+
+			return b.Bytes()
+		})
+
+		ct.Write(content)
+	}
+}
+
+// usedSelectors holds the tags, classes and ids of the elements above the
+// fold in a HTML document.
+type usedSelectors struct {
+	elements int
+	tags     map[string]bool
+	classes  map[string]bool
+	ids      map[string]bool
+}
+
+// collectUsedSelectors collects the tags, classes and ids of the html and
+// body elements and the first n elements in the body of the document.
+func collectUsedSelectors(doc []byte, n int) *usedSelectors {
+	used := &usedSelectors{
+		elements: n,
+		tags:     map[string]bool{"html": true, "body": true},
+		classes:  make(map[string]bool),
+		ids:      make(map[string]bool),
+	}
+
+	var (
+		z      = html.NewTokenizer(bytes.NewReader(doc))
+		inBody bool
+		count  int
+	)
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return used
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		tag := string(name)
+
+		if tag != "html" && tag != "body" {
+			if !inBody {
+				continue
+			}
+			if count >= n {
+				return used
+			}
+			count++
+			used.tags[tag] = true
+		}
+
+		if tag == "body" {
+			inBody = true
+		}
+
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			switch string(key) {
+			case "class":
+				for _, c := range strings.Fields(string(val)) {
+					used.classes[c] = true
+				}
+			case "id":
+				used.ids[string(val)] = true
+			}
+		}
+	}
+}
+
+// cssRule is a qualified rule, e.g. "a { color: red }", or an at-rule, e.g.
+// "@media print { ... }" or "@import url(a.css);", in a stylesheet.
+type cssRule struct {
+	prelude string
+	body    string
+	block   bool
+}
+
+// parseCSSRules splits the top level rules of the stylesheet, which must not
+// contain comments.
+func parseCSSRules(css string) []cssRule {
+	var (
+		rules []cssRule
+		start int
+		depth int
+		open  int
+	)
+
+	for i := 0; i < len(css); i++ {
+		c := css[i]
+		switch c {
+		case '"', '\'':
+			for i++; i < len(css) && css[i] != c; i++ {
+				if css[i] == '\\' {
+					i++
+				}
+			}
+		case '{':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				start = i + 1
+				continue
+			}
+			depth--
+			if depth == 0 {
+				rules = append(rules, cssRule{
+					prelude: strings.TrimSpace(css[start:open]),
+					body:    css[open+1 : i],
+					block:   true,
+				})
+				start = i + 1
+			}
+		case ';':
+			if depth == 0 {
+				if prelude := strings.TrimSpace(css[start:i]); prelude != "" {
+					rules = append(rules, cssRule{prelude: prelude})
+				}
+				start = i + 1
+			}
+		}
+	}
+
+	return rules
+}
+
+var cssCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+func stripCSSComments(s string) string {
+	return cssCommentRe.ReplaceAllString(s, "")
+}
+
+// extractCriticalCSS returns the rules of css with selectors matching the
+// used selectors or listed in allow. @import, @charset, @font-face and
+// @keyframes rules are kept, @media and @supports blocks are kept with their
+// critical rules.
+func extractCriticalCSS(css []byte, used *usedSelectors, allow []string) string {
+	var b bytes.Buffer
+	writeCriticalRules(&b, parseCSSRules(stripCSSComments(string(css))), used, allow)
+	return b.String()
+}
+
+func writeCriticalRules(b *bytes.Buffer, rules []cssRule, used *usedSelectors, allow []string) {
+	for _, r := range rules {
+		if strings.HasPrefix(r.prelude, "@") {
+			name := strings.ToLower(r.prelude)
+			if i := strings.IndexAny(name, " \t\n\r({"); i != -1 {
+				name = name[:i]
+			}
+
+			switch name {
+			case "@import", "@charset", "@namespace":
+				if !r.block {
+					b.WriteString(r.prelude + ";")
+				}
+			case "@font-face", "@keyframes", "@-webkit-keyframes", "@-moz-keyframes", "@-o-keyframes":
+				if r.block {
+					b.WriteString(r.prelude + "{" + compactCSS(r.body) + "}")
+				}
+			case "@media", "@supports", "@document":
+				var inner bytes.Buffer
+				writeCriticalRules(&inner, parseCSSRules(r.body), used, allow)
+				if inner.Len() > 0 {
+					b.WriteString(r.prelude + "{")
+					b.Write(inner.Bytes())
+					b.WriteString("}")
+				}
+			}
+			continue
+		}
+
+		if !r.block {
+			continue
+		}
+
+		var selectors []string
+		for _, sel := range splitCSSSelectors(r.prelude) {
+			if isCriticalSelector(sel, used, allow) {
+				selectors = append(selectors, sel)
+			}
+		}
+
+		if len(selectors) > 0 {
+			b.WriteString(strings.Join(selectors, ",") + "{" + compactCSS(r.body) + "}")
+		}
+	}
+}
+
+var cssURLRe = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)|@import\s*(?:"([^"]*)"|'([^']*)')`)
+
+// rebaseCSSURLs rewrites the relative URLs in css, which are relative to the
+// stylesheet at href, to work in the page the CSS is inlined in, e.g.
+// url(../img/a.png) in /css/main.css to url("/img/a.png").
+func rebaseCSSURLs(css, href string) string {
+	base, err := url.Parse(href)
+	if err != nil {
+		return css
+	}
+
+	return cssURLRe.ReplaceAllStringFunc(css, func(m string) string {
+		var u string
+		for _, v := range cssURLRe.FindStringSubmatch(m)[1:] {
+			if v != "" {
+				u = v
+				break
+			}
+		}
+
+		if u == "" || strings.HasPrefix(u, "/") || strings.HasPrefix(u, "#") {
+			return m
+		}
+		ref, err := url.Parse(u)
+		if err != nil || ref.IsAbs() {
+			return m
+		}
+
+		rebased := base.ResolveReference(ref).String()
+		if strings.HasPrefix(m, "@") {
+			return `@import "` + rebased + `"`
+		}
+		return `url("` + rebased + `")`
+	})
+}
+
+func compactCSS(body string) string {
+	return strings.Join(strings.Fields(body), " ")
+}
+
+// splitCSSSelectors splits a selector list at the commas not in parentheses
+// or brackets.
+func splitCSSSelectors(prelude string) []string {
+	var (
+		selectors []string
+		depth     int
+		start     int
+	)
+
+	for i := 0; i < len(prelude); i++ {
+		switch prelude[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				selectors = append(selectors, strings.TrimSpace(prelude[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(selectors, strings.TrimSpace(prelude[start:]))
+}
+
+var (
+	// Pseudo-classes and elements, including functional ones, and
+	// attribute selectors, which are ignored when matching.
+	cssIgnoredSelectorRe = regexp.MustCompile(`::?[a-zA-Z-]+(\([^)]*\))?|\[[^\]]*\]`)
+	cssCombinatorRe      = regexp.MustCompile(`\s*[>+~]\s*|\s+`)
+	cssSimpleSelectorRe  = regexp.MustCompile(`[.#]?[a-zA-Z0-9_-]+|\*`)
+)
+
+// isCriticalSelector reports whether all the compound selectors of the
+// selector, ignoring pseudo-classes and attributes, match the used tags,
+// classes and ids, or the selector contains a selector in allow, e.g. ".hero"
+// matches ".hero .title".
+func isCriticalSelector(sel string, used *usedSelectors, allow []string) bool {
+	var simples []string
+	stripped := strings.TrimSpace(cssIgnoredSelectorRe.ReplaceAllString(sel, ""))
+	for _, compound := range cssCombinatorRe.Split(stripped, -1) {
+		simples = append(simples, cssSimpleSelectorRe.FindAllString(compound, -1)...)
+	}
+
+	for _, a := range allow {
+		if sel == a {
+			return true
+		}
+		for _, simple := range simples {
+			if simple == a {
+				return true
+			}
+		}
+	}
+
+	if used == nil {
+		return false
+	}
+
+	for _, simple := range simples {
+		switch simple[0] {
+		case '*':
+		case '.':
+			if !used.classes[simple[1:]] {
+				return false
+			}
+		case '#':
+			if !used.ids[simple[1:]] {
+				return false
+			}
+		default:
+			if !used.tags[strings.ToLower(simple)] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSSRules(t *testing.T) {
+	assert := require.New(t)
+
+	rules := parseCSSRules(`@import url("a.css"); a { color: red } @media print { .b { color: "}" } } .c{}`)
+
+	assert.Equal([]cssRule{
+		{prelude: `@import url("a.css")`},
+		{prelude: "a", body: " color: red ", block: true},
+		{prelude: "@media print", body: ` .b { color: "}" } `, block: true},
+		{prelude: ".c", block: true},
+	}, rules)
+}
+
+func TestExtractCriticalCSS(t *testing.T) {
+	assert := require.New(t)
+
+	used := collectUsedSelectors([]byte(`<html><head><title>T</title></head><body class="home"><nav id="main"><a class="logo">L</a></nav><p class="below">B</p></body></html>`), 2)
+
+	assert.True(used.classes["home"])
+	assert.True(used.classes["logo"])
+	assert.False(used.classes["below"])
+	assert.True(used.ids["main"])
+
+	css := `
+/* The base. */
+@charset "utf-8";
+body.home { margin: 0 }
+#main a.logo:hover, .below { color: red }
+.below { color: blue }
+nav > a[href] { display: block }
+.footer .title { color: green }
+@media (max-width: 600px) { .logo { width: 100% } .below { width: 50% } }
+@media print { .below { display: none } }
+@font-face { font-family: "A"; src: url(a.woff) }
+@keyframes spin { from { top: 0 } }
+`
+
+	assert.Equal(`@charset "utf-8";body.home{margin: 0}#main a.logo:hover{color: red}nav > a[href]{display: block}@media (max-width: 600px){.logo{width: 100%}}@font-face{font-family: "A"; src: url(a.woff)}@keyframes spin{from { top: 0 }}`,
+		extractCriticalCSS([]byte(css), used, nil))
+
+	assert.Equal(`@charset "utf-8";.footer .title{color: green}@font-face{font-family: "A"; src: url(a.woff)}@keyframes spin{from { top: 0 }}`,
+		extractCriticalCSS([]byte(css), nil, []string{".footer"}))
+}
+
+func TestRebaseCSSURLs(t *testing.T) {
+	assert := require.New(t)
+
+	css := `@import "base.css";@import url('../print.css') print;.a{background: url(../img/a.png)}` +
+		`.b{background: url( "/img/b.png" )}.c{background: url(data:image/png;base64,AA==)}` +
+		`.d{background: url(https://cdn.com/d.png)}.e{filter: url(#f)}`
+
+	assert.Equal(`@import "/blog/css/base.css";@import url("/blog/print.css") print;.a{background: url("/blog/img/a.png")}`+
+		`.b{background: url( "/img/b.png" )}.c{background: url(data:image/png;base64,AA==)}`+
+		`.d{background: url(https://cdn.com/d.png)}.e{filter: url(#f)}`,
+		rebaseCSSURLs(css, "/blog/css/main.css?v=1"))
+
+	assert.Equal(`.a{background: url("http://example.com/img/a.png")}`,
+		rebaseCSSURLs(`.a{background: url(../img/a.png)}`, "http://example.com/css/main.css"))
+}
+
+func TestCriticalCSS(t *testing.T) {
+	assert := require.New(t)
+
+	load := func(p string) ([]byte, error) {
+		if p == "/css/main.css" {
+			return []byte(`.hero { color: red } .footer { color: blue } .extra { color: green }`), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	for i, test := range []struct {
+		in     string
+		expect string
+	}{
+		{
+			`<head><link rel="stylesheet" href="/blog/css/main.css?v=1" data-critical=".extra"></head><body><div class="hero"></div><div class="footer"></div></body>`,
+			`<head><style>.hero{color: red}.footer{color: blue}.extra{color: green}</style><link rel="preload" href="/blog/css/main.css?v=1" as="style" onload="this.onload=null;this.rel='stylesheet'"><noscript><link rel="stylesheet" href="/blog/css/main.css?v=1"></noscript></head><body><div class="hero"></div><div class="footer"></div></body>`,
+		},
+		{
+			`<head><link rel="stylesheet" href="http://example.com/blog/css/main.css" data-critical="" data-critical-elements="1"></head><body><div class="hero"></div><div class="footer"></div></body>`,
+			`<head><style>.hero{color: red}</style><link rel="preload" href="http://example.com/blog/css/main.css" as="style" onload="this.onload=null;this.rel='stylesheet'"><noscript><link rel="stylesheet" href="http://example.com/blog/css/main.css"></noscript></head><body><div class="hero"></div><div class="footer"></div></body>`,
+		},
+		// Not found, external and not marked stylesheets are left as is.
+		{
+			`<link rel="stylesheet" href="/blog/css/other.css" data-critical=""><link rel="stylesheet" href="https://other.com/blog/css/main.css" data-critical=""><link rel="stylesheet" href="/blog/css/main.css">`,
+			`<link rel="stylesheet" href="/blog/css/other.css" data-critical=""><link rel="stylesheet" href="https://other.com/blog/css/main.css" data-critical=""><link rel="stylesheet" href="/blog/css/main.css">`,
+		},
+	} {
+		var b bytes.Buffer
+		tr := NewChain(CriticalCSS("http://example.com/blog/", load))
+		assert.NoError(tr.Apply(&b, bytes.NewBufferString(test.in), nil))
+		assert.Equal(test.expect, b.String(), "[%d]", i)
+	}
+}
@@ -19,32 +19,35 @@ import (
 	"regexp"
 
 	"github.com/gohugoio/hugo/helpers"
+	jww "github.com/spf13/jwalterweatherman"
 )
 
 var metaTagsCheck = regexp.MustCompile(`(?i)<meta\s+name=['|"]?generator['|"]?`)
 var hugoGeneratorTag = fmt.Sprintf(`<meta name="generator" content="Hugo %s" />`, helpers.CurrentHugoVersion)
 
 // HugoGeneratorInject injects a meta generator tag for Hugo if none present.
-func HugoGeneratorInject(ct contentTransformer) {
-	if metaTagsCheck.Match(ct.Content()) {
-		if _, err := ct.Write(ct.Content()); err != nil {
-			helpers.DistinctWarnLog.Println("Failed to inject Hugo generator tag:", err)
+// Failures are logged as warnings to the given logger.
+func HugoGeneratorInject(logger *jww.Notepad) func(ct contentTransformer) {
+	return func(ct contentTransformer) {
+		if metaTagsCheck.Match(ct.Content()) {
+			if _, err := ct.Write(ct.Content()); err != nil {
+				helpers.Warnidf(logger, "warning-generator-inject", "Failed to inject Hugo generator tag: %s", err)
+			}
+			return
 		}
-		return
-	}
-
-	head := "<head>"
-	replace := []byte(fmt.Sprintf("%s\n\t%s", head, hugoGeneratorTag))
-	newcontent := bytes.Replace(ct.Content(), []byte(head), replace, 1)
 
-	if len(newcontent) == len(ct.Content()) {
-		head := "<HEAD>"
+		head := "<head>"
 		replace := []byte(fmt.Sprintf("%s\n\t%s", head, hugoGeneratorTag))
-		newcontent = bytes.Replace(ct.Content(), []byte(head), replace, 1)
-	}
+		newcontent := bytes.Replace(ct.Content(), []byte(head), replace, 1)
 
-	if _, err := ct.Write(newcontent); err != nil {
-		helpers.DistinctWarnLog.Println("Failed to inject Hugo generator tag:", err)
-	}
+		if len(newcontent) == len(ct.Content()) {
+			head := "<HEAD>"
+			replace := []byte(fmt.Sprintf("%s\n\t%s", head, hugoGeneratorTag))
+			newcontent = bytes.Replace(ct.Content(), []byte(head), replace, 1)
+		}
 
+		if _, err := ct.Write(newcontent); err != nil {
+			helpers.Warnidf(logger, "warning-generator-inject", "Failed to inject Hugo generator tag: %s", err)
+		}
+	}
 }
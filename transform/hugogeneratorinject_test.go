@@ -15,8 +15,13 @@ package transform
 
 import (
 	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
 	"strings"
 	"testing"
+
+	jww "github.com/spf13/jwalterweatherman"
 )
 
 func TestHugoGeneratorInject(t *testing.T) {
@@ -48,7 +53,7 @@ func TestHugoGeneratorInject(t *testing.T) {
 		in := strings.NewReader(this.in)
 		out := new(bytes.Buffer)
 
-		tr := NewChain(HugoGeneratorInject)
+		tr := NewChain(HugoGeneratorInject(jww.NewNotepad(jww.LevelError, jww.LevelError, os.Stdout, ioutil.Discard, "", log.Ldate|log.Ltime)))
 		tr.Apply(out, in, []byte(""))
 
 		if out.String() != this.expect {
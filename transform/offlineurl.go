@@ -0,0 +1,155 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	offlineAttrRe   = regexp.MustCompile(`(?i)(\s(?:href|src|srcset|poster|action|data)\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+	offlineCSSURLRe = regexp.MustCompile(`url\(\s*(["']?)([^"')]+)(["']?)\s*\)`)
+	schemeRe        = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// OfflineURL returns a function that rewrites the links in a HTML document
+// so the site works when opened from the file system, e.g. from a zip file
+// or a USB stick:
+//
+// Root relative links and absolute links to the site, i.e. starting with
+// the given base URL, are made relative to the page using the dotted path of
+// the content transformer, e.g. "../../". This includes srcset and url(...)
+// in inline styles. Links to directories get an index.html appended, as there
+// is no web server to do that.
+func OfflineURL(baseURL string) func(ct contentTransformer) {
+	o := newOfflineURLs(baseURL)
+	return func(ct contentTransformer) {
+		dotted := string(ct.Path())
+
+		content := offlineAttrRe.ReplaceAllFunc(ct.Content(), func(m []byte) []byte {
+			sm := offlineAttrRe.FindSubmatch(m)
+			quote, value := `"`, string(sm[2])
+			if sm[3] != nil {
+				quote, value = "'", string(sm[3])
+			}
+
+			if strings.HasSuffix(strings.ToLower(strings.TrimRight(string(sm[1]), " =\t\n")), "srcset") {
+				value = o.rewriteSrcset(value, dotted)
+			} else {
+				value = o.rewrite(value, dotted)
+			}
+
+			return []byte(string(sm[1]) + quote + value + quote)
+		})
+
+		content = offlineCSSURLRe.ReplaceAllFunc(content, func(m []byte) []byte {
+			sm := offlineCSSURLRe.FindSubmatch(m)
+			return []byte("url(" + string(sm[1]) + o.rewrite(string(sm[2]), dotted) + string(sm[3]) + ")")
+		})
+
+		ct.Write(content)
+	}
+}
+
+type offlineURLs struct {
+	host     string
+	basePath string
+}
+
+func newOfflineURLs(baseURL string) offlineURLs {
+	o := offlineURLs{basePath: "/"}
+	if u, err := url.Parse(baseURL); err == nil {
+		o.host = u.Host
+		if u.Path != "" {
+			o.basePath = strings.TrimSuffix(u.Path, "/") + "/"
+		}
+	}
+	return o
+}
+
+// rewrite rewrites the given URL relative to the dotted path.
+func (o offlineURLs) rewrite(u, dotted string) string {
+	if u == "" || u[0] == '#' {
+		return u
+	}
+
+	var rel string
+
+	switch {
+	case strings.HasPrefix(u, "//") || schemeRe.MatchString(u):
+		pu, err := url.Parse(u)
+		if err != nil || o.host == "" || pu.Host != o.host {
+			return u
+		}
+		if pu.Scheme != "" && pu.Scheme != "http" && pu.Scheme != "https" {
+			return u
+		}
+		p := u[strings.Index(u, pu.Host)+len(pu.Host):]
+		var ok bool
+		if rel, ok = o.trimBasePath(p); !ok {
+			// Another site on the same host.
+			return u
+		}
+	case u[0] == '/':
+		rel, _ = o.trimBasePath(u)
+	default:
+		return addIndexHTML(u, false)
+	}
+
+	return dotted + addIndexHTML(rel, true)
+}
+
+// rewriteSrcset rewrites the URLs in a srcset attribute value, e.g.
+// "/img/small.jpg 200w, /img/big.jpg 700w".
+func (o offlineURLs) rewriteSrcset(srcset, dotted string) string {
+	candidates := strings.Split(srcset, ",")
+	for i, c := range candidates {
+		fields := strings.Fields(c)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = o.rewrite(fields[0], dotted)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// trimBasePath returns the path relative to the publish dir. It returns
+// false if the path is outside of the base URL's path.
+func (o offlineURLs) trimBasePath(p string) (string, bool) {
+	if p == "" || p == strings.TrimSuffix(o.basePath, "/") {
+		return "", true
+	}
+	if strings.HasPrefix(p, o.basePath) {
+		return p[len(o.basePath):], true
+	}
+	return strings.TrimPrefix(p, "/"), false
+}
+
+// addIndexHTML appends index.html to URLs pointing to a directory. If root
+// is set, the empty path is the publish dir.
+func addIndexHTML(u string, root bool) string {
+	p, suffix := u, ""
+	if i := strings.IndexAny(u, "?#"); i != -1 {
+		p, suffix = u[:i], u[i:]
+	}
+
+	if (p == "" && root) || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	}
+
+	return p + suffix
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineURL(t *testing.T) {
+	assert := require.New(t)
+
+	for i, test := range []struct {
+		baseURL string
+		path    string
+		in      string
+		expect  string
+	}{
+		{"http://example.com/", "../../", `<a href="/posts/">Posts</a>`, `<a href="../../posts/index.html">Posts</a>`},
+		{"http://example.com/", "./", `<a href='/'>Home</a>`, `<a href='./index.html'>Home</a>`},
+		{"http://example.com/", "../", `<a href="http://example.com/about/#team">About</a>`, `<a href="../about/index.html#team">About</a>`},
+		{"http://example.com/", "../", `<a href="https://example.com/tags/?page=2">Tags</a>`, `<a href="../tags/index.html?page=2">Tags</a>`},
+		{"http://example.com/blog/", "../", `<a href="/blog/posts/p1/">P1</a> <img src="http://example.com/blog/logo.png">`, `<a href="../posts/p1/index.html">P1</a> <img src="../logo.png">`},
+		{"http://example.com/blog/", "../", `<a href="http://example.com/other/">Other</a>`, `<a href="http://example.com/other/">Other</a>`},
+		{"http://example.com/", "../", `<a href="https://gohugo.io/">Hugo</a> <a href="//cdn.com/x.js">CDN</a> <a href="mailto:a@b.c">Mail</a> <a href="#top">Top</a>`, `<a href="https://gohugo.io/">Hugo</a> <a href="//cdn.com/x.js">CDN</a> <a href="mailto:a@b.c">Mail</a> <a href="#top">Top</a>`},
+		{"http://example.com/", "../", `<a href="sub/">Sub</a> <a href="img.png">Img</a>`, `<a href="sub/index.html">Sub</a> <a href="img.png">Img</a>`},
+		{"http://example.com/", "../../", `<img srcset="/img/small.jpg 200w, /img/big.jpg 700w" src="/img/foo.jpg">`, `<img srcset="../../img/small.jpg 200w, ../../img/big.jpg 700w" src="../../img/foo.jpg">`},
+		{"http://example.com/", "../", `<div style="background: url('/img/bg.png')"></div>`, `<div style="background: url('../img/bg.png')"></div>`},
+		{"http://example.com/", "../", `<video poster="/poster.jpg"></video>`, `<video poster="../poster.jpg"></video>`},
+	} {
+		var b bytes.Buffer
+		tr := NewChain(OfflineURL(test.baseURL))
+		assert.NoError(tr.Apply(&b, bytes.NewBufferString(test.in), []byte(test.path)))
+		assert.Equal(test.expect, b.String(), "[%d]", i)
+	}
+}